@@ -0,0 +1,62 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/tOgg1/forge/internal/forged"
+)
+
+const certUsage = `Usage: forged cert init [flags]
+
+Generate a CA plus server and client certificates for forged TLS.
+
+Flags:
+  -config FILE  config file (default is $HOME/.config/forge/config.yaml)
+  -dir DIR      output directory (default is forged.tls.dir or <config_dir>/certs)
+  -host HOSTS   comma-separated extra DNS names or IPs for the server certificate
+  -force        overwrite existing certificates
+`
+
+// runCert implements "forged cert".
+func runCert(args []string) int {
+	if len(args) == 0 || args[0] != "init" {
+		fmt.Fprint(os.Stderr, certUsage)
+		return 2
+	}
+
+	fs := flag.NewFlagSet("forged cert init", flag.ContinueOnError)
+	configFile := fs.String("config", "", "config file (default is $HOME/.config/forge/config.yaml)")
+	dir := fs.String("dir", "", "output directory (default is forged.tls.dir or <config_dir>/certs)")
+	hosts := fs.String("host", "", "comma-separated extra DNS names or IPs for the server certificate")
+	force := fs.Bool("force", false, "overwrite existing certificates")
+	if err := fs.Parse(args[1:]); err != nil {
+		return 2
+	}
+
+	certDir := *dir
+	if certDir == "" {
+		cfg, _, err := loadConfig(*configFile)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error loading config: %v\n", err)
+			return 1
+		}
+		certDir = cfg.CertDir()
+	}
+
+	opts := forged.CertOptions{Overwrite: *force}
+	if *hosts != "" {
+		opts.Hosts = strings.Split(*hosts, ",")
+	}
+	if err := forged.GenerateCerts(certDir, opts); err != nil {
+		fmt.Fprintf(os.Stderr, "Error generating certificates: %v\n", err)
+		return 1
+	}
+
+	fmt.Printf("Wrote certificates to %s\n", certDir)
+	fmt.Printf("Copy %s, %s, and %s to clients and relay peers, then set forged.tls.enabled: true.\n",
+		forged.CACertFile, forged.ClientCertFile, forged.ClientKeyFile)
+	return 0
+}
@@ -16,6 +16,7 @@ import (
 	"github.com/tOgg1/forge/internal/config"
 	"github.com/tOgg1/forge/internal/forged"
 	"github.com/tOgg1/forge/internal/logging"
+	"github.com/tOgg1/forge/internal/loop"
 	"github.com/tOgg1/forge/internal/node"
 	"github.com/tOgg1/forge/internal/scheduler"
 	"github.com/tOgg1/forge/internal/workspace"
@@ -29,6 +30,15 @@ var (
 )
 
 func main() {
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "token":
+			os.Exit(runToken(os.Args[2:]))
+		case "cert":
+			os.Exit(runCert(os.Args[2:]))
+		}
+	}
+
 	hostname := flag.String("hostname", forged.DefaultHost, "hostname to listen on")
 	port := flag.Int("port", forged.DefaultPort, "port to listen on")
 	configFile := flag.String("config", "", "config file (default is $HOME/.config/forge/config.yaml)")
@@ -40,6 +50,8 @@ func main() {
 	diskCritical := flag.Float64("disk-critical", defaultDisk.CriticalPercent, "disk usage percent to treat as critical")
 	diskResume := flag.Float64("disk-resume", defaultDisk.ResumePercent, "disk usage percent to resume paused agents")
 	diskPause := flag.Bool("disk-pause", defaultDisk.PauseAgents, "pause agent processes when disk is critically full")
+	forgeBin := flag.String("forge-bin", forged.DefaultLoopCommand, "forge binary used to run supervised loops")
+	metricsAddr := flag.String("metrics-addr", "", "address to serve Prometheus /metrics on, e.g. 127.0.0.1:9464 (default disabled)")
 	flag.Parse()
 
 	cfg, loader, err := loadConfig(*configFile)
@@ -95,6 +107,9 @@ func main() {
 		Hostname:          *hostname,
 		Port:              *port,
 		DiskMonitorConfig: &diskConfig,
+		LoopCommand:       *forgeBin,
+		ConfigFile:        loader.ConfigFileUsed(),
+		MetricsAddr:       *metricsAddr,
 	})
 	if err != nil {
 		logger.Error().Err(err).Msg("failed to initialize forged")
@@ -156,7 +171,24 @@ func main() {
 		)
 
 		daemon.SetScheduler(sched)
+		daemon.Metrics().AddCollector(func(_ context.Context, w *forged.MetricsWriter) {
+			stats := sched.Stats()
+			w.Gauge("forge_scheduler_running", "Whether the scheduler is running.", boolMetric(stats.Running))
+			w.Gauge("forge_scheduler_paused", "Whether the scheduler is paused.", boolMetric(stats.Paused))
+			w.Counter("forge_scheduler_dispatches_total", "Queue dispatches by result.", float64(stats.SuccessfulDispatches), "result", "success")
+			w.Counter("forge_scheduler_dispatches_total", "Queue dispatches by result.", float64(stats.FailedDispatches), "result", "failure")
+		})
 		logger.Info().Msg("scheduler configured")
+
+		// Reap loops whose runner died and restart them per their restart policy
+		if loops := daemon.LoopSupervisor(); loops != nil {
+			reaper := loop.NewReaper(daemon.Database(), cfg)
+			reaper.Restart = loops.StartLoop
+			loops.SetReaper(func(ctx context.Context) error {
+				_, err := reaper.Reap(ctx)
+				return err
+			})
+		}
 	}
 
 	if err := daemon.Run(ctx); err != nil {
@@ -165,6 +197,13 @@ func main() {
 	}
 }
 
+func boolMetric(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
 func loadConfig(path string) (*config.Config, *config.Loader, error) {
 	loader := config.NewLoader()
 	if path != "" {
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/tOgg1/forge/internal/vault"
+)
+
+const tokenUsage = `Usage: forged token <command> [flags]
+
+Manage API tokens for the forged gRPC service and mail relay.
+
+Commands:
+  create -name NAME [-scope read|control]  mint a token (printed once)
+  list                                     list tokens
+  revoke NAME                              revoke a token
+
+Flags:
+  -config FILE  config file (default is $HOME/.config/forge/config.yaml)
+`
+
+// runToken implements "forged token".
+func runToken(args []string) int {
+	if len(args) == 0 {
+		fmt.Fprint(os.Stderr, tokenUsage)
+		return 2
+	}
+
+	fs := flag.NewFlagSet("forged token "+args[0], flag.ContinueOnError)
+	configFile := fs.String("config", "", "config file (default is $HOME/.config/forge/config.yaml)")
+	name := fs.String("name", "", "token name (e.g., the node or operator using it)")
+	scopeFlag := fs.String("scope", string(vault.TokenScopeControl), "token scope: read or control")
+	if err := fs.Parse(args[1:]); err != nil {
+		return 2
+	}
+
+	cfg, _, err := loadConfig(*configFile)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading config: %v\n", err)
+		return 1
+	}
+	vaultPath := cfg.TokenVaultPath()
+
+	switch args[0] {
+	case "create":
+		scope, err := vault.ParseTokenScope(*scopeFlag)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return 2
+		}
+		secret, token, err := vault.MintToken(vaultPath, *name, scope)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error creating token: %v\n", err)
+			return 1
+		}
+		fmt.Printf("Created %s token %q. It will not be shown again:\n\n%s\n\n", token.Scope, token.Name, secret)
+		fmt.Println("Store it on the control machine with: forge node add ... --token <token>")
+		if !cfg.Forged.Auth.Enabled {
+			fmt.Println("Note: forged.auth.enabled is false; tokens are not checked until it is enabled.")
+		}
+		return 0
+
+	case "list":
+		tokens, err := vault.ListTokens(vaultPath)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error listing tokens: %v\n", err)
+			return 1
+		}
+		if len(tokens) == 0 {
+			fmt.Println("No tokens")
+			return 0
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(w, "NAME\tSCOPE\tCREATED")
+		for _, token := range tokens {
+			fmt.Fprintf(w, "%s\t%s\t%s\n", token.Name, token.Scope, token.CreatedAt.Local().Format(time.RFC3339))
+		}
+		_ = w.Flush()
+		return 0
+
+	case "revoke":
+		target := *name
+		if fs.NArg() > 0 {
+			target = fs.Arg(0)
+		}
+		if err := vault.RevokeToken(vaultPath, target); err != nil {
+			fmt.Fprintf(os.Stderr, "Error revoking token %q: %v\n", target, err)
+			return 1
+		}
+		fmt.Printf("Revoked token %q\n", target)
+		return 0
+
+	default:
+		fmt.Fprintf(os.Stderr, "unknown token command %q\n\n%s", args[0], tokenUsage)
+		return 2
+	}
+}
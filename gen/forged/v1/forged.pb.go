@@ -160,6 +160,7 @@ const (
 	EventType_EVENT_TYPE_ERROR                EventType = 5
 	EventType_EVENT_TYPE_PANE_CONTENT_CHANGED EventType = 6
 	EventType_EVENT_TYPE_RESOURCE_VIOLATION   EventType = 7
+	EventType_EVENT_TYPE_LOOP_RUN_FINISHED    EventType = 8
 )
 
 // Enum value maps for EventType.
@@ -173,6 +174,7 @@ var (
 		5: "EVENT_TYPE_ERROR",
 		6: "EVENT_TYPE_PANE_CONTENT_CHANGED",
 		7: "EVENT_TYPE_RESOURCE_VIOLATION",
+		8: "EVENT_TYPE_LOOP_RUN_FINISHED",
 	}
 	EventType_value = map[string]int32{
 		"EVENT_TYPE_UNSPECIFIED":          0,
@@ -183,6 +185,7 @@ var (
 		"EVENT_TYPE_ERROR":                5,
 		"EVENT_TYPE_PANE_CONTENT_CHANGED": 6,
 		"EVENT_TYPE_RESOURCE_VIOLATION":   7,
+		"EVENT_TYPE_LOOP_RUN_FINISHED":    8,
 	}
 )
 
@@ -324,6 +327,64 @@ func (TranscriptEntryType) EnumDescriptor() ([]byte, []int) {
 	return file_forged_v1_forged_proto_rawDescGZIP(), []int{4}
 }
 
+type LoopState int32
+
+const (
+	LoopState_LOOP_STATE_UNSPECIFIED LoopState = 0
+	LoopState_LOOP_STATE_RUNNING     LoopState = 1
+	LoopState_LOOP_STATE_SLEEPING    LoopState = 2
+	LoopState_LOOP_STATE_WAITING     LoopState = 3
+	LoopState_LOOP_STATE_STOPPED     LoopState = 4
+	LoopState_LOOP_STATE_ERROR       LoopState = 5
+)
+
+// Enum value maps for LoopState.
+var (
+	LoopState_name = map[int32]string{
+		0: "LOOP_STATE_UNSPECIFIED",
+		1: "LOOP_STATE_RUNNING",
+		2: "LOOP_STATE_SLEEPING",
+		3: "LOOP_STATE_WAITING",
+		4: "LOOP_STATE_STOPPED",
+		5: "LOOP_STATE_ERROR",
+	}
+	LoopState_value = map[string]int32{
+		"LOOP_STATE_UNSPECIFIED": 0,
+		"LOOP_STATE_RUNNING":     1,
+		"LOOP_STATE_SLEEPING":    2,
+		"LOOP_STATE_WAITING":     3,
+		"LOOP_STATE_STOPPED":     4,
+		"LOOP_STATE_ERROR":       5,
+	}
+)
+
+func (x LoopState) Enum() *LoopState {
+	p := new(LoopState)
+	*p = x
+	return p
+}
+
+func (x LoopState) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (LoopState) Descriptor() protoreflect.EnumDescriptor {
+	return file_forged_v1_forged_proto_enumTypes[5].Descriptor()
+}
+
+func (LoopState) Type() protoreflect.EnumType {
+	return &file_forged_v1_forged_proto_enumTypes[5]
+}
+
+func (x LoopState) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use LoopState.Descriptor instead.
+func (LoopState) EnumDescriptor() ([]byte, []int) {
+	return file_forged_v1_forged_proto_rawDescGZIP(), []int{5}
+}
+
 type Health int32
 
 const (
@@ -360,11 +421,11 @@ func (x Health) String() string {
 }
 
 func (Health) Descriptor() protoreflect.EnumDescriptor {
-	return file_forged_v1_forged_proto_enumTypes[5].Descriptor()
+	return file_forged_v1_forged_proto_enumTypes[6].Descriptor()
 }
 
 func (Health) Type() protoreflect.EnumType {
-	return &file_forged_v1_forged_proto_enumTypes[5]
+	return &file_forged_v1_forged_proto_enumTypes[6]
 }
 
 func (x Health) Number() protoreflect.EnumNumber {
@@ -373,7 +434,7 @@ func (x Health) Number() protoreflect.EnumNumber {
 
 // Deprecated: Use Health.Descriptor instead.
 func (Health) EnumDescriptor() ([]byte, []int) {
-	return file_forged_v1_forged_proto_rawDescGZIP(), []int{5}
+	return file_forged_v1_forged_proto_rawDescGZIP(), []int{6}
 }
 
 type SpawnAgentRequest struct {
@@ -1598,7 +1659,9 @@ type StreamEventsRequest struct {
 	// Filter by agent IDs.
 	AgentIds []string `protobuf:"bytes,3,rep,name=agent_ids,json=agentIds,proto3" json:"agent_ids,omitempty"`
 	// Filter by workspace IDs.
-	WorkspaceIds  []string `protobuf:"bytes,4,rep,name=workspace_ids,json=workspaceIds,proto3" json:"workspace_ids,omitempty"`
+	WorkspaceIds []string `protobuf:"bytes,4,rep,name=workspace_ids,json=workspaceIds,proto3" json:"workspace_ids,omitempty"`
+	// Filter by loop IDs.
+	LoopIds       []string `protobuf:"bytes,5,rep,name=loop_ids,json=loopIds,proto3" json:"loop_ids,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -1661,6 +1724,13 @@ func (x *StreamEventsRequest) GetWorkspaceIds() []string {
 	return nil
 }
 
+func (x *StreamEventsRequest) GetLoopIds() []string {
+	if x != nil {
+		return x.LoopIds
+	}
+	return nil
+}
+
 type StreamEventsResponse struct {
 	state protoimpl.MessageState `protogen:"open.v1"`
 	// The event.
@@ -1718,6 +1788,8 @@ type Event struct {
 	AgentId string `protobuf:"bytes,4,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
 	// Associated workspace (if applicable).
 	WorkspaceId string `protobuf:"bytes,5,opt,name=workspace_id,json=workspaceId,proto3" json:"workspace_id,omitempty"`
+	// Associated loop (if applicable).
+	LoopId string `protobuf:"bytes,6,opt,name=loop_id,json=loopId,proto3" json:"loop_id,omitempty"`
 	// Event-specific payload.
 	//
 	// Types that are valid to be assigned to Payload:
@@ -1729,6 +1801,7 @@ type Event struct {
 	//	*Event_Error
 	//	*Event_PaneContentChanged
 	//	*Event_ResourceViolation
+	//	*Event_LoopRunFinished
 	Payload       isEvent_Payload `protobuf_oneof:"payload"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
@@ -1799,6 +1872,13 @@ func (x *Event) GetWorkspaceId() string {
 	return ""
 }
 
+func (x *Event) GetLoopId() string {
+	if x != nil {
+		return x.LoopId
+	}
+	return ""
+}
+
 func (x *Event) GetPayload() isEvent_Payload {
 	if x != nil {
 		return x.Payload
@@ -1869,6 +1949,15 @@ func (x *Event) GetResourceViolation() *ResourceViolationEvent {
 	return nil
 }
 
+func (x *Event) GetLoopRunFinished() *LoopRunFinishedEvent {
+	if x != nil {
+		if x, ok := x.Payload.(*Event_LoopRunFinished); ok {
+			return x.LoopRunFinished
+		}
+	}
+	return nil
+}
+
 type isEvent_Payload interface {
 	isEvent_Payload()
 }
@@ -1901,6 +1990,10 @@ type Event_ResourceViolation struct {
 	ResourceViolation *ResourceViolationEvent `protobuf:"bytes,16,opt,name=resource_violation,json=resourceViolation,proto3,oneof"`
 }
 
+type Event_LoopRunFinished struct {
+	LoopRunFinished *LoopRunFinishedEvent `protobuf:"bytes,17,opt,name=loop_run_finished,json=loopRunFinished,proto3,oneof"`
+}
+
 func (*Event_AgentStateChanged) isEvent_Payload() {}
 
 func (*Event_AgentOutput) isEvent_Payload() {}
@@ -1915,6 +2008,8 @@ func (*Event_PaneContentChanged) isEvent_Payload() {}
 
 func (*Event_ResourceViolation) isEvent_Payload() {}
 
+func (*Event_LoopRunFinished) isEvent_Payload() {}
+
 type AgentStateChangedEvent struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	PreviousState AgentState             `protobuf:"varint,1,opt,name=previous_state,json=previousState,proto3,enum=forged.v1.AgentState" json:"previous_state,omitempty"`
@@ -2309,6 +2404,106 @@ func (x *ResourceViolationEvent) GetActionTaken() ResourceLimitAction {
 	return ResourceLimitAction_RESOURCE_LIMIT_ACTION_UNSPECIFIED
 }
 
+// LoopRunFinishedEvent is emitted when a loop iteration finishes.
+type LoopRunFinishedEvent struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Loop name.
+	LoopName string `protobuf:"bytes,1,opt,name=loop_name,json=loopName,proto3" json:"loop_name,omitempty"`
+	// Finished run ID.
+	RunId string `protobuf:"bytes,2,opt,name=run_id,json=runId,proto3" json:"run_id,omitempty"`
+	// Run status (success, error, killed).
+	Status string `protobuf:"bytes,3,opt,name=status,proto3" json:"status,omitempty"`
+	// Harness exit code.
+	ExitCode int32 `protobuf:"varint,4,opt,name=exit_code,json=exitCode,proto3" json:"exit_code,omitempty"`
+	// Run duration.
+	Duration *durationpb.Duration `protobuf:"bytes,5,opt,name=duration,proto3" json:"duration,omitempty"`
+	// Loop iteration the run belonged to.
+	Iteration int32 `protobuf:"varint,6,opt,name=iteration,proto3" json:"iteration,omitempty"`
+	// Error text, if the run failed.
+	Error         string `protobuf:"bytes,7,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *LoopRunFinishedEvent) Reset() {
+	*x = LoopRunFinishedEvent{}
+	mi := &file_forged_v1_forged_proto_msgTypes[26]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LoopRunFinishedEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LoopRunFinishedEvent) ProtoMessage() {}
+
+func (x *LoopRunFinishedEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_forged_v1_forged_proto_msgTypes[26]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LoopRunFinishedEvent.ProtoReflect.Descriptor instead.
+func (*LoopRunFinishedEvent) Descriptor() ([]byte, []int) {
+	return file_forged_v1_forged_proto_rawDescGZIP(), []int{26}
+}
+
+func (x *LoopRunFinishedEvent) GetLoopName() string {
+	if x != nil {
+		return x.LoopName
+	}
+	return ""
+}
+
+func (x *LoopRunFinishedEvent) GetRunId() string {
+	if x != nil {
+		return x.RunId
+	}
+	return ""
+}
+
+func (x *LoopRunFinishedEvent) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *LoopRunFinishedEvent) GetExitCode() int32 {
+	if x != nil {
+		return x.ExitCode
+	}
+	return 0
+}
+
+func (x *LoopRunFinishedEvent) GetDuration() *durationpb.Duration {
+	if x != nil {
+		return x.Duration
+	}
+	return nil
+}
+
+func (x *LoopRunFinishedEvent) GetIteration() int32 {
+	if x != nil {
+		return x.Iteration
+	}
+	return 0
+}
+
+func (x *LoopRunFinishedEvent) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
 type PaneContentChangedEvent struct {
 	state protoimpl.MessageState `protogen:"open.v1"`
 	// New content hash.
@@ -2321,7 +2516,7 @@ type PaneContentChangedEvent struct {
 
 func (x *PaneContentChangedEvent) Reset() {
 	*x = PaneContentChangedEvent{}
-	mi := &file_forged_v1_forged_proto_msgTypes[26]
+	mi := &file_forged_v1_forged_proto_msgTypes[27]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -2333,7 +2528,7 @@ func (x *PaneContentChangedEvent) String() string {
 func (*PaneContentChangedEvent) ProtoMessage() {}
 
 func (x *PaneContentChangedEvent) ProtoReflect() protoreflect.Message {
-	mi := &file_forged_v1_forged_proto_msgTypes[26]
+	mi := &file_forged_v1_forged_proto_msgTypes[27]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2346,7 +2541,7 @@ func (x *PaneContentChangedEvent) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use PaneContentChangedEvent.ProtoReflect.Descriptor instead.
 func (*PaneContentChangedEvent) Descriptor() ([]byte, []int) {
-	return file_forged_v1_forged_proto_rawDescGZIP(), []int{26}
+	return file_forged_v1_forged_proto_rawDescGZIP(), []int{27}
 }
 
 func (x *PaneContentChangedEvent) GetContentHash() string {
@@ -2379,7 +2574,7 @@ type GetTranscriptRequest struct {
 
 func (x *GetTranscriptRequest) Reset() {
 	*x = GetTranscriptRequest{}
-	mi := &file_forged_v1_forged_proto_msgTypes[27]
+	mi := &file_forged_v1_forged_proto_msgTypes[28]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -2391,7 +2586,7 @@ func (x *GetTranscriptRequest) String() string {
 func (*GetTranscriptRequest) ProtoMessage() {}
 
 func (x *GetTranscriptRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_forged_v1_forged_proto_msgTypes[27]
+	mi := &file_forged_v1_forged_proto_msgTypes[28]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2404,7 +2599,7 @@ func (x *GetTranscriptRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use GetTranscriptRequest.ProtoReflect.Descriptor instead.
 func (*GetTranscriptRequest) Descriptor() ([]byte, []int) {
-	return file_forged_v1_forged_proto_rawDescGZIP(), []int{27}
+	return file_forged_v1_forged_proto_rawDescGZIP(), []int{28}
 }
 
 func (x *GetTranscriptRequest) GetAgentId() string {
@@ -2451,7 +2646,7 @@ type GetTranscriptResponse struct {
 
 func (x *GetTranscriptResponse) Reset() {
 	*x = GetTranscriptResponse{}
-	mi := &file_forged_v1_forged_proto_msgTypes[28]
+	mi := &file_forged_v1_forged_proto_msgTypes[29]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -2463,7 +2658,7 @@ func (x *GetTranscriptResponse) String() string {
 func (*GetTranscriptResponse) ProtoMessage() {}
 
 func (x *GetTranscriptResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_forged_v1_forged_proto_msgTypes[28]
+	mi := &file_forged_v1_forged_proto_msgTypes[29]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2476,7 +2671,7 @@ func (x *GetTranscriptResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use GetTranscriptResponse.ProtoReflect.Descriptor instead.
 func (*GetTranscriptResponse) Descriptor() ([]byte, []int) {
-	return file_forged_v1_forged_proto_rawDescGZIP(), []int{28}
+	return file_forged_v1_forged_proto_rawDescGZIP(), []int{29}
 }
 
 func (x *GetTranscriptResponse) GetAgentId() string {
@@ -2523,7 +2718,7 @@ type TranscriptEntry struct {
 
 func (x *TranscriptEntry) Reset() {
 	*x = TranscriptEntry{}
-	mi := &file_forged_v1_forged_proto_msgTypes[29]
+	mi := &file_forged_v1_forged_proto_msgTypes[30]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -2535,7 +2730,7 @@ func (x *TranscriptEntry) String() string {
 func (*TranscriptEntry) ProtoMessage() {}
 
 func (x *TranscriptEntry) ProtoReflect() protoreflect.Message {
-	mi := &file_forged_v1_forged_proto_msgTypes[29]
+	mi := &file_forged_v1_forged_proto_msgTypes[30]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2548,7 +2743,7 @@ func (x *TranscriptEntry) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use TranscriptEntry.ProtoReflect.Descriptor instead.
 func (*TranscriptEntry) Descriptor() ([]byte, []int) {
-	return file_forged_v1_forged_proto_rawDescGZIP(), []int{29}
+	return file_forged_v1_forged_proto_rawDescGZIP(), []int{30}
 }
 
 func (x *TranscriptEntry) GetTimestamp() *timestamppb.Timestamp {
@@ -2591,7 +2786,7 @@ type StreamTranscriptRequest struct {
 
 func (x *StreamTranscriptRequest) Reset() {
 	*x = StreamTranscriptRequest{}
-	mi := &file_forged_v1_forged_proto_msgTypes[30]
+	mi := &file_forged_v1_forged_proto_msgTypes[31]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -2603,7 +2798,7 @@ func (x *StreamTranscriptRequest) String() string {
 func (*StreamTranscriptRequest) ProtoMessage() {}
 
 func (x *StreamTranscriptRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_forged_v1_forged_proto_msgTypes[30]
+	mi := &file_forged_v1_forged_proto_msgTypes[31]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2616,7 +2811,7 @@ func (x *StreamTranscriptRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use StreamTranscriptRequest.ProtoReflect.Descriptor instead.
 func (*StreamTranscriptRequest) Descriptor() ([]byte, []int) {
-	return file_forged_v1_forged_proto_rawDescGZIP(), []int{30}
+	return file_forged_v1_forged_proto_rawDescGZIP(), []int{31}
 }
 
 func (x *StreamTranscriptRequest) GetAgentId() string {
@@ -2645,7 +2840,7 @@ type StreamTranscriptResponse struct {
 
 func (x *StreamTranscriptResponse) Reset() {
 	*x = StreamTranscriptResponse{}
-	mi := &file_forged_v1_forged_proto_msgTypes[31]
+	mi := &file_forged_v1_forged_proto_msgTypes[32]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -2657,7 +2852,7 @@ func (x *StreamTranscriptResponse) String() string {
 func (*StreamTranscriptResponse) ProtoMessage() {}
 
 func (x *StreamTranscriptResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_forged_v1_forged_proto_msgTypes[31]
+	mi := &file_forged_v1_forged_proto_msgTypes[32]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2670,7 +2865,7 @@ func (x *StreamTranscriptResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use StreamTranscriptResponse.ProtoReflect.Descriptor instead.
 func (*StreamTranscriptResponse) Descriptor() ([]byte, []int) {
-	return file_forged_v1_forged_proto_rawDescGZIP(), []int{31}
+	return file_forged_v1_forged_proto_rawDescGZIP(), []int{32}
 }
 
 func (x *StreamTranscriptResponse) GetEntries() []*TranscriptEntry {
@@ -2687,27 +2882,49 @@ func (x *StreamTranscriptResponse) GetCursor() string {
 	return ""
 }
 
-type GetStatusRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
+type CreateLoopRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Loop name (unique on this node).
+	Name string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	// Repository the loop works in.
+	RepoPath string `protobuf:"bytes,2,opt,name=repo_path,json=repoPath,proto3" json:"repo_path,omitempty"`
+	// Base prompt file path (absolute or relative to repo_path).
+	PromptPath string `protobuf:"bytes,3,opt,name=prompt_path,json=promptPath,proto3" json:"prompt_path,omitempty"`
+	// Inline base prompt (instead of prompt_path).
+	PromptMsg string `protobuf:"bytes,4,opt,name=prompt_msg,json=promptMsg,proto3" json:"prompt_msg,omitempty"`
+	// Sleep between iterations.
+	Interval *durationpb.Duration `protobuf:"bytes,5,opt,name=interval,proto3" json:"interval,omitempty"`
+	// Maximum iterations before stopping (> 0).
+	MaxIterations int32 `protobuf:"varint,6,opt,name=max_iterations,json=maxIterations,proto3" json:"max_iterations,omitempty"`
+	// Maximum runtime before stopping (> 0).
+	MaxRuntime *durationpb.Duration `protobuf:"bytes,7,opt,name=max_runtime,json=maxRuntime,proto3" json:"max_runtime,omitempty"`
+	// Profile name or ID (optional).
+	Profile string `protobuf:"bytes,8,opt,name=profile,proto3" json:"profile,omitempty"`
+	// Pool name or ID (optional).
+	Pool string `protobuf:"bytes,9,opt,name=pool,proto3" json:"pool,omitempty"`
+	// Loop tags.
+	Tags []string `protobuf:"bytes,10,rep,name=tags,proto3" json:"tags,omitempty"`
+	// If true, start the loop after creating it.
+	Start         bool `protobuf:"varint,11,opt,name=start,proto3" json:"start,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *GetStatusRequest) Reset() {
-	*x = GetStatusRequest{}
-	mi := &file_forged_v1_forged_proto_msgTypes[32]
+func (x *CreateLoopRequest) Reset() {
+	*x = CreateLoopRequest{}
+	mi := &file_forged_v1_forged_proto_msgTypes[33]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *GetStatusRequest) String() string {
+func (x *CreateLoopRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*GetStatusRequest) ProtoMessage() {}
+func (*CreateLoopRequest) ProtoMessage() {}
 
-func (x *GetStatusRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_forged_v1_forged_proto_msgTypes[32]
+func (x *CreateLoopRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_forged_v1_forged_proto_msgTypes[33]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2718,90 +2935,110 @@ func (x *GetStatusRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use GetStatusRequest.ProtoReflect.Descriptor instead.
-func (*GetStatusRequest) Descriptor() ([]byte, []int) {
-	return file_forged_v1_forged_proto_rawDescGZIP(), []int{32}
+// Deprecated: Use CreateLoopRequest.ProtoReflect.Descriptor instead.
+func (*CreateLoopRequest) Descriptor() ([]byte, []int) {
+	return file_forged_v1_forged_proto_rawDescGZIP(), []int{33}
 }
 
-type GetStatusResponse struct {
-	state protoimpl.MessageState `protogen:"open.v1"`
-	// Daemon status details.
-	Status        *DaemonStatus `protobuf:"bytes,1,opt,name=status,proto3" json:"status,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+func (x *CreateLoopRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
 }
 
-func (x *GetStatusResponse) Reset() {
-	*x = GetStatusResponse{}
-	mi := &file_forged_v1_forged_proto_msgTypes[33]
-	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-	ms.StoreMessageInfo(mi)
+func (x *CreateLoopRequest) GetRepoPath() string {
+	if x != nil {
+		return x.RepoPath
+	}
+	return ""
 }
 
-func (x *GetStatusResponse) String() string {
-	return protoimpl.X.MessageStringOf(x)
+func (x *CreateLoopRequest) GetPromptPath() string {
+	if x != nil {
+		return x.PromptPath
+	}
+	return ""
 }
 
-func (*GetStatusResponse) ProtoMessage() {}
+func (x *CreateLoopRequest) GetPromptMsg() string {
+	if x != nil {
+		return x.PromptMsg
+	}
+	return ""
+}
 
-func (x *GetStatusResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_forged_v1_forged_proto_msgTypes[33]
+func (x *CreateLoopRequest) GetInterval() *durationpb.Duration {
 	if x != nil {
-		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-		if ms.LoadMessageInfo() == nil {
-			ms.StoreMessageInfo(mi)
-		}
-		return ms
+		return x.Interval
 	}
-	return mi.MessageOf(x)
+	return nil
 }
 
-// Deprecated: Use GetStatusResponse.ProtoReflect.Descriptor instead.
-func (*GetStatusResponse) Descriptor() ([]byte, []int) {
-	return file_forged_v1_forged_proto_rawDescGZIP(), []int{33}
+func (x *CreateLoopRequest) GetMaxIterations() int32 {
+	if x != nil {
+		return x.MaxIterations
+	}
+	return 0
 }
 
-func (x *GetStatusResponse) GetStatus() *DaemonStatus {
+func (x *CreateLoopRequest) GetMaxRuntime() *durationpb.Duration {
 	if x != nil {
-		return x.Status
+		return x.MaxRuntime
 	}
 	return nil
 }
 
-type DaemonStatus struct {
+func (x *CreateLoopRequest) GetProfile() string {
+	if x != nil {
+		return x.Profile
+	}
+	return ""
+}
+
+func (x *CreateLoopRequest) GetPool() string {
+	if x != nil {
+		return x.Pool
+	}
+	return ""
+}
+
+func (x *CreateLoopRequest) GetTags() []string {
+	if x != nil {
+		return x.Tags
+	}
+	return nil
+}
+
+func (x *CreateLoopRequest) GetStart() bool {
+	if x != nil {
+		return x.Start
+	}
+	return false
+}
+
+type CreateLoopResponse struct {
 	state protoimpl.MessageState `protogen:"open.v1"`
-	// Daemon version.
-	Version string `protobuf:"bytes,1,opt,name=version,proto3" json:"version,omitempty"`
-	// Node hostname.
-	Hostname string `protobuf:"bytes,2,opt,name=hostname,proto3" json:"hostname,omitempty"`
-	// When the daemon started.
-	StartedAt *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=started_at,json=startedAt,proto3" json:"started_at,omitempty"`
-	// Current uptime.
-	Uptime *durationpb.Duration `protobuf:"bytes,4,opt,name=uptime,proto3" json:"uptime,omitempty"`
-	// Number of managed agents.
-	AgentCount int32 `protobuf:"varint,5,opt,name=agent_count,json=agentCount,proto3" json:"agent_count,omitempty"`
-	// Resource usage.
-	Resources *ResourceUsage `protobuf:"bytes,6,opt,name=resources,proto3" json:"resources,omitempty"`
-	// Health status.
-	Health        *HealthStatus `protobuf:"bytes,7,opt,name=health,proto3" json:"health,omitempty"`
+	// The created loop.
+	Loop          *Loop `protobuf:"bytes,1,opt,name=loop,proto3" json:"loop,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *DaemonStatus) Reset() {
-	*x = DaemonStatus{}
+func (x *CreateLoopResponse) Reset() {
+	*x = CreateLoopResponse{}
 	mi := &file_forged_v1_forged_proto_msgTypes[34]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *DaemonStatus) String() string {
+func (x *CreateLoopResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*DaemonStatus) ProtoMessage() {}
+func (*CreateLoopResponse) ProtoMessage() {}
 
-func (x *DaemonStatus) ProtoReflect() protoreflect.Message {
+func (x *CreateLoopResponse) ProtoReflect() protoreflect.Message {
 	mi := &file_forged_v1_forged_proto_msgTypes[34]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -2813,14 +3050,981 @@ func (x *DaemonStatus) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use DaemonStatus.ProtoReflect.Descriptor instead.
-func (*DaemonStatus) Descriptor() ([]byte, []int) {
+// Deprecated: Use CreateLoopResponse.ProtoReflect.Descriptor instead.
+func (*CreateLoopResponse) Descriptor() ([]byte, []int) {
 	return file_forged_v1_forged_proto_rawDescGZIP(), []int{34}
 }
 
-func (x *DaemonStatus) GetVersion() string {
+func (x *CreateLoopResponse) GetLoop() *Loop {
 	if x != nil {
-		return x.Version
+		return x.Loop
+	}
+	return nil
+}
+
+type StartLoopRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Loop name or ID.
+	Loop          string `protobuf:"bytes,1,opt,name=loop,proto3" json:"loop,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StartLoopRequest) Reset() {
+	*x = StartLoopRequest{}
+	mi := &file_forged_v1_forged_proto_msgTypes[35]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StartLoopRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StartLoopRequest) ProtoMessage() {}
+
+func (x *StartLoopRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_forged_v1_forged_proto_msgTypes[35]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StartLoopRequest.ProtoReflect.Descriptor instead.
+func (*StartLoopRequest) Descriptor() ([]byte, []int) {
+	return file_forged_v1_forged_proto_rawDescGZIP(), []int{35}
+}
+
+func (x *StartLoopRequest) GetLoop() string {
+	if x != nil {
+		return x.Loop
+	}
+	return ""
+}
+
+type StartLoopResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The started loop.
+	Loop          *Loop `protobuf:"bytes,1,opt,name=loop,proto3" json:"loop,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StartLoopResponse) Reset() {
+	*x = StartLoopResponse{}
+	mi := &file_forged_v1_forged_proto_msgTypes[36]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StartLoopResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StartLoopResponse) ProtoMessage() {}
+
+func (x *StartLoopResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_forged_v1_forged_proto_msgTypes[36]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StartLoopResponse.ProtoReflect.Descriptor instead.
+func (*StartLoopResponse) Descriptor() ([]byte, []int) {
+	return file_forged_v1_forged_proto_rawDescGZIP(), []int{36}
+}
+
+func (x *StartLoopResponse) GetLoop() *Loop {
+	if x != nil {
+		return x.Loop
+	}
+	return nil
+}
+
+type StopLoopRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Loop name or ID.
+	Loop string `protobuf:"bytes,1,opt,name=loop,proto3" json:"loop,omitempty"`
+	// Why the loop is being stopped.
+	Reason        string `protobuf:"bytes,2,opt,name=reason,proto3" json:"reason,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StopLoopRequest) Reset() {
+	*x = StopLoopRequest{}
+	mi := &file_forged_v1_forged_proto_msgTypes[37]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StopLoopRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StopLoopRequest) ProtoMessage() {}
+
+func (x *StopLoopRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_forged_v1_forged_proto_msgTypes[37]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StopLoopRequest.ProtoReflect.Descriptor instead.
+func (*StopLoopRequest) Descriptor() ([]byte, []int) {
+	return file_forged_v1_forged_proto_rawDescGZIP(), []int{37}
+}
+
+func (x *StopLoopRequest) GetLoop() string {
+	if x != nil {
+		return x.Loop
+	}
+	return ""
+}
+
+func (x *StopLoopRequest) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+type StopLoopResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The loop after the stop request was queued.
+	Loop          *Loop `protobuf:"bytes,1,opt,name=loop,proto3" json:"loop,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StopLoopResponse) Reset() {
+	*x = StopLoopResponse{}
+	mi := &file_forged_v1_forged_proto_msgTypes[38]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StopLoopResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StopLoopResponse) ProtoMessage() {}
+
+func (x *StopLoopResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_forged_v1_forged_proto_msgTypes[38]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StopLoopResponse.ProtoReflect.Descriptor instead.
+func (*StopLoopResponse) Descriptor() ([]byte, []int) {
+	return file_forged_v1_forged_proto_rawDescGZIP(), []int{38}
+}
+
+func (x *StopLoopResponse) GetLoop() *Loop {
+	if x != nil {
+		return x.Loop
+	}
+	return nil
+}
+
+type KillLoopRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Loop name or ID.
+	Loop string `protobuf:"bytes,1,opt,name=loop,proto3" json:"loop,omitempty"`
+	// Why the loop is being killed.
+	Reason        string `protobuf:"bytes,2,opt,name=reason,proto3" json:"reason,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *KillLoopRequest) Reset() {
+	*x = KillLoopRequest{}
+	mi := &file_forged_v1_forged_proto_msgTypes[39]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *KillLoopRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*KillLoopRequest) ProtoMessage() {}
+
+func (x *KillLoopRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_forged_v1_forged_proto_msgTypes[39]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use KillLoopRequest.ProtoReflect.Descriptor instead.
+func (*KillLoopRequest) Descriptor() ([]byte, []int) {
+	return file_forged_v1_forged_proto_rawDescGZIP(), []int{39}
+}
+
+func (x *KillLoopRequest) GetLoop() string {
+	if x != nil {
+		return x.Loop
+	}
+	return ""
+}
+
+func (x *KillLoopRequest) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+type KillLoopResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The killed loop.
+	Loop          *Loop `protobuf:"bytes,1,opt,name=loop,proto3" json:"loop,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *KillLoopResponse) Reset() {
+	*x = KillLoopResponse{}
+	mi := &file_forged_v1_forged_proto_msgTypes[40]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *KillLoopResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*KillLoopResponse) ProtoMessage() {}
+
+func (x *KillLoopResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_forged_v1_forged_proto_msgTypes[40]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use KillLoopResponse.ProtoReflect.Descriptor instead.
+func (*KillLoopResponse) Descriptor() ([]byte, []int) {
+	return file_forged_v1_forged_proto_rawDescGZIP(), []int{40}
+}
+
+func (x *KillLoopResponse) GetLoop() *Loop {
+	if x != nil {
+		return x.Loop
+	}
+	return nil
+}
+
+type ListLoopsRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Filter by repository path (optional).
+	RepoPath string `protobuf:"bytes,1,opt,name=repo_path,json=repoPath,proto3" json:"repo_path,omitempty"`
+	// Filter by loop state (optional).
+	States []LoopState `protobuf:"varint,2,rep,packed,name=states,proto3,enum=forged.v1.LoopState" json:"states,omitempty"`
+	// Filter by tag (optional).
+	Tag           string `protobuf:"bytes,3,opt,name=tag,proto3" json:"tag,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListLoopsRequest) Reset() {
+	*x = ListLoopsRequest{}
+	mi := &file_forged_v1_forged_proto_msgTypes[41]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListLoopsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListLoopsRequest) ProtoMessage() {}
+
+func (x *ListLoopsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_forged_v1_forged_proto_msgTypes[41]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListLoopsRequest.ProtoReflect.Descriptor instead.
+func (*ListLoopsRequest) Descriptor() ([]byte, []int) {
+	return file_forged_v1_forged_proto_rawDescGZIP(), []int{41}
+}
+
+func (x *ListLoopsRequest) GetRepoPath() string {
+	if x != nil {
+		return x.RepoPath
+	}
+	return ""
+}
+
+func (x *ListLoopsRequest) GetStates() []LoopState {
+	if x != nil {
+		return x.States
+	}
+	return nil
+}
+
+func (x *ListLoopsRequest) GetTag() string {
+	if x != nil {
+		return x.Tag
+	}
+	return ""
+}
+
+type ListLoopsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Loops         []*Loop                `protobuf:"bytes,1,rep,name=loops,proto3" json:"loops,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListLoopsResponse) Reset() {
+	*x = ListLoopsResponse{}
+	mi := &file_forged_v1_forged_proto_msgTypes[42]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListLoopsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListLoopsResponse) ProtoMessage() {}
+
+func (x *ListLoopsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_forged_v1_forged_proto_msgTypes[42]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListLoopsResponse.ProtoReflect.Descriptor instead.
+func (*ListLoopsResponse) Descriptor() ([]byte, []int) {
+	return file_forged_v1_forged_proto_rawDescGZIP(), []int{42}
+}
+
+func (x *ListLoopsResponse) GetLoops() []*Loop {
+	if x != nil {
+		return x.Loops
+	}
+	return nil
+}
+
+type EnqueueLoopItemsRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Loop name or ID.
+	Loop string `protobuf:"bytes,1,opt,name=loop,proto3" json:"loop,omitempty"`
+	// Items to append, in order.
+	Items         []*LoopQueueItem `protobuf:"bytes,2,rep,name=items,proto3" json:"items,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *EnqueueLoopItemsRequest) Reset() {
+	*x = EnqueueLoopItemsRequest{}
+	mi := &file_forged_v1_forged_proto_msgTypes[43]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *EnqueueLoopItemsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*EnqueueLoopItemsRequest) ProtoMessage() {}
+
+func (x *EnqueueLoopItemsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_forged_v1_forged_proto_msgTypes[43]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use EnqueueLoopItemsRequest.ProtoReflect.Descriptor instead.
+func (*EnqueueLoopItemsRequest) Descriptor() ([]byte, []int) {
+	return file_forged_v1_forged_proto_rawDescGZIP(), []int{43}
+}
+
+func (x *EnqueueLoopItemsRequest) GetLoop() string {
+	if x != nil {
+		return x.Loop
+	}
+	return ""
+}
+
+func (x *EnqueueLoopItemsRequest) GetItems() []*LoopQueueItem {
+	if x != nil {
+		return x.Items
+	}
+	return nil
+}
+
+type EnqueueLoopItemsResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// IDs of the queued items.
+	ItemIds       []string `protobuf:"bytes,1,rep,name=item_ids,json=itemIds,proto3" json:"item_ids,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *EnqueueLoopItemsResponse) Reset() {
+	*x = EnqueueLoopItemsResponse{}
+	mi := &file_forged_v1_forged_proto_msgTypes[44]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *EnqueueLoopItemsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*EnqueueLoopItemsResponse) ProtoMessage() {}
+
+func (x *EnqueueLoopItemsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_forged_v1_forged_proto_msgTypes[44]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use EnqueueLoopItemsResponse.ProtoReflect.Descriptor instead.
+func (*EnqueueLoopItemsResponse) Descriptor() ([]byte, []int) {
+	return file_forged_v1_forged_proto_rawDescGZIP(), []int{44}
+}
+
+func (x *EnqueueLoopItemsResponse) GetItemIds() []string {
+	if x != nil {
+		return x.ItemIds
+	}
+	return nil
+}
+
+// LoopQueueItem is a loop queue entry.
+type LoopQueueItem struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Item type (message_append, next_prompt_override, pause, stop_graceful,
+	// kill_now, steer_message, config_update, trigger).
+	Type string `protobuf:"bytes,1,opt,name=type,proto3" json:"type,omitempty"`
+	// Type-specific JSON payload, as stored in the loop queue.
+	PayloadJson   string `protobuf:"bytes,2,opt,name=payload_json,json=payloadJson,proto3" json:"payload_json,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *LoopQueueItem) Reset() {
+	*x = LoopQueueItem{}
+	mi := &file_forged_v1_forged_proto_msgTypes[45]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LoopQueueItem) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LoopQueueItem) ProtoMessage() {}
+
+func (x *LoopQueueItem) ProtoReflect() protoreflect.Message {
+	mi := &file_forged_v1_forged_proto_msgTypes[45]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LoopQueueItem.ProtoReflect.Descriptor instead.
+func (*LoopQueueItem) Descriptor() ([]byte, []int) {
+	return file_forged_v1_forged_proto_rawDescGZIP(), []int{45}
+}
+
+func (x *LoopQueueItem) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *LoopQueueItem) GetPayloadJson() string {
+	if x != nil {
+		return x.PayloadJson
+	}
+	return ""
+}
+
+type StreamLoopLogsRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Loop name or ID.
+	Loop string `protobuf:"bytes,1,opt,name=loop,proto3" json:"loop,omitempty"`
+	// Number of existing lines to send first (0 = none, -1 = all).
+	TailLines int32 `protobuf:"varint,2,opt,name=tail_lines,json=tailLines,proto3" json:"tail_lines,omitempty"`
+	// If true, keep streaming new lines until the client disconnects.
+	Follow        bool `protobuf:"varint,3,opt,name=follow,proto3" json:"follow,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StreamLoopLogsRequest) Reset() {
+	*x = StreamLoopLogsRequest{}
+	mi := &file_forged_v1_forged_proto_msgTypes[46]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StreamLoopLogsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StreamLoopLogsRequest) ProtoMessage() {}
+
+func (x *StreamLoopLogsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_forged_v1_forged_proto_msgTypes[46]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StreamLoopLogsRequest.ProtoReflect.Descriptor instead.
+func (*StreamLoopLogsRequest) Descriptor() ([]byte, []int) {
+	return file_forged_v1_forged_proto_rawDescGZIP(), []int{46}
+}
+
+func (x *StreamLoopLogsRequest) GetLoop() string {
+	if x != nil {
+		return x.Loop
+	}
+	return ""
+}
+
+func (x *StreamLoopLogsRequest) GetTailLines() int32 {
+	if x != nil {
+		return x.TailLines
+	}
+	return 0
+}
+
+func (x *StreamLoopLogsRequest) GetFollow() bool {
+	if x != nil {
+		return x.Follow
+	}
+	return false
+}
+
+type StreamLoopLogsResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Loop ID.
+	LoopId string `protobuf:"bytes,1,opt,name=loop_id,json=loopId,proto3" json:"loop_id,omitempty"`
+	// Log lines in this chunk.
+	Lines         []string `protobuf:"bytes,2,rep,name=lines,proto3" json:"lines,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StreamLoopLogsResponse) Reset() {
+	*x = StreamLoopLogsResponse{}
+	mi := &file_forged_v1_forged_proto_msgTypes[47]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StreamLoopLogsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StreamLoopLogsResponse) ProtoMessage() {}
+
+func (x *StreamLoopLogsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_forged_v1_forged_proto_msgTypes[47]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StreamLoopLogsResponse.ProtoReflect.Descriptor instead.
+func (*StreamLoopLogsResponse) Descriptor() ([]byte, []int) {
+	return file_forged_v1_forged_proto_rawDescGZIP(), []int{47}
+}
+
+func (x *StreamLoopLogsResponse) GetLoopId() string {
+	if x != nil {
+		return x.LoopId
+	}
+	return ""
+}
+
+func (x *StreamLoopLogsResponse) GetLines() []string {
+	if x != nil {
+		return x.Lines
+	}
+	return nil
+}
+
+type Loop struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Unique identifier.
+	Id string `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	// Short identifier shown by `forge ps`.
+	ShortId string `protobuf:"bytes,2,opt,name=short_id,json=shortId,proto3" json:"short_id,omitempty"`
+	// Loop name.
+	Name string `protobuf:"bytes,3,opt,name=name,proto3" json:"name,omitempty"`
+	// Repository the loop works in.
+	RepoPath string `protobuf:"bytes,4,opt,name=repo_path,json=repoPath,proto3" json:"repo_path,omitempty"`
+	// Current state.
+	State LoopState `protobuf:"varint,5,opt,name=state,proto3,enum=forged.v1.LoopState" json:"state,omitempty"`
+	// Profile ID (if pinned).
+	ProfileId string `protobuf:"bytes,6,opt,name=profile_id,json=profileId,proto3" json:"profile_id,omitempty"`
+	// Pool ID (if using a pool).
+	PoolId string `protobuf:"bytes,7,opt,name=pool_id,json=poolId,proto3" json:"pool_id,omitempty"`
+	// Loop tags.
+	Tags []string `protobuf:"bytes,8,rep,name=tags,proto3" json:"tags,omitempty"`
+	// Runner process ID (0 when not running).
+	Pid int32 `protobuf:"varint,9,opt,name=pid,proto3" json:"pid,omitempty"`
+	// Whether this daemon supervises the runner process.
+	Supervised bool `protobuf:"varint,10,opt,name=supervised,proto3" json:"supervised,omitempty"`
+	// Last run timestamp.
+	LastRunAt *timestamppb.Timestamp `protobuf:"bytes,11,opt,name=last_run_at,json=lastRunAt,proto3" json:"last_run_at,omitempty"`
+	// Last error, if any.
+	LastError string `protobuf:"bytes,12,opt,name=last_error,json=lastError,proto3" json:"last_error,omitempty"`
+	// Log file path on the node.
+	LogPath string `protobuf:"bytes,13,opt,name=log_path,json=logPath,proto3" json:"log_path,omitempty"`
+	// When the loop was created.
+	CreatedAt     *timestamppb.Timestamp `protobuf:"bytes,14,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Loop) Reset() {
+	*x = Loop{}
+	mi := &file_forged_v1_forged_proto_msgTypes[48]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Loop) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Loop) ProtoMessage() {}
+
+func (x *Loop) ProtoReflect() protoreflect.Message {
+	mi := &file_forged_v1_forged_proto_msgTypes[48]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Loop.ProtoReflect.Descriptor instead.
+func (*Loop) Descriptor() ([]byte, []int) {
+	return file_forged_v1_forged_proto_rawDescGZIP(), []int{48}
+}
+
+func (x *Loop) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Loop) GetShortId() string {
+	if x != nil {
+		return x.ShortId
+	}
+	return ""
+}
+
+func (x *Loop) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Loop) GetRepoPath() string {
+	if x != nil {
+		return x.RepoPath
+	}
+	return ""
+}
+
+func (x *Loop) GetState() LoopState {
+	if x != nil {
+		return x.State
+	}
+	return LoopState_LOOP_STATE_UNSPECIFIED
+}
+
+func (x *Loop) GetProfileId() string {
+	if x != nil {
+		return x.ProfileId
+	}
+	return ""
+}
+
+func (x *Loop) GetPoolId() string {
+	if x != nil {
+		return x.PoolId
+	}
+	return ""
+}
+
+func (x *Loop) GetTags() []string {
+	if x != nil {
+		return x.Tags
+	}
+	return nil
+}
+
+func (x *Loop) GetPid() int32 {
+	if x != nil {
+		return x.Pid
+	}
+	return 0
+}
+
+func (x *Loop) GetSupervised() bool {
+	if x != nil {
+		return x.Supervised
+	}
+	return false
+}
+
+func (x *Loop) GetLastRunAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.LastRunAt
+	}
+	return nil
+}
+
+func (x *Loop) GetLastError() string {
+	if x != nil {
+		return x.LastError
+	}
+	return ""
+}
+
+func (x *Loop) GetLogPath() string {
+	if x != nil {
+		return x.LogPath
+	}
+	return ""
+}
+
+func (x *Loop) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+type GetStatusRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetStatusRequest) Reset() {
+	*x = GetStatusRequest{}
+	mi := &file_forged_v1_forged_proto_msgTypes[49]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetStatusRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetStatusRequest) ProtoMessage() {}
+
+func (x *GetStatusRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_forged_v1_forged_proto_msgTypes[49]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetStatusRequest.ProtoReflect.Descriptor instead.
+func (*GetStatusRequest) Descriptor() ([]byte, []int) {
+	return file_forged_v1_forged_proto_rawDescGZIP(), []int{49}
+}
+
+type GetStatusResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Daemon status details.
+	Status        *DaemonStatus `protobuf:"bytes,1,opt,name=status,proto3" json:"status,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetStatusResponse) Reset() {
+	*x = GetStatusResponse{}
+	mi := &file_forged_v1_forged_proto_msgTypes[50]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetStatusResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetStatusResponse) ProtoMessage() {}
+
+func (x *GetStatusResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_forged_v1_forged_proto_msgTypes[50]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetStatusResponse.ProtoReflect.Descriptor instead.
+func (*GetStatusResponse) Descriptor() ([]byte, []int) {
+	return file_forged_v1_forged_proto_rawDescGZIP(), []int{50}
+}
+
+func (x *GetStatusResponse) GetStatus() *DaemonStatus {
+	if x != nil {
+		return x.Status
+	}
+	return nil
+}
+
+type DaemonStatus struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Daemon version.
+	Version string `protobuf:"bytes,1,opt,name=version,proto3" json:"version,omitempty"`
+	// Node hostname.
+	Hostname string `protobuf:"bytes,2,opt,name=hostname,proto3" json:"hostname,omitempty"`
+	// When the daemon started.
+	StartedAt *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=started_at,json=startedAt,proto3" json:"started_at,omitempty"`
+	// Current uptime.
+	Uptime *durationpb.Duration `protobuf:"bytes,4,opt,name=uptime,proto3" json:"uptime,omitempty"`
+	// Number of managed agents.
+	AgentCount int32 `protobuf:"varint,5,opt,name=agent_count,json=agentCount,proto3" json:"agent_count,omitempty"`
+	// Resource usage.
+	Resources *ResourceUsage `protobuf:"bytes,6,opt,name=resources,proto3" json:"resources,omitempty"`
+	// Health status.
+	Health        *HealthStatus `protobuf:"bytes,7,opt,name=health,proto3" json:"health,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DaemonStatus) Reset() {
+	*x = DaemonStatus{}
+	mi := &file_forged_v1_forged_proto_msgTypes[51]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DaemonStatus) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DaemonStatus) ProtoMessage() {}
+
+func (x *DaemonStatus) ProtoReflect() protoreflect.Message {
+	mi := &file_forged_v1_forged_proto_msgTypes[51]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DaemonStatus.ProtoReflect.Descriptor instead.
+func (*DaemonStatus) Descriptor() ([]byte, []int) {
+	return file_forged_v1_forged_proto_rawDescGZIP(), []int{51}
+}
+
+func (x *DaemonStatus) GetVersion() string {
+	if x != nil {
+		return x.Version
 	}
 	return ""
 }
@@ -2883,7 +4087,7 @@ type ResourceUsage struct {
 
 func (x *ResourceUsage) Reset() {
 	*x = ResourceUsage{}
-	mi := &file_forged_v1_forged_proto_msgTypes[35]
+	mi := &file_forged_v1_forged_proto_msgTypes[52]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -2895,7 +4099,7 @@ func (x *ResourceUsage) String() string {
 func (*ResourceUsage) ProtoMessage() {}
 
 func (x *ResourceUsage) ProtoReflect() protoreflect.Message {
-	mi := &file_forged_v1_forged_proto_msgTypes[35]
+	mi := &file_forged_v1_forged_proto_msgTypes[52]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2908,7 +4112,7 @@ func (x *ResourceUsage) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ResourceUsage.ProtoReflect.Descriptor instead.
 func (*ResourceUsage) Descriptor() ([]byte, []int) {
-	return file_forged_v1_forged_proto_rawDescGZIP(), []int{35}
+	return file_forged_v1_forged_proto_rawDescGZIP(), []int{52}
 }
 
 func (x *ResourceUsage) GetCpuPercent() float64 {
@@ -2951,7 +4155,7 @@ type HealthStatus struct {
 
 func (x *HealthStatus) Reset() {
 	*x = HealthStatus{}
-	mi := &file_forged_v1_forged_proto_msgTypes[36]
+	mi := &file_forged_v1_forged_proto_msgTypes[53]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -2963,7 +4167,7 @@ func (x *HealthStatus) String() string {
 func (*HealthStatus) ProtoMessage() {}
 
 func (x *HealthStatus) ProtoReflect() protoreflect.Message {
-	mi := &file_forged_v1_forged_proto_msgTypes[36]
+	mi := &file_forged_v1_forged_proto_msgTypes[53]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2976,7 +4180,7 @@ func (x *HealthStatus) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use HealthStatus.ProtoReflect.Descriptor instead.
 func (*HealthStatus) Descriptor() ([]byte, []int) {
-	return file_forged_v1_forged_proto_rawDescGZIP(), []int{36}
+	return file_forged_v1_forged_proto_rawDescGZIP(), []int{53}
 }
 
 func (x *HealthStatus) GetHealth() Health {
@@ -3009,7 +4213,7 @@ type HealthCheck struct {
 
 func (x *HealthCheck) Reset() {
 	*x = HealthCheck{}
-	mi := &file_forged_v1_forged_proto_msgTypes[37]
+	mi := &file_forged_v1_forged_proto_msgTypes[54]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -3021,7 +4225,7 @@ func (x *HealthCheck) String() string {
 func (*HealthCheck) ProtoMessage() {}
 
 func (x *HealthCheck) ProtoReflect() protoreflect.Message {
-	mi := &file_forged_v1_forged_proto_msgTypes[37]
+	mi := &file_forged_v1_forged_proto_msgTypes[54]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -3034,7 +4238,7 @@ func (x *HealthCheck) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use HealthCheck.ProtoReflect.Descriptor instead.
 func (*HealthCheck) Descriptor() ([]byte, []int) {
-	return file_forged_v1_forged_proto_rawDescGZIP(), []int{37}
+	return file_forged_v1_forged_proto_rawDescGZIP(), []int{54}
 }
 
 func (x *HealthCheck) GetName() string {
@@ -3073,7 +4277,7 @@ type PingRequest struct {
 
 func (x *PingRequest) Reset() {
 	*x = PingRequest{}
-	mi := &file_forged_v1_forged_proto_msgTypes[38]
+	mi := &file_forged_v1_forged_proto_msgTypes[55]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -3085,7 +4289,7 @@ func (x *PingRequest) String() string {
 func (*PingRequest) ProtoMessage() {}
 
 func (x *PingRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_forged_v1_forged_proto_msgTypes[38]
+	mi := &file_forged_v1_forged_proto_msgTypes[55]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -3098,7 +4302,7 @@ func (x *PingRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use PingRequest.ProtoReflect.Descriptor instead.
 func (*PingRequest) Descriptor() ([]byte, []int) {
-	return file_forged_v1_forged_proto_rawDescGZIP(), []int{38}
+	return file_forged_v1_forged_proto_rawDescGZIP(), []int{55}
 }
 
 type PingResponse struct {
@@ -3113,7 +4317,7 @@ type PingResponse struct {
 
 func (x *PingResponse) Reset() {
 	*x = PingResponse{}
-	mi := &file_forged_v1_forged_proto_msgTypes[39]
+	mi := &file_forged_v1_forged_proto_msgTypes[56]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -3125,7 +4329,7 @@ func (x *PingResponse) String() string {
 func (*PingResponse) ProtoMessage() {}
 
 func (x *PingResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_forged_v1_forged_proto_msgTypes[39]
+	mi := &file_forged_v1_forged_proto_msgTypes[56]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -3138,7 +4342,7 @@ func (x *PingResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use PingResponse.ProtoReflect.Descriptor instead.
 func (*PingResponse) Descriptor() ([]byte, []int) {
-	return file_forged_v1_forged_proto_rawDescGZIP(), []int{39}
+	return file_forged_v1_forged_proto_rawDescGZIP(), []int{56}
 }
 
 func (x *PingResponse) GetTimestamp() *timestamppb.Timestamp {
@@ -3253,20 +4457,22 @@ const file_forged_v1_forged_proto_rawDesc = "" +
 	"\acontent\x18\x03 \x01(\tR\acontent\x12\x18\n" +
 	"\achanged\x18\x04 \x01(\bR\achanged\x128\n" +
 	"\ttimestamp\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\ttimestamp\x12<\n" +
-	"\x0edetected_state\x18\x06 \x01(\x0e2\x15.forged.v1.AgentStateR\rdetectedState\"\x9b\x01\n" +
+	"\x0edetected_state\x18\x06 \x01(\x0e2\x15.forged.v1.AgentStateR\rdetectedState\"\xb6\x01\n" +
 	"\x13StreamEventsRequest\x12\x16\n" +
 	"\x06cursor\x18\x01 \x01(\tR\x06cursor\x12*\n" +
 	"\x05types\x18\x02 \x03(\x0e2\x14.forged.v1.EventTypeR\x05types\x12\x1b\n" +
 	"\tagent_ids\x18\x03 \x03(\tR\bagentIds\x12#\n" +
-	"\rworkspace_ids\x18\x04 \x03(\tR\fworkspaceIds\">\n" +
+	"\rworkspace_ids\x18\x04 \x03(\tR\fworkspaceIds\x12\x19\n" +
+	"\bloop_ids\x18\x05 \x03(\tR\aloopIds\">\n" +
 	"\x14StreamEventsResponse\x12&\n" +
-	"\x05event\x18\x01 \x01(\v2\x10.forged.v1.EventR\x05event\"\xdb\x05\n" +
+	"\x05event\x18\x01 \x01(\v2\x10.forged.v1.EventR\x05event\"\xc3\x06\n" +
 	"\x05Event\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\tR\x02id\x12(\n" +
 	"\x04type\x18\x02 \x01(\x0e2\x14.forged.v1.EventTypeR\x04type\x128\n" +
 	"\ttimestamp\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\ttimestamp\x12\x19\n" +
 	"\bagent_id\x18\x04 \x01(\tR\aagentId\x12!\n" +
-	"\fworkspace_id\x18\x05 \x01(\tR\vworkspaceId\x12S\n" +
+	"\fworkspace_id\x18\x05 \x01(\tR\vworkspaceId\x12\x17\n" +
+	"\aloop_id\x18\x06 \x01(\tR\x06loopId\x12S\n" +
 	"\x13agent_state_changed\x18\n" +
 	" \x01(\v2!.forged.v1.AgentStateChangedEventH\x00R\x11agentStateChanged\x12@\n" +
 	"\fagent_output\x18\v \x01(\v2\x1b.forged.v1.AgentOutputEventH\x00R\vagentOutput\x12R\n" +
@@ -3274,7 +4480,8 @@ const file_forged_v1_forged_proto_rawDesc = "" +
 	"\x11approval_resolved\x18\r \x01(\v2 .forged.v1.ApprovalResolvedEventH\x00R\x10approvalResolved\x12-\n" +
 	"\x05error\x18\x0e \x01(\v2\x15.forged.v1.ErrorEventH\x00R\x05error\x12V\n" +
 	"\x14pane_content_changed\x18\x0f \x01(\v2\".forged.v1.PaneContentChangedEventH\x00R\x12paneContentChanged\x12R\n" +
-	"\x12resource_violation\x18\x10 \x01(\v2!.forged.v1.ResourceViolationEventH\x00R\x11resourceViolationB\t\n" +
+	"\x12resource_violation\x18\x10 \x01(\v2!.forged.v1.ResourceViolationEventH\x00R\x11resourceViolation\x12M\n" +
+	"\x11loop_run_finished\x18\x11 \x01(\v2\x1f.forged.v1.LoopRunFinishedEventH\x00R\x0floopRunFinishedB\t\n" +
 	"\apayload\"\xa2\x01\n" +
 	"\x16AgentStateChangedEvent\x12<\n" +
 	"\x0eprevious_state\x18\x01 \x01(\x0e2\x15.forged.v1.AgentStateR\rpreviousState\x122\n" +
@@ -3307,7 +4514,15 @@ const file_forged_v1_forged_proto_rawDesc = "" +
 	"\vlimit_value\x18\x03 \x01(\x01R\n" +
 	"limitValue\x12'\n" +
 	"\x0fviolation_count\x18\x04 \x01(\x05R\x0eviolationCount\x12A\n" +
-	"\faction_taken\x18\x05 \x01(\x0e2\x1e.forged.v1.ResourceLimitActionR\vactionTaken\"a\n" +
+	"\faction_taken\x18\x05 \x01(\x0e2\x1e.forged.v1.ResourceLimitActionR\vactionTaken\"\xea\x01\n" +
+	"\x14LoopRunFinishedEvent\x12\x1b\n" +
+	"\tloop_name\x18\x01 \x01(\tR\bloopName\x12\x15\n" +
+	"\x06run_id\x18\x02 \x01(\tR\x05runId\x12\x16\n" +
+	"\x06status\x18\x03 \x01(\tR\x06status\x12\x1b\n" +
+	"\texit_code\x18\x04 \x01(\x05R\bexitCode\x125\n" +
+	"\bduration\x18\x05 \x01(\v2\x19.google.protobuf.DurationR\bduration\x12\x1c\n" +
+	"\titeration\x18\x06 \x01(\x05R\titeration\x12\x14\n" +
+	"\x05error\x18\a \x01(\tR\x05error\"a\n" +
 	"\x17PaneContentChangedEvent\x12!\n" +
 	"\fcontent_hash\x18\x01 \x01(\tR\vcontentHash\x12#\n" +
 	"\rlines_changed\x18\x02 \x01(\x05R\flinesChanged\"\xb9\x01\n" +
@@ -3336,7 +4551,82 @@ const file_forged_v1_forged_proto_rawDesc = "" +
 	"\x06cursor\x18\x02 \x01(\tR\x06cursor\"h\n" +
 	"\x18StreamTranscriptResponse\x124\n" +
 	"\aentries\x18\x01 \x03(\v2\x1a.forged.v1.TranscriptEntryR\aentries\x12\x16\n" +
-	"\x06cursor\x18\x02 \x01(\tR\x06cursor\"\x12\n" +
+	"\x06cursor\x18\x02 \x01(\tR\x06cursor\"\xf6\x02\n" +
+	"\x11CreateLoopRequest\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x1b\n" +
+	"\trepo_path\x18\x02 \x01(\tR\brepoPath\x12\x1f\n" +
+	"\vprompt_path\x18\x03 \x01(\tR\n" +
+	"promptPath\x12\x1d\n" +
+	"\n" +
+	"prompt_msg\x18\x04 \x01(\tR\tpromptMsg\x125\n" +
+	"\binterval\x18\x05 \x01(\v2\x19.google.protobuf.DurationR\binterval\x12%\n" +
+	"\x0emax_iterations\x18\x06 \x01(\x05R\rmaxIterations\x12:\n" +
+	"\vmax_runtime\x18\a \x01(\v2\x19.google.protobuf.DurationR\n" +
+	"maxRuntime\x12\x18\n" +
+	"\aprofile\x18\b \x01(\tR\aprofile\x12\x12\n" +
+	"\x04pool\x18\t \x01(\tR\x04pool\x12\x12\n" +
+	"\x04tags\x18\n" +
+	" \x03(\tR\x04tags\x12\x14\n" +
+	"\x05start\x18\v \x01(\bR\x05start\"9\n" +
+	"\x12CreateLoopResponse\x12#\n" +
+	"\x04loop\x18\x01 \x01(\v2\x0f.forged.v1.LoopR\x04loop\"&\n" +
+	"\x10StartLoopRequest\x12\x12\n" +
+	"\x04loop\x18\x01 \x01(\tR\x04loop\"8\n" +
+	"\x11StartLoopResponse\x12#\n" +
+	"\x04loop\x18\x01 \x01(\v2\x0f.forged.v1.LoopR\x04loop\"=\n" +
+	"\x0fStopLoopRequest\x12\x12\n" +
+	"\x04loop\x18\x01 \x01(\tR\x04loop\x12\x16\n" +
+	"\x06reason\x18\x02 \x01(\tR\x06reason\"7\n" +
+	"\x10StopLoopResponse\x12#\n" +
+	"\x04loop\x18\x01 \x01(\v2\x0f.forged.v1.LoopR\x04loop\"=\n" +
+	"\x0fKillLoopRequest\x12\x12\n" +
+	"\x04loop\x18\x01 \x01(\tR\x04loop\x12\x16\n" +
+	"\x06reason\x18\x02 \x01(\tR\x06reason\"7\n" +
+	"\x10KillLoopResponse\x12#\n" +
+	"\x04loop\x18\x01 \x01(\v2\x0f.forged.v1.LoopR\x04loop\"o\n" +
+	"\x10ListLoopsRequest\x12\x1b\n" +
+	"\trepo_path\x18\x01 \x01(\tR\brepoPath\x12,\n" +
+	"\x06states\x18\x02 \x03(\x0e2\x14.forged.v1.LoopStateR\x06states\x12\x10\n" +
+	"\x03tag\x18\x03 \x01(\tR\x03tag\":\n" +
+	"\x11ListLoopsResponse\x12%\n" +
+	"\x05loops\x18\x01 \x03(\v2\x0f.forged.v1.LoopR\x05loops\"]\n" +
+	"\x17EnqueueLoopItemsRequest\x12\x12\n" +
+	"\x04loop\x18\x01 \x01(\tR\x04loop\x12.\n" +
+	"\x05items\x18\x02 \x03(\v2\x18.forged.v1.LoopQueueItemR\x05items\"5\n" +
+	"\x18EnqueueLoopItemsResponse\x12\x19\n" +
+	"\bitem_ids\x18\x01 \x03(\tR\aitemIds\"F\n" +
+	"\rLoopQueueItem\x12\x12\n" +
+	"\x04type\x18\x01 \x01(\tR\x04type\x12!\n" +
+	"\fpayload_json\x18\x02 \x01(\tR\vpayloadJson\"b\n" +
+	"\x15StreamLoopLogsRequest\x12\x12\n" +
+	"\x04loop\x18\x01 \x01(\tR\x04loop\x12\x1d\n" +
+	"\n" +
+	"tail_lines\x18\x02 \x01(\x05R\ttailLines\x12\x16\n" +
+	"\x06follow\x18\x03 \x01(\bR\x06follow\"G\n" +
+	"\x16StreamLoopLogsResponse\x12\x17\n" +
+	"\aloop_id\x18\x01 \x01(\tR\x06loopId\x12\x14\n" +
+	"\x05lines\x18\x02 \x03(\tR\x05lines\"\xbd\x03\n" +
+	"\x04Loop\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x19\n" +
+	"\bshort_id\x18\x02 \x01(\tR\ashortId\x12\x12\n" +
+	"\x04name\x18\x03 \x01(\tR\x04name\x12\x1b\n" +
+	"\trepo_path\x18\x04 \x01(\tR\brepoPath\x12*\n" +
+	"\x05state\x18\x05 \x01(\x0e2\x14.forged.v1.LoopStateR\x05state\x12\x1d\n" +
+	"\n" +
+	"profile_id\x18\x06 \x01(\tR\tprofileId\x12\x17\n" +
+	"\apool_id\x18\a \x01(\tR\x06poolId\x12\x12\n" +
+	"\x04tags\x18\b \x03(\tR\x04tags\x12\x10\n" +
+	"\x03pid\x18\t \x01(\x05R\x03pid\x12\x1e\n" +
+	"\n" +
+	"supervised\x18\n" +
+	" \x01(\bR\n" +
+	"supervised\x12:\n" +
+	"\vlast_run_at\x18\v \x01(\v2\x1a.google.protobuf.TimestampR\tlastRunAt\x12\x1d\n" +
+	"\n" +
+	"last_error\x18\f \x01(\tR\tlastError\x12\x19\n" +
+	"\blog_path\x18\r \x01(\tR\alogPath\x129\n" +
+	"\n" +
+	"created_at\x18\x0e \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\"\x12\n" +
 	"\x10GetStatusRequest\"D\n" +
 	"\x11GetStatusResponse\x12/\n" +
 	"\x06status\x18\x01 \x01(\v2\x17.forged.v1.DaemonStatusR\x06status\"\xbc\x02\n" +
@@ -3384,7 +4674,7 @@ const file_forged_v1_forged_proto_rawDesc = "" +
 	"\x12AGENT_STATE_PAUSED\x10\x05\x12\x18\n" +
 	"\x14AGENT_STATE_STOPPING\x10\x06\x12\x17\n" +
 	"\x13AGENT_STATE_STOPPED\x10\a\x12\x16\n" +
-	"\x12AGENT_STATE_FAILED\x10\b*\x8b\x02\n" +
+	"\x12AGENT_STATE_FAILED\x10\b*\xad\x02\n" +
 	"\tEventType\x12\x1a\n" +
 	"\x16EVENT_TYPE_UNSPECIFIED\x10\x00\x12\"\n" +
 	"\x1eEVENT_TYPE_AGENT_STATE_CHANGED\x10\x01\x12\x1b\n" +
@@ -3393,7 +4683,8 @@ const file_forged_v1_forged_proto_rawDesc = "" +
 	"\x1cEVENT_TYPE_APPROVAL_RESOLVED\x10\x04\x12\x14\n" +
 	"\x10EVENT_TYPE_ERROR\x10\x05\x12#\n" +
 	"\x1fEVENT_TYPE_PANE_CONTENT_CHANGED\x10\x06\x12!\n" +
-	"\x1dEVENT_TYPE_RESOURCE_VIOLATION\x10\a*^\n" +
+	"\x1dEVENT_TYPE_RESOURCE_VIOLATION\x10\a\x12 \n" +
+	"\x1cEVENT_TYPE_LOOP_RUN_FINISHED\x10\b*^\n" +
 	"\fResourceType\x12\x1d\n" +
 	"\x19RESOURCE_TYPE_UNSPECIFIED\x10\x00\x12\x15\n" +
 	"\x11RESOURCE_TYPE_CPU\x10\x01\x12\x18\n" +
@@ -3405,12 +4696,19 @@ const file_forged_v1_forged_proto_rawDesc = "" +
 	"\x1bTRANSCRIPT_ENTRY_TYPE_ERROR\x10\x03\x12&\n" +
 	"\"TRANSCRIPT_ENTRY_TYPE_STATE_CHANGE\x10\x04\x12\"\n" +
 	"\x1eTRANSCRIPT_ENTRY_TYPE_APPROVAL\x10\x05\x12$\n" +
-	" TRANSCRIPT_ENTRY_TYPE_USER_INPUT\x10\x06*_\n" +
+	" TRANSCRIPT_ENTRY_TYPE_USER_INPUT\x10\x06*\x9e\x01\n" +
+	"\tLoopState\x12\x1a\n" +
+	"\x16LOOP_STATE_UNSPECIFIED\x10\x00\x12\x16\n" +
+	"\x12LOOP_STATE_RUNNING\x10\x01\x12\x17\n" +
+	"\x13LOOP_STATE_SLEEPING\x10\x02\x12\x16\n" +
+	"\x12LOOP_STATE_WAITING\x10\x03\x12\x16\n" +
+	"\x12LOOP_STATE_STOPPED\x10\x04\x12\x14\n" +
+	"\x10LOOP_STATE_ERROR\x10\x05*_\n" +
 	"\x06Health\x12\x16\n" +
 	"\x12HEALTH_UNSPECIFIED\x10\x00\x12\x12\n" +
 	"\x0eHEALTH_HEALTHY\x10\x01\x12\x13\n" +
 	"\x0fHEALTH_DEGRADED\x10\x02\x12\x14\n" +
-	"\x10HEALTH_UNHEALTHY\x10\x032\xb1\a\n" +
+	"\x10HEALTH_UNHEALTHY\x10\x032\xcc\v\n" +
 	"\rForgedService\x12I\n" +
 	"\n" +
 	"SpawnAgent\x12\x1c.forged.v1.SpawnAgentRequest\x1a\x1d.forged.v1.SpawnAgentResponse\x12F\n" +
@@ -3423,7 +4721,15 @@ const file_forged_v1_forged_proto_rawDesc = "" +
 	"\x11StreamPaneUpdates\x12#.forged.v1.StreamPaneUpdatesRequest\x1a$.forged.v1.StreamPaneUpdatesResponse0\x01\x12Q\n" +
 	"\fStreamEvents\x12\x1e.forged.v1.StreamEventsRequest\x1a\x1f.forged.v1.StreamEventsResponse0\x01\x12R\n" +
 	"\rGetTranscript\x12\x1f.forged.v1.GetTranscriptRequest\x1a .forged.v1.GetTranscriptResponse\x12]\n" +
-	"\x10StreamTranscript\x12\".forged.v1.StreamTranscriptRequest\x1a#.forged.v1.StreamTranscriptResponse0\x01\x12F\n" +
+	"\x10StreamTranscript\x12\".forged.v1.StreamTranscriptRequest\x1a#.forged.v1.StreamTranscriptResponse0\x01\x12I\n" +
+	"\n" +
+	"CreateLoop\x12\x1c.forged.v1.CreateLoopRequest\x1a\x1d.forged.v1.CreateLoopResponse\x12F\n" +
+	"\tStartLoop\x12\x1b.forged.v1.StartLoopRequest\x1a\x1c.forged.v1.StartLoopResponse\x12C\n" +
+	"\bStopLoop\x12\x1a.forged.v1.StopLoopRequest\x1a\x1b.forged.v1.StopLoopResponse\x12C\n" +
+	"\bKillLoop\x12\x1a.forged.v1.KillLoopRequest\x1a\x1b.forged.v1.KillLoopResponse\x12F\n" +
+	"\tListLoops\x12\x1b.forged.v1.ListLoopsRequest\x1a\x1c.forged.v1.ListLoopsResponse\x12[\n" +
+	"\x10EnqueueLoopItems\x12\".forged.v1.EnqueueLoopItemsRequest\x1a#.forged.v1.EnqueueLoopItemsResponse\x12W\n" +
+	"\x0eStreamLoopLogs\x12 .forged.v1.StreamLoopLogsRequest\x1a!.forged.v1.StreamLoopLogsResponse0\x01\x12F\n" +
 	"\tGetStatus\x12\x1b.forged.v1.GetStatusRequest\x1a\x1c.forged.v1.GetStatusResponse\x127\n" +
 	"\x04Ping\x12\x16.forged.v1.PingRequest\x1a\x17.forged.v1.PingResponseB\x8c\x01\n" +
 	"\rcom.forged.v1B\vForgedProtoP\x01Z)github.com/tOgg1/forge/forged/v1;forgedv1\xa2\x02\x03FXX\xaa\x02\tForged.V1\xca\x02\tForged\\V1\xe2\x02\x15Forged\\V1\\GPBMetadata\xea\x02\n" +
@@ -3441,141 +4747,187 @@ func file_forged_v1_forged_proto_rawDescGZIP() []byte {
 	return file_forged_v1_forged_proto_rawDescData
 }
 
-var file_forged_v1_forged_proto_enumTypes = make([]protoimpl.EnumInfo, 6)
-var file_forged_v1_forged_proto_msgTypes = make([]protoimpl.MessageInfo, 42)
+var file_forged_v1_forged_proto_enumTypes = make([]protoimpl.EnumInfo, 7)
+var file_forged_v1_forged_proto_msgTypes = make([]protoimpl.MessageInfo, 59)
 var file_forged_v1_forged_proto_goTypes = []any{
 	(ResourceLimitAction)(0),          // 0: forged.v1.ResourceLimitAction
 	(AgentState)(0),                   // 1: forged.v1.AgentState
 	(EventType)(0),                    // 2: forged.v1.EventType
 	(ResourceType)(0),                 // 3: forged.v1.ResourceType
 	(TranscriptEntryType)(0),          // 4: forged.v1.TranscriptEntryType
-	(Health)(0),                       // 5: forged.v1.Health
-	(*SpawnAgentRequest)(nil),         // 6: forged.v1.SpawnAgentRequest
-	(*ResourceLimits)(nil),            // 7: forged.v1.ResourceLimits
-	(*SpawnAgentResponse)(nil),        // 8: forged.v1.SpawnAgentResponse
-	(*KillAgentRequest)(nil),          // 9: forged.v1.KillAgentRequest
-	(*KillAgentResponse)(nil),         // 10: forged.v1.KillAgentResponse
-	(*SendInputRequest)(nil),          // 11: forged.v1.SendInputRequest
-	(*SendInputResponse)(nil),         // 12: forged.v1.SendInputResponse
-	(*ListAgentsRequest)(nil),         // 13: forged.v1.ListAgentsRequest
-	(*ListAgentsResponse)(nil),        // 14: forged.v1.ListAgentsResponse
-	(*GetAgentRequest)(nil),           // 15: forged.v1.GetAgentRequest
-	(*GetAgentResponse)(nil),          // 16: forged.v1.GetAgentResponse
-	(*Agent)(nil),                     // 17: forged.v1.Agent
-	(*AgentResourceUsage)(nil),        // 18: forged.v1.AgentResourceUsage
-	(*CapturePaneRequest)(nil),        // 19: forged.v1.CapturePaneRequest
-	(*CapturePaneResponse)(nil),       // 20: forged.v1.CapturePaneResponse
-	(*StreamPaneUpdatesRequest)(nil),  // 21: forged.v1.StreamPaneUpdatesRequest
-	(*StreamPaneUpdatesResponse)(nil), // 22: forged.v1.StreamPaneUpdatesResponse
-	(*StreamEventsRequest)(nil),       // 23: forged.v1.StreamEventsRequest
-	(*StreamEventsResponse)(nil),      // 24: forged.v1.StreamEventsResponse
-	(*Event)(nil),                     // 25: forged.v1.Event
-	(*AgentStateChangedEvent)(nil),    // 26: forged.v1.AgentStateChangedEvent
-	(*AgentOutputEvent)(nil),          // 27: forged.v1.AgentOutputEvent
-	(*ApprovalRequestedEvent)(nil),    // 28: forged.v1.ApprovalRequestedEvent
-	(*ApprovalResolvedEvent)(nil),     // 29: forged.v1.ApprovalResolvedEvent
-	(*ErrorEvent)(nil),                // 30: forged.v1.ErrorEvent
-	(*ResourceViolationEvent)(nil),    // 31: forged.v1.ResourceViolationEvent
-	(*PaneContentChangedEvent)(nil),   // 32: forged.v1.PaneContentChangedEvent
-	(*GetTranscriptRequest)(nil),      // 33: forged.v1.GetTranscriptRequest
-	(*GetTranscriptResponse)(nil),     // 34: forged.v1.GetTranscriptResponse
-	(*TranscriptEntry)(nil),           // 35: forged.v1.TranscriptEntry
-	(*StreamTranscriptRequest)(nil),   // 36: forged.v1.StreamTranscriptRequest
-	(*StreamTranscriptResponse)(nil),  // 37: forged.v1.StreamTranscriptResponse
-	(*GetStatusRequest)(nil),          // 38: forged.v1.GetStatusRequest
-	(*GetStatusResponse)(nil),         // 39: forged.v1.GetStatusResponse
-	(*DaemonStatus)(nil),              // 40: forged.v1.DaemonStatus
-	(*ResourceUsage)(nil),             // 41: forged.v1.ResourceUsage
-	(*HealthStatus)(nil),              // 42: forged.v1.HealthStatus
-	(*HealthCheck)(nil),               // 43: forged.v1.HealthCheck
-	(*PingRequest)(nil),               // 44: forged.v1.PingRequest
-	(*PingResponse)(nil),              // 45: forged.v1.PingResponse
-	nil,                               // 46: forged.v1.SpawnAgentRequest.EnvEntry
-	nil,                               // 47: forged.v1.TranscriptEntry.MetadataEntry
-	(*durationpb.Duration)(nil),       // 48: google.protobuf.Duration
-	(*timestamppb.Timestamp)(nil),     // 49: google.protobuf.Timestamp
+	(LoopState)(0),                    // 5: forged.v1.LoopState
+	(Health)(0),                       // 6: forged.v1.Health
+	(*SpawnAgentRequest)(nil),         // 7: forged.v1.SpawnAgentRequest
+	(*ResourceLimits)(nil),            // 8: forged.v1.ResourceLimits
+	(*SpawnAgentResponse)(nil),        // 9: forged.v1.SpawnAgentResponse
+	(*KillAgentRequest)(nil),          // 10: forged.v1.KillAgentRequest
+	(*KillAgentResponse)(nil),         // 11: forged.v1.KillAgentResponse
+	(*SendInputRequest)(nil),          // 12: forged.v1.SendInputRequest
+	(*SendInputResponse)(nil),         // 13: forged.v1.SendInputResponse
+	(*ListAgentsRequest)(nil),         // 14: forged.v1.ListAgentsRequest
+	(*ListAgentsResponse)(nil),        // 15: forged.v1.ListAgentsResponse
+	(*GetAgentRequest)(nil),           // 16: forged.v1.GetAgentRequest
+	(*GetAgentResponse)(nil),          // 17: forged.v1.GetAgentResponse
+	(*Agent)(nil),                     // 18: forged.v1.Agent
+	(*AgentResourceUsage)(nil),        // 19: forged.v1.AgentResourceUsage
+	(*CapturePaneRequest)(nil),        // 20: forged.v1.CapturePaneRequest
+	(*CapturePaneResponse)(nil),       // 21: forged.v1.CapturePaneResponse
+	(*StreamPaneUpdatesRequest)(nil),  // 22: forged.v1.StreamPaneUpdatesRequest
+	(*StreamPaneUpdatesResponse)(nil), // 23: forged.v1.StreamPaneUpdatesResponse
+	(*StreamEventsRequest)(nil),       // 24: forged.v1.StreamEventsRequest
+	(*StreamEventsResponse)(nil),      // 25: forged.v1.StreamEventsResponse
+	(*Event)(nil),                     // 26: forged.v1.Event
+	(*AgentStateChangedEvent)(nil),    // 27: forged.v1.AgentStateChangedEvent
+	(*AgentOutputEvent)(nil),          // 28: forged.v1.AgentOutputEvent
+	(*ApprovalRequestedEvent)(nil),    // 29: forged.v1.ApprovalRequestedEvent
+	(*ApprovalResolvedEvent)(nil),     // 30: forged.v1.ApprovalResolvedEvent
+	(*ErrorEvent)(nil),                // 31: forged.v1.ErrorEvent
+	(*ResourceViolationEvent)(nil),    // 32: forged.v1.ResourceViolationEvent
+	(*LoopRunFinishedEvent)(nil),      // 33: forged.v1.LoopRunFinishedEvent
+	(*PaneContentChangedEvent)(nil),   // 34: forged.v1.PaneContentChangedEvent
+	(*GetTranscriptRequest)(nil),      // 35: forged.v1.GetTranscriptRequest
+	(*GetTranscriptResponse)(nil),     // 36: forged.v1.GetTranscriptResponse
+	(*TranscriptEntry)(nil),           // 37: forged.v1.TranscriptEntry
+	(*StreamTranscriptRequest)(nil),   // 38: forged.v1.StreamTranscriptRequest
+	(*StreamTranscriptResponse)(nil),  // 39: forged.v1.StreamTranscriptResponse
+	(*CreateLoopRequest)(nil),         // 40: forged.v1.CreateLoopRequest
+	(*CreateLoopResponse)(nil),        // 41: forged.v1.CreateLoopResponse
+	(*StartLoopRequest)(nil),          // 42: forged.v1.StartLoopRequest
+	(*StartLoopResponse)(nil),         // 43: forged.v1.StartLoopResponse
+	(*StopLoopRequest)(nil),           // 44: forged.v1.StopLoopRequest
+	(*StopLoopResponse)(nil),          // 45: forged.v1.StopLoopResponse
+	(*KillLoopRequest)(nil),           // 46: forged.v1.KillLoopRequest
+	(*KillLoopResponse)(nil),          // 47: forged.v1.KillLoopResponse
+	(*ListLoopsRequest)(nil),          // 48: forged.v1.ListLoopsRequest
+	(*ListLoopsResponse)(nil),         // 49: forged.v1.ListLoopsResponse
+	(*EnqueueLoopItemsRequest)(nil),   // 50: forged.v1.EnqueueLoopItemsRequest
+	(*EnqueueLoopItemsResponse)(nil),  // 51: forged.v1.EnqueueLoopItemsResponse
+	(*LoopQueueItem)(nil),             // 52: forged.v1.LoopQueueItem
+	(*StreamLoopLogsRequest)(nil),     // 53: forged.v1.StreamLoopLogsRequest
+	(*StreamLoopLogsResponse)(nil),    // 54: forged.v1.StreamLoopLogsResponse
+	(*Loop)(nil),                      // 55: forged.v1.Loop
+	(*GetStatusRequest)(nil),          // 56: forged.v1.GetStatusRequest
+	(*GetStatusResponse)(nil),         // 57: forged.v1.GetStatusResponse
+	(*DaemonStatus)(nil),              // 58: forged.v1.DaemonStatus
+	(*ResourceUsage)(nil),             // 59: forged.v1.ResourceUsage
+	(*HealthStatus)(nil),              // 60: forged.v1.HealthStatus
+	(*HealthCheck)(nil),               // 61: forged.v1.HealthCheck
+	(*PingRequest)(nil),               // 62: forged.v1.PingRequest
+	(*PingResponse)(nil),              // 63: forged.v1.PingResponse
+	nil,                               // 64: forged.v1.SpawnAgentRequest.EnvEntry
+	nil,                               // 65: forged.v1.TranscriptEntry.MetadataEntry
+	(*durationpb.Duration)(nil),       // 66: google.protobuf.Duration
+	(*timestamppb.Timestamp)(nil),     // 67: google.protobuf.Timestamp
 }
 var file_forged_v1_forged_proto_depIdxs = []int32{
-	46, // 0: forged.v1.SpawnAgentRequest.env:type_name -> forged.v1.SpawnAgentRequest.EnvEntry
-	7,  // 1: forged.v1.SpawnAgentRequest.resource_limits:type_name -> forged.v1.ResourceLimits
+	64, // 0: forged.v1.SpawnAgentRequest.env:type_name -> forged.v1.SpawnAgentRequest.EnvEntry
+	8,  // 1: forged.v1.SpawnAgentRequest.resource_limits:type_name -> forged.v1.ResourceLimits
 	0,  // 2: forged.v1.ResourceLimits.action:type_name -> forged.v1.ResourceLimitAction
-	48, // 3: forged.v1.ResourceLimits.grace_period:type_name -> google.protobuf.Duration
-	17, // 4: forged.v1.SpawnAgentResponse.agent:type_name -> forged.v1.Agent
-	48, // 5: forged.v1.KillAgentRequest.grace_period:type_name -> google.protobuf.Duration
+	66, // 3: forged.v1.ResourceLimits.grace_period:type_name -> google.protobuf.Duration
+	18, // 4: forged.v1.SpawnAgentResponse.agent:type_name -> forged.v1.Agent
+	66, // 5: forged.v1.KillAgentRequest.grace_period:type_name -> google.protobuf.Duration
 	1,  // 6: forged.v1.ListAgentsRequest.states:type_name -> forged.v1.AgentState
-	17, // 7: forged.v1.ListAgentsResponse.agents:type_name -> forged.v1.Agent
-	17, // 8: forged.v1.GetAgentResponse.agent:type_name -> forged.v1.Agent
+	18, // 7: forged.v1.ListAgentsResponse.agents:type_name -> forged.v1.Agent
+	18, // 8: forged.v1.GetAgentResponse.agent:type_name -> forged.v1.Agent
 	1,  // 9: forged.v1.Agent.state:type_name -> forged.v1.AgentState
-	49, // 10: forged.v1.Agent.spawned_at:type_name -> google.protobuf.Timestamp
-	49, // 11: forged.v1.Agent.last_activity_at:type_name -> google.protobuf.Timestamp
-	7,  // 12: forged.v1.Agent.resource_limits:type_name -> forged.v1.ResourceLimits
-	18, // 13: forged.v1.Agent.resource_usage:type_name -> forged.v1.AgentResourceUsage
-	49, // 14: forged.v1.AgentResourceUsage.measured_at:type_name -> google.protobuf.Timestamp
-	49, // 15: forged.v1.CapturePaneResponse.captured_at:type_name -> google.protobuf.Timestamp
-	48, // 16: forged.v1.StreamPaneUpdatesRequest.min_interval:type_name -> google.protobuf.Duration
-	49, // 17: forged.v1.StreamPaneUpdatesResponse.timestamp:type_name -> google.protobuf.Timestamp
+	67, // 10: forged.v1.Agent.spawned_at:type_name -> google.protobuf.Timestamp
+	67, // 11: forged.v1.Agent.last_activity_at:type_name -> google.protobuf.Timestamp
+	8,  // 12: forged.v1.Agent.resource_limits:type_name -> forged.v1.ResourceLimits
+	19, // 13: forged.v1.Agent.resource_usage:type_name -> forged.v1.AgentResourceUsage
+	67, // 14: forged.v1.AgentResourceUsage.measured_at:type_name -> google.protobuf.Timestamp
+	67, // 15: forged.v1.CapturePaneResponse.captured_at:type_name -> google.protobuf.Timestamp
+	66, // 16: forged.v1.StreamPaneUpdatesRequest.min_interval:type_name -> google.protobuf.Duration
+	67, // 17: forged.v1.StreamPaneUpdatesResponse.timestamp:type_name -> google.protobuf.Timestamp
 	1,  // 18: forged.v1.StreamPaneUpdatesResponse.detected_state:type_name -> forged.v1.AgentState
 	2,  // 19: forged.v1.StreamEventsRequest.types:type_name -> forged.v1.EventType
-	25, // 20: forged.v1.StreamEventsResponse.event:type_name -> forged.v1.Event
+	26, // 20: forged.v1.StreamEventsResponse.event:type_name -> forged.v1.Event
 	2,  // 21: forged.v1.Event.type:type_name -> forged.v1.EventType
-	49, // 22: forged.v1.Event.timestamp:type_name -> google.protobuf.Timestamp
-	26, // 23: forged.v1.Event.agent_state_changed:type_name -> forged.v1.AgentStateChangedEvent
-	27, // 24: forged.v1.Event.agent_output:type_name -> forged.v1.AgentOutputEvent
-	28, // 25: forged.v1.Event.approval_requested:type_name -> forged.v1.ApprovalRequestedEvent
-	29, // 26: forged.v1.Event.approval_resolved:type_name -> forged.v1.ApprovalResolvedEvent
-	30, // 27: forged.v1.Event.error:type_name -> forged.v1.ErrorEvent
-	32, // 28: forged.v1.Event.pane_content_changed:type_name -> forged.v1.PaneContentChangedEvent
-	31, // 29: forged.v1.Event.resource_violation:type_name -> forged.v1.ResourceViolationEvent
-	1,  // 30: forged.v1.AgentStateChangedEvent.previous_state:type_name -> forged.v1.AgentState
-	1,  // 31: forged.v1.AgentStateChangedEvent.new_state:type_name -> forged.v1.AgentState
-	3,  // 32: forged.v1.ResourceViolationEvent.resource_type:type_name -> forged.v1.ResourceType
-	0,  // 33: forged.v1.ResourceViolationEvent.action_taken:type_name -> forged.v1.ResourceLimitAction
-	49, // 34: forged.v1.GetTranscriptRequest.start_time:type_name -> google.protobuf.Timestamp
-	49, // 35: forged.v1.GetTranscriptRequest.end_time:type_name -> google.protobuf.Timestamp
-	35, // 36: forged.v1.GetTranscriptResponse.entries:type_name -> forged.v1.TranscriptEntry
-	49, // 37: forged.v1.TranscriptEntry.timestamp:type_name -> google.protobuf.Timestamp
-	4,  // 38: forged.v1.TranscriptEntry.type:type_name -> forged.v1.TranscriptEntryType
-	47, // 39: forged.v1.TranscriptEntry.metadata:type_name -> forged.v1.TranscriptEntry.MetadataEntry
-	35, // 40: forged.v1.StreamTranscriptResponse.entries:type_name -> forged.v1.TranscriptEntry
-	40, // 41: forged.v1.GetStatusResponse.status:type_name -> forged.v1.DaemonStatus
-	49, // 42: forged.v1.DaemonStatus.started_at:type_name -> google.protobuf.Timestamp
-	48, // 43: forged.v1.DaemonStatus.uptime:type_name -> google.protobuf.Duration
-	41, // 44: forged.v1.DaemonStatus.resources:type_name -> forged.v1.ResourceUsage
-	42, // 45: forged.v1.DaemonStatus.health:type_name -> forged.v1.HealthStatus
-	5,  // 46: forged.v1.HealthStatus.health:type_name -> forged.v1.Health
-	43, // 47: forged.v1.HealthStatus.checks:type_name -> forged.v1.HealthCheck
-	5,  // 48: forged.v1.HealthCheck.health:type_name -> forged.v1.Health
-	49, // 49: forged.v1.HealthCheck.last_check:type_name -> google.protobuf.Timestamp
-	49, // 50: forged.v1.PingResponse.timestamp:type_name -> google.protobuf.Timestamp
-	6,  // 51: forged.v1.ForgedService.SpawnAgent:input_type -> forged.v1.SpawnAgentRequest
-	9,  // 52: forged.v1.ForgedService.KillAgent:input_type -> forged.v1.KillAgentRequest
-	11, // 53: forged.v1.ForgedService.SendInput:input_type -> forged.v1.SendInputRequest
-	13, // 54: forged.v1.ForgedService.ListAgents:input_type -> forged.v1.ListAgentsRequest
-	15, // 55: forged.v1.ForgedService.GetAgent:input_type -> forged.v1.GetAgentRequest
-	19, // 56: forged.v1.ForgedService.CapturePane:input_type -> forged.v1.CapturePaneRequest
-	21, // 57: forged.v1.ForgedService.StreamPaneUpdates:input_type -> forged.v1.StreamPaneUpdatesRequest
-	23, // 58: forged.v1.ForgedService.StreamEvents:input_type -> forged.v1.StreamEventsRequest
-	33, // 59: forged.v1.ForgedService.GetTranscript:input_type -> forged.v1.GetTranscriptRequest
-	36, // 60: forged.v1.ForgedService.StreamTranscript:input_type -> forged.v1.StreamTranscriptRequest
-	38, // 61: forged.v1.ForgedService.GetStatus:input_type -> forged.v1.GetStatusRequest
-	44, // 62: forged.v1.ForgedService.Ping:input_type -> forged.v1.PingRequest
-	8,  // 63: forged.v1.ForgedService.SpawnAgent:output_type -> forged.v1.SpawnAgentResponse
-	10, // 64: forged.v1.ForgedService.KillAgent:output_type -> forged.v1.KillAgentResponse
-	12, // 65: forged.v1.ForgedService.SendInput:output_type -> forged.v1.SendInputResponse
-	14, // 66: forged.v1.ForgedService.ListAgents:output_type -> forged.v1.ListAgentsResponse
-	16, // 67: forged.v1.ForgedService.GetAgent:output_type -> forged.v1.GetAgentResponse
-	20, // 68: forged.v1.ForgedService.CapturePane:output_type -> forged.v1.CapturePaneResponse
-	22, // 69: forged.v1.ForgedService.StreamPaneUpdates:output_type -> forged.v1.StreamPaneUpdatesResponse
-	24, // 70: forged.v1.ForgedService.StreamEvents:output_type -> forged.v1.StreamEventsResponse
-	34, // 71: forged.v1.ForgedService.GetTranscript:output_type -> forged.v1.GetTranscriptResponse
-	37, // 72: forged.v1.ForgedService.StreamTranscript:output_type -> forged.v1.StreamTranscriptResponse
-	39, // 73: forged.v1.ForgedService.GetStatus:output_type -> forged.v1.GetStatusResponse
-	45, // 74: forged.v1.ForgedService.Ping:output_type -> forged.v1.PingResponse
-	63, // [63:75] is the sub-list for method output_type
-	51, // [51:63] is the sub-list for method input_type
-	51, // [51:51] is the sub-list for extension type_name
-	51, // [51:51] is the sub-list for extension extendee
-	0,  // [0:51] is the sub-list for field type_name
+	67, // 22: forged.v1.Event.timestamp:type_name -> google.protobuf.Timestamp
+	27, // 23: forged.v1.Event.agent_state_changed:type_name -> forged.v1.AgentStateChangedEvent
+	28, // 24: forged.v1.Event.agent_output:type_name -> forged.v1.AgentOutputEvent
+	29, // 25: forged.v1.Event.approval_requested:type_name -> forged.v1.ApprovalRequestedEvent
+	30, // 26: forged.v1.Event.approval_resolved:type_name -> forged.v1.ApprovalResolvedEvent
+	31, // 27: forged.v1.Event.error:type_name -> forged.v1.ErrorEvent
+	34, // 28: forged.v1.Event.pane_content_changed:type_name -> forged.v1.PaneContentChangedEvent
+	32, // 29: forged.v1.Event.resource_violation:type_name -> forged.v1.ResourceViolationEvent
+	33, // 30: forged.v1.Event.loop_run_finished:type_name -> forged.v1.LoopRunFinishedEvent
+	1,  // 31: forged.v1.AgentStateChangedEvent.previous_state:type_name -> forged.v1.AgentState
+	1,  // 32: forged.v1.AgentStateChangedEvent.new_state:type_name -> forged.v1.AgentState
+	3,  // 33: forged.v1.ResourceViolationEvent.resource_type:type_name -> forged.v1.ResourceType
+	0,  // 34: forged.v1.ResourceViolationEvent.action_taken:type_name -> forged.v1.ResourceLimitAction
+	66, // 35: forged.v1.LoopRunFinishedEvent.duration:type_name -> google.protobuf.Duration
+	67, // 36: forged.v1.GetTranscriptRequest.start_time:type_name -> google.protobuf.Timestamp
+	67, // 37: forged.v1.GetTranscriptRequest.end_time:type_name -> google.protobuf.Timestamp
+	37, // 38: forged.v1.GetTranscriptResponse.entries:type_name -> forged.v1.TranscriptEntry
+	67, // 39: forged.v1.TranscriptEntry.timestamp:type_name -> google.protobuf.Timestamp
+	4,  // 40: forged.v1.TranscriptEntry.type:type_name -> forged.v1.TranscriptEntryType
+	65, // 41: forged.v1.TranscriptEntry.metadata:type_name -> forged.v1.TranscriptEntry.MetadataEntry
+	37, // 42: forged.v1.StreamTranscriptResponse.entries:type_name -> forged.v1.TranscriptEntry
+	66, // 43: forged.v1.CreateLoopRequest.interval:type_name -> google.protobuf.Duration
+	66, // 44: forged.v1.CreateLoopRequest.max_runtime:type_name -> google.protobuf.Duration
+	55, // 45: forged.v1.CreateLoopResponse.loop:type_name -> forged.v1.Loop
+	55, // 46: forged.v1.StartLoopResponse.loop:type_name -> forged.v1.Loop
+	55, // 47: forged.v1.StopLoopResponse.loop:type_name -> forged.v1.Loop
+	55, // 48: forged.v1.KillLoopResponse.loop:type_name -> forged.v1.Loop
+	5,  // 49: forged.v1.ListLoopsRequest.states:type_name -> forged.v1.LoopState
+	55, // 50: forged.v1.ListLoopsResponse.loops:type_name -> forged.v1.Loop
+	52, // 51: forged.v1.EnqueueLoopItemsRequest.items:type_name -> forged.v1.LoopQueueItem
+	5,  // 52: forged.v1.Loop.state:type_name -> forged.v1.LoopState
+	67, // 53: forged.v1.Loop.last_run_at:type_name -> google.protobuf.Timestamp
+	67, // 54: forged.v1.Loop.created_at:type_name -> google.protobuf.Timestamp
+	58, // 55: forged.v1.GetStatusResponse.status:type_name -> forged.v1.DaemonStatus
+	67, // 56: forged.v1.DaemonStatus.started_at:type_name -> google.protobuf.Timestamp
+	66, // 57: forged.v1.DaemonStatus.uptime:type_name -> google.protobuf.Duration
+	59, // 58: forged.v1.DaemonStatus.resources:type_name -> forged.v1.ResourceUsage
+	60, // 59: forged.v1.DaemonStatus.health:type_name -> forged.v1.HealthStatus
+	6,  // 60: forged.v1.HealthStatus.health:type_name -> forged.v1.Health
+	61, // 61: forged.v1.HealthStatus.checks:type_name -> forged.v1.HealthCheck
+	6,  // 62: forged.v1.HealthCheck.health:type_name -> forged.v1.Health
+	67, // 63: forged.v1.HealthCheck.last_check:type_name -> google.protobuf.Timestamp
+	67, // 64: forged.v1.PingResponse.timestamp:type_name -> google.protobuf.Timestamp
+	7,  // 65: forged.v1.ForgedService.SpawnAgent:input_type -> forged.v1.SpawnAgentRequest
+	10, // 66: forged.v1.ForgedService.KillAgent:input_type -> forged.v1.KillAgentRequest
+	12, // 67: forged.v1.ForgedService.SendInput:input_type -> forged.v1.SendInputRequest
+	14, // 68: forged.v1.ForgedService.ListAgents:input_type -> forged.v1.ListAgentsRequest
+	16, // 69: forged.v1.ForgedService.GetAgent:input_type -> forged.v1.GetAgentRequest
+	20, // 70: forged.v1.ForgedService.CapturePane:input_type -> forged.v1.CapturePaneRequest
+	22, // 71: forged.v1.ForgedService.StreamPaneUpdates:input_type -> forged.v1.StreamPaneUpdatesRequest
+	24, // 72: forged.v1.ForgedService.StreamEvents:input_type -> forged.v1.StreamEventsRequest
+	35, // 73: forged.v1.ForgedService.GetTranscript:input_type -> forged.v1.GetTranscriptRequest
+	38, // 74: forged.v1.ForgedService.StreamTranscript:input_type -> forged.v1.StreamTranscriptRequest
+	40, // 75: forged.v1.ForgedService.CreateLoop:input_type -> forged.v1.CreateLoopRequest
+	42, // 76: forged.v1.ForgedService.StartLoop:input_type -> forged.v1.StartLoopRequest
+	44, // 77: forged.v1.ForgedService.StopLoop:input_type -> forged.v1.StopLoopRequest
+	46, // 78: forged.v1.ForgedService.KillLoop:input_type -> forged.v1.KillLoopRequest
+	48, // 79: forged.v1.ForgedService.ListLoops:input_type -> forged.v1.ListLoopsRequest
+	50, // 80: forged.v1.ForgedService.EnqueueLoopItems:input_type -> forged.v1.EnqueueLoopItemsRequest
+	53, // 81: forged.v1.ForgedService.StreamLoopLogs:input_type -> forged.v1.StreamLoopLogsRequest
+	56, // 82: forged.v1.ForgedService.GetStatus:input_type -> forged.v1.GetStatusRequest
+	62, // 83: forged.v1.ForgedService.Ping:input_type -> forged.v1.PingRequest
+	9,  // 84: forged.v1.ForgedService.SpawnAgent:output_type -> forged.v1.SpawnAgentResponse
+	11, // 85: forged.v1.ForgedService.KillAgent:output_type -> forged.v1.KillAgentResponse
+	13, // 86: forged.v1.ForgedService.SendInput:output_type -> forged.v1.SendInputResponse
+	15, // 87: forged.v1.ForgedService.ListAgents:output_type -> forged.v1.ListAgentsResponse
+	17, // 88: forged.v1.ForgedService.GetAgent:output_type -> forged.v1.GetAgentResponse
+	21, // 89: forged.v1.ForgedService.CapturePane:output_type -> forged.v1.CapturePaneResponse
+	23, // 90: forged.v1.ForgedService.StreamPaneUpdates:output_type -> forged.v1.StreamPaneUpdatesResponse
+	25, // 91: forged.v1.ForgedService.StreamEvents:output_type -> forged.v1.StreamEventsResponse
+	36, // 92: forged.v1.ForgedService.GetTranscript:output_type -> forged.v1.GetTranscriptResponse
+	39, // 93: forged.v1.ForgedService.StreamTranscript:output_type -> forged.v1.StreamTranscriptResponse
+	41, // 94: forged.v1.ForgedService.CreateLoop:output_type -> forged.v1.CreateLoopResponse
+	43, // 95: forged.v1.ForgedService.StartLoop:output_type -> forged.v1.StartLoopResponse
+	45, // 96: forged.v1.ForgedService.StopLoop:output_type -> forged.v1.StopLoopResponse
+	47, // 97: forged.v1.ForgedService.KillLoop:output_type -> forged.v1.KillLoopResponse
+	49, // 98: forged.v1.ForgedService.ListLoops:output_type -> forged.v1.ListLoopsResponse
+	51, // 99: forged.v1.ForgedService.EnqueueLoopItems:output_type -> forged.v1.EnqueueLoopItemsResponse
+	54, // 100: forged.v1.ForgedService.StreamLoopLogs:output_type -> forged.v1.StreamLoopLogsResponse
+	57, // 101: forged.v1.ForgedService.GetStatus:output_type -> forged.v1.GetStatusResponse
+	63, // 102: forged.v1.ForgedService.Ping:output_type -> forged.v1.PingResponse
+	84, // [84:103] is the sub-list for method output_type
+	65, // [65:84] is the sub-list for method input_type
+	65, // [65:65] is the sub-list for extension type_name
+	65, // [65:65] is the sub-list for extension extendee
+	0,  // [0:65] is the sub-list for field type_name
 }
 
 func init() { file_forged_v1_forged_proto_init() }
@@ -3591,14 +4943,15 @@ func file_forged_v1_forged_proto_init() {
 		(*Event_Error)(nil),
 		(*Event_PaneContentChanged)(nil),
 		(*Event_ResourceViolation)(nil),
+		(*Event_LoopRunFinished)(nil),
 	}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_forged_v1_forged_proto_rawDesc), len(file_forged_v1_forged_proto_rawDesc)),
-			NumEnums:      6,
-			NumMessages:   42,
+			NumEnums:      7,
+			NumMessages:   59,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
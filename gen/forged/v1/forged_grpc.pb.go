@@ -32,6 +32,13 @@ const (
 	ForgedService_StreamEvents_FullMethodName      = "/forged.v1.ForgedService/StreamEvents"
 	ForgedService_GetTranscript_FullMethodName     = "/forged.v1.ForgedService/GetTranscript"
 	ForgedService_StreamTranscript_FullMethodName  = "/forged.v1.ForgedService/StreamTranscript"
+	ForgedService_CreateLoop_FullMethodName        = "/forged.v1.ForgedService/CreateLoop"
+	ForgedService_StartLoop_FullMethodName         = "/forged.v1.ForgedService/StartLoop"
+	ForgedService_StopLoop_FullMethodName          = "/forged.v1.ForgedService/StopLoop"
+	ForgedService_KillLoop_FullMethodName          = "/forged.v1.ForgedService/KillLoop"
+	ForgedService_ListLoops_FullMethodName         = "/forged.v1.ForgedService/ListLoops"
+	ForgedService_EnqueueLoopItems_FullMethodName  = "/forged.v1.ForgedService/EnqueueLoopItems"
+	ForgedService_StreamLoopLogs_FullMethodName    = "/forged.v1.ForgedService/StreamLoopLogs"
 	ForgedService_GetStatus_FullMethodName         = "/forged.v1.ForgedService/GetStatus"
 	ForgedService_Ping_FullMethodName              = "/forged.v1.ForgedService/Ping"
 )
@@ -62,6 +69,20 @@ type ForgedServiceClient interface {
 	GetTranscript(ctx context.Context, in *GetTranscriptRequest, opts ...grpc.CallOption) (*GetTranscriptResponse, error)
 	// StreamTranscript streams transcript updates in real-time.
 	StreamTranscript(ctx context.Context, in *StreamTranscriptRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[StreamTranscriptResponse], error)
+	// CreateLoop creates a loop and optionally starts it.
+	CreateLoop(ctx context.Context, in *CreateLoopRequest, opts ...grpc.CallOption) (*CreateLoopResponse, error)
+	// StartLoop starts a loop runner process supervised by this daemon.
+	StartLoop(ctx context.Context, in *StartLoopRequest, opts ...grpc.CallOption) (*StartLoopResponse, error)
+	// StopLoop asks a loop to stop after its current iteration.
+	StopLoop(ctx context.Context, in *StopLoopRequest, opts ...grpc.CallOption) (*StopLoopResponse, error)
+	// KillLoop stops a loop immediately, killing its runner process.
+	KillLoop(ctx context.Context, in *KillLoopRequest, opts ...grpc.CallOption) (*KillLoopResponse, error)
+	// ListLoops returns the loops known to this node.
+	ListLoops(ctx context.Context, in *ListLoopsRequest, opts ...grpc.CallOption) (*ListLoopsResponse, error)
+	// EnqueueLoopItems appends items to a loop's queue.
+	EnqueueLoopItems(ctx context.Context, in *EnqueueLoopItemsRequest, opts ...grpc.CallOption) (*EnqueueLoopItemsResponse, error)
+	// StreamLoopLogs streams a loop's log lines, optionally following new output.
+	StreamLoopLogs(ctx context.Context, in *StreamLoopLogsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[StreamLoopLogsResponse], error)
 	// GetStatus returns daemon health and resource usage.
 	GetStatus(ctx context.Context, in *GetStatusRequest, opts ...grpc.CallOption) (*GetStatusResponse, error)
 	// Ping is a simple health check.
@@ -203,6 +224,85 @@ func (c *forgedServiceClient) StreamTranscript(ctx context.Context, in *StreamTr
 // This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
 type ForgedService_StreamTranscriptClient = grpc.ServerStreamingClient[StreamTranscriptResponse]
 
+func (c *forgedServiceClient) CreateLoop(ctx context.Context, in *CreateLoopRequest, opts ...grpc.CallOption) (*CreateLoopResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CreateLoopResponse)
+	err := c.cc.Invoke(ctx, ForgedService_CreateLoop_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *forgedServiceClient) StartLoop(ctx context.Context, in *StartLoopRequest, opts ...grpc.CallOption) (*StartLoopResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(StartLoopResponse)
+	err := c.cc.Invoke(ctx, ForgedService_StartLoop_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *forgedServiceClient) StopLoop(ctx context.Context, in *StopLoopRequest, opts ...grpc.CallOption) (*StopLoopResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(StopLoopResponse)
+	err := c.cc.Invoke(ctx, ForgedService_StopLoop_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *forgedServiceClient) KillLoop(ctx context.Context, in *KillLoopRequest, opts ...grpc.CallOption) (*KillLoopResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(KillLoopResponse)
+	err := c.cc.Invoke(ctx, ForgedService_KillLoop_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *forgedServiceClient) ListLoops(ctx context.Context, in *ListLoopsRequest, opts ...grpc.CallOption) (*ListLoopsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListLoopsResponse)
+	err := c.cc.Invoke(ctx, ForgedService_ListLoops_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *forgedServiceClient) EnqueueLoopItems(ctx context.Context, in *EnqueueLoopItemsRequest, opts ...grpc.CallOption) (*EnqueueLoopItemsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(EnqueueLoopItemsResponse)
+	err := c.cc.Invoke(ctx, ForgedService_EnqueueLoopItems_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *forgedServiceClient) StreamLoopLogs(ctx context.Context, in *StreamLoopLogsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[StreamLoopLogsResponse], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &ForgedService_ServiceDesc.Streams[3], ForgedService_StreamLoopLogs_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[StreamLoopLogsRequest, StreamLoopLogsResponse]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type ForgedService_StreamLoopLogsClient = grpc.ServerStreamingClient[StreamLoopLogsResponse]
+
 func (c *forgedServiceClient) GetStatus(ctx context.Context, in *GetStatusRequest, opts ...grpc.CallOption) (*GetStatusResponse, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(GetStatusResponse)
@@ -249,6 +349,20 @@ type ForgedServiceServer interface {
 	GetTranscript(context.Context, *GetTranscriptRequest) (*GetTranscriptResponse, error)
 	// StreamTranscript streams transcript updates in real-time.
 	StreamTranscript(*StreamTranscriptRequest, grpc.ServerStreamingServer[StreamTranscriptResponse]) error
+	// CreateLoop creates a loop and optionally starts it.
+	CreateLoop(context.Context, *CreateLoopRequest) (*CreateLoopResponse, error)
+	// StartLoop starts a loop runner process supervised by this daemon.
+	StartLoop(context.Context, *StartLoopRequest) (*StartLoopResponse, error)
+	// StopLoop asks a loop to stop after its current iteration.
+	StopLoop(context.Context, *StopLoopRequest) (*StopLoopResponse, error)
+	// KillLoop stops a loop immediately, killing its runner process.
+	KillLoop(context.Context, *KillLoopRequest) (*KillLoopResponse, error)
+	// ListLoops returns the loops known to this node.
+	ListLoops(context.Context, *ListLoopsRequest) (*ListLoopsResponse, error)
+	// EnqueueLoopItems appends items to a loop's queue.
+	EnqueueLoopItems(context.Context, *EnqueueLoopItemsRequest) (*EnqueueLoopItemsResponse, error)
+	// StreamLoopLogs streams a loop's log lines, optionally following new output.
+	StreamLoopLogs(*StreamLoopLogsRequest, grpc.ServerStreamingServer[StreamLoopLogsResponse]) error
 	// GetStatus returns daemon health and resource usage.
 	GetStatus(context.Context, *GetStatusRequest) (*GetStatusResponse, error)
 	// Ping is a simple health check.
@@ -293,6 +407,27 @@ func (UnimplementedForgedServiceServer) GetTranscript(context.Context, *GetTrans
 func (UnimplementedForgedServiceServer) StreamTranscript(*StreamTranscriptRequest, grpc.ServerStreamingServer[StreamTranscriptResponse]) error {
 	return status.Error(codes.Unimplemented, "method StreamTranscript not implemented")
 }
+func (UnimplementedForgedServiceServer) CreateLoop(context.Context, *CreateLoopRequest) (*CreateLoopResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method CreateLoop not implemented")
+}
+func (UnimplementedForgedServiceServer) StartLoop(context.Context, *StartLoopRequest) (*StartLoopResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method StartLoop not implemented")
+}
+func (UnimplementedForgedServiceServer) StopLoop(context.Context, *StopLoopRequest) (*StopLoopResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method StopLoop not implemented")
+}
+func (UnimplementedForgedServiceServer) KillLoop(context.Context, *KillLoopRequest) (*KillLoopResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method KillLoop not implemented")
+}
+func (UnimplementedForgedServiceServer) ListLoops(context.Context, *ListLoopsRequest) (*ListLoopsResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method ListLoops not implemented")
+}
+func (UnimplementedForgedServiceServer) EnqueueLoopItems(context.Context, *EnqueueLoopItemsRequest) (*EnqueueLoopItemsResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method EnqueueLoopItems not implemented")
+}
+func (UnimplementedForgedServiceServer) StreamLoopLogs(*StreamLoopLogsRequest, grpc.ServerStreamingServer[StreamLoopLogsResponse]) error {
+	return status.Error(codes.Unimplemented, "method StreamLoopLogs not implemented")
+}
 func (UnimplementedForgedServiceServer) GetStatus(context.Context, *GetStatusRequest) (*GetStatusResponse, error) {
 	return nil, status.Error(codes.Unimplemented, "method GetStatus not implemented")
 }
@@ -479,6 +614,125 @@ func _ForgedService_StreamTranscript_Handler(srv interface{}, stream grpc.Server
 // This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
 type ForgedService_StreamTranscriptServer = grpc.ServerStreamingServer[StreamTranscriptResponse]
 
+func _ForgedService_CreateLoop_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateLoopRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ForgedServiceServer).CreateLoop(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ForgedService_CreateLoop_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ForgedServiceServer).CreateLoop(ctx, req.(*CreateLoopRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ForgedService_StartLoop_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(StartLoopRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ForgedServiceServer).StartLoop(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ForgedService_StartLoop_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ForgedServiceServer).StartLoop(ctx, req.(*StartLoopRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ForgedService_StopLoop_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(StopLoopRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ForgedServiceServer).StopLoop(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ForgedService_StopLoop_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ForgedServiceServer).StopLoop(ctx, req.(*StopLoopRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ForgedService_KillLoop_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(KillLoopRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ForgedServiceServer).KillLoop(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ForgedService_KillLoop_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ForgedServiceServer).KillLoop(ctx, req.(*KillLoopRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ForgedService_ListLoops_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListLoopsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ForgedServiceServer).ListLoops(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ForgedService_ListLoops_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ForgedServiceServer).ListLoops(ctx, req.(*ListLoopsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ForgedService_EnqueueLoopItems_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(EnqueueLoopItemsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ForgedServiceServer).EnqueueLoopItems(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ForgedService_EnqueueLoopItems_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ForgedServiceServer).EnqueueLoopItems(ctx, req.(*EnqueueLoopItemsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ForgedService_StreamLoopLogs_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(StreamLoopLogsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(ForgedServiceServer).StreamLoopLogs(m, &grpc.GenericServerStream[StreamLoopLogsRequest, StreamLoopLogsResponse]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type ForgedService_StreamLoopLogsServer = grpc.ServerStreamingServer[StreamLoopLogsResponse]
+
 func _ForgedService_GetStatus_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(GetStatusRequest)
 	if err := dec(in); err != nil {
@@ -550,6 +804,30 @@ var ForgedService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "GetTranscript",
 			Handler:    _ForgedService_GetTranscript_Handler,
 		},
+		{
+			MethodName: "CreateLoop",
+			Handler:    _ForgedService_CreateLoop_Handler,
+		},
+		{
+			MethodName: "StartLoop",
+			Handler:    _ForgedService_StartLoop_Handler,
+		},
+		{
+			MethodName: "StopLoop",
+			Handler:    _ForgedService_StopLoop_Handler,
+		},
+		{
+			MethodName: "KillLoop",
+			Handler:    _ForgedService_KillLoop_Handler,
+		},
+		{
+			MethodName: "ListLoops",
+			Handler:    _ForgedService_ListLoops_Handler,
+		},
+		{
+			MethodName: "EnqueueLoopItems",
+			Handler:    _ForgedService_EnqueueLoopItems_Handler,
+		},
 		{
 			MethodName: "GetStatus",
 			Handler:    _ForgedService_GetStatus_Handler,
@@ -575,6 +853,11 @@ var ForgedService_ServiceDesc = grpc.ServiceDesc{
 			Handler:       _ForgedService_StreamTranscript_Handler,
 			ServerStreams: true,
 		},
+		{
+			StreamName:    "StreamLoopLogs",
+			Handler:       _ForgedService_StreamLoopLogs_Handler,
+			ServerStreams: true,
+		},
 	},
 	Metadata: "forged/v1/forged.proto",
 }
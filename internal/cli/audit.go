@@ -18,7 +18,7 @@ func init() {
 
 	auditCmd.Flags().StringVar(&auditEventTypes, "type", "", "filter by event type (comma-separated)")
 	auditCmd.Flags().StringVar(&auditActionTypes, "action", "", "alias for --type")
-	auditCmd.Flags().StringVar(&auditEntityType, "entity-type", "", "filter by entity type (node, workspace, agent, queue, account, system)")
+	auditCmd.Flags().StringVar(&auditEntityType, "entity-type", "", "filter by entity type (node, workspace, agent, queue, account, system, loop)")
 	auditCmd.Flags().StringVar(&auditEntityID, "entity-id", "", "filter by entity ID")
 	auditCmd.Flags().StringVar(&auditUntil, "until", "", "filter events before a time (same format as --since)")
 	auditCmd.Flags().StringVar(&auditCursor, "cursor", "", "start after this event ID")
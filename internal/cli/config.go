@@ -194,6 +194,27 @@ loop_defaults:
   # Default base prompt message content
   # prompt_msg: ""
 
+  # How long each run's full output and sent prompt are kept (0 = forever)
+  # Default: 336h
+  # run_output_max_age: 336h
+
+  # How many recent runs per loop keep their full output (0 = no limit)
+  # Default: 500
+  # run_output_max_runs: 500
+
+  # How often a running loop records a heartbeat
+  # Default: 30s
+  # heartbeat_interval: 30s
+
+  # Heartbeat age after which a loop is marked "error: orphaned"
+  # Default: 5m
+  # heartbeat_timeout: 5m
+
+  # Snapshot the git working tree before and after each run
+  # (see "forge runs diff" and "forge runs revert")
+  # Default: false
+  # checkpoints: false
+
 # =============================================================================
 # Scheduler Settings
 # =============================================================================
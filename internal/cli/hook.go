@@ -2,15 +2,18 @@
 package cli
 
 import (
+	"context"
 	"fmt"
 	"os"
-	"path/filepath"
 	"strings"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/spf13/cobra"
+	"github.com/tOgg1/forge/internal/db"
 	"github.com/tOgg1/forge/internal/hooks"
 	"github.com/tOgg1/forge/internal/models"
+	"github.com/tOgg1/forge/internal/vault"
 )
 
 var (
@@ -22,20 +25,39 @@ var (
 	hookEntityID string
 	hookTimeout  string
 	hookDisabled bool
+	hookRetry    bool
+	hookLogLimit int
+
+	hookPreset       string
+	hookTemplate     string
+	hookTemplateFile string
+	hookSign         bool
+	hookSecret       string
 )
 
 func init() {
 	rootCmd.AddCommand(hookCmd)
 	hookCmd.AddCommand(hookOnEventCmd)
+	hookCmd.AddCommand(hookLogCmd)
+	hookCmd.AddCommand(hookTestCmd)
+	hookCmd.AddCommand(hookReplayCmd)
 
 	hookOnEventCmd.Flags().StringVar(&hookCommand, "cmd", "", "command to execute for matching events")
 	hookOnEventCmd.Flags().StringVar(&hookURL, "url", "", "webhook URL to POST matching events")
 	hookOnEventCmd.Flags().StringSliceVar(&hookHeaders, "header", nil, "webhook header (key=value)")
 	hookOnEventCmd.Flags().StringVar(&hookTypes, "type", "", "filter by event type (comma-separated)")
-	hookOnEventCmd.Flags().StringVar(&hookEntity, "entity-type", "", "filter by entity type (node, workspace, agent, queue, account, system)")
+	hookOnEventCmd.Flags().StringVar(&hookEntity, "entity-type", "", "filter by entity type (node, workspace, agent, queue, account, system, loop)")
 	hookOnEventCmd.Flags().StringVar(&hookEntityID, "entity-id", "", "filter by entity ID")
 	hookOnEventCmd.Flags().StringVar(&hookTimeout, "timeout", hooks.DefaultTimeout.String(), "hook execution timeout (0 to disable)")
 	hookOnEventCmd.Flags().BoolVar(&hookDisabled, "disabled", false, "register hook as disabled")
+	hookOnEventCmd.Flags().BoolVar(&hookRetry, "retry", false, "retry the command when it fails (commands run once by default)")
+	hookOnEventCmd.Flags().StringVar(&hookPreset, "preset", "", "render the body for a chat webhook ("+strings.Join(hooks.PresetNames(), ", ")+")")
+	hookOnEventCmd.Flags().StringVar(&hookTemplate, "template", "", "Go template for the body (fields: .Event, .Payload, .Summary)")
+	hookOnEventCmd.Flags().StringVar(&hookTemplateFile, "template-file", "", "read the body template from a file")
+	hookOnEventCmd.Flags().BoolVar(&hookSign, "sign", false, "sign webhook bodies with a generated secret (printed once)")
+	hookOnEventCmd.Flags().StringVar(&hookSecret, "secret", "", "sign webhook bodies with this secret")
+
+	hookLogCmd.Flags().IntVar(&hookLogLimit, "limit", 20, "maximum deliveries to show (0 for all)")
 }
 
 var hookCmd = &cobra.Command{
@@ -50,7 +72,14 @@ var hookOnEventCmd = &cobra.Command{
 	Long: `Register a command or webhook to run when events match the filter.
 
 The hook is stored on disk and will be loaded whenever Forge executes commands
-that publish events.`,
+that publish events.
+
+By default the event is sent as JSON. --preset or --template renders the body
+instead, e.g. for Slack or Discord. Commands receive the body on stdin.
+
+With --sign or --secret, webhooks carry X-Forge-Timestamp and
+X-Forge-Signature headers. The signature is "sha256=" followed by the hex
+HMAC-SHA256 of "<timestamp>.<body>". The secret is stored in the vault.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		command := strings.TrimSpace(hookCommand)
 		url := strings.TrimSpace(hookURL)
@@ -58,6 +87,9 @@ that publish events.`,
 		if (command == "") == (url == "") {
 			return fmt.Errorf("exactly one of --cmd or --url is required")
 		}
+		if hookRetry && command == "" {
+			return fmt.Errorf("--retry requires --cmd; webhooks are always retried on transient failures")
+		}
 
 		if hookTimeout != "" {
 			if hookTimeout != "0" {
@@ -82,37 +114,222 @@ that publish events.`,
 			return err
 		}
 
+		template := hookTemplate
+		if hookTemplateFile != "" {
+			if template != "" {
+				return fmt.Errorf("--template and --template-file are mutually exclusive")
+			}
+			data, err := os.ReadFile(hookTemplateFile)
+			if err != nil {
+				return fmt.Errorf("failed to read template: %w", err)
+			}
+			template = string(data)
+		}
+
+		secret := strings.TrimSpace(hookSecret)
+		if (hookSign || secret != "") && url == "" {
+			return fmt.Errorf("--sign and --secret require --url")
+		}
+		generated := false
+		if hookSign && secret == "" {
+			if secret, err = hooks.NewSecret(); err != nil {
+				return err
+			}
+			generated = true
+		}
+
 		hook := hooks.Hook{
+			ID:          uuid.New().String(),
 			Kind:        hooks.KindCommand,
 			Command:     command,
 			URL:         url,
 			Headers:     headers,
+			Signed:      secret != "",
+			Preset:      strings.TrimSpace(hookPreset),
+			Template:    template,
 			EventTypes:  eventTypes,
 			EntityTypes: entityTypes,
 			EntityID:    strings.TrimSpace(hookEntityID),
 			Enabled:     !hookDisabled,
 			Timeout:     strings.TrimSpace(hookTimeout),
+			Retry:       hookRetry,
 		}
 		if url != "" {
 			hook.Kind = hooks.KindWebhook
 		}
+		if _, err := hooks.ParseTemplate(hook); err != nil {
+			return err
+		}
+
+		// Store the secret first so a signed hook never runs without one.
+		if hook.Signed {
+			if err := vault.StoreHookSecret(hookVaultPath(), hook.ID, secret); err != nil {
+				return err
+			}
+		}
 
 		store := hooks.NewStore(hookStorePath())
 		stored, err := store.Add(hook)
 		if err != nil {
+			if hook.Signed {
+				_ = vault.DeleteHookSecret(hookVaultPath(), hook.ID)
+			}
 			return err
 		}
 
 		if IsJSONOutput() || IsJSONLOutput() {
-			return WriteOutput(os.Stdout, stored)
+			result := struct {
+				hooks.Hook
+				SigningSecret string `json:"signing_secret,omitempty"`
+			}{Hook: stored}
+			if generated {
+				result.SigningSecret = secret
+			}
+			return WriteOutput(os.Stdout, result)
 		}
 
 		fmt.Printf("Hook registered: %s\n", stored.ID)
 		fmt.Printf("Store: %s\n", store.Path())
+		if generated {
+			fmt.Printf("Signing secret: %s\n", secret)
+			fmt.Println("Save the secret now; it cannot be shown again.")
+		}
 		return nil
 	},
 }
 
+var hookLogCmd = &cobra.Command{
+	Use:   "log <hook-id>",
+	Short: "Show delivery history for a hook",
+	Long: `Show recent deliveries for a hook, newest first.
+
+Failed webhook deliveries, and command hooks registered with --retry, are
+retried with exponential backoff by forged. A delivery that exhausts its
+retries, or fails with a permanent error such as a 4xx response or a failed
+command, is marked dead; send it again with "forge hook replay".`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		hook, err := hooks.NewStore(hookStorePath()).Get(args[0])
+		if err != nil {
+			return err
+		}
+
+		database, err := openDatabase()
+		if err != nil {
+			return err
+		}
+		defer database.Close()
+
+		deliveries, err := db.NewHookDeliveryRepository(database).ListByHook(context.Background(), hook.ID, hookLogLimit)
+		if err != nil {
+			return err
+		}
+
+		if IsJSONOutput() || IsJSONLOutput() {
+			return WriteOutput(os.Stdout, deliveries)
+		}
+
+		if len(deliveries) == 0 {
+			fmt.Fprintln(os.Stdout, "No deliveries found")
+			return nil
+		}
+
+		rows := make([][]string, 0, len(deliveries))
+		for _, delivery := range deliveries {
+			rows = append(rows, []string{
+				delivery.ID,
+				string(delivery.EventType),
+				string(delivery.Status),
+				fmt.Sprintf("%d", delivery.Attempts),
+				formatRelativeTime(delivery.CreatedAt),
+				formatHookDeliveryDetail(delivery),
+			})
+		}
+		return writeTable(os.Stdout, []string{"DELIVERY", "EVENT", "STATUS", "ATTEMPTS", "CREATED", "DETAIL"}, rows)
+	},
+}
+
+var hookTestCmd = &cobra.Command{
+	Use:   "test <hook-id>",
+	Short: "Send a test event to a hook",
+	Long: `Send a synthetic hook.test event to a hook and report the result.
+
+The delivery is recorded in the hook's log and retried like any other.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runHookDelivery(func(ctx context.Context, manager *hooks.Manager) (*models.HookDelivery, error) {
+			return manager.Test(ctx, args[0])
+		})
+	},
+}
+
+var hookReplayCmd = &cobra.Command{
+	Use:   "replay <delivery-id>",
+	Short: "Send a past delivery again",
+	Long: `Send the event of an earlier delivery to its hook again, for example
+after a dead-lettered delivery's endpoint is fixed. The replay is recorded as
+a new delivery.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runHookDelivery(func(ctx context.Context, manager *hooks.Manager) (*models.HookDelivery, error) {
+			return manager.Replay(ctx, args[0])
+		})
+	},
+}
+
+// newHookManager returns a hook manager that queues deliveries in database.
+func newHookManager(database *db.DB) *hooks.Manager {
+	store := hooks.NewStore(hookStorePath())
+	executor := hooks.NewExecutor(hooks.WithVaultPath(hookVaultPath()))
+	return hooks.NewManager(store, executor, hooks.WithDeliveryRepository(db.NewHookDeliveryRepository(database)))
+}
+
+func runHookDelivery(send func(context.Context, *hooks.Manager) (*models.HookDelivery, error)) error {
+	database, err := openDatabase()
+	if err != nil {
+		return err
+	}
+	defer database.Close()
+
+	delivery, err := send(context.Background(), newHookManager(database))
+	if err != nil {
+		return err
+	}
+
+	if IsJSONOutput() || IsJSONLOutput() {
+		return WriteOutput(os.Stdout, delivery)
+	}
+
+	fmt.Printf("Delivery: %s\n", delivery.ID)
+	fmt.Printf("Status: %s\n", delivery.Status)
+	if detail := formatHookDeliveryDetail(delivery); detail != "" {
+		fmt.Printf("Detail: %s\n", detail)
+	}
+	if delivery.Status != models.HookDeliveryDelivered {
+		return fmt.Errorf("hook delivery failed")
+	}
+	return nil
+}
+
+func formatHookDeliveryDetail(delivery *models.HookDelivery) string {
+	switch delivery.Status {
+	case models.HookDeliveryDelivered:
+		if delivery.ReplayOf != "" {
+			return "replay of " + delivery.ReplayOf
+		}
+		return ""
+	case models.HookDeliveryPending:
+		if delivery.NextAttemptAt != nil && delivery.LastError != "" {
+			when := formatTimeUntil(*delivery.NextAttemptAt)
+			if when == "expired" {
+				when = "due"
+			}
+			return fmt.Sprintf("retry %s: %s", when, truncate(delivery.LastError, 80))
+		}
+	}
+	return truncate(delivery.LastError, 80)
+}
+
 func parseEntityTypes(raw string) ([]models.EntityType, error) {
 	trimmed := strings.TrimSpace(raw)
 	if trimmed == "" {
@@ -122,7 +339,7 @@ func parseEntityTypes(raw string) ([]models.EntityType, error) {
 	entity := models.EntityType(trimmed)
 	switch entity {
 	case models.EntityTypeNode, models.EntityTypeWorkspace, models.EntityTypeAgent,
-		models.EntityTypeQueue, models.EntityTypeAccount, models.EntityTypeSystem:
+		models.EntityTypeQueue, models.EntityTypeAccount, models.EntityTypeSystem, models.EntityTypeLoop:
 		return []models.EntityType{entity}, nil
 	default:
 		return nil, fmt.Errorf("invalid entity type: %s", trimmed)
@@ -155,16 +372,18 @@ func parseHeaders(values []string) (map[string]string, error) {
 }
 
 func hookStorePath() string {
+	configDir := ""
 	if cfg := GetConfig(); cfg != nil {
-		if strings.TrimSpace(cfg.Global.ConfigDir) != "" {
-			return filepath.Join(cfg.Global.ConfigDir, "hooks.json")
-		}
+		configDir = cfg.Global.ConfigDir
 	}
+	return hooks.StorePath(configDir)
+}
 
-	homeDir, err := os.UserHomeDir()
-	if err != nil || homeDir == "" {
-		return "hooks.json"
+// hookVaultPath returns the vault hook signing secrets are stored in. It is
+// the forged token vault, so forged finds the secrets when it retries.
+func hookVaultPath() string {
+	if cfg := GetConfig(); cfg != nil {
+		return cfg.TokenVaultPath()
 	}
-
-	return filepath.Join(homeDir, ".config", "forge", "hooks.json")
+	return vault.DefaultVaultPath()
 }
@@ -0,0 +1,73 @@
+package cli
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/tOgg1/forge/internal/hooks"
+	"github.com/tOgg1/forge/internal/models"
+	"github.com/tOgg1/forge/internal/vault"
+)
+
+func TestSignedHookUsesConfiguredVault(t *testing.T) {
+	tmpDir := t.TempDir()
+	restore := withTempConfig(t, tmpDir)
+	defer restore()
+	customVault := filepath.Join(tmpDir, "custom-vault")
+	appConfig.Forged.Auth.VaultPath = customVault
+
+	type received struct {
+		body      []byte
+		signature string
+		timestamp string
+	}
+	requests := make(chan received, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		requests <- received{body: body, signature: r.Header.Get(hooks.SignatureHeader), timestamp: r.Header.Get(hooks.TimestampHeader)}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	hookURL = server.URL
+	hookSecret = "s3cret"
+	hookTimeout = hooks.DefaultTimeout.String()
+	defer func() {
+		hookURL = ""
+		hookSecret = ""
+	}()
+	if err := hookOnEventCmd.RunE(hookOnEventCmd, nil); err != nil {
+		t.Fatalf("hook on-event: %v", err)
+	}
+
+	stored, err := hooks.NewStore(hookStorePath()).List()
+	if err != nil || len(stored) != 1 {
+		t.Fatalf("expected one stored hook, got %v (%v)", stored, err)
+	}
+	if secret, err := vault.HookSecret(customVault, stored[0].ID); err != nil || secret != "s3cret" {
+		t.Fatalf("expected the secret in the configured vault, got %q (%v)", secret, err)
+	}
+
+	database, err := openDatabase()
+	if err != nil {
+		t.Fatalf("open database: %v", err)
+	}
+	defer database.Close()
+
+	delivery, err := newHookManager(database).Test(context.Background(), stored[0].ID)
+	if err != nil {
+		t.Fatalf("hook test: %v", err)
+	}
+	if delivery.Status != models.HookDeliveryDelivered {
+		t.Fatalf("expected a delivered hook, got %s (%s)", delivery.Status, delivery.LastError)
+	}
+	got := <-requests
+	if err := hooks.VerifySignature("s3cret", got.signature, got.timestamp, got.body, 5*time.Minute, time.Now()); err != nil {
+		t.Fatalf("VerifySignature: %v", err)
+	}
+}
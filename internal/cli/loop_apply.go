@@ -0,0 +1,458 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/tOgg1/forge/internal/db"
+	"github.com/tOgg1/forge/internal/loop"
+	"github.com/tOgg1/forge/internal/models"
+)
+
+var (
+	applyFiles          []string
+	applyDryRun         bool
+	applyRestartStopped bool
+)
+
+func init() {
+	rootCmd.AddCommand(applyCmd)
+
+	applyCmd.Flags().StringArrayVarP(&applyFiles, "file", "f", nil, "loop spec file or directory (repeatable; default .forge/loops)")
+	applyCmd.Flags().BoolVar(&applyDryRun, "dry-run", false, "print the plan without changing any loop")
+	applyCmd.Flags().BoolVar(&applyRestartStopped, "restart-stopped", false, "also restart stopped loops whose spec did not change")
+}
+
+const (
+	applyActionCreate    = "create"
+	applyActionStart     = "start"
+	applyActionUpdate    = "update"
+	applyActionStop      = "stop"
+	applyActionUnchanged = "unchanged"
+	applyActionSkip      = "skip"
+)
+
+var applyCmd = &cobra.Command{
+	Use:   "apply",
+	Short: "Converge loops to the specs in .forge/loops",
+	Long: `Converge the repo's loops to declarative specs.
+
+Each spec file (.forge/loops/*.yaml) declares a named set of identical loops:
+count, pool or profile, prompt, interval, limits, tags, restart policy,
+isolation and stop rules. forge apply compares them with the loops in the
+database and:
+- creates and starts missing loops
+- restarts errored loops, and stopped loops whose spec changed, with the
+  spec's settings
+- skips stopped loops whose spec did not change, since they finished on their
+  own (max iterations, stop rules, budget); --restart-stopped starts them too
+- queues a settings update for running loops that drifted from their spec
+- gracefully stops loops beyond the spec's count, or whose spec file was removed
+
+Loops are named <name>, <name>-2, <name>-3, ... so changing the count never
+renames existing loops. With -f, only the given files are applied and loops of
+other specs are left alone.`,
+	Example: `  forge apply --dry-run
+  forge apply
+  forge apply --restart-stopped
+  forge apply -f .forge/loops/review.yaml`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := context.Background()
+		repoPath, err := resolveRepoPath("")
+		if err != nil {
+			return err
+		}
+
+		paths := applyFiles
+		prune := len(paths) == 0
+		if prune {
+			paths = []string{loop.SpecDir(repoPath)}
+		}
+		specs, err := loop.LoadSpecs(paths...)
+		if err != nil {
+			return err
+		}
+		if len(specs) == 0 && !prune {
+			return fmt.Errorf("no loop specs found in %s", strings.Join(paths, ", "))
+		}
+
+		database, err := openDatabase()
+		if err != nil {
+			return err
+		}
+		defer database.Close()
+
+		actions, err := planApply(ctx, database, repoPath, specs, prune)
+		if err != nil {
+			return err
+		}
+		if !applyDryRun {
+			if err := executeApply(ctx, database, repoPath, actions); err != nil {
+				return err
+			}
+		}
+
+		if IsJSONOutput() || IsJSONLOutput() {
+			return WriteOutput(os.Stdout, map[string]any{"dry_run": applyDryRun, "actions": actions})
+		}
+		if IsQuiet() {
+			return nil
+		}
+		if len(actions) == 0 {
+			fmt.Fprintf(os.Stdout, "No loop specs found in %s\n", filepath.Join(".forge", "loops"))
+			return nil
+		}
+		if applyDryRun {
+			fmt.Fprintln(os.Stdout, "Plan (dry run):")
+		}
+		rows := make([][]string, 0, len(actions))
+		for _, action := range actions {
+			rows = append(rows, []string{action.Action, action.Loop, action.Spec, action.details()})
+		}
+		return writeTable(os.Stdout, []string{"ACTION", "LOOP", "SPEC", "DETAILS"}, rows)
+	},
+}
+
+type applyAction struct {
+	Action  string   `json:"action"`
+	Spec    string   `json:"spec"`
+	Loop    string   `json:"loop"`
+	LoopID  string   `json:"loop_id,omitempty"`
+	Changes []string `json:"changes,omitempty"`
+	Reason  string   `json:"reason,omitempty"`
+
+	config    models.LoopConfigPayload
+	isolation models.LoopIsolation
+	entry     *models.Loop
+}
+
+func (a *applyAction) details() string {
+	parts := make([]string, 0, 2)
+	if len(a.Changes) > 0 {
+		parts = append(parts, strings.Join(a.Changes, ", "))
+	}
+	if a.Reason != "" {
+		parts = append(parts, a.Reason)
+	}
+	if len(parts) == 0 {
+		return "-"
+	}
+	return strings.Join(parts, "; ")
+}
+
+// planApply diffs the desired loops of specs against the loops in the
+// database. With prune, loops of specs that are no longer defined are stopped.
+func planApply(ctx context.Context, database *db.DB, repoPath string, specs []*loop.Spec, prune bool) ([]*applyAction, error) {
+	loopRepo := db.NewLoopRepository(database)
+	queueRepo := db.NewLoopQueueRepository(database)
+
+	loops, err := loopRepo.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	byName := make(map[string]*models.Loop, len(loops))
+	for _, entry := range loops {
+		byName[entry.Name] = entry
+	}
+
+	actions := make([]*applyAction, 0)
+	desired := make(map[string]map[string]struct{}, len(specs))
+	for _, spec := range specs {
+		cfg, isolation, err := resolveSpecConfig(ctx, database, repoPath, spec)
+		if err != nil {
+			return nil, fmt.Errorf("loop spec %q: %w", spec.Name, err)
+		}
+
+		names := make(map[string]struct{}, spec.Count)
+		desired[spec.Name] = names
+		for _, name := range spec.LoopNames() {
+			names[name] = struct{}{}
+			action := &applyAction{Spec: spec.Name, Loop: name, config: cfg, isolation: isolation}
+			actions = append(actions, action)
+
+			existing, ok := byName[name]
+			if !ok {
+				action.Action = applyActionCreate
+				continue
+			}
+			if loop.SpecName(existing) != spec.Name || !loopInRepo(existing, repoPath) {
+				return nil, fmt.Errorf("loop %q already exists and is not managed by spec %q", name, spec.Name)
+			}
+			action.LoopID = existing.ID
+			action.entry = existing
+
+			current := loop.LoopConfig(existing)
+			if pending, err := pendingConfigUpdate(ctx, queueRepo, existing.ID); err != nil {
+				return nil, err
+			} else if pending != nil {
+				current = *pending
+			}
+			action.Changes = loop.LoopConfigChanges(current, cfg)
+
+			switch {
+			case existing.State == models.LoopStateError:
+				action.Action = applyActionStart
+				action.Reason = string(existing.State)
+			case existing.State == models.LoopStateStopped && (len(action.Changes) > 0 || applyRestartStopped):
+				action.Action = applyActionStart
+				action.Reason = string(existing.State)
+			case existing.State == models.LoopStateStopped:
+				action.Action = applyActionSkip
+				action.Reason = stoppedLoopReason(existing) + "; use --restart-stopped to start it again"
+			case len(action.Changes) > 0:
+				action.Action = applyActionUpdate
+			default:
+				action.Action = applyActionUnchanged
+			}
+		}
+	}
+
+	for _, entry := range loops {
+		specName := loop.SpecName(entry)
+		if specName == "" || !loopInRepo(entry, repoPath) {
+			continue
+		}
+		if entry.State == models.LoopStateStopped || entry.State == models.LoopStateError {
+			continue
+		}
+		reason := ""
+		if names, ok := desired[specName]; ok {
+			if _, keep := names[entry.Name]; keep {
+				continue
+			}
+			reason = "beyond spec count"
+		} else if prune {
+			reason = "spec removed"
+		} else {
+			continue
+		}
+		if stopping, err := hasPendingQueueItem(ctx, queueRepo, entry.ID, models.LoopQueueItemStopGraceful); err != nil {
+			return nil, err
+		} else if stopping {
+			continue
+		}
+		actions = append(actions, &applyAction{Action: applyActionStop, Spec: specName, Loop: entry.Name, LoopID: entry.ID, Reason: reason, entry: entry})
+	}
+
+	sort.SliceStable(actions, func(i, j int) bool {
+		if actions[i].Spec != actions[j].Spec {
+			return actions[i].Spec < actions[j].Spec
+		}
+		return actions[i].Loop < actions[j].Loop
+	})
+	return actions, nil
+}
+
+// stoppedLoopReason describes why a loop stopped, for the plan output.
+func stoppedLoopReason(entry *models.Loop) string {
+	if entry.LastError != "" {
+		return "stopped: " + entry.LastError
+	}
+	return "stopped"
+}
+
+func executeApply(ctx context.Context, database *db.DB, repoPath string, actions []*applyAction) error {
+	loopRepo := db.NewLoopRepository(database)
+	queueRepo := db.NewLoopQueueRepository(database)
+
+	for _, action := range actions {
+		switch action.Action {
+		case applyActionCreate:
+			loopEntry := &models.Loop{
+				Name:     action.Loop,
+				RepoPath: repoPath,
+				State:    models.LoopStateStopped,
+				Metadata: map[string]any{loop.LoopSpecKey: action.Spec},
+			}
+			loop.ApplyLoopConfig(loopEntry, action.config)
+			if err := createLoop(ctx, loopRepo, loopEntry, action.isolation); err != nil {
+				return fmt.Errorf("create loop %q: %w", action.Loop, err)
+			}
+			action.LoopID = loopEntry.ID
+		case applyActionStart:
+			// The spec is written to the loop directly, so an update queued by
+			// an earlier apply must not roll it back when the runner starts.
+			if err := skipPendingConfigUpdates(ctx, queueRepo, action.LoopID); err != nil {
+				return err
+			}
+			loop.ApplyLoopConfig(action.entry, action.config)
+			if err := loopRepo.Update(ctx, action.entry); err != nil {
+				return err
+			}
+			if err := startLoopProcessFunc(action.entry.ID); err != nil {
+				return err
+			}
+		case applyActionUpdate:
+			payload, err := json.Marshal(action.config)
+			if err != nil {
+				return err
+			}
+			item := &models.LoopQueueItem{Type: models.LoopQueueItemConfigUpdate, Payload: payload}
+			if err := queueRepo.Enqueue(ctx, action.LoopID, item); err != nil {
+				return err
+			}
+		case applyActionStop:
+			payload, err := json.Marshal(models.StopPayload{Reason: "apply: " + action.Reason})
+			if err != nil {
+				return err
+			}
+			item := &models.LoopQueueItem{Type: models.LoopQueueItemStopGraceful, Payload: payload}
+			if err := queueRepo.Enqueue(ctx, action.LoopID, item); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// resolveSpecConfig turns a spec into loop settings, applying the same defaults
+// and validation as forge up.
+func resolveSpecConfig(ctx context.Context, database *db.DB, repoPath string, spec *loop.Spec) (models.LoopConfigPayload, models.LoopIsolation, error) {
+	cfg := GetConfig()
+	var out models.LoopConfigPayload
+
+	interval, err := parseDuration(spec.Interval, cfg.LoopDefaults.Interval)
+	if err != nil {
+		return out, "", err
+	}
+	if interval < 0 {
+		return out, "", fmt.Errorf("interval must be >= 0")
+	}
+	maxRuntime, err := parseDuration(spec.MaxRuntime, 0)
+	if err != nil {
+		return out, "", err
+	}
+	if maxRuntime <= 0 {
+		return out, "", fmt.Errorf("max_runtime must be > 0")
+	}
+	restartPolicy, err := parseRestartPolicy(spec.Restart)
+	if err != nil {
+		return out, "", err
+	}
+	if spec.MaxTokens < 0 || spec.MaxCost < 0 {
+		return out, "", fmt.Errorf("max_tokens and max_cost must be >= 0")
+	}
+	schedule, err := parseSchedule(spec.Schedule)
+	if err != nil {
+		return out, "", err
+	}
+	isolation, err := parseIsolation(spec.Isolate)
+	if err != nil {
+		return out, "", err
+	}
+
+	out.BasePromptMsg = strings.TrimSpace(spec.PromptMsg)
+	if out.BasePromptMsg == "" {
+		out.BasePromptMsg = strings.TrimSpace(cfg.LoopDefaults.PromptMsg)
+	}
+	prompt := spec.Prompt
+	if prompt == "" {
+		prompt = cfg.LoopDefaults.Prompt
+	}
+	if prompt != "" {
+		resolved, _, err := resolvePromptPath(repoPath, prompt)
+		if err != nil {
+			return out, "", err
+		}
+		out.BasePromptPath = resolved
+	}
+
+	if spec.Pool != "" {
+		pool, err := resolvePoolByRef(ctx, db.NewPoolRepository(database), spec.Pool)
+		if err != nil {
+			return out, "", err
+		}
+		out.PoolID = pool.ID
+	}
+	if spec.Profile != "" {
+		profile, err := resolveProfileByRef(ctx, db.NewProfileRepository(database), spec.Profile)
+		if err != nil {
+			return out, "", err
+		}
+		out.ProfileID = profile.ID
+	}
+
+	stopCfg, err := buildStopConfig(repoPath, spec.Stop)
+	if err != nil {
+		return out, "", err
+	}
+	if !stopCfg.Empty() {
+		out.StopConfig = &stopCfg
+	}
+	chainCfg, err := buildChainConfig(spec.ChainSpec)
+	if err != nil {
+		return out, "", err
+	}
+	if !chainCfg.Empty() {
+		out.ChainConfig = &chainCfg
+	}
+
+	out.IntervalSeconds = int(interval.Round(time.Second).Seconds())
+	out.MaxIterations = spec.MaxIterations
+	out.MaxRuntimeSeconds = int(maxRuntime.Round(time.Second).Seconds())
+	out.MaxTokens = spec.MaxTokens
+	out.MaxCostUSD = spec.MaxCost
+	out.Tags = parseTags(strings.Join(spec.Tags, ","))
+	out.RestartPolicy = restartPolicy
+	out.Schedule = schedule
+	return out, isolation, nil
+}
+
+// pendingConfigUpdate returns the latest settings update still queued for a loop.
+func pendingConfigUpdate(ctx context.Context, repo *db.LoopQueueRepository, loopID string) (*models.LoopConfigPayload, error) {
+	items, err := repo.List(ctx, loopID)
+	if err != nil {
+		return nil, err
+	}
+	var latest *models.LoopConfigPayload
+	for _, item := range items {
+		if item.Status != models.LoopQueueStatusPending || item.Type != models.LoopQueueItemConfigUpdate {
+			continue
+		}
+		var payload models.LoopConfigPayload
+		if err := json.Unmarshal(item.Payload, &payload); err != nil {
+			return nil, err
+		}
+		latest = &payload
+	}
+	return latest, nil
+}
+
+// skipPendingConfigUpdates marks the settings updates still queued for a loop
+// as skipped.
+func skipPendingConfigUpdates(ctx context.Context, repo *db.LoopQueueRepository, loopID string) error {
+	items, err := repo.List(ctx, loopID)
+	if err != nil {
+		return err
+	}
+	for _, item := range items {
+		if item.Status != models.LoopQueueStatusPending || item.Type != models.LoopQueueItemConfigUpdate {
+			continue
+		}
+		if err := repo.UpdateStatus(ctx, item.ID, models.LoopQueueStatusSkipped, "superseded by forge apply"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func hasPendingQueueItem(ctx context.Context, repo *db.LoopQueueRepository, loopID string, itemType models.LoopQueueItemType) (bool, error) {
+	items, err := repo.List(ctx, loopID)
+	if err != nil {
+		return false, err
+	}
+	for _, item := range items {
+		if item.Status == models.LoopQueueStatusPending && item.Type == itemType {
+			return true, nil
+		}
+	}
+	return false, nil
+}
@@ -0,0 +1,244 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/tOgg1/forge/internal/config"
+	"github.com/tOgg1/forge/internal/db"
+	"github.com/tOgg1/forge/internal/loop"
+	"github.com/tOgg1/forge/internal/models"
+)
+
+func TestApplyConvergesLoopsToSpecs(t *testing.T) {
+	tmpDir := t.TempDir()
+	specDir := filepath.Join(tmpDir, ".forge", "loops")
+	if err := os.MkdirAll(specDir, 0o755); err != nil {
+		t.Fatalf("mkdir spec dir: %v", err)
+	}
+	writeSpec := func(content string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(specDir, "review.yaml"), []byte(content), 0o644); err != nil {
+			t.Fatalf("write spec: %v", err)
+		}
+	}
+
+	originalCfg := appConfig
+	cfg := config.DefaultConfig()
+	cfg.Global.DataDir = filepath.Join(tmpDir, "data")
+	cfg.Global.ConfigDir = filepath.Join(tmpDir, "config")
+	appConfig = cfg
+	defer func() { appConfig = originalCfg }()
+
+	if err := os.MkdirAll(cfg.Global.DataDir, 0o755); err != nil {
+		t.Fatalf("mkdir data dir: %v", err)
+	}
+
+	originalWd, _ := os.Getwd()
+	if err := os.Chdir(tmpDir); err != nil {
+		t.Fatalf("chdir: %v", err)
+	}
+	defer func() { _ = os.Chdir(originalWd) }()
+
+	started := 0
+	originalStart := startLoopProcessFunc
+	startLoopProcessFunc = func(string) error { started++; return nil }
+	defer func() { startLoopProcessFunc = originalStart }()
+
+	applyFiles = nil
+	defer func() { applyDryRun = false }()
+
+	writeSpec(`name: review
+count: 2
+prompt_msg: review the code
+interval: 10s
+max_iterations: 5
+max_runtime: 1h
+tags: [review]
+stop:
+  quantitative:
+    cmd: "test -f DONE"
+`)
+
+	applyDryRun = true
+	if err := applyCmd.RunE(applyCmd, nil); err != nil {
+		t.Fatalf("apply --dry-run: %v", err)
+	}
+	if started != 0 {
+		t.Fatalf("dry run started %d loops", started)
+	}
+
+	applyDryRun = false
+	if err := applyCmd.RunE(applyCmd, nil); err != nil {
+		t.Fatalf("apply: %v", err)
+	}
+	if started != 2 {
+		t.Fatalf("expected 2 loops started, got %d", started)
+	}
+
+	database, err := openDatabase()
+	if err != nil {
+		t.Fatalf("open database: %v", err)
+	}
+	defer database.Close()
+
+	ctx := context.Background()
+	loopRepo := db.NewLoopRepository(database)
+	queueRepo := db.NewLoopQueueRepository(database)
+	for _, name := range []string{"review", "review-2"} {
+		loopEntry, err := loopRepo.GetByName(ctx, name)
+		if err != nil {
+			t.Fatalf("get loop %s: %v", name, err)
+		}
+		if loop.SpecName(loopEntry) != "review" || loopEntry.IntervalSeconds != 10 || loopEntry.MaxIterations != 5 {
+			t.Fatalf("unexpected loop %s: %+v", name, loopEntry)
+		}
+		if loop.LoopConfig(loopEntry).StopConfig == nil {
+			t.Fatalf("expected stop config on %s", name)
+		}
+		// Pretend the runners are up.
+		loopEntry.State = models.LoopStateSleeping
+		if err := loopRepo.Update(ctx, loopEntry); err != nil {
+			t.Fatalf("update loop: %v", err)
+		}
+	}
+
+	// A second apply with no spec change is a no-op.
+	if err := applyCmd.RunE(applyCmd, nil); err != nil {
+		t.Fatalf("re-apply: %v", err)
+	}
+	if started != 2 {
+		t.Fatalf("expected re-apply not to start loops, got %d starts", started)
+	}
+
+	writeSpec(`name: review
+count: 1
+prompt_msg: review the code
+interval: 20s
+max_iterations: 5
+max_runtime: 1h
+tags: [review]
+`)
+	if err := applyCmd.RunE(applyCmd, nil); err != nil {
+		t.Fatalf("apply scale down: %v", err)
+	}
+
+	kept, _ := loopRepo.GetByName(ctx, "review")
+	items, err := queueRepo.List(ctx, kept.ID)
+	if err != nil {
+		t.Fatalf("list queue: %v", err)
+	}
+	if len(items) != 1 || items[0].Type != models.LoopQueueItemConfigUpdate {
+		t.Fatalf("expected a queued config update, got %+v", items)
+	}
+	var update models.LoopConfigPayload
+	if err := json.Unmarshal(items[0].Payload, &update); err != nil {
+		t.Fatalf("decode update: %v", err)
+	}
+	if update.IntervalSeconds != 20 || update.StopConfig != nil {
+		t.Fatalf("unexpected config update: %+v", update)
+	}
+
+	extra, _ := loopRepo.GetByName(ctx, "review-2")
+	items, err = queueRepo.List(ctx, extra.ID)
+	if err != nil {
+		t.Fatalf("list queue: %v", err)
+	}
+	if len(items) != 1 || items[0].Type != models.LoopQueueItemStopGraceful {
+		t.Fatalf("expected a queued stop for the extra loop, got %+v", items)
+	}
+
+	// Pending updates and stops are not queued twice.
+	if err := applyCmd.RunE(applyCmd, nil); err != nil {
+		t.Fatalf("re-apply after scale down: %v", err)
+	}
+	for _, loopEntry := range []*models.Loop{kept, extra} {
+		items, _ := queueRepo.List(ctx, loopEntry.ID)
+		if len(items) != 1 {
+			t.Fatalf("expected no new queue items for %s, got %d", loopEntry.Name, len(items))
+		}
+	}
+
+	// A loop that finished on its own is skipped unless --restart-stopped.
+	finished, _ := loopRepo.GetByName(ctx, "review")
+	loop.ApplyLoopConfig(finished, update)
+	finished.State = models.LoopStateStopped
+	finished.LastError = "max iterations reached (5)"
+	if err := loopRepo.Update(ctx, finished); err != nil {
+		t.Fatalf("update loop: %v", err)
+	}
+	if _, err := queueRepo.Clear(ctx, finished.ID); err != nil {
+		t.Fatalf("clear queue: %v", err)
+	}
+	repoPath, err := resolveRepoPath("")
+	if err != nil {
+		t.Fatalf("resolve repo path: %v", err)
+	}
+	specs, err := loop.LoadSpecs(loop.SpecDir(repoPath))
+	if err != nil {
+		t.Fatalf("load specs: %v", err)
+	}
+	actions, err := planApply(ctx, database, repoPath, specs, true)
+	if err != nil {
+		t.Fatalf("plan: %v", err)
+	}
+	if len(actions) != 1 || actions[0].Action != applyActionSkip || !strings.Contains(actions[0].Reason, "max iterations reached") {
+		t.Fatalf("expected the finished loop to be skipped, got %+v", actions)
+	}
+	if err := applyCmd.RunE(applyCmd, nil); err != nil {
+		t.Fatalf("re-apply finished loop: %v", err)
+	}
+	if started != 2 {
+		t.Fatalf("expected the finished loop to stay stopped, got %d starts", started)
+	}
+
+	applyRestartStopped = true
+	defer func() { applyRestartStopped = false }()
+	if err := applyCmd.RunE(applyCmd, nil); err != nil {
+		t.Fatalf("apply --restart-stopped: %v", err)
+	}
+	if started != 3 {
+		t.Fatalf("expected --restart-stopped to start the finished loop, got %d starts", started)
+	}
+
+	// An update queued by an earlier apply must not roll back a newer spec
+	// written when the stopped loop is started.
+	stale, _ := loopRepo.GetByName(ctx, "review")
+	stale.State = models.LoopStateStopped
+	if err := loopRepo.Update(ctx, stale); err != nil {
+		t.Fatalf("update loop: %v", err)
+	}
+	stalePayload, err := json.Marshal(update)
+	if err != nil {
+		t.Fatalf("encode update: %v", err)
+	}
+	if err := queueRepo.Enqueue(ctx, stale.ID, &models.LoopQueueItem{Type: models.LoopQueueItemConfigUpdate, Payload: stalePayload}); err != nil {
+		t.Fatalf("enqueue update: %v", err)
+	}
+	applyRestartStopped = false
+	writeSpec(`name: review
+count: 1
+prompt_msg: review the code
+interval: 30s
+max_iterations: 5
+max_runtime: 1h
+tags: [review]
+`)
+	if err := applyCmd.RunE(applyCmd, nil); err != nil {
+		t.Fatalf("apply changed spec: %v", err)
+	}
+	if started != 4 {
+		t.Fatalf("expected the changed loop to start, got %d starts", started)
+	}
+	restarted, _ := loopRepo.GetByName(ctx, "review")
+	if restarted.IntervalSeconds != 30 {
+		t.Fatalf("expected the new interval on the loop, got %d", restarted.IntervalSeconds)
+	}
+	if pending, err := pendingConfigUpdate(ctx, queueRepo, stale.ID); err != nil || pending != nil {
+		t.Fatalf("expected the stale config update to be skipped, got %+v (%v)", pending, err)
+	}
+}
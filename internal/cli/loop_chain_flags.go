@@ -0,0 +1,80 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/tOgg1/forge/internal/loop"
+	"github.com/tOgg1/forge/internal/models"
+)
+
+// addLoopChainFlags registers the --on-stop and --on-success flags, bound to spec.
+func addLoopChainFlags(cmd *cobra.Command, spec *loop.ChainSpec) {
+	flags := cmd.Flags()
+	flags.StringArrayVar(&spec.OnStopFlags, "on-stop", nil, "hand off to loop when this loop stops on its own: loop[:reason,...] (repeatable)")
+	flags.StringArrayVar(&spec.OnSuccessFlags, "on-success", nil, "hand off to loop when a stop rule ends this loop: loop[:reason,...] (repeatable)")
+}
+
+// buildChainConfig validates chain targets from spec files and flags.
+func buildChainConfig(spec loop.ChainSpec) (models.LoopChainConfig, error) {
+	chainCfg := models.LoopChainConfig{}
+	var err error
+	if chainCfg.OnStop, err = buildChainTargets("on_stop", spec.OnStop, spec.OnStopFlags); err != nil {
+		return chainCfg, err
+	}
+	if chainCfg.OnSuccess, err = buildChainTargets("on_success", spec.OnSuccess, spec.OnSuccessFlags); err != nil {
+		return chainCfg, err
+	}
+	for _, target := range chainCfg.OnSuccess {
+		for _, reason := range target.Reasons {
+			if !reason.Succeeded() {
+				return chainCfg, fmt.Errorf("on_success %s: reason %s never counts as success (use on_stop)", target.Loop, reason)
+			}
+		}
+	}
+	return chainCfg, nil
+}
+
+func buildChainTargets(field string, specs []loop.ChainTargetSpec, flagValues []string) ([]models.LoopChainTarget, error) {
+	specs = append([]loop.ChainTargetSpec{}, specs...)
+	for _, value := range flagValues {
+		specs = append(specs, parseChainFlag(value))
+	}
+
+	targets := make([]models.LoopChainTarget, 0, len(specs))
+	for _, spec := range specs {
+		target := models.LoopChainTarget{
+			Loop:    strings.TrimSpace(spec.Loop),
+			Message: strings.TrimSpace(spec.Message),
+		}
+		for _, value := range spec.Reasons {
+			reason, err := models.ParseLoopStopReason(value)
+			if err != nil {
+				return nil, fmt.Errorf("%s %s: %w", field, target.Loop, err)
+			}
+			target.Reasons = append(target.Reasons, reason)
+		}
+		if err := target.Validate(); err != nil {
+			return nil, fmt.Errorf("%s: %w", field, err)
+		}
+		targets = append(targets, target)
+	}
+	if len(targets) == 0 {
+		return nil, nil
+	}
+	return targets, nil
+}
+
+// parseChainFlag parses an --on-stop or --on-success value of the form
+// loop[:reason,...].
+func parseChainFlag(value string) loop.ChainTargetSpec {
+	name, reasons, _ := strings.Cut(value, ":")
+	spec := loop.ChainTargetSpec{Loop: strings.TrimSpace(name)}
+	for _, reason := range strings.Split(reasons, ",") {
+		if strings.TrimSpace(reason) != "" {
+			spec.Reasons = append(spec.Reasons, reason)
+		}
+	}
+	return spec
+}
@@ -0,0 +1,81 @@
+package cli
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/tOgg1/forge/internal/loop"
+	"github.com/tOgg1/forge/internal/models"
+)
+
+func TestBuildChainConfig(t *testing.T) {
+	spec := loop.ChainSpec{
+		OnStop:         []loop.ChainTargetSpec{{Loop: "triage", Message: "Pick up where build left off."}},
+		OnStopFlags:    []string{"cleanup:max_iterations, budget"},
+		OnSuccessFlags: []string{"review"},
+	}
+	chainCfg, err := buildChainConfig(spec)
+	if err != nil {
+		t.Fatalf("build chain config: %v", err)
+	}
+	if len(chainCfg.OnStop) != 2 || len(chainCfg.OnSuccess) != 1 {
+		t.Fatalf("unexpected chain config: %+v", chainCfg)
+	}
+	cleanup := chainCfg.OnStop[1]
+	if cleanup.Loop != "cleanup" || len(cleanup.Reasons) != 2 || cleanup.Reasons[1] != models.LoopStopReasonBudget {
+		t.Fatalf("unexpected on_stop target: %+v", cleanup)
+	}
+	if got := chainCfg.Targets(models.LoopStopReasonQual); len(got) != 2 || got[1].Loop != "review" {
+		t.Fatalf("expected triage and review for a qual stop, got %+v", got)
+	}
+
+	for _, tc := range []struct {
+		spec loop.ChainSpec
+		want string
+	}{
+		{spec: loop.ChainSpec{OnStopFlags: []string{":quant"}}, want: "loop is required"},
+		{spec: loop.ChainSpec{OnStopFlags: []string{"next:done"}}, want: "invalid stop reason"},
+		{spec: loop.ChainSpec{OnSuccessFlags: []string{"next:budget"}}, want: "never counts as success"},
+	} {
+		if _, err := buildChainConfig(tc.spec); err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Fatalf("spec %+v: expected error containing %q, got %v", tc.spec, tc.want, err)
+		}
+	}
+}
+
+func TestLoopChainTree(t *testing.T) {
+	chainTo := func(targets ...string) map[string]any {
+		chainCfg := models.LoopChainConfig{}
+		for _, target := range targets {
+			chainCfg.OnStop = append(chainCfg.OnStop, models.LoopChainTarget{Loop: target})
+		}
+		return loop.ChainMetadata(chainCfg)
+	}
+	loops := []*models.Loop{
+		{ID: "1", Name: "review"},
+		{ID: "2", Name: "build", Metadata: chainTo("review", "docs")},
+		{ID: "3", Name: "solo"},
+		{ID: "4", Name: "docs", Metadata: chainTo("publish")},
+		{ID: "5", Name: "publish", Metadata: chainTo("docs")},
+		{ID: "6", Name: "ping", Metadata: chainTo("pong")},
+		{ID: "7", Name: "pong", Metadata: chainTo("ping")},
+	}
+
+	ordered, prefixes := loopChainTree(loops)
+	got := make([]string, 0, len(ordered))
+	for _, loopEntry := range ordered {
+		got = append(got, prefixes[loopEntry.ID]+loopEntry.Name)
+	}
+	want := []string{
+		"build",
+		"├─ review",
+		"└─ docs",
+		"   └─ publish",
+		"solo",
+		"ping",
+		"└─ pong",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("tree =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
@@ -31,7 +31,8 @@ var loopCleanCmd = &cobra.Command{
 	Short: "Remove inactive loops",
 	Long: `Remove inactive loop records (stopped or errored).
 
-Logs and ledgers are left on disk.`,
+Stored run output and worktrees of isolated loops are removed with them; logs
+and ledgers are left on disk.`,
 	Args: cobra.NoArgs,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		ctx := context.Background()
@@ -91,6 +92,7 @@ Logs and ledgers are left on disk.`,
 			if err := loopRepo.Delete(ctx, loopEntry.ID); err != nil {
 				return err
 			}
+			removeLoopResources(loopEntry)
 		}
 
 		if IsJSONOutput() || IsJSONLOutput() {
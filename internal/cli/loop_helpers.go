@@ -7,11 +7,11 @@ import (
 	"os"
 	"path/filepath"
 	"sort"
-	"strconv"
 	"strings"
 	"time"
 
 	"github.com/tOgg1/forge/internal/db"
+	"github.com/tOgg1/forge/internal/loop"
 	"github.com/tOgg1/forge/internal/models"
 )
 
@@ -82,27 +82,6 @@ func parseTags(value string) []string {
 	return out
 }
 
-func parseCSVInts(value string) ([]int, error) {
-	value = strings.TrimSpace(value)
-	if value == "" {
-		return nil, nil
-	}
-	parts := strings.Split(value, ",")
-	out := make([]int, 0, len(parts))
-	for _, part := range parts {
-		part = strings.TrimSpace(part)
-		if part == "" {
-			continue
-		}
-		n, err := strconv.Atoi(part)
-		if err != nil {
-			return nil, fmt.Errorf("invalid int %q", part)
-		}
-		out = append(out, n)
-	}
-	return out, nil
-}
-
 func parseDuration(value string, fallback time.Duration) (time.Duration, error) {
 	if strings.TrimSpace(value) == "" {
 		return fallback, nil
@@ -114,6 +93,48 @@ func parseDuration(value string, fallback time.Duration) (time.Duration, error)
 	return parsed, nil
 }
 
+func parseRestartPolicy(value string) (models.LoopRestartPolicy, error) {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "", "never", "no":
+		return models.LoopRestartNever, nil
+	case "on-failure", "on_failure":
+		return models.LoopRestartOnFailure, nil
+	case "always":
+		return models.LoopRestartAlways, nil
+	default:
+		return "", fmt.Errorf("invalid restart policy %q (use never, on-failure, or always)", value)
+	}
+}
+
+func parseSchedule(value string) (string, error) {
+	value = strings.TrimSpace(value)
+	if _, err := models.ParseLoopSchedule(value); err != nil {
+		return "", fmt.Errorf("invalid schedule %q: %w", value, err)
+	}
+	return value, nil
+}
+
+func parseIsolation(value string) (models.LoopIsolation, error) {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "", "none":
+		return models.LoopIsolationNone, nil
+	case "worktree":
+		return models.LoopIsolationWorktree, nil
+	default:
+		return "", fmt.Errorf("invalid isolation mode %q (use none or worktree)", value)
+	}
+}
+
+// isolateLoop moves a newly created loop into its own git worktree.
+func isolateLoop(loopEntry *models.Loop, repoPath string) error {
+	wt, err := loop.CreateWorktree(repoPath, GetConfig().Global.DataDir, loopEntry)
+	if err != nil {
+		return err
+	}
+	loop.SetWorktree(loopEntry, wt)
+	return nil
+}
+
 func selectLoops(ctx context.Context, loopRepo *db.LoopRepository, poolRepo *db.PoolRepository, profileRepo *db.ProfileRepository, selector loopSelector) ([]*models.Loop, error) {
 	loops, err := loopRepo.List(ctx)
 	if err != nil {
@@ -148,7 +169,7 @@ func selectLoops(ctx context.Context, loopRepo *db.LoopRepository, poolRepo *db.
 
 	filtered := make([]*models.Loop, 0)
 	for _, loop := range loops {
-		if repoFilter != "" && loop.RepoPath != repoFilter {
+		if repoFilter != "" && !loopInRepo(loop, repoFilter) {
 			continue
 		}
 		if poolID != "" && loop.PoolID != poolID {
@@ -222,6 +243,12 @@ func resolveLoopByRef(ctx context.Context, repo *db.LoopRepository, ref string)
 	return matches[0], nil
 }
 
+// loopInRepo matches a loop by its checkout or, for loops isolated in a
+// worktree, by the repo the worktree belongs to.
+func loopInRepo(loopEntry *models.Loop, repoPath string) bool {
+	return loopEntry.RepoPath == repoPath || loop.SourceRepoPath(loopEntry) == repoPath
+}
+
 func loopHasTag(loop *models.Loop, tag string) bool {
 	for _, value := range loop.Tags {
 		if value == tag {
@@ -18,8 +18,8 @@ func init() {
 }
 
 var loopInternalCmd = &cobra.Command{
-	Use:    "loop",
-	Hidden: true,
+	Use:   "loop",
+	Short: "Loop maintenance commands",
 }
 
 var loopRunCmd = &cobra.Command{
@@ -40,11 +40,13 @@ var loopRunCmd = &cobra.Command{
 		}
 
 		runner := loop.NewRunner(database, GetConfig())
+		runner.Publisher = newEventPublisher(database)
+		runner.StartLoop = startLoopProcessFunc
 
 		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
 		defer stop()
 
-		if err := runner.RunLoop(ctx, loopEntry.ID); err != nil {
+		if err := runner.Supervise(ctx, loopEntry.ID); err != nil {
 			return fmt.Errorf("loop run failed: %w", err)
 		}
 
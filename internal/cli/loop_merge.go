@@ -0,0 +1,105 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/tOgg1/forge/internal/db"
+	"github.com/tOgg1/forge/internal/loop"
+	"github.com/tOgg1/forge/internal/models"
+)
+
+var (
+	loopMergeRebase bool
+	loopMergeForce  bool
+)
+
+func init() {
+	loopInternalCmd.AddCommand(loopMergeCmd)
+
+	loopMergeCmd.Flags().BoolVar(&loopMergeRebase, "rebase", false, "rebase the loop branch onto the current branch, then fast-forward")
+	loopMergeCmd.Flags().BoolVar(&loopMergeForce, "force", false, "rebase even while the loop is active")
+}
+
+var loopMergeCmd = &cobra.Command{
+	Use:   "merge <loop>",
+	Short: "Merge an isolated loop's branch back",
+	Long: `Merge the branch of a loop started with --isolate worktree into the branch
+checked out in the main repo.
+
+Only work committed on the loop branch is merged. If the merge (or rebase with
+--rebase) conflicts, it is aborted and the conflicting files are listed.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		database, err := openDatabase()
+		if err != nil {
+			return err
+		}
+		defer database.Close()
+
+		loopRepo := db.NewLoopRepository(database)
+		loopEntry, err := resolveLoopByRef(context.Background(), loopRepo, args[0])
+		if err != nil {
+			return err
+		}
+
+		wt, ok := loop.LoadWorktree(loopEntry)
+		if !ok {
+			return fmt.Errorf("loop %q is not isolated in a worktree (start it with --isolate worktree)", loopEntry.Name)
+		}
+		if loopMergeRebase && !loopMergeForce {
+			switch loopEntry.State {
+			case models.LoopStateStopped, models.LoopStateError:
+			default:
+				return fmt.Errorf("loop %q is %s; stop it before rebasing its branch or use --force", loopEntry.Name, loopEntry.State)
+			}
+		}
+
+		result, err := loop.MergeWorktree(wt, loopMergeRebase)
+		if err != nil {
+			return err
+		}
+
+		if IsJSONOutput() || IsJSONLOutput() {
+			if err := WriteOutput(os.Stdout, result); err != nil {
+				return err
+			}
+		} else if !IsQuiet() || len(result.Conflicts) > 0 {
+			printMergeResult(loopEntry, result)
+		}
+
+		if len(result.Conflicts) > 0 {
+			return &ExitError{Code: 1, Err: fmt.Errorf("merging %s into %s conflicts", result.Branch, result.Into), Printed: true}
+		}
+		return nil
+	},
+}
+
+func printMergeResult(loopEntry *models.Loop, result *loop.MergeResult) {
+	verb := "Merged"
+	if result.Rebase {
+		verb = "Rebased and merged"
+	}
+
+	switch {
+	case len(result.Conflicts) > 0:
+		action := "Merge"
+		if result.Rebase {
+			action = "Rebase"
+		}
+		fmt.Fprintf(os.Stdout, "%s of %s into %s conflicts (aborted):\n", action, result.Branch, result.Into)
+		for _, path := range result.Conflicts {
+			fmt.Fprintf(os.Stdout, "  %s\n", path)
+		}
+	case result.Commits == 0:
+		fmt.Fprintf(os.Stdout, "Nothing to merge: %s has no commits beyond %s\n", result.Branch, result.Into)
+	default:
+		fmt.Fprintf(os.Stdout, "%s %s into %s (%d commit(s)) for loop %q\n", verb, result.Branch, result.Into, result.Commits, loopEntry.Name)
+	}
+
+	if result.DirtyWorktree {
+		fmt.Fprintf(os.Stdout, "Note: the loop worktree has uncommitted changes that were not merged.\n")
+	}
+}
@@ -1,6 +1,7 @@
 package cli
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"os"
@@ -9,8 +10,13 @@ import (
 	"strings"
 
 	"github.com/spf13/cobra"
+	"github.com/tOgg1/forge/internal/db"
+	"github.com/tOgg1/forge/internal/loop"
+	"github.com/tOgg1/forge/internal/models"
 )
 
+var promptRenderLoop string
+
 func init() {
 	rootCmd.AddCommand(promptCmd)
 
@@ -18,6 +24,9 @@ func init() {
 	promptCmd.AddCommand(promptAddCmd)
 	promptCmd.AddCommand(promptEditCmd)
 	promptCmd.AddCommand(promptSetDefaultCmd)
+	promptCmd.AddCommand(promptRenderCmd)
+
+	promptRenderCmd.Flags().StringVar(&promptRenderLoop, "loop", "", "loop whose variables, memory and queued messages to render with (required)")
 }
 
 var promptCmd = &cobra.Command{
@@ -174,6 +183,59 @@ var promptSetDefaultCmd = &cobra.Command{
 	},
 }
 
+var promptRenderCmd = &cobra.Command{
+	Use:   "render [name]",
+	Short: "Preview the prompt a loop would send",
+	Long: `Render a prompt exactly as the loop's next iteration would send it: template
+variables filled in (for prompts that opt in with "template: true" front matter
+or a .tmpl file name), followed by the loop memory and queued messages.
+
+Without a name the loop's own next prompt is rendered (a queued --next-prompt
+override, else its base prompt). Nothing is consumed from the loop queue, but
+{{sh}} snippets in the prompt do run.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if strings.TrimSpace(promptRenderLoop) == "" {
+			return fmt.Errorf("--loop is required")
+		}
+
+		ctx := context.Background()
+		database, err := openDatabase()
+		if err != nil {
+			return err
+		}
+		defer database.Close()
+
+		loopEntry, err := resolveLoopByRef(ctx, db.NewLoopRepository(database), promptRenderLoop)
+		if err != nil {
+			return err
+		}
+
+		var override *models.NextPromptOverridePayload
+		if len(args) == 1 {
+			path, _, err := resolvePromptPath(loopEntry.RepoPath, args[0])
+			if err != nil {
+				return err
+			}
+			override = &models.NextPromptOverridePayload{Prompt: path, IsPath: true}
+		}
+
+		prompt, err := loop.NewRunner(database, GetConfig()).PreviewPrompt(ctx, loopEntry, override)
+		if err != nil {
+			return err
+		}
+
+		if IsJSONOutput() || IsJSONLOutput() {
+			return WriteOutput(os.Stdout, map[string]string{"loop": loopEntry.Name, "prompt": prompt})
+		}
+		fmt.Fprint(os.Stdout, prompt)
+		if !strings.HasSuffix(prompt, "\n") {
+			fmt.Fprintln(os.Stdout)
+		}
+		return nil
+	},
+}
+
 func copyFile(src, dst string) error {
 	in, err := os.Open(src)
 	if err != nil {
@@ -4,13 +4,16 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"slices"
 	"sort"
 	"strings"
 	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/tOgg1/forge/internal/db"
+	"github.com/tOgg1/forge/internal/loop"
 	"github.com/tOgg1/forge/internal/models"
+	"github.com/tOgg1/forge/internal/workflows"
 )
 
 var (
@@ -19,6 +22,8 @@ var (
 	loopPsProfile string
 	loopPsState   string
 	loopPsTag     string
+	loopPsTree    bool
+	loopPsAll     bool
 )
 
 func init() {
@@ -29,6 +34,8 @@ func init() {
 	loopPsCmd.Flags().StringVar(&loopPsProfile, "profile", "", "filter by profile")
 	loopPsCmd.Flags().StringVar(&loopPsState, "state", "", "filter by state")
 	loopPsCmd.Flags().StringVar(&loopPsTag, "tag", "", "filter by tag")
+	loopPsCmd.Flags().BoolVar(&loopPsTree, "tree", false, "show loops under the loops that chain to them (on_stop/on_success)")
+	loopPsCmd.Flags().BoolVar(&loopPsAll, "all", false, "include the loops of finished workflow steps")
 }
 
 var loopPsCmd = &cobra.Command{
@@ -43,6 +50,10 @@ var loopPsCmd = &cobra.Command{
 		}
 		defer database.Close()
 
+		if _, err := reapLoops(context.Background(), database); err != nil {
+			return err
+		}
+
 		loopRepo := db.NewLoopRepository(database)
 		poolRepo := db.NewPoolRepository(database)
 		profileRepo := db.NewProfileRepository(database)
@@ -72,6 +83,11 @@ var loopPsCmd = &cobra.Command{
 		if err != nil {
 			return err
 		}
+		if !loopPsAll && loopPsTag != workflows.DoneLoopTag {
+			loops = slices.DeleteFunc(loops, func(loopEntry *models.Loop) bool {
+				return slices.Contains(loopEntry.Tags, workflows.DoneLoopTag)
+			})
+		}
 
 		if IsJSONOutput() || IsJSONLOutput() {
 			return WriteOutput(os.Stdout, loops)
@@ -83,6 +99,10 @@ var loopPsCmd = &cobra.Command{
 		}
 
 		sort.Slice(loops, func(i, j int) bool { return loops[i].CreatedAt.Before(loops[j].CreatedAt) })
+		var treePrefixes map[string]string
+		if loopPsTree {
+			loops, treePrefixes = loopChainTree(loops)
+		}
 
 		loopIDs := make([]string, 0, len(loops))
 		for _, loopEntry := range loops {
@@ -116,6 +136,8 @@ var loopPsCmd = &cobra.Command{
 				}
 			}
 
+			nextRun := formatNextRun(loopEntry)
+
 			displayID := loopShortID(loopEntry)
 			uniqueLen := uniquePrefixes[displayID]
 			if uniqueLen == 0 {
@@ -124,10 +146,11 @@ var loopPsCmd = &cobra.Command{
 
 			rows = append(rows, []string{
 				formatLoopShortID(displayID, uniqueLen),
-				loopEntry.Name,
+				treePrefixes[loopEntry.ID] + loopEntry.Name,
 				fmt.Sprintf("%d", runCount),
-				string(loopEntry.State),
+				formatLoopState(loopEntry),
 				waitUntil,
+				nextRun,
 				loopEntry.ProfileID,
 				loopEntry.PoolID,
 				fmt.Sprintf("%d", pending),
@@ -136,10 +159,115 @@ var loopPsCmd = &cobra.Command{
 			})
 		}
 
-		return writeTable(os.Stdout, []string{"ID", "NAME", "RUNS", "STATE", "WAIT_UNTIL", "PROFILE", "POOL", "QUEUE", "LAST_RUN", "REPO"}, rows)
+		return writeTable(os.Stdout, []string{"ID", "NAME", "RUNS", "STATE", "WAIT_UNTIL", "NEXT_RUN", "PROFILE", "POOL", "QUEUE", "LAST_RUN", "REPO"}, rows)
 	},
 }
 
+// reapLoops marks loops whose runner died as orphaned, so listings never show
+// dead loops as running. Restart policies are left to forged.
+func reapLoops(ctx context.Context, database *db.DB) ([]loop.ReapResult, error) {
+	reaper := loop.NewReaper(database, GetConfig())
+	reaper.Publisher = newEventPublisher(database)
+	return reaper.Reap(ctx)
+}
+
+// loopChainTree orders loops depth-first along their chains, each loop under
+// the first listed loop that chains to it, and returns the tree prefix of each
+// loop's name. Loops in a cycle are listed once.
+func loopChainTree(loops []*models.Loop) ([]*models.Loop, map[string]string) {
+	byRef := make(map[string]*models.Loop, len(loops)*2)
+	for _, loopEntry := range loops {
+		byRef[loopEntry.ID] = loopEntry
+		byRef[loopEntry.Name] = loopEntry
+	}
+	children := make(map[string][]*models.Loop, len(loops))
+	chained := make(map[string]bool, len(loops))
+	for _, loopEntry := range loops {
+		chainCfg, ok := loop.LoadChainConfig(loopEntry)
+		if !ok {
+			continue
+		}
+		seen := make(map[string]bool)
+		for _, target := range append(chainCfg.OnStop, chainCfg.OnSuccess...) {
+			child, ok := byRef[target.Loop]
+			if !ok || child.ID == loopEntry.ID || seen[child.ID] {
+				continue
+			}
+			seen[child.ID] = true
+			children[loopEntry.ID] = append(children[loopEntry.ID], child)
+			chained[child.ID] = true
+		}
+	}
+
+	ordered := make([]*models.Loop, 0, len(loops))
+	prefixes := make(map[string]string, len(loops))
+	visited := make(map[string]bool, len(loops))
+	var walk func(loopEntry *models.Loop, indent, branch string)
+	walk = func(loopEntry *models.Loop, indent, branch string) {
+		visited[loopEntry.ID] = true
+		ordered = append(ordered, loopEntry)
+		prefixes[loopEntry.ID] = indent + branch
+
+		pending := make([]*models.Loop, 0, len(children[loopEntry.ID]))
+		for _, child := range children[loopEntry.ID] {
+			if !visited[child.ID] {
+				pending = append(pending, child)
+				// Claim the child so a sibling subtree does not list it too.
+				visited[child.ID] = true
+			}
+		}
+		switch branch {
+		case "├─ ":
+			indent += "│  "
+		case "└─ ":
+			indent += "   "
+		}
+		for i, child := range pending {
+			if i == len(pending)-1 {
+				walk(child, indent, "└─ ")
+			} else {
+				walk(child, indent, "├─ ")
+			}
+		}
+	}
+	for _, loopEntry := range loops {
+		if !chained[loopEntry.ID] && !visited[loopEntry.ID] {
+			walk(loopEntry, "", "")
+		}
+	}
+	// Whatever is left only has parents in a cycle.
+	for _, loopEntry := range loops {
+		if !visited[loopEntry.ID] {
+			walk(loopEntry, "", "")
+		}
+	}
+	return ordered, prefixes
+}
+
+func formatLoopState(loopEntry *models.Loop) string {
+	if loop.IsOrphaned(loopEntry) {
+		return fmt.Sprintf("%s: %s", loopEntry.State, loop.OrphanedError)
+	}
+	if rule := loop.StoppedBy(loopEntry); rule != "" && loopEntry.State == models.LoopStateStopped {
+		return fmt.Sprintf("%s (%s)", loopEntry.State, rule)
+	}
+	return string(loopEntry.State)
+}
+
+// formatNextRun shows when a sleeping loop runs next, or that it is waiting
+// for `forge loop trigger`.
+func formatNextRun(loopEntry *models.Loop) string {
+	if loopEntry.NextRunAt != nil {
+		return loopEntry.NextRunAt.UTC().Format(time.RFC3339)
+	}
+	if loopEntry.State == models.LoopStateSleeping {
+		if schedule, err := models.ParseLoopSchedule(loopEntry.Schedule); err == nil && schedule != nil && schedule.Kind == models.LoopScheduleTrigger {
+			return "on trigger"
+		}
+	}
+	return ""
+}
+
 func loopUniquePrefixLengths(ids []string) map[string]int {
 	result := make(map[string]int, len(ids))
 	for idx, id := range ids {
@@ -7,6 +7,7 @@ import (
 
 	"github.com/spf13/cobra"
 	"github.com/tOgg1/forge/internal/db"
+	"github.com/tOgg1/forge/internal/loop"
 	"github.com/tOgg1/forge/internal/models"
 )
 
@@ -38,7 +39,9 @@ var loopRmCmd = &cobra.Command{
 	Short:   "Remove loop records",
 	Long: `Remove loop records from Forge.
 
-This only removes the loop record. Logs and ledgers are left on disk.`,
+This removes the loop record, its stored run output, and its worktree when the
+loop was started with --isolate worktree. Uncommitted work in the worktree is
+committed onto the loop branch first. Logs and ledgers are left on disk.`,
 	Args: cobra.MaximumNArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		sel := loopSelector{Repo: loopRmRepo, Pool: loopRmPool, Profile: loopRmProfile, State: loopRmState, Tag: loopRmTag}
@@ -102,6 +105,7 @@ This only removes the loop record. Logs and ledgers are left on disk.`,
 			if err := loopRepo.Delete(ctx, loopEntry.ID); err != nil {
 				return err
 			}
+			removeLoopResources(loopEntry)
 		}
 
 		if IsJSONOutput() || IsJSONLOutput() {
@@ -135,3 +139,21 @@ This only removes the loop record. Logs and ledgers are left on disk.`,
 		return nil
 	},
 }
+
+// removeLoopResources deletes what Forge owns for a removed loop: stored run
+// output and, for isolated loops, the worktree. Unmerged loop branches are kept.
+func removeLoopResources(loopEntry *models.Loop) {
+	_ = os.RemoveAll(loop.RunArtifactDir(GetConfig().Global.DataDir, loopEntry.ID))
+	if err := loop.DeleteCheckpoints(loop.SourceRepoPath(loopEntry), loopEntry); err != nil {
+		fmt.Fprintf(os.Stderr, "warning: %v\n", err)
+	}
+	if wt, ok := loop.LoadWorktree(loopEntry); ok {
+		snapshot, err := loop.RemoveWorktree(wt)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "warning: %v\n", err)
+		}
+		if snapshot != "" {
+			fmt.Fprintf(os.Stderr, "Saved uncommitted work from %s to branch %s (%s)\n", wt.Path, wt.Branch, shortID(snapshot))
+		}
+	}
+}
@@ -0,0 +1,138 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/tOgg1/forge/internal/db"
+	"github.com/tOgg1/forge/internal/loop"
+	"github.com/tOgg1/forge/internal/models"
+)
+
+var (
+	runDiffStat     bool
+	runRevertDryRun bool
+	runRevertForce  bool
+)
+
+func init() {
+	runsCmd.AddCommand(runDiffCmd)
+	runsCmd.AddCommand(runRevertCmd)
+
+	runDiffCmd.Flags().BoolVar(&runDiffStat, "stat", false, "show a diffstat instead of the full diff")
+	runRevertCmd.Flags().BoolVar(&runRevertDryRun, "dry-run", false, "check that the revert applies without changing files")
+	runRevertCmd.Flags().BoolVar(&runRevertForce, "force", false, "revert even while the loop is active")
+}
+
+var runDiffCmd = &cobra.Command{
+	Use:   "diff <run-id> [-- <path>...]",
+	Short: "Show the changes a loop run made",
+	Long: `Show exactly what a single iteration changed in the loop's working tree,
+committed or not. Requires loop_defaults.checkpoints to have been enabled when
+the run happened.`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := context.Background()
+		database, err := openDatabase()
+		if err != nil {
+			return err
+		}
+		defer database.Close()
+
+		run, loopEntry, err := resolveCheckpointedRun(ctx, database, args[0])
+		if err != nil {
+			return err
+		}
+
+		diff, err := loop.RunDiff(loopEntry.RepoPath, run, runDiffStat, args[1:]...)
+		if err != nil {
+			return err
+		}
+
+		if IsJSONOutput() || IsJSONLOutput() {
+			return WriteOutput(os.Stdout, map[string]any{
+				"run_id":         run.ID,
+				"checkpoint_ref": run.CheckpointRef,
+				"commit_before":  run.CommitBefore,
+				"commit_after":   run.CommitAfter,
+				"diff":           diff,
+			})
+		}
+		_, err = fmt.Fprint(os.Stdout, diff)
+		return err
+	},
+}
+
+var runRevertCmd = &cobra.Command{
+	Use:   "revert <run-id>",
+	Short: "Undo the changes a loop run made",
+	Long: `Undo a single iteration by applying its checkpointed changes in reverse to the
+loop's current working tree. Nothing is committed; review and commit the result.
+
+If later edits touched the same lines, the revert fails without changing any
+file.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := context.Background()
+		database, err := openDatabase()
+		if err != nil {
+			return err
+		}
+		defer database.Close()
+
+		run, loopEntry, err := resolveCheckpointedRun(ctx, database, args[0])
+		if err != nil {
+			return err
+		}
+		if !runRevertDryRun && !runRevertForce {
+			switch loopEntry.State {
+			case models.LoopStateStopped, models.LoopStateError:
+			default:
+				return fmt.Errorf("loop %q is %s; stop it before reverting or use --force", loopEntry.Name, loopEntry.State)
+			}
+		}
+
+		files, err := loop.RevertRun(loopEntry.RepoPath, run, runRevertDryRun)
+		if err != nil {
+			return err
+		}
+
+		if IsJSONOutput() || IsJSONLOutput() {
+			return WriteOutput(os.Stdout, map[string]any{
+				"run_id":   run.ID,
+				"files":    files,
+				"dry_run":  runRevertDryRun,
+				"reverted": !runRevertDryRun && len(files) > 0,
+			})
+		}
+		if IsQuiet() {
+			return nil
+		}
+		switch {
+		case len(files) == 0:
+			fmt.Fprintf(os.Stdout, "Run %s made no changes\n", shortID(run.ID))
+		case runRevertDryRun:
+			fmt.Fprintf(os.Stdout, "Run %s can be reverted cleanly (%d file(s)):\n", shortID(run.ID), len(files))
+		default:
+			fmt.Fprintf(os.Stdout, "Reverted run %s in %s (%d file(s)):\n", shortID(run.ID), loopEntry.RepoPath, len(files))
+		}
+		for _, path := range files {
+			fmt.Fprintf(os.Stdout, "  %s\n", path)
+		}
+		return nil
+	},
+}
+
+func resolveCheckpointedRun(ctx context.Context, database *db.DB, ref string) (*models.LoopRun, *models.Loop, error) {
+	run, err := resolveLoopRun(ctx, db.NewLoopRunRepository(database), ref)
+	if err != nil {
+		return nil, nil, err
+	}
+	loopEntry, err := db.NewLoopRepository(database).Get(ctx, run.LoopID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return run, loopEntry, nil
+}
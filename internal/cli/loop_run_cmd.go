@@ -2,20 +2,37 @@ package cli
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/tOgg1/forge/internal/db"
+	"github.com/tOgg1/forge/internal/harness"
 	"github.com/tOgg1/forge/internal/loop"
+	"github.com/tOgg1/forge/internal/models"
+)
+
+var (
+	runListLimit int
 )
 
 func init() {
 	rootCmd.AddCommand(loopRunOnceCmd)
+	rootCmd.AddCommand(runsCmd)
+	runsCmd.AddCommand(runListCmd)
+	runsCmd.AddCommand(runShowCmd)
+
+	runListCmd.Flags().IntVar(&runListLimit, "limit", 20, "maximum runs to list (0 for all)")
 }
 
 var loopRunOnceCmd = &cobra.Command{
 	Use:   "run <loop>",
 	Short: "Run a single loop iteration",
+	Long:  "Run a single loop iteration. Use `forge runs` to inspect past iterations.",
 	Args:  cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		database, err := openDatabase()
@@ -31,6 +48,7 @@ var loopRunOnceCmd = &cobra.Command{
 		}
 
 		runner := loop.NewRunner(database, GetConfig())
+		runner.Publisher = newEventPublisher(database)
 		if err := runner.RunOnce(context.Background(), loopEntry.ID); err != nil {
 			return fmt.Errorf("loop run failed: %w", err)
 		}
@@ -38,3 +56,253 @@ var loopRunOnceCmd = &cobra.Command{
 		return nil
 	},
 }
+
+var runsCmd = &cobra.Command{
+	Use:   "runs",
+	Short: "Inspect past loop runs",
+	Long:  "Inspect past loop iterations: `forge runs ls <loop>` and `forge runs show <run-id>` list and show runs, and `forge runs diff|revert <run-id>` view or undo a checkpointed iteration's changes.",
+}
+
+var runListCmd = &cobra.Command{
+	Use:     "ls <loop>",
+	Aliases: []string{"list"},
+	Short:   "List runs of a loop",
+	Args:    cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := context.Background()
+		database, err := openDatabase()
+		if err != nil {
+			return err
+		}
+		defer database.Close()
+
+		loopEntry, err := resolveLoopByRef(ctx, db.NewLoopRepository(database), args[0])
+		if err != nil {
+			return err
+		}
+
+		runs, err := db.NewLoopRunRepository(database).ListByLoop(ctx, loopEntry.ID)
+		if err != nil {
+			return err
+		}
+		if runListLimit > 0 && len(runs) > runListLimit {
+			runs = runs[:runListLimit]
+		}
+
+		if IsJSONOutput() || IsJSONLOutput() {
+			return WriteOutput(os.Stdout, runs)
+		}
+		if len(runs) == 0 {
+			fmt.Fprintln(os.Stdout, "No runs found")
+			return nil
+		}
+
+		profileNames := runProfileNames(ctx, db.NewProfileRepository(database), runs)
+		rows := make([][]string, 0, len(runs))
+		for _, run := range runs {
+			rows = append(rows, []string{
+				shortID(run.ID),
+				string(run.Status),
+				formatRunExitCode(run.ExitCode),
+				profileNames[run.ProfileID],
+				run.PromptSource,
+				run.StartedAt.UTC().Format(time.RFC3339),
+				workflowElapsed(&run.StartedAt, run.FinishedAt),
+				formatRunTokens(run.Usage),
+			})
+		}
+		return writeTable(os.Stdout, []string{"RUN", "STATUS", "EXIT", "PROFILE", "PROMPT", "STARTED", "DURATION", "TOKENS"}, rows)
+	},
+}
+
+var runShowCmd = &cobra.Command{
+	Use:   "show <run-id>",
+	Short: "Show a loop run with its prompt and full output",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := context.Background()
+		database, err := openDatabase()
+		if err != nil {
+			return err
+		}
+		defer database.Close()
+
+		run, err := resolveLoopRun(ctx, db.NewLoopRunRepository(database), args[0])
+		if err != nil {
+			return err
+		}
+
+		view := loopRunView{LoopRun: run}
+		if loopEntry, err := db.NewLoopRepository(database).Get(ctx, run.LoopID); err == nil {
+			view.LoopName = loopEntry.Name
+		}
+		if run.ProfileID != "" {
+			if profile, err := db.NewProfileRepository(database).Get(ctx, run.ProfileID); err == nil {
+				view.ProfileName = profile.Name
+			}
+		}
+		if run.FinishedAt != nil {
+			view.Duration = run.FinishedAt.Sub(run.StartedAt).Round(time.Second).String()
+		}
+		view.Prompt, view.PromptStored = readRunArtifact(run.PromptSentPath)
+		view.Output, view.OutputStored = readRunArtifact(run.OutputPath)
+		if !view.OutputStored {
+			view.Output = run.OutputTail
+		}
+		if run.EventsPath != "" {
+			if events, err := loop.ReadRunEvents(run.EventsPath); err == nil {
+				view.Events = events
+			}
+		}
+
+		if IsJSONOutput() || IsJSONLOutput() {
+			return WriteOutput(os.Stdout, view)
+		}
+		return printLoopRun(view)
+	},
+}
+
+type loopRunView struct {
+	*models.LoopRun
+	LoopName     string `json:"loop_name,omitempty"`
+	ProfileName  string `json:"profile_name,omitempty"`
+	Duration     string `json:"duration,omitempty"`
+	Prompt       string `json:"prompt,omitempty"`
+	PromptStored bool   `json:"prompt_stored"`
+	Output       string `json:"output,omitempty"`
+	OutputStored bool   `json:"output_stored"`
+
+	Events []models.LoopRunEvent `json:"events,omitempty"`
+}
+
+func printLoopRun(view loopRunView) error {
+	run := view.LoopRun
+	fmt.Printf("Run: %s\n", run.ID)
+	fmt.Printf("Loop: %s\n", firstNonEmpty(view.LoopName, run.LoopID))
+	fmt.Printf("Status: %s\n", run.Status)
+	fmt.Printf("Exit code: %s\n", formatRunExitCode(run.ExitCode))
+	fmt.Printf("Profile: %s\n", firstNonEmpty(view.ProfileName, run.ProfileID, "-"))
+	fmt.Printf("Started: %s\n", run.StartedAt.UTC().Format(time.RFC3339))
+	fmt.Printf("Duration: %s\n", workflowElapsed(&run.StartedAt, run.FinishedAt))
+	prompt := firstNonEmpty(run.PromptSource, "-")
+	if run.PromptPath != "" {
+		prompt = fmt.Sprintf("%s (%s)", prompt, run.PromptPath)
+	}
+	fmt.Printf("Prompt source: %s\n", prompt)
+	if reason, ok := run.Metadata["rate_limit_reason"].(string); ok && reason != "" {
+		fmt.Printf("Rate limit: %s\n", reason)
+	}
+	if !run.Usage.IsZero() {
+		fmt.Printf("Usage: %d tokens (input %d, output %d, cache read %d, cache write %d), %s\n",
+			run.Usage.TotalTokens, run.Usage.InputTokens, run.Usage.OutputTokens,
+			run.Usage.CacheReadTokens, run.Usage.CacheWriteTokens, formatCostUSD(run.Usage.CostUSD))
+	}
+	if run.CheckpointRef != "" {
+		fmt.Printf("Checkpoint: %s (%s..%s)\n", run.CheckpointRef, shortID(run.CommitBefore), firstNonEmpty(shortID(run.CommitAfter), "-"))
+	}
+
+	fmt.Println()
+	fmt.Println("--- prompt ---")
+	if view.PromptStored {
+		fmt.Println(strings.TrimRight(view.Prompt, "\n"))
+	} else {
+		fmt.Println("(not stored)")
+	}
+
+	if len(view.Events) > 0 {
+		fmt.Println()
+		fmt.Println("--- events ---")
+		for _, event := range view.Events {
+			fmt.Println(harness.FormatEvent(event))
+		}
+	}
+
+	fmt.Println()
+	if view.OutputStored {
+		fmt.Println("--- output ---")
+	} else {
+		fmt.Println("--- output (tail; full output not stored) ---")
+	}
+	fmt.Println(strings.TrimRight(view.Output, "\n"))
+	return nil
+}
+
+func formatRunTokens(usage models.LoopRunUsage) string {
+	if usage.IsZero() {
+		return "-"
+	}
+	return strconv.FormatInt(usage.TotalTokens, 10)
+}
+
+// resolveLoopRun finds a loop run by full ID or unique ID prefix.
+func resolveLoopRun(ctx context.Context, repo *db.LoopRunRepository, ref string) (*models.LoopRun, error) {
+	ref = strings.TrimSpace(ref)
+	if ref == "" {
+		return nil, errors.New("run ID required")
+	}
+
+	run, err := repo.Get(ctx, ref)
+	if err == nil {
+		return run, nil
+	}
+	if !errors.Is(err, db.ErrLoopRunNotFound) {
+		return nil, err
+	}
+
+	matches, err := repo.ListByIDPrefix(ctx, ref, 2)
+	if err != nil {
+		return nil, err
+	}
+	switch len(matches) {
+	case 0:
+		return nil, fmt.Errorf("run %q not found", ref)
+	case 1:
+		return matches[0], nil
+	default:
+		return nil, fmt.Errorf("run prefix %q is ambiguous", ref)
+	}
+}
+
+func runProfileNames(ctx context.Context, repo *db.ProfileRepository, runs []*models.LoopRun) map[string]string {
+	names := make(map[string]string)
+	for _, run := range runs {
+		if run.ProfileID == "" {
+			continue
+		}
+		if _, ok := names[run.ProfileID]; ok {
+			continue
+		}
+		names[run.ProfileID] = shortID(run.ProfileID)
+		if profile, err := repo.Get(ctx, run.ProfileID); err == nil {
+			names[run.ProfileID] = profile.Name
+		}
+	}
+	return names
+}
+
+func readRunArtifact(path string) (string, bool) {
+	if path == "" {
+		return "", false
+	}
+	content, err := loop.ReadRunArtifact(path)
+	if err != nil {
+		return "", false
+	}
+	return content, true
+}
+
+func formatRunExitCode(code *int) string {
+	if code == nil {
+		return "-"
+	}
+	return fmt.Sprintf("%d", *code)
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+	return ""
+}
@@ -5,7 +5,6 @@ import (
 	"fmt"
 	"os"
 	"sort"
-	"strings"
 	"time"
 
 	"github.com/spf13/cobra"
@@ -23,26 +22,17 @@ var (
 	loopScaleInterval      string
 	loopScaleMaxRuntime    string
 	loopScaleMaxIterations int
+	loopScaleMaxTokens     int64
+	loopScaleMaxCost       float64
 	loopScaleTags          string
+	loopScaleRestart       string
+	loopScaleSchedule      string
+	loopScaleIsolate       string
 	loopScaleNamePrefix    string
 	loopScaleKill          bool
 
-	loopScaleQuantStopCmd        string
-	loopScaleQuantStopEvery      int
-	loopScaleQuantStopWhen       string
-	loopScaleQuantStopDecision   string
-	loopScaleQuantStopExitCodes  string
-	loopScaleQuantStopExitInvert bool
-	loopScaleQuantStopStdoutMode string
-	loopScaleQuantStopStderrMode string
-	loopScaleQuantStopStdoutRe   string
-	loopScaleQuantStopStderrRe   string
-	loopScaleQuantStopTimeout    string
-
-	loopScaleQualStopEvery     int
-	loopScaleQualStopPrompt    string
-	loopScaleQualStopPromptMsg string
-	loopScaleQualStopOnInvalid string
+	loopScaleStop  = loop.DefaultStopSpec()
+	loopScaleChain loop.ChainSpec
 )
 
 func init() {
@@ -56,26 +46,17 @@ func init() {
 	loopScaleCmd.Flags().StringVar(&loopScaleInterval, "interval", "", "sleep interval")
 	loopScaleCmd.Flags().StringVarP(&loopScaleMaxRuntime, "max-runtime", "r", "", "max runtime before stopping (e.g., 30m, 2h)")
 	loopScaleCmd.Flags().IntVarP(&loopScaleMaxIterations, "max-iterations", "i", 0, "max iterations before stopping (> 0 required for new loops)")
+	loopScaleCmd.Flags().Int64Var(&loopScaleMaxTokens, "max-tokens", 0, "stop the loop once its runs have used this many tokens (0 = no limit)")
+	loopScaleCmd.Flags().Float64Var(&loopScaleMaxCost, "max-cost", 0, "stop the loop once its runs have cost this much in USD (0 = no limit)")
 	loopScaleCmd.Flags().StringVar(&loopScaleTags, "tags", "", "comma-separated tags")
+	loopScaleCmd.Flags().StringVar(&loopScaleSchedule, "schedule", "", "run on a cron expression, inside time windows (window:Mon-Fri 09:00-17:00) or only when triggered (trigger)")
+	loopScaleCmd.Flags().StringVar(&loopScaleRestart, "restart", "never", "restart policy when the loop runner exits (never|on-failure|always)")
+	loopScaleCmd.Flags().StringVar(&loopScaleIsolate, "isolate", "none", "working tree isolation per loop (none|worktree)")
 	loopScaleCmd.Flags().StringVar(&loopScaleNamePrefix, "name-prefix", "", "name prefix for new loops")
 	loopScaleCmd.Flags().BoolVar(&loopScaleKill, "kill", false, "kill extra loops instead of stopping")
 
-	loopScaleCmd.Flags().StringVar(&loopScaleQuantStopCmd, "quantitative-stop-cmd", "", "quantitative stop: command to execute (bash -lc)")
-	loopScaleCmd.Flags().IntVar(&loopScaleQuantStopEvery, "quantitative-stop-every", 1, "quantitative stop: evaluate every N iterations (> 0)")
-	loopScaleCmd.Flags().StringVar(&loopScaleQuantStopWhen, "quantitative-stop-when", "before", "quantitative stop: when to evaluate (before|after|both)")
-	loopScaleCmd.Flags().StringVar(&loopScaleQuantStopDecision, "quantitative-stop-decision", "stop", "quantitative stop: decision on match (stop|continue)")
-	loopScaleCmd.Flags().StringVar(&loopScaleQuantStopExitCodes, "quantitative-stop-exit-codes", "", "quantitative stop: match exit codes (comma-separated ints)")
-	loopScaleCmd.Flags().BoolVar(&loopScaleQuantStopExitInvert, "quantitative-stop-exit-invert", false, "quantitative stop: invert exit code match (match when exit not in codes)")
-	loopScaleCmd.Flags().StringVar(&loopScaleQuantStopStdoutMode, "quantitative-stop-stdout", "any", "quantitative stop: stdout mode (any|empty|nonempty)")
-	loopScaleCmd.Flags().StringVar(&loopScaleQuantStopStderrMode, "quantitative-stop-stderr", "any", "quantitative stop: stderr mode (any|empty|nonempty)")
-	loopScaleCmd.Flags().StringVar(&loopScaleQuantStopStdoutRe, "quantitative-stop-stdout-regex", "", "quantitative stop: stdout regex (RE2)")
-	loopScaleCmd.Flags().StringVar(&loopScaleQuantStopStderrRe, "quantitative-stop-stderr-regex", "", "quantitative stop: stderr regex (RE2)")
-	loopScaleCmd.Flags().StringVar(&loopScaleQuantStopTimeout, "quantitative-stop-timeout", "", "quantitative stop: command timeout (duration, e.g. 10s)")
-
-	loopScaleCmd.Flags().IntVar(&loopScaleQualStopEvery, "qualitative-stop-every", 0, "qualitative stop: run every N main iterations (> 0)")
-	loopScaleCmd.Flags().StringVar(&loopScaleQualStopPrompt, "qualitative-stop-prompt", "", "qualitative stop: prompt path or prompt name under .forge/prompts/")
-	loopScaleCmd.Flags().StringVar(&loopScaleQualStopPromptMsg, "qualitative-stop-prompt-msg", "", "qualitative stop: inline prompt content")
-	loopScaleCmd.Flags().StringVar(&loopScaleQualStopOnInvalid, "qualitative-stop-on-invalid", "continue", "qualitative stop: on invalid judge output (stop|continue)")
+	addLoopStopFlags(loopScaleCmd, &loopScaleStop)
+	addLoopChainFlags(loopScaleCmd, &loopScaleChain)
 }
 
 var loopScaleCmd = &cobra.Command{
@@ -85,7 +66,17 @@ var loopScaleCmd = &cobra.Command{
 
 For new loops, you can configure smart stop:
 - quantitative: run a command and stop/continue on match (--quantitative-stop-*)
-- qualitative: every N main iterations, run a judge iteration; agent prints 0(stop) or 1(continue) (--qualitative-stop-*)`,
+- qualitative: every N main iterations, run a judge iteration; agent prints 0(stop) or 1(continue) (--qualitative-stop-*)
+- rules: after each main iteration, check built-in conditions (--stop-rule, repeatable):
+    no_changes=N       working tree unchanged for N iterations in a row
+    same_output=N      identical output N times in a row
+    failures=N         N failed iterations in a row
+    queue_empty=beads  no open beads issues (or queue_empty=fmail:<topic|@agent>)
+    file_exists=PATH   PATH exists
+    command=CMD        CMD exits 0 (takes the rest of the value)
+  Options follow as ,key=value: name, action (stop|pause|switch_prompt|notify),
+  pause, prompt, prompt_msg, message. --stop-rules-combine=all acts only once
+  every rule matches.`,
 	Args: cobra.NoArgs,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		if loopScaleCount < 0 {
@@ -108,6 +99,21 @@ For new loops, you can configure smart stop:
 		if loopScaleMaxIterations < 0 {
 			return fmt.Errorf("max iterations must be >= 0")
 		}
+		if loopScaleMaxTokens < 0 || loopScaleMaxCost < 0 {
+			return fmt.Errorf("max tokens and max cost must be >= 0")
+		}
+		restartPolicy, err := parseRestartPolicy(loopScaleRestart)
+		if err != nil {
+			return err
+		}
+		schedule, err := parseSchedule(loopScaleSchedule)
+		if err != nil {
+			return err
+		}
+		isolation, err := parseIsolation(loopScaleIsolate)
+		if err != nil {
+			return err
+		}
 		maxRuntime, err := parseDuration(loopScaleMaxRuntime, 0)
 		if err != nil {
 			return err
@@ -138,85 +144,13 @@ For new loops, you can configure smart stop:
 
 		tags := parseTags(loopScaleTags)
 
-		stopCfg := models.LoopStopConfig{}
-		if strings.TrimSpace(loopScaleQuantStopCmd) != "" {
-			if loopScaleQuantStopEvery <= 0 {
-				return fmt.Errorf("quantitative stop every must be > 0")
-			}
-			exitCodes, err := parseCSVInts(loopScaleQuantStopExitCodes)
-			if err != nil {
-				return fmt.Errorf("quantitative stop exit codes: %w", err)
-			}
-			timeout, err := parseDuration(loopScaleQuantStopTimeout, 0)
-			if err != nil {
-				return err
-			}
-			if timeout < 0 {
-				return fmt.Errorf("quantitative stop timeout must be >= 0")
-			}
-			stdoutMode := strings.ToLower(strings.TrimSpace(loopScaleQuantStopStdoutMode))
-			stderrMode := strings.ToLower(strings.TrimSpace(loopScaleQuantStopStderrMode))
-			if stdoutMode == "" {
-				stdoutMode = "any"
-			}
-			if stderrMode == "" {
-				stderrMode = "any"
-			}
-			noCriteria := len(exitCodes) == 0 &&
-				stdoutMode == "any" &&
-				stderrMode == "any" &&
-				strings.TrimSpace(loopScaleQuantStopStdoutRe) == "" &&
-				strings.TrimSpace(loopScaleQuantStopStderrRe) == ""
-			if noCriteria {
-				exitCodes = []int{0}
-			}
-
-			stopCfg.Quant = &models.LoopQuantStopConfig{
-				Cmd:            loopScaleQuantStopCmd,
-				EveryN:         loopScaleQuantStopEvery,
-				When:           loopScaleQuantStopWhen,
-				Decision:       loopScaleQuantStopDecision,
-				ExitCodes:      exitCodes,
-				ExitInvert:     loopScaleQuantStopExitInvert,
-				StdoutMode:     stdoutMode,
-				StderrMode:     stderrMode,
-				StdoutRegex:    loopScaleQuantStopStdoutRe,
-				StderrRegex:    loopScaleQuantStopStderrRe,
-				TimeoutSeconds: int(timeout.Round(time.Second).Seconds()),
-			}
+		stopCfg, err := buildStopConfig(repoPath, loopScaleStop)
+		if err != nil {
+			return err
 		}
-
-		if loopScaleQualStopEvery > 0 ||
-			strings.TrimSpace(loopScaleQualStopPrompt) != "" ||
-			strings.TrimSpace(loopScaleQualStopPromptMsg) != "" {
-			if loopScaleQualStopEvery <= 0 {
-				return fmt.Errorf("qualitative stop every must be > 0")
-			}
-			if strings.TrimSpace(loopScaleQualStopPrompt) != "" && strings.TrimSpace(loopScaleQualStopPromptMsg) != "" {
-				return fmt.Errorf("use either --qualitative-stop-prompt or --qualitative-stop-prompt-msg, not both")
-			}
-
-			payload := models.NextPromptOverridePayload{}
-			if strings.TrimSpace(loopScaleQualStopPromptMsg) != "" {
-				payload.Prompt = strings.TrimSpace(loopScaleQualStopPromptMsg)
-				payload.IsPath = false
-			} else {
-				if strings.TrimSpace(loopScaleQualStopPrompt) == "" {
-					return fmt.Errorf("qualitative stop requires --qualitative-stop-prompt or --qualitative-stop-prompt-msg")
-				}
-				resolved, _, err := resolvePromptPath(repoPath, loopScaleQualStopPrompt)
-				if err != nil {
-					return err
-				}
-				payload.Prompt = resolved
-				payload.IsPath = true
-			}
-
-			stopCfg.Qual = &models.LoopQualStopConfig{
-				EveryN:    loopScaleQualStopEvery,
-				Prompt:    payload,
-				OnInvalid: loopScaleQualStopOnInvalid,
-			}
+		chainCfg, err := buildChainConfig(loopScaleChain)
+		if err != nil {
+			return err
 		}
 
 		database, err := openDatabase()
@@ -298,25 +232,17 @@ For new loops, you can configure smart stop:
 					IntervalSeconds:   int(interval.Round(time.Second).Seconds()),
 					MaxIterations:     loopScaleMaxIterations,
 					MaxRuntimeSeconds: int(maxRuntime.Round(time.Second).Seconds()),
+					MaxTokens:         loopScaleMaxTokens,
+					MaxCostUSD:        loopScaleMaxCost,
 					PoolID:            poolID,
 					ProfileID:         profileID,
 					Tags:              tags,
 					State:             models.LoopStateStopped,
+					RestartPolicy:     restartPolicy,
+					Schedule:          schedule,
+					Metadata:          loopConfigMetadata(stopCfg, chainCfg),
 				}
-				if stopCfg.Quant != nil || stopCfg.Qual != nil {
-					loopEntry.Metadata = map[string]any{"stop_config": stopCfg}
-				}
-				if err := loopRepo.Create(context.Background(), loopEntry); err != nil {
-					return err
-				}
-
-				loopEntry.LogPath = loop.LogPath(cfg.Global.DataDir, loopEntry.Name, loopEntry.ID)
-				loopEntry.LedgerPath = loop.LedgerPath(repoPath, loopEntry.Name, loopEntry.ID)
-				if err := loopRepo.Update(context.Background(), loopEntry); err != nil {
-					return err
-				}
-
-				if err := startLoopProcess(loopEntry.ID); err != nil {
+				if err := createLoop(context.Background(), loopRepo, loopEntry, isolation); err != nil {
 					return err
 				}
 			}
@@ -0,0 +1,338 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/tOgg1/forge/internal/db"
+	"github.com/tOgg1/forge/internal/loop"
+	"github.com/tOgg1/forge/internal/models"
+)
+
+// addLoopStopFlags registers the --quantitative-stop-* and --qualitative-stop-*
+// flags, bound to spec.
+func addLoopStopFlags(cmd *cobra.Command, spec *loop.StopSpec) {
+	defaults := loop.DefaultStopSpec()
+	quant := &spec.Quantitative
+	qual := &spec.Qualitative
+
+	flags := cmd.Flags()
+	flags.StringVar(&quant.Cmd, "quantitative-stop-cmd", "", "quantitative stop: command to execute (bash -lc)")
+	flags.IntVar(&quant.Every, "quantitative-stop-every", defaults.Quantitative.Every, "quantitative stop: evaluate every N iterations (> 0)")
+	flags.StringVar(&quant.When, "quantitative-stop-when", defaults.Quantitative.When, "quantitative stop: when to evaluate (before|after|both)")
+	flags.StringVar(&quant.Decision, "quantitative-stop-decision", defaults.Quantitative.Decision, "quantitative stop: decision on match (stop|continue)")
+	flags.IntSliceVar(&quant.ExitCodes, "quantitative-stop-exit-codes", nil, "quantitative stop: match exit codes (comma-separated ints)")
+	flags.BoolVar(&quant.ExitInvert, "quantitative-stop-exit-invert", false, "quantitative stop: invert exit code match (match when exit not in codes)")
+	flags.StringVar(&quant.Stdout, "quantitative-stop-stdout", defaults.Quantitative.Stdout, "quantitative stop: stdout mode (any|empty|nonempty)")
+	flags.StringVar(&quant.Stderr, "quantitative-stop-stderr", defaults.Quantitative.Stderr, "quantitative stop: stderr mode (any|empty|nonempty)")
+	flags.StringVar(&quant.StdoutRegex, "quantitative-stop-stdout-regex", "", "quantitative stop: stdout regex (RE2)")
+	flags.StringVar(&quant.StderrRegex, "quantitative-stop-stderr-regex", "", "quantitative stop: stderr regex (RE2)")
+	flags.StringVar(&quant.Timeout, "quantitative-stop-timeout", "", "quantitative stop: command timeout (duration, e.g. 10s)")
+
+	flags.IntVar(&qual.Every, "qualitative-stop-every", 0, "qualitative stop: run every N main iterations (> 0)")
+	flags.StringVar(&qual.Prompt, "qualitative-stop-prompt", "", "qualitative stop: prompt path or prompt name under .forge/prompts/")
+	flags.StringVar(&qual.PromptMsg, "qualitative-stop-prompt-msg", "", "qualitative stop: inline prompt content")
+	flags.StringVar(&qual.OnInvalid, "qualitative-stop-on-invalid", defaults.Qualitative.OnInvalid, "qualitative stop: on invalid judge output (stop|continue)")
+
+	flags.StringArrayVar(&spec.RuleFlags, "stop-rule", nil, "stop rule: kind[=value][,key=value...] (repeatable)")
+	flags.StringVar(&spec.Combine, "stop-rules-combine", "", "stop rules: act when any rule matches or only when all match (any|all)")
+}
+
+// buildStopConfig validates stop rules and resolves their prompts against repoPath.
+func buildStopConfig(repoPath string, spec loop.StopSpec) (models.LoopStopConfig, error) {
+	stopCfg := models.LoopStopConfig{}
+
+	quant := spec.Quantitative
+	if strings.TrimSpace(quant.Cmd) != "" {
+		if quant.Every <= 0 {
+			return stopCfg, fmt.Errorf("quantitative stop every must be > 0")
+		}
+		quantCfg, err := buildQuantStopConfig(quant)
+		if err != nil {
+			return stopCfg, err
+		}
+		stopCfg.Quant = quantCfg
+	}
+
+	qual := spec.Qualitative
+	if qual.Every > 0 ||
+		strings.TrimSpace(qual.Prompt) != "" ||
+		strings.TrimSpace(qual.PromptMsg) != "" {
+		if qual.Every <= 0 {
+			return stopCfg, fmt.Errorf("qualitative stop every must be > 0")
+		}
+		if strings.TrimSpace(qual.Prompt) != "" && strings.TrimSpace(qual.PromptMsg) != "" {
+			return stopCfg, fmt.Errorf("use either --qualitative-stop-prompt or --qualitative-stop-prompt-msg, not both")
+		}
+
+		payload := models.NextPromptOverridePayload{}
+		if strings.TrimSpace(qual.PromptMsg) != "" {
+			payload.Prompt = strings.TrimSpace(qual.PromptMsg)
+			payload.IsPath = false
+		} else {
+			if strings.TrimSpace(qual.Prompt) == "" {
+				return stopCfg, fmt.Errorf("qualitative stop requires --qualitative-stop-prompt or --qualitative-stop-prompt-msg")
+			}
+			resolved, _, err := resolvePromptPath(repoPath, qual.Prompt)
+			if err != nil {
+				return stopCfg, err
+			}
+			payload.Prompt = resolved
+			payload.IsPath = true
+		}
+
+		stopCfg.Qual = &models.LoopQualStopConfig{
+			EveryN:    qual.Every,
+			Prompt:    payload,
+			OnInvalid: qual.OnInvalid,
+		}
+	}
+
+	ruleSpecs := append([]loop.StopRuleSpec{}, spec.Rules...)
+	for _, value := range spec.RuleFlags {
+		ruleSpec, err := parseStopRuleFlag(value)
+		if err != nil {
+			return stopCfg, err
+		}
+		ruleSpecs = append(ruleSpecs, ruleSpec)
+	}
+	kinds := make(map[string]int, len(ruleSpecs))
+	for _, ruleSpec := range ruleSpecs {
+		kinds[strings.ToLower(strings.TrimSpace(ruleSpec.Kind))]++
+	}
+	names := make(map[string]bool, len(ruleSpecs))
+	for i, ruleSpec := range ruleSpecs {
+		rule, err := buildStopRule(repoPath, ruleSpec)
+		if err != nil {
+			return stopCfg, fmt.Errorf("stop rule %d: %w", i+1, err)
+		}
+		// Unnamed rules are named after their kind when it is unique.
+		if rule.Name == "" && kinds[string(rule.Kind)] == 1 {
+			rule.Name = string(rule.Kind)
+		}
+		stopCfg.Rules = append(stopCfg.Rules, rule)
+		name := stopCfg.RuleName(i)
+		if names[name] {
+			return stopCfg, fmt.Errorf("duplicate stop rule name %q", name)
+		}
+		names[name] = true
+	}
+
+	switch combine := strings.ToLower(strings.TrimSpace(spec.Combine)); combine {
+	case "", models.LoopStopCombineAny:
+	case models.LoopStopCombineAll:
+		if len(stopCfg.Rules) > 0 {
+			stopCfg.Combine = combine
+		}
+	default:
+		return stopCfg, fmt.Errorf("invalid stop rules combine %q (use any or all)", spec.Combine)
+	}
+
+	return stopCfg, nil
+}
+
+// buildQuantStopConfig validates a command stop rule. Without match criteria
+// it matches a successful exit.
+func buildQuantStopConfig(quant loop.QuantStopSpec) (*models.LoopQuantStopConfig, error) {
+	timeout, err := parseDuration(quant.Timeout, 0)
+	if err != nil {
+		return nil, err
+	}
+	if timeout < 0 {
+		return nil, fmt.Errorf("quantitative stop timeout must be >= 0")
+	}
+	exitCodes := quant.ExitCodes
+	stdoutMode := strings.ToLower(strings.TrimSpace(quant.Stdout))
+	stderrMode := strings.ToLower(strings.TrimSpace(quant.Stderr))
+	if stdoutMode == "" {
+		stdoutMode = "any"
+	}
+	if stderrMode == "" {
+		stderrMode = "any"
+	}
+	noCriteria := len(exitCodes) == 0 &&
+		stdoutMode == "any" &&
+		stderrMode == "any" &&
+		strings.TrimSpace(quant.StdoutRegex) == "" &&
+		strings.TrimSpace(quant.StderrRegex) == ""
+	if noCriteria {
+		// Default: match success.
+		exitCodes = []int{0}
+	}
+
+	return &models.LoopQuantStopConfig{
+		Cmd:            quant.Cmd,
+		EveryN:         quant.Every,
+		When:           quant.When,
+		Decision:       quant.Decision,
+		ExitCodes:      exitCodes,
+		ExitInvert:     quant.ExitInvert,
+		StdoutMode:     stdoutMode,
+		StderrMode:     stderrMode,
+		StdoutRegex:    quant.StdoutRegex,
+		StderrRegex:    quant.StderrRegex,
+		TimeoutSeconds: int(timeout.Round(time.Second).Seconds()),
+	}, nil
+}
+
+// buildStopRule validates a composite stop rule and resolves its prompt
+// against repoPath.
+func buildStopRule(repoPath string, spec loop.StopRuleSpec) (models.LoopStopRule, error) {
+	rule := models.LoopStopRule{
+		Name:    strings.TrimSpace(spec.Name),
+		Kind:    models.LoopStopRuleKind(strings.ToLower(strings.TrimSpace(spec.Kind))),
+		Count:   spec.Count,
+		Path:    strings.TrimSpace(spec.Path),
+		Queue:   strings.ToLower(strings.TrimSpace(spec.Queue)),
+		Topic:   strings.TrimSpace(spec.Topic),
+		Action:  models.LoopStopAction(strings.ToLower(strings.TrimSpace(spec.Action))),
+		Message: strings.TrimSpace(spec.Message),
+	}
+
+	if rule.Kind == models.LoopStopRuleCommand && strings.TrimSpace(spec.Cmd) != "" {
+		command, err := buildQuantStopConfig(loop.QuantStopSpec{
+			Cmd:         spec.Cmd,
+			ExitCodes:   spec.ExitCodes,
+			ExitInvert:  spec.ExitInvert,
+			Stdout:      spec.Stdout,
+			Stderr:      spec.Stderr,
+			StdoutRegex: spec.StdoutRegex,
+			StderrRegex: spec.StderrRegex,
+			Timeout:     spec.Timeout,
+		})
+		if err != nil {
+			return rule, err
+		}
+		rule.Command = command
+	}
+
+	if strings.TrimSpace(spec.Pause) != "" {
+		pause, err := parseDuration(spec.Pause, 0)
+		if err != nil {
+			return rule, err
+		}
+		rule.PauseSeconds = int(pause.Round(time.Second).Seconds())
+	}
+
+	if strings.TrimSpace(spec.Prompt) != "" && strings.TrimSpace(spec.PromptMsg) != "" {
+		return rule, fmt.Errorf("use either prompt or prompt_msg, not both")
+	}
+	if strings.TrimSpace(spec.PromptMsg) != "" {
+		rule.Prompt = &models.NextPromptOverridePayload{Prompt: strings.TrimSpace(spec.PromptMsg)}
+	} else if strings.TrimSpace(spec.Prompt) != "" {
+		resolved, _, err := resolvePromptPath(repoPath, spec.Prompt)
+		if err != nil {
+			return rule, err
+		}
+		rule.Prompt = &models.NextPromptOverridePayload{Prompt: resolved, IsPath: true}
+	}
+
+	if err := rule.Validate(); err != nil {
+		return rule, err
+	}
+	return rule, nil
+}
+
+// parseStopRuleFlag parses a --stop-rule value of the form
+// kind[=value][,key=value...]. The value is the count of no_changes,
+// same_output and failures rules, the path of file_exists rules, "beads" or
+// "fmail:<topic>" for queue_empty rules and the command of command rules.
+// A command takes the rest of the flag, so command rules accept no options.
+func parseStopRuleFlag(value string) (loop.StopRuleSpec, error) {
+	spec := loop.StopRuleSpec{}
+	head, rest, _ := strings.Cut(value, ",")
+	kind, arg, hasArg := strings.Cut(head, "=")
+	spec.Kind = strings.ToLower(strings.TrimSpace(kind))
+	arg = strings.TrimSpace(arg)
+
+	switch models.LoopStopRuleKind(spec.Kind) {
+	case models.LoopStopRuleCommand:
+		_, cmd, _ := strings.Cut(value, "=")
+		spec.Cmd = strings.TrimSpace(cmd)
+		return spec, nil
+	case models.LoopStopRuleNoChanges, models.LoopStopRuleSameOutput, models.LoopStopRuleFailures:
+		if hasArg {
+			count, err := strconv.Atoi(arg)
+			if err != nil {
+				return spec, fmt.Errorf("invalid --stop-rule %q: count must be an integer", value)
+			}
+			spec.Count = count
+		}
+	case models.LoopStopRuleFileExists:
+		spec.Path = arg
+	case models.LoopStopRuleQueueEmpty:
+		queue, topic, _ := strings.Cut(arg, ":")
+		spec.Queue = queue
+		spec.Topic = topic
+	default:
+		return spec, fmt.Errorf("invalid --stop-rule %q: unknown kind %q", value, kind)
+	}
+
+	if strings.TrimSpace(rest) == "" {
+		return spec, nil
+	}
+	for _, option := range strings.Split(rest, ",") {
+		key, optionValue, ok := strings.Cut(option, "=")
+		if !ok {
+			return spec, fmt.Errorf("invalid --stop-rule %q: expected key=value, got %q", value, option)
+		}
+		optionValue = strings.TrimSpace(optionValue)
+		switch strings.ToLower(strings.TrimSpace(key)) {
+		case "name":
+			spec.Name = optionValue
+		case "action":
+			spec.Action = optionValue
+		case "pause":
+			spec.Pause = optionValue
+		case "prompt":
+			spec.Prompt = optionValue
+		case "prompt_msg", "prompt-msg":
+			spec.PromptMsg = optionValue
+		case "message":
+			spec.Message = optionValue
+		default:
+			return spec, fmt.Errorf("invalid --stop-rule %q: unknown option %q", value, key)
+		}
+	}
+	return spec, nil
+}
+
+// loopConfigMetadata returns loop metadata holding stopCfg and chainCfg, or nil
+// when both are empty.
+func loopConfigMetadata(stopCfg models.LoopStopConfig, chainCfg models.LoopChainConfig) map[string]any {
+	metadata := loop.ChainMetadata(chainCfg)
+	if !stopCfg.Empty() {
+		if metadata == nil {
+			metadata = make(map[string]any)
+		}
+		metadata["stop_config"] = stopCfg
+	}
+	return metadata
+}
+
+// createLoop stores a new loop, moves it into a worktree when isolated, fills in
+// its log and ledger paths and starts its runner.
+func createLoop(ctx context.Context, loopRepo *db.LoopRepository, loopEntry *models.Loop, isolation models.LoopIsolation) error {
+	repoPath := loopEntry.RepoPath
+	if err := loopRepo.Create(ctx, loopEntry); err != nil {
+		return err
+	}
+	if isolation == models.LoopIsolationWorktree {
+		if err := isolateLoop(loopEntry, repoPath); err != nil {
+			_ = loopRepo.Delete(ctx, loopEntry.ID)
+			return err
+		}
+	}
+
+	loopEntry.LogPath = loop.LogPath(GetConfig().Global.DataDir, loopEntry.Name, loopEntry.ID)
+	loopEntry.LedgerPath = loop.LedgerPath(repoPath, loopEntry.Name, loopEntry.ID)
+	if err := loopRepo.Update(ctx, loopEntry); err != nil {
+		return err
+	}
+
+	return startLoopProcessFunc(loopEntry.ID)
+}
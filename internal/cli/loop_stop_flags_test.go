@@ -0,0 +1,64 @@
+package cli
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/tOgg1/forge/internal/loop"
+	"github.com/tOgg1/forge/internal/models"
+)
+
+func TestBuildStopConfigRules(t *testing.T) {
+	spec := loop.DefaultStopSpec()
+	spec.Combine = "all"
+	spec.Rules = []loop.StopRuleSpec{
+		{Kind: "failures", Count: 3, Action: "pause", Pause: "10m"},
+	}
+	spec.RuleFlags = []string{
+		"no_changes=2,name=idle,action=notify,message=nothing to do",
+		"queue_empty=fmail:tasks",
+		"command=test -f DONE, or not",
+	}
+
+	cfg, err := buildStopConfig(t.TempDir(), spec)
+	if err != nil {
+		t.Fatalf("build stop config: %v", err)
+	}
+	if cfg.Combine != models.LoopStopCombineAll || len(cfg.Rules) != 4 {
+		t.Fatalf("expected 4 rules combined with all, got %+v", cfg)
+	}
+
+	failures := cfg.Rules[0]
+	if failures.Name != "failures" || failures.Count != 3 || failures.Action != models.LoopStopActionPause || failures.PauseSeconds != 600 {
+		t.Fatalf("unexpected failures rule: %+v", failures)
+	}
+	idle := cfg.Rules[1]
+	if idle.Name != "idle" || idle.Kind != models.LoopStopRuleNoChanges || idle.Count != 2 || idle.Message != "nothing to do" {
+		t.Fatalf("unexpected no_changes rule: %+v", idle)
+	}
+	queue := cfg.Rules[2]
+	if queue.Queue != models.LoopStopQueueFmail || queue.Topic != "tasks" {
+		t.Fatalf("unexpected queue_empty rule: %+v", queue)
+	}
+	command := cfg.Rules[3]
+	if command.Command == nil || command.Command.Cmd != "test -f DONE, or not" || len(command.Command.ExitCodes) != 1 {
+		t.Fatalf("unexpected command rule: %+v", command)
+	}
+
+	for _, tc := range []struct {
+		flags []string
+		want  string
+	}{
+		{flags: []string{"bogus=1"}, want: "unknown kind"},
+		{flags: []string{"failures=x"}, want: "count must be an integer"},
+		{flags: []string{"file_exists"}, want: "requires path"},
+		{flags: []string{"failures=1,action=pause"}, want: "requires a pause duration"},
+		{flags: []string{"failures=1", "failures=2,name=failures-1"}, want: "duplicate stop rule name"},
+	} {
+		spec := loop.DefaultStopSpec()
+		spec.RuleFlags = tc.flags
+		if _, err := buildStopConfig(t.TempDir(), spec); err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Fatalf("flags %q: expected error containing %q, got %v", tc.flags, tc.want, err)
+		}
+	}
+}
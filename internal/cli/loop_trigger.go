@@ -0,0 +1,82 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/tOgg1/forge/internal/db"
+	"github.com/tOgg1/forge/internal/models"
+)
+
+func init() {
+	loopInternalCmd.AddCommand(loopTriggerCmd)
+}
+
+var loopTriggerCmd = &cobra.Command{
+	Use:   "trigger <loop>",
+	Short: "Run a loop now, ignoring its schedule",
+	Long: `Queue a trigger that wakes a sleeping loop so its next iteration runs
+immediately, whatever its schedule or interval. Loops scheduled with
+"trigger" only run when triggered.
+
+A stopped or errored loop is started and runs right away.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		database, err := openDatabase()
+		if err != nil {
+			return err
+		}
+		defer database.Close()
+
+		ctx := context.Background()
+		loopRepo := db.NewLoopRepository(database)
+		queueRepo := db.NewLoopQueueRepository(database)
+
+		loopEntry, err := resolveLoopByRef(ctx, loopRepo, args[0])
+		if err != nil {
+			return err
+		}
+
+		payload, err := json.Marshal(models.TriggerPayload{Reason: "operator"})
+		if err != nil {
+			return err
+		}
+		item := &models.LoopQueueItem{Type: models.LoopQueueItemTrigger, Payload: payload}
+		if err := queueRepo.Enqueue(ctx, loopEntry.ID, item); err != nil {
+			return err
+		}
+
+		started := false
+		switch loopEntry.State {
+		case models.LoopStateStopped, models.LoopStateError:
+			if err := startLoopProcessFunc(loopEntry.ID); err != nil {
+				return err
+			}
+			started = true
+		}
+
+		if IsJSONOutput() || IsJSONLOutput() {
+			return WriteOutput(os.Stdout, map[string]any{
+				"triggered": true,
+				"started":   started,
+				"loop_id":   loopEntry.ID,
+				"name":      loopEntry.Name,
+				"item_id":   item.ID,
+			})
+		}
+
+		if IsQuiet() {
+			return nil
+		}
+
+		if started {
+			fmt.Fprintf(os.Stdout, "Loop %q started and triggered (%s)\n", loopEntry.Name, loopShortID(loopEntry))
+		} else {
+			fmt.Fprintf(os.Stdout, "Loop %q triggered (%s)\n", loopEntry.Name, loopShortID(loopEntry))
+		}
+		return nil
+	},
+}
@@ -25,24 +25,15 @@ var (
 	loopUpInterval      string
 	loopUpMaxRuntime    string
 	loopUpMaxIterations int
+	loopUpMaxTokens     int64
+	loopUpMaxCost       float64
 	loopUpTags          string
+	loopUpRestart       string
+	loopUpSchedule      string
+	loopUpIsolate       string
 
-	loopUpQuantStopCmd        string
-	loopUpQuantStopEvery      int
-	loopUpQuantStopWhen       string
-	loopUpQuantStopDecision   string
-	loopUpQuantStopExitCodes  string
-	loopUpQuantStopExitInvert bool
-	loopUpQuantStopStdoutMode string
-	loopUpQuantStopStderrMode string
-	loopUpQuantStopStdoutRe   string
-	loopUpQuantStopStderrRe   string
-	loopUpQuantStopTimeout    string
-
-	loopUpQualStopEvery     int
-	loopUpQualStopPrompt    string
-	loopUpQualStopPromptMsg string
-	loopUpQualStopOnInvalid string
+	loopUpStop  = loop.DefaultStopSpec()
+	loopUpChain loop.ChainSpec
 
 	startLoopProcessFunc = startLoopProcess
 )
@@ -60,24 +51,15 @@ func init() {
 	loopUpCmd.Flags().StringVar(&loopUpInterval, "interval", "", "sleep interval (e.g., 30s, 2m)")
 	loopUpCmd.Flags().StringVarP(&loopUpMaxRuntime, "max-runtime", "r", "", "max runtime before stopping (e.g., 30m, 2h)")
 	loopUpCmd.Flags().IntVarP(&loopUpMaxIterations, "max-iterations", "i", 0, "max iterations before stopping (> 0 required)")
+	loopUpCmd.Flags().Int64Var(&loopUpMaxTokens, "max-tokens", 0, "stop the loop once its runs have used this many tokens (0 = no limit)")
+	loopUpCmd.Flags().Float64Var(&loopUpMaxCost, "max-cost", 0, "stop the loop once its runs have cost this much in USD (0 = no limit)")
 	loopUpCmd.Flags().StringVar(&loopUpTags, "tags", "", "comma-separated tags")
+	loopUpCmd.Flags().StringVar(&loopUpSchedule, "schedule", "", "run on a cron expression, inside time windows (window:Mon-Fri 09:00-17:00) or only when triggered (trigger)")
+	loopUpCmd.Flags().StringVar(&loopUpRestart, "restart", "never", "restart policy when the loop runner exits (never|on-failure|always)")
+	loopUpCmd.Flags().StringVar(&loopUpIsolate, "isolate", "none", "working tree isolation per loop (none|worktree)")
 
-	loopUpCmd.Flags().StringVar(&loopUpQuantStopCmd, "quantitative-stop-cmd", "", "quantitative stop: command to execute (bash -lc)")
-	loopUpCmd.Flags().IntVar(&loopUpQuantStopEvery, "quantitative-stop-every", 1, "quantitative stop: evaluate every N iterations (> 0)")
-	loopUpCmd.Flags().StringVar(&loopUpQuantStopWhen, "quantitative-stop-when", "before", "quantitative stop: when to evaluate (before|after|both)")
-	loopUpCmd.Flags().StringVar(&loopUpQuantStopDecision, "quantitative-stop-decision", "stop", "quantitative stop: decision on match (stop|continue)")
-	loopUpCmd.Flags().StringVar(&loopUpQuantStopExitCodes, "quantitative-stop-exit-codes", "", "quantitative stop: match exit codes (comma-separated ints)")
-	loopUpCmd.Flags().BoolVar(&loopUpQuantStopExitInvert, "quantitative-stop-exit-invert", false, "quantitative stop: invert exit code match (match when exit not in codes)")
-	loopUpCmd.Flags().StringVar(&loopUpQuantStopStdoutMode, "quantitative-stop-stdout", "any", "quantitative stop: stdout mode (any|empty|nonempty)")
-	loopUpCmd.Flags().StringVar(&loopUpQuantStopStderrMode, "quantitative-stop-stderr", "any", "quantitative stop: stderr mode (any|empty|nonempty)")
-	loopUpCmd.Flags().StringVar(&loopUpQuantStopStdoutRe, "quantitative-stop-stdout-regex", "", "quantitative stop: stdout regex (RE2)")
-	loopUpCmd.Flags().StringVar(&loopUpQuantStopStderrRe, "quantitative-stop-stderr-regex", "", "quantitative stop: stderr regex (RE2)")
-	loopUpCmd.Flags().StringVar(&loopUpQuantStopTimeout, "quantitative-stop-timeout", "", "quantitative stop: command timeout (duration, e.g. 10s)")
-
-	loopUpCmd.Flags().IntVar(&loopUpQualStopEvery, "qualitative-stop-every", 0, "qualitative stop: run every N main iterations (> 0)")
-	loopUpCmd.Flags().StringVar(&loopUpQualStopPrompt, "qualitative-stop-prompt", "", "qualitative stop: prompt path or prompt name under .forge/prompts/")
-	loopUpCmd.Flags().StringVar(&loopUpQualStopPromptMsg, "qualitative-stop-prompt-msg", "", "qualitative stop: inline prompt content")
-	loopUpCmd.Flags().StringVar(&loopUpQualStopOnInvalid, "qualitative-stop-on-invalid", "continue", "qualitative stop: on invalid judge output (stop|continue)")
+	addLoopStopFlags(loopUpCmd, &loopUpStop)
+	addLoopChainFlags(loopUpCmd, &loopUpChain)
 }
 
 var loopUpCmd = &cobra.Command{
@@ -87,7 +69,22 @@ var loopUpCmd = &cobra.Command{
 
 Smart stop (optional):
 - quantitative: run a command and stop/continue on match (--quantitative-stop-*)
-- qualitative: every N main iterations, run a judge iteration; agent prints 0(stop) or 1(continue) (--qualitative-stop-*)`,
+- qualitative: every N main iterations, run a judge iteration; agent prints 0(stop) or 1(continue) (--qualitative-stop-*)
+- rules: after each main iteration, check built-in conditions (--stop-rule, repeatable):
+    no_changes=N       working tree unchanged for N iterations in a row
+    same_output=N      identical output N times in a row
+    failures=N         N failed iterations in a row
+    queue_empty=beads  no open beads issues (or queue_empty=fmail:<topic|@agent>)
+    file_exists=PATH   PATH exists
+    command=CMD        CMD exits 0 (takes the rest of the value)
+  Options follow as ,key=value: name, action (stop|pause|switch_prompt|notify),
+  pause, prompt, prompt_msg, message. --stop-rules-combine=all acts only once
+  every rule matches.
+
+Chaining (optional):
+- --on-success LOOP[:reason,...]: when a stop rule ends this loop (quant, qual, rule, condition)
+- --on-stop LOOP[:reason,...]: on any stop of its own, including max_iterations, max_runtime, budget, error
+  The target gets this loop's memory and a handoff message, and is started if stopped.`,
 	Args: cobra.NoArgs,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		if loopUpCount < 1 {
@@ -116,6 +113,21 @@ Smart stop (optional):
 		if loopUpMaxIterations < 0 {
 			return fmt.Errorf("max iterations must be >= 0")
 		}
+		if loopUpMaxTokens < 0 || loopUpMaxCost < 0 {
+			return fmt.Errorf("max tokens and max cost must be >= 0")
+		}
+		restartPolicy, err := parseRestartPolicy(loopUpRestart)
+		if err != nil {
+			return err
+		}
+		schedule, err := parseSchedule(loopUpSchedule)
+		if err != nil {
+			return err
+		}
+		isolation, err := parseIsolation(loopUpIsolate)
+		if err != nil {
+			return err
+		}
 		maxRuntime, err := parseDuration(loopUpMaxRuntime, 0)
 		if err != nil {
 			return err
@@ -149,86 +161,13 @@ Smart stop (optional):
 
 		tags := parseTags(loopUpTags)
 
-		stopCfg := models.LoopStopConfig{}
-		if strings.TrimSpace(loopUpQuantStopCmd) != "" {
-			if loopUpQuantStopEvery <= 0 {
-				return fmt.Errorf("quantitative stop every must be > 0")
-			}
-			exitCodes, err := parseCSVInts(loopUpQuantStopExitCodes)
-			if err != nil {
-				return fmt.Errorf("quantitative stop exit codes: %w", err)
-			}
-			timeout, err := parseDuration(loopUpQuantStopTimeout, 0)
-			if err != nil {
-				return err
-			}
-			if timeout < 0 {
-				return fmt.Errorf("quantitative stop timeout must be >= 0")
-			}
-			stdoutMode := strings.ToLower(strings.TrimSpace(loopUpQuantStopStdoutMode))
-			stderrMode := strings.ToLower(strings.TrimSpace(loopUpQuantStopStderrMode))
-			if stdoutMode == "" {
-				stdoutMode = "any"
-			}
-			if stderrMode == "" {
-				stderrMode = "any"
-			}
-			noCriteria := len(exitCodes) == 0 &&
-				stdoutMode == "any" &&
-				stderrMode == "any" &&
-				strings.TrimSpace(loopUpQuantStopStdoutRe) == "" &&
-				strings.TrimSpace(loopUpQuantStopStderrRe) == ""
-			if noCriteria {
-				// Default: match success.
-				exitCodes = []int{0}
-			}
-
-			stopCfg.Quant = &models.LoopQuantStopConfig{
-				Cmd:            loopUpQuantStopCmd,
-				EveryN:         loopUpQuantStopEvery,
-				When:           loopUpQuantStopWhen,
-				Decision:       loopUpQuantStopDecision,
-				ExitCodes:      exitCodes,
-				ExitInvert:     loopUpQuantStopExitInvert,
-				StdoutMode:     stdoutMode,
-				StderrMode:     stderrMode,
-				StdoutRegex:    loopUpQuantStopStdoutRe,
-				StderrRegex:    loopUpQuantStopStderrRe,
-				TimeoutSeconds: int(timeout.Round(time.Second).Seconds()),
-			}
+		stopCfg, err := buildStopConfig(repoPath, loopUpStop)
+		if err != nil {
+			return err
 		}
-
-		if loopUpQualStopEvery > 0 ||
-			strings.TrimSpace(loopUpQualStopPrompt) != "" ||
-			strings.TrimSpace(loopUpQualStopPromptMsg) != "" {
-			if loopUpQualStopEvery <= 0 {
-				return fmt.Errorf("qualitative stop every must be > 0")
-			}
-			if strings.TrimSpace(loopUpQualStopPrompt) != "" && strings.TrimSpace(loopUpQualStopPromptMsg) != "" {
-				return fmt.Errorf("use either --qualitative-stop-prompt or --qualitative-stop-prompt-msg, not both")
-			}
-
-			payload := models.NextPromptOverridePayload{}
-			if strings.TrimSpace(loopUpQualStopPromptMsg) != "" {
-				payload.Prompt = strings.TrimSpace(loopUpQualStopPromptMsg)
-				payload.IsPath = false
-			} else {
-				if strings.TrimSpace(loopUpQualStopPrompt) == "" {
-					return fmt.Errorf("qualitative stop requires --qualitative-stop-prompt or --qualitative-stop-prompt-msg")
-				}
-				resolved, _, err := resolvePromptPath(repoPath, loopUpQualStopPrompt)
-				if err != nil {
-					return err
-				}
-				payload.Prompt = resolved
-				payload.IsPath = true
-			}
-
-			stopCfg.Qual = &models.LoopQualStopConfig{
-				EveryN:    loopUpQualStopEvery,
-				Prompt:    payload,
-				OnInvalid: loopUpQualStopOnInvalid,
-			}
+		chainCfg, err := buildChainConfig(loopUpChain)
+		if err != nil {
+			return err
 		}
 
 		database, err := openDatabase()
@@ -291,25 +230,17 @@ Smart stop (optional):
 				IntervalSeconds:   int(interval.Round(time.Second).Seconds()),
 				MaxIterations:     loopUpMaxIterations,
 				MaxRuntimeSeconds: int(maxRuntime.Round(time.Second).Seconds()),
+				MaxTokens:         loopUpMaxTokens,
+				MaxCostUSD:        loopUpMaxCost,
 				PoolID:            poolID,
 				ProfileID:         profileID,
 				Tags:              tags,
 				State:             models.LoopStateStopped,
+				RestartPolicy:     restartPolicy,
+				Schedule:          schedule,
+				Metadata:          loopConfigMetadata(stopCfg, chainCfg),
 			}
-			if stopCfg.Quant != nil || stopCfg.Qual != nil {
-				loopEntry.Metadata = map[string]any{"stop_config": stopCfg}
-			}
-			if err := loopRepo.Create(context.Background(), loopEntry); err != nil {
-				return err
-			}
-
-			loopEntry.LogPath = loop.LogPath(cfg.Global.DataDir, loopEntry.Name, loopEntry.ID)
-			loopEntry.LedgerPath = loop.LedgerPath(repoPath, loopEntry.Name, loopEntry.ID)
-			if err := loopRepo.Update(context.Background(), loopEntry); err != nil {
-				return err
-			}
-
-			if err := startLoopProcessFunc(loopEntry.ID); err != nil {
+			if err := createLoop(context.Background(), loopRepo, loopEntry, isolation); err != nil {
 				return err
 			}
 
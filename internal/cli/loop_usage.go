@@ -0,0 +1,123 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/tOgg1/forge/internal/db"
+	"github.com/tOgg1/forge/internal/models"
+)
+
+var (
+	usageBy    string
+	usageLoop  string
+	usageSince string
+)
+
+func init() {
+	rootCmd.AddCommand(usageCmd)
+
+	usageCmd.Flags().StringVar(&usageBy, "by", "loop", "group usage by loop, profile, pool or day")
+	usageCmd.Flags().StringVar(&usageLoop, "loop", "", "only count runs of this loop")
+	usageCmd.Flags().StringVar(&usageSince, "since", "", "only count runs started since a duration (e.g. 24h, 7d), date or timestamp")
+}
+
+var usageCmd = &cobra.Command{
+	Use:   "usage",
+	Short: "Report token, cost and time usage of loop runs",
+	Long: `Report the tokens, cost and run time used by loop runs, grouped by loop,
+profile, pool or day (UTC).
+
+Usage is parsed from each run's harness output: Claude, Codex, OpenCode and Pi
+JSON output modes, Codex's "tokens used" trailer, and FORGE_USAGE trailer lines.
+Runs whose harness reported nothing count towards RUNS and DURATION only.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		group, err := parseUsageGroup(usageBy)
+		if err != nil {
+			return err
+		}
+		since, err := ParseSince(usageSince)
+		if err != nil {
+			return fmt.Errorf("invalid --since: %w", err)
+		}
+
+		ctx := context.Background()
+		database, err := openDatabase()
+		if err != nil {
+			return err
+		}
+		defer database.Close()
+
+		query := models.LoopUsageQuery{GroupBy: group, Since: since}
+		if usageLoop != "" {
+			loopEntry, err := resolveLoopByRef(ctx, db.NewLoopRepository(database), usageLoop)
+			if err != nil {
+				return err
+			}
+			query.LoopID = loopEntry.ID
+		}
+
+		summaries, err := db.NewLoopRunRepository(database).SummarizeUsage(ctx, query)
+		if err != nil {
+			return err
+		}
+
+		if IsJSONOutput() || IsJSONLOutput() {
+			return WriteOutput(os.Stdout, summaries)
+		}
+		if len(summaries) == 0 {
+			fmt.Fprintln(os.Stdout, "No runs found")
+			return nil
+		}
+
+		total := &models.LoopUsageSummary{Key: "TOTAL"}
+		rows := make([][]string, 0, len(summaries)+1)
+		for _, summary := range summaries {
+			rows = append(rows, usageRow(summary))
+			total.Runs += summary.Runs
+			total.DurationSeconds += summary.DurationSeconds
+			total.Usage.Add(summary.Usage)
+		}
+		if len(summaries) > 1 {
+			rows = append(rows, usageRow(total))
+		}
+
+		headers := []string{strings.ToUpper(string(group)), "RUNS", "DURATION", "INPUT", "OUTPUT", "CACHE_READ", "CACHE_WRITE", "TOKENS", "COST"}
+		return writeTable(os.Stdout, headers, rows)
+	},
+}
+
+func parseUsageGroup(value string) (models.LoopUsageGroup, error) {
+	switch group := models.LoopUsageGroup(strings.ToLower(strings.TrimSpace(value))); group {
+	case models.LoopUsageByLoop, models.LoopUsageByProfile, models.LoopUsageByPool, models.LoopUsageByDay:
+		return group, nil
+	case "":
+		return models.LoopUsageByLoop, nil
+	default:
+		return "", fmt.Errorf("invalid --by %q (use loop, profile, pool, or day)", value)
+	}
+}
+
+func usageRow(summary *models.LoopUsageSummary) []string {
+	return []string{
+		firstNonEmpty(summary.Key, "-"),
+		strconv.FormatInt(summary.Runs, 10),
+		(time.Duration(summary.DurationSeconds) * time.Second).String(),
+		strconv.FormatInt(summary.Usage.InputTokens, 10),
+		strconv.FormatInt(summary.Usage.OutputTokens, 10),
+		strconv.FormatInt(summary.Usage.CacheReadTokens, 10),
+		strconv.FormatInt(summary.Usage.CacheWriteTokens, 10),
+		strconv.FormatInt(summary.Usage.TotalTokens, 10),
+		formatCostUSD(summary.Usage.CostUSD),
+	}
+}
+
+func formatCostUSD(cost float64) string {
+	return fmt.Sprintf("$%.2f", cost)
+}
@@ -6,14 +6,17 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
 	"strings"
 	"text/tabwriter"
 	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/tOgg1/forge/internal/db"
+	"github.com/tOgg1/forge/internal/forged"
 	"github.com/tOgg1/forge/internal/models"
 	"github.com/tOgg1/forge/internal/node"
+	"github.com/tOgg1/forge/internal/vault"
 )
 
 var (
@@ -26,6 +29,8 @@ var (
 	nodeAddLocal   bool
 	nodeAddKeyPath string
 	nodeAddNoTest  bool
+	nodeAddToken   string
+	nodeAddTLSDir  string
 
 	// Node remove flags
 	nodeRemoveForce bool
@@ -53,6 +58,8 @@ func init() {
 	nodeAddCmd.Flags().BoolVar(&nodeAddLocal, "local", false, "mark as local node (no SSH)")
 	nodeAddCmd.Flags().StringVar(&nodeAddKeyPath, "key", "", "path to SSH private key")
 	nodeAddCmd.Flags().BoolVar(&nodeAddNoTest, "no-test", false, "skip connection test")
+	nodeAddCmd.Flags().StringVar(&nodeAddToken, "token", "", "forged API token for this node (from forged token create)")
+	nodeAddCmd.Flags().StringVar(&nodeAddTLSDir, "tls-dir", "", "directory with the node's ca.pem and optional client.pem/client-key.pem")
 	if err := nodeAddCmd.MarkFlagRequired("name"); err != nil {
 		panic(err)
 	}
@@ -134,7 +141,11 @@ For remote nodes, provide the SSH target:
 For local nodes:
   forge node add --name localhost --local
 
-By default, the connection is tested before adding. Use --no-test to skip.`,
+By default, the connection is tested before adding. Use --no-test to skip.
+
+If the node's forged requires auth, pass the token minted on the node with
+"forged token create" via --token. For forged TLS, point --tls-dir at a copy
+of the node's certificates. Both are stored in the vault.`,
 	Example: `  # Add a remote node
   forge node add --name prod-server --ssh ubuntu@192.168.1.100
 
@@ -142,7 +153,10 @@ By default, the connection is tested before adding. Use --no-test to skip.`,
   forge node add --name staging --ssh deploy@staging.example.com:2222 --key ~/.ssh/staging_key
 
   # Add the local machine
-  forge node add --name localhost --local`,
+  forge node add --name localhost --local
+
+  # Add a node whose forged requires a token and TLS
+  forge node add --name gpu-1 --ssh ops@gpu-1 --token fgd_... --tls-dir ./gpu-1-certs`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		ctx := context.Background()
 
@@ -183,11 +197,18 @@ By default, the connection is tested before adding. Use --no-test to skip.`,
 			return fmt.Errorf("failed to add node: %w", err)
 		}
 
+		if err := storeNodeCredentials(n.Name, nodeAddToken, nodeAddTLSDir); err != nil {
+			return fmt.Errorf("node added but storing credentials failed: %w", err)
+		}
+
 		if IsJSONOutput() || IsJSONLOutput() {
 			return WriteOutput(os.Stdout, n)
 		}
 
 		fmt.Printf("Node '%s' added successfully (ID: %s)\n", n.Name, n.ID)
+		if nodeAddToken != "" || nodeAddTLSDir != "" {
+			fmt.Printf("Credentials stored in %s\n", vault.NodePath(vault.DefaultVaultPath(), n.Name))
+		}
 		if testConnection {
 			fmt.Println("Connection test: PASSED")
 			if n.Metadata.TmuxVersion != "" {
@@ -246,6 +267,9 @@ This does not stop agents running on the node.`,
 		if err := service.RemoveNode(ctx, n.ID); err != nil {
 			return fmt.Errorf("failed to remove node: %w", err)
 		}
+		if err := vault.DeleteNodeCredentials(vault.DefaultVaultPath(), n.Name); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+		}
 
 		if IsJSONOutput() || IsJSONLOutput() {
 			return WriteOutput(os.Stdout, map[string]any{
@@ -632,3 +656,35 @@ The command must be specified after the -- separator.`,
 		return nil
 	},
 }
+
+// storeNodeCredentials saves the forged token and TLS certificates used to
+// reach a node, where the node client looks for them.
+func storeNodeCredentials(nodeName, token, tlsDir string) error {
+	vaultPath := vault.DefaultVaultPath()
+	if strings.TrimSpace(token) != "" {
+		if err := vault.StoreNodeToken(vaultPath, nodeName, token); err != nil {
+			return err
+		}
+	}
+	if tlsDir == "" {
+		return nil
+	}
+
+	dest := vault.NodePath(vaultPath, nodeName)
+	if err := os.MkdirAll(dest, 0o700); err != nil {
+		return err
+	}
+	for _, name := range []string{forged.CACertFile, forged.ClientCertFile, forged.ClientKeyFile} {
+		data, err := os.ReadFile(filepath.Join(tlsDir, name))
+		if err != nil {
+			if os.IsNotExist(err) && name != forged.CACertFile {
+				continue
+			}
+			return fmt.Errorf("failed to read %s: %w", name, err)
+		}
+		if err := os.WriteFile(filepath.Join(dest, name), data, 0o600); err != nil {
+			return fmt.Errorf("failed to store %s: %w", name, err)
+		}
+	}
+	return nil
+}
@@ -6,14 +6,17 @@ import (
 	"fmt"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/tOgg1/forge/internal/db"
+	"github.com/tOgg1/forge/internal/loop"
 	"github.com/tOgg1/forge/internal/models"
 )
 
 var (
 	poolCreateStrategy string
+	poolAddWeight      int
 )
 
 func init() {
@@ -25,7 +28,8 @@ func init() {
 	poolCmd.AddCommand(poolShowCmd)
 	poolCmd.AddCommand(poolSetDefaultCmd)
 
-	poolCreateCmd.Flags().StringVar(&poolCreateStrategy, "strategy", string(models.PoolStrategyRoundRobin), "selection strategy (round_robin)")
+	poolCreateCmd.Flags().StringVar(&poolCreateStrategy, "strategy", string(models.PoolStrategyRoundRobin), "selection strategy (round_robin, lru, least_loaded)")
+	poolAddCmd.Flags().IntVar(&poolAddWeight, "weight", 1, "round-robin weight of the added profiles")
 }
 
 var poolCmd = &cobra.Command{
@@ -124,6 +128,9 @@ var poolAddCmd = &cobra.Command{
 	RunE: func(cmd *cobra.Command, args []string) error {
 		poolRef := args[0]
 		profileRefs := args[1:]
+		if poolAddWeight < 1 {
+			return fmt.Errorf("--weight must be at least 1")
+		}
 
 		database, err := openDatabase()
 		if err != nil {
@@ -157,6 +164,7 @@ var poolAddCmd = &cobra.Command{
 			member := &models.PoolMember{
 				PoolID:    pool.ID,
 				ProfileID: profile.ID,
+				Weight:    poolAddWeight,
 				Position:  position,
 			}
 			if err := poolRepo.AddMember(context.Background(), member); err != nil {
@@ -204,7 +212,14 @@ var poolShowCmd = &cobra.Command{
 			if err != nil {
 				continue
 			}
-			view.Members = append(view.Members, poolMemberView{ProfileID: profile.ID, ProfileName: profile.Name, Harness: string(profile.Harness), AuthKind: profile.AuthKind})
+			view.Members = append(view.Members, poolMemberView{ProfileID: profile.ID, ProfileName: profile.Name, Harness: string(profile.Harness), AuthKind: profile.AuthKind, Weight: member.Weight})
+		}
+		if len(members) > 0 {
+			next, err := loop.NextPoolMember(context.Background(), database, pool)
+			if err != nil {
+				return err
+			}
+			view.Next = next
 		}
 
 		if IsJSONOutput() || IsJSONLOutput() {
@@ -213,7 +228,11 @@ var poolShowCmd = &cobra.Command{
 
 		fmt.Fprintf(os.Stdout, "Pool %s\n", pool.Name)
 		fmt.Fprintf(os.Stdout, "Strategy: %s\n", pool.Strategy)
-		fmt.Fprintf(os.Stdout, "Default: %s\n\n", formatYesNo(pool.IsDefault))
+		fmt.Fprintf(os.Stdout, "Default: %s\n", formatYesNo(pool.IsDefault))
+		if view.Next != nil {
+			fmt.Fprintf(os.Stdout, "Next: %s\n", formatPoolSelection(view.Next))
+		}
+		fmt.Fprintln(os.Stdout)
 
 		if len(view.Members) == 0 {
 			fmt.Fprintln(os.Stdout, "No members")
@@ -222,9 +241,9 @@ var poolShowCmd = &cobra.Command{
 
 		rows := make([][]string, 0, len(view.Members))
 		for _, member := range view.Members {
-			rows = append(rows, []string{member.ProfileName, member.Harness, member.AuthKind})
+			rows = append(rows, []string{member.ProfileName, member.Harness, member.AuthKind, fmt.Sprintf("%d", member.Weight)})
 		}
-		return writeTable(os.Stdout, []string{"PROFILE", "HARNESS", "AUTH_KIND"}, rows)
+		return writeTable(os.Stdout, []string{"PROFILE", "HARNESS", "AUTH_KIND", "WEIGHT"}, rows)
 	},
 }
 
@@ -263,11 +282,24 @@ type poolMemberView struct {
 	ProfileName string `json:"profile_name"`
 	Harness     string `json:"harness"`
 	AuthKind    string `json:"auth_kind"`
+	Weight      int    `json:"weight"`
 }
 
 type poolView struct {
-	Pool    *models.Pool     `json:"pool"`
-	Members []poolMemberView `json:"members"`
+	Pool    *models.Pool        `json:"pool"`
+	Members []poolMemberView    `json:"members"`
+	Next    *loop.PoolSelection `json:"next,omitempty"`
+}
+
+// formatPoolSelection renders the next pool member and the reason it was chosen.
+func formatPoolSelection(selection *loop.PoolSelection) string {
+	if selection.Profile == nil {
+		if selection.WaitUntil != nil {
+			return fmt.Sprintf("none until %s (%s)", selection.WaitUntil.UTC().Format(time.RFC3339), selection.Reason)
+		}
+		return fmt.Sprintf("none (%s)", selection.Reason)
+	}
+	return fmt.Sprintf("%s (%s)", selection.Profile.Name, selection.Reason)
 }
 
 func resolvePoolByRef(ctx context.Context, repo *db.PoolRepository, ref string) (*models.Pool, error) {
@@ -290,6 +322,10 @@ func parsePoolStrategy(value string) (models.PoolStrategy, error) {
 	switch strings.ToLower(value) {
 	case "round_robin", "round-robin", "rr":
 		return models.PoolStrategyRoundRobin, nil
+	case "lru", "least_recently_used", "least-recently-used":
+		return models.PoolStrategyLRU, nil
+	case "least_loaded", "least-loaded":
+		return models.PoolStrategyLeastLoaded, nil
 	default:
 		return "", fmt.Errorf("unknown pool strategy %q", value)
 	}
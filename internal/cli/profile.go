@@ -19,6 +19,7 @@ var (
 	profileAddAuthKind       string
 	profileAddAuthHome       string
 	profileAddPromptMode     string
+	profileAddOutputFormat   string
 	profileAddCommand        string
 	profileAddModel          string
 	profileAddExtraArgs      []string
@@ -29,6 +30,7 @@ var (
 	profileEditAuthKind       string
 	profileEditAuthHome       string
 	profileEditPromptMode     string
+	profileEditOutputFormat   string
 	profileEditCommand        string
 	profileEditModel          string
 	profileEditExtraArgs      []string
@@ -56,6 +58,7 @@ func init() {
 	profileAddCmd.Flags().StringVar(&profileAddAuthKind, "auth-kind", "", "auth kind (claude, codex, etc)")
 	profileAddCmd.Flags().StringVar(&profileAddAuthHome, "home", "", "auth home directory")
 	profileAddCmd.Flags().StringVar(&profileAddPromptMode, "prompt-mode", "", "prompt mode (env, stdin, path)")
+	profileAddCmd.Flags().StringVar(&profileAddOutputFormat, "output-format", "", "harness output format (text, stream-json)")
 	profileAddCmd.Flags().StringVar(&profileAddCommand, "command", "", "command template override")
 	profileAddCmd.Flags().StringVar(&profileAddModel, "model", "", "model name")
 	profileAddCmd.Flags().StringSliceVar(&profileAddExtraArgs, "extra-arg", nil, "extra argument (repeatable)")
@@ -66,6 +69,7 @@ func init() {
 	profileEditCmd.Flags().StringVar(&profileEditAuthKind, "auth-kind", "", "auth kind (claude, codex, etc)")
 	profileEditCmd.Flags().StringVar(&profileEditAuthHome, "home", "", "auth home directory")
 	profileEditCmd.Flags().StringVar(&profileEditPromptMode, "prompt-mode", "", "prompt mode (env, stdin, path)")
+	profileEditCmd.Flags().StringVar(&profileEditOutputFormat, "output-format", "", "harness output format (text, stream-json)")
 	profileEditCmd.Flags().StringVar(&profileEditCommand, "command", "", "command template override")
 	profileEditCmd.Flags().StringVar(&profileEditModel, "model", "", "model name")
 	profileEditCmd.Flags().StringSliceVar(&profileEditExtraArgs, "extra-arg", nil, "extra argument (repeatable)")
@@ -149,7 +153,15 @@ var profileAddCmd = &cobra.Command{
 			return fmt.Errorf("invalid prompt mode %q", profileAddPromptMode)
 		}
 
+		outputFormat, err := parseOutputFormat(profileAddOutputFormat)
+		if err != nil {
+			return err
+		}
+
 		commandTemplate := strings.TrimSpace(profileAddCommand)
+		if commandTemplate == "" && outputFormat == models.OutputFormatStreamJSON {
+			commandTemplate = harness.DefaultStreamCommandTemplate(harnessValue, profileAddModel)
+		}
 		if commandTemplate == "" {
 			commandTemplate = harness.DefaultCommandTemplate(harnessValue, profileAddModel)
 		}
@@ -165,6 +177,7 @@ var profileAddCmd = &cobra.Command{
 			AuthKind:        profileAddAuthKind,
 			AuthHome:        profileAddAuthHome,
 			PromptMode:      promptMode,
+			OutputFormat:    outputFormat,
 			CommandTemplate: commandTemplate,
 			Model:           profileAddModel,
 			ExtraArgs:       profileAddExtraArgs,
@@ -226,6 +239,13 @@ var profileEditCmd = &cobra.Command{
 			}
 			profile.PromptMode = promptMode
 		}
+		if cmd.Flags().Changed("output-format") {
+			outputFormat, err := parseOutputFormat(profileEditOutputFormat)
+			if err != nil {
+				return err
+			}
+			profile.OutputFormat = outputFormat
+		}
 		if cmd.Flags().Changed("command") {
 			profile.CommandTemplate = profileEditCommand
 		}
@@ -461,6 +481,17 @@ func isValidPromptMode(mode models.PromptMode) bool {
 	}
 }
 
+func parseOutputFormat(value string) (models.OutputFormat, error) {
+	switch format := models.OutputFormat(strings.ToLower(strings.TrimSpace(value))); format {
+	case "":
+		return models.OutputFormatText, nil
+	case models.OutputFormatText, models.OutputFormatStreamJSON:
+		return format, nil
+	default:
+		return "", fmt.Errorf("invalid output format %q (use text or stream-json)", value)
+	}
+}
+
 func parseEnvPairs(pairs []string) map[string]string {
 	if len(pairs) == 0 {
 		return nil
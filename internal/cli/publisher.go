@@ -5,7 +5,6 @@ import (
 
 	"github.com/tOgg1/forge/internal/db"
 	"github.com/tOgg1/forge/internal/events"
-	"github.com/tOgg1/forge/internal/hooks"
 )
 
 func newEventPublisher(database *db.DB) events.Publisher {
@@ -16,10 +15,9 @@ func newEventPublisher(database *db.DB) events.Publisher {
 	repo := db.NewEventRepository(database)
 	publisher := events.NewInMemoryPublisher(events.WithRepository(repo))
 
-	store := hooks.NewStore(hookStorePath())
-	manager := hooks.NewManager(store, nil)
+	manager := newHookManager(database)
 	if err := manager.Attach(publisher); err != nil {
-		logger.Warn().Err(err).Str("store", strings.TrimSpace(store.Path())).Msg("failed to load hooks")
+		logger.Warn().Err(err).Str("store", strings.TrimSpace(hookStorePath())).Msg("failed to load hooks")
 	}
 
 	return publisher
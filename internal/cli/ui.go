@@ -49,6 +49,7 @@ func runTUI() error {
 		loopConfig.DefaultInterval = cfg.LoopDefaults.Interval
 		loopConfig.DefaultPrompt = cfg.LoopDefaults.Prompt
 		loopConfig.DefaultPromptMsg = cfg.LoopDefaults.PromptMsg
+		loopConfig.HeartbeatTimeout = cfg.LoopDefaults.HeartbeatTimeout
 	}
 	loopConfig.ConfigFile = cfgFile
 
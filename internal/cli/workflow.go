@@ -27,7 +27,7 @@ var workflowCmd = &cobra.Command{
 	Use:     "workflow",
 	Aliases: []string{"wf"},
 	Short:   "Manage workflows",
-	Long:    "List, inspect, and validate workflow definitions.",
+	Long:    "List, inspect, validate, and run workflow definitions.",
 }
 
 var workflowListCmd = &cobra.Command{
@@ -0,0 +1,113 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/tOgg1/forge/internal/db"
+	"github.com/tOgg1/forge/internal/models"
+	"github.com/tOgg1/forge/internal/workflows"
+)
+
+var (
+	workflowApproveNote string
+	workflowRejectNote  string
+)
+
+func init() {
+	workflowCmd.AddCommand(workflowApproveCmd)
+	workflowCmd.AddCommand(workflowRejectCmd)
+
+	workflowApproveCmd.Flags().StringVar(&workflowApproveNote, "note", "", "note passed to downstream steps")
+	workflowRejectCmd.Flags().StringVar(&workflowRejectNote, "note", "", "reason for rejecting")
+}
+
+var workflowApproveCmd = &cobra.Command{
+	Use:   "approve <run> <step>",
+	Short: "Approve a waiting human step",
+	Long: `Approve the pending approval of a human step so the workflow run continues.
+
+The note is available to downstream steps as ${steps.<step>.outputs.note}.`,
+	Args: cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return resolveWorkflowApproval(args[0], args[1], models.ApprovalStatusApproved, workflowApproveNote)
+	},
+}
+
+var workflowRejectCmd = &cobra.Command{
+	Use:   "reject <run> <step>",
+	Short: "Reject a waiting human step",
+	Long:  "Reject the pending approval of a human step. The step fails and its downstream steps are skipped.",
+	Args:  cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return resolveWorkflowApproval(args[0], args[1], models.ApprovalStatusDenied, workflowRejectNote)
+	},
+}
+
+func resolveWorkflowApproval(ref, stepID string, status models.ApprovalStatus, note string) error {
+	ctx := context.Background()
+
+	database, err := openDatabase()
+	if err != nil {
+		return err
+	}
+	defer database.Close()
+
+	run, err := resolveWorkflowRun(ctx, db.NewWorkflowRunRepository(database), ref)
+	if err != nil {
+		return err
+	}
+
+	runner := workflows.NewRunner(database, GetConfig())
+	approval, orphaned, err := runner.ResolveApproval(ctx, run.ID, stepID, status, approverName(), strings.TrimSpace(note))
+	if err != nil {
+		return err
+	}
+
+	// The process that was waiting on this step is gone; continue the run in the background.
+	if orphaned {
+		if err := startWorkflowProcessFunc(run.ID); err != nil {
+			return err
+		}
+	}
+
+	if IsJSONOutput() || IsJSONLOutput() {
+		return WriteOutput(os.Stdout, approval)
+	}
+	if IsQuiet() {
+		return nil
+	}
+
+	verb := "approved"
+	if status == models.ApprovalStatusDenied {
+		verb = "rejected"
+	}
+	fmt.Printf("Step %s of workflow run %s %s\n", stepID, shortID(run.ID), verb)
+	if orphaned {
+		fmt.Printf("Workflow run %s restarted (%s)\n", shortID(run.ID), run.WorkflowName)
+	}
+	return nil
+}
+
+// approverName identifies who resolved an approval from the CLI.
+func approverName() string {
+	if name := strings.TrimSpace(os.Getenv("USER")); name != "" {
+		return name
+	}
+	return "user"
+}
+
+// workflowApprovalPrompt extracts the prompt text from a human step approval request.
+func workflowApprovalPrompt(approval *models.Approval) string {
+	var details struct {
+		Prompt string `json:"prompt"`
+	}
+	if err := json.Unmarshal(approval.RequestDetails, &details); err != nil {
+		return ""
+	}
+	return details.Prompt
+}
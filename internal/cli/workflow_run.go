@@ -0,0 +1,626 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/tOgg1/forge/internal/db"
+	"github.com/tOgg1/forge/internal/models"
+	"github.com/tOgg1/forge/internal/workflows"
+)
+
+var (
+	workflowRunInputs []string
+	workflowRunDetach bool
+
+	workflowStatusLimit int
+
+	workflowResumeDetach bool
+	workflowRetryStep    string
+	workflowRetryDetach  bool
+
+	workflowLogsFollow bool
+	workflowLogsLines  int
+)
+
+var startWorkflowProcessFunc = startWorkflowProcess
+
+func init() {
+	workflowCmd.AddCommand(workflowRunCmd)
+	workflowCmd.AddCommand(workflowStatusCmd)
+	workflowCmd.AddCommand(workflowLogsCmd)
+	workflowCmd.AddCommand(workflowResumeCmd)
+	workflowCmd.AddCommand(workflowRetryCmd)
+	workflowCmd.AddCommand(workflowExecCmd)
+
+	workflowRunCmd.Flags().StringArrayVar(&workflowRunInputs, "input", nil, "workflow input (key=value, repeatable)")
+	workflowRunCmd.Flags().BoolVarP(&workflowRunDetach, "detach", "d", false, "run in the background")
+
+	workflowStatusCmd.Flags().IntVar(&workflowStatusLimit, "limit", 20, "max runs to list")
+
+	workflowResumeCmd.Flags().BoolVarP(&workflowResumeDetach, "detach", "d", false, "resume in the background")
+
+	workflowRetryCmd.Flags().StringVar(&workflowRetryStep, "step", "", "step to re-run (downstream steps are re-run too)")
+	workflowRetryCmd.Flags().BoolVarP(&workflowRetryDetach, "detach", "d", false, "retry in the background")
+	_ = workflowRetryCmd.MarkFlagRequired("step")
+
+	workflowLogsCmd.Flags().BoolVarP(&workflowLogsFollow, "follow", "f", false, "follow log output")
+	workflowLogsCmd.Flags().IntVarP(&workflowLogsLines, "lines", "n", 50, "number of lines to show")
+}
+
+var workflowRunCmd = &cobra.Command{
+	Use:   "run <name>",
+	Short: "Run a workflow",
+	Long:  "Execute a workflow's steps in dependency order, recording per-step status and outputs.",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := context.Background()
+
+		database, err := openDatabase()
+		if err != nil {
+			return err
+		}
+		defer database.Close()
+
+		wsRepo := db.NewWorkspaceRepository(database)
+		projectDir := resolveTemplateProjectDir(ctx, wsRepo)
+		wf, err := loadWorkflowByName(projectDir, args[0])
+		if err != nil {
+			return err
+		}
+
+		inputs, err := parseWorkflowInputs(workflowRunInputs)
+		if err != nil {
+			return err
+		}
+
+		runner := workflows.NewRunner(database, GetConfig())
+		runner.Publisher = newEventPublisher(database)
+		run, err := runner.CreateRun(ctx, wf, inputs)
+		if err != nil {
+			return err
+		}
+
+		if workflowRunDetach {
+			if err := startWorkflowProcessFunc(run.ID); err != nil {
+				return err
+			}
+			if IsJSONOutput() || IsJSONLOutput() {
+				return WriteOutput(os.Stdout, run)
+			}
+			if IsQuiet() {
+				return nil
+			}
+			fmt.Printf("Workflow run %s started (%s)\n", shortID(run.ID), run.WorkflowName)
+			return nil
+		}
+
+		if !IsJSONOutput() && !IsJSONLOutput() && !IsQuiet() {
+			fmt.Printf("Workflow run %s started (%s)\n", shortID(run.ID), run.WorkflowName)
+		}
+
+		sigCtx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
+		run, err = runner.Execute(sigCtx, run, wf)
+		if err != nil {
+			return err
+		}
+
+		return outputWorkflowRunResult(ctx, database, run)
+	},
+}
+
+var workflowStatusCmd = &cobra.Command{
+	Use:   "status [run]",
+	Short: "Show workflow run status",
+	Long:  "List recent workflow runs, or show per-step status for a single run.",
+	Args:  cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := context.Background()
+
+		database, err := openDatabase()
+		if err != nil {
+			return err
+		}
+		defer database.Close()
+
+		repo := db.NewWorkflowRunRepository(database)
+		if len(args) == 0 {
+			runs, err := repo.List(ctx, workflowStatusLimit)
+			if err != nil {
+				return err
+			}
+			if IsJSONOutput() || IsJSONLOutput() {
+				return WriteOutput(os.Stdout, runs)
+			}
+			if len(runs) == 0 {
+				fmt.Println("No workflow runs found")
+				return nil
+			}
+
+			rows := make([][]string, 0, len(runs))
+			for _, run := range runs {
+				rows = append(rows, []string{
+					shortID(run.ID),
+					run.WorkflowName,
+					string(run.Status),
+					formatRelativeTime(run.CreatedAt),
+					workflowElapsed(run.StartedAt, run.FinishedAt),
+				})
+			}
+			return writeTable(os.Stdout, []string{"RUN", "WORKFLOW", "STATUS", "CREATED", "DURATION"}, rows)
+		}
+
+		run, err := resolveWorkflowRun(ctx, repo, args[0])
+		if err != nil {
+			return err
+		}
+		steps, err := repo.ListSteps(ctx, run.ID)
+		if err != nil {
+			return err
+		}
+
+		approvals, err := db.NewApprovalRepository(database).ListByWorkflowRun(ctx, run.ID)
+		if err != nil {
+			return err
+		}
+
+		if IsJSONOutput() || IsJSONLOutput() {
+			return WriteOutput(os.Stdout, workflowRunStatus{Run: run, Steps: steps, Approvals: approvals})
+		}
+
+		return printWorkflowRun(run, steps, approvals)
+	},
+}
+
+var workflowLogsCmd = &cobra.Command{
+	Use:   "logs <run> [step]",
+	Short: "Show workflow run logs",
+	Long:  "Show the scheduler log for a run, or the log for a single step. Agent and loop steps also include the underlying loop log.",
+	Args:  cobra.RangeArgs(1, 2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := context.Background()
+
+		database, err := openDatabase()
+		if err != nil {
+			return err
+		}
+		defer database.Close()
+
+		repo := db.NewWorkflowRunRepository(database)
+		run, err := resolveWorkflowRun(ctx, repo, args[0])
+		if err != nil {
+			return err
+		}
+
+		dataDir := GetConfig().Global.DataDir
+		if len(args) == 1 {
+			path := workflows.RunLogPath(dataDir, run.ID)
+			if workflowLogsFollow {
+				return followFile(path, workflowLogsLines)
+			}
+			return printLogFile(path, workflowLogsLines)
+		}
+
+		step, err := repo.GetStep(ctx, run.ID, args[1])
+		if err != nil {
+			if errors.Is(err, db.ErrWorkflowStepRunNotFound) {
+				return fmt.Errorf("step %q not found in run %s", args[1], shortID(run.ID))
+			}
+			return err
+		}
+
+		path := step.LogPath
+		if path == "" {
+			path = workflows.StepLogPath(dataDir, run.ID, step.StepID)
+		}
+		if workflowLogsFollow && step.LoopID == "" {
+			return followFile(path, workflowLogsLines)
+		}
+		if err := printLogFile(path, workflowLogsLines); err != nil {
+			return err
+		}
+
+		if step.LoopID == "" {
+			return nil
+		}
+		// Step loops are removed once the step is done; their log path is
+		// kept in the step outputs.
+		loopName, _ := step.Outputs["loop_name"].(string)
+		loopLogPath, _ := step.Outputs["log_path"].(string)
+		if loopEntry, err := db.NewLoopRepository(database).Get(ctx, step.LoopID); err == nil {
+			loopName, loopLogPath = loopEntry.Name, loopEntry.LogPath
+		}
+		if loopLogPath == "" {
+			return nil
+		}
+		if !IsQuiet() {
+			fmt.Fprintf(os.Stdout, "\n==> %s <==\n", loopName)
+		}
+		if workflowLogsFollow {
+			return followFile(loopLogPath, workflowLogsLines)
+		}
+		return printLogFile(loopLogPath, workflowLogsLines)
+	},
+}
+
+var workflowResumeCmd = &cobra.Command{
+	Use:   "resume <run>",
+	Short: "Resume an interrupted or failed workflow run",
+	Long:  "Re-enter the workflow DAG for a run. Steps that already succeeded keep their outputs; everything else runs again.",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return restartWorkflowRun(args[0], workflowResumeDetach, func(ctx context.Context, runner *workflows.Runner, runID string) (*models.WorkflowRun, error) {
+			return runner.PrepareResume(ctx, runID)
+		})
+	},
+}
+
+var workflowRetryCmd = &cobra.Command{
+	Use:   "retry <run> --step <id>",
+	Short: "Re-run a workflow step and its downstream steps",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return restartWorkflowRun(args[0], workflowRetryDetach, func(ctx context.Context, runner *workflows.Runner, runID string) (*models.WorkflowRun, error) {
+			return runner.PrepareRetry(ctx, runID, workflowRetryStep)
+		})
+	},
+}
+
+var workflowExecCmd = &cobra.Command{
+	Use:    "exec <run-id>",
+	Hidden: true,
+	Args:   cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		database, err := openDatabase()
+		if err != nil {
+			return err
+		}
+		defer database.Close()
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
+		runner := workflows.NewRunner(database, GetConfig())
+		runner.Publisher = newEventPublisher(database)
+		if _, err := runner.ExecuteRun(ctx, args[0]); err != nil {
+			return fmt.Errorf("workflow run failed: %w", err)
+		}
+		return nil
+	},
+}
+
+func restartWorkflowRun(ref string, detach bool, prepare func(context.Context, *workflows.Runner, string) (*models.WorkflowRun, error)) error {
+	ctx := context.Background()
+
+	database, err := openDatabase()
+	if err != nil {
+		return err
+	}
+	defer database.Close()
+
+	run, err := resolveWorkflowRun(ctx, db.NewWorkflowRunRepository(database), ref)
+	if err != nil {
+		return err
+	}
+
+	runner := workflows.NewRunner(database, GetConfig())
+	runner.Publisher = newEventPublisher(database)
+	run, err = prepare(ctx, runner, run.ID)
+	if err != nil {
+		return err
+	}
+
+	if detach {
+		if err := startWorkflowProcessFunc(run.ID); err != nil {
+			return err
+		}
+		if IsJSONOutput() || IsJSONLOutput() {
+			return WriteOutput(os.Stdout, run)
+		}
+		if !IsQuiet() {
+			fmt.Printf("Workflow run %s restarted (%s)\n", shortID(run.ID), run.WorkflowName)
+		}
+		return nil
+	}
+
+	if !IsJSONOutput() && !IsJSONLOutput() && !IsQuiet() {
+		fmt.Printf("Workflow run %s restarted (%s)\n", shortID(run.ID), run.WorkflowName)
+	}
+
+	sigCtx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	run, err = runner.ExecuteRun(sigCtx, run.ID)
+	if err != nil {
+		return err
+	}
+
+	return outputWorkflowRunResult(ctx, database, run)
+}
+
+type workflowRunStatus struct {
+	Run       *models.WorkflowRun       `json:"run"`
+	Steps     []*models.WorkflowStepRun `json:"steps"`
+	Approvals []*models.Approval        `json:"approvals,omitempty"`
+}
+
+func outputWorkflowRunResult(ctx context.Context, database *db.DB, run *models.WorkflowRun) error {
+	steps, err := db.NewWorkflowRunRepository(database).ListSteps(ctx, run.ID)
+	if err != nil {
+		return err
+	}
+	approvals, err := db.NewApprovalRepository(database).ListByWorkflowRun(ctx, run.ID)
+	if err != nil {
+		return err
+	}
+
+	if IsJSONOutput() || IsJSONLOutput() {
+		if err := WriteOutput(os.Stdout, workflowRunStatus{Run: run, Steps: steps, Approvals: approvals}); err != nil {
+			return err
+		}
+	} else if !IsQuiet() {
+		if err := printWorkflowRun(run, steps, approvals); err != nil {
+			return err
+		}
+	}
+
+	if run.Status != models.WorkflowRunStatusSuccess {
+		return &ExitError{Code: 1, Err: fmt.Errorf("workflow run %s %s", shortID(run.ID), run.Status), Printed: true}
+	}
+	return nil
+}
+
+func printWorkflowRun(run *models.WorkflowRun, steps []*models.WorkflowStepRun, approvals []*models.Approval) error {
+	fmt.Printf("Run: %s\n", run.ID)
+	fmt.Printf("Workflow: %s\n", run.WorkflowName)
+	fmt.Printf("Status: %s\n", run.Status)
+	if run.ParentRunID != "" {
+		fmt.Printf("Parent: %s (step %s)\n", shortID(run.ParentRunID), run.ParentStepID)
+	}
+	if run.RepoPath != "" {
+		fmt.Printf("Repo: %s\n", run.RepoPath)
+	}
+	fmt.Printf("Duration: %s\n", workflowElapsed(run.StartedAt, run.FinishedAt))
+	if len(run.Inputs) > 0 {
+		fmt.Printf("Inputs: %s\n", formatWorkflowMap(run.Inputs))
+	}
+	if len(run.Outputs) > 0 {
+		fmt.Printf("Outputs: %s\n", formatWorkflowMap(run.Outputs))
+	}
+	if run.Error != "" {
+		fmt.Printf("Error: %s\n", run.Error)
+	}
+	fmt.Println()
+
+	hosts := workflowAliveWith(run)
+	byID := make(map[string]*models.WorkflowStepRun, len(steps))
+	for _, step := range steps {
+		byID[step.StepID] = step
+	}
+
+	rows := make([][]string, 0, len(steps))
+	for _, step := range steps {
+		detail := workflowStepDetail(step)
+		if lifetime := workflowParasiteLifetime(step, hosts[step.StepID], byID); lifetime != "" {
+			detail = strings.TrimSpace(lifetime + " " + detail)
+		}
+		rows = append(rows, []string{
+			step.StepID,
+			step.StepType,
+			string(step.Status),
+			fmt.Sprintf("%d", step.Attempt),
+			workflowElapsed(step.StartedAt, step.FinishedAt),
+			detail,
+		})
+	}
+	if err := writeTable(os.Stdout, []string{"STEP", "TYPE", "STATUS", "ATTEMPT", "DURATION", "DETAIL"}, rows); err != nil {
+		return err
+	}
+
+	pending := make([]*models.Approval, 0, len(approvals))
+	for _, approval := range approvals {
+		if approval.Status == models.ApprovalStatusPending {
+			pending = append(pending, approval)
+		}
+	}
+	if len(pending) == 0 {
+		return nil
+	}
+	fmt.Println()
+	fmt.Println("Pending approvals:")
+	for _, approval := range pending {
+		fmt.Printf("  %s: %s\n", approval.WorkflowStepID, workflowApprovalPrompt(approval))
+		fmt.Printf("    forge workflow approve %s %s --note \"...\"\n", shortID(run.ID), approval.WorkflowStepID)
+		fmt.Printf("    forge workflow reject %s %s --note \"...\"\n", shortID(run.ID), approval.WorkflowStepID)
+	}
+	return nil
+}
+
+func workflowStepDetail(step *models.WorkflowStepRun) string {
+	parts := make([]string, 0, 3)
+	if step.LoopID != "" {
+		parts = append(parts, "loop="+shortID(step.LoopID))
+	}
+	if step.ChildRunID != "" {
+		parts = append(parts, "run="+shortID(step.ChildRunID))
+	}
+	if step.ExitCode != nil {
+		parts = append(parts, fmt.Sprintf("exit=%d", *step.ExitCode))
+	}
+	if step.Status == models.WorkflowStepStatusWaiting {
+		parts = append(parts, "awaiting approval")
+	} else if by, ok := step.Outputs["resolved_by"].(string); ok && step.Status == models.WorkflowStepStatusSuccess {
+		parts = append(parts, "approved by "+by)
+	}
+	if step.Error != "" {
+		parts = append(parts, step.Error)
+	}
+	return strings.Join(parts, " ")
+}
+
+// workflowAliveWith maps parasitic steps to their hosts using the run's workflow source.
+func workflowAliveWith(run *models.WorkflowRun) map[string][]string {
+	if run.WorkflowSource == "" {
+		return nil
+	}
+	wf, err := workflows.LoadWorkflow(run.WorkflowSource)
+	if err != nil {
+		return nil
+	}
+	hosts := make(map[string][]string)
+	for _, step := range wf.Steps {
+		if len(step.AliveWith) > 0 {
+			hosts[step.ID] = step.AliveWith
+		}
+	}
+	return hosts
+}
+
+// workflowParasiteLifetime describes when an alive_with step started and stopped
+// relative to its hosts: start is measured from the last host start, stop from the
+// first host finish.
+func workflowParasiteLifetime(step *models.WorkflowStepRun, hosts []string, byID map[string]*models.WorkflowStepRun) string {
+	if len(hosts) == 0 {
+		return ""
+	}
+	label := "alive_with " + strings.Join(hosts, ",")
+	if step.StartedAt == nil {
+		return label
+	}
+
+	var hostStart, hostEnd *time.Time
+	for _, id := range hosts {
+		host := byID[id]
+		if host == nil {
+			continue
+		}
+		if host.StartedAt != nil && (hostStart == nil || host.StartedAt.After(*hostStart)) {
+			hostStart = host.StartedAt
+		}
+		if host.FinishedAt != nil && (hostEnd == nil || host.FinishedAt.Before(*hostEnd)) {
+			hostEnd = host.FinishedAt
+		}
+	}
+
+	parts := make([]string, 0, 2)
+	if hostStart != nil {
+		parts = append(parts, "start "+signedDuration(step.StartedAt.Sub(*hostStart)))
+	}
+	switch {
+	case step.FinishedAt == nil:
+		parts = append(parts, "running")
+	case hostEnd != nil:
+		parts = append(parts, "stop "+signedDuration(step.FinishedAt.Sub(*hostEnd)))
+	default:
+		parts = append(parts, "stopped before host")
+	}
+	return fmt.Sprintf("%s (%s)", label, strings.Join(parts, ", "))
+}
+
+func signedDuration(d time.Duration) string {
+	d = d.Round(100 * time.Millisecond)
+	if d < 0 {
+		return d.String()
+	}
+	return "+" + d.String()
+}
+
+func workflowElapsed(startedAt, finishedAt *time.Time) string {
+	if startedAt == nil {
+		return "-"
+	}
+	end := time.Now().UTC()
+	if finishedAt != nil {
+		end = *finishedAt
+	}
+	return end.Sub(*startedAt).Round(time.Second).String()
+}
+
+func resolveWorkflowRun(ctx context.Context, repo *db.WorkflowRunRepository, ref string) (*models.WorkflowRun, error) {
+	ref = strings.TrimSpace(ref)
+	if ref == "" {
+		return nil, errors.New("workflow run ID required")
+	}
+
+	run, err := repo.Get(ctx, ref)
+	if err == nil {
+		return run, nil
+	}
+	if !errors.Is(err, db.ErrWorkflowRunNotFound) {
+		return nil, err
+	}
+
+	runs, err := repo.List(ctx, 0)
+	if err != nil {
+		return nil, err
+	}
+	matches := make([]*models.WorkflowRun, 0, 1)
+	for _, candidate := range runs {
+		if strings.HasPrefix(candidate.ID, ref) {
+			matches = append(matches, candidate)
+		}
+	}
+
+	switch len(matches) {
+	case 0:
+		return nil, fmt.Errorf("workflow run %q not found", ref)
+	case 1:
+		return matches[0], nil
+	default:
+		return nil, fmt.Errorf("workflow run %q is ambiguous (%d matches)", ref, len(matches))
+	}
+}
+
+func parseWorkflowInputs(values []string) (map[string]any, error) {
+	inputs := make(map[string]any, len(values))
+	for _, value := range values {
+		key, val, ok := strings.Cut(value, "=")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" {
+			return nil, fmt.Errorf("invalid --input %q (expected key=value)", value)
+		}
+		inputs[key] = val
+	}
+	return inputs, nil
+}
+
+func printLogFile(path string, lines int) error {
+	content, err := readLog(path, lines, "")
+	if err != nil {
+		if os.IsNotExist(err) {
+			return fmt.Errorf("no log found at %s", path)
+		}
+		return err
+	}
+	if content != "" {
+		fmt.Fprintln(os.Stdout, content)
+	}
+	return nil
+}
+
+func startWorkflowProcess(runID string) error {
+	args := []string{"workflow", "exec", runID}
+	if cfgFile != "" {
+		args = append([]string{"--config", cfgFile}, args...)
+	}
+
+	cmd := exec.Command(os.Args[0], args...)
+	cmd.Stdout = nil
+	cmd.Stderr = nil
+
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("failed to start workflow process: %w", err)
+	}
+
+	return nil
+}
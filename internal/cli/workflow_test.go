@@ -2,6 +2,7 @@ package cli
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"io"
@@ -11,8 +12,11 @@ import (
 	"runtime"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/tOgg1/forge/internal/config"
+	"github.com/tOgg1/forge/internal/db"
+	"github.com/tOgg1/forge/internal/models"
 	"github.com/tOgg1/forge/internal/workflows"
 )
 
@@ -198,3 +202,190 @@ func readGolden(t *testing.T, name string) string {
 	}
 	return string(data)
 }
+
+func TestWorkflowCLIRunAndStatus(t *testing.T) {
+	repo := setupWorkflowRepo(t)
+	workflow := "name = \"echo\"\n\n[[steps]]\nid = \"greet\"\ntype = \"bash\"\ncmd = \"echo hi-${inputs.who}\"\n"
+	if err := os.WriteFile(filepath.Join(repo, ".forge", "workflows", "echo.toml"), []byte(workflow), 0o644); err != nil {
+		t.Fatalf("write workflow: %v", err)
+	}
+
+	cleanupConfig := withTempConfig(t, repo)
+	defer cleanupConfig()
+
+	withWorkingDir(t, repo, func() {
+		originalJSON := jsonOutput
+		originalJSONL := jsonlOutput
+		originalInputs := workflowRunInputs
+		jsonOutput = true
+		jsonlOutput = false
+		workflowRunInputs = []string{"who=forge"}
+		defer func() {
+			jsonOutput = originalJSON
+			jsonlOutput = originalJSONL
+			workflowRunInputs = originalInputs
+		}()
+
+		out, err := captureStdout(func() error {
+			return workflowRunCmd.RunE(workflowRunCmd, []string{"echo"})
+		})
+		if err != nil {
+			t.Fatalf("workflow run: %v", err)
+		}
+
+		var result workflowRunStatus
+		if err := json.Unmarshal([]byte(out), &result); err != nil {
+			t.Fatalf("parse workflow run output: %v", err)
+		}
+		if result.Run == nil || result.Run.Status != "success" {
+			t.Fatalf("expected successful run, got %+v", result.Run)
+		}
+		if len(result.Steps) != 1 || result.Steps[0].Outputs["stdout"] != "hi-forge" {
+			t.Fatalf("unexpected step result %+v", result.Steps)
+		}
+
+		out, err = captureStdout(func() error {
+			return workflowStatusCmd.RunE(workflowStatusCmd, []string{result.Run.ID[:8]})
+		})
+		if err != nil {
+			t.Fatalf("workflow status: %v", err)
+		}
+		var status workflowRunStatus
+		if err := json.Unmarshal([]byte(out), &status); err != nil {
+			t.Fatalf("parse workflow status output: %v", err)
+		}
+		if status.Run.ID != result.Run.ID {
+			t.Fatalf("expected run %s, got %s", result.Run.ID, status.Run.ID)
+		}
+
+		jsonOutput = false
+		out, err = captureStdout(func() error {
+			return workflowLogsCmd.RunE(workflowLogsCmd, []string{result.Run.ID, "greet"})
+		})
+		if err != nil {
+			t.Fatalf("workflow logs: %v", err)
+		}
+		if !strings.Contains(out, "hi-forge") {
+			t.Fatalf("expected step output in logs, got %q", out)
+		}
+	})
+}
+
+func TestWorkflowCLIApproveOrphanedHumanStep(t *testing.T) {
+	repo := setupWorkflowRepo(t)
+	workflow := "name = \"gated\"\n\n[[steps]]\nid = \"signoff\"\ntype = \"human\"\nprompt = \"Ship it?\"\n"
+	if err := os.WriteFile(filepath.Join(repo, ".forge", "workflows", "gated.toml"), []byte(workflow), 0o644); err != nil {
+		t.Fatalf("write workflow: %v", err)
+	}
+
+	cleanupConfig := withTempConfig(t, repo)
+	defer cleanupConfig()
+
+	withWorkingDir(t, repo, func() {
+		database, err := openDatabase()
+		if err != nil {
+			t.Fatalf("open database: %v", err)
+		}
+		defer database.Close()
+
+		ctx := context.Background()
+		wf, err := loadWorkflowByName(repo, "gated")
+		if err != nil {
+			t.Fatalf("load workflow: %v", err)
+		}
+		run, err := workflows.NewRunner(database, GetConfig()).CreateRun(ctx, wf, nil)
+		if err != nil {
+			t.Fatalf("create run: %v", err)
+		}
+
+		// Simulate a runner that requested approval and then exited.
+		runRepo := db.NewWorkflowRunRepository(database)
+		step, err := runRepo.GetStep(ctx, run.ID, "signoff")
+		if err != nil {
+			t.Fatalf("get step: %v", err)
+		}
+		step.Status = models.WorkflowStepStatusWaiting
+		if err := runRepo.UpdateStep(ctx, step); err != nil {
+			t.Fatalf("update step: %v", err)
+		}
+		run.Status = models.WorkflowRunStatusRunning
+		if err := runRepo.Update(ctx, run); err != nil {
+			t.Fatalf("update run: %v", err)
+		}
+		if err := db.NewApprovalRepository(database).Create(ctx, &models.Approval{
+			WorkflowRunID:  run.ID,
+			WorkflowStepID: "signoff",
+			RequestType:    models.ApprovalRequestWorkflowStep,
+			RequestDetails: json.RawMessage(`{"prompt":"Ship it?"}`),
+		}); err != nil {
+			t.Fatalf("create approval: %v", err)
+		}
+
+		out, err := captureStdout(func() error {
+			return workflowStatusCmd.RunE(workflowStatusCmd, []string{run.ID})
+		})
+		if err != nil {
+			t.Fatalf("workflow status: %v", err)
+		}
+		if !strings.Contains(out, "forge workflow approve "+shortID(run.ID)+" signoff") {
+			t.Fatalf("expected approval hint in status, got %q", out)
+		}
+
+		var restarted string
+		originalStart := startWorkflowProcessFunc
+		originalNote := workflowApproveNote
+		startWorkflowProcessFunc = func(runID string) error {
+			restarted = runID
+			return nil
+		}
+		workflowApproveNote = "ship on friday"
+		defer func() {
+			startWorkflowProcessFunc = originalStart
+			workflowApproveNote = originalNote
+		}()
+
+		if _, err := captureStdout(func() error {
+			return workflowApproveCmd.RunE(workflowApproveCmd, []string{shortID(run.ID), "signoff"})
+		}); err != nil {
+			t.Fatalf("workflow approve: %v", err)
+		}
+		if restarted != run.ID {
+			t.Fatalf("expected orphaned run to be restarted, got %q", restarted)
+		}
+
+		step, err = runRepo.GetStep(ctx, run.ID, "signoff")
+		if err != nil {
+			t.Fatalf("get step: %v", err)
+		}
+		if step.Status != models.WorkflowStepStatusSuccess || step.Outputs["note"] != "ship on friday" {
+			t.Fatalf("expected approved step with note, got %s %v", step.Status, step.Outputs)
+		}
+	})
+}
+
+func TestWorkflowParasiteLifetime(t *testing.T) {
+	base := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	at := func(offset time.Duration) *time.Time {
+		ts := base.Add(offset)
+		return &ts
+	}
+
+	byID := map[string]*models.WorkflowStepRun{
+		"build": {StepID: "build", StartedAt: at(0), FinishedAt: at(10 * time.Second)},
+		"test":  {StepID: "test", StartedAt: at(2 * time.Second)},
+	}
+
+	watch := &models.WorkflowStepRun{StepID: "watch", StartedAt: at(3 * time.Second), FinishedAt: at(11 * time.Second)}
+	if got := workflowParasiteLifetime(watch, []string{"build", "test"}, byID); got != "alive_with build,test (start +1s, stop +1s)" {
+		t.Fatalf("unexpected lifetime %q", got)
+	}
+
+	watch.FinishedAt = nil
+	if got := workflowParasiteLifetime(watch, []string{"test"}, byID); got != "alive_with test (start +1s, running)" {
+		t.Fatalf("unexpected lifetime %q", got)
+	}
+
+	if got := workflowParasiteLifetime(&models.WorkflowStepRun{StepID: "watch"}, []string{"build"}, byID); got != "alive_with build" {
+		t.Fatalf("unexpected lifetime %q", got)
+	}
+}
@@ -58,6 +58,9 @@ type Config struct {
 	// Mail settings
 	Mail MailConfig `yaml:"mail" mapstructure:"mail"`
 
+	// Forged daemon settings
+	Forged ForgedConfig `yaml:"forged" mapstructure:"forged"`
+
 	// EventRetention settings
 	EventRetention EventRetentionConfig `yaml:"event_retention" mapstructure:"event_retention"`
 }
@@ -224,6 +227,24 @@ type LoopDefaultsConfig struct {
 
 	// PromptMsg is the default base prompt message (optional).
 	PromptMsg string `yaml:"prompt_msg" mapstructure:"prompt_msg"`
+
+	// RunOutputMaxAge is how long per-run output artifacts are kept. Zero means no age limit.
+	RunOutputMaxAge time.Duration `yaml:"run_output_max_age" mapstructure:"run_output_max_age"`
+
+	// RunOutputMaxRuns is how many recent runs per loop keep their output artifacts.
+	// Zero means no count limit.
+	RunOutputMaxRuns int `yaml:"run_output_max_runs" mapstructure:"run_output_max_runs"`
+
+	// HeartbeatInterval is how often a running loop records that it is alive.
+	HeartbeatInterval time.Duration `yaml:"heartbeat_interval" mapstructure:"heartbeat_interval"`
+
+	// HeartbeatTimeout is how old a loop heartbeat may get before the loop is
+	// considered orphaned.
+	HeartbeatTimeout time.Duration `yaml:"heartbeat_timeout" mapstructure:"heartbeat_timeout"`
+
+	// Checkpoints snapshots the loop's git working tree around every run so a
+	// single iteration can be diffed or reverted.
+	Checkpoints bool `yaml:"checkpoints" mapstructure:"checkpoints"`
 }
 
 // SchedulerConfig contains scheduler settings.
@@ -278,6 +299,39 @@ type MailRelayConfig struct {
 
 	// ReconnectInterval is the delay between reconnect attempts.
 	ReconnectInterval time.Duration `yaml:"reconnect_interval" mapstructure:"reconnect_interval"`
+
+	// Token is presented to peers that require auth.
+	Token string `yaml:"token" mapstructure:"token"`
+}
+
+// ForgedConfig contains forged daemon settings.
+type ForgedConfig struct {
+	// Auth controls token authentication for gRPC and mail relay.
+	Auth ForgedAuthConfig `yaml:"auth" mapstructure:"auth"`
+
+	// TLS controls transport security for gRPC and mail relay.
+	TLS ForgedTLSConfig `yaml:"tls" mapstructure:"tls"`
+}
+
+// ForgedAuthConfig contains token authentication settings.
+type ForgedAuthConfig struct {
+	// Enabled requires a valid token on every gRPC call and relay handshake.
+	Enabled bool `yaml:"enabled" mapstructure:"enabled"`
+
+	// VaultPath is the vault tokens are stored in (default: ConfigDir/vault).
+	VaultPath string `yaml:"vault_path" mapstructure:"vault_path"`
+}
+
+// ForgedTLSConfig contains TLS settings.
+type ForgedTLSConfig struct {
+	// Enabled serves gRPC over TLS and accepts TLS relay peers.
+	Enabled bool `yaml:"enabled" mapstructure:"enabled"`
+
+	// Dir holds the certificates written by "forged cert init" (default: ConfigDir/certs).
+	Dir string `yaml:"dir" mapstructure:"dir"`
+
+	// ClientAuth requires clients to present a certificate signed by the CA (mTLS).
+	ClientAuth bool `yaml:"client_auth" mapstructure:"client_auth"`
 }
 
 // EventRetentionConfig contains event retention policy settings.
@@ -355,7 +409,11 @@ func DefaultConfig() *Config {
 			AutoRotateOnRateLimit:   true,
 		},
 		LoopDefaults: LoopDefaultsConfig{
-			Interval: 30 * time.Second,
+			Interval:          30 * time.Second,
+			RunOutputMaxAge:   14 * 24 * time.Hour, // 14 days
+			RunOutputMaxRuns:  500,
+			HeartbeatInterval: 30 * time.Second,
+			HeartbeatTimeout:  5 * time.Minute,
 		},
 		TUI: TUIConfig{
 			RefreshInterval: 500 * time.Millisecond,
@@ -371,6 +429,17 @@ func DefaultConfig() *Config {
 				ReconnectInterval: 2 * time.Second,
 			},
 		},
+		Forged: ForgedConfig{
+			Auth: ForgedAuthConfig{
+				Enabled:   false,
+				VaultPath: "", // Will be set to ConfigDir/vault
+			},
+			TLS: ForgedTLSConfig{
+				Enabled:    false,
+				Dir:        "", // Will be set to ConfigDir/certs
+				ClientAuth: false,
+			},
+		},
 		EventRetention: EventRetentionConfig{
 			Enabled:             true,
 			MaxAge:              30 * 24 * time.Hour, // 30 days
@@ -451,6 +520,9 @@ func (c *Config) Validate() error {
 	if c.Mail.Relay.ReconnectInterval < 0 {
 		return fmt.Errorf("mail.relay.reconnect_interval must be zero or greater")
 	}
+	if c.Forged.TLS.ClientAuth && !c.Forged.TLS.Enabled {
+		return fmt.Errorf("forged.tls.client_auth requires forged.tls.enabled")
+	}
 
 	for i, override := range c.WorkspaceOverrides {
 		path := fmt.Sprintf("workspace_overrides[%d]", i)
@@ -577,6 +649,18 @@ func (c *Config) Validate() error {
 	if c.LoopDefaults.Interval < 0 {
 		return fmt.Errorf("loop_defaults.interval must be zero or positive")
 	}
+	if c.LoopDefaults.RunOutputMaxAge < 0 {
+		return fmt.Errorf("loop_defaults.run_output_max_age must be zero or positive")
+	}
+	if c.LoopDefaults.RunOutputMaxRuns < 0 {
+		return fmt.Errorf("loop_defaults.run_output_max_runs must be zero or positive")
+	}
+	if c.LoopDefaults.HeartbeatInterval <= 0 {
+		return fmt.Errorf("loop_defaults.heartbeat_interval must be positive")
+	}
+	if c.LoopDefaults.HeartbeatTimeout <= c.LoopDefaults.HeartbeatInterval {
+		return fmt.Errorf("loop_defaults.heartbeat_timeout must be greater than loop_defaults.heartbeat_interval")
+	}
 
 	return nil
 }
@@ -646,3 +730,19 @@ func (c *Config) ArchivePath() string {
 	}
 	return filepath.Join(c.Global.DataDir, "archives")
 }
+
+// TokenVaultPath returns the vault directory forged tokens are stored in.
+func (c *Config) TokenVaultPath() string {
+	if c.Forged.Auth.VaultPath != "" {
+		return c.Forged.Auth.VaultPath
+	}
+	return filepath.Join(c.Global.ConfigDir, "vault")
+}
+
+// CertDir returns the directory forged certificates are stored in.
+func (c *Config) CertDir() string {
+	if c.Forged.TLS.Dir != "" {
+		return c.Forged.TLS.Dir
+	}
+	return filepath.Join(c.Global.ConfigDir, "certs")
+}
@@ -88,6 +88,8 @@ func expandPaths(cfg *Config) {
 	cfg.NodeDefaults.SSHKeyPath = expandTilde(cfg.NodeDefaults.SSHKeyPath)
 	cfg.EventRetention.ArchiveDir = expandTilde(cfg.EventRetention.ArchiveDir)
 	cfg.LoopDefaults.Prompt = expandTilde(cfg.LoopDefaults.Prompt)
+	cfg.Forged.Auth.VaultPath = expandTilde(cfg.Forged.Auth.VaultPath)
+	cfg.Forged.TLS.Dir = expandTilde(cfg.Forged.TLS.Dir)
 	for i := range cfg.Profiles {
 		cfg.Profiles[i].AuthHome = expandTilde(cfg.Profiles[i].AuthHome)
 	}
@@ -179,6 +181,11 @@ func (l *Loader) setDefaults(cfg *Config) {
 	v.SetDefault("loop_defaults.interval", cfg.LoopDefaults.Interval)
 	v.SetDefault("loop_defaults.prompt", cfg.LoopDefaults.Prompt)
 	v.SetDefault("loop_defaults.prompt_msg", cfg.LoopDefaults.PromptMsg)
+	v.SetDefault("loop_defaults.run_output_max_age", cfg.LoopDefaults.RunOutputMaxAge)
+	v.SetDefault("loop_defaults.run_output_max_runs", cfg.LoopDefaults.RunOutputMaxRuns)
+	v.SetDefault("loop_defaults.heartbeat_interval", cfg.LoopDefaults.HeartbeatInterval)
+	v.SetDefault("loop_defaults.heartbeat_timeout", cfg.LoopDefaults.HeartbeatTimeout)
+	v.SetDefault("loop_defaults.checkpoints", cfg.LoopDefaults.Checkpoints)
 
 	// Pools/default pool
 	v.SetDefault("default_pool", cfg.DefaultPool)
@@ -194,6 +201,14 @@ func (l *Loader) setDefaults(cfg *Config) {
 	v.SetDefault("mail.relay.peers", cfg.Mail.Relay.Peers)
 	v.SetDefault("mail.relay.dial_timeout", cfg.Mail.Relay.DialTimeout)
 	v.SetDefault("mail.relay.reconnect_interval", cfg.Mail.Relay.ReconnectInterval)
+	v.SetDefault("mail.relay.token", cfg.Mail.Relay.Token)
+
+	// Forged
+	v.SetDefault("forged.auth.enabled", cfg.Forged.Auth.Enabled)
+	v.SetDefault("forged.auth.vault_path", cfg.Forged.Auth.VaultPath)
+	v.SetDefault("forged.tls.enabled", cfg.Forged.TLS.Enabled)
+	v.SetDefault("forged.tls.dir", cfg.Forged.TLS.Dir)
+	v.SetDefault("forged.tls.client_auth", cfg.Forged.TLS.ClientAuth)
 }
 
 // loadConfigFile attempts to load the configuration file.
@@ -304,6 +319,10 @@ func bindEnvVars(v *viper.Viper) {
 		"loop_defaults.interval",
 		"loop_defaults.prompt",
 		"loop_defaults.prompt_msg",
+		"loop_defaults.run_output_max_age",
+		"loop_defaults.run_output_max_runs",
+		"loop_defaults.heartbeat_interval",
+		"loop_defaults.heartbeat_timeout",
 		// Pools
 		"default_pool",
 		// TUI
@@ -15,9 +15,15 @@ import (
 
 // Approval repository errors.
 var (
-	ErrApprovalNotFound = errors.New("approval not found")
+	ErrApprovalNotFound   = errors.New("approval not found")
+	ErrApprovalNotPending = errors.New("approval is not pending")
 )
 
+const approvalColumns = `
+			id, agent_id, workflow_run_id, workflow_step_id,
+			request_type, request_details_json,
+			status, created_at, resolved_at, resolved_by, resolution_note`
+
 // ApprovalRepository handles approval persistence.
 type ApprovalRepository struct {
 	db *DB
@@ -30,8 +36,8 @@ func NewApprovalRepository(db *DB) *ApprovalRepository {
 
 // Create adds a new approval request to the database.
 func (r *ApprovalRepository) Create(ctx context.Context, approval *models.Approval) error {
-	if approval.AgentID == "" {
-		return fmt.Errorf("approval agent id is required")
+	if approval.AgentID == "" && approval.WorkflowRunID == "" {
+		return fmt.Errorf("approval agent id or workflow run id is required")
 	}
 	if approval.RequestType == "" {
 		return fmt.Errorf("approval request type is required")
@@ -48,19 +54,20 @@ func (r *ApprovalRepository) Create(ctx context.Context, approval *models.Approv
 	}
 
 	_, err := r.db.ExecContext(ctx, `
-		INSERT INTO approvals (
-			id, agent_id, request_type, request_details_json,
-			status, created_at, resolved_at, resolved_by
-		) VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+		INSERT INTO approvals (`+approvalColumns+`
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`,
 		approval.ID,
-		approval.AgentID,
+		nullableString(approval.AgentID),
+		nullableString(approval.WorkflowRunID),
+		nullableString(approval.WorkflowStepID),
 		string(approval.RequestType),
 		string(approval.RequestDetails),
 		string(approval.Status),
 		approval.CreatedAt.Format(time.RFC3339),
 		stringTimePtr(approval.ResolvedAt),
 		approval.ResolvedBy,
+		nullableString(approval.ResolutionNote),
 	)
 	if err != nil {
 		return fmt.Errorf("failed to insert approval: %w", err)
@@ -69,12 +76,32 @@ func (r *ApprovalRepository) Create(ctx context.Context, approval *models.Approv
 	return nil
 }
 
+// Get retrieves an approval by ID.
+func (r *ApprovalRepository) Get(ctx context.Context, id string) (*models.Approval, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+approvalColumns+`
+		FROM approvals
+		WHERE id = ?
+	`, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query approval: %w", err)
+	}
+	defer rows.Close()
+
+	approvals, err := r.scanApprovals(rows)
+	if err != nil {
+		return nil, err
+	}
+	if len(approvals) == 0 {
+		return nil, ErrApprovalNotFound
+	}
+	return approvals[0], nil
+}
+
 // ListPendingByAgent lists pending approvals for a single agent.
 func (r *ApprovalRepository) ListPendingByAgent(ctx context.Context, agentID string) ([]*models.Approval, error) {
 	rows, err := r.db.QueryContext(ctx, `
-		SELECT 
-			id, agent_id, request_type, request_details_json,
-			status, created_at, resolved_at, resolved_by
+		SELECT `+approvalColumns+`
 		FROM approvals
 		WHERE agent_id = ? AND status = 'pending'
 		ORDER BY created_at
@@ -87,6 +114,96 @@ func (r *ApprovalRepository) ListPendingByAgent(ctx context.Context, agentID str
 	return r.scanApprovals(rows)
 }
 
+// ListPendingWorkflow lists pending approvals requested by workflow steps.
+func (r *ApprovalRepository) ListPendingWorkflow(ctx context.Context) ([]*models.Approval, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+approvalColumns+`
+		FROM approvals
+		WHERE workflow_run_id IS NOT NULL AND status = 'pending'
+		ORDER BY created_at
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query approvals: %w", err)
+	}
+	defer rows.Close()
+
+	return r.scanApprovals(rows)
+}
+
+// ListByWorkflowRun lists every approval requested by a workflow run, oldest first.
+func (r *ApprovalRepository) ListByWorkflowRun(ctx context.Context, runID string) ([]*models.Approval, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+approvalColumns+`
+		FROM approvals
+		WHERE workflow_run_id = ?
+		ORDER BY created_at, rowid
+	`, runID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query approvals: %w", err)
+	}
+	defer rows.Close()
+
+	return r.scanApprovals(rows)
+}
+
+// GetPendingForWorkflowStep returns the pending approval for a workflow step.
+func (r *ApprovalRepository) GetPendingForWorkflowStep(ctx context.Context, runID, stepID string) (*models.Approval, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+approvalColumns+`
+		FROM approvals
+		WHERE workflow_run_id = ? AND workflow_step_id = ? AND status = 'pending'
+		ORDER BY created_at DESC, rowid DESC
+		LIMIT 1
+	`, runID, stepID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query approval: %w", err)
+	}
+	defer rows.Close()
+
+	approvals, err := r.scanApprovals(rows)
+	if err != nil {
+		return nil, err
+	}
+	if len(approvals) == 0 {
+		return nil, ErrApprovalNotFound
+	}
+	return approvals[0], nil
+}
+
+// Resolve moves a pending approval to a final status and records the resolver's note.
+func (r *ApprovalRepository) Resolve(ctx context.Context, id string, status models.ApprovalStatus, resolvedBy, note string) error {
+	if id == "" {
+		return fmt.Errorf("approval id is required")
+	}
+	if status == "" || status == models.ApprovalStatusPending {
+		return fmt.Errorf("approval status must be final")
+	}
+
+	now := time.Now().UTC().Format(time.RFC3339)
+
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE approvals
+		SET status = ?, resolved_at = ?, resolved_by = ?, resolution_note = ?
+		WHERE id = ? AND status = 'pending'
+	`, string(status), now, resolvedBy, nullableString(note), id)
+	if err != nil {
+		return fmt.Errorf("failed to resolve approval: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		if _, err := r.Get(ctx, id); err != nil {
+			return err
+		}
+		return ErrApprovalNotPending
+	}
+
+	return nil
+}
+
 // UpdateStatus updates the status of an approval.
 func (r *ApprovalRepository) UpdateStatus(ctx context.Context, id string, status models.ApprovalStatus, resolvedBy string) error {
 	if id == "" {
@@ -135,26 +252,37 @@ func (r *ApprovalRepository) scanApprovals(rows *sql.Rows) ([]*models.Approval,
 
 func (r *ApprovalRepository) scanApprovalFromRows(rows *sql.Rows) (*models.Approval, error) {
 	var approval models.Approval
+	var agentID sql.NullString
+	var workflowRunID sql.NullString
+	var workflowStepID sql.NullString
 	var requestType string
 	var requestDetails sql.NullString
 	var status string
 	var createdAt string
 	var resolvedAt sql.NullString
 	var resolvedBy sql.NullString
+	var resolutionNote sql.NullString
 
 	if err := rows.Scan(
 		&approval.ID,
-		&approval.AgentID,
+		&agentID,
+		&workflowRunID,
+		&workflowStepID,
 		&requestType,
 		&requestDetails,
 		&status,
 		&createdAt,
 		&resolvedAt,
 		&resolvedBy,
+		&resolutionNote,
 	); err != nil {
 		return nil, fmt.Errorf("failed to scan approval: %w", err)
 	}
 
+	approval.AgentID = agentID.String
+	approval.WorkflowRunID = workflowRunID.String
+	approval.WorkflowStepID = workflowStepID.String
+
 	approval.RequestType = models.ApprovalRequestType(requestType)
 	approval.Status = models.ApprovalStatus(status)
 
@@ -179,6 +307,9 @@ func (r *ApprovalRepository) scanApprovalFromRows(rows *sql.Rows) (*models.Appro
 	if resolvedBy.Valid {
 		approval.ResolvedBy = resolvedBy.String
 	}
+	if resolutionNote.Valid {
+		approval.ResolutionNote = resolutionNote.String
+	}
 
 	return &approval, nil
 }
@@ -88,3 +88,60 @@ func TestApprovalRepository_CreateListUpdate(t *testing.T) {
 		t.Fatalf("expected 0 pending approvals after update, got %d", len(pending))
 	}
 }
+
+func TestApprovalRepository_WorkflowStep(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	repo := NewApprovalRepository(db)
+	ctx := context.Background()
+
+	run := &models.WorkflowRun{WorkflowName: "release"}
+	if err := NewWorkflowRunRepository(db).Create(ctx, run); err != nil {
+		t.Fatalf("Create workflow run failed: %v", err)
+	}
+
+	approval := &models.Approval{
+		WorkflowRunID:  run.ID,
+		WorkflowStepID: "signoff",
+		RequestType:    models.ApprovalRequestWorkflowStep,
+		RequestDetails: json.RawMessage(`{"prompt":"ship it?"}`),
+	}
+	if err := repo.Create(ctx, approval); err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+
+	pending, err := repo.GetPendingForWorkflowStep(ctx, run.ID, "signoff")
+	if err != nil {
+		t.Fatalf("GetPendingForWorkflowStep failed: %v", err)
+	}
+	if pending.ID != approval.ID || pending.AgentID != "" {
+		t.Fatalf("unexpected pending approval: %+v", pending)
+	}
+
+	all, err := repo.ListPendingWorkflow(ctx)
+	if err != nil {
+		t.Fatalf("ListPendingWorkflow failed: %v", err)
+	}
+	if len(all) != 1 {
+		t.Fatalf("expected 1 pending workflow approval, got %d", len(all))
+	}
+
+	if err := repo.Resolve(ctx, approval.ID, models.ApprovalStatusApproved, "alice", "looks good"); err != nil {
+		t.Fatalf("Resolve failed: %v", err)
+	}
+	if err := repo.Resolve(ctx, approval.ID, models.ApprovalStatusDenied, "bob", ""); err != ErrApprovalNotPending {
+		t.Fatalf("expected ErrApprovalNotPending, got %v", err)
+	}
+
+	stored, err := repo.Get(ctx, approval.ID)
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	if stored.Status != models.ApprovalStatusApproved || stored.ResolvedBy != "alice" || stored.ResolutionNote != "looks good" {
+		t.Fatalf("unexpected resolved approval: %+v", stored)
+	}
+	if _, err := repo.GetPendingForWorkflowStep(ctx, run.ID, "signoff"); err != ErrApprovalNotFound {
+		t.Fatalf("expected ErrApprovalNotFound, got %v", err)
+	}
+}
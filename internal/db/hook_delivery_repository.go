@@ -0,0 +1,251 @@
+// Package db provides SQLite database access for Forge.
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/tOgg1/forge/internal/models"
+)
+
+// ErrHookDeliveryNotFound is returned when a hook delivery does not exist.
+var ErrHookDeliveryNotFound = errors.New("hook delivery not found")
+
+const hookDeliveryColumns = `id, hook_id, event_id, event_type, payload_json, status,
+	attempts, next_attempt_at, last_error, last_status_code, replay_of,
+	delivered_at, created_at, updated_at`
+
+// HookDeliveryRepository handles hook delivery persistence.
+type HookDeliveryRepository struct {
+	db *DB
+}
+
+// NewHookDeliveryRepository creates a new HookDeliveryRepository.
+func NewHookDeliveryRepository(db *DB) *HookDeliveryRepository {
+	return &HookDeliveryRepository{db: db}
+}
+
+// Create adds a new hook delivery.
+func (r *HookDeliveryRepository) Create(ctx context.Context, delivery *models.HookDelivery) error {
+	if delivery.HookID == "" {
+		return fmt.Errorf("hook delivery hook id is required")
+	}
+	if len(delivery.Payload) == 0 {
+		return fmt.Errorf("hook delivery payload is required")
+	}
+	if delivery.ID == "" {
+		delivery.ID = uuid.New().String()
+	}
+	if delivery.Status == "" {
+		delivery.Status = models.HookDeliveryPending
+	}
+
+	now := time.Now().UTC()
+	delivery.CreatedAt = now
+	delivery.UpdatedAt = now
+
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO hook_deliveries (`+hookDeliveryColumns+`)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`,
+		delivery.ID,
+		delivery.HookID,
+		nullableString(delivery.EventID),
+		nullableString(string(delivery.EventType)),
+		string(delivery.Payload),
+		string(delivery.Status),
+		delivery.Attempts,
+		stringTimePtr(delivery.NextAttemptAt),
+		nullableString(delivery.LastError),
+		delivery.LastStatusCode,
+		nullableString(delivery.ReplayOf),
+		stringTimePtr(delivery.DeliveredAt),
+		delivery.CreatedAt.Format(time.RFC3339),
+		delivery.UpdatedAt.Format(time.RFC3339),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to insert hook delivery: %w", err)
+	}
+	return nil
+}
+
+// Get retrieves a hook delivery by ID.
+func (r *HookDeliveryRepository) Get(ctx context.Context, id string) (*models.HookDelivery, error) {
+	row := r.db.QueryRowContext(ctx, `
+		SELECT `+hookDeliveryColumns+`
+		FROM hook_deliveries WHERE id = ?
+	`, id)
+	return r.scanDelivery(row)
+}
+
+// ListByHook retrieves deliveries for a hook, newest first. A limit <= 0
+// returns all deliveries.
+func (r *HookDeliveryRepository) ListByHook(ctx context.Context, hookID string, limit int) ([]*models.HookDelivery, error) {
+	query := `
+		SELECT ` + hookDeliveryColumns + `
+		FROM hook_deliveries
+		WHERE hook_id = ?
+		ORDER BY created_at DESC, rowid DESC
+	`
+	args := []any{hookID}
+	if limit > 0 {
+		query += " LIMIT ?"
+		args = append(args, limit)
+	}
+	return r.list(ctx, query, args...)
+}
+
+// ListDue retrieves pending deliveries whose next attempt is due, oldest first.
+func (r *HookDeliveryRepository) ListDue(ctx context.Context, now time.Time, limit int) ([]*models.HookDelivery, error) {
+	query := `
+		SELECT ` + hookDeliveryColumns + `
+		FROM hook_deliveries
+		WHERE status = 'pending' AND (next_attempt_at IS NULL OR next_attempt_at <= ?)
+		ORDER BY next_attempt_at, rowid
+	`
+	args := []any{now.UTC().Format(time.RFC3339)}
+	if limit > 0 {
+		query += " LIMIT ?"
+		args = append(args, limit)
+	}
+	return r.list(ctx, query, args...)
+}
+
+// Claim pushes a due pending delivery's next attempt to until, so other
+// processes leave it alone while it is being attempted. It returns false if
+// the delivery is no longer due, e.g. because another process claimed it.
+func (r *HookDeliveryRepository) Claim(ctx context.Context, id string, now, until time.Time) (bool, error) {
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE hook_deliveries
+		SET next_attempt_at = ?, updated_at = ?
+		WHERE id = ? AND status = 'pending' AND (next_attempt_at IS NULL OR next_attempt_at <= ?)
+	`,
+		until.UTC().Format(time.RFC3339),
+		now.UTC().Format(time.RFC3339),
+		id,
+		now.UTC().Format(time.RFC3339),
+	)
+	if err != nil {
+		return false, fmt.Errorf("failed to claim hook delivery: %w", err)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	return rows == 1, nil
+}
+
+// Update saves the outcome of a delivery attempt.
+func (r *HookDeliveryRepository) Update(ctx context.Context, delivery *models.HookDelivery) error {
+	delivery.UpdatedAt = time.Now().UTC()
+
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE hook_deliveries
+		SET status = ?, attempts = ?, next_attempt_at = ?, last_error = ?,
+			last_status_code = ?, delivered_at = ?, updated_at = ?
+		WHERE id = ?
+	`,
+		string(delivery.Status),
+		delivery.Attempts,
+		stringTimePtr(delivery.NextAttemptAt),
+		nullableString(delivery.LastError),
+		delivery.LastStatusCode,
+		stringTimePtr(delivery.DeliveredAt),
+		delivery.UpdatedAt.Format(time.RFC3339),
+		delivery.ID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update hook delivery: %w", err)
+	}
+
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		return ErrHookDeliveryNotFound
+	}
+	return nil
+}
+
+func (r *HookDeliveryRepository) list(ctx context.Context, query string, args ...any) ([]*models.HookDelivery, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query hook deliveries: %w", err)
+	}
+	defer rows.Close()
+
+	deliveries := make([]*models.HookDelivery, 0)
+	for rows.Next() {
+		delivery, err := r.scanDelivery(rows)
+		if err != nil {
+			return nil, err
+		}
+		deliveries = append(deliveries, delivery)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating hook deliveries: %w", err)
+	}
+	return deliveries, nil
+}
+
+func (r *HookDeliveryRepository) scanDelivery(scanner interface{ Scan(...any) error }) (*models.HookDelivery, error) {
+	var (
+		delivery       models.HookDelivery
+		eventID        sql.NullString
+		eventType      sql.NullString
+		payload        string
+		status         string
+		nextAttemptAt  sql.NullString
+		lastError      sql.NullString
+		lastStatusCode sql.NullInt64
+		replayOf       sql.NullString
+		deliveredAt    sql.NullString
+		createdAt      string
+		updatedAt      string
+	)
+
+	if err := scanner.Scan(
+		&delivery.ID,
+		&delivery.HookID,
+		&eventID,
+		&eventType,
+		&payload,
+		&status,
+		&delivery.Attempts,
+		&nextAttemptAt,
+		&lastError,
+		&lastStatusCode,
+		&replayOf,
+		&deliveredAt,
+		&createdAt,
+		&updatedAt,
+	); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrHookDeliveryNotFound
+		}
+		return nil, fmt.Errorf("failed to scan hook delivery: %w", err)
+	}
+
+	delivery.EventID = eventID.String
+	delivery.EventType = models.EventType(eventType.String)
+	delivery.Payload = []byte(payload)
+	delivery.Status = models.HookDeliveryStatus(status)
+	delivery.NextAttemptAt = parseNullableTime(nextAttemptAt)
+	delivery.LastError = lastError.String
+	if lastStatusCode.Valid {
+		code := int(lastStatusCode.Int64)
+		delivery.LastStatusCode = &code
+	}
+	delivery.ReplayOf = replayOf.String
+	delivery.DeliveredAt = parseNullableTime(deliveredAt)
+	if t, err := time.Parse(time.RFC3339, createdAt); err == nil {
+		delivery.CreatedAt = t
+	}
+	if t, err := time.Parse(time.RFC3339, updatedAt); err == nil {
+		delivery.UpdatedAt = t
+	}
+
+	return &delivery, nil
+}
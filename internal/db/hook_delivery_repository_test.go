@@ -0,0 +1,65 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tOgg1/forge/internal/models"
+)
+
+func TestHookDeliveryRepository_DueClaimUpdate(t *testing.T) {
+	database := setupTestDB(t)
+	defer database.Close()
+
+	ctx := context.Background()
+	repo := NewHookDeliveryRepository(database)
+	now := time.Now().UTC()
+	later := now.Add(time.Hour)
+
+	due := &models.HookDelivery{HookID: "hook-1", EventType: models.EventTypeLoopStarted, Payload: []byte(`{"id":"e1"}`), NextAttemptAt: &now}
+	notDue := &models.HookDelivery{HookID: "hook-1", Payload: []byte(`{"id":"e2"}`), NextAttemptAt: &later}
+	other := &models.HookDelivery{HookID: "hook-2", Payload: []byte(`{"id":"e3"}`), NextAttemptAt: &now}
+	for _, delivery := range []*models.HookDelivery{due, notDue, other} {
+		require.NoError(t, repo.Create(ctx, delivery))
+	}
+	require.Equal(t, models.HookDeliveryPending, due.Status)
+
+	listed, err := repo.ListDue(ctx, now, 0)
+	require.NoError(t, err)
+	require.Len(t, listed, 2)
+
+	claimed, err := repo.Claim(ctx, due.ID, now, later)
+	require.NoError(t, err)
+	require.True(t, claimed)
+	claimed, err = repo.Claim(ctx, due.ID, now, later)
+	require.NoError(t, err)
+	require.False(t, claimed, "a claimed delivery is no longer due")
+
+	code := 503
+	due.Status = models.HookDeliveryDead
+	due.Attempts = 3
+	due.LastError = "webhook returned status 503"
+	due.LastStatusCode = &code
+	due.NextAttemptAt = nil
+	require.NoError(t, repo.Update(ctx, due))
+
+	stored, err := repo.Get(ctx, due.ID)
+	require.NoError(t, err)
+	require.Equal(t, models.HookDeliveryDead, stored.Status)
+	require.Equal(t, 3, stored.Attempts)
+	require.Equal(t, models.EventTypeLoopStarted, stored.EventType)
+	require.JSONEq(t, `{"id":"e1"}`, string(stored.Payload))
+	require.NotNil(t, stored.LastStatusCode)
+	require.Equal(t, 503, *stored.LastStatusCode)
+	require.Nil(t, stored.NextAttemptAt)
+
+	history, err := repo.ListByHook(ctx, "hook-1", 0)
+	require.NoError(t, err)
+	require.Len(t, history, 2)
+	require.Equal(t, notDue.ID, history[0].ID)
+
+	_, err = repo.Get(ctx, "missing")
+	require.ErrorIs(t, err, ErrHookDeliveryNotFound)
+}
@@ -144,6 +144,33 @@ func (r *LoopQueueRepository) List(ctx context.Context, loopID string) ([]*model
 	return items, nil
 }
 
+// CountPendingByLoop returns the number of pending items per loop name.
+// Loops with an empty queue are omitted.
+func (r *LoopQueueRepository) CountPendingByLoop(ctx context.Context) (map[string]int, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT COALESCE(l.name, q.loop_id), COUNT(1)
+		FROM loop_queue_items q
+		LEFT JOIN loops l ON l.id = q.loop_id
+		WHERE q.status = ?
+		GROUP BY 1
+	`, string(models.LoopQueueStatusPending))
+	if err != nil {
+		return nil, fmt.Errorf("failed to count pending loop queue items: %w", err)
+	}
+	defer rows.Close()
+
+	counts := make(map[string]int)
+	for rows.Next() {
+		var name string
+		var count int
+		if err := rows.Scan(&name, &count); err != nil {
+			return nil, fmt.Errorf("failed to scan loop queue count: %w", err)
+		}
+		counts[name] = count
+	}
+	return counts, rows.Err()
+}
+
 // Clear removes all pending items from a loop queue.
 func (r *LoopQueueRepository) Clear(ctx context.Context, loopID string) (int, error) {
 	result, err := r.db.ExecContext(ctx, `
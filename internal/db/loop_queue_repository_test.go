@@ -79,3 +79,27 @@ func TestLoopQueueRepository_EnqueuePeekDequeue(t *testing.T) {
 		t.Fatalf("expected 2 items, got %d", len(items))
 	}
 }
+
+func TestLoopQueueRepository_CountPendingByLoop(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	loop := createTestLoop(t, db)
+	repo := NewLoopQueueRepository(db)
+	ctx := context.Background()
+
+	if err := repo.Enqueue(ctx, loop.ID, newLoopMessageItem(t, "a"), newLoopMessageItem(t, "b"), newLoopMessageItem(t, "c")); err != nil {
+		t.Fatalf("Enqueue failed: %v", err)
+	}
+	if _, err := repo.Dequeue(ctx, loop.ID); err != nil {
+		t.Fatalf("Dequeue failed: %v", err)
+	}
+
+	counts, err := repo.CountPendingByLoop(ctx)
+	if err != nil {
+		t.Fatalf("CountPendingByLoop failed: %v", err)
+	}
+	if len(counts) != 1 || counts[loop.Name] != 2 {
+		t.Fatalf("expected 2 pending items for %s, got %v", loop.Name, counts)
+	}
+}
@@ -47,6 +47,9 @@ func (r *LoopRepository) Create(ctx context.Context, loop *models.Loop) error {
 	if loop.State == "" {
 		loop.State = models.DefaultLoopState()
 	}
+	if loop.RestartPolicy == "" {
+		loop.RestartPolicy = models.LoopRestartNever
+	}
 
 	now := time.Now().UTC()
 	loop.CreatedAt = now
@@ -77,11 +80,12 @@ func (r *LoopRepository) Create(ctx context.Context, loop *models.Loop) error {
 	_, err := r.db.ExecContext(ctx, `
 		INSERT INTO loops (
 			id, short_id, name, repo_path, base_prompt_path, base_prompt_msg,
-			interval_seconds, max_iterations, max_runtime_seconds, pool_id, profile_id, state,
+			interval_seconds, max_iterations, max_runtime_seconds, max_tokens, max_cost_usd, pool_id, profile_id, state,
 			last_run_at, last_exit_code, last_error,
 			log_path, ledger_path, tags_json, metadata_json,
+			restart_policy, heartbeat_at, schedule, next_run_at,
 			created_at, updated_at
-		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`,
 		loop.ID,
 		loop.ShortID,
@@ -92,6 +96,8 @@ func (r *LoopRepository) Create(ctx context.Context, loop *models.Loop) error {
 		loop.IntervalSeconds,
 		loop.MaxIterations,
 		loop.MaxRuntimeSeconds,
+		loop.MaxTokens,
+		loop.MaxCostUSD,
 		nullableString(loop.PoolID),
 		nullableString(loop.ProfileID),
 		string(loop.State),
@@ -102,6 +108,10 @@ func (r *LoopRepository) Create(ctx context.Context, loop *models.Loop) error {
 		nullableString(loop.LedgerPath),
 		tagsJSON,
 		metadataJSON,
+		string(loop.RestartPolicy),
+		stringTimePtr(loop.HeartbeatAt),
+		nullableString(loop.Schedule),
+		stringTimePtr(loop.NextRunAt),
 		loop.CreatedAt.Format(time.RFC3339),
 		loop.UpdatedAt.Format(time.RFC3339),
 	)
@@ -120,9 +130,10 @@ func (r *LoopRepository) Get(ctx context.Context, id string) (*models.Loop, erro
 	row := r.db.QueryRowContext(ctx, `
 		SELECT
 			id, short_id, name, repo_path, base_prompt_path, base_prompt_msg,
-			interval_seconds, max_iterations, max_runtime_seconds, pool_id, profile_id, state,
+			interval_seconds, max_iterations, max_runtime_seconds, max_tokens, max_cost_usd, pool_id, profile_id, state,
 			last_run_at, last_exit_code, last_error,
 			log_path, ledger_path, tags_json, metadata_json,
+			restart_policy, heartbeat_at, schedule, next_run_at,
 			created_at, updated_at
 		FROM loops WHERE id = ?
 	`, id)
@@ -135,9 +146,10 @@ func (r *LoopRepository) GetByName(ctx context.Context, name string) (*models.Lo
 	row := r.db.QueryRowContext(ctx, `
 		SELECT
 			id, short_id, name, repo_path, base_prompt_path, base_prompt_msg,
-			interval_seconds, max_iterations, max_runtime_seconds, pool_id, profile_id, state,
+			interval_seconds, max_iterations, max_runtime_seconds, max_tokens, max_cost_usd, pool_id, profile_id, state,
 			last_run_at, last_exit_code, last_error,
 			log_path, ledger_path, tags_json, metadata_json,
+			restart_policy, heartbeat_at, schedule, next_run_at,
 			created_at, updated_at
 		FROM loops WHERE name = ?
 	`, name)
@@ -150,9 +162,10 @@ func (r *LoopRepository) GetByShortID(ctx context.Context, shortID string) (*mod
 	row := r.db.QueryRowContext(ctx, `
 		SELECT
 			id, short_id, name, repo_path, base_prompt_path, base_prompt_msg,
-			interval_seconds, max_iterations, max_runtime_seconds, pool_id, profile_id, state,
+			interval_seconds, max_iterations, max_runtime_seconds, max_tokens, max_cost_usd, pool_id, profile_id, state,
 			last_run_at, last_exit_code, last_error,
 			log_path, ledger_path, tags_json, metadata_json,
+			restart_policy, heartbeat_at, schedule, next_run_at,
 			created_at, updated_at
 		FROM loops WHERE short_id = ?
 	`, shortID)
@@ -165,9 +178,10 @@ func (r *LoopRepository) List(ctx context.Context) ([]*models.Loop, error) {
 	rows, err := r.db.QueryContext(ctx, `
 		SELECT
 			id, short_id, name, repo_path, base_prompt_path, base_prompt_msg,
-			interval_seconds, max_iterations, max_runtime_seconds, pool_id, profile_id, state,
+			interval_seconds, max_iterations, max_runtime_seconds, max_tokens, max_cost_usd, pool_id, profile_id, state,
 			last_run_at, last_exit_code, last_error,
 			log_path, ledger_path, tags_json, metadata_json,
+			restart_policy, heartbeat_at, schedule, next_run_at,
 			created_at, updated_at
 		FROM loops
 		ORDER BY created_at
@@ -191,46 +205,76 @@ func (r *LoopRepository) List(ctx context.Context) ([]*models.Loop, error) {
 
 // Update updates a loop.
 func (r *LoopRepository) Update(ctx context.Context, loop *models.Loop) error {
-	if err := r.ensureLoopShortID(ctx, loop); err != nil {
+	rows, err := r.update(ctx, loop, "")
+	if err != nil {
 		return err
 	}
+	if rows == 0 {
+		return ErrLoopNotFound
+	}
+	return nil
+}
+
+// LoopVersion is what a caller last read of a loop's state, heartbeat and
+// metadata. UpdateIfUnchanged only writes when the stored row still matches it.
+type LoopVersion struct {
+	state       models.LoopState
+	heartbeatAt *string
+	metadata    *string
+}
+
+// VersionOf captures the version of a loop as read, before it is modified.
+func VersionOf(loop *models.Loop) (LoopVersion, error) {
+	metadataJSON, err := marshalLoopMetadata(loop.Metadata)
+	if err != nil {
+		return LoopVersion{}, err
+	}
+	return LoopVersion{state: loop.State, heartbeatAt: stringTimePtr(loop.HeartbeatAt), metadata: metadataJSON}, nil
+}
+
+// UpdateIfUnchanged updates a loop only if nobody else changed its state,
+// heartbeat or metadata since version was read, and reports whether it did.
+// Concurrent reapers use it to claim a loop before acting on it.
+func (r *LoopRepository) UpdateIfUnchanged(ctx context.Context, loop *models.Loop, version LoopVersion) (bool, error) {
+	rows, err := r.update(ctx, loop, " AND state = ? AND heartbeat_at IS ? AND metadata_json IS ?",
+		string(version.state), version.heartbeatAt, version.metadata)
+	if err != nil {
+		return false, err
+	}
+	return rows == 1, nil
+}
+
+func (r *LoopRepository) update(ctx context.Context, loop *models.Loop, condition string, conditionArgs ...any) (int64, error) {
+	if err := r.ensureLoopShortID(ctx, loop); err != nil {
+		return 0, err
+	}
 	if err := loop.Validate(); err != nil {
-		return fmt.Errorf("invalid loop: %w", err)
+		return 0, fmt.Errorf("invalid loop: %w", err)
 	}
 
+	if loop.RestartPolicy == "" {
+		loop.RestartPolicy = models.LoopRestartNever
+	}
 	loop.UpdatedAt = time.Now().UTC()
 
 	var tagsJSON *string
 	if len(loop.Tags) > 0 {
 		data, err := json.Marshal(loop.Tags)
 		if err != nil {
-			return fmt.Errorf("failed to marshal tags: %w", err)
+			return 0, fmt.Errorf("failed to marshal tags: %w", err)
 		}
 		value := string(data)
 		tagsJSON = &value
 	}
 
-	var metadataJSON *string
-	if loop.Metadata != nil {
-		data, err := json.Marshal(loop.Metadata)
-		if err != nil {
-			return fmt.Errorf("failed to marshal metadata: %w", err)
-		}
-		value := string(data)
-		metadataJSON = &value
+	metadataJSON, err := marshalLoopMetadata(loop.Metadata)
+	if err != nil {
+		return 0, err
 	}
 
 	lastRunAt := stringTimePtr(loop.LastRunAt)
 
-	result, err := r.db.ExecContext(ctx, `
-		UPDATE loops
-		SET short_id = ?, name = ?, repo_path = ?, base_prompt_path = ?, base_prompt_msg = ?,
-			interval_seconds = ?, max_iterations = ?, max_runtime_seconds = ?, pool_id = ?, profile_id = ?, state = ?,
-			last_run_at = ?, last_exit_code = ?, last_error = ?,
-			log_path = ?, ledger_path = ?, tags_json = ?, metadata_json = ?,
-			updated_at = ?
-		WHERE id = ?
-	`,
+	args := []any{
 		loop.ShortID,
 		loop.Name,
 		loop.RepoPath,
@@ -239,6 +283,8 @@ func (r *LoopRepository) Update(ctx context.Context, loop *models.Loop) error {
 		loop.IntervalSeconds,
 		loop.MaxIterations,
 		loop.MaxRuntimeSeconds,
+		loop.MaxTokens,
+		loop.MaxCostUSD,
 		nullableString(loop.PoolID),
 		nullableString(loop.ProfileID),
 		string(loop.State),
@@ -249,13 +295,65 @@ func (r *LoopRepository) Update(ctx context.Context, loop *models.Loop) error {
 		nullableString(loop.LedgerPath),
 		tagsJSON,
 		metadataJSON,
+		string(loop.RestartPolicy),
+		stringTimePtr(loop.HeartbeatAt),
+		nullableString(loop.Schedule),
+		stringTimePtr(loop.NextRunAt),
 		loop.UpdatedAt.Format(time.RFC3339),
 		loop.ID,
+	}
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE loops
+		SET short_id = ?, name = ?, repo_path = ?, base_prompt_path = ?, base_prompt_msg = ?,
+			interval_seconds = ?, max_iterations = ?, max_runtime_seconds = ?, max_tokens = ?, max_cost_usd = ?, pool_id = ?, profile_id = ?, state = ?,
+			last_run_at = ?, last_exit_code = ?, last_error = ?,
+			log_path = ?, ledger_path = ?, tags_json = ?, metadata_json = ?,
+			restart_policy = ?, heartbeat_at = ?, schedule = ?, next_run_at = ?,
+			updated_at = ?
+		WHERE id = ?`+condition,
+		append(args, conditionArgs...)...,
 	)
 	if err != nil {
-		return fmt.Errorf("failed to update loop: %w", err)
+		return 0, fmt.Errorf("failed to update loop: %w", err)
 	}
 
+	rows, _ := result.RowsAffected()
+	return rows, nil
+}
+
+func marshalLoopMetadata(metadata map[string]any) (*string, error) {
+	if metadata == nil {
+		return nil, nil
+	}
+	data, err := json.Marshal(metadata)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal metadata: %w", err)
+	}
+	value := string(data)
+	return &value, nil
+}
+
+// Heartbeat records that the loop's runner is alive without touching other columns.
+func (r *LoopRepository) Heartbeat(ctx context.Context, id string, at time.Time) error {
+	result, err := r.db.ExecContext(ctx, `UPDATE loops SET heartbeat_at = ? WHERE id = ?`, at.UTC().Format(time.RFC3339), id)
+	if err != nil {
+		return fmt.Errorf("failed to record loop heartbeat: %w", err)
+	}
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		return ErrLoopNotFound
+	}
+	return nil
+}
+
+// SetState sets a loop's state and last error without touching other columns,
+// for callers that must not write back a loop they read earlier.
+func (r *LoopRepository) SetState(ctx context.Context, id string, state models.LoopState, lastError string) error {
+	result, err := r.db.ExecContext(ctx, `UPDATE loops SET state = ?, last_error = ?, updated_at = ? WHERE id = ?`,
+		string(state), nullableString(lastError), time.Now().UTC().Format(time.RFC3339), id)
+	if err != nil {
+		return fmt.Errorf("failed to set loop state: %w", err)
+	}
 	rows, _ := result.RowsAffected()
 	if rows == 0 {
 		return ErrLoopNotFound
@@ -287,6 +385,8 @@ func (r *LoopRepository) scanLoop(scanner interface{ Scan(...any) error }) (*mod
 		intervalSeconds int
 		maxIterations   int
 		maxRuntimeSecs  int
+		maxTokens       int64
+		maxCostUSD      float64
 		poolID          sql.NullString
 		profileID       sql.NullString
 		state           string
@@ -297,6 +397,10 @@ func (r *LoopRepository) scanLoop(scanner interface{ Scan(...any) error }) (*mod
 		ledgerPath      sql.NullString
 		tagsJSON        sql.NullString
 		metadataJSON    sql.NullString
+		restartPolicy   sql.NullString
+		heartbeatAt     sql.NullString
+		schedule        sql.NullString
+		nextRunAt       sql.NullString
 		createdAt       string
 		updatedAt       string
 	)
@@ -311,6 +415,8 @@ func (r *LoopRepository) scanLoop(scanner interface{ Scan(...any) error }) (*mod
 		&intervalSeconds,
 		&maxIterations,
 		&maxRuntimeSecs,
+		&maxTokens,
+		&maxCostUSD,
 		&poolID,
 		&profileID,
 		&state,
@@ -321,6 +427,10 @@ func (r *LoopRepository) scanLoop(scanner interface{ Scan(...any) error }) (*mod
 		&ledgerPath,
 		&tagsJSON,
 		&metadataJSON,
+		&restartPolicy,
+		&heartbeatAt,
+		&schedule,
+		&nextRunAt,
 		&createdAt,
 		&updatedAt,
 	); err != nil {
@@ -340,12 +450,16 @@ func (r *LoopRepository) scanLoop(scanner interface{ Scan(...any) error }) (*mod
 		IntervalSeconds:   intervalSeconds,
 		MaxIterations:     maxIterations,
 		MaxRuntimeSeconds: maxRuntimeSecs,
+		MaxTokens:         maxTokens,
+		MaxCostUSD:        maxCostUSD,
 		PoolID:            poolID.String,
 		ProfileID:         profileID.String,
 		State:             models.LoopState(state),
 		LastError:         lastError.String,
 		LogPath:           logPath.String,
 		LedgerPath:        ledgerPath.String,
+		RestartPolicy:     models.LoopRestartPolicy(restartPolicy.String),
+		Schedule:          schedule.String,
 	}
 
 	if lastRunAt.Valid && lastRunAt.String != "" {
@@ -353,6 +467,16 @@ func (r *LoopRepository) scanLoop(scanner interface{ Scan(...any) error }) (*mod
 			loop.LastRunAt = &t
 		}
 	}
+	if heartbeatAt.Valid && heartbeatAt.String != "" {
+		if t, err := time.Parse(time.RFC3339, heartbeatAt.String); err == nil {
+			loop.HeartbeatAt = &t
+		}
+	}
+	if nextRunAt.Valid && nextRunAt.String != "" {
+		if t, err := time.Parse(time.RFC3339, nextRunAt.String); err == nil {
+			loop.NextRunAt = &t
+		}
+	}
 	if lastExitCode.Valid {
 		exitCode := int(lastExitCode.Int64)
 		loop.LastExitCode = &exitCode
@@ -55,3 +55,97 @@ func TestLoopRepository_CreateGetUpdate(t *testing.T) {
 		t.Fatalf("expected state to update")
 	}
 }
+
+func TestLoopRepository_UpdateIfUnchanged(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	repo := NewLoopRepository(db)
+	ctx := context.Background()
+
+	loop := &models.Loop{Name: "claimed", RepoPath: "/repo", State: models.LoopStateRunning, Metadata: map[string]any{"pid": 1001}}
+	if err := repo.Create(ctx, loop); err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+
+	first, err := repo.Get(ctx, loop.ID)
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	second, err := repo.Get(ctx, loop.ID)
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	firstVersion, err := VersionOf(first)
+	if err != nil {
+		t.Fatalf("VersionOf failed: %v", err)
+	}
+	secondVersion, err := VersionOf(second)
+	if err != nil {
+		t.Fatalf("VersionOf failed: %v", err)
+	}
+
+	first.State = models.LoopStateError
+	first.LastError = "orphaned"
+	claimed, err := repo.UpdateIfUnchanged(ctx, first, firstVersion)
+	if err != nil || !claimed {
+		t.Fatalf("first UpdateIfUnchanged: claimed=%t err=%v", claimed, err)
+	}
+
+	// The second caller read the same row, so its update must lose.
+	second.State = models.LoopStateError
+	second.LastError = "orphaned twice"
+	claimed, err = repo.UpdateIfUnchanged(ctx, second, secondVersion)
+	if err != nil || claimed {
+		t.Fatalf("second UpdateIfUnchanged: claimed=%t err=%v", claimed, err)
+	}
+
+	updated, err := repo.Get(ctx, loop.ID)
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	if updated.LastError != "orphaned" {
+		t.Fatalf("expected first update to win, got %q", updated.LastError)
+	}
+}
+
+func TestLoopRepository_SetState(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	repo := NewLoopRepository(db)
+	ctx := context.Background()
+
+	loop := &models.Loop{Name: "killed", RepoPath: "/repo", State: models.LoopStateRunning, LastError: "stale"}
+	if err := repo.Create(ctx, loop); err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+
+	// A runner writes its pid after the caller read the loop.
+	current, err := repo.Get(ctx, loop.ID)
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	current.Metadata = map[string]any{"pid": 4242}
+	if err := repo.Update(ctx, current); err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+
+	if err := repo.SetState(ctx, loop.ID, models.LoopStateStopped, ""); err != nil {
+		t.Fatalf("SetState failed: %v", err)
+	}
+	updated, err := repo.Get(ctx, loop.ID)
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	if updated.State != models.LoopStateStopped || updated.LastError != "" {
+		t.Fatalf("expected stopped loop without error, got %s %q", updated.State, updated.LastError)
+	}
+	if updated.Metadata["pid"] != float64(4242) {
+		t.Fatalf("expected SetState to keep metadata, got %v", updated.Metadata)
+	}
+
+	if err := repo.SetState(ctx, "missing", models.LoopStateStopped, ""); err != ErrLoopNotFound {
+		t.Fatalf("SetState on a missing loop: got %v, want ErrLoopNotFound", err)
+	}
+}
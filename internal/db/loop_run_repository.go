@@ -7,6 +7,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -59,8 +60,10 @@ func (r *LoopRunRepository) Create(ctx context.Context, run *models.LoopRun) err
 		INSERT INTO loop_runs (
 			id, loop_id, profile_id, status,
 			prompt_source, prompt_path, prompt_override,
-			started_at, finished_at, exit_code, output_tail, metadata_json
-		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+			started_at, finished_at, exit_code, output_tail, metadata_json,
+			output_path, prompt_sent_path, events_path, checkpoint_ref, commit_before, commit_after,
+			input_tokens, output_tokens, cache_read_tokens, cache_write_tokens, total_tokens, cost_usd
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`,
 		run.ID,
 		run.LoopID,
@@ -74,6 +77,18 @@ func (r *LoopRunRepository) Create(ctx context.Context, run *models.LoopRun) err
 		run.ExitCode,
 		nullableString(run.OutputTail),
 		metadataJSON,
+		nullableString(run.OutputPath),
+		nullableString(run.PromptSentPath),
+		nullableString(run.EventsPath),
+		nullableString(run.CheckpointRef),
+		nullableString(run.CommitBefore),
+		nullableString(run.CommitAfter),
+		run.Usage.InputTokens,
+		run.Usage.OutputTokens,
+		run.Usage.CacheReadTokens,
+		run.Usage.CacheWriteTokens,
+		run.Usage.TotalTokens,
+		run.Usage.CostUSD,
 	)
 	if err != nil {
 		return fmt.Errorf("failed to insert loop run: %w", err)
@@ -87,7 +102,9 @@ func (r *LoopRunRepository) Get(ctx context.Context, id string) (*models.LoopRun
 	row := r.db.QueryRowContext(ctx, `
 		SELECT id, loop_id, profile_id, status,
 			prompt_source, prompt_path, prompt_override,
-			started_at, finished_at, exit_code, output_tail, metadata_json
+			started_at, finished_at, exit_code, output_tail, metadata_json,
+			output_path, prompt_sent_path, events_path, checkpoint_ref, commit_before, commit_after,
+			input_tokens, output_tokens, cache_read_tokens, cache_write_tokens, total_tokens, cost_usd
 		FROM loop_runs WHERE id = ?
 	`, id)
 
@@ -99,7 +116,9 @@ func (r *LoopRunRepository) ListByLoop(ctx context.Context, loopID string) ([]*m
 	rows, err := r.db.QueryContext(ctx, `
 		SELECT id, loop_id, profile_id, status,
 			prompt_source, prompt_path, prompt_override,
-			started_at, finished_at, exit_code, output_tail, metadata_json
+			started_at, finished_at, exit_code, output_tail, metadata_json,
+			output_path, prompt_sent_path, events_path, checkpoint_ref, commit_before, commit_after,
+			input_tokens, output_tokens, cache_read_tokens, cache_write_tokens, total_tokens, cost_usd
 		FROM loop_runs
 		WHERE loop_id = ?
 		ORDER BY started_at DESC
@@ -121,6 +140,69 @@ func (r *LoopRunRepository) ListByLoop(ctx context.Context, loopID string) ([]*m
 	return runs, nil
 }
 
+// ListByIDPrefix retrieves runs whose ID starts with prefix, newest first.
+func (r *LoopRunRepository) ListByIDPrefix(ctx context.Context, prefix string, limit int) ([]*models.LoopRun, error) {
+	if limit <= 0 {
+		limit = 10
+	}
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT id, loop_id, profile_id, status,
+			prompt_source, prompt_path, prompt_override,
+			started_at, finished_at, exit_code, output_tail, metadata_json,
+			output_path, prompt_sent_path, events_path, checkpoint_ref, commit_before, commit_after,
+			input_tokens, output_tokens, cache_read_tokens, cache_write_tokens, total_tokens, cost_usd
+		FROM loop_runs
+		WHERE id LIKE ? || '%'
+		ORDER BY started_at DESC
+		LIMIT ?
+	`, prefix, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query loop runs: %w", err)
+	}
+	defer rows.Close()
+
+	runs := make([]*models.LoopRun, 0)
+	for rows.Next() {
+		run, err := r.scanLoopRun(rows)
+		if err != nil {
+			return nil, err
+		}
+		runs = append(runs, run)
+	}
+
+	return runs, nil
+}
+
+// LastWithEvents retrieves the newest run of a loop that stored structured
+// events.
+func (r *LoopRunRepository) LastWithEvents(ctx context.Context, loopID string) (*models.LoopRun, error) {
+	row := r.db.QueryRowContext(ctx, `
+		SELECT id, loop_id, profile_id, status,
+			prompt_source, prompt_path, prompt_override,
+			started_at, finished_at, exit_code, output_tail, metadata_json,
+			output_path, prompt_sent_path, events_path, checkpoint_ref, commit_before, commit_after,
+			input_tokens, output_tokens, cache_read_tokens, cache_write_tokens, total_tokens, cost_usd
+		FROM loop_runs
+		WHERE loop_id = ? AND events_path IS NOT NULL
+		ORDER BY started_at DESC
+		LIMIT 1
+	`, loopID)
+
+	return r.scanLoopRun(row)
+}
+
+// ClearArtifacts drops the output, prompt and event artifact links of a run.
+func (r *LoopRunRepository) ClearArtifacts(ctx context.Context, id string) error {
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE loop_runs SET output_path = NULL, prompt_sent_path = NULL, events_path = NULL
+		WHERE id = ?
+	`, id)
+	if err != nil {
+		return fmt.Errorf("failed to clear loop run artifacts: %w", err)
+	}
+	return nil
+}
+
 // CountRunningByProfile returns the number of running loop runs for a profile.
 func (r *LoopRunRepository) CountRunningByProfile(ctx context.Context, profileID string) (int, error) {
 	row := r.db.QueryRowContext(ctx, `
@@ -135,6 +217,28 @@ func (r *LoopRunRepository) CountRunningByProfile(ctx context.Context, profileID
 	return count, nil
 }
 
+// LastStartedByProfile returns when the most recent run on a profile started,
+// or nil if the profile has never run.
+func (r *LoopRunRepository) LastStartedByProfile(ctx context.Context, profileID string) (*time.Time, error) {
+	row := r.db.QueryRowContext(ctx, `
+		SELECT MAX(started_at) FROM loop_runs
+		WHERE profile_id = ?
+	`, profileID)
+
+	var startedAt sql.NullString
+	if err := row.Scan(&startedAt); err != nil {
+		return nil, fmt.Errorf("failed to query last loop run: %w", err)
+	}
+	if !startedAt.Valid || startedAt.String == "" {
+		return nil, nil
+	}
+	parsed, err := time.Parse(time.RFC3339, startedAt.String)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse loop run started_at: %w", err)
+	}
+	return &parsed, nil
+}
+
 // CountByLoop returns the number of runs for a loop.
 func (r *LoopRunRepository) CountByLoop(ctx context.Context, loopID string) (int, error) {
 	row := r.db.QueryRowContext(ctx, `
@@ -149,19 +253,163 @@ func (r *LoopRunRepository) CountByLoop(ctx context.Context, loopID string) (int
 	return count, nil
 }
 
+// LoopRunCount is the number of runs with a status on a profile.
+type LoopRunCount struct {
+	Status  models.LoopRunStatus
+	Profile string // profile name, or "" for runs without a profile
+	Count   int64
+}
+
+// CountByStatusAndProfile counts all runs grouped by status and profile name.
+func (r *LoopRunRepository) CountByStatusAndProfile(ctx context.Context) ([]*LoopRunCount, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT lr.status, COALESCE(p.name, lr.profile_id, ''), COUNT(1)
+		FROM loop_runs lr
+		LEFT JOIN profiles p ON p.id = lr.profile_id
+		GROUP BY 1, 2
+		ORDER BY 1, 2
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count loop runs: %w", err)
+	}
+	defer rows.Close()
+
+	counts := make([]*LoopRunCount, 0)
+	for rows.Next() {
+		count := &LoopRunCount{}
+		var status string
+		if err := rows.Scan(&status, &count.Profile, &count.Count); err != nil {
+			return nil, fmt.Errorf("failed to scan loop run count: %w", err)
+		}
+		count.Status = models.LoopRunStatus(status)
+		counts = append(counts, count)
+	}
+	return counts, rows.Err()
+}
+
+// SummarizeUsage aggregates loop run usage, grouped as the query asks.
+func (r *LoopRunRepository) SummarizeUsage(ctx context.Context, query models.LoopUsageQuery) ([]*models.LoopUsageSummary, error) {
+	var key string
+	switch query.GroupBy {
+	case models.LoopUsageByLoop, "":
+		key = "COALESCE(l.name, lr.loop_id)"
+	case models.LoopUsageByProfile:
+		key = "COALESCE(p.name, lr.profile_id, '')"
+	case models.LoopUsageByPool:
+		key = "COALESCE(po.name, l.pool_id, '')"
+	case models.LoopUsageByDay:
+		key = "substr(lr.started_at, 1, 10)"
+	default:
+		return nil, fmt.Errorf("unsupported usage grouping %q", query.GroupBy)
+	}
+
+	conditions := []string{"1 = 1"}
+	args := make([]any, 0, 2)
+	if query.LoopID != "" {
+		conditions = append(conditions, "lr.loop_id = ?")
+		args = append(args, query.LoopID)
+	}
+	if query.Since != nil {
+		conditions = append(conditions, "lr.started_at >= ?")
+		args = append(args, query.Since.UTC().Format(time.RFC3339))
+	}
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+key+` AS usage_key,
+			COUNT(1),
+			COALESCE(SUM(CASE WHEN lr.finished_at IS NOT NULL
+				THEN (julianday(lr.finished_at) - julianday(lr.started_at)) * 86400 ELSE 0 END), 0),
+			COALESCE(SUM(lr.input_tokens), 0),
+			COALESCE(SUM(lr.output_tokens), 0),
+			COALESCE(SUM(lr.cache_read_tokens), 0),
+			COALESCE(SUM(lr.cache_write_tokens), 0),
+			COALESCE(SUM(lr.total_tokens), 0),
+			COALESCE(SUM(lr.cost_usd), 0)
+		FROM loop_runs lr
+		LEFT JOIN loops l ON l.id = lr.loop_id
+		LEFT JOIN profiles p ON p.id = lr.profile_id
+		LEFT JOIN pools po ON po.id = l.pool_id
+		WHERE `+strings.Join(conditions, " AND ")+`
+		GROUP BY usage_key
+		ORDER BY usage_key
+	`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query loop run usage: %w", err)
+	}
+	defer rows.Close()
+
+	summaries := make([]*models.LoopUsageSummary, 0)
+	for rows.Next() {
+		summary := &models.LoopUsageSummary{}
+		var duration float64
+		if err := rows.Scan(
+			&summary.Key,
+			&summary.Runs,
+			&duration,
+			&summary.Usage.InputTokens,
+			&summary.Usage.OutputTokens,
+			&summary.Usage.CacheReadTokens,
+			&summary.Usage.CacheWriteTokens,
+			&summary.Usage.TotalTokens,
+			&summary.Usage.CostUSD,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan loop run usage: %w", err)
+		}
+		summary.DurationSeconds = int64(duration + 0.5)
+		summaries = append(summaries, summary)
+	}
+	return summaries, rows.Err()
+}
+
+// UsageByLoop returns the total usage of all runs of a loop.
+func (r *LoopRunRepository) UsageByLoop(ctx context.Context, loopID string) (models.LoopRunUsage, error) {
+	summaries, err := r.SummarizeUsage(ctx, models.LoopUsageQuery{GroupBy: models.LoopUsageByLoop, LoopID: loopID})
+	if err != nil || len(summaries) == 0 {
+		return models.LoopRunUsage{}, err
+	}
+	return summaries[0].Usage, nil
+}
+
 // Finish updates a loop run with completion details.
 func (r *LoopRunRepository) Finish(ctx context.Context, run *models.LoopRun) error {
 	finishedAt := time.Now().UTC()
 	run.FinishedAt = &finishedAt
+
+	var metadataJSON *string
+	if run.Metadata != nil {
+		data, err := json.Marshal(run.Metadata)
+		if err != nil {
+			return fmt.Errorf("failed to marshal run metadata: %w", err)
+		}
+		value := string(data)
+		metadataJSON = &value
+	}
+
 	result, err := r.db.ExecContext(ctx, `
 		UPDATE loop_runs
-		SET status = ?, finished_at = ?, exit_code = ?, output_tail = ?
+		SET status = ?, finished_at = ?, exit_code = ?, output_tail = ?, metadata_json = ?,
+			output_path = ?, prompt_sent_path = ?, events_path = ?,
+			checkpoint_ref = ?, commit_before = ?, commit_after = ?,
+			input_tokens = ?, output_tokens = ?, cache_read_tokens = ?, cache_write_tokens = ?, total_tokens = ?, cost_usd = ?
 		WHERE id = ?
 	`,
 		string(run.Status),
 		stringTimePtr(run.FinishedAt),
 		run.ExitCode,
 		nullableString(run.OutputTail),
+		metadataJSON,
+		nullableString(run.OutputPath),
+		nullableString(run.PromptSentPath),
+		nullableString(run.EventsPath),
+		nullableString(run.CheckpointRef),
+		nullableString(run.CommitBefore),
+		nullableString(run.CommitAfter),
+		run.Usage.InputTokens,
+		run.Usage.OutputTokens,
+		run.Usage.CacheReadTokens,
+		run.Usage.CacheWriteTokens,
+		run.Usage.TotalTokens,
+		run.Usage.CostUSD,
 		run.ID,
 	)
 	if err != nil {
@@ -189,6 +437,13 @@ func (r *LoopRunRepository) scanLoopRun(scanner interface{ Scan(...any) error })
 		exitCode       sql.NullInt64
 		outputTail     sql.NullString
 		metadataJSON   sql.NullString
+		outputPath     sql.NullString
+		promptSentPath sql.NullString
+		eventsPath     sql.NullString
+		checkpointRef  sql.NullString
+		commitBefore   sql.NullString
+		commitAfter    sql.NullString
+		usage          models.LoopRunUsage
 	)
 
 	if err := scanner.Scan(
@@ -204,6 +459,18 @@ func (r *LoopRunRepository) scanLoopRun(scanner interface{ Scan(...any) error })
 		&exitCode,
 		&outputTail,
 		&metadataJSON,
+		&outputPath,
+		&promptSentPath,
+		&eventsPath,
+		&checkpointRef,
+		&commitBefore,
+		&commitAfter,
+		&usage.InputTokens,
+		&usage.OutputTokens,
+		&usage.CacheReadTokens,
+		&usage.CacheWriteTokens,
+		&usage.TotalTokens,
+		&usage.CostUSD,
 	); err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, ErrLoopRunNotFound
@@ -220,6 +487,13 @@ func (r *LoopRunRepository) scanLoopRun(scanner interface{ Scan(...any) error })
 		PromptPath:     promptPath.String,
 		PromptOverride: promptOverride == 1,
 		OutputTail:     outputTail.String,
+		OutputPath:     outputPath.String,
+		PromptSentPath: promptSentPath.String,
+		EventsPath:     eventsPath.String,
+		CheckpointRef:  checkpointRef.String,
+		CommitBefore:   commitBefore.String,
+		CommitAfter:    commitAfter.String,
+		Usage:          usage,
 	}
 
 	if t, err := time.Parse(time.RFC3339, startedAt); err == nil {
@@ -3,6 +3,7 @@ package db
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/tOgg1/forge/internal/models"
 )
@@ -41,6 +42,9 @@ func TestLoopRunRepository_CreateFinish(t *testing.T) {
 	run.Status = models.LoopRunStatusSuccess
 	run.ExitCode = &exitCode
 	run.OutputTail = "ok"
+	run.CheckpointRef = "refs/forge/loop/" + run.ID
+	run.CommitBefore = "1111111"
+	run.CommitAfter = "2222222"
 
 	if err := repo.Finish(ctx, run); err != nil {
 		t.Fatalf("Finish failed: %v", err)
@@ -56,6 +60,9 @@ func TestLoopRunRepository_CreateFinish(t *testing.T) {
 	if stored.ExitCode == nil || *stored.ExitCode != 0 {
 		t.Fatalf("expected exit code 0")
 	}
+	if stored.CheckpointRef != run.CheckpointRef || stored.CommitBefore != "1111111" || stored.CommitAfter != "2222222" {
+		t.Fatalf("expected checkpoints to round-trip, got %+v", stored)
+	}
 }
 
 func TestLoopRunRepository_CountByLoop(t *testing.T) {
@@ -112,3 +119,163 @@ func TestLoopRunRepository_CountByLoop(t *testing.T) {
 		t.Fatalf("expected 1 run, got %d", countB)
 	}
 }
+
+func TestLoopRunRepository_LastStartedByProfile(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	ctx := context.Background()
+	loop := createTestLoop(t, db)
+	profileRepo := NewProfileRepository(db)
+	profile := &models.Profile{Name: "pi-lru", Harness: models.HarnessPi, CommandTemplate: "pi"}
+	if err := profileRepo.Create(ctx, profile); err != nil {
+		t.Fatalf("Create profile failed: %v", err)
+	}
+
+	repo := NewLoopRunRepository(db)
+	last, err := repo.LastStartedByProfile(ctx, profile.ID)
+	if err != nil {
+		t.Fatalf("LastStartedByProfile failed: %v", err)
+	}
+	if last != nil {
+		t.Fatalf("expected no last run, got %v", last)
+	}
+
+	older := time.Date(2026, 10, 1, 8, 0, 0, 0, time.UTC)
+	newer := older.Add(time.Hour)
+	for _, startedAt := range []time.Time{newer, older} {
+		run := &models.LoopRun{LoopID: loop.ID, ProfileID: profile.ID, StartedAt: startedAt}
+		if err := repo.Create(ctx, run); err != nil {
+			t.Fatalf("Create run failed: %v", err)
+		}
+	}
+
+	last, err = repo.LastStartedByProfile(ctx, profile.ID)
+	if err != nil {
+		t.Fatalf("LastStartedByProfile failed: %v", err)
+	}
+	if last == nil || !last.Equal(newer) {
+		t.Fatalf("expected last run at %s, got %v", newer, last)
+	}
+}
+
+func TestLoopRunRepository_SummarizeUsage(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	ctx := context.Background()
+	repo := NewLoopRunRepository(db)
+	loop := createTestLoop(t, db)
+
+	usages := []models.LoopRunUsage{
+		{InputTokens: 100, OutputTokens: 20, CacheReadTokens: 5, TotalTokens: 125, CostUSD: 0.25},
+		{InputTokens: 50, OutputTokens: 10, TotalTokens: 60, CostUSD: 0.5},
+		{},
+	}
+	for _, usage := range usages {
+		run := &models.LoopRun{
+			LoopID:       loop.ID,
+			PromptSource: "base",
+			Status:       models.LoopRunStatusRunning,
+		}
+		if err := repo.Create(ctx, run); err != nil {
+			t.Fatalf("Create run failed: %v", err)
+		}
+		run.Status = models.LoopRunStatusSuccess
+		run.Usage = usage
+		if err := repo.Finish(ctx, run); err != nil {
+			t.Fatalf("Finish failed: %v", err)
+		}
+	}
+
+	summaries, err := repo.SummarizeUsage(ctx, models.LoopUsageQuery{GroupBy: models.LoopUsageByLoop})
+	if err != nil {
+		t.Fatalf("SummarizeUsage failed: %v", err)
+	}
+	if len(summaries) != 1 {
+		t.Fatalf("expected 1 summary, got %d", len(summaries))
+	}
+	summary := summaries[0]
+	if summary.Key != loop.Name || summary.Runs != 3 {
+		t.Fatalf("unexpected summary %+v", summary)
+	}
+	want := models.LoopRunUsage{InputTokens: 150, OutputTokens: 30, CacheReadTokens: 5, TotalTokens: 185, CostUSD: 0.75}
+	if summary.Usage != want {
+		t.Fatalf("usage = %+v, want %+v", summary.Usage, want)
+	}
+
+	total, err := repo.UsageByLoop(ctx, loop.ID)
+	if err != nil {
+		t.Fatalf("UsageByLoop failed: %v", err)
+	}
+	if total != want {
+		t.Fatalf("UsageByLoop = %+v, want %+v", total, want)
+	}
+
+	future := time.Now().Add(time.Hour)
+	summaries, err = repo.SummarizeUsage(ctx, models.LoopUsageQuery{GroupBy: models.LoopUsageByDay, Since: &future})
+	if err != nil {
+		t.Fatalf("SummarizeUsage failed: %v", err)
+	}
+	if len(summaries) != 0 {
+		t.Fatalf("expected no runs since %s, got %d", future, len(summaries))
+	}
+}
+
+func TestLoopRunRepository_CountByStatusAndProfile(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	ctx := context.Background()
+	loop := createTestLoop(t, db)
+	profile := &models.Profile{
+		Name:            "pi-runner",
+		Harness:         models.HarnessPi,
+		CommandTemplate: "pi -p \"{prompt}\"",
+		MaxConcurrency:  1,
+		PromptMode:      models.PromptModePath,
+	}
+	if err := NewProfileRepository(db).Create(ctx, profile); err != nil {
+		t.Fatalf("Create profile failed: %v", err)
+	}
+
+	repo := NewLoopRunRepository(db)
+	for _, spec := range []struct {
+		profileID string
+		status    models.LoopRunStatus
+	}{
+		{profile.ID, models.LoopRunStatusRunning},
+		{profile.ID, models.LoopRunStatusError},
+		{profile.ID, models.LoopRunStatusError},
+		{"", models.LoopRunStatusSuccess},
+	} {
+		run := &models.LoopRun{LoopID: loop.ID, ProfileID: spec.profileID, PromptSource: "base", Status: models.LoopRunStatusRunning}
+		if err := repo.Create(ctx, run); err != nil {
+			t.Fatalf("Create run failed: %v", err)
+		}
+		if spec.status != models.LoopRunStatusRunning {
+			run.Status = spec.status
+			if err := repo.Finish(ctx, run); err != nil {
+				t.Fatalf("Finish failed: %v", err)
+			}
+		}
+	}
+
+	counts, err := repo.CountByStatusAndProfile(ctx)
+	if err != nil {
+		t.Fatalf("CountByStatusAndProfile failed: %v", err)
+	}
+	got := make(map[string]int64)
+	for _, count := range counts {
+		got[string(count.Status)+"/"+count.Profile] = count.Count
+	}
+	want := map[string]int64{"error/pi-runner": 2, "running/pi-runner": 1, "success/": 1}
+	if len(got) != len(want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+	for key, value := range want {
+		if got[key] != value {
+			t.Fatalf("expected %v, got %v", want, got)
+		}
+	}
+}
@@ -0,0 +1,13 @@
+-- Migration: 013_workflow_runs (DOWN)
+-- Description: Remove workflow run persistence
+-- Created: 2026-10-16
+
+DROP TRIGGER IF EXISTS update_workflow_step_runs_timestamp;
+DROP TRIGGER IF EXISTS update_workflow_runs_timestamp;
+DROP INDEX IF EXISTS idx_workflow_step_runs_status;
+DROP INDEX IF EXISTS idx_workflow_step_runs_run_id;
+DROP TABLE IF EXISTS workflow_step_runs;
+DROP INDEX IF EXISTS idx_workflow_runs_parent;
+DROP INDEX IF EXISTS idx_workflow_runs_status;
+DROP INDEX IF EXISTS idx_workflow_runs_name;
+DROP TABLE IF EXISTS workflow_runs;
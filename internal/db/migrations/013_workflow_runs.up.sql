@@ -0,0 +1,60 @@
+-- Migration: 013_workflow_runs
+-- Description: Persist workflow runs and per-step status
+-- Created: 2026-10-16
+
+CREATE TABLE IF NOT EXISTS workflow_runs (
+    id TEXT PRIMARY KEY,
+    workflow_name TEXT NOT NULL,
+    workflow_source TEXT,
+    repo_path TEXT,
+    status TEXT NOT NULL DEFAULT 'pending',
+    parent_run_id TEXT REFERENCES workflow_runs(id) ON DELETE CASCADE,
+    parent_step_id TEXT,
+    inputs_json TEXT,
+    outputs_json TEXT,
+    error_message TEXT,
+    metadata_json TEXT,
+    started_at TEXT,
+    finished_at TEXT,
+    created_at TEXT NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%SZ', 'now')),
+    updated_at TEXT NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%SZ', 'now'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_workflow_runs_name ON workflow_runs(workflow_name);
+CREATE INDEX IF NOT EXISTS idx_workflow_runs_status ON workflow_runs(status);
+CREATE INDEX IF NOT EXISTS idx_workflow_runs_parent ON workflow_runs(parent_run_id);
+
+CREATE TABLE IF NOT EXISTS workflow_step_runs (
+    id TEXT PRIMARY KEY,
+    run_id TEXT NOT NULL REFERENCES workflow_runs(id) ON DELETE CASCADE,
+    step_id TEXT NOT NULL,
+    step_type TEXT NOT NULL,
+    status TEXT NOT NULL DEFAULT 'pending',
+    attempt INTEGER NOT NULL DEFAULT 0,
+    loop_id TEXT,
+    child_run_id TEXT,
+    log_path TEXT,
+    exit_code INTEGER,
+    outputs_json TEXT,
+    error_message TEXT,
+    started_at TEXT,
+    finished_at TEXT,
+    created_at TEXT NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%SZ', 'now')),
+    updated_at TEXT NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%SZ', 'now')),
+    UNIQUE(run_id, step_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_workflow_step_runs_run_id ON workflow_step_runs(run_id);
+CREATE INDEX IF NOT EXISTS idx_workflow_step_runs_status ON workflow_step_runs(status);
+
+CREATE TRIGGER IF NOT EXISTS update_workflow_runs_timestamp
+AFTER UPDATE ON workflow_runs
+BEGIN
+    UPDATE workflow_runs SET updated_at = strftime('%Y-%m-%dT%H:%M:%SZ', 'now') WHERE id = NEW.id;
+END;
+
+CREATE TRIGGER IF NOT EXISTS update_workflow_step_runs_timestamp
+AFTER UPDATE ON workflow_step_runs
+BEGIN
+    UPDATE workflow_step_runs SET updated_at = strftime('%Y-%m-%dT%H:%M:%SZ', 'now') WHERE id = NEW.id;
+END;
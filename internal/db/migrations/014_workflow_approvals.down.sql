@@ -0,0 +1,34 @@
+-- Migration: 014_workflow_approvals (DOWN)
+-- Description: Restore agent-only approvals
+-- Created: 2026-10-16
+
+DROP INDEX IF EXISTS idx_approvals_workflow_run;
+
+-- SQLite does not support DROP COLUMN; rebuild the table without workflow columns.
+-- Workflow approvals have no agent and cannot be kept.
+CREATE TABLE approvals_new (
+    id TEXT PRIMARY KEY,
+    agent_id TEXT NOT NULL REFERENCES agents(id) ON DELETE CASCADE,
+    request_type TEXT NOT NULL,
+    request_details_json TEXT NOT NULL,
+    status TEXT NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'approved', 'denied', 'expired')),
+    created_at TEXT NOT NULL DEFAULT (datetime('now')),
+    resolved_at TEXT,
+    resolved_by TEXT
+);
+
+INSERT INTO approvals_new (
+    id, agent_id, request_type, request_details_json,
+    status, created_at, resolved_at, resolved_by
+)
+SELECT
+    id, agent_id, request_type, request_details_json,
+    status, created_at, resolved_at, resolved_by
+FROM approvals
+WHERE agent_id IS NOT NULL;
+
+DROP TABLE approvals;
+ALTER TABLE approvals_new RENAME TO approvals;
+
+CREATE INDEX IF NOT EXISTS idx_approvals_agent_id ON approvals(agent_id);
+CREATE INDEX IF NOT EXISTS idx_approvals_status ON approvals(status);
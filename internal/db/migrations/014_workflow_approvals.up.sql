@@ -0,0 +1,35 @@
+-- Migration: 014_workflow_approvals
+-- Description: Allow approvals to gate workflow steps and record resolution notes
+-- Created: 2026-10-16
+
+-- SQLite cannot relax NOT NULL in place; rebuild approvals with an optional agent.
+CREATE TABLE approvals_new (
+    id TEXT PRIMARY KEY,
+    agent_id TEXT REFERENCES agents(id) ON DELETE CASCADE,
+    workflow_run_id TEXT REFERENCES workflow_runs(id) ON DELETE CASCADE,
+    workflow_step_id TEXT,
+    request_type TEXT NOT NULL,
+    request_details_json TEXT NOT NULL,
+    status TEXT NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'approved', 'denied', 'expired')),
+    created_at TEXT NOT NULL DEFAULT (datetime('now')),
+    resolved_at TEXT,
+    resolved_by TEXT,
+    resolution_note TEXT,
+    CHECK (agent_id IS NOT NULL OR workflow_run_id IS NOT NULL)
+);
+
+INSERT INTO approvals_new (
+    id, agent_id, request_type, request_details_json,
+    status, created_at, resolved_at, resolved_by
+)
+SELECT
+    id, agent_id, request_type, request_details_json,
+    status, created_at, resolved_at, resolved_by
+FROM approvals;
+
+DROP TABLE approvals;
+ALTER TABLE approvals_new RENAME TO approvals;
+
+CREATE INDEX IF NOT EXISTS idx_approvals_agent_id ON approvals(agent_id);
+CREATE INDEX IF NOT EXISTS idx_approvals_status ON approvals(status);
+CREATE INDEX IF NOT EXISTS idx_approvals_workflow_run ON approvals(workflow_run_id, workflow_step_id);
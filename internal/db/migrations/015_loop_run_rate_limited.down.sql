@@ -0,0 +1,34 @@
+-- Migration: 015_loop_run_rate_limited (DOWN)
+-- Description: Restore the original loop_runs status constraint
+-- Created: 2026-10-16
+
+CREATE TABLE loop_runs_old (
+    id TEXT PRIMARY KEY,
+    loop_id TEXT NOT NULL REFERENCES loops(id) ON DELETE CASCADE,
+    profile_id TEXT REFERENCES profiles(id) ON DELETE SET NULL,
+    status TEXT NOT NULL DEFAULT 'running' CHECK (status IN ('running', 'success', 'error', 'killed')),
+    prompt_source TEXT,
+    prompt_path TEXT,
+    prompt_override INTEGER NOT NULL DEFAULT 0,
+    started_at TEXT NOT NULL DEFAULT (datetime('now')),
+    finished_at TEXT,
+    exit_code INTEGER,
+    output_tail TEXT,
+    metadata_json TEXT
+);
+
+-- Rate-limited runs are recorded as errors.
+INSERT INTO loop_runs_old
+SELECT
+    id, loop_id, profile_id,
+    CASE WHEN status = 'rate_limited' THEN 'error' ELSE status END,
+    prompt_source, prompt_path, prompt_override, started_at, finished_at,
+    exit_code, output_tail, metadata_json
+FROM loop_runs;
+
+DROP TABLE loop_runs;
+ALTER TABLE loop_runs_old RENAME TO loop_runs;
+
+CREATE INDEX IF NOT EXISTS idx_loop_runs_loop_id ON loop_runs(loop_id);
+CREATE INDEX IF NOT EXISTS idx_loop_runs_profile_id ON loop_runs(profile_id);
+CREATE INDEX IF NOT EXISTS idx_loop_runs_status ON loop_runs(status);
@@ -0,0 +1,26 @@
+-- Migration: 016_loop_events (DOWN)
+-- Description: Drop loop events and restore the original entity_type constraint
+-- Created: 2026-10-16
+
+CREATE TABLE events_old (
+    id TEXT PRIMARY KEY,
+    timestamp TEXT NOT NULL DEFAULT (datetime('now')),
+    type TEXT NOT NULL,
+    entity_type TEXT NOT NULL CHECK (entity_type IN ('node', 'workspace', 'agent', 'queue', 'account', 'system')),
+    entity_id TEXT NOT NULL,
+    payload_json TEXT,
+    metadata_json TEXT
+);
+
+INSERT INTO events_old (id, timestamp, type, entity_type, entity_id, payload_json, metadata_json)
+SELECT id, timestamp, type, entity_type, entity_id, payload_json, metadata_json
+FROM events
+WHERE entity_type != 'loop';
+
+DROP TABLE events;
+ALTER TABLE events_old RENAME TO events;
+
+CREATE INDEX IF NOT EXISTS idx_events_timestamp ON events(timestamp);
+CREATE INDEX IF NOT EXISTS idx_events_type ON events(type);
+CREATE INDEX IF NOT EXISTS idx_events_entity ON events(entity_type, entity_id);
+CREATE INDEX IF NOT EXISTS idx_events_entity_timestamp ON events(entity_type, entity_id, timestamp);
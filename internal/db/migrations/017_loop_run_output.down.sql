@@ -0,0 +1,37 @@
+-- Migration: 017_loop_run_output (DOWN)
+-- Description: Remove loop run artifact paths
+-- Created: 2026-10-16
+
+-- SQLite does not support DROP COLUMN; rebuild the table without artifact paths.
+CREATE TABLE loop_runs_old (
+    id TEXT PRIMARY KEY,
+    loop_id TEXT NOT NULL REFERENCES loops(id) ON DELETE CASCADE,
+    profile_id TEXT REFERENCES profiles(id) ON DELETE SET NULL,
+    status TEXT NOT NULL DEFAULT 'running' CHECK (status IN ('running', 'success', 'error', 'killed', 'rate_limited')),
+    prompt_source TEXT,
+    prompt_path TEXT,
+    prompt_override INTEGER NOT NULL DEFAULT 0,
+    started_at TEXT NOT NULL DEFAULT (datetime('now')),
+    finished_at TEXT,
+    exit_code INTEGER,
+    output_tail TEXT,
+    metadata_json TEXT
+);
+
+INSERT INTO loop_runs_old (
+    id, loop_id, profile_id, status,
+    prompt_source, prompt_path, prompt_override,
+    started_at, finished_at, exit_code, output_tail, metadata_json
+)
+SELECT
+    id, loop_id, profile_id, status,
+    prompt_source, prompt_path, prompt_override,
+    started_at, finished_at, exit_code, output_tail, metadata_json
+FROM loop_runs;
+
+DROP TABLE loop_runs;
+ALTER TABLE loop_runs_old RENAME TO loop_runs;
+
+CREATE INDEX IF NOT EXISTS idx_loop_runs_loop_id ON loop_runs(loop_id);
+CREATE INDEX IF NOT EXISTS idx_loop_runs_profile_id ON loop_runs(profile_id);
+CREATE INDEX IF NOT EXISTS idx_loop_runs_status ON loop_runs(status);
@@ -0,0 +1,6 @@
+-- Migration: 017_loop_run_output
+-- Description: Link loop runs to their stored output and sent prompt artifacts
+-- Created: 2026-10-16
+
+ALTER TABLE loop_runs ADD COLUMN output_path TEXT;
+ALTER TABLE loop_runs ADD COLUMN prompt_sent_path TEXT;
@@ -0,0 +1,60 @@
+-- Migration: 018_loop_supervision (DOWN)
+-- Description: Remove loop heartbeats and restart policies
+-- Created: 2026-10-16
+
+DROP INDEX IF EXISTS idx_loops_short_id;
+
+-- SQLite does not support DROP COLUMN; rebuild the table without supervision columns.
+CREATE TABLE loops_new (
+    id TEXT PRIMARY KEY,
+    short_id TEXT NOT NULL,
+    name TEXT NOT NULL UNIQUE,
+    repo_path TEXT NOT NULL,
+    base_prompt_path TEXT,
+    base_prompt_msg TEXT,
+    interval_seconds INTEGER NOT NULL DEFAULT 30,
+    pool_id TEXT REFERENCES pools(id) ON DELETE SET NULL,
+    profile_id TEXT REFERENCES profiles(id) ON DELETE SET NULL,
+    state TEXT NOT NULL DEFAULT 'stopped' CHECK (state IN ('running', 'sleeping', 'waiting', 'stopped', 'error')),
+    last_run_at TEXT,
+    last_exit_code INTEGER,
+    last_error TEXT,
+    log_path TEXT,
+    ledger_path TEXT,
+    tags_json TEXT,
+    metadata_json TEXT,
+    created_at TEXT NOT NULL DEFAULT (datetime('now')),
+    updated_at TEXT NOT NULL DEFAULT (datetime('now')),
+    max_iterations INTEGER NOT NULL DEFAULT 0,
+    max_runtime_seconds INTEGER NOT NULL DEFAULT 0
+);
+
+INSERT INTO loops_new (
+    id, short_id, name, repo_path, base_prompt_path, base_prompt_msg,
+    interval_seconds, pool_id, profile_id, state,
+    last_run_at, last_exit_code, last_error,
+    log_path, ledger_path, tags_json, metadata_json,
+    created_at, updated_at, max_iterations, max_runtime_seconds
+)
+SELECT
+    id, short_id, name, repo_path, base_prompt_path, base_prompt_msg,
+    interval_seconds, pool_id, profile_id, state,
+    last_run_at, last_exit_code, last_error,
+    log_path, ledger_path, tags_json, metadata_json,
+    created_at, updated_at, max_iterations, max_runtime_seconds
+FROM loops;
+
+DROP TABLE loops;
+ALTER TABLE loops_new RENAME TO loops;
+
+CREATE INDEX IF NOT EXISTS idx_loops_repo_path ON loops(repo_path);
+CREATE INDEX IF NOT EXISTS idx_loops_state ON loops(state);
+CREATE INDEX IF NOT EXISTS idx_loops_pool_id ON loops(pool_id);
+CREATE INDEX IF NOT EXISTS idx_loops_profile_id ON loops(profile_id);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_loops_short_id ON loops(short_id);
+
+CREATE TRIGGER IF NOT EXISTS update_loops_timestamp
+AFTER UPDATE ON loops
+BEGIN
+    UPDATE loops SET updated_at = datetime('now') WHERE id = NEW.id;
+END;
@@ -0,0 +1,6 @@
+-- Migration: 018_loop_supervision
+-- Description: Add loop heartbeats and restart policies
+-- Created: 2026-10-16
+
+ALTER TABLE loops ADD COLUMN heartbeat_at TEXT;
+ALTER TABLE loops ADD COLUMN restart_policy TEXT NOT NULL DEFAULT 'never';
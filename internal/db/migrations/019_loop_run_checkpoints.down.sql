@@ -0,0 +1,41 @@
+-- Migration: 019_loop_run_checkpoints (DOWN)
+-- Description: Remove loop run git checkpoints
+-- Created: 2026-10-16
+
+-- SQLite does not support DROP COLUMN; rebuild the table without checkpoints.
+CREATE TABLE loop_runs_old (
+    id TEXT PRIMARY KEY,
+    loop_id TEXT NOT NULL REFERENCES loops(id) ON DELETE CASCADE,
+    profile_id TEXT REFERENCES profiles(id) ON DELETE SET NULL,
+    status TEXT NOT NULL DEFAULT 'running' CHECK (status IN ('running', 'success', 'error', 'killed', 'rate_limited')),
+    prompt_source TEXT,
+    prompt_path TEXT,
+    prompt_override INTEGER NOT NULL DEFAULT 0,
+    started_at TEXT NOT NULL DEFAULT (datetime('now')),
+    finished_at TEXT,
+    exit_code INTEGER,
+    output_tail TEXT,
+    metadata_json TEXT,
+    output_path TEXT,
+    prompt_sent_path TEXT
+);
+
+INSERT INTO loop_runs_old (
+    id, loop_id, profile_id, status,
+    prompt_source, prompt_path, prompt_override,
+    started_at, finished_at, exit_code, output_tail, metadata_json,
+    output_path, prompt_sent_path
+)
+SELECT
+    id, loop_id, profile_id, status,
+    prompt_source, prompt_path, prompt_override,
+    started_at, finished_at, exit_code, output_tail, metadata_json,
+    output_path, prompt_sent_path
+FROM loop_runs;
+
+DROP TABLE loop_runs;
+ALTER TABLE loop_runs_old RENAME TO loop_runs;
+
+CREATE INDEX IF NOT EXISTS idx_loop_runs_loop_id ON loop_runs(loop_id);
+CREATE INDEX IF NOT EXISTS idx_loop_runs_profile_id ON loop_runs(profile_id);
+CREATE INDEX IF NOT EXISTS idx_loop_runs_status ON loop_runs(status);
@@ -0,0 +1,7 @@
+-- Migration: 019_loop_run_checkpoints
+-- Description: Record git checkpoints taken before and after each loop run
+-- Created: 2026-10-16
+
+ALTER TABLE loop_runs ADD COLUMN checkpoint_ref TEXT;
+ALTER TABLE loop_runs ADD COLUMN commit_before TEXT;
+ALTER TABLE loop_runs ADD COLUMN commit_after TEXT;
@@ -0,0 +1,35 @@
+-- Migration: 020_loop_queue_config_update (DOWN)
+-- Description: Restore the original loop queue item type constraint
+-- Created: 2026-10-16
+
+CREATE TABLE loop_queue_items_old (
+    id TEXT PRIMARY KEY,
+    loop_id TEXT NOT NULL REFERENCES loops(id) ON DELETE CASCADE,
+    type TEXT NOT NULL CHECK (type IN (
+        'message_append',
+        'next_prompt_override',
+        'pause',
+        'stop_graceful',
+        'kill_now',
+        'steer_message'
+    )),
+    position INTEGER NOT NULL,
+    status TEXT NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'dispatched', 'completed', 'failed', 'skipped')),
+    attempts INTEGER NOT NULL DEFAULT 0,
+    payload_json TEXT NOT NULL,
+    error_message TEXT,
+    created_at TEXT NOT NULL DEFAULT (datetime('now')),
+    dispatched_at TEXT,
+    completed_at TEXT
+);
+
+-- Queued setting updates have no equivalent; drop them.
+INSERT INTO loop_queue_items_old
+SELECT * FROM loop_queue_items WHERE type != 'config_update';
+
+DROP TABLE loop_queue_items;
+ALTER TABLE loop_queue_items_old RENAME TO loop_queue_items;
+
+CREATE INDEX IF NOT EXISTS idx_loop_queue_items_loop_id ON loop_queue_items(loop_id);
+CREATE INDEX IF NOT EXISTS idx_loop_queue_items_status ON loop_queue_items(status);
+CREATE INDEX IF NOT EXISTS idx_loop_queue_items_position ON loop_queue_items(loop_id, position);
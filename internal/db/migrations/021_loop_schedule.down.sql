@@ -0,0 +1,97 @@
+-- Migration: 021_loop_schedule (DOWN)
+-- Description: Remove loop schedules and the trigger queue item type
+-- Created: 2026-10-16
+
+CREATE TABLE loop_queue_items_old (
+    id TEXT PRIMARY KEY,
+    loop_id TEXT NOT NULL REFERENCES loops(id) ON DELETE CASCADE,
+    type TEXT NOT NULL CHECK (type IN (
+        'message_append',
+        'next_prompt_override',
+        'pause',
+        'stop_graceful',
+        'kill_now',
+        'steer_message',
+        'config_update'
+    )),
+    position INTEGER NOT NULL,
+    status TEXT NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'dispatched', 'completed', 'failed', 'skipped')),
+    attempts INTEGER NOT NULL DEFAULT 0,
+    payload_json TEXT NOT NULL,
+    error_message TEXT,
+    created_at TEXT NOT NULL DEFAULT (datetime('now')),
+    dispatched_at TEXT,
+    completed_at TEXT
+);
+
+-- Triggers have no equivalent; drop them.
+INSERT INTO loop_queue_items_old
+SELECT * FROM loop_queue_items WHERE type != 'trigger';
+
+DROP TABLE loop_queue_items;
+ALTER TABLE loop_queue_items_old RENAME TO loop_queue_items;
+
+CREATE INDEX IF NOT EXISTS idx_loop_queue_items_loop_id ON loop_queue_items(loop_id);
+CREATE INDEX IF NOT EXISTS idx_loop_queue_items_status ON loop_queue_items(status);
+CREATE INDEX IF NOT EXISTS idx_loop_queue_items_position ON loop_queue_items(loop_id, position);
+
+DROP INDEX IF EXISTS idx_loops_short_id;
+
+-- SQLite does not support DROP COLUMN; rebuild the table without schedule columns.
+CREATE TABLE loops_new (
+    id TEXT PRIMARY KEY,
+    short_id TEXT NOT NULL,
+    name TEXT NOT NULL UNIQUE,
+    repo_path TEXT NOT NULL,
+    base_prompt_path TEXT,
+    base_prompt_msg TEXT,
+    interval_seconds INTEGER NOT NULL DEFAULT 30,
+    pool_id TEXT REFERENCES pools(id) ON DELETE SET NULL,
+    profile_id TEXT REFERENCES profiles(id) ON DELETE SET NULL,
+    state TEXT NOT NULL DEFAULT 'stopped' CHECK (state IN ('running', 'sleeping', 'waiting', 'stopped', 'error')),
+    last_run_at TEXT,
+    last_exit_code INTEGER,
+    last_error TEXT,
+    log_path TEXT,
+    ledger_path TEXT,
+    tags_json TEXT,
+    metadata_json TEXT,
+    created_at TEXT NOT NULL DEFAULT (datetime('now')),
+    updated_at TEXT NOT NULL DEFAULT (datetime('now')),
+    max_iterations INTEGER NOT NULL DEFAULT 0,
+    max_runtime_seconds INTEGER NOT NULL DEFAULT 0,
+    heartbeat_at TEXT,
+    restart_policy TEXT NOT NULL DEFAULT 'never'
+);
+
+INSERT INTO loops_new (
+    id, short_id, name, repo_path, base_prompt_path, base_prompt_msg,
+    interval_seconds, pool_id, profile_id, state,
+    last_run_at, last_exit_code, last_error,
+    log_path, ledger_path, tags_json, metadata_json,
+    created_at, updated_at, max_iterations, max_runtime_seconds,
+    heartbeat_at, restart_policy
+)
+SELECT
+    id, short_id, name, repo_path, base_prompt_path, base_prompt_msg,
+    interval_seconds, pool_id, profile_id, state,
+    last_run_at, last_exit_code, last_error,
+    log_path, ledger_path, tags_json, metadata_json,
+    created_at, updated_at, max_iterations, max_runtime_seconds,
+    heartbeat_at, restart_policy
+FROM loops;
+
+DROP TABLE loops;
+ALTER TABLE loops_new RENAME TO loops;
+
+CREATE INDEX IF NOT EXISTS idx_loops_repo_path ON loops(repo_path);
+CREATE INDEX IF NOT EXISTS idx_loops_state ON loops(state);
+CREATE INDEX IF NOT EXISTS idx_loops_pool_id ON loops(pool_id);
+CREATE INDEX IF NOT EXISTS idx_loops_profile_id ON loops(profile_id);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_loops_short_id ON loops(short_id);
+
+CREATE TRIGGER IF NOT EXISTS update_loops_timestamp
+AFTER UPDATE ON loops
+BEGIN
+    UPDATE loops SET updated_at = datetime('now') WHERE id = NEW.id;
+END;
@@ -0,0 +1,107 @@
+-- Migration: 022_loop_usage (DOWN)
+-- Description: Remove loop run usage and per-loop budgets
+-- Created: 2026-10-16
+
+-- SQLite does not support DROP COLUMN; rebuild loop_runs without usage columns.
+CREATE TABLE loop_runs_old (
+    id TEXT PRIMARY KEY,
+    loop_id TEXT NOT NULL REFERENCES loops(id) ON DELETE CASCADE,
+    profile_id TEXT REFERENCES profiles(id) ON DELETE SET NULL,
+    status TEXT NOT NULL DEFAULT 'running' CHECK (status IN ('running', 'success', 'error', 'killed', 'rate_limited')),
+    prompt_source TEXT,
+    prompt_path TEXT,
+    prompt_override INTEGER NOT NULL DEFAULT 0,
+    started_at TEXT NOT NULL DEFAULT (datetime('now')),
+    finished_at TEXT,
+    exit_code INTEGER,
+    output_tail TEXT,
+    metadata_json TEXT,
+    output_path TEXT,
+    prompt_sent_path TEXT,
+    checkpoint_ref TEXT,
+    commit_before TEXT,
+    commit_after TEXT
+);
+
+INSERT INTO loop_runs_old (
+    id, loop_id, profile_id, status,
+    prompt_source, prompt_path, prompt_override,
+    started_at, finished_at, exit_code, output_tail, metadata_json,
+    output_path, prompt_sent_path, checkpoint_ref, commit_before, commit_after
+)
+SELECT
+    id, loop_id, profile_id, status,
+    prompt_source, prompt_path, prompt_override,
+    started_at, finished_at, exit_code, output_tail, metadata_json,
+    output_path, prompt_sent_path, checkpoint_ref, commit_before, commit_after
+FROM loop_runs;
+
+DROP TABLE loop_runs;
+ALTER TABLE loop_runs_old RENAME TO loop_runs;
+
+CREATE INDEX IF NOT EXISTS idx_loop_runs_loop_id ON loop_runs(loop_id);
+CREATE INDEX IF NOT EXISTS idx_loop_runs_profile_id ON loop_runs(profile_id);
+CREATE INDEX IF NOT EXISTS idx_loop_runs_status ON loop_runs(status);
+
+DROP INDEX IF EXISTS idx_loops_short_id;
+
+-- SQLite does not support DROP COLUMN; rebuild the table without budget columns.
+CREATE TABLE loops_new (
+    id TEXT PRIMARY KEY,
+    short_id TEXT NOT NULL,
+    name TEXT NOT NULL UNIQUE,
+    repo_path TEXT NOT NULL,
+    base_prompt_path TEXT,
+    base_prompt_msg TEXT,
+    interval_seconds INTEGER NOT NULL DEFAULT 30,
+    pool_id TEXT REFERENCES pools(id) ON DELETE SET NULL,
+    profile_id TEXT REFERENCES profiles(id) ON DELETE SET NULL,
+    state TEXT NOT NULL DEFAULT 'stopped' CHECK (state IN ('running', 'sleeping', 'waiting', 'stopped', 'error')),
+    last_run_at TEXT,
+    last_exit_code INTEGER,
+    last_error TEXT,
+    log_path TEXT,
+    ledger_path TEXT,
+    tags_json TEXT,
+    metadata_json TEXT,
+    created_at TEXT NOT NULL DEFAULT (datetime('now')),
+    updated_at TEXT NOT NULL DEFAULT (datetime('now')),
+    max_iterations INTEGER NOT NULL DEFAULT 0,
+    max_runtime_seconds INTEGER NOT NULL DEFAULT 0,
+    heartbeat_at TEXT,
+    restart_policy TEXT NOT NULL DEFAULT 'never',
+    schedule TEXT,
+    next_run_at TEXT
+);
+
+INSERT INTO loops_new (
+    id, short_id, name, repo_path, base_prompt_path, base_prompt_msg,
+    interval_seconds, pool_id, profile_id, state,
+    last_run_at, last_exit_code, last_error,
+    log_path, ledger_path, tags_json, metadata_json,
+    created_at, updated_at, max_iterations, max_runtime_seconds,
+    heartbeat_at, restart_policy, schedule, next_run_at
+)
+SELECT
+    id, short_id, name, repo_path, base_prompt_path, base_prompt_msg,
+    interval_seconds, pool_id, profile_id, state,
+    last_run_at, last_exit_code, last_error,
+    log_path, ledger_path, tags_json, metadata_json,
+    created_at, updated_at, max_iterations, max_runtime_seconds,
+    heartbeat_at, restart_policy, schedule, next_run_at
+FROM loops;
+
+DROP TABLE loops;
+ALTER TABLE loops_new RENAME TO loops;
+
+CREATE INDEX IF NOT EXISTS idx_loops_repo_path ON loops(repo_path);
+CREATE INDEX IF NOT EXISTS idx_loops_state ON loops(state);
+CREATE INDEX IF NOT EXISTS idx_loops_pool_id ON loops(pool_id);
+CREATE INDEX IF NOT EXISTS idx_loops_profile_id ON loops(profile_id);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_loops_short_id ON loops(short_id);
+
+CREATE TRIGGER IF NOT EXISTS update_loops_timestamp
+AFTER UPDATE ON loops
+BEGIN
+    UPDATE loops SET updated_at = datetime('now') WHERE id = NEW.id;
+END;
@@ -0,0 +1,15 @@
+-- Migration: 022_loop_usage
+-- Description: Record token and cost usage per loop run and per-loop budgets
+-- Created: 2026-10-16
+
+ALTER TABLE loop_runs ADD COLUMN input_tokens INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE loop_runs ADD COLUMN output_tokens INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE loop_runs ADD COLUMN cache_read_tokens INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE loop_runs ADD COLUMN cache_write_tokens INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE loop_runs ADD COLUMN total_tokens INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE loop_runs ADD COLUMN cost_usd REAL NOT NULL DEFAULT 0;
+
+CREATE INDEX IF NOT EXISTS idx_loop_runs_started_at ON loop_runs(started_at);
+
+ALTER TABLE loops ADD COLUMN max_tokens INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE loops ADD COLUMN max_cost_usd REAL NOT NULL DEFAULT 0;
@@ -0,0 +1,98 @@
+-- Migration: 023_loop_run_events (DOWN)
+-- Description: Remove profile output formats and run event artifacts
+-- Created: 2026-10-16
+
+-- SQLite does not support DROP COLUMN; rebuild loop_runs without events_path.
+CREATE TABLE loop_runs_old (
+    id TEXT PRIMARY KEY,
+    loop_id TEXT NOT NULL REFERENCES loops(id) ON DELETE CASCADE,
+    profile_id TEXT REFERENCES profiles(id) ON DELETE SET NULL,
+    status TEXT NOT NULL DEFAULT 'running' CHECK (status IN ('running', 'success', 'error', 'killed', 'rate_limited')),
+    prompt_source TEXT,
+    prompt_path TEXT,
+    prompt_override INTEGER NOT NULL DEFAULT 0,
+    started_at TEXT NOT NULL DEFAULT (datetime('now')),
+    finished_at TEXT,
+    exit_code INTEGER,
+    output_tail TEXT,
+    metadata_json TEXT,
+    output_path TEXT,
+    prompt_sent_path TEXT,
+    checkpoint_ref TEXT,
+    commit_before TEXT,
+    commit_after TEXT,
+    input_tokens INTEGER NOT NULL DEFAULT 0,
+    output_tokens INTEGER NOT NULL DEFAULT 0,
+    cache_read_tokens INTEGER NOT NULL DEFAULT 0,
+    cache_write_tokens INTEGER NOT NULL DEFAULT 0,
+    total_tokens INTEGER NOT NULL DEFAULT 0,
+    cost_usd REAL NOT NULL DEFAULT 0
+);
+
+INSERT INTO loop_runs_old (
+    id, loop_id, profile_id, status,
+    prompt_source, prompt_path, prompt_override,
+    started_at, finished_at, exit_code, output_tail, metadata_json,
+    output_path, prompt_sent_path, checkpoint_ref, commit_before, commit_after,
+    input_tokens, output_tokens, cache_read_tokens, cache_write_tokens, total_tokens, cost_usd
+)
+SELECT
+    id, loop_id, profile_id, status,
+    prompt_source, prompt_path, prompt_override,
+    started_at, finished_at, exit_code, output_tail, metadata_json,
+    output_path, prompt_sent_path, checkpoint_ref, commit_before, commit_after,
+    input_tokens, output_tokens, cache_read_tokens, cache_write_tokens, total_tokens, cost_usd
+FROM loop_runs;
+
+DROP TABLE loop_runs;
+ALTER TABLE loop_runs_old RENAME TO loop_runs;
+
+CREATE INDEX IF NOT EXISTS idx_loop_runs_loop_id ON loop_runs(loop_id);
+CREATE INDEX IF NOT EXISTS idx_loop_runs_profile_id ON loop_runs(profile_id);
+CREATE INDEX IF NOT EXISTS idx_loop_runs_status ON loop_runs(status);
+CREATE INDEX IF NOT EXISTS idx_loop_runs_started_at ON loop_runs(started_at);
+
+-- Rebuild profiles without output_format.
+DROP TRIGGER IF EXISTS update_profiles_timestamp;
+
+CREATE TABLE profiles_new (
+    id TEXT PRIMARY KEY,
+    name TEXT NOT NULL UNIQUE,
+    harness TEXT NOT NULL,
+    auth_kind TEXT,
+    auth_home TEXT,
+    prompt_mode TEXT NOT NULL DEFAULT 'env' CHECK (prompt_mode IN ('env', 'stdin', 'path')),
+    command_template TEXT NOT NULL,
+    model TEXT,
+    extra_args_json TEXT,
+    env_json TEXT,
+    max_concurrency INTEGER NOT NULL DEFAULT 1,
+    cooldown_until TEXT,
+    created_at TEXT NOT NULL DEFAULT (datetime('now')),
+    updated_at TEXT NOT NULL DEFAULT (datetime('now'))
+);
+
+INSERT INTO profiles_new (
+    id, name, harness, auth_kind, auth_home,
+    prompt_mode, command_template, model,
+    extra_args_json, env_json, max_concurrency,
+    cooldown_until, created_at, updated_at
+)
+SELECT
+    id, name, harness, auth_kind, auth_home,
+    prompt_mode, command_template, model,
+    extra_args_json, env_json, max_concurrency,
+    cooldown_until, created_at, updated_at
+FROM profiles;
+
+DROP TABLE profiles;
+ALTER TABLE profiles_new RENAME TO profiles;
+
+CREATE INDEX IF NOT EXISTS idx_profiles_harness ON profiles(harness);
+CREATE INDEX IF NOT EXISTS idx_profiles_cooldown ON profiles(cooldown_until);
+
+CREATE TRIGGER IF NOT EXISTS update_profiles_timestamp
+AFTER UPDATE ON profiles
+BEGIN
+    UPDATE profiles SET updated_at = datetime('now') WHERE id = NEW.id;
+END;
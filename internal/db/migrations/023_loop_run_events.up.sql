@@ -0,0 +1,7 @@
+-- Migration: 023_loop_run_events
+-- Description: Per-profile harness output format and structured run event artifacts
+-- Created: 2026-10-16
+
+ALTER TABLE profiles ADD COLUMN output_format TEXT NOT NULL DEFAULT 'text' CHECK (output_format IN ('text', 'stream-json'));
+
+ALTER TABLE loop_runs ADD COLUMN events_path TEXT;
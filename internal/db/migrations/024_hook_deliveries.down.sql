@@ -0,0 +1,7 @@
+-- Migration: 024_hook_deliveries (DOWN)
+-- Description: Remove the hook delivery queue
+-- Created: 2026-10-16
+
+DROP INDEX IF EXISTS idx_hook_deliveries_due;
+DROP INDEX IF EXISTS idx_hook_deliveries_hook;
+DROP TABLE IF EXISTS hook_deliveries;
@@ -0,0 +1,23 @@
+-- Migration: 024_hook_deliveries
+-- Description: Persistent hook delivery queue with retries and history
+-- Created: 2026-10-16
+
+CREATE TABLE IF NOT EXISTS hook_deliveries (
+    id TEXT PRIMARY KEY,
+    hook_id TEXT NOT NULL,
+    event_id TEXT,
+    event_type TEXT,
+    payload_json TEXT NOT NULL,
+    status TEXT NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'delivered', 'dead')),
+    attempts INTEGER NOT NULL DEFAULT 0,
+    next_attempt_at TEXT,
+    last_error TEXT,
+    last_status_code INTEGER,
+    replay_of TEXT,
+    delivered_at TEXT,
+    created_at TEXT NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%SZ', 'now')),
+    updated_at TEXT NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%SZ', 'now'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_hook_deliveries_hook ON hook_deliveries(hook_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_hook_deliveries_due ON hook_deliveries(status, next_attempt_at);
@@ -42,6 +42,9 @@ func (r *ProfileRepository) Create(ctx context.Context, profile *models.Profile)
 	if profile.PromptMode == "" {
 		profile.PromptMode = models.DefaultPromptMode()
 	}
+	if profile.OutputFormat == "" {
+		profile.OutputFormat = models.OutputFormatText
+	}
 
 	now := time.Now().UTC()
 	profile.CreatedAt = now
@@ -72,10 +75,10 @@ func (r *ProfileRepository) Create(ctx context.Context, profile *models.Profile)
 	_, err := r.db.ExecContext(ctx, `
 		INSERT INTO profiles (
 			id, name, harness, auth_kind, auth_home,
-			prompt_mode, command_template, model,
+			prompt_mode, output_format, command_template, model,
 			extra_args_json, env_json, max_concurrency,
 			cooldown_until, created_at, updated_at
-		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`,
 		profile.ID,
 		profile.Name,
@@ -83,6 +86,7 @@ func (r *ProfileRepository) Create(ctx context.Context, profile *models.Profile)
 		profile.AuthKind,
 		profile.AuthHome,
 		string(profile.PromptMode),
+		string(profile.OutputFormat),
 		profile.CommandTemplate,
 		profile.Model,
 		extraArgsJSON,
@@ -107,7 +111,7 @@ func (r *ProfileRepository) Get(ctx context.Context, id string) (*models.Profile
 	row := r.db.QueryRowContext(ctx, `
 		SELECT
 			id, name, harness, auth_kind, auth_home,
-			prompt_mode, command_template, model,
+			prompt_mode, output_format, command_template, model,
 			extra_args_json, env_json, max_concurrency,
 			cooldown_until, created_at, updated_at
 		FROM profiles WHERE id = ?
@@ -121,7 +125,7 @@ func (r *ProfileRepository) GetByName(ctx context.Context, name string) (*models
 	row := r.db.QueryRowContext(ctx, `
 		SELECT
 			id, name, harness, auth_kind, auth_home,
-			prompt_mode, command_template, model,
+			prompt_mode, output_format, command_template, model,
 			extra_args_json, env_json, max_concurrency,
 			cooldown_until, created_at, updated_at
 		FROM profiles WHERE name = ?
@@ -135,7 +139,7 @@ func (r *ProfileRepository) List(ctx context.Context) ([]*models.Profile, error)
 	rows, err := r.db.QueryContext(ctx, `
 		SELECT
 			id, name, harness, auth_kind, auth_home,
-			prompt_mode, command_template, model,
+			prompt_mode, output_format, command_template, model,
 			extra_args_json, env_json, max_concurrency,
 			cooldown_until, created_at, updated_at
 		FROM profiles
@@ -165,6 +169,9 @@ func (r *ProfileRepository) Update(ctx context.Context, profile *models.Profile)
 	}
 
 	profile.UpdatedAt = time.Now().UTC()
+	if profile.OutputFormat == "" {
+		profile.OutputFormat = models.OutputFormatText
+	}
 
 	var extraArgsJSON *string
 	if len(profile.ExtraArgs) > 0 {
@@ -191,7 +198,7 @@ func (r *ProfileRepository) Update(ctx context.Context, profile *models.Profile)
 	result, err := r.db.ExecContext(ctx, `
 		UPDATE profiles
 		SET name = ?, harness = ?, auth_kind = ?, auth_home = ?,
-			prompt_mode = ?, command_template = ?, model = ?,
+			prompt_mode = ?, output_format = ?, command_template = ?, model = ?,
 			extra_args_json = ?, env_json = ?, max_concurrency = ?,
 			cooldown_until = ?, updated_at = ?
 		WHERE id = ?
@@ -201,6 +208,7 @@ func (r *ProfileRepository) Update(ctx context.Context, profile *models.Profile)
 		profile.AuthKind,
 		profile.AuthHome,
 		string(profile.PromptMode),
+		string(profile.OutputFormat),
 		profile.CommandTemplate,
 		profile.Model,
 		extraArgsJSON,
@@ -263,6 +271,7 @@ func (r *ProfileRepository) scanProfile(scanner interface {
 		authKind        sql.NullString
 		authHome        sql.NullString
 		promptMode      string
+		outputFormat    string
 		commandTemplate string
 		model           sql.NullString
 		extraArgsJSON   sql.NullString
@@ -280,6 +289,7 @@ func (r *ProfileRepository) scanProfile(scanner interface {
 		&authKind,
 		&authHome,
 		&promptMode,
+		&outputFormat,
 		&commandTemplate,
 		&model,
 		&extraArgsJSON,
@@ -302,6 +312,7 @@ func (r *ProfileRepository) scanProfile(scanner interface {
 		AuthKind:        authKind.String,
 		AuthHome:        authHome.String,
 		PromptMode:      models.PromptMode(promptMode),
+		OutputFormat:    models.OutputFormat(outputFormat),
 		CommandTemplate: commandTemplate,
 		Model:           model.String,
 		MaxConcurrency:  maxConcurrency,
@@ -0,0 +1,482 @@
+// Package db provides SQLite database access for Forge.
+package db
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/tOgg1/forge/internal/models"
+)
+
+// Workflow run repository errors.
+var (
+	ErrWorkflowRunNotFound     = errors.New("workflow run not found")
+	ErrWorkflowStepRunNotFound = errors.New("workflow step run not found")
+)
+
+// WorkflowRunRepository handles workflow run persistence.
+type WorkflowRunRepository struct {
+	db *DB
+}
+
+// NewWorkflowRunRepository creates a new WorkflowRunRepository.
+func NewWorkflowRunRepository(db *DB) *WorkflowRunRepository {
+	return &WorkflowRunRepository{db: db}
+}
+
+const workflowRunColumns = `id, workflow_name, workflow_source, repo_path, status,
+	parent_run_id, parent_step_id, inputs_json, outputs_json, error_message,
+	metadata_json, started_at, finished_at, created_at, updated_at`
+
+const workflowStepRunColumns = `id, run_id, step_id, step_type, status, attempt,
+	loop_id, child_run_id, log_path, exit_code, outputs_json, error_message,
+	started_at, finished_at, created_at, updated_at`
+
+// Create adds a new workflow run.
+func (r *WorkflowRunRepository) Create(ctx context.Context, run *models.WorkflowRun) error {
+	if run.ID == "" {
+		run.ID = uuid.New().String()
+	}
+	if run.Status == "" {
+		run.Status = models.WorkflowRunStatusPending
+	}
+	if err := run.Validate(); err != nil {
+		return fmt.Errorf("invalid workflow run: %w", err)
+	}
+
+	now := time.Now().UTC()
+	run.CreatedAt = now
+	run.UpdatedAt = now
+
+	inputsJSON, err := marshalJSONMap(run.Inputs)
+	if err != nil {
+		return fmt.Errorf("failed to marshal workflow inputs: %w", err)
+	}
+	outputsJSON, err := marshalJSONMap(run.Outputs)
+	if err != nil {
+		return fmt.Errorf("failed to marshal workflow outputs: %w", err)
+	}
+	metadataJSON, err := marshalJSONMap(run.Metadata)
+	if err != nil {
+		return fmt.Errorf("failed to marshal workflow metadata: %w", err)
+	}
+
+	_, err = r.db.ExecContext(ctx, `
+		INSERT INTO workflow_runs (`+workflowRunColumns+`)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`,
+		run.ID,
+		run.WorkflowName,
+		nullableString(run.WorkflowSource),
+		nullableString(run.RepoPath),
+		string(run.Status),
+		nullableString(run.ParentRunID),
+		nullableString(run.ParentStepID),
+		inputsJSON,
+		outputsJSON,
+		nullableString(run.Error),
+		metadataJSON,
+		stringTimePtr(run.StartedAt),
+		stringTimePtr(run.FinishedAt),
+		run.CreatedAt.Format(time.RFC3339),
+		run.UpdatedAt.Format(time.RFC3339),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to insert workflow run: %w", err)
+	}
+	return nil
+}
+
+// Get retrieves a workflow run by ID.
+func (r *WorkflowRunRepository) Get(ctx context.Context, id string) (*models.WorkflowRun, error) {
+	row := r.db.QueryRowContext(ctx, `
+		SELECT `+workflowRunColumns+`
+		FROM workflow_runs WHERE id = ?
+	`, id)
+	return r.scanRun(row)
+}
+
+// List retrieves workflow runs, newest first. A limit <= 0 returns all runs.
+func (r *WorkflowRunRepository) List(ctx context.Context, limit int) ([]*models.WorkflowRun, error) {
+	query := `
+		SELECT ` + workflowRunColumns + `
+		FROM workflow_runs
+		ORDER BY created_at DESC, id DESC
+	`
+	args := []any{}
+	if limit > 0 {
+		query += " LIMIT ?"
+		args = append(args, limit)
+	}
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query workflow runs: %w", err)
+	}
+	defer rows.Close()
+
+	runs := make([]*models.WorkflowRun, 0)
+	for rows.Next() {
+		run, err := r.scanRun(rows)
+		if err != nil {
+			return nil, err
+		}
+		runs = append(runs, run)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating workflow runs: %w", err)
+	}
+	return runs, nil
+}
+
+// Update persists the mutable fields of a workflow run.
+func (r *WorkflowRunRepository) Update(ctx context.Context, run *models.WorkflowRun) error {
+	if err := run.Validate(); err != nil {
+		return fmt.Errorf("invalid workflow run: %w", err)
+	}
+
+	run.UpdatedAt = time.Now().UTC()
+
+	inputsJSON, err := marshalJSONMap(run.Inputs)
+	if err != nil {
+		return fmt.Errorf("failed to marshal workflow inputs: %w", err)
+	}
+	outputsJSON, err := marshalJSONMap(run.Outputs)
+	if err != nil {
+		return fmt.Errorf("failed to marshal workflow outputs: %w", err)
+	}
+	metadataJSON, err := marshalJSONMap(run.Metadata)
+	if err != nil {
+		return fmt.Errorf("failed to marshal workflow metadata: %w", err)
+	}
+
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE workflow_runs
+		SET status = ?, inputs_json = ?, outputs_json = ?, error_message = ?,
+			metadata_json = ?, started_at = ?, finished_at = ?, updated_at = ?
+		WHERE id = ?
+	`,
+		string(run.Status),
+		inputsJSON,
+		outputsJSON,
+		nullableString(run.Error),
+		metadataJSON,
+		stringTimePtr(run.StartedAt),
+		stringTimePtr(run.FinishedAt),
+		run.UpdatedAt.Format(time.RFC3339),
+		run.ID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update workflow run: %w", err)
+	}
+
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		return ErrWorkflowRunNotFound
+	}
+	return nil
+}
+
+// CreateStep adds a step record to a workflow run.
+func (r *WorkflowRunRepository) CreateStep(ctx context.Context, step *models.WorkflowStepRun) error {
+	if step.ID == "" {
+		step.ID = uuid.New().String()
+	}
+	if step.Status == "" {
+		step.Status = models.WorkflowStepStatusPending
+	}
+	if err := step.Validate(); err != nil {
+		return fmt.Errorf("invalid workflow step run: %w", err)
+	}
+
+	now := time.Now().UTC()
+	step.CreatedAt = now
+	step.UpdatedAt = now
+
+	outputsJSON, err := marshalJSONMap(step.Outputs)
+	if err != nil {
+		return fmt.Errorf("failed to marshal step outputs: %w", err)
+	}
+
+	_, err = r.db.ExecContext(ctx, `
+		INSERT INTO workflow_step_runs (`+workflowStepRunColumns+`)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`,
+		step.ID,
+		step.RunID,
+		step.StepID,
+		step.StepType,
+		string(step.Status),
+		step.Attempt,
+		nullableString(step.LoopID),
+		nullableString(step.ChildRunID),
+		nullableString(step.LogPath),
+		step.ExitCode,
+		outputsJSON,
+		nullableString(step.Error),
+		stringTimePtr(step.StartedAt),
+		stringTimePtr(step.FinishedAt),
+		step.CreatedAt.Format(time.RFC3339),
+		step.UpdatedAt.Format(time.RFC3339),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to insert workflow step run: %w", err)
+	}
+	return nil
+}
+
+// GetStep retrieves a step record by run and step ID.
+func (r *WorkflowRunRepository) GetStep(ctx context.Context, runID, stepID string) (*models.WorkflowStepRun, error) {
+	row := r.db.QueryRowContext(ctx, `
+		SELECT `+workflowStepRunColumns+`
+		FROM workflow_step_runs WHERE run_id = ? AND step_id = ?
+	`, runID, stepID)
+	return r.scanStep(row)
+}
+
+// ListSteps retrieves all step records for a workflow run in creation order.
+func (r *WorkflowRunRepository) ListSteps(ctx context.Context, runID string) ([]*models.WorkflowStepRun, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+workflowStepRunColumns+`
+		FROM workflow_step_runs
+		WHERE run_id = ?
+		ORDER BY rowid
+	`, runID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query workflow step runs: %w", err)
+	}
+	defer rows.Close()
+
+	steps := make([]*models.WorkflowStepRun, 0)
+	for rows.Next() {
+		step, err := r.scanStep(rows)
+		if err != nil {
+			return nil, err
+		}
+		steps = append(steps, step)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating workflow step runs: %w", err)
+	}
+	return steps, nil
+}
+
+// UpdateStep persists the mutable fields of a step record.
+func (r *WorkflowRunRepository) UpdateStep(ctx context.Context, step *models.WorkflowStepRun) error {
+	if err := step.Validate(); err != nil {
+		return fmt.Errorf("invalid workflow step run: %w", err)
+	}
+
+	step.UpdatedAt = time.Now().UTC()
+
+	outputsJSON, err := marshalJSONMap(step.Outputs)
+	if err != nil {
+		return fmt.Errorf("failed to marshal step outputs: %w", err)
+	}
+
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE workflow_step_runs
+		SET status = ?, attempt = ?, loop_id = ?, child_run_id = ?, log_path = ?,
+			exit_code = ?, outputs_json = ?, error_message = ?,
+			started_at = ?, finished_at = ?, updated_at = ?
+		WHERE id = ?
+	`,
+		string(step.Status),
+		step.Attempt,
+		nullableString(step.LoopID),
+		nullableString(step.ChildRunID),
+		nullableString(step.LogPath),
+		step.ExitCode,
+		outputsJSON,
+		nullableString(step.Error),
+		stringTimePtr(step.StartedAt),
+		stringTimePtr(step.FinishedAt),
+		step.UpdatedAt.Format(time.RFC3339),
+		step.ID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update workflow step run: %w", err)
+	}
+
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		return ErrWorkflowStepRunNotFound
+	}
+	return nil
+}
+
+func (r *WorkflowRunRepository) scanRun(scanner interface{ Scan(...any) error }) (*models.WorkflowRun, error) {
+	var (
+		id             string
+		workflowName   string
+		workflowSource sql.NullString
+		repoPath       sql.NullString
+		status         string
+		parentRunID    sql.NullString
+		parentStepID   sql.NullString
+		inputsJSON     sql.NullString
+		outputsJSON    sql.NullString
+		errorMessage   sql.NullString
+		metadataJSON   sql.NullString
+		startedAt      sql.NullString
+		finishedAt     sql.NullString
+		createdAt      string
+		updatedAt      string
+	)
+
+	if err := scanner.Scan(
+		&id,
+		&workflowName,
+		&workflowSource,
+		&repoPath,
+		&status,
+		&parentRunID,
+		&parentStepID,
+		&inputsJSON,
+		&outputsJSON,
+		&errorMessage,
+		&metadataJSON,
+		&startedAt,
+		&finishedAt,
+		&createdAt,
+		&updatedAt,
+	); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrWorkflowRunNotFound
+		}
+		return nil, fmt.Errorf("failed to scan workflow run: %w", err)
+	}
+
+	run := &models.WorkflowRun{
+		ID:             id,
+		WorkflowName:   workflowName,
+		WorkflowSource: workflowSource.String,
+		RepoPath:       repoPath.String,
+		Status:         models.WorkflowRunStatus(status),
+		ParentRunID:    parentRunID.String,
+		ParentStepID:   parentStepID.String,
+		Error:          errorMessage.String,
+		StartedAt:      parseNullableTime(startedAt),
+		FinishedAt:     parseNullableTime(finishedAt),
+	}
+	if inputsJSON.Valid && inputsJSON.String != "" {
+		_ = json.Unmarshal([]byte(inputsJSON.String), &run.Inputs)
+	}
+	if outputsJSON.Valid && outputsJSON.String != "" {
+		_ = json.Unmarshal([]byte(outputsJSON.String), &run.Outputs)
+	}
+	if metadataJSON.Valid && metadataJSON.String != "" {
+		_ = json.Unmarshal([]byte(metadataJSON.String), &run.Metadata)
+	}
+	if t, err := time.Parse(time.RFC3339, createdAt); err == nil {
+		run.CreatedAt = t
+	}
+	if t, err := time.Parse(time.RFC3339, updatedAt); err == nil {
+		run.UpdatedAt = t
+	}
+
+	return run, nil
+}
+
+func (r *WorkflowRunRepository) scanStep(scanner interface{ Scan(...any) error }) (*models.WorkflowStepRun, error) {
+	var (
+		id           string
+		runID        string
+		stepID       string
+		stepType     string
+		status       string
+		attempt      int
+		loopID       sql.NullString
+		childRunID   sql.NullString
+		logPath      sql.NullString
+		exitCode     sql.NullInt64
+		outputsJSON  sql.NullString
+		errorMessage sql.NullString
+		startedAt    sql.NullString
+		finishedAt   sql.NullString
+		createdAt    string
+		updatedAt    string
+	)
+
+	if err := scanner.Scan(
+		&id,
+		&runID,
+		&stepID,
+		&stepType,
+		&status,
+		&attempt,
+		&loopID,
+		&childRunID,
+		&logPath,
+		&exitCode,
+		&outputsJSON,
+		&errorMessage,
+		&startedAt,
+		&finishedAt,
+		&createdAt,
+		&updatedAt,
+	); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrWorkflowStepRunNotFound
+		}
+		return nil, fmt.Errorf("failed to scan workflow step run: %w", err)
+	}
+
+	step := &models.WorkflowStepRun{
+		ID:         id,
+		RunID:      runID,
+		StepID:     stepID,
+		StepType:   stepType,
+		Status:     models.WorkflowStepStatus(status),
+		Attempt:    attempt,
+		LoopID:     loopID.String,
+		ChildRunID: childRunID.String,
+		LogPath:    logPath.String,
+		Error:      errorMessage.String,
+		StartedAt:  parseNullableTime(startedAt),
+		FinishedAt: parseNullableTime(finishedAt),
+	}
+	if exitCode.Valid {
+		code := int(exitCode.Int64)
+		step.ExitCode = &code
+	}
+	if outputsJSON.Valid && outputsJSON.String != "" {
+		_ = json.Unmarshal([]byte(outputsJSON.String), &step.Outputs)
+	}
+	if t, err := time.Parse(time.RFC3339, createdAt); err == nil {
+		step.CreatedAt = t
+	}
+	if t, err := time.Parse(time.RFC3339, updatedAt); err == nil {
+		step.UpdatedAt = t
+	}
+
+	return step, nil
+}
+
+func marshalJSONMap(values map[string]any) (*string, error) {
+	if values == nil {
+		return nil, nil
+	}
+	data, err := json.Marshal(values)
+	if err != nil {
+		return nil, err
+	}
+	value := string(data)
+	return &value, nil
+}
+
+func parseNullableTime(value sql.NullString) *time.Time {
+	if !value.Valid || value.String == "" {
+		return nil
+	}
+	t, err := time.Parse(time.RFC3339, value.String)
+	if err != nil {
+		return nil
+	}
+	return &t
+}
@@ -0,0 +1,81 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tOgg1/forge/internal/models"
+)
+
+func TestWorkflowRunRepository_CreateUpdateGet(t *testing.T) {
+	database := setupTestDB(t)
+	defer database.Close()
+
+	ctx := context.Background()
+	repo := NewWorkflowRunRepository(database)
+
+	run := &models.WorkflowRun{
+		WorkflowName: "basic",
+		RepoPath:     "/repo",
+		Inputs:       map[string]any{"repo": "."},
+	}
+	require.NoError(t, repo.Create(ctx, run))
+	require.NotEmpty(t, run.ID)
+	require.Equal(t, models.WorkflowRunStatusPending, run.Status)
+
+	now := time.Now().UTC()
+	run.Status = models.WorkflowRunStatusSuccess
+	run.StartedAt = &now
+	run.FinishedAt = &now
+	run.Outputs = map[string]any{"result": "ok"}
+	require.NoError(t, repo.Update(ctx, run))
+
+	stored, err := repo.Get(ctx, run.ID)
+	require.NoError(t, err)
+	require.Equal(t, models.WorkflowRunStatusSuccess, stored.Status)
+	require.Equal(t, ".", stored.Inputs["repo"])
+	require.Equal(t, "ok", stored.Outputs["result"])
+	require.NotNil(t, stored.FinishedAt)
+
+	_, err = repo.Get(ctx, "missing")
+	require.ErrorIs(t, err, ErrWorkflowRunNotFound)
+}
+
+func TestWorkflowRunRepository_Steps(t *testing.T) {
+	database := setupTestDB(t)
+	defer database.Close()
+
+	ctx := context.Background()
+	repo := NewWorkflowRunRepository(database)
+
+	run := &models.WorkflowRun{WorkflowName: "basic"}
+	require.NoError(t, repo.Create(ctx, run))
+
+	for _, id := range []string{"plan", "build"} {
+		require.NoError(t, repo.CreateStep(ctx, &models.WorkflowStepRun{RunID: run.ID, StepID: id, StepType: "bash"}))
+	}
+
+	step, err := repo.GetStep(ctx, run.ID, "build")
+	require.NoError(t, err)
+	require.Equal(t, models.WorkflowStepStatusPending, step.Status)
+
+	exitCode := 0
+	step.Status = models.WorkflowStepStatusSuccess
+	step.ExitCode = &exitCode
+	step.Outputs = map[string]any{"stdout": "done"}
+	require.NoError(t, repo.UpdateStep(ctx, step))
+
+	steps, err := repo.ListSteps(ctx, run.ID)
+	require.NoError(t, err)
+	require.Len(t, steps, 2)
+	require.Equal(t, "plan", steps[0].StepID)
+	require.Equal(t, "build", steps[1].StepID)
+	require.Equal(t, models.WorkflowStepStatusSuccess, steps[1].Status)
+	require.NotNil(t, steps[1].ExitCode)
+	require.Equal(t, "done", steps[1].Outputs["stdout"])
+
+	_, err = repo.GetStep(ctx, run.ID, "missing")
+	require.ErrorIs(t, err, ErrWorkflowStepRunNotFound)
+}
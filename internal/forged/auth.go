@@ -0,0 +1,187 @@
+package forged
+
+import (
+	"context"
+	"errors"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/tOgg1/forge/internal/vault"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// authMetadataKey is the gRPC metadata key carrying the bearer token.
+const authMetadataKey = "authorization"
+
+// Auth errors.
+var (
+	errMissingToken = errors.New("missing token")
+	errInvalidToken = errors.New("invalid token")
+	errTokenScope   = errors.New("token scope does not allow this operation")
+)
+
+// ReadScopedMethods lists the RPCs a read-only token may call. Every other
+// method requires the control scope.
+var ReadScopedMethods = map[string]bool{
+	"/forged.v1.ForgedService/ListAgents":        true,
+	"/forged.v1.ForgedService/GetAgent":          true,
+	"/forged.v1.ForgedService/CapturePane":       true,
+	"/forged.v1.ForgedService/StreamPaneUpdates": true,
+	"/forged.v1.ForgedService/StreamEvents":      true,
+	"/forged.v1.ForgedService/GetTranscript":     true,
+	"/forged.v1.ForgedService/StreamTranscript":  true,
+	"/forged.v1.ForgedService/ListLoops":         true,
+	"/forged.v1.ForgedService/StreamLoopLogs":    true,
+	"/forged.v1.ForgedService/GetStatus":         true,
+	"/forged.v1.ForgedService/Ping":              true,
+}
+
+// MethodScope returns the token scope required to call a gRPC method.
+func MethodScope(fullMethod string) vault.TokenScope {
+	if ReadScopedMethods[fullMethod] {
+		return vault.TokenScopeRead
+	}
+	return vault.TokenScopeControl
+}
+
+// Authenticator verifies daemon tokens minted with "forged token create".
+// The token file is re-read when it changes, so new and revoked tokens take
+// effect without a restart.
+type Authenticator struct {
+	vaultPath string
+
+	mu      sync.Mutex
+	tokens  []*vault.Token
+	modTime time.Time
+	size    int64
+}
+
+// NewAuthenticator creates an authenticator for tokens stored in vaultPath.
+func NewAuthenticator(vaultPath string) *Authenticator {
+	return &Authenticator{vaultPath: vaultPath}
+}
+
+// Authenticate checks that secret is a known token whose scope allows required.
+func (a *Authenticator) Authenticate(secret string, required vault.TokenScope) (*vault.Token, error) {
+	if strings.TrimSpace(secret) == "" {
+		return nil, errMissingToken
+	}
+
+	tokens, err := a.load()
+	if err != nil {
+		return nil, err
+	}
+	token, ok := vault.MatchToken(tokens, secret)
+	if !ok {
+		return nil, errInvalidToken
+	}
+	if !token.Scope.Allows(required) {
+		return token, errTokenScope
+	}
+	return token, nil
+}
+
+// load returns the current tokens, re-reading the token file when its size or
+// modification time changed.
+func (a *Authenticator) load() ([]*vault.Token, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	info, err := os.Stat(vault.TokensPath(a.vaultPath))
+	if err != nil {
+		if os.IsNotExist(err) {
+			a.tokens, a.modTime, a.size = nil, time.Time{}, 0
+			return nil, nil
+		}
+		return nil, err
+	}
+	if info.ModTime().Equal(a.modTime) && info.Size() == a.size {
+		return a.tokens, nil
+	}
+
+	tokens, err := vault.ListTokens(a.vaultPath)
+	if err != nil {
+		return nil, err
+	}
+	a.tokens, a.modTime, a.size = tokens, info.ModTime(), info.Size()
+	return tokens, nil
+}
+
+// authorize authenticates the bearer token in ctx for a gRPC method.
+func (a *Authenticator) authorize(ctx context.Context, fullMethod string) error {
+	_, err := a.Authenticate(bearerToken(ctx), MethodScope(fullMethod))
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, errTokenScope):
+		return status.Errorf(codes.PermissionDenied, "%s requires a %s token", fullMethod, MethodScope(fullMethod))
+	case errors.Is(err, errMissingToken), errors.Is(err, errInvalidToken):
+		return status.Error(codes.Unauthenticated, err.Error())
+	default:
+		return status.Errorf(codes.Internal, "failed to load tokens: %v", err)
+	}
+}
+
+// UnaryServerInterceptor returns a gRPC unary interceptor that requires a token.
+func (a *Authenticator) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(
+		ctx context.Context,
+		req interface{},
+		info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler,
+	) (interface{}, error) {
+		if err := a.authorize(ctx, info.FullMethod); err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// StreamServerInterceptor returns a gRPC stream interceptor that requires a token.
+func (a *Authenticator) StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(
+		srv interface{},
+		ss grpc.ServerStream,
+		info *grpc.StreamServerInfo,
+		handler grpc.StreamHandler,
+	) error {
+		if err := a.authorize(ss.Context(), info.FullMethod); err != nil {
+			return err
+		}
+		return handler(srv, ss)
+	}
+}
+
+// bearerToken extracts the token from "authorization: Bearer <token>" metadata.
+func bearerToken(ctx context.Context) string {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ""
+	}
+	for _, value := range md.Get(authMetadataKey) {
+		scheme, token, found := strings.Cut(strings.TrimSpace(value), " ")
+		if found && strings.EqualFold(scheme, "bearer") {
+			return strings.TrimSpace(token)
+		}
+	}
+	return ""
+}
+
+// tokenCredentials attaches a bearer token to every RPC. Tokens are also sent
+// over plaintext connections so they work through SSH tunnels.
+type tokenCredentials struct {
+	token string
+}
+
+func (c tokenCredentials) GetRequestMetadata(ctx context.Context, uri ...string) (map[string]string, error) {
+	return map[string]string{authMetadataKey: "Bearer " + c.token}, nil
+}
+
+func (c tokenCredentials) RequireTransportSecurity() bool {
+	return false
+}
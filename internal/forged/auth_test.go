@@ -0,0 +1,327 @@
+package forged
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	forgedv1 "github.com/tOgg1/forge/gen/forged/v1"
+	"github.com/tOgg1/forge/internal/fmail"
+	"github.com/tOgg1/forge/internal/vault"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/status"
+)
+
+// startAuthTestServer serves the forged service on a random local port.
+func startAuthTestServer(t *testing.T, opts ...grpc.ServerOption) string {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	grpcServer := grpc.NewServer(opts...)
+	forgedv1.RegisterForgedServiceServer(grpcServer, NewServer(zerolog.Nop()))
+	go func() {
+		_ = grpcServer.Serve(listener)
+	}()
+	t.Cleanup(grpcServer.Stop)
+	return listener.Addr().String()
+}
+
+func dialAuthTestServer(t *testing.T, addr string, opts ...ClientOption) *Client {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	client, err := Dial(ctx, addr, opts...)
+	if err != nil {
+		t.Fatalf("Dial() error = %v", err)
+	}
+	t.Cleanup(func() { _ = client.Close() })
+	return client
+}
+
+func TestAuthenticatorScopes(t *testing.T) {
+	skipIfNoNetwork(t)
+
+	vaultPath := t.TempDir()
+	readToken, _, err := vault.MintToken(vaultPath, "viewer", vault.TokenScopeRead)
+	if err != nil {
+		t.Fatalf("MintToken() error = %v", err)
+	}
+
+	auth := NewAuthenticator(vaultPath)
+	addr := startAuthTestServer(t,
+		grpc.ChainUnaryInterceptor(auth.UnaryServerInterceptor()),
+		grpc.ChainStreamInterceptor(auth.StreamServerInterceptor()),
+	)
+	ctx := context.Background()
+
+	anonymous := dialAuthTestServer(t, addr)
+	if _, err := anonymous.Ping(ctx); status.Code(err) != codes.Unauthenticated {
+		t.Fatalf("Ping() without token: code = %v, want Unauthenticated", status.Code(err))
+	}
+
+	bogus := dialAuthTestServer(t, addr, WithToken("fgd_bogus"))
+	if _, err := bogus.Ping(ctx); status.Code(err) != codes.Unauthenticated {
+		t.Fatalf("Ping() with bogus token: code = %v, want Unauthenticated", status.Code(err))
+	}
+
+	reader := dialAuthTestServer(t, addr, WithToken(readToken))
+	if _, err := reader.Ping(ctx); err != nil {
+		t.Fatalf("Ping() with read token error = %v", err)
+	}
+	if _, err := reader.KillAgent(ctx, &forgedv1.KillAgentRequest{AgentId: "agent-1", Force: true}); status.Code(err) != codes.PermissionDenied {
+		t.Fatalf("KillAgent() with read token: code = %v, want PermissionDenied", status.Code(err))
+	}
+
+	// Tokens minted while the daemon runs take effect without a restart.
+	controlToken, _, err := vault.MintToken(vaultPath, "operator", vault.TokenScopeControl)
+	if err != nil {
+		t.Fatalf("MintToken() error = %v", err)
+	}
+	operator := dialAuthTestServer(t, addr, WithToken(controlToken))
+	_, err = operator.KillAgent(ctx, &forgedv1.KillAgentRequest{AgentId: "agent-1", Force: true})
+	if code := status.Code(err); code == codes.PermissionDenied || code == codes.Unauthenticated {
+		t.Fatalf("KillAgent() with control token: code = %v, want auth to pass", code)
+	}
+
+	if err := vault.RevokeToken(vaultPath, "operator"); err != nil {
+		t.Fatalf("RevokeToken() error = %v", err)
+	}
+	if _, err := operator.Ping(ctx); status.Code(err) != codes.Unauthenticated {
+		t.Fatalf("Ping() with revoked token: code = %v, want Unauthenticated", status.Code(err))
+	}
+}
+
+func TestMethodScope(t *testing.T) {
+	known := make(map[string]bool)
+	for _, method := range forgedv1.ForgedService_ServiceDesc.Methods {
+		known["/forged.v1.ForgedService/"+method.MethodName] = true
+	}
+	for _, stream := range forgedv1.ForgedService_ServiceDesc.Streams {
+		known["/forged.v1.ForgedService/"+stream.StreamName] = true
+	}
+	for method := range ReadScopedMethods {
+		if !known[method] {
+			t.Errorf("ReadScopedMethods lists unknown method %s", method)
+		}
+	}
+	if got := MethodScope("/forged.v1.ForgedService/SpawnAgent"); got != vault.TokenScopeControl {
+		t.Errorf("SpawnAgent scope = %s, want control", got)
+	}
+	if got := MethodScope("/forged.v1.ForgedService/StreamEvents"); got != vault.TokenScopeRead {
+		t.Errorf("StreamEvents scope = %s, want read", got)
+	}
+}
+
+func TestMutualTLS(t *testing.T) {
+	skipIfNoNetwork(t)
+
+	dir := t.TempDir()
+	if err := GenerateCerts(dir, CertOptions{Hosts: []string{"forge.example"}}); err != nil {
+		t.Fatalf("GenerateCerts() error = %v", err)
+	}
+	if err := GenerateCerts(dir, CertOptions{}); err == nil {
+		t.Fatal("GenerateCerts() over existing certs should fail without Overwrite")
+	}
+
+	serverTLS, err := LoadServerTLS(dir, true)
+	if err != nil {
+		t.Fatalf("LoadServerTLS() error = %v", err)
+	}
+	addr := startAuthTestServer(t, grpc.Creds(credentials.NewTLS(serverTLS)))
+
+	clientTLS, err := LoadClientTLS(dir)
+	if err != nil {
+		t.Fatalf("LoadClientTLS() error = %v", err)
+	}
+	client := dialAuthTestServer(t, addr, WithTLS(clientTLS))
+	if _, err := client.Ping(context.Background()); err != nil {
+		t.Fatalf("Ping() over mTLS error = %v", err)
+	}
+
+	// A client that trusts the CA but has no certificate is rejected.
+	noCert := clientTLS.Clone()
+	noCert.Certificates = nil
+	anonymous := dialAuthTestServer(t, addr, WithTLS(noCert))
+	if _, err := anonymous.Ping(context.Background()); err == nil {
+		t.Fatal("Ping() without client certificate should fail")
+	}
+}
+
+func TestMailRelayHandshakeAuth(t *testing.T) {
+	skipNetworkTest(t)
+
+	root := t.TempDir()
+	projectID, err := fmail.DeriveProjectID(root)
+	if err != nil {
+		t.Fatalf("derive project id: %v", err)
+	}
+	vaultPath := t.TempDir()
+	token, _, err := vault.MintToken(vaultPath, "peer", vault.TokenScopeRead)
+	if err != nil {
+		t.Fatalf("MintToken() error = %v", err)
+	}
+	certDir := t.TempDir()
+	if err := GenerateCerts(certDir, CertOptions{}); err != nil {
+		t.Fatalf("GenerateCerts() error = %v", err)
+	}
+	serverTLS, err := LoadServerTLS(certDir, false)
+	if err != nil {
+		t.Fatalf("LoadServerTLS() error = %v", err)
+	}
+	clientTLS, err := LoadClientTLS(certDir)
+	if err != nil {
+		t.Fatalf("LoadClientTLS() error = %v", err)
+	}
+
+	server := newMailServer(zerolog.Nop())
+	server.auth = NewAuthenticator(vaultPath)
+	resolver, err := newStaticProjectResolver(root)
+	if err != nil {
+		t.Fatalf("static resolver: %v", err)
+	}
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	defer listener.Close()
+	go func() {
+		_ = server.Serve(newMailTLSListener(listener, serverTLS), resolver, true)
+	}()
+	addr := listener.Addr().String()
+
+	relay := func(conn net.Conn, token string) mailResponse {
+		t.Helper()
+		defer conn.Close()
+		if err := conn.SetDeadline(time.Now().Add(2 * time.Second)); err != nil {
+			t.Fatalf("deadline: %v", err)
+		}
+		writeLine(t, conn, mailRelayRequest{mailBaseRequest: mailBaseRequest{
+			Cmd:       "relay",
+			ProjectID: projectID,
+			Agent:     "relay-peer",
+			ReqID:     "r1",
+			Token:     token,
+		}})
+		var resp mailResponse
+		readJSONLine(t, bufio.NewReader(conn), &resp)
+		return resp
+	}
+
+	plain, err := net.Dial("tcp", addr)
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	if resp := relay(plain, ""); resp.OK || resp.Error == nil || resp.Error.Code != "unauthorized" {
+		t.Fatalf("relay without token: got %+v, want unauthorized", resp)
+	}
+
+	secure, err := tls.Dial("tcp", addr, clientTLS)
+	if err != nil {
+		t.Fatalf("tls dial: %v", err)
+	}
+	if resp := relay(secure, token); !resp.OK {
+		t.Fatalf("relay over TLS with token: got %+v, want ok", resp)
+	}
+
+	// Local fmail clients keep speaking plaintext and need no token.
+	sendConn, err := net.Dial("tcp", addr)
+	if err != nil {
+		t.Fatalf("dial send: %v", err)
+	}
+	defer sendConn.Close()
+	writeLine(t, sendConn, mailSendRequest{
+		mailBaseRequest: mailBaseRequest{Cmd: "send", ProjectID: projectID, Agent: "alice", ReqID: "s1"},
+		To:              "task",
+		Body:            []byte(`"hello"`),
+	})
+	var sendResp mailResponse
+	readJSONLine(t, bufio.NewReader(sendConn), &sendResp)
+	if !sendResp.OK {
+		t.Fatalf("plaintext send: got %+v, want ok", sendResp)
+	}
+}
+
+func TestMailTLSListenerRefusesRemotePlaintext(t *testing.T) {
+	skipNetworkTest(t)
+
+	root := t.TempDir()
+	projectID, err := fmail.DeriveProjectID(root)
+	if err != nil {
+		t.Fatalf("derive project id: %v", err)
+	}
+	certDir := t.TempDir()
+	if err := GenerateCerts(certDir, CertOptions{}); err != nil {
+		t.Fatalf("GenerateCerts() error = %v", err)
+	}
+	serverTLS, err := LoadServerTLS(certDir, true)
+	if err != nil {
+		t.Fatalf("LoadServerTLS() error = %v", err)
+	}
+
+	server := newMailServer(zerolog.Nop())
+	server.auth = NewAuthenticator(t.TempDir())
+	resolver, err := newStaticProjectResolver(root)
+	if err != nil {
+		t.Fatalf("static resolver: %v", err)
+	}
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	defer listener.Close()
+	// Pretend every client connects from another host.
+	remote := &remoteAddrListener{Listener: listener, addr: &net.TCPAddr{IP: net.ParseIP("10.0.0.5"), Port: 40000}}
+	go func() {
+		_ = server.Serve(newMailTLSListener(remote, serverTLS), resolver, true)
+	}()
+
+	conn, err := net.Dial("tcp", listener.Addr().String())
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	defer conn.Close()
+	if err := conn.SetDeadline(time.Now().Add(2 * time.Second)); err != nil {
+		t.Fatalf("deadline: %v", err)
+	}
+	writeLine(t, conn, mailRelayRequest{mailBaseRequest: mailBaseRequest{
+		Cmd:       "relay",
+		ProjectID: projectID,
+		Agent:     "relay-peer",
+		ReqID:     "r1",
+		Token:     "secret-token",
+	}})
+	if line, err := bufio.NewReader(conn).ReadString('\n'); err == nil {
+		t.Fatalf("plaintext relay from a remote address was served: %q", line)
+	}
+}
+
+// remoteAddrListener reports a fixed remote address for accepted connections.
+type remoteAddrListener struct {
+	net.Listener
+	addr net.Addr
+}
+
+func (l *remoteAddrListener) Accept() (net.Conn, error) {
+	conn, err := l.Listener.Accept()
+	if err != nil {
+		return nil, err
+	}
+	return &remoteAddrConn{Conn: conn, addr: l.addr}, nil
+}
+
+type remoteAddrConn struct {
+	net.Conn
+	addr net.Addr
+}
+
+func (c *remoteAddrConn) RemoteAddr() net.Addr {
+	return c.addr
+}
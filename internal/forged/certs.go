@@ -0,0 +1,225 @@
+package forged
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Certificate files written by GenerateCerts.
+const (
+	CACertFile     = "ca.pem"
+	CAKeyFile      = "ca-key.pem"
+	ServerCertFile = "server.pem"
+	ServerKeyFile  = "server-key.pem"
+	ClientCertFile = "client.pem"
+	ClientKeyFile  = "client-key.pem"
+)
+
+// DefaultCertValidity is how long generated certificates are valid.
+const DefaultCertValidity = 2 * 365 * 24 * time.Hour
+
+// ErrCertsExist is returned by GenerateCerts when dir already holds a CA.
+var ErrCertsExist = errors.New("certificates already exist")
+
+// CertOptions configure GenerateCerts.
+type CertOptions struct {
+	// Hosts are extra DNS names or IPs for the server certificate.
+	// localhost and 127.0.0.1 are always included.
+	Hosts []string
+
+	// ValidFor is the certificate lifetime (default: DefaultCertValidity).
+	ValidFor time.Duration
+
+	// Overwrite replaces existing certificates.
+	Overwrite bool
+}
+
+// GenerateCerts creates a CA plus a server and a client certificate signed by
+// it in dir. Copy ca.pem, client.pem, and client-key.pem to machines that
+// connect to this daemon.
+func GenerateCerts(dir string, opts CertOptions) error {
+	if strings.TrimSpace(dir) == "" {
+		return errors.New("cert directory is required")
+	}
+	if !opts.Overwrite {
+		if _, err := os.Stat(filepath.Join(dir, CACertFile)); err == nil {
+			return fmt.Errorf("%w in %s", ErrCertsExist, dir)
+		}
+	}
+	if opts.ValidFor <= 0 {
+		opts.ValidFor = DefaultCertValidity
+	}
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return fmt.Errorf("failed to create cert directory: %w", err)
+	}
+
+	now := time.Now()
+	caKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return fmt.Errorf("failed to generate CA key: %w", err)
+	}
+	caTemplate := &x509.Certificate{
+		Subject:               pkix.Name{Organization: []string{"forge"}, CommonName: "forged CA"},
+		NotBefore:             now.Add(-time.Hour),
+		NotAfter:              now.Add(opts.ValidFor),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	caTemplate.SerialNumber, err = newSerialNumber()
+	if err != nil {
+		return err
+	}
+	caDER, err := x509.CreateCertificate(rand.Reader, caTemplate, caTemplate, &caKey.PublicKey, caKey)
+	if err != nil {
+		return fmt.Errorf("failed to create CA certificate: %w", err)
+	}
+	caCert, err := x509.ParseCertificate(caDER)
+	if err != nil {
+		return err
+	}
+	if err := writeCertPair(dir, CACertFile, CAKeyFile, caDER, caKey); err != nil {
+		return err
+	}
+
+	server := &x509.Certificate{
+		Subject:     pkix.Name{Organization: []string{"forge"}, CommonName: "forged"},
+		NotBefore:   now.Add(-time.Hour),
+		NotAfter:    now.Add(opts.ValidFor),
+		KeyUsage:    x509.KeyUsageDigitalSignature,
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	for _, host := range append([]string{"localhost", "127.0.0.1", "::1"}, opts.Hosts...) {
+		host = strings.TrimSpace(host)
+		if host == "" {
+			continue
+		}
+		if ip := net.ParseIP(host); ip != nil {
+			server.IPAddresses = append(server.IPAddresses, ip)
+		} else {
+			server.DNSNames = append(server.DNSNames, host)
+		}
+	}
+	if err := signCert(dir, ServerCertFile, ServerKeyFile, server, caCert, caKey); err != nil {
+		return err
+	}
+
+	client := &x509.Certificate{
+		Subject:     pkix.Name{Organization: []string{"forge"}, CommonName: "forge client"},
+		NotBefore:   now.Add(-time.Hour),
+		NotAfter:    now.Add(opts.ValidFor),
+		KeyUsage:    x509.KeyUsageDigitalSignature,
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	return signCert(dir, ClientCertFile, ClientKeyFile, client, caCert, caKey)
+}
+
+// LoadServerTLS loads the server certificate from dir. When clientAuth is set,
+// clients must present a certificate signed by the CA in dir.
+func LoadServerTLS(dir string, clientAuth bool) (*tls.Config, error) {
+	cert, err := tls.LoadX509KeyPair(filepath.Join(dir, ServerCertFile), filepath.Join(dir, ServerKeyFile))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load server certificate: %w", err)
+	}
+	tlsConfig := &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   tls.VersionTLS12,
+	}
+	if clientAuth {
+		pool, err := loadCAPool(dir)
+		if err != nil {
+			return nil, err
+		}
+		tlsConfig.ClientCAs = pool
+		tlsConfig.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+	return tlsConfig, nil
+}
+
+// LoadClientTLS loads the CA from dir to verify forged, and the client
+// certificate when present.
+func LoadClientTLS(dir string) (*tls.Config, error) {
+	pool, err := loadCAPool(dir)
+	if err != nil {
+		return nil, err
+	}
+	tlsConfig := &tls.Config{
+		RootCAs:    pool,
+		MinVersion: tls.VersionTLS12,
+	}
+
+	certPath := filepath.Join(dir, ClientCertFile)
+	if _, err := os.Stat(certPath); err == nil {
+		cert, err := tls.LoadX509KeyPair(certPath, filepath.Join(dir, ClientKeyFile))
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+	return tlsConfig, nil
+}
+
+func loadCAPool(dir string) (*x509.CertPool, error) {
+	data, err := os.ReadFile(filepath.Join(dir, CACertFile))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CA certificate: %w", err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(data) {
+		return nil, fmt.Errorf("no certificates found in %s", filepath.Join(dir, CACertFile))
+	}
+	return pool, nil
+}
+
+func signCert(dir, certFile, keyFile string, template, ca *x509.Certificate, caKey *ecdsa.PrivateKey) error {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return fmt.Errorf("failed to generate key for %s: %w", certFile, err)
+	}
+	template.SerialNumber, err = newSerialNumber()
+	if err != nil {
+		return err
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, ca, &key.PublicKey, caKey)
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", certFile, err)
+	}
+	return writeCertPair(dir, certFile, keyFile, der, key)
+}
+
+func writeCertPair(dir, certFile, keyFile string, der []byte, key *ecdsa.PrivateKey) error {
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return fmt.Errorf("failed to marshal key for %s: %w", certFile, err)
+	}
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+	if err := os.WriteFile(filepath.Join(dir, certFile), certPEM, 0o644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", certFile, err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, keyFile), keyPEM, 0o600); err != nil {
+		return fmt.Errorf("failed to write %s: %w", keyFile, err)
+	}
+	return nil
+}
+
+func newSerialNumber() (*big.Int, error) {
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate serial number: %w", err)
+	}
+	return serial, nil
+}
@@ -3,6 +3,7 @@ package forged
 
 import (
 	"context"
+	"crypto/tls"
 	"fmt"
 	"net"
 	"sync"
@@ -11,6 +12,7 @@ import (
 	forgedv1 "github.com/tOgg1/forge/gen/forged/v1"
 	"github.com/tOgg1/forge/internal/ssh"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/credentials/insecure"
 )
 
@@ -33,6 +35,8 @@ type clientConfig struct {
 	logger     zerolog.Logger
 	dialOpts   []grpc.DialOption
 	sshOptions []ssh.NativeExecutorOption
+	token      string
+	tlsConfig  *tls.Config
 }
 
 // WithLogger sets the logger for the client.
@@ -56,6 +60,34 @@ func WithSSHOptions(opts ...ssh.NativeExecutorOption) ClientOption {
 	}
 }
 
+// WithToken authenticates every call with a token minted by "forged token create".
+func WithToken(token string) ClientOption {
+	return func(c *clientConfig) {
+		c.token = token
+	}
+}
+
+// WithTLS connects over TLS instead of plaintext (see LoadClientTLS).
+func WithTLS(tlsConfig *tls.Config) ClientOption {
+	return func(c *clientConfig) {
+		c.tlsConfig = tlsConfig
+	}
+}
+
+// grpcDialOptions returns the transport and auth dial options followed by any
+// options from WithDialOptions.
+func (c *clientConfig) grpcDialOptions() []grpc.DialOption {
+	transport := insecure.NewCredentials()
+	if c.tlsConfig != nil {
+		transport = credentials.NewTLS(c.tlsConfig)
+	}
+	opts := []grpc.DialOption{grpc.WithTransportCredentials(transport)}
+	if c.token != "" {
+		opts = append(opts, grpc.WithPerRPCCredentials(tokenCredentials{token: c.token}))
+	}
+	return append(opts, c.dialOpts...)
+}
+
 // Dial creates a direct connection to a forged daemon.
 // Use this for local connections or when the daemon is directly reachable.
 func Dial(ctx context.Context, target string, opts ...ClientOption) (*Client, error) {
@@ -66,9 +98,7 @@ func Dial(ctx context.Context, target string, opts ...ClientOption) (*Client, er
 		opt(cfg)
 	}
 
-	dialOpts := append([]grpc.DialOption{
-		grpc.WithTransportCredentials(insecure.NewCredentials()),
-	}, cfg.dialOpts...)
+	dialOpts := cfg.grpcDialOptions()
 
 	//nolint:staticcheck // grpc.DialContext remains supported across gRPC 1.x
 	conn, err := grpc.DialContext(ctx, target, dialOpts...)
@@ -140,9 +170,7 @@ func DialSSH(ctx context.Context, sshHost string, sshPort int, forgedPort int, o
 		Str("local_addr", localAddr).
 		Msg("SSH tunnel established")
 
-	dialOpts := append([]grpc.DialOption{
-		grpc.WithTransportCredentials(insecure.NewCredentials()),
-	}, cfg.dialOpts...)
+	dialOpts := cfg.grpcDialOptions()
 
 	//nolint:staticcheck // grpc.DialContext remains supported across gRPC 1.x
 	conn, err := grpc.DialContext(ctx, localAddr, dialOpts...)
@@ -200,9 +228,7 @@ func DialSSHWithExecutor(ctx context.Context, executor ssh.PortForwarder, forged
 		Int("forged_port", forgedPort).
 		Msg("SSH tunnel established via executor")
 
-	dialOpts := append([]grpc.DialOption{
-		grpc.WithTransportCredentials(insecure.NewCredentials()),
-	}, cfg.dialOpts...)
+	dialOpts := cfg.grpcDialOptions()
 
 	//nolint:staticcheck // grpc.DialContext remains supported across gRPC 1.x
 	conn, err := grpc.DialContext(ctx, localAddr, dialOpts...)
@@ -284,6 +310,41 @@ func (c *Client) StreamTranscript(ctx context.Context, req *forgedv1.StreamTrans
 	return c.svc.StreamTranscript(ctx, req)
 }
 
+// CreateLoop creates a loop on the node.
+func (c *Client) CreateLoop(ctx context.Context, req *forgedv1.CreateLoopRequest) (*forgedv1.CreateLoopResponse, error) {
+	return c.svc.CreateLoop(ctx, req)
+}
+
+// StartLoop starts a loop's runner.
+func (c *Client) StartLoop(ctx context.Context, req *forgedv1.StartLoopRequest) (*forgedv1.StartLoopResponse, error) {
+	return c.svc.StartLoop(ctx, req)
+}
+
+// StopLoop asks a loop to stop after its current iteration.
+func (c *Client) StopLoop(ctx context.Context, req *forgedv1.StopLoopRequest) (*forgedv1.StopLoopResponse, error) {
+	return c.svc.StopLoop(ctx, req)
+}
+
+// KillLoop kills a loop's runner immediately.
+func (c *Client) KillLoop(ctx context.Context, req *forgedv1.KillLoopRequest) (*forgedv1.KillLoopResponse, error) {
+	return c.svc.KillLoop(ctx, req)
+}
+
+// ListLoops returns the node's loops.
+func (c *Client) ListLoops(ctx context.Context, req *forgedv1.ListLoopsRequest) (*forgedv1.ListLoopsResponse, error) {
+	return c.svc.ListLoops(ctx, req)
+}
+
+// EnqueueLoopItems appends items to a loop's queue.
+func (c *Client) EnqueueLoopItems(ctx context.Context, req *forgedv1.EnqueueLoopItemsRequest) (*forgedv1.EnqueueLoopItemsResponse, error) {
+	return c.svc.EnqueueLoopItems(ctx, req)
+}
+
+// StreamLoopLogs streams a loop's log lines.
+func (c *Client) StreamLoopLogs(ctx context.Context, req *forgedv1.StreamLoopLogsRequest) (forgedv1.ForgedService_StreamLoopLogsClient, error) {
+	return c.svc.StreamLoopLogs(ctx, req)
+}
+
 // LocalAddr returns the local address of the connection.
 // For SSH tunnel connections, this is the local tunnel endpoint.
 func (c *Client) LocalAddr() string {
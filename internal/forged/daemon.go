@@ -3,9 +3,11 @@ package forged
 
 import (
 	"context"
+	"crypto/tls"
 	"errors"
 	"fmt"
 	"net"
+	"net/http"
 	"path/filepath"
 	"strconv"
 	"time"
@@ -15,11 +17,13 @@ import (
 	"github.com/tOgg1/forge/internal/adapters"
 	"github.com/tOgg1/forge/internal/config"
 	"github.com/tOgg1/forge/internal/db"
+	"github.com/tOgg1/forge/internal/hooks"
 	"github.com/tOgg1/forge/internal/models"
 	"github.com/tOgg1/forge/internal/queue"
 	"github.com/tOgg1/forge/internal/state"
 	"github.com/tOgg1/forge/internal/tmux"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
 )
 
 // Options configure the daemon runtime.
@@ -47,7 +51,18 @@ type Options struct {
 	DiskMonitorConfig *DiskMonitorConfig
 
 	// DisableDatabase skips database initialization (for testing).
+	// Loop supervision requires the database.
 	DisableDatabase bool
+
+	// LoopCommand is the forge binary loops are run with (default: "forge").
+	LoopCommand string
+
+	// ConfigFile is passed to loop processes as --config.
+	ConfigFile string
+
+	// MetricsAddr is the address to serve Prometheus metrics on
+	// (e.g., "127.0.0.1:9464"). Empty disables the endpoint.
+	MetricsAddr string
 }
 
 // SchedulerRunner provides lifecycle management for an external scheduler.
@@ -73,6 +88,12 @@ type Daemon struct {
 	mailServer      *mailServer
 	mailListeners   []mailListener
 	mailRelay       *mailRelayManager
+	loopSupervisor  *LoopSupervisor
+	hookManager     *hooks.Manager
+	authenticator   *Authenticator
+	serverTLS       *tls.Config
+	metrics         *Metrics
+	metricsServer   *http.Server
 
 	// Database and repositories
 	database  *db.DB
@@ -107,6 +128,24 @@ func New(cfg *config.Config, logger zerolog.Logger, opts Options) (*Daemon, erro
 		opts.Port = DefaultPort
 	}
 
+	// Load auth and TLS settings before opening anything that needs cleanup
+	var authenticator *Authenticator
+	if cfg.Forged.Auth.Enabled {
+		authenticator = NewAuthenticator(cfg.TokenVaultPath())
+	}
+	var serverTLS, relayTLS *tls.Config
+	if cfg.Forged.TLS.Enabled {
+		var err error
+		serverTLS, err = LoadServerTLS(cfg.CertDir(), cfg.Forged.TLS.ClientAuth)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load TLS config (run forged cert init): %w", err)
+		}
+		relayTLS, err = LoadClientTLS(cfg.CertDir())
+		if err != nil {
+			return nil, fmt.Errorf("failed to load relay TLS config: %w", err)
+		}
+	}
+
 	// Initialize database and repositories (unless disabled for testing)
 	var (
 		database  *db.DB
@@ -211,6 +250,7 @@ func New(cfg *config.Config, logger zerolog.Logger, opts Options) (*Daemon, erro
 	// Create the gRPC service implementation
 	server := NewServer(logger, WithVersion(opts.Version))
 	mailServer := newMailServer(logger)
+	mailServer.auth = authenticator
 	var mailRelay *mailRelayManager
 	if cfg.Mail.Relay.Enabled && len(cfg.Mail.Relay.Peers) > 0 {
 		mailRelay = newMailRelayManager(
@@ -221,8 +261,12 @@ func New(cfg *config.Config, logger zerolog.Logger, opts Options) (*Daemon, erro
 			cfg.Mail.Relay.DialTimeout,
 			cfg.Mail.Relay.ReconnectInterval,
 		)
+		mailRelay.token = cfg.Mail.Relay.Token
+		mailRelay.tlsConfig = relayTLS
 	}
 
+	metrics := newMetrics(logger, server, database, mailServer, mailRelay, statePoller)
+
 	// Create rate limiter with options
 	var rlOpts []RateLimiterOption
 	if opts.CustomRateLimits != nil {
@@ -236,11 +280,27 @@ func New(cfg *config.Config, logger zerolog.Logger, opts Options) (*Daemon, erro
 	}
 	rateLimiter := NewRateLimiter(rlOpts...)
 
-	// Create the gRPC server with rate limiting interceptors
-	grpcServer := grpc.NewServer(
-		grpc.ChainUnaryInterceptor(rateLimiter.UnaryServerInterceptor()),
-		grpc.ChainStreamInterceptor(rateLimiter.StreamServerInterceptor()),
+	// Create the gRPC server with auth and rate limiting interceptors.
+	// Auth runs first so unauthenticated callers cannot drain rate limits.
+	var (
+		unaryInterceptors  []grpc.UnaryServerInterceptor
+		streamInterceptors []grpc.StreamServerInterceptor
 	)
+	if authenticator != nil {
+		unaryInterceptors = append(unaryInterceptors, authenticator.UnaryServerInterceptor())
+		streamInterceptors = append(streamInterceptors, authenticator.StreamServerInterceptor())
+	}
+	unaryInterceptors = append(unaryInterceptors, rateLimiter.UnaryServerInterceptor())
+	streamInterceptors = append(streamInterceptors, rateLimiter.StreamServerInterceptor())
+
+	serverOpts := []grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(unaryInterceptors...),
+		grpc.ChainStreamInterceptor(streamInterceptors...),
+	}
+	if serverTLS != nil {
+		serverOpts = append(serverOpts, grpc.Creds(credentials.NewTLS(serverTLS)))
+	}
+	grpcServer := grpc.NewServer(serverOpts...)
 	forgedv1.RegisterForgedServiceServer(grpcServer, server)
 
 	// Store rate limiter reference in server for status reporting
@@ -250,6 +310,12 @@ func New(cfg *config.Config, logger zerolog.Logger, opts Options) (*Daemon, erro
 		Bool("rate_limiting_enabled", rateLimiter.IsEnabled()).
 		Msg("rate limiter configured")
 
+	logger.Info().
+		Bool("auth_enabled", authenticator != nil).
+		Bool("tls_enabled", serverTLS != nil).
+		Bool("client_auth", cfg.Forged.TLS.ClientAuth).
+		Msg("transport security configured")
+
 	// Create resource monitor if enabled (default: enabled)
 	var resourceMonitor *ResourceMonitor
 	resourceMonitorEnabled := opts.ResourceMonitorEnabled == nil || *opts.ResourceMonitorEnabled
@@ -258,6 +324,7 @@ func New(cfg *config.Config, logger zerolog.Logger, opts Options) (*Daemon, erro
 			WithViolationCallback(func(v ResourceViolation) {
 				// Publish resource violation event
 				server.publishResourceViolation(v)
+				metrics.RecordViolation(v)
 			}),
 			WithKillCallback(func(agentID, reason string) {
 				// Kill the agent via the server
@@ -290,6 +357,26 @@ func New(cfg *config.Config, logger zerolog.Logger, opts Options) (*Daemon, erro
 			Msg("resource monitor configured")
 	}
 
+	// Create loop supervisor (requires the database)
+	var loopSupervisor *LoopSupervisor
+	if database != nil {
+		loopSupervisor = NewLoopSupervisor(logger, server, database, cfg,
+			WithLoopCommand(opts.LoopCommand),
+			WithLoopConfigFile(opts.ConfigFile),
+		)
+		server.SetLoopSupervisor(loopSupervisor)
+	}
+
+	// Retry failed hook deliveries queued by forge commands and loop runners
+	var hookManager *hooks.Manager
+	if database != nil {
+		hookManager = hooks.NewManager(
+			hooks.NewStore(hooks.StorePath(cfg.Global.ConfigDir)),
+			hooks.NewExecutor(hooks.WithVaultPath(cfg.TokenVaultPath())),
+			hooks.WithDeliveryRepository(db.NewHookDeliveryRepository(database)),
+		)
+	}
+
 	return &Daemon{
 		cfg:             cfg,
 		logger:          logger,
@@ -301,6 +388,11 @@ func New(cfg *config.Config, logger zerolog.Logger, opts Options) (*Daemon, erro
 		mailServer:      mailServer,
 		database:        database,
 		mailRelay:       mailRelay,
+		loopSupervisor:  loopSupervisor,
+		hookManager:     hookManager,
+		authenticator:   authenticator,
+		serverTLS:       serverTLS,
+		metrics:         metrics,
 		agentRepo:       agentRepo,
 		queueRepo:       queueRepo,
 		wsRepo:          wsRepo,
@@ -333,6 +425,12 @@ func (d *Daemon) Run(ctx context.Context) error {
 		Str("version", d.opts.Version).
 		Msg("forged gRPC server starting")
 
+	if d.authenticator == nil && !isLoopbackHost(d.opts.Hostname) {
+		d.logger.Warn().
+			Str("bind", bindAddr).
+			Msg("forged is reachable beyond localhost without auth; set forged.auth.enabled")
+	}
+
 	// Start resource monitor if configured
 	if d.resourceMonitor != nil {
 		d.resourceMonitor.Start(ctx)
@@ -347,6 +445,16 @@ func (d *Daemon) Run(ctx context.Context) error {
 		d.logger.Info().Msg("state poller started")
 	}
 
+	// Start loop supervisor if available
+	if d.loopSupervisor != nil {
+		d.loopSupervisor.Start(ctx)
+	}
+
+	// Start hook delivery retries if available
+	if d.hookManager != nil {
+		d.hookManager.Start(ctx, hooks.DefaultRetryInterval)
+	}
+
 	// Start scheduler if registered
 	if d.scheduler != nil {
 		if err := d.scheduler.Start(ctx); err != nil {
@@ -378,6 +486,10 @@ func (d *Daemon) Run(ctx context.Context) error {
 		d.shutdown()
 		return err
 	}
+	if err := d.startMetricsServer(errCh); err != nil {
+		d.shutdown()
+		return err
+	}
 
 	// Wait for shutdown signal or error
 	select {
@@ -396,7 +508,7 @@ func (d *Daemon) Run(ctx context.Context) error {
 }
 
 // shutdown performs ordered cleanup of all daemon components.
-// Shutdown order: scheduler -> event watcher -> state poller -> gRPC server -> mail servers -> resource monitor -> database
+// Shutdown order: scheduler -> event watcher -> state poller -> gRPC server -> mail servers -> metrics server -> loop supervisor -> hook retries -> resource monitor -> database
 func (d *Daemon) shutdown() {
 	// 1. Stop scheduler first (waits for in-progress dispatches)
 	if d.scheduler != nil {
@@ -438,14 +550,39 @@ func (d *Daemon) shutdown() {
 	d.shutdownMailServers()
 	d.logger.Debug().Msg("mail servers stopped")
 
-	// 6. Stop resource monitor
+	// 6. Stop metrics server
+	if d.metricsServer != nil {
+		d.logger.Debug().Msg("stopping metrics server...")
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		if err := d.metricsServer.Shutdown(ctx); err != nil {
+			d.logger.Warn().Err(err).Msg("metrics server shutdown returned error")
+		}
+		cancel()
+		d.logger.Debug().Msg("metrics server stopped")
+	}
+
+	// 7. Stop loop supervisor (loop processes keep running)
+	if d.loopSupervisor != nil {
+		d.logger.Debug().Msg("stopping loop supervisor...")
+		d.loopSupervisor.Stop()
+		d.logger.Debug().Msg("loop supervisor stopped")
+	}
+
+	// 8. Stop hook delivery retries
+	if d.hookManager != nil {
+		d.logger.Debug().Msg("stopping hook retries...")
+		d.hookManager.Stop()
+		d.logger.Debug().Msg("hook retries stopped")
+	}
+
+	// 9. Stop resource monitor
 	if d.resourceMonitor != nil {
 		d.logger.Debug().Msg("stopping resource monitor...")
 		d.resourceMonitor.Stop()
 		d.logger.Debug().Msg("resource monitor stopped")
 	}
 
-	// 7. Close database
+	// 10. Close database
 	if d.database != nil {
 		d.logger.Debug().Msg("closing database...")
 		if err := d.database.Close(); err != nil {
@@ -455,10 +592,52 @@ func (d *Daemon) shutdown() {
 	}
 }
 
+// startMetricsServer serves /metrics when a metrics address is configured.
+func (d *Daemon) startMetricsServer(errCh chan<- error) error {
+	if d.opts.MetricsAddr == "" {
+		return nil
+	}
+
+	listener, err := net.Listen("tcp", d.opts.MetricsAddr)
+	if err != nil {
+		return fmt.Errorf("metrics listen: %w", err)
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle(MetricsPath, d.metrics)
+	d.metricsServer = &http.Server{
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	go func() {
+		if err := d.metricsServer.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			errCh <- fmt.Errorf("metrics server: %w", err)
+		}
+	}()
+
+	d.logger.Info().Str("bind", listener.Addr().String()).Msg("forged metrics server listening")
+	return nil
+}
+
 func (d *Daemon) bindAddr() string {
 	return net.JoinHostPort(d.opts.Hostname, strconv.Itoa(d.opts.Port))
 }
 
+// isLoopbackHost reports whether host only accepts local connections.
+func isLoopbackHost(host string) bool {
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// Metrics returns the daemon's metrics registry, used to add collectors for
+// components created outside this package.
+func (d *Daemon) Metrics() *Metrics {
+	return d.metrics
+}
+
 // Server returns the underlying gRPC service implementation.
 // Useful for testing.
 func (d *Daemon) Server() *Server {
@@ -503,6 +682,11 @@ func (d *Daemon) WorkspaceRepository() *db.WorkspaceRepository {
 	return d.wsRepo
 }
 
+// LoopSupervisor returns the loop supervisor (nil without a database).
+func (d *Daemon) LoopSupervisor() *LoopSupervisor {
+	return d.loopSupervisor
+}
+
 // EventRepository returns the event repository.
 func (d *Daemon) EventRepository() *db.EventRepository {
 	return d.eventRepo
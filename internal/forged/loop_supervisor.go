@@ -0,0 +1,325 @@
+package forged
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/rs/zerolog"
+	forgedv1 "github.com/tOgg1/forge/gen/forged/v1"
+	"github.com/tOgg1/forge/internal/config"
+	"github.com/tOgg1/forge/internal/db"
+	"github.com/tOgg1/forge/internal/models"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// DefaultLoopCommand is the forge binary used to run supervised loops.
+const DefaultLoopCommand = "forge"
+
+// LoopReapFunc checks every loop for a dead runner once. It is implemented by
+// loop.Reaper and registered via SetReaper to avoid an import cycle.
+type LoopReapFunc func(ctx context.Context) error
+
+// LoopSupervisor runs loops on this node. Each loop runs in its own
+// "forge loop run <id>" child process; the supervisor reaps loops whose runner
+// died and relays loop.run.finished events to StreamEvents subscribers.
+type LoopSupervisor struct {
+	logger     zerolog.Logger
+	server     *Server
+	db         *db.DB
+	cfg        *config.Config
+	command    string
+	configFile string
+	interval   time.Duration
+	reap       LoopReapFunc
+
+	mu    sync.Mutex
+	procs map[string]*exec.Cmd // running loop processes keyed by loop ID
+
+	// Control
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// LoopSupervisorOption configures the LoopSupervisor.
+type LoopSupervisorOption func(*LoopSupervisor)
+
+// WithLoopCommand sets the forge binary used to run loops.
+func WithLoopCommand(command string) LoopSupervisorOption {
+	return func(ls *LoopSupervisor) {
+		if command != "" {
+			ls.command = command
+		}
+	}
+}
+
+// WithLoopConfigFile passes --config to loop processes.
+func WithLoopConfigFile(path string) LoopSupervisorOption {
+	return func(ls *LoopSupervisor) {
+		ls.configFile = path
+	}
+}
+
+// WithLoopPollInterval sets how often loop events are relayed.
+func WithLoopPollInterval(d time.Duration) LoopSupervisorOption {
+	return func(ls *LoopSupervisor) {
+		if d > 0 {
+			ls.interval = d
+		}
+	}
+}
+
+// NewLoopSupervisor creates a loop supervisor backed by database.
+func NewLoopSupervisor(logger zerolog.Logger, server *Server, database *db.DB, cfg *config.Config, opts ...LoopSupervisorOption) *LoopSupervisor {
+	ls := &LoopSupervisor{
+		logger:   logger,
+		server:   server,
+		db:       database,
+		cfg:      cfg,
+		command:  DefaultLoopCommand,
+		interval: time.Second,
+		procs:    make(map[string]*exec.Cmd),
+	}
+
+	for _, opt := range opts {
+		opt(ls)
+	}
+
+	return ls
+}
+
+// SetReaper registers the orphaned loop reaper. It must be called before Start.
+func (ls *LoopSupervisor) SetReaper(reap LoopReapFunc) {
+	ls.reap = reap
+}
+
+// Start begins reaping orphaned loops and relaying loop events.
+func (ls *LoopSupervisor) Start(ctx context.Context) {
+	ctx, ls.cancel = context.WithCancel(ctx)
+
+	ls.wg.Add(1)
+	go func() {
+		defer ls.wg.Done()
+		ls.superviseLoop(ctx)
+	}()
+
+	ls.logger.Info().
+		Str("command", ls.command).
+		Dur("interval", ls.interval).
+		Msg("loop supervisor started")
+}
+
+// Stop halts the supervisor. Loop processes keep running; a restarted daemon
+// picks them up through their heartbeats.
+func (ls *LoopSupervisor) Stop() {
+	if ls.cancel != nil {
+		ls.cancel()
+	}
+	ls.wg.Wait()
+	ls.logger.Info().Msg("loop supervisor stopped")
+}
+
+// StartLoop launches a runner process for a loop. It matches loop.RestartFunc.
+func (ls *LoopSupervisor) StartLoop(loopID string) error {
+	ls.mu.Lock()
+	defer ls.mu.Unlock()
+
+	if _, running := ls.procs[loopID]; running {
+		return nil
+	}
+
+	args := make([]string, 0, 5)
+	if ls.configFile != "" {
+		args = append(args, "--config", ls.configFile)
+	}
+	args = append(args, "loop", "run", loopID)
+
+	cmd := exec.Command(ls.command, args...)
+	cmd.Stdout = nil
+	cmd.Stderr = nil
+
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("failed to start loop process: %w", err)
+	}
+	ls.procs[loopID] = cmd
+
+	ls.logger.Info().
+		Str("loop_id", loopID).
+		Int("pid", cmd.Process.Pid).
+		Msg("loop process started")
+
+	go func() {
+		err := cmd.Wait()
+		ls.mu.Lock()
+		delete(ls.procs, loopID)
+		ls.mu.Unlock()
+
+		event := ls.logger.Debug()
+		if err != nil {
+			event = ls.logger.Warn().Err(err)
+		}
+		event.Str("loop_id", loopID).Msg("loop process exited")
+	}()
+	return nil
+}
+
+// KillLoop terminates a loop's runner process, whether it was started by this
+// supervisor or recorded in the loop's metadata.
+func (ls *LoopSupervisor) KillLoop(loopEntry *models.Loop) error {
+	ls.mu.Lock()
+	cmd, ok := ls.procs[loopEntry.ID]
+	ls.mu.Unlock()
+	if ok {
+		return cmd.Process.Kill()
+	}
+
+	pid, ok := loopPID(loopEntry)
+	if !ok {
+		return nil
+	}
+	process, err := os.FindProcess(pid)
+	if err != nil {
+		return err
+	}
+	if err := process.Kill(); err != nil && !errors.Is(err, os.ErrProcessDone) {
+		return err
+	}
+	return nil
+}
+
+// Supervised reports whether this supervisor started the loop's runner.
+func (ls *LoopSupervisor) Supervised(loopID string) bool {
+	ls.mu.Lock()
+	defer ls.mu.Unlock()
+	_, ok := ls.procs[loopID]
+	return ok
+}
+
+func (ls *LoopSupervisor) superviseLoop(ctx context.Context) {
+	reapInterval := ls.cfg.LoopDefaults.HeartbeatInterval
+	if reapInterval <= 0 {
+		reapInterval = 30 * time.Second
+	}
+
+	relayTicker := time.NewTicker(ls.interval)
+	defer relayTicker.Stop()
+	reapTicker := time.NewTicker(reapInterval)
+	defer reapTicker.Stop()
+
+	since := time.Now().UTC()
+	cursor := ""
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-reapTicker.C:
+			if ls.reap == nil {
+				continue
+			}
+			if err := ls.reap(ctx); err != nil && ctx.Err() == nil {
+				ls.logger.Warn().Err(err).Msg("loop reap failed")
+			}
+		case <-relayTicker.C:
+			next, err := ls.relayEvents(ctx, since, cursor)
+			if err != nil {
+				if ctx.Err() == nil {
+					ls.logger.Warn().Err(err).Msg("loop event relay failed")
+				}
+				continue
+			}
+			cursor = next
+		}
+	}
+}
+
+// relayEvents publishes loop.run.finished events recorded after cursor and
+// returns the new cursor.
+func (ls *LoopSupervisor) relayEvents(ctx context.Context, since time.Time, cursor string) (string, error) {
+	eventType := models.EventTypeLoopRunFinished
+	repo := db.NewEventRepository(ls.db)
+	for {
+		page, err := repo.Query(ctx, db.EventQuery{Type: &eventType, Since: &since, Cursor: cursor})
+		if err != nil {
+			return cursor, err
+		}
+		for _, event := range page.Events {
+			ls.server.publishLoopRunFinished(event)
+			cursor = event.ID
+		}
+		if page.NextCursor == "" {
+			return cursor, nil
+		}
+	}
+}
+
+// publishLoopRunFinished publishes a loop run finished event.
+func (s *Server) publishLoopRunFinished(event *models.Event) {
+	var payload models.LoopRunFinishedPayload
+	if err := json.Unmarshal(event.Payload, &payload); err != nil {
+		s.logger.Warn().Err(err).Str("event_id", event.ID).Msg("invalid loop.run.finished payload")
+		return
+	}
+
+	finished := &forgedv1.LoopRunFinishedEvent{
+		LoopName:  payload.LoopName,
+		RunId:     payload.RunID,
+		Status:    string(payload.Status),
+		Duration:  durationpb.New(time.Duration(payload.DurationMs) * time.Millisecond),
+		Iteration: int32(payload.Iteration),
+		Error:     payload.Error,
+	}
+	if payload.ExitCode != nil {
+		finished.ExitCode = int32(*payload.ExitCode)
+	}
+
+	s.publishEvent(&forgedv1.Event{
+		Type:      forgedv1.EventType_EVENT_TYPE_LOOP_RUN_FINISHED,
+		Timestamp: timestamppb.New(event.Timestamp),
+		LoopId:    event.EntityID,
+		Payload: &forgedv1.Event_LoopRunFinished{
+			LoopRunFinished: finished,
+		},
+	})
+}
+
+// loopPID returns the runner pid recorded in loop metadata.
+func loopPID(loopEntry *models.Loop) (int, bool) {
+	if loopEntry == nil || loopEntry.Metadata == nil {
+		return 0, false
+	}
+	switch v := loopEntry.Metadata["pid"].(type) {
+	case float64:
+		return int(v), true
+	case int:
+		return v, true
+	case int64:
+		return int(v), true
+	case string:
+		parsed, err := strconv.Atoi(v)
+		if err != nil {
+			return 0, false
+		}
+		return parsed, true
+	default:
+		return 0, false
+	}
+}
+
+func processAlive(pid int) bool {
+	if pid <= 0 {
+		return false
+	}
+	process, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+	return process.Signal(syscall.Signal(0)) == nil
+}
@@ -30,6 +30,10 @@ func (d *Daemon) startMailServers(errCh chan<- error) error {
 		return fmt.Errorf("mail tcp listen: %w", err)
 	}
 	d.mailListeners = append(d.mailListeners, mailListener{listener: listener})
+	if d.serverTLS != nil {
+		// Relay peers connect over TLS; plaintext is only accepted from loopback.
+		listener = newMailTLSListener(listener, d.serverTLS)
+	}
 
 	go func() {
 		if err := d.mailServer.Serve(listener, resolver, true); err != nil {
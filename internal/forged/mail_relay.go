@@ -3,10 +3,12 @@ package forged
 import (
 	"bufio"
 	"context"
+	"crypto/tls"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"net"
+	"sort"
 	"strings"
 	"sync"
 	"time"
@@ -30,8 +32,13 @@ type mailRelayManager struct {
 	dialTimeout       time.Duration
 	reconnectInterval time.Duration
 
+	// token is sent on relay handshakes; tlsConfig, when set, secures TCP peers.
+	token     string
+	tlsConfig *tls.Config
+
 	mu       sync.Mutex
 	lastSeen map[string]string
+	status   map[string]*mailRelayPeerStatus
 	cancel   context.CancelFunc
 	wg       sync.WaitGroup
 }
@@ -52,9 +59,52 @@ func newMailRelayManager(logger zerolog.Logger, server *mailServer, host string,
 		dialTimeout:       dialTimeout,
 		reconnectInterval: reconnectInterval,
 		lastSeen:          make(map[string]string),
+		status:            make(map[string]*mailRelayPeerStatus),
 	}
 }
 
+// mailRelayPeerStatus tracks one peer/project relay stream for metrics.
+type mailRelayPeerStatus struct {
+	Peer      string
+	Project   string
+	Connected bool
+	Relayed   int64         // messages ingested from the peer
+	Lag       time.Duration // age of the last message when it arrived
+}
+
+// peerStatuses returns a snapshot of every relay stream, sorted by peer and project.
+func (m *mailRelayManager) peerStatuses() []mailRelayPeerStatus {
+	if m == nil {
+		return nil
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	statuses := make([]mailRelayPeerStatus, 0, len(m.status))
+	for _, status := range m.status {
+		statuses = append(statuses, *status)
+	}
+	sort.Slice(statuses, func(i, j int) bool {
+		if statuses[i].Peer != statuses[j].Peer {
+			return statuses[i].Peer < statuses[j].Peer
+		}
+		return statuses[i].Project < statuses[j].Project
+	})
+	return statuses
+}
+
+// updateStatus applies fn to the status of a peer/project stream.
+func (m *mailRelayManager) updateStatus(peer mailRelayPeer, projectID string, fn func(*mailRelayPeerStatus)) {
+	key := relayLastSeenKey(peer, projectID)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	status, ok := m.status[key]
+	if !ok {
+		status = &mailRelayPeerStatus{Peer: peer.raw, Project: projectID}
+		m.status[key] = status
+	}
+	fn(status)
+}
+
 func (m *mailRelayManager) Start(ctx context.Context, projects []mailProject) error {
 	if m == nil || m.server == nil {
 		return nil
@@ -107,7 +157,7 @@ func (m *mailRelayManager) runPeer(ctx context.Context, peer mailRelayPeer, proj
 		default:
 		}
 
-		conn, err := net.DialTimeout(peer.network, peer.addr, m.dialTimeout)
+		conn, err := m.dial(peer)
 		if err != nil {
 			m.logger.Warn().Err(err).Str("peer", peer.raw).Msg("mail relay dial failed")
 			if !sleepUntil(ctx, m.reconnectInterval) {
@@ -119,6 +169,7 @@ func (m *mailRelayManager) runPeer(ctx context.Context, peer mailRelayPeer, proj
 		m.logger.Info().Str("peer", peer.raw).Str("project", project.ID).Msg("mail relay connected")
 		err = m.relayProject(ctx, conn, peer, project)
 		_ = conn.Close()
+		m.updateStatus(peer, project.ID, func(status *mailRelayPeerStatus) { status.Connected = false })
 
 		if err != nil && !errors.Is(err, context.Canceled) {
 			m.logger.Warn().Err(err).Str("peer", peer.raw).Str("project", project.ID).Msg("mail relay disconnected")
@@ -149,6 +200,7 @@ func (m *mailRelayManager) relayProject(ctx context.Context, conn net.Conn, peer
 			Agent:     m.agent,
 			Host:      m.host,
 			ReqID:     fmt.Sprintf("relay-%d", time.Now().UTC().UnixNano()),
+			Token:     m.token,
 		},
 		Since: m.lastSeenID(peer, project.ID),
 	}
@@ -167,6 +219,7 @@ func (m *mailRelayManager) relayProject(ctx context.Context, conn net.Conn, peer
 	if !resp.OK {
 		return fmt.Errorf("relay ack failed: %s", formatRelayError(resp.Error))
 	}
+	m.updateStatus(peer, project.ID, func(status *mailRelayPeerStatus) { status.Connected = true })
 
 	for {
 		select {
@@ -194,25 +247,50 @@ func (m *mailRelayManager) relayProject(ctx context.Context, conn net.Conn, peer
 			m.updateLastSeen(peer, project.ID, env.Msg.ID)
 		}
 
-		if err := m.applyMessage(project, env.Msg); err != nil {
+		saved, err := m.applyMessage(project, env.Msg)
+		if err != nil {
 			m.logger.Warn().Err(err).Str("peer", peer.raw).Str("project", project.ID).Msg("mail relay apply failed")
+			continue
+		}
+		lag := time.Since(env.Msg.Time)
+		m.updateStatus(peer, project.ID, func(status *mailRelayPeerStatus) {
+			if saved {
+				status.Relayed++
+			}
+			if !env.Msg.Time.IsZero() && lag > 0 {
+				status.Lag = lag
+			}
+		})
+	}
+}
+
+func (m *mailRelayManager) dial(peer mailRelayPeer) (net.Conn, error) {
+	if m.tlsConfig == nil || peer.network != "tcp" {
+		return net.DialTimeout(peer.network, peer.addr, m.dialTimeout)
+	}
+	tlsConfig := m.tlsConfig.Clone()
+	if tlsConfig.ServerName == "" {
+		if host, _, err := net.SplitHostPort(peer.addr); err == nil {
+			tlsConfig.ServerName = host
 		}
 	}
+	dialer := &net.Dialer{Timeout: m.dialTimeout}
+	return tls.DialWithDialer(dialer, peer.network, peer.addr, tlsConfig)
 }
 
-func (m *mailRelayManager) applyMessage(project mailProject, message *fmail.Message) error {
+// applyMessage stores a relayed message and reports whether it was new.
+func (m *mailRelayManager) applyMessage(project mailProject, message *fmail.Message) (bool, error) {
 	if message == nil {
-		return errors.New("message is nil")
+		return false, errors.New("message is nil")
 	}
 	if m.server == nil {
-		return errors.New("mail server is nil")
+		return false, errors.New("mail server is nil")
 	}
 	hub, err := m.server.getHub(project)
 	if err != nil {
-		return err
+		return false, err
 	}
-	_, err = hub.ingestMessage(message)
-	return err
+	return hub.ingestMessage(message)
 }
 
 func (m *mailRelayManager) lastSeenID(peer mailRelayPeer, projectID string) string {
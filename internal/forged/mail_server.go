@@ -19,6 +19,7 @@ import (
 
 	"github.com/rs/zerolog"
 	"github.com/tOgg1/forge/internal/fmail"
+	"github.com/tOgg1/forge/internal/vault"
 )
 
 const (
@@ -36,6 +37,12 @@ type mailServer struct {
 	logger zerolog.Logger
 	host   string
 
+	// auth, when set, requires a read token on relay handshakes.
+	auth *Authenticator
+
+	// sent counts messages accepted from local clients.
+	sent atomic.Int64
+
 	mu   sync.Mutex
 	hubs map[string]*mailHub
 }
@@ -90,6 +97,14 @@ func (s *mailServer) handleConn(conn net.Conn, resolver mailProjectResolver, req
 		return
 	}
 
+	if cmd == "relay" && s.auth != nil {
+		if _, err := s.auth.Authenticate(base.Token, vault.TokenScopeRead); err != nil {
+			s.logger.Warn().Err(err).Str("remote", conn.RemoteAddr().String()).Msg("mail relay handshake rejected")
+			_ = writeMailError(conn, base.ReqID, "unauthorized", err.Error())
+			return
+		}
+	}
+
 	projectID := strings.TrimSpace(base.ProjectID)
 	if requireProjectID && projectID == "" {
 		_ = writeMailError(conn, base.ReqID, "invalid_request", "missing project_id")
@@ -190,6 +205,7 @@ func (s *mailServer) handleSend(conn net.Conn, line []byte, base mailBaseRequest
 		return
 	}
 
+	s.sent.Add(1)
 	hub.broadcast(message)
 	_ = writeMailResponse(conn, mailResponse{OK: true, ID: message.ID, ReqID: base.ReqID})
 }
@@ -413,6 +429,24 @@ func (s *mailServer) getHub(project mailProject) (*mailHub, error) {
 	return hub, nil
 }
 
+// subscriberCounts returns the number of watch and relay subscribers per project.
+func (s *mailServer) subscriberCounts() map[string]int {
+	s.mu.Lock()
+	hubs := make([]*mailHub, 0, len(s.hubs))
+	for _, hub := range s.hubs {
+		hubs = append(hubs, hub)
+	}
+	s.mu.Unlock()
+
+	counts := make(map[string]int, len(hubs))
+	for _, hub := range hubs {
+		hub.mu.RLock()
+		counts[hub.project.ID] += len(hub.subs)
+		hub.mu.RUnlock()
+	}
+	return counts
+}
+
 type mailHub struct {
 	project mailProject
 	store   *fmail.Store
@@ -694,6 +728,7 @@ type mailBaseRequest struct {
 	Agent     string `json:"agent"`
 	Host      string `json:"host,omitempty"`
 	ReqID     string `json:"req_id,omitempty"`
+	Token     string `json:"token,omitempty"`
 }
 
 type mailSendRequest struct {
@@ -0,0 +1,102 @@
+package forged
+
+import (
+	"bufio"
+	"crypto/tls"
+	"errors"
+	"net"
+	"sync"
+)
+
+// tlsRecordHandshake is the first byte of a TLS ClientHello record.
+const tlsRecordHandshake = 0x16
+
+// errMailPlaintextRefused is returned for plaintext mail connections from a
+// non-loopback address when TLS is enabled.
+var errMailPlaintextRefused = errors.New("mail: plaintext connections are only accepted from loopback when TLS is enabled")
+
+// mailTLSListener accepts TLS connections, and plaintext connections from
+// loopback only. Remote relay peers connect over TLS while local fmail clients
+// keep using plain JSON lines; the first byte a client sends tells them apart.
+// A remote peer cannot skip TLS, and with it client auth, by sending plaintext.
+type mailTLSListener struct {
+	net.Listener
+	config *tls.Config
+}
+
+func newMailTLSListener(listener net.Listener, config *tls.Config) net.Listener {
+	return &mailTLSListener{Listener: listener, config: config}
+}
+
+func (l *mailTLSListener) Accept() (net.Conn, error) {
+	conn, err := l.Listener.Accept()
+	if err != nil {
+		return nil, err
+	}
+	return &sniffConn{Conn: conn, reader: bufio.NewReader(conn), config: l.config}, nil
+}
+
+// sniffConn decides on first use whether the peer speaks TLS.
+type sniffConn struct {
+	net.Conn
+	reader *bufio.Reader
+	config *tls.Config
+
+	once    sync.Once
+	tlsConn *tls.Conn
+	err     error
+}
+
+func (c *sniffConn) detect() {
+	first, err := c.reader.Peek(1)
+	if err != nil {
+		c.err = err
+		return
+	}
+	if first[0] == tlsRecordHandshake {
+		c.tlsConn = tls.Server(&bufferedConn{Conn: c.Conn, reader: c.reader}, c.config)
+		return
+	}
+	if !isLoopbackAddr(c.Conn.RemoteAddr()) {
+		c.err = errMailPlaintextRefused
+		_ = c.Conn.Close()
+	}
+}
+
+// isLoopbackAddr reports whether addr is a loopback TCP address.
+func isLoopbackAddr(addr net.Addr) bool {
+	tcpAddr, ok := addr.(*net.TCPAddr)
+	return ok && tcpAddr.IP.IsLoopback()
+}
+
+func (c *sniffConn) Read(p []byte) (int, error) {
+	c.once.Do(c.detect)
+	if c.err != nil {
+		return 0, c.err
+	}
+	if c.tlsConn != nil {
+		return c.tlsConn.Read(p)
+	}
+	return c.reader.Read(p)
+}
+
+func (c *sniffConn) Write(p []byte) (int, error) {
+	c.once.Do(c.detect)
+	if c.err != nil {
+		return 0, c.err
+	}
+	if c.tlsConn != nil {
+		return c.tlsConn.Write(p)
+	}
+	return c.Conn.Write(p)
+}
+
+// bufferedConn replays bytes already buffered while sniffing.
+type bufferedConn struct {
+	net.Conn
+	reader *bufio.Reader
+}
+
+func (c *bufferedConn) Read(p []byte) (int, error) {
+	return c.reader.Read(p)
+}
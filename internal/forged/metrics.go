@@ -0,0 +1,358 @@
+package forged
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/tOgg1/forge/internal/db"
+	"github.com/tOgg1/forge/internal/models"
+	"github.com/tOgg1/forge/internal/state"
+)
+
+// MetricsPath is the HTTP path metrics are served on.
+const MetricsPath = "/metrics"
+
+// metricsContentType is the Prometheus text exposition format.
+const metricsContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// metricsScrapeTimeout bounds the database queries of one scrape.
+const metricsScrapeTimeout = 5 * time.Second
+
+// MetricsCollector writes extra metrics on each scrape. Components created
+// outside this package, like the scheduler, register one via AddCollector.
+type MetricsCollector func(ctx context.Context, w *MetricsWriter)
+
+// Metrics renders daemon state in the Prometheus text format. Most values are
+// read live from the components that own them when /metrics is scraped.
+type Metrics struct {
+	logger    zerolog.Logger
+	server    *Server
+	db        *db.DB
+	mail      *mailServer
+	relay     *mailRelayManager
+	poller    *state.Poller
+	startedAt time.Time
+
+	mu         sync.Mutex
+	violations map[[2]string]int64 // by violation type and severity
+	collectors []MetricsCollector
+}
+
+func newMetrics(logger zerolog.Logger, server *Server, database *db.DB, mail *mailServer, relay *mailRelayManager, poller *state.Poller) *Metrics {
+	return &Metrics{
+		logger:     logger,
+		server:     server,
+		db:         database,
+		mail:       mail,
+		relay:      relay,
+		poller:     poller,
+		startedAt:  time.Now(),
+		violations: make(map[[2]string]int64),
+	}
+}
+
+// AddCollector registers an extra collector.
+func (m *Metrics) AddCollector(collector MetricsCollector) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.collectors = append(m.collectors, collector)
+}
+
+// RecordViolation counts a resource limit violation.
+func (m *Metrics) RecordViolation(v ResourceViolation) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.violations[[2]string{v.ViolationType, v.Severity}]++
+}
+
+// ServeHTTP writes all metrics.
+func (m *Metrics) ServeHTTP(rw http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), metricsScrapeTimeout)
+	defer cancel()
+
+	w := NewMetricsWriter()
+	m.Collect(ctx, w)
+
+	rw.Header().Set("Content-Type", metricsContentType)
+	_, _ = rw.Write(w.Bytes())
+}
+
+// Collect writes all metrics to w.
+func (m *Metrics) Collect(ctx context.Context, w *MetricsWriter) {
+	w.Gauge("forged_start_time_seconds", "Unix time the daemon started.", float64(m.startedAt.Unix()))
+	if m.server != nil {
+		w.Gauge("forged_info", "Daemon version.", 1, "version", m.server.version)
+		w.Gauge("forged_event_subscribers", "Active StreamEvents subscribers.", float64(m.server.EventSubscriberCount()))
+		m.collectRateLimits(w)
+		m.collectResources(w)
+	}
+	if m.poller != nil {
+		w.Gauge("forged_state_poller_running", "Whether the agent state poller is running.", boolValue(m.poller.IsRunning()))
+	}
+	m.collectViolations(w)
+	m.collectMail(w)
+	if m.db != nil {
+		m.collectLoops(ctx, w)
+	}
+
+	m.mu.Lock()
+	collectors := append([]MetricsCollector(nil), m.collectors...)
+	m.mu.Unlock()
+	for _, collector := range collectors {
+		collector(ctx, w)
+	}
+}
+
+func (m *Metrics) collectRateLimits(w *MetricsWriter) {
+	rl := m.server.RateLimiter()
+	if rl == nil {
+		return
+	}
+	stats := rl.Stats()
+	sort.Slice(stats, func(i, j int) bool { return stats[i].Method < stats[j].Method })
+	for _, stat := range stats {
+		w.Counter("forged_grpc_requests_total", "gRPC requests seen by the rate limiter.", float64(stat.TotalRequests), "method", stat.Method)
+	}
+	for _, stat := range stats {
+		w.Counter("forged_grpc_rate_limited_total", "gRPC requests rejected by the rate limiter.", float64(stat.DeniedRequests), "method", stat.Method)
+	}
+}
+
+func (m *Metrics) collectResources(w *MetricsWriter) {
+	rm := m.server.ResourceMonitor()
+	if rm == nil {
+		return
+	}
+
+	usage := rm.GetAllUsage()
+	agentIDs := make([]string, 0, len(usage))
+	for id := range usage {
+		agentIDs = append(agentIDs, id)
+	}
+	sort.Strings(agentIDs)
+	for _, id := range agentIDs {
+		w.Gauge("forge_agent_memory_bytes", "Agent resident memory.", float64(usage[id].MemoryBytes), "agent", id)
+	}
+	for _, id := range agentIDs {
+		w.Gauge("forge_agent_cpu_percent", "Agent CPU usage.", usage[id].CPUPercent, "agent", id)
+	}
+
+	if disk, level, ok := rm.GetDiskUsage(); ok {
+		w.Gauge("forged_disk_used_percent", "Disk usage of the monitored path.", disk.UsedPercent, "path", disk.Path)
+		w.Gauge("forged_disk_usage_level", "Disk usage level: 0 ok, 1 warn, 2 critical.", float64(level), "path", disk.Path)
+	}
+}
+
+func (m *Metrics) collectViolations(w *MetricsWriter) {
+	m.mu.Lock()
+	keys := make([][2]string, 0, len(m.violations))
+	for key := range m.violations {
+		keys = append(keys, key)
+	}
+	counts := make(map[[2]string]int64, len(m.violations))
+	for key, count := range m.violations {
+		counts[key] = count
+	}
+	m.mu.Unlock()
+
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i][0] != keys[j][0] {
+			return keys[i][0] < keys[j][0]
+		}
+		return keys[i][1] < keys[j][1]
+	})
+	if len(keys) == 0 {
+		w.Counter("forge_resource_violations_total", "Agent resource limit violations.", 0)
+	}
+	for _, key := range keys {
+		w.Counter("forge_resource_violations_total", "Agent resource limit violations.", float64(counts[key]), "type", key[0], "severity", key[1])
+	}
+}
+
+func (m *Metrics) collectMail(w *MetricsWriter) {
+	if m.mail == nil {
+		return
+	}
+
+	statuses := m.relay.peerStatuses()
+	var relayed int64
+	for _, status := range statuses {
+		relayed += status.Relayed
+	}
+	w.Counter("forge_mail_messages_total", "Mail messages accepted, by source.", float64(m.mail.sent.Load()), "source", "local")
+	w.Counter("forge_mail_messages_total", "Mail messages accepted, by source.", float64(relayed), "source", "relay")
+
+	subscribers := m.mail.subscriberCounts()
+	projects := make([]string, 0, len(subscribers))
+	for project := range subscribers {
+		projects = append(projects, project)
+	}
+	sort.Strings(projects)
+	for _, project := range projects {
+		w.Gauge("forge_mail_subscribers", "Mail watch and relay subscribers.", float64(subscribers[project]), "project", project)
+	}
+
+	for _, status := range statuses {
+		w.Gauge("forge_mail_relay_peer_connected", "Whether the relay stream to a peer is connected.", boolValue(status.Connected), "peer", status.Peer, "project", status.Project)
+	}
+	for _, status := range statuses {
+		w.Gauge("forge_mail_relay_peer_lag_seconds", "Age of the last relayed message when it arrived.", status.Lag.Seconds(), "peer", status.Peer, "project", status.Project)
+	}
+	for _, status := range statuses {
+		w.Counter("forge_mail_relay_messages_total", "Mail messages relayed from a peer.", float64(status.Relayed), "peer", status.Peer, "project", status.Project)
+	}
+}
+
+func (m *Metrics) collectLoops(ctx context.Context, w *MetricsWriter) {
+	loops, err := db.NewLoopRepository(m.db).List(ctx)
+	if err != nil {
+		m.logger.Warn().Err(err).Msg("metrics: failed to list loops")
+	} else {
+		byState := make(map[models.LoopState]int)
+		for _, loopEntry := range loops {
+			byState[loopEntry.State]++
+		}
+		for _, loopState := range []models.LoopState{
+			models.LoopStateRunning,
+			models.LoopStateSleeping,
+			models.LoopStateWaiting,
+			models.LoopStateStopped,
+			models.LoopStateError,
+		} {
+			w.Gauge("forge_loops", "Loops by state.", float64(byState[loopState]), "state", string(loopState))
+		}
+	}
+
+	runs, err := db.NewLoopRunRepository(m.db).CountByStatusAndProfile(ctx)
+	if err != nil {
+		m.logger.Warn().Err(err).Msg("metrics: failed to count loop runs")
+	} else {
+		for _, count := range runs {
+			w.Counter("forge_loop_runs_total", "Loop runs by exit status and profile.", float64(count.Count), "status", string(count.Status), "profile", count.Profile)
+		}
+	}
+
+	pending, err := db.NewLoopQueueRepository(m.db).CountPendingByLoop(ctx)
+	if err != nil {
+		m.logger.Warn().Err(err).Msg("metrics: failed to count loop queues")
+	} else {
+		names := make([]string, 0, len(pending))
+		for name := range pending {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			w.Gauge("forge_loop_queue_pending", "Pending loop queue items.", float64(pending[name]), "loop", name)
+		}
+	}
+
+	profiles, err := db.NewProfileRepository(m.db).List(ctx)
+	if err != nil {
+		m.logger.Warn().Err(err).Msg("metrics: failed to list profiles")
+		return
+	}
+	now := time.Now()
+	cooling := 0
+	for _, profile := range profiles {
+		if profile.CooldownUntil != nil && profile.CooldownUntil.After(now) {
+			cooling++
+		}
+	}
+	w.Gauge("forge_profiles_in_cooldown", "Profiles currently in cooldown.", float64(cooling))
+	for _, profile := range profiles {
+		if profile.CooldownUntil != nil && profile.CooldownUntil.After(now) {
+			w.Gauge("forge_profile_cooldown_remaining_seconds", "Time until a profile leaves cooldown.", profile.CooldownUntil.Sub(now).Seconds(), "profile", profile.Name)
+		}
+	}
+}
+
+// MetricsWriter builds a Prometheus text exposition. Samples of one metric
+// must be written consecutively; HELP and TYPE are emitted before the first.
+type MetricsWriter struct {
+	buf  bytes.Buffer
+	last string
+}
+
+// NewMetricsWriter creates an empty writer.
+func NewMetricsWriter() *MetricsWriter {
+	return &MetricsWriter{}
+}
+
+// Gauge writes a gauge sample. labels are name/value pairs.
+func (w *MetricsWriter) Gauge(name, help string, value float64, labels ...string) {
+	w.sample("gauge", name, help, value, labels)
+}
+
+// Counter writes a counter sample. labels are name/value pairs.
+func (w *MetricsWriter) Counter(name, help string, value float64, labels ...string) {
+	w.sample("counter", name, help, value, labels)
+}
+
+// Bytes returns the exposition written so far.
+func (w *MetricsWriter) Bytes() []byte {
+	return w.buf.Bytes()
+}
+
+func (w *MetricsWriter) sample(kind, name, help string, value float64, labels []string) {
+	if name != w.last {
+		fmt.Fprintf(&w.buf, "# HELP %s %s\n# TYPE %s %s\n", name, escapeMetricHelp(help), name, kind)
+		w.last = name
+	}
+
+	w.buf.WriteString(name)
+	if len(labels) > 0 {
+		w.buf.WriteByte('{')
+		for i := 0; i+1 < len(labels); i += 2 {
+			if i > 0 {
+				w.buf.WriteByte(',')
+			}
+			fmt.Fprintf(&w.buf, "%s=\"%s\"", labels[i], escapeMetricLabel(labels[i+1]))
+		}
+		w.buf.WriteByte('}')
+	}
+	w.buf.WriteByte(' ')
+	w.buf.WriteString(formatMetricValue(value))
+	w.buf.WriteByte('\n')
+}
+
+func formatMetricValue(value float64) string {
+	switch {
+	case math.IsInf(value, 1):
+		return "+Inf"
+	case math.IsInf(value, -1):
+		return "-Inf"
+	case math.IsNaN(value):
+		return "NaN"
+	default:
+		return strconv.FormatFloat(value, 'g', -1, 64)
+	}
+}
+
+var (
+	metricHelpEscaper  = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+	metricLabelEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+)
+
+func escapeMetricHelp(help string) string {
+	return metricHelpEscaper.Replace(help)
+}
+
+func escapeMetricLabel(value string) string {
+	return metricLabelEscaper.Replace(value)
+}
+
+func boolValue(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
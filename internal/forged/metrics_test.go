@@ -0,0 +1,100 @@
+package forged
+
+import (
+	"context"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/tOgg1/forge/internal/db"
+	"github.com/tOgg1/forge/internal/models"
+)
+
+func TestMetricsWriter(t *testing.T) {
+	w := NewMetricsWriter()
+	w.Gauge("forge_loops", "Loops by state.", 2, "state", "running")
+	w.Gauge("forge_loops", "Loops by state.", 0, "state", "error")
+	w.Counter("forge_events_total", "Events.\nSecond line.", 1.5, "name", `a "quoted" \ value`)
+	w.Gauge("forge_up", "Up.", 1)
+
+	want := `# HELP forge_loops Loops by state.
+# TYPE forge_loops gauge
+forge_loops{state="running"} 2
+forge_loops{state="error"} 0
+# HELP forge_events_total Events.\nSecond line.
+# TYPE forge_events_total counter
+forge_events_total{name="a \"quoted\" \\ value"} 1.5
+# HELP forge_up Up.
+# TYPE forge_up gauge
+forge_up 1
+`
+	if got := string(w.Bytes()); got != want {
+		t.Fatalf("MetricsWriter output =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestMetricsHandler(t *testing.T) {
+	server, database := newLoopTestServer(t)
+	ctx := context.Background()
+
+	loopRepo := db.NewLoopRepository(database)
+	running := &models.Loop{Name: "build", RepoPath: "/repo", State: models.LoopStateRunning}
+	if err := loopRepo.Create(ctx, running); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if err := db.NewLoopQueueRepository(database).Enqueue(ctx, running.ID, &models.LoopQueueItem{
+		Type:    models.LoopQueueItemStopGraceful,
+		Payload: []byte(`{}`),
+	}); err != nil {
+		t.Fatalf("Enqueue() error = %v", err)
+	}
+
+	cooldown := time.Now().UTC().Add(10 * time.Minute)
+	profile := &models.Profile{
+		Name:            "pi",
+		Harness:         models.HarnessPi,
+		CommandTemplate: "pi -p \"{prompt}\"",
+		PromptMode:      models.PromptModePath,
+		CooldownUntil:   &cooldown,
+	}
+	if err := db.NewProfileRepository(database).Create(ctx, profile); err != nil {
+		t.Fatalf("Create() profile error = %v", err)
+	}
+	run := &models.LoopRun{LoopID: running.ID, ProfileID: profile.ID, Status: models.LoopRunStatusSuccess}
+	if err := db.NewLoopRunRepository(database).Create(ctx, run); err != nil {
+		t.Fatalf("Create() run error = %v", err)
+	}
+
+	metrics := newMetrics(zerolog.Nop(), server, database, newMailServer(zerolog.Nop()), nil, nil)
+	metrics.RecordViolation(ResourceViolation{ViolationType: "memory", Severity: "warning"})
+	metrics.RecordViolation(ResourceViolation{ViolationType: "memory", Severity: "warning"})
+	metrics.AddCollector(func(_ context.Context, w *MetricsWriter) {
+		w.Gauge("forge_extra", "Extra.", 7)
+	})
+
+	rec := httptest.NewRecorder()
+	metrics.ServeHTTP(rec, httptest.NewRequest("GET", MetricsPath, nil))
+	if ct := rec.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/plain; version=0.0.4") {
+		t.Errorf("Content-Type = %q, want Prometheus text format", ct)
+	}
+
+	body := rec.Body.String()
+	for _, want := range []string{
+		`forge_loops{state="running"} 1`,
+		`forge_loops{state="stopped"} 0`,
+		`forge_loop_runs_total{status="success",profile="pi"} 1`,
+		`forge_loop_queue_pending{loop="build"} 1`,
+		`forge_profiles_in_cooldown 1`,
+		`forge_profile_cooldown_remaining_seconds{profile="pi"} `,
+		`forge_mail_messages_total{source="local"} 0`,
+		`forge_resource_violations_total{type="memory",severity="warning"} 2`,
+		`forged_event_subscribers 0`,
+		`forge_extra 7`,
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("metrics missing %q\n%s", want, body)
+		}
+	}
+}
@@ -26,6 +26,14 @@ var DefaultRateLimits = map[string]RateLimitConfig{
 	"/forged.v1.ForgedService/SpawnAgent": {RequestsPerSecond: 5, BurstSize: 10},
 	"/forged.v1.ForgedService/KillAgent":  {RequestsPerSecond: 10, BurstSize: 20},
 
+	// Loop control
+	"/forged.v1.ForgedService/CreateLoop":       {RequestsPerSecond: 5, BurstSize: 10},
+	"/forged.v1.ForgedService/StartLoop":        {RequestsPerSecond: 5, BurstSize: 10},
+	"/forged.v1.ForgedService/StopLoop":         {RequestsPerSecond: 10, BurstSize: 20},
+	"/forged.v1.ForgedService/KillLoop":         {RequestsPerSecond: 10, BurstSize: 20},
+	"/forged.v1.ForgedService/EnqueueLoopItems": {RequestsPerSecond: 50, BurstSize: 100},
+	"/forged.v1.ForgedService/ListLoops":        {RequestsPerSecond: 100, BurstSize: 200},
+
 	// Input operations - moderate limits
 	"/forged.v1.ForgedService/SendInput": {RequestsPerSecond: 50, BurstSize: 100},
 
@@ -45,6 +53,7 @@ var DefaultRateLimits = map[string]RateLimitConfig{
 	"/forged.v1.ForgedService/StreamPaneUpdates": {RequestsPerSecond: 10, BurstSize: 20},
 	"/forged.v1.ForgedService/StreamEvents":      {RequestsPerSecond: 10, BurstSize: 20},
 	"/forged.v1.ForgedService/StreamTranscript":  {RequestsPerSecond: 10, BurstSize: 20},
+	"/forged.v1.ForgedService/StreamLoopLogs":    {RequestsPerSecond: 10, BurstSize: 20},
 }
 
 // tokenBucket implements the token bucket algorithm for rate limiting.
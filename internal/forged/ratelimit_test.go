@@ -381,6 +381,13 @@ func TestDefaultRateLimitsExist(t *testing.T) {
 		"/forged.v1.ForgedService/StreamPaneUpdates",
 		"/forged.v1.ForgedService/StreamEvents",
 		"/forged.v1.ForgedService/StreamTranscript",
+		"/forged.v1.ForgedService/CreateLoop",
+		"/forged.v1.ForgedService/StartLoop",
+		"/forged.v1.ForgedService/StopLoop",
+		"/forged.v1.ForgedService/KillLoop",
+		"/forged.v1.ForgedService/ListLoops",
+		"/forged.v1.ForgedService/EnqueueLoopItems",
+		"/forged.v1.ForgedService/StreamLoopLogs",
 	}
 
 	for _, method := range expectedMethods {
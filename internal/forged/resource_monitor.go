@@ -365,6 +365,18 @@ func (rm *ResourceMonitor) GetAllUsage() map[string]ResourceUsage {
 	return result
 }
 
+// GetDiskUsage returns the last disk usage sample and its level
+// (0 ok, 1 warn, 2 critical). ok is false until disk usage has been measured.
+func (rm *ResourceMonitor) GetDiskUsage() (usage DiskUsage, level int, ok bool) {
+	rm.mu.RLock()
+	defer rm.mu.RUnlock()
+
+	if rm.diskState.lastCheckedAt.IsZero() {
+		return DiskUsage{}, 0, false
+	}
+	return rm.diskState.lastUsage, int(rm.diskState.level), true
+}
+
 // monitorLoop is the main monitoring loop.
 func (rm *ResourceMonitor) monitorLoop(ctx context.Context) {
 	ticker := time.NewTicker(rm.interval)
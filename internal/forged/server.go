@@ -39,6 +39,7 @@ type eventSubscriber struct {
 	eventTypes   map[forgedv1.EventType]bool // nil = all types
 	agentIDs     map[string]bool             // nil = all agents
 	workspaceIDs map[string]bool             // nil = all workspaces
+	loopIDs      map[string]bool             // nil = all loops
 	ch           chan *forgedv1.Event
 }
 
@@ -96,6 +97,9 @@ type Server struct {
 
 	// Resource monitor for enforcing resource caps
 	resourceMonitor *ResourceMonitor
+
+	// Loop supervisor for the loop control RPCs
+	loopSupervisor *LoopSupervisor
 }
 
 // ServerOption configures the Server.
@@ -150,6 +154,16 @@ func (s *Server) ResourceMonitor() *ResourceMonitor {
 	return s.resourceMonitor
 }
 
+// SetLoopSupervisor sets the loop supervisor reference.
+func (s *Server) SetLoopSupervisor(ls *LoopSupervisor) {
+	s.loopSupervisor = ls
+}
+
+// LoopSupervisor returns the loop supervisor, if configured.
+func (s *Server) LoopSupervisor() *LoopSupervisor {
+	return s.loopSupervisor
+}
+
 // =============================================================================
 // Agent Control
 // =============================================================================
@@ -1023,6 +1037,14 @@ func (s *Server) StreamEvents(req *forgedv1.StreamEventsRequest, stream forgedv1
 		}
 	}
 
+	var loopIDs map[string]bool
+	if len(req.LoopIds) > 0 {
+		loopIDs = make(map[string]bool, len(req.LoopIds))
+		for _, id := range req.LoopIds {
+			loopIDs[id] = true
+		}
+	}
+
 	// Parse cursor if provided
 	var cursor int64
 	if req.Cursor != "" {
@@ -1038,6 +1060,7 @@ func (s *Server) StreamEvents(req *forgedv1.StreamEventsRequest, stream forgedv1
 		eventTypes:   eventTypes,
 		agentIDs:     agentIDs,
 		workspaceIDs: workspaceIDs,
+		loopIDs:      loopIDs,
 		ch:           make(chan *forgedv1.Event, eventChannelBuffer),
 	}
 
@@ -1118,9 +1141,21 @@ func (s *Server) eventMatchesFilter(event *forgedv1.Event, sub *eventSubscriber)
 		return false
 	}
 
+	// Check loop ID filter
+	if sub.loopIDs != nil && event.LoopId != "" && !sub.loopIDs[event.LoopId] {
+		return false
+	}
+
 	return true
 }
 
+// EventSubscriberCount returns the number of active StreamEvents subscribers.
+func (s *Server) EventSubscriberCount() int {
+	s.eventsMu.RLock()
+	defer s.eventsMu.RUnlock()
+	return len(s.eventSubs)
+}
+
 // publishEvent stores an event and broadcasts it to all matching subscribers.
 func (s *Server) publishEvent(event *forgedv1.Event) {
 	s.eventsMu.Lock()
@@ -0,0 +1,560 @@
+package forged
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	forgedv1 "github.com/tOgg1/forge/gen/forged/v1"
+	"github.com/tOgg1/forge/internal/db"
+	"github.com/tOgg1/forge/internal/models"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// loopLogPollInterval is how often followed loop logs are checked for new lines.
+const loopLogPollInterval = 250 * time.Millisecond
+
+// Loop log reads are bounded so a large log cannot exhaust forged's memory.
+const (
+	// maxLogTailBytes is how far back from the end the initial tail reads,
+	// also when all lines are requested.
+	maxLogTailBytes = 1 << 20
+	// logReadChunkBytes is the read size when tailing, and the most a single
+	// follow poll reads.
+	logReadChunkBytes = 64 << 10
+)
+
+// =============================================================================
+// Loop Control
+// =============================================================================
+
+// CreateLoop creates a loop on this node and optionally starts it.
+func (s *Server) CreateLoop(ctx context.Context, req *forgedv1.CreateLoopRequest) (*forgedv1.CreateLoopResponse, error) {
+	ls, err := s.requireLoopSupervisor()
+	if err != nil {
+		return nil, err
+	}
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return nil, status.Error(codes.InvalidArgument, "name is required")
+	}
+	if req.RepoPath == "" {
+		return nil, status.Error(codes.InvalidArgument, "repo_path is required")
+	}
+	if !filepath.IsAbs(req.RepoPath) {
+		return nil, status.Error(codes.InvalidArgument, "repo_path must be absolute")
+	}
+
+	interval := ls.cfg.LoopDefaults.Interval
+	if req.Interval != nil {
+		interval = req.Interval.AsDuration()
+	}
+	var maxRuntime time.Duration
+	if req.MaxRuntime != nil {
+		maxRuntime = req.MaxRuntime.AsDuration()
+	}
+	if interval < 0 {
+		return nil, status.Error(codes.InvalidArgument, "interval must be >= 0")
+	}
+	if req.MaxIterations <= 0 || maxRuntime <= 0 {
+		return nil, status.Error(codes.InvalidArgument, "max_iterations and max_runtime must be > 0")
+	}
+
+	loopRepo := db.NewLoopRepository(ls.db)
+	if _, err := loopRepo.GetByName(ctx, name); err == nil {
+		return nil, status.Errorf(codes.AlreadyExists, "loop %q already exists", name)
+	}
+
+	loopEntry := &models.Loop{
+		Name:              name,
+		RepoPath:          filepath.Clean(req.RepoPath),
+		BasePromptPath:    req.PromptPath,
+		BasePromptMsg:     strings.TrimSpace(req.PromptMsg),
+		IntervalSeconds:   int(interval.Round(time.Second).Seconds()),
+		MaxIterations:     int(req.MaxIterations),
+		MaxRuntimeSeconds: int(maxRuntime.Round(time.Second).Seconds()),
+		Tags:              req.Tags,
+		State:             models.LoopStateStopped,
+	}
+	if loopEntry.BasePromptMsg == "" && loopEntry.BasePromptPath == "" {
+		loopEntry.BasePromptMsg = strings.TrimSpace(ls.cfg.LoopDefaults.PromptMsg)
+		loopEntry.BasePromptPath = ls.cfg.LoopDefaults.Prompt
+	}
+	if req.Profile != "" {
+		profileRepo := db.NewProfileRepository(ls.db)
+		profile, err := profileRepo.GetByName(ctx, req.Profile)
+		if err != nil {
+			if profile, err = profileRepo.Get(ctx, req.Profile); err != nil {
+				return nil, status.Errorf(codes.NotFound, "profile %q not found", req.Profile)
+			}
+		}
+		loopEntry.ProfileID = profile.ID
+	}
+	if req.Pool != "" {
+		poolRepo := db.NewPoolRepository(ls.db)
+		pool, err := poolRepo.GetByName(ctx, req.Pool)
+		if err != nil {
+			if pool, err = poolRepo.Get(ctx, req.Pool); err != nil {
+				return nil, status.Errorf(codes.NotFound, "pool %q not found", req.Pool)
+			}
+		}
+		loopEntry.PoolID = pool.ID
+	}
+
+	if err := loopRepo.Create(ctx, loopEntry); err != nil {
+		if errors.Is(err, db.ErrLoopAlreadyExists) {
+			return nil, status.Errorf(codes.AlreadyExists, "loop %q already exists", name)
+		}
+		return nil, status.Errorf(codes.InvalidArgument, "failed to create loop: %v", err)
+	}
+
+	if req.Start {
+		if err := ls.StartLoop(loopEntry.ID); err != nil {
+			return nil, status.Errorf(codes.Internal, "failed to start loop: %v", err)
+		}
+	}
+
+	s.logger.Info().
+		Str("loop_id", loopEntry.ID).
+		Str("name", loopEntry.Name).
+		Str("repo_path", loopEntry.RepoPath).
+		Bool("start", req.Start).
+		Msg("loop created")
+
+	return &forgedv1.CreateLoopResponse{Loop: ls.loopToProto(loopEntry)}, nil
+}
+
+// StartLoop launches a runner process for a stopped loop.
+func (s *Server) StartLoop(ctx context.Context, req *forgedv1.StartLoopRequest) (*forgedv1.StartLoopResponse, error) {
+	ls, err := s.requireLoopSupervisor()
+	if err != nil {
+		return nil, err
+	}
+	loopEntry, err := ls.resolveLoop(ctx, req.Loop)
+	if err != nil {
+		return nil, err
+	}
+
+	if !ls.Supervised(loopEntry.ID) {
+		if pid, ok := loopPID(loopEntry); ok && loopActive(loopEntry) && processAlive(pid) {
+			return nil, status.Errorf(codes.FailedPrecondition, "loop %q is already running (pid %d)", loopEntry.Name, pid)
+		}
+		if err := ls.StartLoop(loopEntry.ID); err != nil {
+			return nil, status.Errorf(codes.Internal, "failed to start loop: %v", err)
+		}
+	}
+
+	return &forgedv1.StartLoopResponse{Loop: ls.loopToProto(loopEntry)}, nil
+}
+
+// StopLoop asks a loop to stop after its current iteration.
+func (s *Server) StopLoop(ctx context.Context, req *forgedv1.StopLoopRequest) (*forgedv1.StopLoopResponse, error) {
+	ls, err := s.requireLoopSupervisor()
+	if err != nil {
+		return nil, err
+	}
+	loopEntry, err := ls.resolveLoop(ctx, req.Loop)
+	if err != nil {
+		return nil, err
+	}
+
+	payload, err := json.Marshal(models.StopPayload{Reason: controlReason(req.Reason)})
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to encode stop payload: %v", err)
+	}
+	item := &models.LoopQueueItem{Type: models.LoopQueueItemStopGraceful, Payload: payload}
+	if err := db.NewLoopQueueRepository(ls.db).Enqueue(ctx, loopEntry.ID, item); err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to enqueue stop: %v", err)
+	}
+
+	s.logger.Info().
+		Str("loop_id", loopEntry.ID).
+		Str("reason", req.Reason).
+		Msg("loop stop requested")
+
+	return &forgedv1.StopLoopResponse{Loop: ls.loopToProto(loopEntry)}, nil
+}
+
+// KillLoop kills a loop's runner immediately and marks the loop stopped.
+func (s *Server) KillLoop(ctx context.Context, req *forgedv1.KillLoopRequest) (*forgedv1.KillLoopResponse, error) {
+	ls, err := s.requireLoopSupervisor()
+	if err != nil {
+		return nil, err
+	}
+	loopEntry, err := ls.resolveLoop(ctx, req.Loop)
+	if err != nil {
+		return nil, err
+	}
+
+	payload, err := json.Marshal(models.KillPayload{Reason: controlReason(req.Reason)})
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to encode kill payload: %v", err)
+	}
+	item := &models.LoopQueueItem{Type: models.LoopQueueItemKillNow, Payload: payload}
+	if err := db.NewLoopQueueRepository(ls.db).Enqueue(ctx, loopEntry.ID, item); err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to enqueue kill: %v", err)
+	}
+	if err := ls.KillLoop(loopEntry); err != nil {
+		s.logger.Warn().Err(err).Str("loop_id", loopEntry.ID).Msg("failed to kill loop process")
+	}
+
+	// The runner may have written the loop since it was resolved, so only the
+	// state is set and the loop is read again.
+	loopRepo := db.NewLoopRepository(ls.db)
+	if err := loopRepo.SetState(ctx, loopEntry.ID, models.LoopStateStopped, ""); err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to update loop: %v", err)
+	}
+	if loopEntry, err = loopRepo.Get(ctx, loopEntry.ID); err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to load loop: %v", err)
+	}
+
+	s.logger.Info().
+		Str("loop_id", loopEntry.ID).
+		Str("reason", req.Reason).
+		Msg("loop killed")
+
+	return &forgedv1.KillLoopResponse{Loop: ls.loopToProto(loopEntry)}, nil
+}
+
+// ListLoops returns the loops on this node.
+func (s *Server) ListLoops(ctx context.Context, req *forgedv1.ListLoopsRequest) (*forgedv1.ListLoopsResponse, error) {
+	ls, err := s.requireLoopSupervisor()
+	if err != nil {
+		return nil, err
+	}
+	loops, err := db.NewLoopRepository(ls.db).List(ctx)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to list loops: %v", err)
+	}
+
+	var states map[forgedv1.LoopState]bool
+	if len(req.States) > 0 {
+		states = make(map[forgedv1.LoopState]bool, len(req.States))
+		for _, state := range req.States {
+			states[state] = true
+		}
+	}
+	repoPath := ""
+	if req.RepoPath != "" {
+		repoPath = filepath.Clean(req.RepoPath)
+	}
+
+	resp := &forgedv1.ListLoopsResponse{Loops: make([]*forgedv1.Loop, 0, len(loops))}
+	for _, loopEntry := range loops {
+		if repoPath != "" && loopEntry.RepoPath != repoPath {
+			continue
+		}
+		if states != nil && !states[loopStateToProto(loopEntry.State)] {
+			continue
+		}
+		if req.Tag != "" && !hasLoopTag(loopEntry, req.Tag) {
+			continue
+		}
+		resp.Loops = append(resp.Loops, ls.loopToProto(loopEntry))
+	}
+	return resp, nil
+}
+
+// EnqueueLoopItems appends items to a loop's queue.
+func (s *Server) EnqueueLoopItems(ctx context.Context, req *forgedv1.EnqueueLoopItemsRequest) (*forgedv1.EnqueueLoopItemsResponse, error) {
+	ls, err := s.requireLoopSupervisor()
+	if err != nil {
+		return nil, err
+	}
+	if len(req.Items) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "items are required")
+	}
+	loopEntry, err := ls.resolveLoop(ctx, req.Loop)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]*models.LoopQueueItem, 0, len(req.Items))
+	for i, reqItem := range req.Items {
+		if !json.Valid([]byte(reqItem.PayloadJson)) {
+			return nil, status.Errorf(codes.InvalidArgument, "item %d: payload_json is not valid JSON", i)
+		}
+		item := &models.LoopQueueItem{
+			Type:    models.LoopQueueItemType(reqItem.Type),
+			Payload: json.RawMessage(reqItem.PayloadJson),
+		}
+		if err := item.Validate(); err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "item %d: %v", i, err)
+		}
+		items = append(items, item)
+	}
+
+	if err := db.NewLoopQueueRepository(ls.db).Enqueue(ctx, loopEntry.ID, items...); err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to enqueue items: %v", err)
+	}
+
+	resp := &forgedv1.EnqueueLoopItemsResponse{ItemIds: make([]string, 0, len(items))}
+	for _, item := range items {
+		resp.ItemIds = append(resp.ItemIds, item.ID)
+	}
+	return resp, nil
+}
+
+// StreamLoopLogs streams a loop's log lines, optionally following new output.
+func (s *Server) StreamLoopLogs(req *forgedv1.StreamLoopLogsRequest, stream forgedv1.ForgedService_StreamLoopLogsServer) error {
+	ls, err := s.requireLoopSupervisor()
+	if err != nil {
+		return err
+	}
+	ctx := stream.Context()
+	loopEntry, err := ls.resolveLoop(ctx, req.Loop)
+	if err != nil {
+		return err
+	}
+
+	// The runner assigns the log path on its first start.
+	var lines []string
+	var offset int64
+	if loopEntry.LogPath != "" {
+		lines, offset, err = tailLogLines(loopEntry.LogPath, int(req.TailLines))
+	}
+	if loopEntry.LogPath == "" || os.IsNotExist(err) {
+		if !req.Follow {
+			return status.Errorf(codes.NotFound, "loop %q has not written a log yet", loopEntry.Name)
+		}
+	} else if err != nil {
+		return status.Errorf(codes.Internal, "failed to read loop log: %v", err)
+	}
+	if len(lines) > 0 {
+		if err := stream.Send(&forgedv1.StreamLoopLogsResponse{LoopId: loopEntry.ID, Lines: lines}); err != nil {
+			return err
+		}
+	}
+	if !req.Follow {
+		return nil
+	}
+
+	ticker := time.NewTicker(loopLogPollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+			if loopEntry.LogPath == "" {
+				if loopEntry, err = ls.resolveLoop(ctx, loopEntry.ID); err != nil {
+					return err
+				}
+				if loopEntry.LogPath == "" {
+					continue
+				}
+			}
+			lines, offset, err = readLogLines(loopEntry.LogPath, offset)
+			if err != nil {
+				if os.IsNotExist(err) {
+					continue
+				}
+				return status.Errorf(codes.Internal, "failed to read loop log: %v", err)
+			}
+			if len(lines) == 0 {
+				continue
+			}
+			if err := stream.Send(&forgedv1.StreamLoopLogsResponse{LoopId: loopEntry.ID, Lines: lines}); err != nil {
+				s.logger.Debug().Err(err).Str("loop_id", loopEntry.ID).Msg("failed to send loop log lines")
+				return err
+			}
+		}
+	}
+}
+
+// requireLoopSupervisor returns the loop supervisor or an Unavailable error.
+func (s *Server) requireLoopSupervisor() (*LoopSupervisor, error) {
+	if s.loopSupervisor == nil {
+		return nil, status.Error(codes.Unavailable, "loop supervision is not enabled on this node")
+	}
+	return s.loopSupervisor, nil
+}
+
+// resolveLoop looks up a loop by short ID, ID, or name.
+func (ls *LoopSupervisor) resolveLoop(ctx context.Context, ref string) (*models.Loop, error) {
+	ref = strings.TrimSpace(ref)
+	if ref == "" {
+		return nil, status.Error(codes.InvalidArgument, "loop is required")
+	}
+	loopRepo := db.NewLoopRepository(ls.db)
+	if loopEntry, err := loopRepo.GetByShortID(ctx, ref); err == nil {
+		return loopEntry, nil
+	}
+	if loopEntry, err := loopRepo.Get(ctx, ref); err == nil {
+		return loopEntry, nil
+	}
+	if loopEntry, err := loopRepo.GetByName(ctx, ref); err == nil {
+		return loopEntry, nil
+	}
+	return nil, status.Errorf(codes.NotFound, "loop %q not found", ref)
+}
+
+// loopToProto converts a loop to proto format.
+func (ls *LoopSupervisor) loopToProto(loopEntry *models.Loop) *forgedv1.Loop {
+	out := &forgedv1.Loop{
+		Id:         loopEntry.ID,
+		ShortId:    loopEntry.ShortID,
+		Name:       loopEntry.Name,
+		RepoPath:   loopEntry.RepoPath,
+		State:      loopStateToProto(loopEntry.State),
+		ProfileId:  loopEntry.ProfileID,
+		PoolId:     loopEntry.PoolID,
+		Tags:       loopEntry.Tags,
+		Supervised: ls.Supervised(loopEntry.ID),
+		LastError:  loopEntry.LastError,
+		LogPath:    loopEntry.LogPath,
+		CreatedAt:  timestamppb.New(loopEntry.CreatedAt),
+	}
+	if pid, ok := loopPID(loopEntry); ok {
+		out.Pid = int32(pid)
+	}
+	if loopEntry.LastRunAt != nil {
+		out.LastRunAt = timestamppb.New(*loopEntry.LastRunAt)
+	}
+	return out
+}
+
+// loopStateToProto converts a loop state to proto format.
+func loopStateToProto(state models.LoopState) forgedv1.LoopState {
+	switch state {
+	case models.LoopStateRunning:
+		return forgedv1.LoopState_LOOP_STATE_RUNNING
+	case models.LoopStateSleeping:
+		return forgedv1.LoopState_LOOP_STATE_SLEEPING
+	case models.LoopStateWaiting:
+		return forgedv1.LoopState_LOOP_STATE_WAITING
+	case models.LoopStateStopped:
+		return forgedv1.LoopState_LOOP_STATE_STOPPED
+	case models.LoopStateError:
+		return forgedv1.LoopState_LOOP_STATE_ERROR
+	default:
+		return forgedv1.LoopState_LOOP_STATE_UNSPECIFIED
+	}
+}
+
+// loopActive reports whether the loop's state says its runner is alive.
+func loopActive(loopEntry *models.Loop) bool {
+	switch loopEntry.State {
+	case models.LoopStateRunning, models.LoopStateSleeping, models.LoopStateWaiting:
+		return true
+	default:
+		return false
+	}
+}
+
+func hasLoopTag(loopEntry *models.Loop, tag string) bool {
+	for _, t := range loopEntry.Tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// controlReason defaults stop and kill reasons to "operator".
+func controlReason(reason string) string {
+	if reason = strings.TrimSpace(reason); reason != "" {
+		return reason
+	}
+	return "operator"
+}
+
+// tailLogLines returns the last n complete lines of path (all of them for a
+// negative n) and the offset following them. It reads backwards from the end
+// in chunks, never more than maxLogTailBytes.
+func tailLogLines(path string, n int) ([]string, int64, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return nil, 0, err
+	}
+
+	size := info.Size()
+	start := size
+	var data []byte
+	for start > 0 && size-start < maxLogTailBytes {
+		// n lines are complete once the newline before them is read too.
+		if n >= 0 && bytes.Count(data, []byte{'\n'}) > n {
+			break
+		}
+		chunk := min(int64(logReadChunkBytes), start, maxLogTailBytes-(size-start))
+		start -= chunk
+		buf := make([]byte, chunk)
+		if _, err := file.ReadAt(buf, start); err != nil {
+			return nil, 0, err
+		}
+		data = append(buf, data...)
+	}
+
+	// A trailing partial line is sent by the follow loop once it is complete.
+	end := bytes.LastIndexByte(data, '\n')
+	if end < 0 {
+		return nil, start, nil
+	}
+	offset := start + int64(end) + 1
+	data = data[:end+1]
+	if start > 0 {
+		// The first line may have started before the read window.
+		data = data[bytes.IndexByte(data, '\n')+1:]
+	}
+	if n == 0 || len(data) == 0 {
+		return nil, offset, nil
+	}
+
+	lines := strings.Split(string(data[:len(data)-1]), "\n")
+	if n > 0 && len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	return lines, offset, nil
+}
+
+// readLogLines reads the complete lines written to path after offset and
+// returns them with the offset to resume from. At most logReadChunkBytes are
+// read per call; a longer line is returned in pieces. A file shorter than
+// offset was truncated and is read from the start.
+func readLogLines(path string, offset int64) ([]string, int64, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, offset, err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return nil, offset, err
+	}
+	if info.Size() < offset {
+		offset = 0
+	}
+	if _, err := file.Seek(offset, io.SeekStart); err != nil {
+		return nil, offset, err
+	}
+	data, err := io.ReadAll(io.LimitReader(file, logReadChunkBytes))
+	if err != nil {
+		return nil, offset, err
+	}
+
+	end := bytes.LastIndexByte(data, '\n')
+	if end < 0 {
+		if len(data) < logReadChunkBytes {
+			return nil, offset, nil
+		}
+		return []string{string(data)}, offset + int64(len(data)), nil
+	}
+	lines := strings.Split(string(data[:end]), "\n")
+	return lines, offset + int64(end) + 1, nil
+}
@@ -0,0 +1,358 @@
+package forged
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	forgedv1 "github.com/tOgg1/forge/gen/forged/v1"
+	"github.com/tOgg1/forge/internal/config"
+	"github.com/tOgg1/forge/internal/db"
+	"github.com/tOgg1/forge/internal/models"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+func newLoopTestServer(t *testing.T) (*Server, *db.DB) {
+	t.Helper()
+	database, err := db.OpenInMemory()
+	if err != nil {
+		t.Fatalf("OpenInMemory() error = %v", err)
+	}
+	t.Cleanup(func() { database.Close() })
+	if err := database.Migrate(context.Background()); err != nil {
+		t.Fatalf("Migrate() error = %v", err)
+	}
+
+	cfg := config.DefaultConfig()
+	cfg.Global.DataDir = t.TempDir()
+	server := NewServer(zerolog.Nop())
+	server.SetLoopSupervisor(NewLoopSupervisor(zerolog.Nop(), server, database, cfg, WithLoopCommand("true")))
+	return server, database
+}
+
+func TestLoopRPCsRequireSupervisor(t *testing.T) {
+	server := NewServer(zerolog.Nop())
+
+	_, err := server.ListLoops(context.Background(), &forgedv1.ListLoopsRequest{})
+	if status.Code(err) != codes.Unavailable {
+		t.Fatalf("ListLoops() code = %v, want %v", status.Code(err), codes.Unavailable)
+	}
+}
+
+func TestLoopRPCs(t *testing.T) {
+	server, database := newLoopTestServer(t)
+	ctx := context.Background()
+	repoPath := t.TempDir()
+
+	created, err := server.CreateLoop(ctx, &forgedv1.CreateLoopRequest{
+		Name:          "build",
+		RepoPath:      repoPath,
+		PromptMsg:     "Fix the build.",
+		MaxIterations: 5,
+		MaxRuntime:    durationpb.New(time.Hour),
+		Tags:          []string{"ci"},
+	})
+	if err != nil {
+		t.Fatalf("CreateLoop() error = %v", err)
+	}
+	if created.Loop.ShortId == "" {
+		t.Fatalf("CreateLoop() loop = %+v, want a short ID", created.Loop)
+	}
+	if created.Loop.State != forgedv1.LoopState_LOOP_STATE_STOPPED {
+		t.Errorf("State = %v, want %v", created.Loop.State, forgedv1.LoopState_LOOP_STATE_STOPPED)
+	}
+
+	_, err = server.CreateLoop(ctx, &forgedv1.CreateLoopRequest{Name: "build", RepoPath: repoPath, MaxIterations: 1, MaxRuntime: durationpb.New(time.Minute)})
+	if status.Code(err) != codes.AlreadyExists {
+		t.Errorf("duplicate CreateLoop() code = %v, want %v", status.Code(err), codes.AlreadyExists)
+	}
+	_, err = server.CreateLoop(ctx, &forgedv1.CreateLoopRequest{Name: "docs", RepoPath: repoPath})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("unbounded CreateLoop() code = %v, want %v", status.Code(err), codes.InvalidArgument)
+	}
+
+	listed, err := server.ListLoops(ctx, &forgedv1.ListLoopsRequest{Tag: "ci", States: []forgedv1.LoopState{forgedv1.LoopState_LOOP_STATE_STOPPED}})
+	if err != nil {
+		t.Fatalf("ListLoops() error = %v", err)
+	}
+	if len(listed.Loops) != 1 || listed.Loops[0].Id != created.Loop.Id {
+		t.Fatalf("ListLoops() = %+v, want the build loop", listed.Loops)
+	}
+	listed, err = server.ListLoops(ctx, &forgedv1.ListLoopsRequest{Tag: "nightly"})
+	if err != nil {
+		t.Fatalf("ListLoops() error = %v", err)
+	}
+	if len(listed.Loops) != 0 {
+		t.Errorf("ListLoops(tag=nightly) = %d loops, want 0", len(listed.Loops))
+	}
+
+	enqueued, err := server.EnqueueLoopItems(ctx, &forgedv1.EnqueueLoopItemsRequest{
+		Loop:  created.Loop.ShortId,
+		Items: []*forgedv1.LoopQueueItem{{Type: string(models.LoopQueueItemMessageAppend), PayloadJson: `{"text":"also run lint"}`}},
+	})
+	if err != nil {
+		t.Fatalf("EnqueueLoopItems() error = %v", err)
+	}
+	if len(enqueued.ItemIds) != 1 {
+		t.Fatalf("ItemIds = %v, want 1 item", enqueued.ItemIds)
+	}
+	_, err = server.EnqueueLoopItems(ctx, &forgedv1.EnqueueLoopItemsRequest{
+		Loop:  "build",
+		Items: []*forgedv1.LoopQueueItem{{Type: "bogus", PayloadJson: `{}`}},
+	})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("invalid EnqueueLoopItems() code = %v, want %v", status.Code(err), codes.InvalidArgument)
+	}
+
+	if _, err := server.StopLoop(ctx, &forgedv1.StopLoopRequest{Loop: "build"}); err != nil {
+		t.Fatalf("StopLoop() error = %v", err)
+	}
+	if _, err := server.KillLoop(ctx, &forgedv1.KillLoopRequest{Loop: created.Loop.Id, Reason: "deploy"}); err != nil {
+		t.Fatalf("KillLoop() error = %v", err)
+	}
+	if _, err := server.StopLoop(ctx, &forgedv1.StopLoopRequest{Loop: "missing"}); status.Code(err) != codes.NotFound {
+		t.Errorf("StopLoop(missing) code = %v, want %v", status.Code(err), codes.NotFound)
+	}
+
+	items, err := db.NewLoopQueueRepository(database).List(ctx, created.Loop.Id)
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	wantTypes := []models.LoopQueueItemType{models.LoopQueueItemMessageAppend, models.LoopQueueItemStopGraceful, models.LoopQueueItemKillNow}
+	if len(items) != len(wantTypes) {
+		t.Fatalf("queue has %d items, want %d", len(items), len(wantTypes))
+	}
+	for i, want := range wantTypes {
+		if items[i].Type != want {
+			t.Errorf("item %d type = %s, want %s", i, items[i].Type, want)
+		}
+	}
+	var kill models.KillPayload
+	if err := json.Unmarshal(items[2].Payload, &kill); err != nil || kill.Reason != "deploy" {
+		t.Errorf("kill payload = %s, want reason deploy", items[2].Payload)
+	}
+}
+
+func TestStreamLoopLogsTail(t *testing.T) {
+	server, database := newLoopTestServer(t)
+	ctx := context.Background()
+
+	created, err := server.CreateLoop(ctx, &forgedv1.CreateLoopRequest{
+		Name:          "build",
+		RepoPath:      t.TempDir(),
+		MaxIterations: 1,
+		MaxRuntime:    durationpb.New(time.Minute),
+	})
+	if err != nil {
+		t.Fatalf("CreateLoop() error = %v", err)
+	}
+	stream := &loopLogsStream{ctx: ctx}
+	err = server.StreamLoopLogs(&forgedv1.StreamLoopLogsRequest{Loop: "build", TailLines: -1}, stream)
+	if status.Code(err) != codes.NotFound {
+		t.Fatalf("StreamLoopLogs() before first run code = %v, want %v", status.Code(err), codes.NotFound)
+	}
+
+	// The runner assigns the log path on its first start.
+	loopRepo := db.NewLoopRepository(database)
+	loopEntry, err := loopRepo.Get(ctx, created.Loop.Id)
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	loopEntry.LogPath = filepath.Join(t.TempDir(), "logs", "build.log")
+	if err := loopRepo.Update(ctx, loopEntry); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(loopEntry.LogPath), 0o755); err != nil {
+		t.Fatalf("MkdirAll() error = %v", err)
+	}
+	if err := os.WriteFile(loopEntry.LogPath, []byte("one\ntwo\nthree\npartial"), 0o644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	stream = &loopLogsStream{ctx: ctx}
+	if err := server.StreamLoopLogs(&forgedv1.StreamLoopLogsRequest{Loop: "build", TailLines: 2}, stream); err != nil {
+		t.Fatalf("StreamLoopLogs() error = %v", err)
+	}
+	if len(stream.sent) != 1 {
+		t.Fatalf("sent %d responses, want 1", len(stream.sent))
+	}
+	if got := stream.sent[0].Lines; len(got) != 2 || got[0] != "two" || got[1] != "three" {
+		t.Errorf("Lines = %v, want [two three]", got)
+	}
+}
+
+func TestReadLogLines(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "loop.log")
+	if err := os.WriteFile(path, []byte("a\nb\npart"), 0o644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	lines, offset, err := readLogLines(path, 0)
+	if err != nil {
+		t.Fatalf("readLogLines() error = %v", err)
+	}
+	if len(lines) != 2 || offset != 4 {
+		t.Fatalf("readLogLines() = %v, %d; want 2 lines at offset 4", lines, offset)
+	}
+
+	if err := os.WriteFile(path, []byte("a\nb\npartial\n"), 0o644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	lines, offset, err = readLogLines(path, offset)
+	if err != nil {
+		t.Fatalf("readLogLines() error = %v", err)
+	}
+	if len(lines) != 1 || lines[0] != "partial" || offset != 12 {
+		t.Fatalf("readLogLines() = %v, %d; want [partial] at offset 12", lines, offset)
+	}
+
+	// Truncated files are read from the start.
+	if err := os.WriteFile(path, []byte("c\n"), 0o644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	lines, _, err = readLogLines(path, offset)
+	if err != nil {
+		t.Fatalf("readLogLines() error = %v", err)
+	}
+	if len(lines) != 1 || lines[0] != "c" {
+		t.Fatalf("readLogLines() after truncate = %v, want [c]", lines)
+	}
+}
+
+func TestTailLogLines(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "loop.log")
+	var log strings.Builder
+	for i := 0; i < 20000; i++ {
+		fmt.Fprintf(&log, "line-%05d\n", i)
+	}
+	log.WriteString("part")
+	if err := os.WriteFile(path, []byte(log.String()), 0o644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	size := int64(log.Len())
+
+	lines, offset, err := tailLogLines(path, 3)
+	if err != nil {
+		t.Fatalf("tailLogLines() error = %v", err)
+	}
+	if len(lines) != 3 || lines[0] != "line-19997" || lines[2] != "line-19999" || offset != size-4 {
+		t.Fatalf("tailLogLines(3) = %v, %d; want the last 3 lines at offset %d", lines, offset, size-4)
+	}
+
+	lines, offset, err = tailLogLines(path, 0)
+	if err != nil || len(lines) != 0 || offset != size-4 {
+		t.Fatalf("tailLogLines(0) = %v, %d, %v; want no lines at offset %d", lines, offset, err, size-4)
+	}
+
+	lines, _, err = tailLogLines(path, -1)
+	if err != nil {
+		t.Fatalf("tailLogLines(-1) error = %v", err)
+	}
+	if len(lines) != 20000 || lines[0] != "line-00000" {
+		t.Fatalf("tailLogLines(-1) = %d lines starting %q, want all 20000", len(lines), lines[0])
+	}
+
+	// Past maxLogTailBytes only whole lines from the last window are sent.
+	big := strings.Repeat("0123456789abcde\n", 2*maxLogTailBytes/16)
+	if err := os.WriteFile(path, []byte(big), 0o644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	lines, offset, err = tailLogLines(path, -1)
+	if err != nil {
+		t.Fatalf("tailLogLines(-1) error = %v", err)
+	}
+	if len(lines) == 0 || len(lines) > maxLogTailBytes/16 || lines[0] != "0123456789abcde" || offset != int64(len(big)) {
+		t.Fatalf("tailLogLines(-1) on a large log = %d lines starting %q at offset %d", len(lines), lines[0], offset)
+	}
+}
+
+func TestRelayLoopRunFinishedEvents(t *testing.T) {
+	server, database := newLoopTestServer(t)
+	ctx := context.Background()
+	since := time.Now().UTC().Add(-time.Minute)
+
+	exitCode := 1
+	payload, err := json.Marshal(models.LoopRunFinishedPayload{
+		LoopName:   "build",
+		RunID:      "run-1",
+		Status:     models.LoopRunStatusError,
+		ExitCode:   &exitCode,
+		DurationMs: 1500,
+		Iteration:  3,
+		Error:      "exit status 1",
+	})
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	if err := db.NewEventRepository(database).Create(ctx, &models.Event{
+		Type:       models.EventTypeLoopRunFinished,
+		EntityType: models.EntityTypeLoop,
+		EntityID:   "loop-1",
+		Payload:    payload,
+	}); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	ls := server.LoopSupervisor()
+	cursor, err := ls.relayEvents(ctx, since, "")
+	if err != nil {
+		t.Fatalf("relayEvents() error = %v", err)
+	}
+	if cursor == "" {
+		t.Fatal("relayEvents() returned an empty cursor")
+	}
+	// Already relayed events are not published again.
+	if _, err := ls.relayEvents(ctx, since, cursor); err != nil {
+		t.Fatalf("relayEvents() error = %v", err)
+	}
+
+	server.eventsMu.RLock()
+	defer server.eventsMu.RUnlock()
+	if len(server.events) != 1 {
+		t.Fatalf("Expected 1 event, got %d", len(server.events))
+	}
+	event := server.events[0].event
+	if event.Type != forgedv1.EventType_EVENT_TYPE_LOOP_RUN_FINISHED || event.LoopId != "loop-1" {
+		t.Fatalf("event = %+v, want loop run finished for loop-1", event)
+	}
+	finished := event.GetLoopRunFinished()
+	if finished == nil {
+		t.Fatal("Expected LoopRunFinished payload")
+	}
+	if finished.RunId != "run-1" || finished.ExitCode != 1 || finished.Iteration != 3 || finished.Duration.AsDuration() != 1500*time.Millisecond {
+		t.Errorf("LoopRunFinished = %+v", finished)
+	}
+
+	match := &eventSubscriber{loopIDs: map[string]bool{"loop-1": true}}
+	other := &eventSubscriber{loopIDs: map[string]bool{"loop-2": true}}
+	if !server.eventMatchesFilter(event, match) {
+		t.Error("event should match its loop ID")
+	}
+	if server.eventMatchesFilter(event, other) {
+		t.Error("event should not match another loop ID")
+	}
+}
+
+// loopLogsStream records StreamLoopLogs responses.
+type loopLogsStream struct {
+	forgedv1.ForgedService_StreamLoopLogsServer
+	ctx  context.Context
+	sent []*forgedv1.StreamLoopLogsResponse
+}
+
+func (s *loopLogsStream) Context() context.Context {
+	return s.ctx
+}
+
+func (s *loopLogsStream) Send(resp *forgedv1.StreamLoopLogsResponse) error {
+	s.sent = append(s.sent, resp)
+	return nil
+}
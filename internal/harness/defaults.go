@@ -25,6 +25,25 @@ func DefaultCommandTemplate(harness models.Harness, model string) string {
 	}
 }
 
+// DefaultStreamCommandTemplate returns the default command template for a
+// harness that emits stream-json output, or "" when it has none.
+func DefaultStreamCommandTemplate(harness models.Harness, model string) string {
+	switch harness {
+	case models.HarnessClaude:
+		// JSON lines stream without a PTY.
+		return "claude -p \"$FORGE_PROMPT_CONTENT\" --output-format stream-json --verbose --dangerously-skip-permissions"
+	case models.HarnessCodex:
+		return "codex exec --json --dangerously-bypass-approvals-and-sandbox -"
+	case models.HarnessOpenCode:
+		if model == "" {
+			model = "anthropic/claude-opus-4-5"
+		}
+		return "opencode run --format json --model " + model + " \"$FORGE_PROMPT_CONTENT\""
+	default:
+		return ""
+	}
+}
+
 // DefaultPromptMode returns the default prompt mode for a harness.
 func DefaultPromptMode(harness models.Harness) models.PromptMode {
 	switch harness {
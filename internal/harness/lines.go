@@ -0,0 +1,53 @@
+package harness
+
+import "bytes"
+
+// maxOutputLineBytes bounds how much of a single output line is buffered while
+// decoding harness output. Longer lines are dropped.
+const maxOutputLineBytes = 4 << 20
+
+// lineBuffer splits streamed output into complete lines.
+type lineBuffer struct {
+	partial  []byte
+	skipping bool
+}
+
+// write feeds data to the buffer, calling fn for every complete line.
+func (b *lineBuffer) write(data []byte, fn func(line []byte)) {
+	rest := data
+	for len(rest) > 0 {
+		idx := bytes.IndexByte(rest, '\n')
+		if idx < 0 {
+			b.buffer(rest)
+			return
+		}
+		b.buffer(rest[:idx])
+		if !b.skipping {
+			fn(b.partial)
+		}
+		b.partial = b.partial[:0]
+		b.skipping = false
+		rest = rest[idx+1:]
+	}
+}
+
+// flush calls fn for a trailing line without a newline.
+func (b *lineBuffer) flush(fn func(line []byte)) {
+	if len(b.partial) > 0 && !b.skipping {
+		fn(b.partial)
+	}
+	b.partial = b.partial[:0]
+	b.skipping = false
+}
+
+func (b *lineBuffer) buffer(chunk []byte) {
+	if b.skipping {
+		return
+	}
+	if len(b.partial)+len(chunk) > maxOutputLineBytes {
+		b.partial = b.partial[:0]
+		b.skipping = true
+		return
+	}
+	b.partial = append(b.partial, chunk...)
+}
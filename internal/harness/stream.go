@@ -0,0 +1,514 @@
+package harness
+
+import (
+	"encoding/json"
+	"io"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/tOgg1/forge/internal/models"
+)
+
+const (
+	// maxStreamEvents caps the structured events kept for one run; later events
+	// are still rendered into the log.
+	maxStreamEvents = 5000
+	// maxEventTextBytes caps the text stored on a single event.
+	maxEventTextBytes = 4000
+	// maxSummaryBytes caps one-line summaries of tool inputs and errors.
+	maxSummaryBytes = 200
+)
+
+// StreamParser decodes a harness's streaming JSON output into structured run
+// events and renders them as readable text into the loop log. Lines that are
+// not JSON events (stderr, wrapper scripts) are passed through unchanged.
+//
+// Supported formats:
+//   - Claude `-p --output-format stream-json --verbose`
+//   - Codex `exec --json`
+//   - OpenCode `run --format json`
+type StreamParser struct {
+	mu     sync.Mutex
+	lines  lineBuffer
+	out    io.Writer
+	decode func(p *StreamParser, line []byte) bool
+
+	events      []models.LoopRunEvent
+	calls       map[string]int
+	lastMessage string
+	result      string
+	hasResult   bool
+}
+
+// NewStreamParser returns a parser for the harness's stream-json output that
+// renders into out, or nil when the harness has no supported stream format.
+func NewStreamParser(harness models.Harness, out io.Writer) *StreamParser {
+	parser := &StreamParser{out: out, calls: make(map[string]int)}
+	switch harness {
+	case models.HarnessClaude:
+		parser.decode = (*StreamParser).decodeClaude
+	case models.HarnessCodex:
+		parser.decode = (*StreamParser).decodeCodex
+	case models.HarnessOpenCode:
+		parser.decode = (*StreamParser).decodeOpenCode
+	default:
+		return nil
+	}
+	return parser
+}
+
+// Write consumes harness output.
+func (p *StreamParser) Write(data []byte) (int, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.lines.write(data, p.parseLine)
+	return len(data), nil
+}
+
+// Flush processes a trailing line that did not end in a newline.
+func (p *StreamParser) Flush() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.lines.flush(p.parseLine)
+}
+
+// Events returns the structured events decoded so far.
+func (p *StreamParser) Events() []models.LoopRunEvent {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return append([]models.LoopRunEvent(nil), p.events...)
+}
+
+// Result returns the agent's final result text; ok is false until the stream
+// reported one.
+func (p *StreamParser) Result() (string, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.result, p.hasResult
+}
+
+// FormatEvent renders an event as log text.
+func FormatEvent(event models.LoopRunEvent) string {
+	switch event.Kind {
+	case models.LoopRunEventMessage:
+		if event.IsError {
+			return "[error] " + event.Text
+		}
+		return event.Text
+	case models.LoopRunEventToolCall:
+		line := "[tool] " + event.Tool
+		if event.Text != "" {
+			line += ": " + event.Text
+		}
+		if event.IsError {
+			line += " (failed)"
+		}
+		return line
+	case models.LoopRunEventFileEdit:
+		line := "[edit] " + event.Tool + " " + event.Path
+		if event.IsError {
+			line += " (failed)"
+		}
+		return line
+	case models.LoopRunEventResult:
+		status := "success"
+		if event.IsError {
+			status = "error"
+		}
+		if event.Text == "" {
+			return "[result] " + status
+		}
+		return "[result] " + status + ": " + summarize(event.Text)
+	default:
+		return event.Text
+	}
+}
+
+func (p *StreamParser) parseLine(raw []byte) {
+	line := strings.TrimSpace(ansiEscape.ReplaceAllString(string(raw), ""))
+	if strings.HasPrefix(line, "{") && p.decode(p, []byte(line)) {
+		return
+	}
+	p.writeLine(string(raw))
+}
+
+func (p *StreamParser) writeLine(text string) {
+	if p.out == nil {
+		return
+	}
+	_, _ = io.WriteString(p.out, strings.TrimRight(text, "\r\n")+"\n")
+}
+
+// emit records an event and renders it; it returns the event's index, or -1
+// when the event list is full.
+func (p *StreamParser) emit(event models.LoopRunEvent) int {
+	event.Text = truncate(strings.TrimSpace(event.Text), maxEventTextBytes)
+	p.writeLine(FormatEvent(event))
+	if event.Kind == models.LoopRunEventMessage && !event.IsError {
+		p.lastMessage = event.Text
+	}
+	if len(p.events) >= maxStreamEvents {
+		return -1
+	}
+	p.events = append(p.events, event)
+	return len(p.events) - 1
+}
+
+func (p *StreamParser) message(text string) {
+	if strings.TrimSpace(text) == "" {
+		return
+	}
+	p.emit(models.LoopRunEvent{Kind: models.LoopRunEventMessage, Text: text})
+}
+
+func (p *StreamParser) streamError(text string) {
+	if strings.TrimSpace(text) == "" {
+		return
+	}
+	p.emit(models.LoopRunEvent{Kind: models.LoopRunEventMessage, Text: text, IsError: true})
+}
+
+// toolCall records a tool call, as a file edit when the tool edits a file.
+func (p *StreamParser) toolCall(id, tool string, input json.RawMessage) {
+	fields := decodeToolInput(input)
+	event := models.LoopRunEvent{Kind: models.LoopRunEventToolCall, Tool: tool}
+	if path := editedPath(tool, fields); path != "" {
+		event.Kind = models.LoopRunEventFileEdit
+		event.Path = path
+	} else {
+		event.Text = summarizeToolInput(fields, input)
+	}
+	index := p.emit(event)
+	if id != "" && index >= 0 {
+		p.calls[id] = index
+	}
+}
+
+// failTool marks an earlier tool call as failed.
+func (p *StreamParser) failTool(id, text string) {
+	index, ok := p.calls[id]
+	if !ok {
+		return
+	}
+	event := &p.events[index]
+	event.IsError = true
+	line := "[tool error] " + event.Tool
+	if text = summarize(text); text != "" {
+		line += ": " + text
+	}
+	p.writeLine(line)
+}
+
+func (p *StreamParser) finish(text string, isError bool) {
+	if strings.TrimSpace(text) == "" && !isError {
+		text = p.lastMessage
+	}
+	p.result = strings.TrimSpace(text)
+	p.hasResult = true
+	p.emit(models.LoopRunEvent{Kind: models.LoopRunEventResult, Text: text, IsError: isError})
+}
+
+type claudeStreamEvent struct {
+	Type    string         `json:"type"`
+	Subtype string         `json:"subtype"`
+	Message *claudeMessage `json:"message"`
+	Result  string         `json:"result"`
+	IsError bool           `json:"is_error"`
+}
+
+type claudeMessage struct {
+	Content json.RawMessage `json:"content"`
+}
+
+type claudeContentBlock struct {
+	Type      string          `json:"type"`
+	Text      string          `json:"text"`
+	ID        string          `json:"id"`
+	Name      string          `json:"name"`
+	Input     json.RawMessage `json:"input"`
+	ToolUseID string          `json:"tool_use_id"`
+	Content   json.RawMessage `json:"content"`
+	IsError   bool            `json:"is_error"`
+}
+
+func (p *StreamParser) decodeClaude(line []byte) bool {
+	var event claudeStreamEvent
+	if err := json.Unmarshal(line, &event); err != nil || event.Type == "" {
+		return false
+	}
+
+	switch event.Type {
+	case "assistant":
+		for _, block := range claudeBlocks(event.Message) {
+			switch block.Type {
+			case "text":
+				p.message(block.Text)
+			case "tool_use":
+				p.toolCall(block.ID, block.Name, block.Input)
+			}
+		}
+	case "user":
+		for _, block := range claudeBlocks(event.Message) {
+			if block.Type == "tool_result" && block.IsError {
+				p.failTool(block.ToolUseID, contentText(block.Content))
+			}
+		}
+	case "result":
+		p.finish(event.Result, event.IsError || (event.Subtype != "" && event.Subtype != "success"))
+	}
+	return true
+}
+
+func claudeBlocks(message *claudeMessage) []claudeContentBlock {
+	if message == nil || len(message.Content) == 0 {
+		return nil
+	}
+	var blocks []claudeContentBlock
+	if err := json.Unmarshal(message.Content, &blocks); err != nil {
+		var text string
+		if json.Unmarshal(message.Content, &text) == nil {
+			return []claudeContentBlock{{Type: "text", Text: text}}
+		}
+		return nil
+	}
+	return blocks
+}
+
+type codexStreamEvent struct {
+	Type    string     `json:"type"`
+	Item    *codexItem `json:"item"`
+	Message string     `json:"message"`
+	Error   *struct {
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+type codexItem struct {
+	ID       string `json:"id"`
+	Type     string `json:"type"`
+	ItemType string `json:"item_type"`
+	Text     string `json:"text"`
+	Command  string `json:"command"`
+	ExitCode *int   `json:"exit_code"`
+	Status   string `json:"status"`
+	Changes  []struct {
+		Path string `json:"path"`
+		Kind string `json:"kind"`
+	} `json:"changes"`
+	Server  string `json:"server"`
+	Tool    string `json:"tool"`
+	Query   string `json:"query"`
+	Message string `json:"message"`
+}
+
+func (p *StreamParser) decodeCodex(line []byte) bool {
+	var event codexStreamEvent
+	if err := json.Unmarshal(line, &event); err != nil || event.Type == "" {
+		return false
+	}
+
+	switch event.Type {
+	case "item.completed":
+		if event.Item != nil {
+			p.codexItem(event.Item)
+		}
+	case "turn.completed":
+		p.finish("", false)
+	case "turn.failed":
+		message := ""
+		if event.Error != nil {
+			message = event.Error.Message
+		}
+		p.finish(message, true)
+	case "error":
+		p.streamError(event.Message)
+	}
+	return true
+}
+
+func (p *StreamParser) codexItem(item *codexItem) {
+	failed := item.Status == "failed"
+	kind := firstNonEmpty(item.Type, item.ItemType)
+	switch kind {
+	case "agent_message", "assistant_message":
+		p.message(item.Text)
+	case "command_execution":
+		isError := failed || (item.ExitCode != nil && *item.ExitCode != 0)
+		p.emit(models.LoopRunEvent{Kind: models.LoopRunEventToolCall, Tool: "shell", Text: summarize(item.Command), IsError: isError})
+	case "file_change":
+		for _, change := range item.Changes {
+			p.emit(models.LoopRunEvent{Kind: models.LoopRunEventFileEdit, Tool: firstNonEmpty(change.Kind, "update"), Path: change.Path, IsError: failed})
+		}
+	case "mcp_tool_call":
+		tool := item.Tool
+		if item.Server != "" {
+			tool = item.Server + "." + item.Tool
+		}
+		p.emit(models.LoopRunEvent{Kind: models.LoopRunEventToolCall, Tool: tool, IsError: failed})
+	case "web_search":
+		p.emit(models.LoopRunEvent{Kind: models.LoopRunEventToolCall, Tool: "web_search", Text: summarize(item.Query)})
+	case "error":
+		p.streamError(item.Message)
+	}
+}
+
+type openCodeStreamEvent struct {
+	Type string `json:"type"`
+	Part *struct {
+		Type   string `json:"type"`
+		Text   string `json:"text"`
+		Tool   string `json:"tool"`
+		CallID string `json:"callID"`
+		Reason string `json:"reason"`
+		State  *struct {
+			Status string          `json:"status"`
+			Input  json.RawMessage `json:"input"`
+			Error  string          `json:"error"`
+		} `json:"state"`
+	} `json:"part"`
+	Error *struct {
+		Name string `json:"name"`
+		Data struct {
+			Message string `json:"message"`
+		} `json:"data"`
+	} `json:"error"`
+}
+
+func (p *StreamParser) decodeOpenCode(line []byte) bool {
+	var event openCodeStreamEvent
+	if err := json.Unmarshal(line, &event); err != nil || event.Type == "" {
+		return false
+	}
+
+	switch event.Type {
+	case "text":
+		if event.Part != nil {
+			p.message(event.Part.Text)
+		}
+	case "tool_use":
+		if event.Part == nil {
+			break
+		}
+		var input json.RawMessage
+		failed := false
+		if state := event.Part.State; state != nil {
+			input = state.Input
+			failed = state.Status == "error"
+		}
+		p.toolCall(event.Part.CallID, event.Part.Tool, input)
+		if failed {
+			p.failTool(event.Part.CallID, event.Part.State.Error)
+		}
+	case "step_finish":
+		if event.Part != nil && event.Part.Reason == "stop" {
+			p.finish("", false)
+		}
+	case "error":
+		message := "error"
+		if event.Error != nil {
+			message = firstNonEmpty(event.Error.Data.Message, event.Error.Name, message)
+		}
+		p.finish(message, true)
+	}
+	return true
+}
+
+// editTools maps tool names (lower-cased) that edit files across harnesses.
+var editTools = map[string]struct{}{
+	"edit":         {},
+	"multiedit":    {},
+	"write":        {},
+	"notebookedit": {},
+	"patch":        {},
+}
+
+// toolInputKeys are the inputs that best summarise a tool call, in order.
+var toolInputKeys = []string{"command", "cmd", "file_path", "filePath", "path", "notebook_path", "pattern", "url", "query", "description", "prompt"}
+
+func decodeToolInput(input json.RawMessage) map[string]any {
+	if len(input) == 0 {
+		return nil
+	}
+	var fields map[string]any
+	if err := json.Unmarshal(input, &fields); err != nil {
+		return nil
+	}
+	return fields
+}
+
+func editedPath(tool string, fields map[string]any) string {
+	if _, ok := editTools[strings.ToLower(tool)]; !ok {
+		return ""
+	}
+	for _, key := range []string{"file_path", "filePath", "notebook_path", "path"} {
+		if value, ok := fields[key].(string); ok && value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+func summarizeToolInput(fields map[string]any, input json.RawMessage) string {
+	for _, key := range toolInputKeys {
+		if value, ok := fields[key].(string); ok && value != "" {
+			return summarize(value)
+		}
+	}
+	if len(fields) == 0 {
+		return ""
+	}
+	return summarize(string(input))
+}
+
+// contentText flattens a tool result, which is either a string or a list of
+// content blocks.
+func contentText(content json.RawMessage) string {
+	if len(content) == 0 {
+		return ""
+	}
+	var text string
+	if err := json.Unmarshal(content, &text); err == nil {
+		return text
+	}
+	var blocks []claudeContentBlock
+	if err := json.Unmarshal(content, &blocks); err != nil {
+		return ""
+	}
+	parts := make([]string, 0, len(blocks))
+	for _, block := range blocks {
+		if block.Text != "" {
+			parts = append(parts, block.Text)
+		}
+	}
+	return strings.Join(parts, "\n")
+}
+
+// summarize collapses text onto one bounded line.
+func summarize(text string) string {
+	return truncate(strings.Join(strings.Fields(text), " "), maxSummaryBytes)
+}
+
+func truncate(text string, limit int) string {
+	if len(text) <= limit {
+		return text
+	}
+	cut := limit
+	for cut > 0 && !utf8.RuneStart(text[cut]) {
+		cut--
+	}
+	return text[:cut] + "..."
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+	return ""
+}
@@ -0,0 +1,139 @@
+package harness
+
+import (
+	"bytes"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/tOgg1/forge/internal/models"
+)
+
+func TestStreamParserFormats(t *testing.T) {
+	tests := []struct {
+		name     string
+		harness  models.Harness
+		output   string
+		want     []models.LoopRunEvent
+		rendered []string
+		result   string
+	}{
+		{
+			name:    "claude",
+			harness: models.HarnessClaude,
+			output: `{"type":"system","subtype":"init","model":"claude-sonnet"}
+{"type":"assistant","message":{"content":[{"type":"text","text":"Running the tests."},{"type":"tool_use","id":"t1","name":"Bash","input":{"command":"go test ./...","description":"Run tests"}}]}}
+{"type":"user","message":{"content":[{"type":"tool_result","tool_use_id":"t1","is_error":true,"content":"exit status 1\nFAIL"}]}}
+{"type":"assistant","message":{"content":[{"type":"tool_use","id":"t2","name":"Edit","input":{"file_path":"internal/foo.go","old_string":"a","new_string":"b"}}]}}
+{"type":"user","message":{"content":[{"type":"tool_result","tool_use_id":"t2","content":[{"type":"text","text":"ok"}]}]}}
+{"type":"result","subtype":"success","is_error":false,"result":"Fixed the failing test.","total_cost_usd":0.01}
+`,
+			want: []models.LoopRunEvent{
+				{Kind: models.LoopRunEventMessage, Text: "Running the tests."},
+				{Kind: models.LoopRunEventToolCall, Tool: "Bash", Text: "go test ./...", IsError: true},
+				{Kind: models.LoopRunEventFileEdit, Tool: "Edit", Path: "internal/foo.go"},
+				{Kind: models.LoopRunEventResult, Text: "Fixed the failing test."},
+			},
+			rendered: []string{
+				"Running the tests.",
+				"[tool] Bash: go test ./...",
+				"[tool error] Bash: exit status 1 FAIL",
+				"[edit] Edit internal/foo.go",
+				"[result] success: Fixed the failing test.",
+			},
+			result: "Fixed the failing test.",
+		},
+		{
+			name:    "codex",
+			harness: models.HarnessCodex,
+			output: `{"type":"thread.started","thread_id":"th_1"}
+{"type":"turn.started"}
+{"type":"item.started","item":{"id":"item_0","type":"command_execution","command":"bash -lc ls","status":"in_progress"}}
+{"type":"item.completed","item":{"id":"item_0","type":"command_execution","command":"bash -lc ls","aggregated_output":"go.mod\n","exit_code":0,"status":"completed"}}
+{"type":"item.completed","item":{"id":"item_1","type":"file_change","changes":[{"path":"README.md","kind":"update"}],"status":"completed"}}
+{"type":"item.completed","item":{"id":"item_2","type":"agent_message","text":"Updated the README."}}
+{"type":"turn.completed","usage":{"input_tokens":10,"output_tokens":5}}
+`,
+			want: []models.LoopRunEvent{
+				{Kind: models.LoopRunEventToolCall, Tool: "shell", Text: "bash -lc ls"},
+				{Kind: models.LoopRunEventFileEdit, Tool: "update", Path: "README.md"},
+				{Kind: models.LoopRunEventMessage, Text: "Updated the README."},
+				{Kind: models.LoopRunEventResult, Text: "Updated the README."},
+			},
+			rendered: []string{
+				"[tool] shell: bash -lc ls",
+				"[edit] update README.md",
+				"Updated the README.",
+				"[result] success: Updated the README.",
+			},
+			result: "Updated the README.",
+		},
+		{
+			name:    "opencode",
+			harness: models.HarnessOpenCode,
+			output: `{"type":"step_start","part":{"type":"step-start"}}
+{"type":"tool_use","part":{"type":"tool","tool":"read","callID":"c1","state":{"status":"completed","input":{"filePath":"main.go"}}}}
+{"type":"tool_use","part":{"type":"tool","tool":"write","callID":"c2","state":{"status":"error","input":{"filePath":"main.go","content":"x"},"error":"permission denied"}}}
+{"type":"step_finish","part":{"type":"step-finish","reason":"tool-calls"}}
+{"type":"text","part":{"type":"text","text":"1"}}
+{"type":"step_finish","part":{"type":"step-finish","reason":"stop"}}
+`,
+			want: []models.LoopRunEvent{
+				{Kind: models.LoopRunEventToolCall, Tool: "read", Text: "main.go"},
+				{Kind: models.LoopRunEventFileEdit, Tool: "write", Path: "main.go", IsError: true},
+				{Kind: models.LoopRunEventMessage, Text: "1"},
+				{Kind: models.LoopRunEventResult, Text: "1"},
+			},
+			rendered: []string{
+				"[tool] read: main.go",
+				"[edit] write main.go",
+				"[tool error] write: permission denied",
+				"1",
+				"[result] success: 1",
+			},
+			result: "1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var rendered bytes.Buffer
+			parser := NewStreamParser(tt.harness, &rendered)
+			for _, chunk := range splitEvery(tt.output, 11) {
+				_, _ = io.WriteString(parser, chunk)
+			}
+			parser.Flush()
+
+			if got := parser.Events(); !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("events = %+v, want %+v", got, tt.want)
+			}
+			if got := strings.Split(strings.TrimSpace(rendered.String()), "\n"); !reflect.DeepEqual(got, tt.rendered) {
+				t.Fatalf("rendered = %q, want %q", got, tt.rendered)
+			}
+			if result, ok := parser.Result(); !ok || result != tt.result {
+				t.Fatalf("result = %q (ok=%v), want %q", result, ok, tt.result)
+			}
+		})
+	}
+}
+
+func TestStreamParserPassesThroughText(t *testing.T) {
+	var rendered bytes.Buffer
+	parser := NewStreamParser(models.HarnessClaude, &rendered)
+	_, _ = io.WriteString(parser, "warning: something on stderr\n{not json}\n{\"type\":\"result\",\"subtype\":\"error_max_turns\",\"is_error\":true}")
+	parser.Flush()
+
+	want := "warning: something on stderr\n{not json}\n[result] error\n"
+	if rendered.String() != want {
+		t.Fatalf("rendered = %q, want %q", rendered.String(), want)
+	}
+	events := parser.Events()
+	if len(events) != 1 || events[0].Kind != models.LoopRunEventResult || !events[0].IsError {
+		t.Fatalf("expected a failed result event, got %+v", events)
+	}
+
+	if NewStreamParser(models.HarnessPi, &rendered) != nil {
+		t.Fatalf("expected no stream parser for pi")
+	}
+}
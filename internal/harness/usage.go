@@ -0,0 +1,229 @@
+package harness
+
+import (
+	"encoding/json"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/tOgg1/forge/internal/models"
+)
+
+// UsageTrailerPrefix marks a line reporting usage for harnesses (or wrapper
+// scripts) without a native usage format, e.g.:
+//
+//	FORGE_USAGE: {"input_tokens": 1200, "output_tokens": 300, "cost_usd": 0.012}
+const UsageTrailerPrefix = "FORGE_USAGE:"
+
+var (
+	codexTokensUsed = regexp.MustCompile(`(?i)^tokens used:?\s*([\d,]+)$`)
+	ansiEscape      = regexp.MustCompile(`\x1b\[[0-9;?]*[A-Za-z]`)
+)
+
+// UsageParser scans harness output line by line for usage reports. It
+// understands:
+//   - Claude `--output-format json|stream-json` result events
+//   - Codex `exec --json` turn.completed events and its "tokens used" trailer
+//   - OpenCode `run --format json` step_finish events
+//   - Pi `--mode json` assistant message_end events
+//   - FORGE_USAGE trailer lines from any command
+//
+// It is an io.Writer so it can sit alongside the loop log.
+type UsageParser struct {
+	mu          sync.Mutex
+	lines       lineBuffer
+	usage       models.LoopRunUsage
+	found       bool
+	final       bool
+	pendingText bool
+}
+
+// NewUsageParser returns an empty usage parser.
+func NewUsageParser() *UsageParser {
+	return &UsageParser{}
+}
+
+// Write consumes harness output.
+func (p *UsageParser) Write(data []byte) (int, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.lines.write(data, p.parseLine)
+	return len(data), nil
+}
+
+// Usage returns the usage found so far; ok is false when the output held no
+// usage report.
+func (p *UsageParser) Usage() (models.LoopRunUsage, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.lines.flush(p.parseLine)
+	return p.usage, p.found
+}
+
+type usageEvent struct {
+	Type string `json:"type"`
+
+	// Claude result events and Codex turn.completed events.
+	Usage *struct {
+		InputTokens              int64 `json:"input_tokens"`
+		OutputTokens             int64 `json:"output_tokens"`
+		CacheReadInputTokens     int64 `json:"cache_read_input_tokens"`
+		CacheCreationInputTokens int64 `json:"cache_creation_input_tokens"`
+		CachedInputTokens        int64 `json:"cached_input_tokens"`
+	} `json:"usage"`
+	TotalCostUSD *float64 `json:"total_cost_usd"`
+
+	// OpenCode step_finish events.
+	Part *struct {
+		Tokens *struct {
+			Input     int64 `json:"input"`
+			Output    int64 `json:"output"`
+			Reasoning int64 `json:"reasoning"`
+			Cache     struct {
+				Read  int64 `json:"read"`
+				Write int64 `json:"write"`
+			} `json:"cache"`
+		} `json:"tokens"`
+		Cost float64 `json:"cost"`
+	} `json:"part"`
+
+	// Pi message_end events.
+	Message *struct {
+		Role  string `json:"role"`
+		Usage *struct {
+			Input       int64 `json:"input"`
+			Output      int64 `json:"output"`
+			CacheRead   int64 `json:"cacheRead"`
+			CacheWrite  int64 `json:"cacheWrite"`
+			TotalTokens int64 `json:"totalTokens"`
+			Cost        struct {
+				Total float64 `json:"total"`
+			} `json:"cost"`
+		} `json:"usage"`
+	} `json:"message"`
+}
+
+func (p *UsageParser) parseLine(raw []byte) {
+	line := strings.TrimSpace(ansiEscape.ReplaceAllString(string(raw), ""))
+	if line == "" {
+		return
+	}
+
+	if strings.HasPrefix(line, UsageTrailerPrefix) {
+		var usage models.LoopRunUsage
+		if err := json.Unmarshal([]byte(strings.TrimSpace(strings.TrimPrefix(line, UsageTrailerPrefix))), &usage); err == nil {
+			p.set(withTotal(usage, usage.InputTokens+usage.OutputTokens+usage.CacheReadTokens+usage.CacheWriteTokens))
+		}
+		return
+	}
+
+	if strings.HasPrefix(line, "{") {
+		var event usageEvent
+		if err := json.Unmarshal([]byte(line), &event); err != nil {
+			return
+		}
+		p.applyEvent(event)
+		return
+	}
+
+	// Codex prints "tokens used" followed by the count, on one line or two.
+	if strings.EqualFold(line, "tokens used") {
+		p.pendingText = true
+		return
+	}
+	text := line
+	if p.pendingText {
+		p.pendingText = false
+		text = "tokens used " + line
+	}
+	if match := codexTokensUsed.FindStringSubmatch(text); match != nil && !p.final {
+		if total, err := strconv.ParseInt(strings.ReplaceAll(match[1], ",", ""), 10, 64); err == nil {
+			p.usage = models.LoopRunUsage{TotalTokens: total}
+			p.found = true
+		}
+	}
+}
+
+func (p *UsageParser) applyEvent(event usageEvent) {
+	switch event.Type {
+	case "result":
+		// Claude reports cumulative usage for the whole session.
+		if event.Usage == nil {
+			return
+		}
+		usage := models.LoopRunUsage{
+			InputTokens:      event.Usage.InputTokens,
+			OutputTokens:     event.Usage.OutputTokens,
+			CacheReadTokens:  event.Usage.CacheReadInputTokens,
+			CacheWriteTokens: event.Usage.CacheCreationInputTokens,
+		}
+		if event.TotalCostUSD != nil {
+			usage.CostUSD = *event.TotalCostUSD
+		}
+		p.set(withTotal(usage, usage.InputTokens+usage.OutputTokens+usage.CacheReadTokens+usage.CacheWriteTokens))
+	case "turn.completed":
+		if event.Usage == nil {
+			return
+		}
+		// Codex cached input tokens are a subset of input tokens.
+		usage := models.LoopRunUsage{
+			InputTokens:     event.Usage.InputTokens,
+			OutputTokens:    event.Usage.OutputTokens,
+			CacheReadTokens: event.Usage.CachedInputTokens,
+		}
+		p.add(withTotal(usage, usage.InputTokens+usage.OutputTokens))
+	case "step_finish":
+		if event.Part == nil || event.Part.Tokens == nil {
+			return
+		}
+		tokens := event.Part.Tokens
+		usage := models.LoopRunUsage{
+			InputTokens:      tokens.Input,
+			OutputTokens:     tokens.Output + tokens.Reasoning,
+			CacheReadTokens:  tokens.Cache.Read,
+			CacheWriteTokens: tokens.Cache.Write,
+			CostUSD:          event.Part.Cost,
+		}
+		p.add(withTotal(usage, usage.InputTokens+usage.OutputTokens+usage.CacheReadTokens+usage.CacheWriteTokens))
+	case "message_end":
+		if event.Message == nil || event.Message.Role != "assistant" || event.Message.Usage == nil {
+			return
+		}
+		msg := event.Message.Usage
+		usage := models.LoopRunUsage{
+			InputTokens:      msg.Input,
+			OutputTokens:     msg.Output,
+			CacheReadTokens:  msg.CacheRead,
+			CacheWriteTokens: msg.CacheWrite,
+			TotalTokens:      msg.TotalTokens,
+			CostUSD:          msg.Cost.Total,
+		}
+		p.add(withTotal(usage, usage.InputTokens+usage.OutputTokens+usage.CacheReadTokens+usage.CacheWriteTokens))
+	}
+}
+
+// set replaces any usage seen so far with a final, cumulative report.
+func (p *UsageParser) set(usage models.LoopRunUsage) {
+	p.usage = usage
+	p.found = true
+	p.final = true
+}
+
+// add accumulates a per-turn report unless a final report was already seen.
+func (p *UsageParser) add(usage models.LoopRunUsage) {
+	if p.final {
+		return
+	}
+	p.usage.Add(usage)
+	p.found = true
+}
+
+func withTotal(usage models.LoopRunUsage, total int64) models.LoopRunUsage {
+	if usage.TotalTokens == 0 {
+		usage.TotalTokens = total
+	}
+	return usage
+}
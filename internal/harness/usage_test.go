@@ -0,0 +1,100 @@
+package harness
+
+import (
+	"io"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/tOgg1/forge/internal/models"
+)
+
+func TestUsageParserFormats(t *testing.T) {
+	tests := []struct {
+		name   string
+		output string
+		want   models.LoopRunUsage
+	}{
+		{
+			name: "claude result",
+			output: `{"type":"system","subtype":"init","model":"claude-sonnet"}
+{"type":"result","subtype":"success","total_cost_usd":0.0421,"usage":{"input_tokens":12,"output_tokens":340,"cache_read_input_tokens":1000,"cache_creation_input_tokens":200}}
+`,
+			want: models.LoopRunUsage{InputTokens: 12, OutputTokens: 340, CacheReadTokens: 1000, CacheWriteTokens: 200, TotalTokens: 1552, CostUSD: 0.0421},
+		},
+		{
+			name: "codex json turns",
+			output: `{"type":"turn.completed","usage":{"input_tokens":100,"cached_input_tokens":40,"output_tokens":10}}
+{"type":"turn.completed","usage":{"input_tokens":200,"cached_input_tokens":0,"output_tokens":20}}`,
+			want: models.LoopRunUsage{InputTokens: 300, OutputTokens: 30, CacheReadTokens: 40, TotalTokens: 330},
+		},
+		{
+			name:   "codex text trailer",
+			output: "codex\nall done\ntokens used\n12,345\n",
+			want:   models.LoopRunUsage{TotalTokens: 12345},
+		},
+		{
+			name: "opencode steps",
+			output: `{"type":"step_finish","part":{"tokens":{"input":10,"output":5,"reasoning":2,"cache":{"read":100,"write":0}},"cost":0.01}}
+{"type":"step_finish","part":{"tokens":{"input":20,"output":5,"reasoning":0,"cache":{"read":0,"write":50}},"cost":0.02}}`,
+			want: models.LoopRunUsage{InputTokens: 30, OutputTokens: 12, CacheReadTokens: 100, CacheWriteTokens: 50, TotalTokens: 192, CostUSD: 0.03},
+		},
+		{
+			name: "pi messages",
+			output: `{"type":"message_end","message":{"role":"user"}}
+{"type":"message_end","message":{"role":"assistant","usage":{"input":50,"output":7,"cacheRead":0,"cacheWrite":0,"totalTokens":57,"cost":{"total":0.005}}}}`,
+			want: models.LoopRunUsage{InputTokens: 50, OutputTokens: 7, TotalTokens: 57, CostUSD: 0.005},
+		},
+		{
+			name:   "trailer",
+			output: "work work\r\nFORGE_USAGE: {\"input_tokens\": 1200, \"output_tokens\": 300, \"cost_usd\": 0.5}",
+			want:   models.LoopRunUsage{InputTokens: 1200, OutputTokens: 300, TotalTokens: 1500, CostUSD: 0.5},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parser := NewUsageParser()
+			// Split writes mid-line to exercise line buffering.
+			for _, chunk := range splitEvery(tt.output, 7) {
+				_, _ = io.WriteString(parser, chunk)
+			}
+			got, ok := parser.Usage()
+			if !ok {
+				t.Fatalf("expected usage to be found")
+			}
+			if math.Abs(got.CostUSD-tt.want.CostUSD) > 1e-9 {
+				t.Fatalf("cost = %v, want %v", got.CostUSD, tt.want.CostUSD)
+			}
+			got.CostUSD = tt.want.CostUSD
+			if got != tt.want {
+				t.Fatalf("usage = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+
+	parser := NewUsageParser()
+	_, _ = io.WriteString(parser, "plain output\n{\"type\":\"assistant\"}\n")
+	if _, ok := parser.Usage(); ok {
+		t.Fatalf("expected no usage in plain output")
+	}
+}
+
+func splitEvery(value string, n int) []string {
+	chunks := make([]string, 0, len(value)/n+1)
+	for len(value) > n {
+		chunks = append(chunks, value[:n])
+		value = value[n:]
+	}
+	return append(chunks, value)
+}
+
+func TestUsageParserSkipsOversizedLines(t *testing.T) {
+	parser := NewUsageParser()
+	_, _ = io.WriteString(parser, strings.Repeat("x", maxOutputLineBytes+1))
+	_, _ = io.WriteString(parser, "\ntokens used: 42\n")
+	usage, ok := parser.Usage()
+	if !ok || usage.TotalTokens != 42 {
+		t.Fatalf("expected usage after an oversized line, got %+v (ok=%v)", usage, ok)
+	}
+}
@@ -0,0 +1,303 @@
+package hooks
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/tOgg1/forge/internal/models"
+)
+
+// DefaultRetryInterval is how often Start looks for due retries.
+const DefaultRetryInterval = 15 * time.Second
+
+// maxRetryBatch bounds the deliveries retried per pass.
+const maxRetryBatch = 100
+
+// ErrNoDeliveryQueue is returned by operations that need the delivery queue
+// when the manager was created without a delivery repository.
+var ErrNoDeliveryQueue = errors.New("hook delivery queue unavailable")
+
+// RetryPolicy controls how failed deliveries are retried.
+type RetryPolicy struct {
+	// MaxAttempts is the number of attempts before a delivery is dead-lettered.
+	MaxAttempts int
+
+	// InitialBackoff is the wait after the first failed attempt. It doubles
+	// with every further failure.
+	InitialBackoff time.Duration
+
+	// MaxBackoff caps the wait between attempts.
+	MaxBackoff time.Duration
+}
+
+// DefaultRetryPolicy retries for roughly an hour before giving up.
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts:    8,
+		InitialBackoff: 30 * time.Second,
+		MaxBackoff:     time.Hour,
+	}
+}
+
+// Backoff returns the wait before the next attempt after attempts failures.
+func (p RetryPolicy) Backoff(attempts int) time.Duration {
+	backoff := p.InitialBackoff
+	for i := 1; i < attempts && backoff < p.MaxBackoff; i++ {
+		backoff *= 2
+	}
+	if p.MaxBackoff > 0 && backoff > p.MaxBackoff {
+		backoff = p.MaxBackoff
+	}
+	return backoff
+}
+
+// Test queues a synthetic hook.test event for a hook and attempts it right
+// away. Disabled hooks can be tested too.
+func (m *Manager) Test(ctx context.Context, hookID string) (*models.HookDelivery, error) {
+	if m.deliveries == nil {
+		return nil, ErrNoDeliveryQueue
+	}
+
+	hook, err := m.store.Get(hookID)
+	if err != nil {
+		return nil, err
+	}
+
+	payload, err := json.Marshal(map[string]string{
+		"hook_id": hook.ID,
+		"message": "test event from forge hook test",
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal test payload: %w", err)
+	}
+	event := &models.Event{
+		ID:         uuid.New().String(),
+		Timestamp:  m.now().UTC(),
+		Type:       models.EventTypeHookTest,
+		EntityType: models.EntityTypeSystem,
+		EntityID:   hook.ID,
+		Payload:    payload,
+	}
+
+	delivery, err := m.enqueue(ctx, hook, event, "")
+	if err != nil {
+		return nil, err
+	}
+	return delivery, m.attempt(ctx, hook, delivery)
+}
+
+// Replay queues the event of an earlier delivery again and attempts it right
+// away. The original delivery is left as it was.
+func (m *Manager) Replay(ctx context.Context, deliveryID string) (*models.HookDelivery, error) {
+	if m.deliveries == nil {
+		return nil, ErrNoDeliveryQueue
+	}
+
+	original, err := m.deliveries.Get(ctx, deliveryID)
+	if err != nil {
+		return nil, err
+	}
+	hook, err := m.store.Get(original.HookID)
+	if err != nil {
+		return nil, err
+	}
+
+	var event models.Event
+	if err := json.Unmarshal(original.Payload, &event); err != nil {
+		return nil, fmt.Errorf("failed to decode delivery payload: %w", err)
+	}
+
+	delivery, err := m.enqueue(ctx, hook, &event, original.ID)
+	if err != nil {
+		return nil, err
+	}
+	return delivery, m.attempt(ctx, hook, delivery)
+}
+
+// RetryDue attempts pending deliveries whose next attempt is due and returns
+// how many were attempted.
+func (m *Manager) RetryDue(ctx context.Context) (int, error) {
+	if m.deliveries == nil {
+		return 0, ErrNoDeliveryQueue
+	}
+
+	now := m.now()
+	due, err := m.deliveries.ListDue(ctx, now, maxRetryBatch)
+	if err != nil {
+		return 0, err
+	}
+	if len(due) == 0 {
+		return 0, nil
+	}
+
+	hooks, err := m.store.List()
+	if err != nil {
+		return 0, err
+	}
+	byID := make(map[string]Hook, len(hooks))
+	for _, hook := range hooks {
+		byID[hook.ID] = hook
+	}
+
+	attempted := 0
+	for _, delivery := range due {
+		if ctx.Err() != nil {
+			return attempted, ctx.Err()
+		}
+
+		hook, ok := byID[delivery.HookID]
+		claimed, err := m.deliveries.Claim(ctx, delivery.ID, now, now.Add(leaseFor(hook)))
+		if err != nil {
+			return attempted, err
+		}
+		if !claimed {
+			continue
+		}
+
+		switch {
+		case !ok:
+			err = m.deadLetter(ctx, delivery, "hook no longer exists")
+		case !hook.Enabled:
+			err = m.deadLetter(ctx, delivery, "hook is disabled")
+		default:
+			err = m.attempt(ctx, hook, delivery)
+			attempted++
+		}
+		if err != nil {
+			return attempted, err
+		}
+	}
+	return attempted, nil
+}
+
+// Start retries due deliveries in the background until Stop is called.
+func (m *Manager) Start(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		interval = DefaultRetryInterval
+	}
+	ctx, m.cancel = context.WithCancel(ctx)
+
+	m.wg.Add(1)
+	go func() {
+		defer m.wg.Done()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			if _, err := m.RetryDue(ctx); err != nil && ctx.Err() == nil {
+				m.logger.Warn().Err(err).Msg("hook retry pass failed")
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Stop halts background retries and waits for an in-flight pass to finish.
+func (m *Manager) Stop() {
+	if m.cancel != nil {
+		m.cancel()
+	}
+	m.wg.Wait()
+}
+
+// enqueue records a delivery that the caller is about to attempt. Its next
+// attempt is leased past the attempt so other processes leave it alone.
+func (m *Manager) enqueue(ctx context.Context, hook Hook, event *models.Event, replayOf string) (*models.HookDelivery, error) {
+	payload, err := marshalEvent(event)
+	if err != nil {
+		return nil, err
+	}
+
+	lease := m.now().Add(leaseFor(hook))
+	delivery := &models.HookDelivery{
+		HookID:        hook.ID,
+		Payload:       payload,
+		NextAttemptAt: &lease,
+		ReplayOf:      replayOf,
+	}
+	if event != nil {
+		delivery.EventID = event.ID
+		delivery.EventType = event.Type
+	}
+	if err := m.deliveries.Create(ctx, delivery); err != nil {
+		return nil, err
+	}
+	return delivery, nil
+}
+
+// attempt runs one delivery attempt and records its outcome. The returned
+// error is only about recording; a failed attempt is reported on delivery.
+func (m *Manager) attempt(ctx context.Context, hook Hook, delivery *models.HookDelivery) error {
+	var event *models.Event
+	if err := json.Unmarshal(delivery.Payload, &event); err != nil {
+		return m.deadLetter(ctx, delivery, fmt.Sprintf("invalid payload: %v", err))
+	}
+
+	execErr := m.execute(ctx, hook, event)
+	now := m.now().UTC()
+	delivery.Attempts++
+	delivery.LastStatusCode = nil
+
+	var statusErr *StatusError
+	if errors.As(execErr, &statusErr) {
+		delivery.LastStatusCode = &statusErr.StatusCode
+	}
+
+	switch {
+	case execErr == nil:
+		delivery.Status = models.HookDeliveryDelivered
+		delivery.DeliveredAt = &now
+		delivery.NextAttemptAt = nil
+		delivery.LastError = ""
+	case delivery.Attempts >= m.policy.MaxAttempts || !Retryable(execErr):
+		delivery.Status = models.HookDeliveryDead
+		delivery.NextAttemptAt = nil
+		delivery.LastError = execErr.Error()
+		m.logger.Error().Err(execErr).
+			Str("hook_id", hook.ID).
+			Str("delivery_id", delivery.ID).
+			Int("attempts", delivery.Attempts).
+			Msg("hook delivery dead-lettered")
+	default:
+		next := now.Add(m.policy.Backoff(delivery.Attempts))
+		delivery.NextAttemptAt = &next
+		delivery.LastError = execErr.Error()
+		m.logger.Warn().Err(execErr).
+			Str("hook_id", hook.ID).
+			Str("delivery_id", delivery.ID).
+			Int("attempts", delivery.Attempts).
+			Time("next_attempt_at", next).
+			Msg("hook delivery failed; will retry")
+	}
+
+	// Record the outcome even if the caller's context ended mid-attempt.
+	return m.deliveries.Update(context.WithoutCancel(ctx), delivery)
+}
+
+func (m *Manager) deadLetter(ctx context.Context, delivery *models.HookDelivery, reason string) error {
+	delivery.Status = models.HookDeliveryDead
+	delivery.NextAttemptAt = nil
+	delivery.LastError = reason
+	m.logger.Warn().
+		Str("hook_id", delivery.HookID).
+		Str("delivery_id", delivery.ID).
+		Str("reason", reason).
+		Msg("hook delivery dead-lettered")
+	return m.deliveries.Update(ctx, delivery)
+}
+
+// leaseFor returns how long a claimed delivery is hidden from other
+// processes: past the hook's timeout, so only abandoned attempts are retried.
+func leaseFor(hook Hook) time.Duration {
+	if timeout, ok := parseTimeout(hook.Timeout); ok {
+		return timeout + time.Minute
+	}
+	return 10 * time.Minute
+}
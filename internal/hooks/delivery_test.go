@@ -0,0 +1,210 @@
+package hooks
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/tOgg1/forge/internal/db"
+	"github.com/tOgg1/forge/internal/models"
+)
+
+func newDeliveryTestManager(t *testing.T, hook Hook) (*Manager, Hook, *db.HookDeliveryRepository) {
+	t.Helper()
+
+	database, err := db.OpenInMemory()
+	if err != nil {
+		t.Fatalf("OpenInMemory: %v", err)
+	}
+	t.Cleanup(func() { database.Close() })
+	if err := database.Migrate(context.Background()); err != nil {
+		t.Fatalf("Migrate: %v", err)
+	}
+
+	store := NewStore(filepath.Join(t.TempDir(), "hooks.json"))
+	stored, err := store.Add(hook)
+	if err != nil {
+		t.Fatalf("Add: %v", err)
+	}
+
+	repo := db.NewHookDeliveryRepository(database)
+	manager := NewManager(store, nil,
+		WithDeliveryRepository(repo),
+		WithRetryPolicy(RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Minute, MaxBackoff: time.Hour}),
+	)
+	return manager, stored, repo
+}
+
+func TestManagerRetriesUntilDelivered(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	manager, hook, repo := newDeliveryTestManager(t, Hook{Kind: KindWebhook, URL: server.URL, Enabled: true})
+	ctx := context.Background()
+
+	manager.runHook(hook, &models.Event{ID: "event-1", Type: models.EventTypeLoopStarted})
+
+	history, err := repo.ListByHook(ctx, hook.ID, 0)
+	if err != nil {
+		t.Fatalf("ListByHook: %v", err)
+	}
+	if len(history) != 1 {
+		t.Fatalf("ListByHook: expected 1 delivery, got %d", len(history))
+	}
+	first := history[0]
+	if first.Status != models.HookDeliveryPending || first.Attempts != 1 {
+		t.Fatalf("after failure: status %s attempts %d, want pending after 1 attempt", first.Status, first.Attempts)
+	}
+	if first.LastStatusCode == nil || *first.LastStatusCode != http.StatusServiceUnavailable {
+		t.Fatalf("after failure: expected status code 503, got %v", first.LastStatusCode)
+	}
+
+	// Nothing is due until the backoff has passed.
+	if attempted, err := manager.RetryDue(ctx); err != nil || attempted != 0 {
+		t.Fatalf("RetryDue before backoff: attempted %d, err %v", attempted, err)
+	}
+
+	manager.now = func() time.Time { return time.Now().Add(2 * time.Minute) }
+	if attempted, err := manager.RetryDue(ctx); err != nil || attempted != 1 {
+		t.Fatalf("RetryDue after backoff: attempted %d, err %v", attempted, err)
+	}
+
+	delivered, err := repo.Get(ctx, first.ID)
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if delivered.Status != models.HookDeliveryDelivered || delivered.Attempts != 2 || delivered.DeliveredAt == nil {
+		t.Fatalf("after retry: got %+v, want delivered after 2 attempts", delivered)
+	}
+}
+
+func TestManagerDeadLettersAndReplays(t *testing.T) {
+	var fail atomic.Bool
+	fail.Store(true)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if fail.Load() {
+			http.Error(w, "bad token", http.StatusUnauthorized)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	manager, hook, repo := newDeliveryTestManager(t, Hook{Kind: KindWebhook, URL: server.URL, Enabled: true})
+	ctx := context.Background()
+
+	// Client errors are permanent, so the first failure dead-letters.
+	dead, err := manager.Test(ctx, hook.ID[:8])
+	if err != nil {
+		t.Fatalf("Test: %v", err)
+	}
+	if dead.Status != models.HookDeliveryDead || dead.Attempts != 1 {
+		t.Fatalf("Test: status %s attempts %d, want dead after 1 attempt", dead.Status, dead.Attempts)
+	}
+	if dead.EventType != models.EventTypeHookTest {
+		t.Fatalf("Test: event type %s, want %s", dead.EventType, models.EventTypeHookTest)
+	}
+
+	fail.Store(false)
+	replay, err := manager.Replay(ctx, dead.ID)
+	if err != nil {
+		t.Fatalf("Replay: %v", err)
+	}
+	if replay.Status != models.HookDeliveryDelivered || replay.ReplayOf != dead.ID {
+		t.Fatalf("Replay: got %+v, want a delivered replay of %s", replay, dead.ID)
+	}
+
+	original, err := repo.Get(ctx, dead.ID)
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if original.Status != models.HookDeliveryDead {
+		t.Fatalf("Replay changed the original delivery to %s", original.Status)
+	}
+}
+
+func TestRetryPolicyBackoff(t *testing.T) {
+	policy := RetryPolicy{InitialBackoff: 30 * time.Second, MaxBackoff: 5 * time.Minute}
+	want := []time.Duration{30 * time.Second, time.Minute, 2 * time.Minute, 4 * time.Minute, 5 * time.Minute, 5 * time.Minute}
+	for i, expected := range want {
+		if got := policy.Backoff(i + 1); got != expected {
+			t.Errorf("Backoff(%d) = %s, want %s", i+1, got, expected)
+		}
+	}
+}
+
+func TestManagerRetriesCommandHooksOnlyWhenOptedIn(t *testing.T) {
+	for _, retry := range []bool{false, true} {
+		marker := filepath.Join(t.TempDir(), "runs")
+		manager, hook, _ := newDeliveryTestManager(t, Hook{
+			Kind:    KindCommand,
+			Command: "echo run >> " + marker + "; exit 1",
+			Enabled: true,
+			Retry:   retry,
+		})
+
+		delivery, err := manager.Test(context.Background(), hook.ID)
+		if err != nil {
+			t.Fatalf("Test: %v", err)
+		}
+		want := models.HookDeliveryDead
+		if retry {
+			want = models.HookDeliveryPending
+		}
+		if delivery.Status != want || delivery.Attempts != 1 {
+			t.Fatalf("retry=%t: status %s attempts %d, want %s after 1 attempt", retry, delivery.Status, delivery.Attempts, want)
+		}
+
+		manager.now = func() time.Time { return time.Now().Add(2 * time.Minute) }
+		if _, err := manager.RetryDue(context.Background()); err != nil {
+			t.Fatalf("RetryDue: %v", err)
+		}
+		runs, err := os.ReadFile(marker)
+		if err != nil {
+			t.Fatalf("read marker: %v", err)
+		}
+		wantRuns := 1
+		if retry {
+			wantRuns = 2
+		}
+		if got := strings.Count(string(runs), "run"); got != wantRuns {
+			t.Fatalf("retry=%t: command ran %d times, want %d", retry, got, wantRuns)
+		}
+	}
+}
+
+func TestRetryable(t *testing.T) {
+	cases := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"transport", &TransportError{Err: errors.New("connection refused")}, true},
+		{"server error", &StatusError{StatusCode: http.StatusBadGateway}, true},
+		{"rate limited", &StatusError{StatusCode: http.StatusTooManyRequests}, true},
+		{"client error", &StatusError{StatusCode: http.StatusNotFound}, false},
+		{"command", &CommandError{Err: errors.New("exit status 1")}, false},
+		{"command with retry", &CommandError{Err: errors.New("exit status 1"), Retry: true}, true},
+		{"template", &TemplateError{Err: errors.New("bad")}, false},
+		{"other", errors.New("hook URL is required"), false},
+	}
+	for _, tc := range cases {
+		if got := Retryable(tc.err); got != tc.want {
+			t.Errorf("Retryable(%s) = %t, want %t", tc.name, got, tc.want)
+		}
+	}
+}
@@ -4,33 +4,136 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"net/http"
 	"os"
 	"os/exec"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/rs/zerolog"
 	"github.com/tOgg1/forge/internal/logging"
 	"github.com/tOgg1/forge/internal/models"
+	"github.com/tOgg1/forge/internal/vault"
 )
 
 // Executor runs hook actions for incoming events.
 type Executor struct {
-	client *http.Client
-	logger zerolog.Logger
+	client    *http.Client
+	logger    zerolog.Logger
+	vaultPath string
+	now       func() time.Time
+}
+
+// ExecutorOption configures an Executor.
+type ExecutorOption func(*Executor)
+
+// WithVaultPath sets the vault hook signing secrets are read from
+// (default: vault.DefaultVaultPath()).
+func WithVaultPath(path string) ExecutorOption {
+	return func(e *Executor) {
+		e.vaultPath = path
+	}
 }
 
 // DefaultTimeout is used when a hook does not specify a timeout.
 const DefaultTimeout = 30 * time.Second
 
+// maxOutputBytes bounds the command output and response body kept for errors.
+const maxOutputBytes = 2048
+
+// StatusError is returned when a webhook responds with a non-2xx status.
+type StatusError struct {
+	StatusCode int
+	Body       string
+}
+
+func (e *StatusError) Error() string {
+	if e.Body == "" {
+		return fmt.Sprintf("webhook returned status %d", e.StatusCode)
+	}
+	return fmt.Sprintf("webhook returned status %d: %s", e.StatusCode, e.Body)
+}
+
+// SecretError is returned when a signed hook has no usable signing secret.
+// It is permanent: retrying the delivery will not bring the secret back.
+type SecretError struct {
+	Err error
+}
+
+func (e *SecretError) Error() string {
+	return "hook signing secret: " + e.Err.Error()
+}
+
+func (e *SecretError) Unwrap() error {
+	return e.Err
+}
+
+// TransportError is returned when a webhook request could not be sent or its
+// response could not be read.
+type TransportError struct {
+	Err error
+}
+
+func (e *TransportError) Error() string {
+	return "webhook request failed: " + e.Err.Error()
+}
+
+func (e *TransportError) Unwrap() error {
+	return e.Err
+}
+
+// CommandError is returned when a command hook fails. Commands may have side
+// effects, such as filing a ticket, so they are only retried when the hook
+// opts in with Retry.
+type CommandError struct {
+	Err   error
+	Retry bool
+}
+
+func (e *CommandError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *CommandError) Unwrap() error {
+	return e.Err
+}
+
+// Retryable reports whether a delivery that failed with err may succeed on a
+// later attempt. Webhooks are retried on transport errors, 429 and 5xx
+// responses; command hooks only when they set Retry. Anything else, such as a
+// template error or a missing signing secret, is permanent.
+func Retryable(err error) bool {
+	var commandErr *CommandError
+	if errors.As(err, &commandErr) {
+		return commandErr.Retry
+	}
+	var transportErr *TransportError
+	if errors.As(err, &transportErr) {
+		return true
+	}
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.StatusCode == http.StatusTooManyRequests || statusErr.StatusCode >= 500
+	}
+	return false
+}
+
 // NewExecutor returns a hook executor with a default HTTP client.
-func NewExecutor() *Executor {
-	return &Executor{
-		client: &http.Client{},
-		logger: logging.Component("hooks"),
+func NewExecutor(opts ...ExecutorOption) *Executor {
+	e := &Executor{
+		client:    &http.Client{},
+		logger:    logging.Component("hooks"),
+		vaultPath: vault.DefaultVaultPath(),
+		now:       time.Now,
+	}
+	for _, opt := range opts {
+		opt(e)
 	}
+	return e
 }
 
 // Execute runs the hook for the given event.
@@ -62,18 +165,26 @@ func (e *Executor) runCommand(ctx context.Context, hook Hook, event *models.Even
 		return fmt.Errorf("hook command is required")
 	}
 
-	payload, err := marshalEvent(event)
+	payload, err := renderBody(hook, event)
 	if err != nil {
 		return err
 	}
 
+	// Keep hook output off the caller's stdout; it is only reported on failure.
+	output := &tailBuffer{max: maxOutputBytes}
 	cmd := exec.CommandContext(ctx, "sh", "-c", command)
 	cmd.Env = append(os.Environ(), eventEnv(event)...)
 	cmd.Stdin = bytes.NewReader(payload)
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
+	cmd.Stdout = output
+	cmd.Stderr = output
 
-	return cmd.Run()
+	if err := cmd.Run(); err != nil {
+		if tail := strings.TrimSpace(output.String()); tail != "" {
+			return &CommandError{Err: fmt.Errorf("hook command failed: %w: %s", err, tail), Retry: hook.Retry}
+		}
+		return &CommandError{Err: fmt.Errorf("hook command failed: %w", err), Retry: hook.Retry}
+	}
+	return nil
 }
 
 func (e *Executor) sendWebhook(ctx context.Context, hook Hook, event *models.Event) error {
@@ -82,7 +193,7 @@ func (e *Executor) sendWebhook(ctx context.Context, hook Hook, event *models.Eve
 		return fmt.Errorf("hook URL is required")
 	}
 
-	payload, err := marshalEvent(event)
+	payload, err := renderBody(hook, event)
 	if err != nil {
 		return err
 	}
@@ -100,25 +211,56 @@ func (e *Executor) sendWebhook(ctx context.Context, hook Hook, event *models.Eve
 		request.Header.Set(key, value)
 	}
 
+	if hook.Signed {
+		secret, err := vault.HookSecret(e.vaultPath, hook.ID)
+		if errors.Is(err, vault.ErrSecretNotFound) || errors.Is(err, vault.ErrInvalidTokenName) {
+			return &SecretError{Err: err}
+		}
+		if err != nil {
+			return fmt.Errorf("failed to load hook signing secret: %w", err)
+		}
+		now := e.now()
+		request.Header.Set(TimestampHeader, strconv.FormatInt(now.Unix(), 10))
+		request.Header.Set(SignatureHeader, Sign(secret, now, payload))
+	}
+
 	response, err := e.client.Do(request)
 	if err != nil {
-		return fmt.Errorf("webhook request failed: %w", err)
+		return &TransportError{Err: err}
 	}
 	defer func() {
 		_ = response.Body.Close()
 	}()
 
 	if response.StatusCode >= http.StatusMultipleChoices {
-		e.logger.Warn().
-			Str("hook_id", hook.ID).
-			Int("status", response.StatusCode).
-			Msg("webhook returned non-2xx status")
-		return fmt.Errorf("webhook returned status %d", response.StatusCode)
+		body, _ := io.ReadAll(io.LimitReader(response.Body, maxOutputBytes))
+		return &StatusError{
+			StatusCode: response.StatusCode,
+			Body:       strings.TrimSpace(string(body)),
+		}
 	}
 
 	return nil
 }
 
+// tailBuffer keeps the last max bytes written to it.
+type tailBuffer struct {
+	max int
+	buf []byte
+}
+
+func (t *tailBuffer) Write(p []byte) (int, error) {
+	t.buf = append(t.buf, p...)
+	if len(t.buf) > t.max {
+		t.buf = t.buf[len(t.buf)-t.max:]
+	}
+	return len(p), nil
+}
+
+func (t *tailBuffer) String() string {
+	return string(t.buf)
+}
+
 func marshalEvent(event *models.Event) ([]byte, error) {
 	if event == nil {
 		return json.Marshal(map[string]any{"event": nil})
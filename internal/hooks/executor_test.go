@@ -0,0 +1,118 @@
+package hooks
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/tOgg1/forge/internal/models"
+	"github.com/tOgg1/forge/internal/vault"
+)
+
+func TestExecutorSignsTemplatedWebhook(t *testing.T) {
+	type received struct {
+		body      []byte
+		signature string
+		timestamp string
+	}
+	requests := make(chan received, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		requests <- received{body: body, signature: r.Header.Get(SignatureHeader), timestamp: r.Header.Get(TimestampHeader)}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	vaultPath := t.TempDir()
+	if err := vault.StoreHookSecret(vaultPath, "hook-1", "s3cret"); err != nil {
+		t.Fatalf("StoreHookSecret: %v", err)
+	}
+
+	executor := NewExecutor(WithVaultPath(vaultPath))
+	hook := Hook{ID: "hook-1", Kind: KindWebhook, URL: server.URL, Signed: true, Preset: "slack"}
+	event := &models.Event{
+		ID:         "event-1",
+		Type:       models.EventTypeLoopRunFinished,
+		EntityType: models.EntityTypeLoop,
+		EntityID:   "1a2b3c4d5e6f",
+		Payload:    json.RawMessage(`{"status":"success","exit_code":0,"profile":""}`),
+	}
+	if err := executor.Execute(context.Background(), hook, event); err != nil {
+		t.Fatalf("Execute: %v", err)
+	}
+
+	got := <-requests
+	var message map[string]string
+	if err := json.Unmarshal(got.body, &message); err != nil {
+		t.Fatalf("slack body is not JSON: %v (%s)", err, got.body)
+	}
+	want := "forge loop.run.finished (loop 1a2b3c4d): exit_code=0 status=success"
+	if message["text"] != want {
+		t.Fatalf("slack text = %q, want %q", message["text"], want)
+	}
+
+	if err := VerifySignature("s3cret", got.signature, got.timestamp, got.body, 5*time.Minute, time.Now()); err != nil {
+		t.Fatalf("VerifySignature: %v", err)
+	}
+	if err := VerifySignature("wrong", got.signature, got.timestamp, got.body, 5*time.Minute, time.Now()); !errors.Is(err, ErrInvalidSignature) {
+		t.Fatalf("VerifySignature with wrong secret: expected ErrInvalidSignature, got %v", err)
+	}
+	if err := VerifySignature("s3cret", got.signature, got.timestamp, got.body, 5*time.Minute, time.Now().Add(time.Hour)); !errors.Is(err, ErrInvalidSignature) {
+		t.Fatalf("VerifySignature of a stale request: expected ErrInvalidSignature, got %v", err)
+	}
+}
+
+func TestRenderBodyTemplate(t *testing.T) {
+	event := &models.Event{Type: models.EventTypeLoopError, Payload: json.RawMessage(`{"stage":"prompt"}`)}
+
+	body, err := renderBody(Hook{Template: `{"msg": {{ json (printf "%s at %s" .Event.Type .Payload.stage) }}}`}, event)
+	if err != nil {
+		t.Fatalf("renderBody: %v", err)
+	}
+	if string(body) != `{"msg": "loop.error at prompt"}` {
+		t.Fatalf("renderBody = %s", body)
+	}
+
+	body, err = renderBody(Hook{}, event)
+	if err != nil {
+		t.Fatalf("renderBody without template: %v", err)
+	}
+	var decoded models.Event
+	if err := json.Unmarshal(body, &decoded); err != nil || decoded.Type != models.EventTypeLoopError {
+		t.Fatalf("renderBody without template should send the event JSON, got %s", body)
+	}
+
+	_, err = renderBody(Hook{Preset: "pager"}, event)
+	if err == nil || Retryable(err) {
+		t.Fatalf("unknown preset: expected a permanent error, got %v", err)
+	}
+}
+
+func TestExecutorMissingSecretIsPermanent(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	executor := NewExecutor(WithVaultPath(t.TempDir()))
+	hook := Hook{ID: "hook-1", Kind: KindWebhook, URL: server.URL, Signed: true}
+	err := executor.Execute(context.Background(), hook, &models.Event{ID: "event-1", Type: models.EventTypeLoopError})
+
+	var secretErr *SecretError
+	if !errors.As(err, &secretErr) || !errors.Is(err, vault.ErrSecretNotFound) {
+		t.Fatalf("expected a SecretError wrapping ErrSecretNotFound, got %v", err)
+	}
+	if Retryable(err) {
+		t.Fatalf("a missing signing secret must not be retried")
+	}
+	if requests != 0 {
+		t.Fatalf("expected no unsigned request to be sent, got %d", requests)
+	}
+}
@@ -27,6 +27,17 @@ type Hook struct {
 	URL     string            `json:"url,omitempty"`
 	Headers map[string]string `json:"headers,omitempty"`
 
+	// Signed webhooks carry an HMAC-SHA256 signature of the body made with the
+	// hook's secret, which is kept in the vault rather than here.
+	Signed bool `json:"signed,omitempty"`
+
+	// Preset renders the body in a built-in chat webhook format (see Presets).
+	Preset string `json:"preset,omitempty"`
+
+	// Template is a Go text/template rendering the body instead of the event
+	// JSON. It takes precedence over Preset.
+	Template string `json:"template,omitempty"`
+
 	EventTypes  []models.EventType  `json:"event_types,omitempty"`
 	EntityTypes []models.EntityType `json:"entity_types,omitempty"`
 	EntityID    string              `json:"entity_id,omitempty"`
@@ -35,6 +46,10 @@ type Hook struct {
 
 	Timeout string `json:"timeout,omitempty"`
 
+	// Retry lets failed command hooks be retried. Webhooks are retried on
+	// transient failures regardless.
+	Retry bool `json:"retry,omitempty"`
+
 	CreatedAt time.Time `json:"created_at,omitempty"`
 	UpdatedAt time.Time `json:"updated_at,omitempty"`
 }
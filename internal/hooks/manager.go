@@ -4,32 +4,66 @@ import (
 	"context"
 	"fmt"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/rs/zerolog"
+	"github.com/tOgg1/forge/internal/db"
 	"github.com/tOgg1/forge/internal/events"
 	"github.com/tOgg1/forge/internal/logging"
 	"github.com/tOgg1/forge/internal/models"
 )
 
-// Manager wires stored hooks into an event publisher.
+// Manager wires stored hooks into an event publisher. With a delivery
+// repository, every matching event is recorded as a delivery that is retried
+// with backoff until it succeeds or is dead-lettered.
 type Manager struct {
-	store    *Store
-	executor *Executor
-	logger   zerolog.Logger
+	store      *Store
+	executor   *Executor
+	deliveries *db.HookDeliveryRepository
+	policy     RetryPolicy
+	logger     zerolog.Logger
+	now        func() time.Time
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// ManagerOption configures a Manager.
+type ManagerOption func(*Manager)
+
+// WithDeliveryRepository queues deliveries in the database so failed
+// deliveries are retried and kept as history.
+func WithDeliveryRepository(repo *db.HookDeliveryRepository) ManagerOption {
+	return func(m *Manager) {
+		m.deliveries = repo
+	}
+}
+
+// WithRetryPolicy overrides DefaultRetryPolicy.
+func WithRetryPolicy(policy RetryPolicy) ManagerOption {
+	return func(m *Manager) {
+		m.policy = policy
+	}
 }
 
 // NewManager creates a new hook manager.
-func NewManager(store *Store, executor *Executor) *Manager {
+func NewManager(store *Store, executor *Executor, opts ...ManagerOption) *Manager {
 	if executor == nil {
 		executor = NewExecutor()
 	}
 
-	return &Manager{
+	m := &Manager{
 		store:    store,
 		executor: executor,
+		policy:   DefaultRetryPolicy(),
 		logger:   logging.Component("hooks"),
+		now:      time.Now,
+	}
+	for _, opt := range opts {
+		opt(m)
 	}
+	return m
 }
 
 // Attach registers all stored hooks with the publisher.
@@ -67,16 +101,33 @@ func (m *Manager) Attach(publisher events.Publisher) error {
 }
 
 func (m *Manager) runHook(hook Hook, event *models.Event) {
+	if m.deliveries == nil {
+		if err := m.execute(context.Background(), hook, event); err != nil {
+			m.logger.Warn().Err(err).Str("hook_id", hook.ID).Msg("hook execution failed")
+		}
+		return
+	}
+
 	ctx := context.Background()
+	delivery, err := m.enqueue(ctx, hook, event, "")
+	if err != nil {
+		m.logger.Warn().Err(err).Str("hook_id", hook.ID).Msg("failed to queue hook delivery")
+		return
+	}
+	if err := m.attempt(ctx, hook, delivery); err != nil {
+		m.logger.Warn().Err(err).Str("hook_id", hook.ID).Msg("failed to record hook delivery")
+	}
+}
+
+// execute runs a hook once within its timeout.
+func (m *Manager) execute(ctx context.Context, hook Hook, event *models.Event) error {
 	if timeout, ok := parseTimeout(hook.Timeout); ok {
 		ctxTimeout, cancel := context.WithTimeout(ctx, timeout)
 		defer cancel()
 		ctx = ctxTimeout
 	}
 
-	if err := m.executor.Execute(ctx, hook, event); err != nil {
-		m.logger.Warn().Err(err).Str("hook_id", hook.ID).Msg("hook execution failed")
-	}
+	return m.executor.Execute(ctx, hook, event)
 }
 
 func parseTimeout(value string) (time.Duration, bool) {
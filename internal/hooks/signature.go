@@ -0,0 +1,69 @@
+package hooks
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Signature headers sent with signed webhooks.
+const (
+	// SignatureHeader carries "sha256=" followed by the hex HMAC-SHA256 of
+	// "<timestamp>.<body>".
+	SignatureHeader = "X-Forge-Signature"
+	// TimestampHeader carries the Unix time the request was signed at.
+	TimestampHeader = "X-Forge-Timestamp"
+)
+
+// signaturePrefix names the signature algorithm.
+const signaturePrefix = "sha256="
+
+// ErrInvalidSignature is returned when a signature does not verify.
+var ErrInvalidSignature = errors.New("invalid webhook signature")
+
+// NewSecret generates a random signing secret.
+func NewSecret() (string, error) {
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return "", fmt.Errorf("failed to generate hook secret: %w", err)
+	}
+	return hex.EncodeToString(raw), nil
+}
+
+// Sign returns the SignatureHeader value for a body sent at timestamp. The
+// timestamp is part of the signed message so captured requests cannot be
+// replayed later.
+func Sign(secret string, timestamp time.Time, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp.Unix(), 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return signaturePrefix + hex.EncodeToString(mac.Sum(nil))
+}
+
+// VerifySignature checks the SignatureHeader and TimestampHeader values of a
+// received webhook. Requests signed more than tolerance away from now are
+// rejected; a tolerance <= 0 skips that check.
+func VerifySignature(secret, signature, timestamp string, body []byte, tolerance time.Duration, now time.Time) error {
+	unix, err := strconv.ParseInt(strings.TrimSpace(timestamp), 10, 64)
+	if err != nil {
+		return fmt.Errorf("%w: bad timestamp %q", ErrInvalidSignature, timestamp)
+	}
+	signedAt := time.Unix(unix, 0)
+	if tolerance > 0 && math.Abs(float64(now.Sub(signedAt))) > float64(tolerance) {
+		return fmt.Errorf("%w: timestamp outside tolerance", ErrInvalidSignature)
+	}
+
+	expected := Sign(secret, signedAt, body)
+	if !hmac.Equal([]byte(expected), []byte(strings.TrimSpace(signature))) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
@@ -2,15 +2,35 @@ package hooks
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/google/uuid"
 )
 
+// ErrHookNotFound is returned when no stored hook matches an ID.
+var ErrHookNotFound = errors.New("hook not found")
+
+// StorePath returns the hook store location for a config directory, falling
+// back to ~/.config/forge when configDir is empty.
+func StorePath(configDir string) string {
+	if strings.TrimSpace(configDir) != "" {
+		return filepath.Join(configDir, "hooks.json")
+	}
+
+	homeDir, err := os.UserHomeDir()
+	if err != nil || homeDir == "" {
+		return "hooks.json"
+	}
+
+	return filepath.Join(homeDir, ".config", "forge", "hooks.json")
+}
+
 type hookFile struct {
 	Hooks []Hook `json:"hooks"`
 }
@@ -39,6 +59,37 @@ func (s *Store) List() ([]Hook, error) {
 	return s.loadLocked()
 }
 
+// Get returns the hook with the given ID or unique ID prefix.
+func (s *Store) Get(id string) (Hook, error) {
+	id = strings.TrimSpace(id)
+	if id == "" {
+		return Hook{}, ErrHookNotFound
+	}
+
+	hooks, err := s.List()
+	if err != nil {
+		return Hook{}, err
+	}
+
+	var matches []Hook
+	for _, hook := range hooks {
+		if hook.ID == id {
+			return hook, nil
+		}
+		if strings.HasPrefix(hook.ID, id) {
+			matches = append(matches, hook)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return Hook{}, fmt.Errorf("%w: %s", ErrHookNotFound, id)
+	case 1:
+		return matches[0], nil
+	default:
+		return Hook{}, fmt.Errorf("hook ID prefix %q is ambiguous (%d matches)", id, len(matches))
+	}
+}
+
 // Add stores a new hook.
 func (s *Store) Add(hook Hook) (Hook, error) {
 	s.mu.Lock()
@@ -0,0 +1,175 @@
+package hooks
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/tOgg1/forge/internal/models"
+)
+
+// Presets are built-in body templates for common chat webhooks.
+var Presets = map[string]string{
+	// Slack and Mattermost incoming webhooks.
+	"slack": `{"text": {{ json .Summary }}}`,
+	// Discord channel webhooks.
+	"discord": `{"content": {{ json .Summary }}, "username": "forge"}`,
+	// Matrix hookshot generic webhooks.
+	"matrix": `{"text": {{ json .Summary }}, "username": "forge"}`,
+	// Google Chat space webhooks.
+	"google-chat": `{"text": {{ json .Summary }}}`,
+}
+
+// maxSummaryLen keeps summaries within chat message limits (Discord: 2000).
+const maxSummaryLen = 1500
+
+// TemplateData is what body templates are executed with.
+type TemplateData struct {
+	// Event is the event being delivered.
+	Event *models.Event
+	// Payload is the event payload decoded from JSON.
+	Payload map[string]any
+	// Summary is a one-line, human-readable description of the event.
+	Summary string
+}
+
+// TemplateError is returned when a hook's body template cannot be rendered.
+// It is permanent: retrying the delivery will not help.
+type TemplateError struct {
+	Err error
+}
+
+func (e *TemplateError) Error() string {
+	return "hook template: " + e.Err.Error()
+}
+
+func (e *TemplateError) Unwrap() error {
+	return e.Err
+}
+
+var templateFuncs = template.FuncMap{
+	// json encodes a value as JSON, for embedding strings in JSON bodies.
+	"json": func(value any) (string, error) {
+		data, err := json.Marshal(value)
+		return string(data), err
+	},
+	// short abbreviates an ID.
+	"short": shortID,
+}
+
+func shortID(id string) string {
+	if len(id) <= 8 {
+		return id
+	}
+	return id[:8]
+}
+
+// ParseTemplate checks a hook's Template or Preset and returns the body
+// template, or nil if the hook sends the raw event JSON.
+func ParseTemplate(hook Hook) (*template.Template, error) {
+	text := hook.Template
+	if strings.TrimSpace(text) == "" {
+		preset := strings.TrimSpace(hook.Preset)
+		if preset == "" {
+			return nil, nil
+		}
+		var ok bool
+		if text, ok = Presets[preset]; !ok {
+			return nil, fmt.Errorf("unknown hook preset %q (use %s)", preset, strings.Join(PresetNames(), ", "))
+		}
+	}
+
+	tmpl, err := template.New("hook").Funcs(templateFuncs).Option("missingkey=zero").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid hook template: %w", err)
+	}
+	return tmpl, nil
+}
+
+// PresetNames returns the built-in preset names, sorted.
+func PresetNames() []string {
+	names := make([]string, 0, len(Presets))
+	for name := range Presets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// renderBody returns the body sent to a hook: the rendered template if the
+// hook has one, the event JSON otherwise.
+func renderBody(hook Hook, event *models.Event) ([]byte, error) {
+	tmpl, err := ParseTemplate(hook)
+	if err != nil {
+		return nil, &TemplateError{Err: err}
+	}
+	if tmpl == nil {
+		return marshalEvent(event)
+	}
+
+	data := TemplateData{Event: event, Summary: Summary(event)}
+	if event != nil && len(event.Payload) > 0 {
+		_ = json.Unmarshal(event.Payload, &data.Payload)
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return nil, &TemplateError{Err: err}
+	}
+	return buf.Bytes(), nil
+}
+
+// Summary describes an event in one line, e.g.
+// "forge loop.run.finished (loop 1a2b3c4d): exit_code=0 status=success".
+func Summary(event *models.Event) string {
+	if event == nil {
+		return "forge event"
+	}
+
+	var b strings.Builder
+	b.WriteString("forge ")
+	b.WriteString(string(event.Type))
+	if event.EntityType != "" {
+		b.WriteString(" (")
+		b.WriteString(string(event.EntityType))
+		if event.EntityID != "" {
+			b.WriteString(" ")
+			b.WriteString(shortID(event.EntityID))
+		}
+		b.WriteString(")")
+	}
+
+	var payload map[string]any
+	if len(event.Payload) > 0 && json.Unmarshal(event.Payload, &payload) == nil && len(payload) > 0 {
+		keys := make([]string, 0, len(payload))
+		for key := range payload {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		sep := ": "
+		for _, key := range keys {
+			switch value := payload[key].(type) {
+			case map[string]any, []any, nil:
+				continue
+			case string:
+				if value == "" {
+					continue
+				}
+				fmt.Fprintf(&b, "%s%s=%s", sep, key, value)
+			default:
+				fmt.Fprintf(&b, "%s%s=%v", sep, key, value)
+			}
+			sep = " "
+		}
+	}
+
+	summary := b.String()
+	if len(summary) > maxSummaryLen {
+		summary = summary[:maxSummaryLen] + "..."
+	}
+	return summary
+}
@@ -0,0 +1,201 @@
+package loop
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/tOgg1/forge/internal/db"
+	"github.com/tOgg1/forge/internal/models"
+)
+
+// RunArtifactDir returns the directory holding a loop's per-run artifacts.
+func RunArtifactDir(dataDir, loopID string) string {
+	return filepath.Join(dataDir, "runs", loopID)
+}
+
+// RunOutputPath returns where a run's full output is stored.
+func RunOutputPath(dataDir, loopID, runID string) string {
+	return filepath.Join(RunArtifactDir(dataDir, loopID), runID+".out.gz")
+}
+
+// RunPromptPath returns where the prompt sent for a run is stored.
+func RunPromptPath(dataDir, loopID, runID string) string {
+	return filepath.Join(RunArtifactDir(dataDir, loopID), runID+".prompt.gz")
+}
+
+// RunEventsPath returns where the structured events decoded from a run's
+// stream-json output are stored.
+func RunEventsPath(dataDir, loopID, runID string) string {
+	return filepath.Join(RunArtifactDir(dataDir, loopID), runID+".events.json.gz")
+}
+
+// ReadRunEvents returns the structured events stored for a run.
+func ReadRunEvents(path string) ([]models.LoopRunEvent, error) {
+	content, err := ReadRunArtifact(path)
+	if err != nil {
+		return nil, err
+	}
+	var events []models.LoopRunEvent
+	if err := json.Unmarshal([]byte(content), &events); err != nil {
+		return nil, err
+	}
+	return events, nil
+}
+
+// ReadRunArtifact returns the decompressed contents of a run artifact.
+func ReadRunArtifact(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	reader, err := gzip.NewReader(file)
+	if err != nil {
+		return "", err
+	}
+	defer reader.Close()
+
+	data, err := io.ReadAll(reader)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// runOutputWriter streams a run's combined stdout/stderr into a gzip artifact.
+type runOutputWriter struct {
+	mu   sync.Mutex
+	file *os.File
+	gz   *gzip.Writer
+}
+
+func newRunOutputWriter(path string) (*runOutputWriter, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, err
+	}
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	return &runOutputWriter{file: file, gz: gzip.NewWriter(file)}, nil
+}
+
+func (w *runOutputWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.gz.Write(p)
+}
+
+func (w *runOutputWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if err := w.gz.Close(); err != nil {
+		_ = w.file.Close()
+		return err
+	}
+	return w.file.Close()
+}
+
+func writeRunArtifact(path, content string) error {
+	writer, err := newRunOutputWriter(path)
+	if err != nil {
+		return err
+	}
+	if _, err := io.WriteString(writer, content); err != nil {
+		_ = writer.Close()
+		return err
+	}
+	return writer.Close()
+}
+
+// openRunArtifacts stores the prompt sent for a run and opens the writer for its
+// full output, linking both from the run record.
+func (r *Runner) openRunArtifacts(loop *models.Loop, run *models.LoopRun, promptPath, promptContent string) (*runOutputWriter, error) {
+	dataDir := r.Config.Global.DataDir
+	promptArtifact := RunPromptPath(dataDir, loop.ID, run.ID)
+	if err := writeRunArtifact(promptArtifact, sentPrompt(promptPath, promptContent)); err != nil {
+		return nil, err
+	}
+	run.PromptSentPath = promptArtifact
+
+	outputArtifact := RunOutputPath(dataDir, loop.ID, run.ID)
+	writer, err := newRunOutputWriter(outputArtifact)
+	if err != nil {
+		return nil, err
+	}
+	run.OutputPath = outputArtifact
+	return writer, nil
+}
+
+// storeRunEvents writes a run's structured events and links them from the run
+// record.
+func (r *Runner) storeRunEvents(loop *models.Loop, run *models.LoopRun, events []models.LoopRunEvent) error {
+	data, err := json.Marshal(events)
+	if err != nil {
+		return err
+	}
+	path := RunEventsPath(r.Config.Global.DataDir, loop.ID, run.ID)
+	if err := writeRunArtifact(path, string(data)); err != nil {
+		return err
+	}
+	run.EventsPath = path
+	return nil
+}
+
+// sentPrompt returns the prompt text the harness received, reading it back from
+// the prompt file when the profile passes prompts by path.
+func sentPrompt(promptPath, promptContent string) string {
+	if promptContent != "" || promptPath == "" {
+		return promptContent
+	}
+	data, err := os.ReadFile(promptPath)
+	if err != nil {
+		return ""
+	}
+	return string(data)
+}
+
+// pruneRunArtifacts deletes the artifacts of runs that fall outside the
+// loop_defaults retention window (by age or by count of newer runs).
+func (r *Runner) pruneRunArtifacts(ctx context.Context, runRepo *db.LoopRunRepository, loopID string, now time.Time) error {
+	maxAge := r.Config.LoopDefaults.RunOutputMaxAge
+	maxRuns := r.Config.LoopDefaults.RunOutputMaxRuns
+	if maxAge <= 0 && maxRuns <= 0 {
+		return nil
+	}
+
+	runs, err := runRepo.ListByLoop(ctx, loopID)
+	if err != nil {
+		return err
+	}
+	for i, run := range runs {
+		if run.OutputPath == "" && run.PromptSentPath == "" && run.EventsPath == "" {
+			continue
+		}
+		expired := maxAge > 0 && run.StartedAt.Before(now.Add(-maxAge))
+		if !expired && (maxRuns <= 0 || i < maxRuns) {
+			continue
+		}
+		removeRunArtifacts(run)
+		if err := runRepo.ClearArtifacts(ctx, run.ID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func removeRunArtifacts(run *models.LoopRun) {
+	for _, path := range []string{run.OutputPath, run.PromptSentPath, run.EventsPath} {
+		if path != "" {
+			_ = os.Remove(path)
+		}
+	}
+}
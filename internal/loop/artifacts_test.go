@@ -0,0 +1,122 @@
+package loop
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/tOgg1/forge/internal/config"
+	"github.com/tOgg1/forge/internal/db"
+	"github.com/tOgg1/forge/internal/models"
+	"github.com/tOgg1/forge/internal/testutil"
+)
+
+func TestRunnerStoresRunArtifacts(t *testing.T) {
+	database, cleanup := testutil.NewTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	cfg := config.DefaultConfig()
+	cfg.Global.DataDir = t.TempDir()
+
+	profile := &models.Profile{Name: "pi-artifacts", Harness: models.HarnessPi, PromptMode: models.PromptModeEnv, CommandTemplate: "pi"}
+	if err := db.NewProfileRepository(database).Create(ctx, profile); err != nil {
+		t.Fatalf("create profile: %v", err)
+	}
+	loopEntry := &models.Loop{Name: "loop-artifacts", RepoPath: t.TempDir(), BasePromptMsg: "fix the build", ProfileID: profile.ID}
+	if err := db.NewLoopRepository(database).Create(ctx, loopEntry); err != nil {
+		t.Fatalf("create loop: %v", err)
+	}
+
+	runner := NewRunner(database, cfg)
+	runner.OutputTailLines = 2
+	runner.Exec = func(ctx context.Context, profile models.Profile, promptPath, promptContent, workDir string, output io.Writer) (int, string, error) {
+		for i := 1; i <= 5; i++ {
+			fmt.Fprintf(output, "line %d\n", i)
+		}
+		return 0, "", nil
+	}
+	if err := runner.RunOnce(ctx, loopEntry.ID); err != nil {
+		t.Fatalf("run once: %v", err)
+	}
+
+	runs, err := db.NewLoopRunRepository(database).ListByLoop(ctx, loopEntry.ID)
+	if err != nil || len(runs) != 1 {
+		t.Fatalf("expected 1 run, got %d (%v)", len(runs), err)
+	}
+	run := runs[0]
+	if strings.Contains(run.OutputTail, "line 1") {
+		t.Fatalf("expected output tail to be truncated, got %q", run.OutputTail)
+	}
+	if run.OutputPath != RunOutputPath(cfg.Global.DataDir, loopEntry.ID, run.ID) {
+		t.Fatalf("unexpected output path %q", run.OutputPath)
+	}
+
+	output, err := ReadRunArtifact(run.OutputPath)
+	if err != nil {
+		t.Fatalf("read output: %v", err)
+	}
+	if output != "line 1\nline 2\nline 3\nline 4\nline 5\n" {
+		t.Fatalf("unexpected full output %q", output)
+	}
+	prompt, err := ReadRunArtifact(run.PromptSentPath)
+	if err != nil {
+		t.Fatalf("read prompt: %v", err)
+	}
+	if !strings.HasPrefix(prompt, "fix the build") {
+		t.Fatalf("unexpected sent prompt %q", prompt)
+	}
+}
+
+func TestPruneRunArtifacts(t *testing.T) {
+	database, cleanup := testutil.NewTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	cfg := config.DefaultConfig()
+	cfg.Global.DataDir = t.TempDir()
+	cfg.LoopDefaults.RunOutputMaxAge = 24 * time.Hour
+	cfg.LoopDefaults.RunOutputMaxRuns = 2
+
+	loopEntry := &models.Loop{Name: "loop-retention", RepoPath: t.TempDir()}
+	if err := db.NewLoopRepository(database).Create(ctx, loopEntry); err != nil {
+		t.Fatalf("create loop: %v", err)
+	}
+
+	runRepo := db.NewLoopRunRepository(database)
+	now := time.Now().UTC()
+	ages := []time.Duration{time.Minute, 2 * time.Minute, 3 * time.Minute, 48 * time.Hour}
+	runs := make([]*models.LoopRun, 0, len(ages))
+	for i, age := range ages {
+		run := &models.LoopRun{ID: fmt.Sprintf("run-%d", i), LoopID: loopEntry.ID, Status: models.LoopRunStatusSuccess, StartedAt: now.Add(-age)}
+		run.OutputPath = RunOutputPath(cfg.Global.DataDir, loopEntry.ID, run.ID)
+		if err := writeRunArtifact(run.OutputPath, "output"); err != nil {
+			t.Fatalf("write artifact: %v", err)
+		}
+		if err := runRepo.Create(ctx, run); err != nil {
+			t.Fatalf("create run: %v", err)
+		}
+		runs = append(runs, run)
+	}
+
+	runner := NewRunner(database, cfg)
+	if err := runner.pruneRunArtifacts(ctx, runRepo, loopEntry.ID, now); err != nil {
+		t.Fatalf("prune: %v", err)
+	}
+
+	for i, run := range runs {
+		stored, err := runRepo.Get(ctx, run.ID)
+		if err != nil {
+			t.Fatalf("get run: %v", err)
+		}
+		_, statErr := os.Stat(run.OutputPath)
+		kept := i < 2
+		if kept != (stored.OutputPath != "") || kept != (statErr == nil) {
+			t.Fatalf("run %d: expected kept=%v, got path=%q stat=%v", i, kept, stored.OutputPath, statErr)
+		}
+	}
+}
@@ -0,0 +1,208 @@
+package loop
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/tOgg1/forge/internal/db"
+	"github.com/tOgg1/forge/internal/models"
+)
+
+const loopChainConfigKey = "chain"
+
+// LoadChainConfig returns the loop's on_stop/on_success chain, if any.
+func LoadChainConfig(loopEntry *models.Loop) (models.LoopChainConfig, bool) {
+	if loopEntry == nil || loopEntry.Metadata == nil {
+		return models.LoopChainConfig{}, false
+	}
+	raw, ok := loopEntry.Metadata[loopChainConfigKey]
+	if !ok || raw == nil {
+		return models.LoopChainConfig{}, false
+	}
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return models.LoopChainConfig{}, false
+	}
+	var cfg models.LoopChainConfig
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return models.LoopChainConfig{}, false
+	}
+	return cfg, true
+}
+
+// ChainMetadata returns the loop metadata entry that stores cfg.
+func ChainMetadata(cfg models.LoopChainConfig) map[string]any {
+	if cfg.Empty() {
+		return nil
+	}
+	return map[string]any{loopChainConfigKey: cfg}
+}
+
+// loopStopReason classifies how the last run of a loop ended. It is empty when
+// the loop did not stop on its own.
+func (r *Runner) loopStopReason(loop *models.Loop) models.LoopStopReason {
+	if r.stoppedByOperator {
+		return ""
+	}
+	switch loop.State {
+	case models.LoopStateError:
+		return models.LoopStopReasonError
+	case models.LoopStateStopped:
+		return r.stopReason
+	default:
+		return ""
+	}
+}
+
+// stopReasonForRule maps the stop rule recorded in stopped_by to a reason.
+func stopReasonForRule(rule string) models.LoopStopReason {
+	switch rule {
+	case "quant":
+		return models.LoopStopReasonQuant
+	case "qual":
+		return models.LoopStopReasonQual
+	case "condition":
+		return models.LoopStopReasonCondition
+	default:
+		return models.LoopStopReasonRule
+	}
+}
+
+// runChain hands off to the loop's chain targets for reason. Failures are
+// logged per target and never fail the upstream loop.
+func (r *Runner) runChain(ctx context.Context, loop *models.Loop, reason models.LoopStopReason) {
+	if reason == "" {
+		return
+	}
+	cfg, ok := LoadChainConfig(loop)
+	if !ok {
+		return
+	}
+	for _, target := range cfg.Targets(reason) {
+		if err := r.handOff(ctx, loop, target, reason); err != nil {
+			r.logLine(loop, fmt.Sprintf("chain to %s failed: %v", target.Loop, err))
+		}
+	}
+}
+
+// handOff copies the loop's memory into the target loop, queues a message
+// with the stop reason and last work state, and starts the target if it is
+// not running.
+func (r *Runner) handOff(ctx context.Context, loop *models.Loop, target models.LoopChainTarget, reason models.LoopStopReason) error {
+	loopRepo := db.NewLoopRepository(r.DB)
+	next, err := resolveChainTarget(ctx, loopRepo, target.Loop)
+	if err != nil {
+		return err
+	}
+	if next.ID == loop.ID {
+		return errors.New("loop cannot chain to itself")
+	}
+
+	kvRepo := db.NewLoopKVRepository(r.DB)
+	mem, err := kvRepo.ListByLoop(ctx, loop.ID)
+	if err != nil {
+		return err
+	}
+	keys := make([]string, 0, len(mem))
+	for _, entry := range mem {
+		if err := kvRepo.Set(ctx, next.ID, entry.Key, entry.Value); err != nil {
+			return err
+		}
+		keys = append(keys, entry.Key)
+	}
+	sort.Strings(keys)
+
+	work, err := lastWorkState(ctx, db.NewLoopWorkStateRepository(r.DB), loop.ID)
+	if err != nil {
+		return err
+	}
+
+	handoff, err := json.Marshal(models.MessageAppendPayload{Text: handoffMessage(loop, reason, work, keys, target.Message)})
+	if err != nil {
+		return err
+	}
+	trigger, err := json.Marshal(models.TriggerPayload{Reason: fmt.Sprintf("chained from %s", loop.Name)})
+	if err != nil {
+		return err
+	}
+	if err := db.NewLoopQueueRepository(r.DB).Enqueue(ctx, next.ID,
+		&models.LoopQueueItem{Type: models.LoopQueueItemMessageAppend, Payload: handoff},
+		&models.LoopQueueItem{Type: models.LoopQueueItemTrigger, Payload: trigger},
+	); err != nil {
+		return err
+	}
+
+	started := false
+	switch next.State {
+	case models.LoopStateStopped, models.LoopStateError:
+		if r.StartLoop == nil {
+			return fmt.Errorf("loop %s is %s and no starter is configured", next.Name, next.State)
+		}
+		if err := r.StartLoop(next.ID); err != nil {
+			return err
+		}
+		started = true
+	}
+
+	message := fmt.Sprintf("chained to %s (%s): copied %d memory keys", next.Name, reason, len(keys))
+	if started {
+		message += ", started loop"
+	}
+	r.logLine(loop, message)
+	return nil
+}
+
+func resolveChainTarget(ctx context.Context, repo *db.LoopRepository, ref string) (*models.Loop, error) {
+	ref = strings.TrimSpace(ref)
+	if next, err := repo.GetByName(ctx, ref); err == nil {
+		return next, nil
+	}
+	next, err := repo.Get(ctx, ref)
+	if err != nil {
+		return nil, fmt.Errorf("chain target %q not found", ref)
+	}
+	return next, nil
+}
+
+// lastWorkState returns the loop's current work state, or else its most
+// recent one.
+func lastWorkState(ctx context.Context, repo *db.LoopWorkStateRepository, loopID string) (*models.LoopWorkState, error) {
+	current, err := repo.GetCurrent(ctx, loopID)
+	if err == nil {
+		return current, nil
+	}
+	if !errors.Is(err, db.ErrLoopWorkStateNotFound) {
+		return nil, err
+	}
+	states, err := repo.ListByLoop(ctx, loopID, 1)
+	if err != nil || len(states) == 0 {
+		return nil, err
+	}
+	return states[0], nil
+}
+
+func handoffMessage(loop *models.Loop, reason models.LoopStopReason, work *models.LoopWorkState, keys []string, note string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Handoff from loop %s (stopped: %s", loop.Name, reason)
+	if detail := strings.TrimSpace(loop.LastError); detail != "" {
+		fmt.Fprintf(&b, ", %s", detail)
+	}
+	b.WriteString(").")
+	if work != nil {
+		fmt.Fprintf(&b, "\nLast work: %s [%s]", work.TaskID, work.Status)
+		if detail := strings.TrimSpace(work.Detail); detail != "" {
+			fmt.Fprintf(&b, " %s", detail)
+		}
+	}
+	if len(keys) > 0 {
+		fmt.Fprintf(&b, "\nMemory copied from %s: %s", loop.Name, strings.Join(keys, ", "))
+	}
+	if note = strings.TrimSpace(note); note != "" {
+		fmt.Fprintf(&b, "\n%s", note)
+	}
+	return b.String()
+}
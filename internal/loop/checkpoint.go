@@ -0,0 +1,206 @@
+package loop
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/tOgg1/forge/internal/models"
+)
+
+// Checkpoint commits are written with a fixed identity so snapshots work in
+// repos without user.name/user.email configured.
+var checkpointIdentity = []string{
+	"GIT_AUTHOR_NAME=forge",
+	"GIT_AUTHOR_EMAIL=forge@localhost",
+	"GIT_COMMITTER_NAME=forge",
+	"GIT_COMMITTER_EMAIL=forge@localhost",
+}
+
+// CheckpointRefPrefix returns the ref namespace holding a loop's checkpoints.
+func CheckpointRefPrefix(name, id string) string {
+	slug := loopSlug(name)
+	if slug == "" {
+		slug = id
+	}
+	return "refs/forge/" + slug + "/"
+}
+
+// CheckpointRef returns the ref that pins a run's checkpoints. It points at the
+// "after" snapshot, whose parent is the "before" snapshot, so both stay
+// reachable.
+func CheckpointRef(name, id, runID string) string {
+	return CheckpointRefPrefix(name, id) + runID
+}
+
+// snapshotWorktree records the full working tree of dir, including untracked
+// files that are not ignored, as a commit on top of parent. The real index,
+// HEAD and branches are left untouched.
+func snapshotWorktree(dir, parent, message string) (string, error) {
+	root, tree, err := writeWorktreeTree(dir)
+	if err != nil {
+		return "", err
+	}
+
+	args := []string{"commit-tree", tree, "-m", message}
+	if parent != "" {
+		args = append(args, "-p", parent)
+	}
+	commit, err := gitCommand(root, checkpointIdentity, nil, args...)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(commit)), nil
+}
+
+// writeWorktreeTree writes the full working tree of dir, including untracked
+// files that are not ignored, as a tree object using a scratch index. It
+// returns the repo root and the tree hash.
+func writeWorktreeTree(dir string) (string, string, error) {
+	root, err := gitOutput(dir, "rev-parse", "--show-toplevel")
+	if err != nil {
+		return "", "", err
+	}
+
+	tmp, err := os.CreateTemp("", "forge-checkpoint-index-*")
+	if err != nil {
+		return "", "", err
+	}
+	tmpPath := tmp.Name()
+	defer os.Remove(tmpPath)
+
+	// Seed the scratch index from the real one so unchanged files are not rehashed.
+	if indexPath, err := gitOutput(root, "rev-parse", "--git-path", "index"); err == nil {
+		if !filepath.IsAbs(indexPath) {
+			indexPath = filepath.Join(root, indexPath)
+		}
+		if data, err := os.ReadFile(indexPath); err == nil {
+			_, _ = tmp.Write(data)
+		}
+	}
+	if err := tmp.Close(); err != nil {
+		return "", "", err
+	}
+
+	env := []string{"GIT_INDEX_FILE=" + tmpPath}
+	if _, err := gitCommand(root, env, nil, "add", "-A"); err != nil {
+		return "", "", err
+	}
+	tree, err := gitCommand(root, env, nil, "write-tree")
+	if err != nil {
+		return "", "", err
+	}
+	return root, strings.TrimSpace(string(tree)), nil
+}
+
+// beginCheckpoint snapshots the working tree before a run and records it on
+// the run.
+func beginCheckpoint(loop *models.Loop, run *models.LoopRun) error {
+	head, _ := gitOutput(loop.RepoPath, "rev-parse", "--verify", "--quiet", "HEAD")
+	commit, err := snapshotWorktree(loop.RepoPath, head, fmt.Sprintf("forge checkpoint: %s run %s (before)", loop.Name, run.ID))
+	if err != nil {
+		return err
+	}
+	ref := CheckpointRef(loop.Name, loop.ID, run.ID)
+	if _, err := gitOutput(loop.RepoPath, "update-ref", ref, commit); err != nil {
+		return err
+	}
+	run.CheckpointRef = ref
+	run.CommitBefore = commit
+	return nil
+}
+
+// finishCheckpoint snapshots the working tree after a run on top of its
+// "before" checkpoint.
+func finishCheckpoint(loop *models.Loop, run *models.LoopRun) error {
+	if run.CommitBefore == "" {
+		return nil
+	}
+	commit, err := snapshotWorktree(loop.RepoPath, run.CommitBefore, fmt.Sprintf("forge checkpoint: %s run %s (after)", loop.Name, run.ID))
+	if err != nil {
+		return err
+	}
+	if _, err := gitOutput(loop.RepoPath, "update-ref", run.CheckpointRef, commit); err != nil {
+		return err
+	}
+	run.CommitAfter = commit
+	return nil
+}
+
+func requireCheckpoints(run *models.LoopRun) error {
+	if run.CommitBefore == "" {
+		return fmt.Errorf("run %s has no checkpoint (enable loop_defaults.checkpoints)", run.ID)
+	}
+	if run.CommitAfter == "" {
+		return fmt.Errorf("run %s has no after checkpoint; it may still be running", run.ID)
+	}
+	return nil
+}
+
+// RunDiff returns the changes a run made to the working tree, optionally as a
+// diffstat or limited to paths.
+func RunDiff(repoPath string, run *models.LoopRun, stat bool, paths ...string) (string, error) {
+	if err := requireCheckpoints(run); err != nil {
+		return "", err
+	}
+	args := []string{"diff"}
+	if stat {
+		args = append(args, "--stat")
+	}
+	args = append(args, run.CommitBefore, run.CommitAfter)
+	if len(paths) > 0 {
+		args = append(append(args, "--"), paths...)
+	}
+	out, err := gitCommand(repoPath, nil, nil, args...)
+	return string(out), err
+}
+
+// RevertRun undoes the changes a run made by applying its diff in reverse to
+// the current working tree. Later edits to the same lines make the revert
+// fail without touching any file. With check, nothing is written. It returns
+// the files the run changed.
+func RevertRun(repoPath string, run *models.LoopRun, check bool) ([]string, error) {
+	if err := requireCheckpoints(run); err != nil {
+		return nil, err
+	}
+	root, err := gitOutput(repoPath, "rev-parse", "--show-toplevel")
+	if err != nil {
+		return nil, err
+	}
+
+	names, err := gitOutput(root, "diff", "--name-only", run.CommitBefore, run.CommitAfter)
+	if err != nil {
+		return nil, err
+	}
+	if names == "" {
+		return nil, nil
+	}
+	patch, err := gitCommand(root, nil, nil, "diff", "--binary", run.CommitBefore, run.CommitAfter)
+	if err != nil {
+		return nil, err
+	}
+
+	args := []string{"apply", "-R"}
+	if check {
+		args = append(args, "--check")
+	}
+	if _, err := gitCommand(root, nil, patch, args...); err != nil {
+		return nil, fmt.Errorf("changes from run %s no longer apply cleanly: %w", run.ID, err)
+	}
+	return strings.Split(names, "\n"), nil
+}
+
+// DeleteCheckpoints removes every checkpoint ref recorded for a loop.
+func DeleteCheckpoints(repoPath string, loop *models.Loop) error {
+	refs, err := gitOutput(repoPath, "for-each-ref", "--format=%(refname)", CheckpointRefPrefix(loop.Name, loop.ID))
+	if err != nil || refs == "" {
+		return err
+	}
+	for _, ref := range strings.Split(refs, "\n") {
+		if _, err := gitOutput(repoPath, "update-ref", "-d", ref); err != nil {
+			return err
+		}
+	}
+	return nil
+}
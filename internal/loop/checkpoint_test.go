@@ -0,0 +1,105 @@
+package loop
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/tOgg1/forge/internal/models"
+)
+
+func TestCheckpointDiffAndRevert(t *testing.T) {
+	repo := initTestRepo(t)
+	loopEntry := &models.Loop{ID: "loop-1", Name: "Checkpoint Loop", RepoPath: repo}
+	run := &models.LoopRun{ID: "run-1", LoopID: loopEntry.ID}
+
+	// Uncommitted work from before the run must survive the revert.
+	if err := os.WriteFile(filepath.Join(repo, "notes.txt"), []byte("mine\n"), 0o644); err != nil {
+		t.Fatalf("write notes: %v", err)
+	}
+	if err := beginCheckpoint(loopEntry, run); err != nil {
+		t.Fatalf("begin checkpoint: %v", err)
+	}
+	if run.CheckpointRef != "refs/forge/checkpoint-loop/run-1" || run.CommitBefore == "" {
+		t.Fatalf("unexpected checkpoint: %+v", run)
+	}
+
+	// The iteration edits a tracked file, adds one and commits part of its work.
+	if err := os.WriteFile(filepath.Join(repo, "generated.txt"), []byte("generated\n"), 0o644); err != nil {
+		t.Fatalf("write generated: %v", err)
+	}
+	commitFile(t, repo, "README.md", "changed by run\n", "run change")
+	if err := finishCheckpoint(loopEntry, run); err != nil {
+		t.Fatalf("finish checkpoint: %v", err)
+	}
+
+	if status, err := gitOutput(repo, "status", "--porcelain", "--", "notes.txt", "generated.txt"); err != nil || !strings.Contains(status, "?? notes.txt") {
+		t.Fatalf("expected checkpoints to leave the index alone, got %q (%v)", status, err)
+	}
+	if ref, err := gitOutput(repo, "rev-parse", run.CheckpointRef); err != nil || ref != run.CommitAfter {
+		t.Fatalf("expected %s to point at %s, got %q (%v)", run.CheckpointRef, run.CommitAfter, ref, err)
+	}
+
+	stat, err := RunDiff(repo, run, true)
+	if err != nil {
+		t.Fatalf("run diff: %v", err)
+	}
+	if !strings.Contains(stat, "README.md") || !strings.Contains(stat, "generated.txt") || strings.Contains(stat, "notes.txt") {
+		t.Fatalf("unexpected diffstat: %q", stat)
+	}
+
+	files, err := RevertRun(repo, run, false)
+	if err != nil {
+		t.Fatalf("revert: %v", err)
+	}
+	if len(files) != 2 {
+		t.Fatalf("expected 2 reverted files, got %v", files)
+	}
+	if data, _ := os.ReadFile(filepath.Join(repo, "README.md")); string(data) != "hello\n" {
+		t.Fatalf("expected README.md restored, got %q", data)
+	}
+	if _, err := os.Stat(filepath.Join(repo, "generated.txt")); !os.IsNotExist(err) {
+		t.Fatalf("expected generated.txt removed, got %v", err)
+	}
+	if data, _ := os.ReadFile(filepath.Join(repo, "notes.txt")); string(data) != "mine\n" {
+		t.Fatalf("expected pre-run notes kept, got %q", data)
+	}
+
+	if err := DeleteCheckpoints(repo, loopEntry); err != nil {
+		t.Fatalf("delete checkpoints: %v", err)
+	}
+	if _, err := gitOutput(repo, "rev-parse", "--verify", "--quiet", run.CheckpointRef); err == nil {
+		t.Fatalf("expected checkpoint ref deleted")
+	}
+}
+
+func TestRevertRunRefusesConflictingEdits(t *testing.T) {
+	repo := initTestRepo(t)
+	loopEntry := &models.Loop{ID: "loop-2", Name: "conflict", RepoPath: repo}
+	run := &models.LoopRun{ID: "run-2", LoopID: loopEntry.ID}
+
+	if err := beginCheckpoint(loopEntry, run); err != nil {
+		t.Fatalf("begin checkpoint: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(repo, "README.md"), []byte("from run\n"), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	if err := finishCheckpoint(loopEntry, run); err != nil {
+		t.Fatalf("finish checkpoint: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(repo, "README.md"), []byte("edited later\n"), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+
+	if _, err := RevertRun(repo, run, false); err == nil {
+		t.Fatalf("expected revert over later edits to fail")
+	}
+	if data, _ := os.ReadFile(filepath.Join(repo, "README.md")); string(data) != "edited later\n" {
+		t.Fatalf("expected failed revert to leave files untouched, got %q", data)
+	}
+
+	if _, err := RunDiff(repo, &models.LoopRun{ID: "run-3"}, false); err == nil {
+		t.Fatalf("expected diff without checkpoints to fail")
+	}
+}
@@ -0,0 +1,132 @@
+package loop
+
+import (
+	"encoding/json"
+
+	"github.com/tOgg1/forge/internal/models"
+)
+
+// LoopConfig returns the loop settings that a config update replaces.
+func LoopConfig(loop *models.Loop) models.LoopConfigPayload {
+	cfg := models.LoopConfigPayload{
+		BasePromptPath:    loop.BasePromptPath,
+		BasePromptMsg:     loop.BasePromptMsg,
+		IntervalSeconds:   loop.IntervalSeconds,
+		MaxIterations:     loop.MaxIterations,
+		MaxRuntimeSeconds: loop.MaxRuntimeSeconds,
+		MaxTokens:         loop.MaxTokens,
+		MaxCostUSD:        loop.MaxCostUSD,
+		PoolID:            loop.PoolID,
+		ProfileID:         loop.ProfileID,
+		Tags:              loop.Tags,
+		RestartPolicy:     loop.RestartPolicy,
+		Schedule:          loop.Schedule,
+	}
+	if stopCfg, ok := loadStopConfig(loop); ok && !stopCfg.Empty() {
+		cfg.StopConfig = &stopCfg
+	}
+	if chainCfg, ok := LoadChainConfig(loop); ok && !chainCfg.Empty() {
+		cfg.ChainConfig = &chainCfg
+	}
+	return cfg
+}
+
+// ApplyLoopConfig overwrites the loop's settings with cfg.
+func ApplyLoopConfig(loop *models.Loop, cfg models.LoopConfigPayload) {
+	loop.BasePromptPath = cfg.BasePromptPath
+	loop.BasePromptMsg = cfg.BasePromptMsg
+	loop.IntervalSeconds = cfg.IntervalSeconds
+	loop.MaxIterations = cfg.MaxIterations
+	loop.MaxRuntimeSeconds = cfg.MaxRuntimeSeconds
+	loop.MaxTokens = cfg.MaxTokens
+	loop.MaxCostUSD = cfg.MaxCostUSD
+	loop.PoolID = cfg.PoolID
+	loop.ProfileID = cfg.ProfileID
+	loop.Tags = cfg.Tags
+	loop.Schedule = cfg.Schedule
+	if cfg.RestartPolicy != "" {
+		loop.RestartPolicy = cfg.RestartPolicy
+	}
+
+	if loop.Metadata == nil {
+		loop.Metadata = make(map[string]any)
+	}
+	if cfg.StopConfig == nil {
+		delete(loop.Metadata, loopStopConfigKey)
+	} else {
+		loop.Metadata[loopStopConfigKey] = cfg.StopConfig
+	}
+	if cfg.ChainConfig == nil {
+		delete(loop.Metadata, loopChainConfigKey)
+	} else {
+		loop.Metadata[loopChainConfigKey] = cfg.ChainConfig
+	}
+}
+
+// LoopConfigChanges lists the settings that differ between two configs.
+func LoopConfigChanges(current, desired models.LoopConfigPayload) []string {
+	changes := make([]string, 0)
+	if current.BasePromptPath != desired.BasePromptPath || current.BasePromptMsg != desired.BasePromptMsg {
+		changes = append(changes, "prompt")
+	}
+	if current.IntervalSeconds != desired.IntervalSeconds {
+		changes = append(changes, "interval")
+	}
+	if current.MaxIterations != desired.MaxIterations {
+		changes = append(changes, "max_iterations")
+	}
+	if current.MaxRuntimeSeconds != desired.MaxRuntimeSeconds {
+		changes = append(changes, "max_runtime")
+	}
+	if current.MaxTokens != desired.MaxTokens {
+		changes = append(changes, "max_tokens")
+	}
+	if current.MaxCostUSD != desired.MaxCostUSD {
+		changes = append(changes, "max_cost")
+	}
+	if current.PoolID != desired.PoolID {
+		changes = append(changes, "pool")
+	}
+	if current.ProfileID != desired.ProfileID {
+		changes = append(changes, "profile")
+	}
+	if !sameStrings(current.Tags, desired.Tags) {
+		changes = append(changes, "tags")
+	}
+	if normalizeRestartPolicy(current.RestartPolicy) != normalizeRestartPolicy(desired.RestartPolicy) {
+		changes = append(changes, "restart")
+	}
+	if current.Schedule != desired.Schedule {
+		changes = append(changes, "schedule")
+	}
+	currentStop, _ := json.Marshal(current.StopConfig)
+	desiredStop, _ := json.Marshal(desired.StopConfig)
+	if string(currentStop) != string(desiredStop) {
+		changes = append(changes, "stop")
+	}
+	currentChain, _ := json.Marshal(current.ChainConfig)
+	desiredChain, _ := json.Marshal(desired.ChainConfig)
+	if string(currentChain) != string(desiredChain) {
+		changes = append(changes, "chain")
+	}
+	return changes
+}
+
+func normalizeRestartPolicy(policy models.LoopRestartPolicy) models.LoopRestartPolicy {
+	if policy == "" {
+		return models.LoopRestartNever
+	}
+	return policy
+}
+
+func sameStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
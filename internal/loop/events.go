@@ -0,0 +1,104 @@
+package loop
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/tOgg1/forge/internal/events"
+	"github.com/tOgg1/forge/internal/models"
+)
+
+// publish emits a loop event when the runner has a publisher. Events are best
+// effort: a failure to encode or persist one never interrupts the loop.
+func (r *Runner) publish(ctx context.Context, eventType models.EventType, loop *models.Loop, payload any) {
+	publishLoopEvent(ctx, r.Publisher, r.Logger, eventType, loop, payload)
+}
+
+func publishLoopEvent(ctx context.Context, publisher events.Publisher, logger zerolog.Logger, eventType models.EventType, loop *models.Loop, payload any) {
+	if publisher == nil || loop == nil {
+		return
+	}
+
+	data, err := json.Marshal(payload)
+	if err != nil {
+		logger.Warn().Err(err).Str("event_type", string(eventType)).Msg("failed to encode loop event")
+		return
+	}
+
+	publisher.Publish(ctx, &models.Event{
+		Timestamp:  time.Now().UTC(),
+		Type:       eventType,
+		EntityType: models.EntityTypeLoop,
+		EntityID:   loop.ID,
+		Payload:    data,
+		Metadata:   map[string]string{"loop_name": loop.Name},
+	})
+}
+
+func (r *Runner) publishLoopStarted(ctx context.Context, loop *models.Loop, iteration int) {
+	r.publish(ctx, models.EventTypeLoopStarted, loop, models.LoopStartedPayload{
+		LoopName:  loop.Name,
+		RepoPath:  loop.RepoPath,
+		ProfileID: loop.ProfileID,
+		PoolID:    loop.PoolID,
+		Iteration: iteration,
+	})
+}
+
+func (r *Runner) publishRunFinished(ctx context.Context, loop *models.Loop, run *models.LoopRun, profile *models.Profile, iteration, consumed int, errText string) {
+	finishedAt := time.Now().UTC()
+	if run.FinishedAt != nil {
+		finishedAt = *run.FinishedAt
+	}
+	duration := finishedAt.Sub(run.StartedAt)
+	if duration < 0 {
+		duration = 0
+	}
+	kind, _ := run.Metadata["kind"].(string)
+
+	r.publish(ctx, models.EventTypeLoopRunFinished, loop, models.LoopRunFinishedPayload{
+		LoopName:         loop.Name,
+		RunID:            run.ID,
+		Status:           run.Status,
+		ExitCode:         run.ExitCode,
+		Duration:         duration.Round(time.Millisecond).String(),
+		DurationMs:       duration.Milliseconds(),
+		ProfileID:        profile.ID,
+		ProfileName:      profile.Name,
+		Kind:             kind,
+		Iteration:        iteration,
+		MessagesConsumed: consumed,
+		Error:            errText,
+	})
+}
+
+func (r *Runner) publishStopMatched(ctx context.Context, loop *models.Loop, run *models.LoopRun, rule, decision, reason string) {
+	payload := models.LoopStopMatchedPayload{
+		LoopName: loop.Name,
+		Rule:     rule,
+		Decision: decision,
+		Reason:   reason,
+	}
+	if run != nil {
+		payload.RunID = run.ID
+	}
+	r.publish(ctx, models.EventTypeLoopStopMatched, loop, payload)
+}
+
+func (r *Runner) publishLoopWaiting(ctx context.Context, loop *models.Loop, waitUntil time.Time, reason string) {
+	r.publish(ctx, models.EventTypeLoopWaiting, loop, models.LoopWaitingPayload{
+		LoopName:  loop.Name,
+		WaitUntil: waitUntil.UTC(),
+		Reason:    reason,
+	})
+}
+
+func (r *Runner) publishLoopError(ctx context.Context, loop *models.Loop, stage string, err error) {
+	r.publish(ctx, models.EventTypeLoopError, loop, models.LoopErrorPayload{
+		LoopName: loop.Name,
+		Stage:    stage,
+		Error:    err.Error(),
+	})
+}
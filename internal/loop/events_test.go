@@ -0,0 +1,137 @@
+package loop
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/tOgg1/forge/internal/config"
+	"github.com/tOgg1/forge/internal/db"
+	"github.com/tOgg1/forge/internal/events"
+	"github.com/tOgg1/forge/internal/models"
+	"github.com/tOgg1/forge/internal/testutil"
+)
+
+func TestRunnerPublishesLoopEvents(t *testing.T) {
+	database, cleanup := testutil.NewTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	cfg := config.DefaultConfig()
+	cfg.Global.DataDir = t.TempDir()
+	cfg.Global.ConfigDir = t.TempDir()
+
+	profile := &models.Profile{Name: "pi-events", Harness: models.HarnessPi, PromptMode: models.PromptModeEnv, CommandTemplate: "pi"}
+	if err := db.NewProfileRepository(database).Create(ctx, profile); err != nil {
+		t.Fatalf("create profile: %v", err)
+	}
+	loopEntry := &models.Loop{Name: "loop-events", RepoPath: t.TempDir(), BasePromptMsg: "base", IntervalSeconds: 1, ProfileID: profile.ID}
+	if err := db.NewLoopRepository(database).Create(ctx, loopEntry); err != nil {
+		t.Fatalf("create loop: %v", err)
+	}
+	message := mustJSON(models.MessageAppendPayload{Text: "hello"})
+	if err := db.NewLoopQueueRepository(database).Enqueue(ctx, loopEntry.ID, &models.LoopQueueItem{Type: models.LoopQueueItemMessageAppend, Payload: message}); err != nil {
+		t.Fatalf("enqueue: %v", err)
+	}
+
+	eventRepo := db.NewEventRepository(database)
+	publisher := events.NewInMemoryPublisher(events.WithRepository(eventRepo))
+	var received []*models.Event
+	if err := publisher.Subscribe("test", events.Filter{EntityTypes: []models.EntityType{models.EntityTypeLoop}}, func(event *models.Event) {
+		received = append(received, event)
+	}); err != nil {
+		t.Fatalf("subscribe: %v", err)
+	}
+
+	runner := NewRunner(database, cfg)
+	runner.Publisher = publisher
+	runner.Exec = func(ctx context.Context, profile models.Profile, promptPath, promptContent, workDir string, output io.Writer) (int, string, error) {
+		return 3, "failed", errors.New("exit status 3")
+	}
+	runner.StopCheck = func(ctx context.Context, loop *models.Loop, run *models.LoopRun) (bool, string, error) {
+		return true, "tests pass", nil
+	}
+
+	if err := runner.RunLoop(ctx, loopEntry.ID); err != nil {
+		t.Fatalf("run loop: %v", err)
+	}
+
+	wantTypes := []models.EventType{models.EventTypeLoopStarted, models.EventTypeLoopRunFinished, models.EventTypeLoopStopMatched}
+	if len(received) != len(wantTypes) {
+		t.Fatalf("expected %d events, got %d", len(wantTypes), len(received))
+	}
+	for i, want := range wantTypes {
+		if received[i].Type != want || received[i].EntityID != loopEntry.ID {
+			t.Fatalf("event %d: expected %s for loop, got %s for %s", i, want, received[i].Type, received[i].EntityID)
+		}
+	}
+
+	var finished models.LoopRunFinishedPayload
+	if err := json.Unmarshal(received[1].Payload, &finished); err != nil {
+		t.Fatalf("decode run finished: %v", err)
+	}
+	if finished.ExitCode == nil || *finished.ExitCode != 3 || finished.Status != models.LoopRunStatusError {
+		t.Fatalf("unexpected run result: %+v", finished)
+	}
+	if finished.ProfileName != profile.Name || finished.MessagesConsumed != 1 || finished.Iteration != 1 || finished.Duration == "" {
+		t.Fatalf("unexpected run details: %+v", finished)
+	}
+
+	var matched models.LoopStopMatchedPayload
+	if err := json.Unmarshal(received[2].Payload, &matched); err != nil {
+		t.Fatalf("decode stop matched: %v", err)
+	}
+	if matched.Rule != "condition" || matched.Decision != "stop" || matched.Reason != "tests pass" || matched.RunID != finished.RunID {
+		t.Fatalf("unexpected stop match: %+v", matched)
+	}
+
+	entityType := models.EntityTypeLoop
+	page, err := eventRepo.Query(ctx, db.EventQuery{EntityType: &entityType, EntityID: &loopEntry.ID})
+	if err != nil {
+		t.Fatalf("query events: %v", err)
+	}
+	if len(page.Events) != len(wantTypes) {
+		t.Fatalf("expected %d persisted events, got %d", len(wantTypes), len(page.Events))
+	}
+}
+
+func TestRunnerPublishesLoopError(t *testing.T) {
+	database, cleanup := testutil.NewTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	cfg := config.DefaultConfig()
+	cfg.Global.DataDir = t.TempDir()
+
+	loopEntry := &models.Loop{Name: "loop-no-pool", RepoPath: t.TempDir(), BasePromptMsg: "base"}
+	if err := db.NewLoopRepository(database).Create(ctx, loopEntry); err != nil {
+		t.Fatalf("create loop: %v", err)
+	}
+
+	publisher := events.NewInMemoryPublisher()
+	var received []*models.Event
+	if err := publisher.Subscribe("test", events.Filter{EventTypes: []models.EventType{models.EventTypeLoopError}}, func(event *models.Event) {
+		received = append(received, event)
+	}); err != nil {
+		t.Fatalf("subscribe: %v", err)
+	}
+
+	runner := NewRunner(database, cfg)
+	runner.Publisher = publisher
+	if err := runner.RunOnce(ctx, loopEntry.ID); !errors.Is(err, ErrPoolUnavailable) {
+		t.Fatalf("expected pool unavailable, got %v", err)
+	}
+
+	if len(received) != 1 {
+		t.Fatalf("expected 1 loop.error event, got %d", len(received))
+	}
+	var payload models.LoopErrorPayload
+	if err := json.Unmarshal(received[0].Payload, &payload); err != nil {
+		t.Fatalf("decode loop error: %v", err)
+	}
+	if payload.Stage != "profile_selection" || payload.Error != ErrPoolUnavailable.Error() {
+		t.Fatalf("unexpected loop error payload: %+v", payload)
+	}
+}
@@ -0,0 +1,200 @@
+package loop
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/tOgg1/forge/internal/db"
+	"github.com/tOgg1/forge/internal/models"
+)
+
+// promptShellTimeout caps each {{sh}} snippet in a prompt template.
+const promptShellTimeout = 30 * time.Second
+
+// PromptData is the data loop prompts are rendered with each iteration.
+//
+//	{{.Loop.Name}} {{.Iteration}} {{.Mem.key}} {{.Work.TaskID}}
+//	{{env "HOME"}} {{include "docs/plan.md"}} {{sh "git log -5 --oneline"}}
+type PromptData struct {
+	Loop *models.Loop
+	// Iteration is the 1-based number of the iteration the prompt is for.
+	Iteration int
+	// Mem holds the loop's `forge mem` entries.
+	Mem map[string]string
+	// Work is the loop's current `forge work` task (zero when none is set).
+	Work models.LoopWorkState
+	Now  time.Time
+}
+
+func (r *Runner) promptData(ctx context.Context, loop *models.Loop, iteration int) (PromptData, error) {
+	data := PromptData{Loop: loop, Iteration: iteration, Mem: map[string]string{}, Now: time.Now().UTC()}
+	if r.DB == nil {
+		return data, nil
+	}
+
+	kv, err := db.NewLoopKVRepository(r.DB).ListByLoop(ctx, loop.ID)
+	if err != nil {
+		return data, err
+	}
+	for _, entry := range kv {
+		data.Mem[entry.Key] = entry.Value
+	}
+
+	current, err := db.NewLoopWorkStateRepository(r.DB).GetCurrent(ctx, loop.ID)
+	if err != nil && !errors.Is(err, db.ErrLoopWorkStateNotFound) {
+		return data, err
+	}
+	if current != nil {
+		data.Work = *current
+	}
+	return data, nil
+}
+
+// renderPromptTemplate renders content as a Go text/template. Content without
+// "{{" is returned unchanged, so plain prompts are never parsed.
+func (r *Runner) renderPromptTemplate(ctx context.Context, loop *models.Loop, content string, data PromptData) (string, error) {
+	if !strings.Contains(content, "{{") {
+		return content, nil
+	}
+
+	runCommand := r.RunCommand
+	if runCommand == nil {
+		runCommand = defaultRunCommand
+	}
+	funcs := template.FuncMap{
+		"env": os.Getenv,
+		"include": func(path string) (string, error) {
+			data, err := os.ReadFile(resolveRepoPath(loop.RepoPath, path))
+			if err != nil {
+				return "", err
+			}
+			return string(data), nil
+		},
+		"sh": func(cmd string) (string, error) {
+			res := runCommand(ctx, loop.RepoPath, cmd, promptShellTimeout)
+			if res.exitCode != 0 {
+				return "", fmt.Errorf("sh %q: exit code %d: %s", cmd, res.exitCode, strings.TrimSpace(res.stderr))
+			}
+			return strings.TrimRight(res.stdout, "\n"), nil
+		},
+	}
+
+	parsed, err := template.New("prompt").Funcs(funcs).Option("missingkey=zero").Parse(content)
+	if err != nil {
+		return "", fmt.Errorf("parse prompt template: %w", err)
+	}
+	var out strings.Builder
+	if err := parsed.Execute(&out, data); err != nil {
+		return "", fmt.Errorf("render prompt template: %w", err)
+	}
+	return out.String(), nil
+}
+
+// promptTemplateSuffix marks prompt files that are rendered as templates.
+const promptTemplateSuffix = ".tmpl"
+
+// renderPrompt renders a resolved prompt for an iteration if it opted into
+// templating; other prompts are sent verbatim, braces and all. A rendered file
+// prompt is no longer passed to the harness by path.
+func (r *Runner) renderPrompt(ctx context.Context, loop *models.Loop, prompt promptSpec, iteration int) (promptSpec, error) {
+	content, ok := templatePrompt(prompt)
+	if !ok {
+		return prompt, nil
+	}
+	data, err := r.promptData(ctx, loop, iteration)
+	if err != nil {
+		return prompt, err
+	}
+	content, err = r.renderPromptTemplate(ctx, loop, content, data)
+	if err != nil {
+		return prompt, err
+	}
+	prompt.Content = content
+	prompt.FromFile = false
+	return prompt, nil
+}
+
+// templatePrompt reports whether a prompt is a template and returns its
+// content without the front matter that marked it. Prompts opt in with a
+// leading front matter block, or with a .tmpl file suffix:
+//
+//	---
+//	template: true
+//	---
+func templatePrompt(prompt promptSpec) (string, bool) {
+	if body, ok := templateFrontMatter(prompt.Content); ok {
+		return body, true
+	}
+	if prompt.FromFile && strings.HasSuffix(prompt.Path, promptTemplateSuffix) {
+		return prompt.Content, true
+	}
+	return prompt.Content, false
+}
+
+func templateFrontMatter(content string) (string, bool) {
+	normalized := strings.ReplaceAll(content, "\r\n", "\n")
+	rest, ok := strings.CutPrefix(normalized, "---\n")
+	if !ok {
+		return content, false
+	}
+	header, body, ok := strings.Cut(rest, "\n---\n")
+	if !ok {
+		if header, ok = strings.CutSuffix(rest, "\n---"); !ok {
+			return content, false
+		}
+	}
+	for _, line := range strings.Split(header, "\n") {
+		key, value, found := strings.Cut(line, ":")
+		if found && strings.TrimSpace(key) == "template" && strings.TrimSpace(value) == "true" {
+			return body, true
+		}
+	}
+	return content, false
+}
+
+// PreviewPrompt returns the prompt the loop's next main iteration would send:
+// override (or else a queued next-prompt override, or the base prompt),
+// rendered if it is a template, followed by the loop memory and queued messages.
+// Nothing is consumed from the queue.
+func (r *Runner) PreviewPrompt(ctx context.Context, loop *models.Loop, override *models.NextPromptOverridePayload) (string, error) {
+	if r.DB == nil {
+		return "", errors.New("runner requires database")
+	}
+
+	plan, err := buildQueuePlan(ctx, db.NewLoopQueueRepository(r.DB), loop.ID, nil)
+	if err != nil {
+		return "", err
+	}
+	if override == nil {
+		override = plan.OverridePrompt
+	}
+
+	var prompt promptSpec
+	if override != nil {
+		prompt, err = resolveOverridePrompt(loop.RepoPath, *override)
+	} else {
+		prompt, err = resolveBasePrompt(loop)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	prompt, err = r.renderPrompt(ctx, loop, prompt, loopIterationCount(loop.Metadata)+1)
+	if err != nil {
+		return "", err
+	}
+
+	mem, err := buildLoopMemory(ctx, r.DB, loop.ID, 0)
+	if err != nil {
+		return "", err
+	}
+	if strings.TrimSpace(mem) != "" {
+		prompt.Content = strings.TrimRight(prompt.Content, "\n") + mem
+	}
+	return appendOperatorMessages(prompt.Content, plan.Messages), nil
+}
@@ -1,11 +1,18 @@
 package loop
 
 import (
+	"context"
+	"io"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/tOgg1/forge/internal/config"
+	"github.com/tOgg1/forge/internal/db"
 	"github.com/tOgg1/forge/internal/models"
+	"github.com/tOgg1/forge/internal/testutil"
 )
 
 func TestResolveBasePromptPrecedence(t *testing.T) {
@@ -66,3 +73,110 @@ func TestResolveBasePromptPrecedence(t *testing.T) {
 		t.Fatalf("expected default prompt path, got %q", prompt.Path)
 	}
 }
+
+func TestPreviewPromptRendersTemplate(t *testing.T) {
+	database, cleanup := testutil.NewTestDB(t)
+	defer cleanup()
+
+	repo := t.TempDir()
+	if err := os.WriteFile(filepath.Join(repo, "plan.md"), []byte("step one"), 0o644); err != nil {
+		t.Fatalf("write plan: %v", err)
+	}
+	t.Setenv("FORGE_PROMPT_TEST_ENV", "from-env")
+
+	loopRepo := db.NewLoopRepository(database)
+	loopEntry := &models.Loop{
+		Name:          "templated",
+		RepoPath:      repo,
+		BasePromptMsg: "---\ntemplate: true\n---\n" + `{{.Loop.Name}} #{{.Iteration}} task={{.Work.TaskID}} focus={{.Mem.focus}} missing=[{{.Mem.nope}}] env={{env "FORGE_PROMPT_TEST_ENV"}} plan={{include "plan.md"}} head={{sh "git rev-parse HEAD"}}`,
+		State:         models.LoopStateStopped,
+		Metadata:      map[string]any{"iteration_count": 2},
+	}
+	if err := loopRepo.Create(context.Background(), loopEntry); err != nil {
+		t.Fatalf("create loop: %v", err)
+	}
+	if err := db.NewLoopKVRepository(database).Set(context.Background(), loopEntry.ID, "focus", "tests"); err != nil {
+		t.Fatalf("set mem: %v", err)
+	}
+	if err := db.NewLoopWorkStateRepository(database).SetCurrent(context.Background(), &models.LoopWorkState{LoopID: loopEntry.ID, AgentID: "templated", TaskID: "forge-42"}); err != nil {
+		t.Fatalf("set work: %v", err)
+	}
+
+	runner := NewRunner(database, config.DefaultConfig())
+	runner.RunCommand = func(ctx context.Context, workDir, cmd string, timeout time.Duration) commandResult {
+		if workDir != repo || cmd != "git rev-parse HEAD" {
+			t.Fatalf("unexpected sh %q in %q", cmd, workDir)
+		}
+		return commandResult{stdout: "abc123\n"}
+	}
+
+	prompt, err := runner.PreviewPrompt(context.Background(), loopEntry, nil)
+	if err != nil {
+		t.Fatalf("preview prompt: %v", err)
+	}
+	want := "templated #3 task=forge-42 focus=tests missing=[] env=from-env plan=step one head=abc123"
+	if !strings.HasPrefix(prompt, want) {
+		t.Fatalf("expected prompt to start with %q, got %q", want, prompt)
+	}
+	if !strings.Contains(prompt, "## Loop Context (persistent)") {
+		t.Fatalf("expected loop memory in preview, got %q", prompt)
+	}
+
+	loopEntry.BasePromptMsg = "---\ntemplate: true\n---\n" + `{{sh "false"}}`
+	runner.RunCommand = func(ctx context.Context, workDir, cmd string, timeout time.Duration) commandResult {
+		return commandResult{exitCode: 1, stderr: "boom"}
+	}
+	if _, err := runner.PreviewPrompt(context.Background(), loopEntry, nil); err == nil || !strings.Contains(err.Error(), "boom") {
+		t.Fatalf("expected sh failure, got %v", err)
+	}
+}
+
+func TestRunnerSendsUnmarkedBracePromptsVerbatim(t *testing.T) {
+	database, cleanup := testutil.NewTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	cfg := config.DefaultConfig()
+	cfg.Global.DataDir = t.TempDir()
+	cfg.Global.ConfigDir = t.TempDir()
+
+	profile := &models.Profile{Name: "pi-braces", Harness: models.HarnessPi, PromptMode: models.PromptModeEnv, CommandTemplate: "pi"}
+	if err := db.NewProfileRepository(database).Create(ctx, profile); err != nil {
+		t.Fatalf("create profile: %v", err)
+	}
+
+	// Helm and Jinja snippets are not valid against PromptData and must not
+	// be rendered unless the prompt opts in.
+	literal := "Fix the chart: replicas: {{ .Values.replicas }} and {% if x %}{{ x }}{% endif %}"
+	repo := t.TempDir()
+	loopEntry := &models.Loop{Name: "braces", RepoPath: repo, BasePromptMsg: literal, ProfileID: profile.ID, State: models.LoopStateStopped}
+	if err := db.NewLoopRepository(database).Create(ctx, loopEntry); err != nil {
+		t.Fatalf("create loop: %v", err)
+	}
+
+	var sent string
+	runner := NewRunner(database, cfg)
+	runner.Exec = func(ctx context.Context, profile models.Profile, promptPath, promptContent, workDir string, output io.Writer) (int, string, error) {
+		sent = promptContent
+		return 0, "ok", nil
+	}
+	if err := runner.RunOnce(ctx, loopEntry.ID); err != nil {
+		t.Fatalf("run once: %v", err)
+	}
+	if !strings.HasPrefix(sent, literal) {
+		t.Fatalf("expected the prompt verbatim, got %q", sent)
+	}
+
+	// A .tmpl prompt file opts in without front matter.
+	tmplPath := filepath.Join(repo, "review.md.tmpl")
+	if err := os.WriteFile(tmplPath, []byte("review {{.Loop.Name}}"), 0o644); err != nil {
+		t.Fatalf("write template: %v", err)
+	}
+	prompt, err := runner.renderPrompt(ctx, loopEntry, promptSpec{Path: tmplPath, Content: "review {{.Loop.Name}}", FromFile: true}, 1)
+	if err != nil {
+		t.Fatalf("render .tmpl prompt: %v", err)
+	}
+	if prompt.Content != "review braces" || prompt.FromFile {
+		t.Fatalf("expected rendered .tmpl prompt, got %+v", prompt)
+	}
+}
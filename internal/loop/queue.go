@@ -19,6 +19,7 @@ type messageEntry struct {
 type queuePlan struct {
 	Messages       []messageEntry
 	OverridePrompt *models.NextPromptOverridePayload
+	ConfigUpdate   *models.LoopConfigPayload
 	StopRequested  bool
 	KillRequested  bool
 	PauseDuration  time.Duration
@@ -27,6 +28,7 @@ type queuePlan struct {
 	PauseItemIDs   []string
 	StopItemIDs    []string
 	KillItemIDs    []string
+	ConfigItemIDs  []string
 }
 
 func buildQueuePlan(ctx context.Context, repo *db.LoopQueueRepository, loopID string, steerMessages []messageEntry) (*queuePlan, error) {
@@ -86,6 +88,17 @@ func buildQueuePlan(ctx context.Context, repo *db.LoopQueueRepository, loopID st
 			}
 			plan.Messages = append(plan.Messages, messageEntry{Text: payload.Message, Timestamp: item.CreatedAt, Source: "steer"})
 			plan.ConsumeItemIDs = append(plan.ConsumeItemIDs, item.ID)
+		case models.LoopQueueItemConfigUpdate:
+			payload, err := decodePayload[models.LoopConfigPayload](item.Payload)
+			if err != nil {
+				return nil, err
+			}
+			// The most recent update wins.
+			plan.ConfigUpdate = &payload
+			plan.ConfigItemIDs = append(plan.ConfigItemIDs, item.ID)
+		case models.LoopQueueItemTrigger:
+			// The run this plan leads to is the triggered run.
+			plan.ConsumeItemIDs = append(plan.ConsumeItemIDs, item.ID)
 		default:
 			return nil, fmt.Errorf("unsupported queue item type %q", item.Type)
 		}
@@ -139,6 +152,25 @@ func consumePendingStop(ctx context.Context, repo *db.LoopQueueRepository, loopI
 	return nil
 }
 
+// hasPendingWake reports whether a stop, kill or trigger is queued, any of
+// which ends a scheduled wait early.
+func hasPendingWake(ctx context.Context, repo *db.LoopQueueRepository, loopID string) (bool, error) {
+	items, err := repo.List(ctx, loopID)
+	if err != nil {
+		return false, err
+	}
+	for _, item := range items {
+		if item.Status != models.LoopQueueStatusPending {
+			continue
+		}
+		switch item.Type {
+		case models.LoopQueueItemStopGraceful, models.LoopQueueItemKillNow, models.LoopQueueItemTrigger:
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
 func hasPendingKill(ctx context.Context, repo *db.LoopQueueRepository, loopID string) (bool, error) {
 	items, err := repo.List(ctx, loopID)
 	if err != nil {
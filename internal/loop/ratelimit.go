@@ -0,0 +1,147 @@
+package loop
+
+import (
+	"encoding/json"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/tOgg1/forge/internal/models"
+	"github.com/tOgg1/forge/internal/state"
+)
+
+// defaultRateLimitCooldown is used when no retry-after hint is found and the
+// scheduler default cooldown is not configured.
+const defaultRateLimitCooldown = 5 * time.Minute
+
+// harnessRateLimitSignals are messages a harness prints when its provider refuses
+// work for quota reasons.
+var harnessRateLimitSignals = map[models.Harness][]string{
+	models.HarnessClaude:   {"claude ai usage limit reached", "rate_limit_error", "you've reached your usage limit"},
+	models.HarnessCodex:    {"you've hit your usage limit", "usage_limit_reached", "rate limit reached for"},
+	models.HarnessOpenCode: {"ratelimiterror", "rate_limit_exceeded"},
+	models.HarnessPi:       {"rate_limit_error", "rate limit exceeded"},
+	models.HarnessDroid:    {"rate limit exceeded", "usage limit reached"},
+}
+
+// genericRateLimitPatterns match rate-limit wording from any harness. They need
+// word boundaries and HTTP status context so "foo.go:429:" or "4290 tokens"
+// do not count.
+var genericRateLimitPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`\b(?:status(?: code)?|code|http(?:/[\d.]+)?|error)[ :=]*429\b`),
+	regexp.MustCompile(`\brate[- ]limit(?:ed)?\b`),
+	regexp.MustCompile(`\btoo many requests\b`),
+	regexp.MustCompile(`\bquota exceeded\b`),
+	regexp.MustCompile(`\bresource_exhausted\b`),
+}
+
+// errorLinePrefixes start lines that report an error rather than agent prose.
+// "[error]" and "[result] error" are how decoded stream-json events render.
+var errorLinePrefixes = []string{"[error]", "[result] error", "error:", "api error", "fatal:"}
+
+// claudeResetPattern matches Claude's "usage limit reached|<unix reset time>" line.
+var claudeResetPattern = regexp.MustCompile(`usage limit reached\|(\d{10})`)
+
+// rateLimitSignal describes a detected rate limit.
+type rateLimitSignal struct {
+	Reason     string
+	RetryAfter time.Duration
+}
+
+// detectRateLimit scans a run's output tail for rate-limit messages. Agents
+// routinely print these words while working on code, so a successful run only
+// counts when the message is on an error or result line. The retry-after
+// duration is zero when the output carries no hint.
+func detectRateLimit(harness models.Harness, output string, failed bool, now time.Time) *rateLimitSignal {
+	if strings.TrimSpace(output) == "" {
+		return nil
+	}
+
+	reason := ""
+	lines := strings.Split(output, "\n")
+	for i := len(lines) - 1; i >= 0 && reason == ""; i-- {
+		line := strings.TrimSpace(lines[i])
+		if line == "" || (!failed && !isErrorLine(line)) {
+			continue
+		}
+		if matchesRateLimit(harness, strings.ToLower(line)) {
+			reason = line
+		}
+	}
+	if reason == "" {
+		return nil
+	}
+	if len(reason) > 200 {
+		reason = reason[:200]
+	}
+
+	lower := strings.ToLower(output)
+	signal := &rateLimitSignal{Reason: reason}
+	if retryAfter, ok := state.ExtractRetryAfter(lower); ok {
+		signal.RetryAfter = retryAfter
+	} else if match := claudeResetPattern.FindStringSubmatch(lower); match != nil {
+		if unix, err := strconv.ParseInt(match[1], 10, 64); err == nil {
+			if reset := time.Unix(unix, 0); reset.After(now) {
+				signal.RetryAfter = reset.Sub(now)
+			}
+		}
+	}
+	return signal
+}
+
+// matchesRateLimit reports whether a lower-cased output line carries a
+// rate-limit signal.
+func matchesRateLimit(harness models.Harness, line string) bool {
+	for _, signal := range harnessRateLimitSignals[harness] {
+		if strings.Contains(line, signal) {
+			return true
+		}
+	}
+	for _, pattern := range genericRateLimitPatterns {
+		if pattern.MatchString(line) {
+			return true
+		}
+	}
+	return false
+}
+
+// isErrorLine reports whether a line is a harness error or result rather than
+// agent prose: an error-prefixed line, Claude's usage limit line, or a JSON
+// event flagged as an error.
+func isErrorLine(line string) bool {
+	lower := strings.ToLower(line)
+	for _, prefix := range errorLinePrefixes {
+		if strings.HasPrefix(lower, prefix) {
+			return true
+		}
+	}
+	if claudeResetPattern.MatchString(lower) {
+		return true
+	}
+	if !strings.HasPrefix(line, "{") {
+		return false
+	}
+
+	var event struct {
+		Type    string          `json:"type"`
+		IsError bool            `json:"is_error"`
+		Error   json.RawMessage `json:"error"`
+	}
+	if err := json.Unmarshal([]byte(line), &event); err != nil {
+		return false
+	}
+	hasError := len(event.Error) > 0 && string(event.Error) != "null" && string(event.Error) != "false"
+	return event.IsError || hasError || event.Type == "error" || event.Type == "turn.failed"
+}
+
+// rateLimitCooldown picks how long a rate-limited profile should rest.
+func (r *Runner) rateLimitCooldown(signal *rateLimitSignal) time.Duration {
+	if signal.RetryAfter > 0 {
+		return signal.RetryAfter
+	}
+	if r.Config != nil && r.Config.Scheduler.DefaultCooldownDuration > 0 {
+		return r.Config.Scheduler.DefaultCooldownDuration
+	}
+	return defaultRateLimitCooldown
+}
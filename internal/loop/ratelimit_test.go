@@ -0,0 +1,133 @@
+package loop
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/tOgg1/forge/internal/config"
+	"github.com/tOgg1/forge/internal/db"
+	"github.com/tOgg1/forge/internal/models"
+	"github.com/tOgg1/forge/internal/testutil"
+)
+
+func TestDetectRateLimit(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+
+	cases := []struct {
+		name       string
+		harness    models.Harness
+		output     string
+		failed     bool
+		limited    bool
+		retryAfter time.Duration
+	}{
+		{name: "codex usage limit", harness: models.HarnessCodex, output: "working...\nYou've hit your usage limit. Try again in 15 minutes.", failed: true, limited: true, retryAfter: 15 * time.Minute},
+		{name: "claude reset time", harness: models.HarnessClaude, output: "Claude AI usage limit reached|1700000600", limited: true, retryAfter: 10 * time.Minute},
+		{name: "generic on failure", harness: models.HarnessPi, output: "Error: 429 Too Many Requests (retry-after: 30)", failed: true, limited: true, retryAfter: 30 * time.Second},
+		{name: "generic on success ignored", harness: models.HarnessPi, output: "added retry handling for rate limit responses", limited: false},
+		{name: "no signal", harness: models.HarnessClaude, output: "all tests pass", failed: true, limited: false},
+		{name: "http status on failure", harness: models.HarnessCodex, output: "request failed: HTTP 429", failed: true, limited: true},
+		{name: "structured error on success", harness: models.HarnessClaude, output: `{"type":"result","is_error":true,"result":"API Error: 429 rate_limit_error"}`, limited: true},
+		{name: "rendered stream error on success", harness: models.HarnessCodex, output: "[error] Rate limit reached for gpt-5. Try again in 2 minutes.", limited: true, retryAfter: 2 * time.Minute},
+		{name: "harness signal in prose on success", harness: models.HarnessClaude, output: "Handled rate_limit_error responses in client.go", limited: false},
+		{name: "compiler error line number", harness: models.HarnessPi, output: "internal/api/client.go:429:12: undefined: backoff", failed: true, limited: false},
+		{name: "token count", harness: models.HarnessPi, output: "used 4290 tokens\nexit status 1", failed: true, limited: false},
+		{name: "rate limiter code", harness: models.HarnessPi, output: "FAIL: TestRateLimiter (ratelimiter_test.go:42)", failed: true, limited: false},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			signal := detectRateLimit(tc.harness, tc.output, tc.failed, now)
+			if (signal != nil) != tc.limited {
+				t.Fatalf("expected limited=%t, got %+v", tc.limited, signal)
+			}
+			if signal != nil && signal.RetryAfter != tc.retryAfter {
+				t.Fatalf("expected retry after %s, got %s", tc.retryAfter, signal.RetryAfter)
+			}
+		})
+	}
+}
+
+func TestRunnerRateLimitCoolsProfileAndMovesToNextPoolMember(t *testing.T) {
+	database, cleanup := testutil.NewTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	cfg := config.DefaultConfig()
+	cfg.Global.DataDir = t.TempDir()
+	cfg.Global.ConfigDir = t.TempDir()
+
+	profileRepo := db.NewProfileRepository(database)
+	poolRepo := db.NewPoolRepository(database)
+	loopRepo := db.NewLoopRepository(database)
+	runRepo := db.NewLoopRunRepository(database)
+
+	limited := &models.Profile{Name: "limited", Harness: models.HarnessCodex, PromptMode: models.PromptModeEnv, CommandTemplate: "codex exec"}
+	spare := &models.Profile{Name: "spare", Harness: models.HarnessCodex, PromptMode: models.PromptModeEnv, CommandTemplate: "codex exec"}
+	for _, profile := range []*models.Profile{limited, spare} {
+		if err := profileRepo.Create(ctx, profile); err != nil {
+			t.Fatalf("create profile: %v", err)
+		}
+	}
+	pool := &models.Pool{Name: "pool-rl", Strategy: models.PoolStrategyRoundRobin}
+	if err := poolRepo.Create(ctx, pool); err != nil {
+		t.Fatalf("create pool: %v", err)
+	}
+	for i, profile := range []*models.Profile{limited, spare} {
+		if err := poolRepo.AddMember(ctx, &models.PoolMember{PoolID: pool.ID, ProfileID: profile.ID, Position: i + 1}); err != nil {
+			t.Fatalf("add member: %v", err)
+		}
+	}
+
+	loopEntry := &models.Loop{Name: "loop-rl", RepoPath: t.TempDir(), BasePromptMsg: "base", PoolID: pool.ID, State: models.LoopStateStopped}
+	if err := loopRepo.Create(ctx, loopEntry); err != nil {
+		t.Fatalf("create loop: %v", err)
+	}
+
+	runner := NewRunner(database, cfg)
+	var used []string
+	runner.Exec = func(ctx context.Context, profile models.Profile, promptPath, promptContent, workDir string, output io.Writer) (int, string, error) {
+		used = append(used, profile.Name)
+		if profile.Name == "limited" {
+			return 1, "You've hit your usage limit. Try again in 20 minutes.", errors.New("exit status 1")
+		}
+		return 0, "done", nil
+	}
+
+	if err := runner.RunOnce(ctx, loopEntry.ID); err != nil {
+		t.Fatalf("run once: %v", err)
+	}
+	if len(used) != 2 || used[0] != "limited" || used[1] != "spare" {
+		t.Fatalf("expected limited then spare, got %v", used)
+	}
+
+	runs, err := runRepo.ListByLoop(ctx, loopEntry.ID)
+	if err != nil {
+		t.Fatalf("list runs: %v", err)
+	}
+	if len(runs) != 2 {
+		t.Fatalf("expected 2 runs, got %d", len(runs))
+	}
+	statuses := map[models.LoopRunStatus]*models.LoopRun{}
+	for _, run := range runs {
+		statuses[run.Status] = run
+	}
+	rateLimited := statuses[models.LoopRunStatusRateLimited]
+	if rateLimited == nil || rateLimited.ProfileID != limited.ID || statuses[models.LoopRunStatusSuccess] == nil {
+		t.Fatalf("expected one rate_limited and one success run, got %+v", runs)
+	}
+	if rateLimited.Metadata["rate_limit_reason"] == nil {
+		t.Fatalf("expected rate limit reason in metadata, got %v", rateLimited.Metadata)
+	}
+
+	cooled, err := profileRepo.Get(ctx, limited.ID)
+	if err != nil {
+		t.Fatalf("get profile: %v", err)
+	}
+	if cooled.CooldownUntil == nil || time.Until(*cooled.CooldownUntil) < 19*time.Minute {
+		t.Fatalf("expected ~20m cooldown, got %v", cooled.CooldownUntil)
+	}
+}
@@ -0,0 +1,245 @@
+package loop
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/tOgg1/forge/internal/config"
+	"github.com/tOgg1/forge/internal/db"
+	"github.com/tOgg1/forge/internal/events"
+	"github.com/tOgg1/forge/internal/logging"
+	"github.com/tOgg1/forge/internal/models"
+)
+
+// OrphanedError prefixes the last_error of loops marked orphaned by the reaper.
+const OrphanedError = "orphaned"
+
+// RestartFunc launches a new runner process for a loop.
+type RestartFunc func(loopID string) error
+
+// Reaper finds loops whose runner died without recording a final state, marks
+// them "error: orphaned", and relaunches them when their restart policy allows.
+// Each loop is claimed with a conditional update first, so concurrent reapers
+// never act on the same loop twice.
+type Reaper struct {
+	DB     *db.DB
+	Config *config.Config
+	Logger zerolog.Logger
+	// Publisher receives loop.error events for orphaned loops; nil disables them.
+	Publisher events.Publisher
+	// Restart relaunches orphaned loops; nil disables restart policies. Only
+	// forged sets it: listings such as forge ps only mark loops orphaned.
+	Restart RestartFunc
+	// HeartbeatTimeout overrides loop_defaults.heartbeat_timeout when > 0.
+	HeartbeatTimeout time.Duration
+
+	now   func() time.Time
+	alive func(pid int) bool
+}
+
+// ReapResult describes what the reaper did with a single loop.
+type ReapResult struct {
+	LoopID    string     `json:"loop_id"`
+	LoopName  string     `json:"loop_name"`
+	Reason    string     `json:"reason"`
+	Orphaned  bool       `json:"orphaned"`
+	Restarted bool       `json:"restarted"`
+	RestartAt *time.Time `json:"restart_at,omitempty"`
+	Error     string     `json:"error,omitempty"`
+}
+
+// NewReaper creates a Reaper with default dependencies.
+func NewReaper(database *db.DB, cfg *config.Config) *Reaper {
+	return &Reaper{
+		DB:     database,
+		Config: cfg,
+		Logger: logging.Component("loop-reaper"),
+	}
+}
+
+// Reap checks every active loop once. Loops that were orphaned earlier and are
+// still waiting out their restart backoff are relaunched once it has elapsed.
+func (rp *Reaper) Reap(ctx context.Context) ([]ReapResult, error) {
+	if rp.DB == nil {
+		return nil, errors.New("reaper requires database")
+	}
+	now := time.Now().UTC()
+	if rp.now != nil {
+		now = rp.now()
+	}
+
+	loopRepo := db.NewLoopRepository(rp.DB)
+	loops, err := loopRepo.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]ReapResult, 0)
+	for _, loop := range loops {
+		switch loop.State {
+		case models.LoopStateRunning, models.LoopStateSleeping, models.LoopStateWaiting:
+			reason, orphaned := rp.orphanReason(loop, now)
+			if !orphaned {
+				continue
+			}
+			version, err := db.VersionOf(loop)
+			if err != nil {
+				return results, err
+			}
+			loop.State = models.LoopStateError
+			loop.LastError = fmt.Sprintf("%s: %s", OrphanedError, reason)
+			claimed, err := loopRepo.UpdateIfUnchanged(ctx, loop, version)
+			if err != nil {
+				return results, err
+			}
+			if !claimed {
+				// Another reaper got there first, or the runner came back.
+				continue
+			}
+			rp.Logger.Warn().Str("loop_id", loop.ID).Str("loop", loop.Name).Msg(loop.LastError)
+			publishLoopEvent(ctx, rp.Publisher, rp.Logger, models.EventTypeLoopError, loop, models.LoopErrorPayload{
+				LoopName: loop.Name,
+				Stage:    OrphanedError,
+				Error:    loop.LastError,
+			})
+
+			result := ReapResult{LoopID: loop.ID, LoopName: loop.Name, Reason: reason, Orphaned: true}
+			if err := rp.restart(ctx, loopRepo, loop, now, &result); err != nil {
+				return results, err
+			}
+			results = append(results, result)
+		case models.LoopStateError:
+			if !IsOrphaned(loop) || !rp.restartable(loop) {
+				continue
+			}
+			result := ReapResult{LoopID: loop.ID, LoopName: loop.Name, Reason: strings.TrimPrefix(loop.LastError, OrphanedError+": ")}
+			if err := rp.restart(ctx, loopRepo, loop, now, &result); err != nil {
+				return results, err
+			}
+			results = append(results, result)
+		}
+	}
+
+	return results, nil
+}
+
+// IsOrphaned reports whether the loop was marked orphaned by the reaper.
+func IsOrphaned(loop *models.Loop) bool {
+	return loop != nil && loop.State == models.LoopStateError && strings.HasPrefix(loop.LastError, OrphanedError)
+}
+
+// orphanReason reports why an active loop no longer has a live runner. A
+// stale heartbeat also catches pids recycled by an unrelated process.
+func (rp *Reaper) orphanReason(loop *models.Loop, now time.Time) (string, bool) {
+	pid, ok := runnerPID(loop)
+	if !ok {
+		return "no runner pid recorded", true
+	}
+	alive := processAlive
+	if rp.alive != nil {
+		alive = rp.alive
+	}
+	if !alive(pid) {
+		return fmt.Sprintf("runner pid %d is gone", pid), true
+	}
+	if loop.HeartbeatAt != nil && now.Sub(*loop.HeartbeatAt) > rp.heartbeatTimeout() {
+		return fmt.Sprintf("no heartbeat since %s", loop.HeartbeatAt.UTC().Format(time.RFC3339)), true
+	}
+	return "", false
+}
+
+func (rp *Reaper) heartbeatTimeout() time.Duration {
+	if rp.HeartbeatTimeout > 0 {
+		return rp.HeartbeatTimeout
+	}
+	if rp.Config != nil && rp.Config.LoopDefaults.HeartbeatTimeout > 0 {
+		return rp.Config.LoopDefaults.HeartbeatTimeout
+	}
+	return defaultHeartbeatTimeout
+}
+
+func (rp *Reaper) restartable(loop *models.Loop) bool {
+	if rp.Restart == nil {
+		return false
+	}
+	switch loop.RestartPolicy {
+	case models.LoopRestartOnFailure, models.LoopRestartAlways:
+		return true
+	default:
+		return false
+	}
+}
+
+// restart relaunches an orphaned loop unless its restart backoff is still
+// running. Recording the restart claims the loop, so only one reaper launches
+// a runner for it.
+func (rp *Reaper) restart(ctx context.Context, repo *db.LoopRepository, loop *models.Loop, now time.Time, result *ReapResult) error {
+	if !rp.restartable(loop) {
+		return nil
+	}
+	if delay := restartDelay(loop, now); delay > 0 {
+		at := now.Add(delay)
+		result.RestartAt = &at
+		return nil
+	}
+
+	version, err := db.VersionOf(loop)
+	if err != nil {
+		return err
+	}
+	recordRestart(loop, now)
+	claimed, err := repo.UpdateIfUnchanged(ctx, loop, version)
+	if err != nil {
+		return err
+	}
+	if !claimed {
+		return nil
+	}
+	if err := rp.Restart(loop.ID); err != nil {
+		result.Error = err.Error()
+		return nil
+	}
+	result.Restarted = true
+	return nil
+}
+
+// runnerPID returns the runner pid recorded in loop metadata.
+func runnerPID(loop *models.Loop) (int, bool) {
+	if loop == nil || loop.Metadata == nil {
+		return 0, false
+	}
+	switch v := loop.Metadata["pid"].(type) {
+	case float64:
+		return int(v), true
+	case int:
+		return v, true
+	case int64:
+		return int(v), true
+	case string:
+		parsed, err := strconv.Atoi(v)
+		if err != nil {
+			return 0, false
+		}
+		return parsed, true
+	default:
+		return 0, false
+	}
+}
+
+func processAlive(pid int) bool {
+	if pid <= 0 {
+		return false
+	}
+	process, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+	return process.Signal(syscall.Signal(0)) == nil
+}
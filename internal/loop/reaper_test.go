@@ -0,0 +1,195 @@
+package loop
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/tOgg1/forge/internal/config"
+	"github.com/tOgg1/forge/internal/db"
+	"github.com/tOgg1/forge/internal/models"
+	"github.com/tOgg1/forge/internal/testutil"
+)
+
+func TestReaperMarksOrphanedLoops(t *testing.T) {
+	database, cleanup := testutil.NewTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	fresh := now.Add(-time.Minute)
+	stale := now.Add(-10 * time.Minute)
+
+	loopRepo := db.NewLoopRepository(database)
+	create := func(name string, state models.LoopState, pid int, heartbeat *time.Time) *models.Loop {
+		t.Helper()
+		loopEntry := &models.Loop{Name: name, RepoPath: t.TempDir(), State: state, HeartbeatAt: heartbeat, Metadata: map[string]any{"pid": pid}}
+		if err := loopRepo.Create(ctx, loopEntry); err != nil {
+			t.Fatalf("create loop %s: %v", name, err)
+		}
+		return loopEntry
+	}
+	dead := create("dead-pid", models.LoopStateRunning, 1001, &fresh)
+	healthy := create("healthy", models.LoopStateSleeping, 1002, &fresh)
+	hung := create("stale-heartbeat", models.LoopStateWaiting, 1002, &stale)
+	stopped := create("stopped", models.LoopStateStopped, 1001, &stale)
+
+	reaper := NewReaper(database, config.DefaultConfig())
+	reaper.now = func() time.Time { return now }
+	reaper.alive = func(pid int) bool { return pid == 1002 }
+
+	results, err := reaper.Reap(ctx)
+	if err != nil {
+		t.Fatalf("reap: %v", err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("expected 2 orphaned loops, got %+v", results)
+	}
+
+	for _, tc := range []struct {
+		loop   *models.Loop
+		state  models.LoopState
+		reason string
+	}{
+		{dead, models.LoopStateError, "runner pid 1001 is gone"},
+		{healthy, models.LoopStateSleeping, ""},
+		{hung, models.LoopStateError, "no heartbeat since"},
+		{stopped, models.LoopStateStopped, ""},
+	} {
+		updated, err := loopRepo.Get(ctx, tc.loop.ID)
+		if err != nil {
+			t.Fatalf("get loop %s: %v", tc.loop.Name, err)
+		}
+		if updated.State != tc.state {
+			t.Fatalf("loop %s: expected state %s, got %s", tc.loop.Name, tc.state, updated.State)
+		}
+		if tc.reason == "" {
+			if IsOrphaned(updated) {
+				t.Fatalf("loop %s should not be orphaned", tc.loop.Name)
+			}
+			continue
+		}
+		if !IsOrphaned(updated) || !strings.Contains(updated.LastError, tc.reason) {
+			t.Fatalf("loop %s: expected orphaned error containing %q, got %q", tc.loop.Name, tc.reason, updated.LastError)
+		}
+	}
+}
+
+func TestReaperRestartsWithBackoff(t *testing.T) {
+	database, cleanup := testutil.NewTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+
+	loopRepo := db.NewLoopRepository(database)
+	loopEntry := &models.Loop{
+		Name:          "crashy",
+		RepoPath:      t.TempDir(),
+		State:         models.LoopStateRunning,
+		RestartPolicy: models.LoopRestartOnFailure,
+		Metadata:      map[string]any{"pid": 1001},
+	}
+	if err := loopRepo.Create(ctx, loopEntry); err != nil {
+		t.Fatalf("create loop: %v", err)
+	}
+
+	var restarted []string
+	reaper := NewReaper(database, config.DefaultConfig())
+	reaper.alive = func(int) bool { return false }
+	reaper.Restart = func(loopID string) error {
+		restarted = append(restarted, loopID)
+		// Simulate the relaunched runner dying straight away.
+		crashed, err := loopRepo.Get(ctx, loopID)
+		if err != nil {
+			return err
+		}
+		crashed.State = models.LoopStateRunning
+		return loopRepo.Update(ctx, crashed)
+	}
+
+	reapAt := func(at time.Time) []ReapResult {
+		t.Helper()
+		reaper.now = func() time.Time { return at }
+		results, err := reaper.Reap(ctx)
+		if err != nil {
+			t.Fatalf("reap: %v", err)
+		}
+		return results
+	}
+
+	results := reapAt(now)
+	if len(results) != 1 || !results[0].Restarted || len(restarted) != 1 {
+		t.Fatalf("expected an immediate restart, got %+v", results)
+	}
+
+	results = reapAt(now.Add(time.Second))
+	if len(results) != 1 || results[0].Restarted || results[0].RestartAt == nil {
+		t.Fatalf("expected restart to wait for backoff, got %+v", results)
+	}
+	if want := now.Add(restartBackoffBase); !results[0].RestartAt.Equal(want) {
+		t.Fatalf("expected restart at %s, got %s", want, results[0].RestartAt)
+	}
+
+	results = reapAt(now.Add(restartBackoffBase))
+	if len(results) != 1 || !results[0].Restarted || len(restarted) != 2 {
+		t.Fatalf("expected restart once backoff elapsed, got %+v", results)
+	}
+
+	updated, err := loopRepo.Get(ctx, loopEntry.ID)
+	if err != nil {
+		t.Fatalf("get loop: %v", err)
+	}
+	if count := loopRestartCount(updated.Metadata); count != 2 {
+		t.Fatalf("expected restart count 2, got %d", count)
+	}
+}
+
+func TestReaperSkipsLoopClaimedByAnotherReaper(t *testing.T) {
+	database, cleanup := testutil.NewTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+
+	loopRepo := db.NewLoopRepository(database)
+	loopEntry := &models.Loop{
+		Name:          "contended",
+		RepoPath:      t.TempDir(),
+		State:         models.LoopStateRunning,
+		RestartPolicy: models.LoopRestartAlways,
+		Metadata:      map[string]any{"pid": 1001},
+	}
+	if err := loopRepo.Create(ctx, loopEntry); err != nil {
+		t.Fatalf("create loop: %v", err)
+	}
+
+	restarts := 0
+	other := NewReaper(database, config.DefaultConfig())
+	other.now = func() time.Time { return now }
+	other.alive = func(int) bool { return false }
+	other.Restart = func(string) error { restarts++; return nil }
+
+	reaper := NewReaper(database, config.DefaultConfig())
+	reaper.now = func() time.Time { return now }
+	reaper.Restart = func(string) error { restarts++; return nil }
+	// The other reaper reaps the loop after this one has listed it.
+	reaper.alive = func(int) bool {
+		if _, err := other.Reap(ctx); err != nil {
+			t.Fatalf("other reap: %v", err)
+		}
+		return false
+	}
+
+	results, err := reaper.Reap(ctx)
+	if err != nil {
+		t.Fatalf("reap: %v", err)
+	}
+	if len(results) != 0 {
+		t.Fatalf("expected the loop to be left to the other reaper, got %+v", results)
+	}
+	if restarts != 1 {
+		t.Fatalf("expected exactly one restart, got %d", restarts)
+	}
+}
@@ -15,6 +15,7 @@ import (
 	"github.com/rs/zerolog"
 	"github.com/tOgg1/forge/internal/config"
 	"github.com/tOgg1/forge/internal/db"
+	"github.com/tOgg1/forge/internal/events"
 	"github.com/tOgg1/forge/internal/harness"
 	"github.com/tOgg1/forge/internal/logging"
 	"github.com/tOgg1/forge/internal/models"
@@ -29,6 +30,10 @@ const (
 // ExecuteFunc runs a harness execution and returns exit code, output tail, and error.
 type ExecuteFunc func(ctx context.Context, profile models.Profile, promptPath, promptContent, workDir string, output io.Writer) (int, string, error)
 
+// StopCheckFunc is evaluated after each main iteration. Returning true stops the loop
+// with the given reason.
+type StopCheckFunc func(ctx context.Context, loop *models.Loop, run *models.LoopRun) (bool, string, error)
+
 // Runner executes loop iterations for a specific loop.
 type Runner struct {
 	DB                    *db.DB
@@ -38,6 +43,19 @@ type Runner struct {
 	InterruptPollInterval time.Duration
 	Exec                  ExecuteFunc
 	RunCommand            runCommandFunc
+	// StopCheck is an optional caller-supplied stop condition (used by workflow steps).
+	StopCheck StopCheckFunc
+	// Publisher receives loop lifecycle events; nil disables them.
+	Publisher events.Publisher
+	// HeartbeatInterval overrides loop_defaults.heartbeat_interval when > 0.
+	HeartbeatInterval time.Duration
+	// StartLoop launches stopped chain targets; nil leaves them stopped.
+	StartLoop RestartFunc
+
+	// stoppedByOperator records whether the last run ended on a stop or kill request.
+	stoppedByOperator bool
+	// stopReason records why the last run stopped on its own.
+	stopReason models.LoopStopReason
 }
 
 // NewRunner creates a Runner with default dependencies.
@@ -103,9 +121,13 @@ func (r *Runner) runLoop(ctx context.Context, loopID string, singleRun bool) err
 	}
 	defer logWriter.Close()
 
+	r.stoppedByOperator = false
+	r.stopReason = ""
 	if err := r.attachLoopPID(ctx, loop, loopRepo); err != nil {
 		logWriter.WriteLine(fmt.Sprintf("warning: failed to record pid: %v", err))
 	}
+	stopHeartbeat := r.startHeartbeat(ctx, loopRepo, loop.ID)
+	defer stopHeartbeat()
 
 	maxIterations := loop.MaxIterations
 	maxRuntime := time.Duration(loop.MaxRuntimeSeconds) * time.Second
@@ -114,15 +136,21 @@ func (r *Runner) runLoop(ctx context.Context, loopID string, singleRun bool) err
 	if maxRuntime > 0 && startedAt.IsZero() {
 		startedAt = time.Now().UTC()
 		setLoopStartedAt(loop, startedAt)
-		_ = loopRepo.Update(ctx, loop)
+		_ = r.updateLoop(ctx, loopRepo, loop)
 	}
 
 	loop.State = models.LoopStateRunning
-	if err := loopRepo.Update(ctx, loop); err != nil {
+	loop.NextRunAt = nil
+	if err := r.updateLoop(ctx, loopRepo, loop); err != nil {
 		return err
 	}
 
 	logWriter.WriteLine("loop started")
+	r.publishLoopStarted(ctx, loop, iterationCount)
+
+	if !singleRun && loop.Schedule != "" {
+		r.waitForNextRun(ctx, loopRepo, queueRepo, loop, logWriter, false, runtimeDeadline(maxRuntime, startedAt))
+	}
 
 	pendingSteer := make([]messageEntry, 0)
 
@@ -130,15 +158,24 @@ func (r *Runner) runLoop(ctx context.Context, loopID string, singleRun bool) err
 		if ctx.Err() != nil {
 			logWriter.WriteLine("loop context cancelled")
 			loop.State = models.LoopStateStopped
-			_ = loopRepo.Update(ctx, loop)
+			_ = r.updateLoop(ctx, loopRepo, loop)
 			return ctx.Err()
 		}
 
-		if reason, shouldStop := loopLimitReason(maxIterations, iterationCount, maxRuntime, startedAt); shouldStop {
+		if reason, kind, shouldStop := loopLimitReason(maxIterations, iterationCount, maxRuntime, startedAt); shouldStop {
+			logWriter.WriteLine(reason)
+			r.stopReason = kind
+			loop.State = models.LoopStateStopped
+			loop.LastError = reason
+			_ = r.updateLoop(ctx, loopRepo, loop)
+			return nil
+		}
+		if reason, shouldStop := r.budgetReason(ctx, runRepo, loop); shouldStop {
 			logWriter.WriteLine(reason)
+			r.stopReason = models.LoopStopReasonBudget
 			loop.State = models.LoopStateStopped
 			loop.LastError = reason
-			_ = loopRepo.Update(ctx, loop)
+			_ = r.updateLoop(ctx, loopRepo, loop)
 			return nil
 		}
 
@@ -147,31 +184,43 @@ func (r *Runner) runLoop(ctx context.Context, loopID string, singleRun bool) err
 		if err != nil {
 			loop.State = models.LoopStateError
 			loop.LastError = err.Error()
-			_ = loopRepo.Update(ctx, loop)
+			_ = r.updateLoop(ctx, loopRepo, loop)
 			logWriter.WriteLine(fmt.Sprintf("queue planning error: %v", err))
+			r.publishLoopError(ctx, loop, "queue", err)
 			return err
 		}
 
+		if plan.ConfigUpdate != nil {
+			ApplyLoopConfig(loop, *plan.ConfigUpdate)
+			maxIterations = loop.MaxIterations
+			maxRuntime = time.Duration(loop.MaxRuntimeSeconds) * time.Second
+			logWriter.WriteLine("loop settings updated")
+			_ = r.updateLoop(ctx, loopRepo, loop)
+			_ = markQueueCompleted(ctx, queueRepo, plan.ConfigItemIDs)
+		}
+
 		if plan.StopRequested {
 			logWriter.WriteLine("graceful stop requested")
+			r.stoppedByOperator = true
 			_ = markQueueCompleted(ctx, queueRepo, plan.StopItemIDs)
 			loop.State = models.LoopStateStopped
-			_ = loopRepo.Update(ctx, loop)
+			_ = r.updateLoop(ctx, loopRepo, loop)
 			return nil
 		}
 
 		if plan.KillRequested {
 			logWriter.WriteLine("kill requested")
+			r.stoppedByOperator = true
 			_ = markQueueCompleted(ctx, queueRepo, plan.KillItemIDs)
 			loop.State = models.LoopStateStopped
-			_ = loopRepo.Update(ctx, loop)
+			_ = r.updateLoop(ctx, loopRepo, loop)
 			return nil
 		}
 
 		if plan.PauseDuration > 0 && plan.PauseBeforeRun {
 			logWriter.WriteLine(fmt.Sprintf("pause for %s", plan.PauseDuration))
 			loop.State = models.LoopStateSleeping
-			_ = loopRepo.Update(ctx, loop)
+			_ = r.updateLoop(ctx, loopRepo, loop)
 			r.sleep(ctx, plan.PauseDuration)
 			if ctx.Err() == nil {
 				_ = markQueueCompleted(ctx, queueRepo, plan.PauseItemIDs)
@@ -191,10 +240,9 @@ func (r *Runner) runLoop(ctx context.Context, loopID string, singleRun bool) err
 			if matched {
 				decision := normalizeDecision(stopCfg.Quant.Decision)
 				logWriter.WriteLine(fmt.Sprintf("quant stop matched (decision=%s exit_code=%d)", decision, res.exitCode))
+				r.publishStopMatched(ctx, loop, nil, "quant", decision, matchReason)
 				if decision == stopDecisionStop {
-					loop.State = models.LoopStateStopped
-					loop.LastError = fmt.Sprintf("quant stop: %s", matchReason)
-					_ = loopRepo.Update(ctx, loop)
+					r.stopForRule(ctx, loopRepo, loop, "quant", fmt.Sprintf("quant stop: %s", matchReason))
 					return nil
 				}
 			} else if strings.TrimSpace(matchReason) != "" {
@@ -212,8 +260,9 @@ func (r *Runner) runLoop(ctx context.Context, loopID string, singleRun bool) err
 		if err != nil {
 			loop.State = models.LoopStateError
 			loop.LastError = err.Error()
-			_ = loopRepo.Update(ctx, loop)
+			_ = r.updateLoop(ctx, loopRepo, loop)
 			logWriter.WriteLine(fmt.Sprintf("profile selection error: %v", err))
+			r.publishLoopError(ctx, loop, "profile_selection", err)
 			return err
 		}
 		if waitUntil != nil {
@@ -223,8 +272,9 @@ func (r *Runner) runLoop(ctx context.Context, loopID string, singleRun bool) err
 			loop.Metadata["wait_until"] = waitUntil.UTC().Format(time.RFC3339)
 			loop.State = models.LoopStateWaiting
 			loop.LastError = fmt.Sprintf("waiting for profile availability until %s", waitUntil.UTC().Format(time.RFC3339))
-			_ = loopRepo.Update(ctx, loop)
+			_ = r.updateLoop(ctx, loopRepo, loop)
 			logWriter.WriteLine(loop.LastError)
+			r.publishLoopWaiting(ctx, loop, *waitUntil, "waiting for profile availability")
 			r.sleepUntil(ctx, *waitUntil)
 			continue
 		}
@@ -238,8 +288,9 @@ func (r *Runner) runLoop(ctx context.Context, loopID string, singleRun bool) err
 		if err != nil {
 			loop.State = models.LoopStateError
 			loop.LastError = err.Error()
-			_ = loopRepo.Update(ctx, loop)
+			_ = r.updateLoop(ctx, loopRepo, loop)
 			logWriter.WriteLine(fmt.Sprintf("prompt resolution error: %v", err))
+			r.publishLoopError(ctx, loop, "prompt", err)
 			return err
 		}
 
@@ -248,8 +299,9 @@ func (r *Runner) runLoop(ctx context.Context, loopID string, singleRun bool) err
 			if err != nil {
 				loop.State = models.LoopStateError
 				loop.LastError = err.Error()
-				_ = loopRepo.Update(ctx, loop)
+				_ = r.updateLoop(ctx, loopRepo, loop)
 				logWriter.WriteLine(fmt.Sprintf("override prompt error: %v", err))
+				r.publishLoopError(ctx, loop, "override_prompt", err)
 				return err
 			}
 			prompt.Source = "override"
@@ -259,14 +311,25 @@ func (r *Runner) runLoop(ctx context.Context, loopID string, singleRun bool) err
 			if err != nil {
 				loop.State = models.LoopStateError
 				loop.LastError = err.Error()
-				_ = loopRepo.Update(ctx, loop)
+				_ = r.updateLoop(ctx, loopRepo, loop)
 				logWriter.WriteLine(fmt.Sprintf("qual stop prompt error: %v", err))
+				r.publishLoopError(ctx, loop, "qual_stop_prompt", err)
 				return err
 			}
 			prompt.Source = "qual_stop"
 			prompt.Override = true
 		}
 
+		prompt, err = r.renderPrompt(ctx, loop, prompt, iterationCount+1)
+		if err != nil {
+			loop.State = models.LoopStateError
+			loop.LastError = err.Error()
+			_ = r.updateLoop(ctx, loopRepo, loop)
+			logWriter.WriteLine(fmt.Sprintf("prompt template error: %v", err))
+			r.publishLoopError(ctx, loop, "prompt_template", err)
+			return err
+		}
+
 		hasMessages := len(plan.Messages) > 0
 		if mem, err := buildLoopMemory(ctx, r.DB, loop.ID, 0); err == nil {
 			if strings.TrimSpace(mem) != "" {
@@ -296,22 +359,116 @@ func (r *Runner) runLoop(ctx context.Context, loopID string, singleRun bool) err
 			_ = runRepo.Finish(ctx, run)
 			loop.State = models.LoopStateError
 			loop.LastError = err.Error()
-			_ = loopRepo.Update(ctx, loop)
+			_ = r.updateLoop(ctx, loopRepo, loop)
 			logWriter.WriteLine(fmt.Sprintf("prompt preparation error: %v", err))
+			r.publishLoopError(ctx, loop, "prompt_preparation", err)
 			return err
 		}
 
 		loop.State = models.LoopStateRunning
-		_ = loopRepo.Update(ctx, loop)
+		_ = r.updateLoop(ctx, loopRepo, loop)
 
 		logWriter.WriteLine(fmt.Sprintf("run %s start (profile=%s)", run.ID, profile.Name))
 
-		runResult, interruptResult := r.runWithInterrupt(ctx, loop, run, effectiveProfile, effectivePromptPath, effectivePromptContent, logWriter)
+		// With stream-json output the log gets the rendered events, while the
+		// artifact and usage parser still see the raw stream.
+		var runOutput io.Writer = logWriter
+		var streamParser *harness.StreamParser
+		var renderedTail *tailWriter
+		if effectiveProfile.OutputFormat == models.OutputFormatStreamJSON {
+			renderedTail = newTailWriter(r.OutputTailLines)
+			if streamParser = harness.NewStreamParser(effectiveProfile.Harness, io.MultiWriter(logWriter, renderedTail)); streamParser != nil {
+				runOutput = streamParser
+			}
+		}
+		outputArtifact, err := r.openRunArtifacts(loop, run, effectivePromptPath, effectivePromptContent)
+		if err != nil {
+			logWriter.WriteLine(fmt.Sprintf("run artifacts unavailable: %v", err))
+		} else {
+			runOutput = io.MultiWriter(runOutput, outputArtifact)
+		}
+		usageParser := harness.NewUsageParser()
+		runOutput = io.MultiWriter(runOutput, usageParser)
+
+		checkpoints := r.Config.LoopDefaults.Checkpoints && isGitRepo(loop.RepoPath)
+		if checkpoints {
+			if err := beginCheckpoint(loop, run); err != nil {
+				logWriter.WriteLine(fmt.Sprintf("checkpoint before run failed: %v", err))
+			}
+		}
+		stopRules := runKind == "main" && stopCfgOK && len(stopCfg.Rules) > 0
+		ruleRun := stopRuleRun{run: run}
+		if stopRules && needsWorktreeFingerprint(stopCfg) {
+			ruleRun.treeBefore = worktreeFingerprint(loop.RepoPath)
+		}
+
+		runResult, interruptResult := r.runWithInterrupt(ctx, loop, run, effectiveProfile, effectivePromptPath, effectivePromptContent, runOutput)
+		if checkpoints {
+			if err := finishCheckpoint(loop, run); err != nil {
+				logWriter.WriteLine(fmt.Sprintf("checkpoint after run failed: %v", err))
+			}
+		}
+		if ruleRun.treeBefore != "" {
+			ruleRun.treeAfter = worktreeFingerprint(loop.RepoPath)
+		}
+		if outputArtifact != nil {
+			if err := outputArtifact.Close(); err != nil {
+				logWriter.WriteLine(fmt.Sprintf("run output artifact close failed: %v", err))
+			}
+		}
+
+		if streamParser != nil {
+			streamParser.Flush()
+			runResult.outputTail = outputTailOrFallback(renderedTail.String(), runResult.outputTail)
+			if err := r.storeRunEvents(loop, run, streamParser.Events()); err != nil {
+				logWriter.WriteLine(fmt.Sprintf("run events unavailable: %v", err))
+			}
+		}
 
 		run.Status = runResult.status
 		run.ExitCode = &runResult.exitCode
 		run.OutputTail = runResult.outputTail
+		if usage, ok := usageParser.Usage(); ok {
+			run.Usage = usage
+			logWriter.WriteLine(fmt.Sprintf("run %s usage: %d tokens, $%.4f", run.ID, usage.TotalTokens, usage.CostUSD))
+		}
+
+		var rateLimit *rateLimitSignal
+		if interruptResult == nil && ctx.Err() == nil {
+			rateLimit = detectRateLimit(profile.Harness, runResult.outputTail, runResult.status != models.LoopRunStatusSuccess, time.Now().UTC())
+		}
+		if rateLimit != nil {
+			run.Status = models.LoopRunStatusRateLimited
+			if run.Metadata == nil {
+				run.Metadata = make(map[string]any)
+			}
+			run.Metadata["rate_limit_reason"] = rateLimit.Reason
+		}
 		_ = runRepo.Finish(ctx, run)
+		if err := r.pruneRunArtifacts(ctx, runRepo, loop.ID, time.Now().UTC()); err != nil {
+			logWriter.WriteLine(fmt.Sprintf("run artifact retention failed: %v", err))
+		}
+		if rateLimit != nil {
+			r.publishRunFinished(ctx, loop, run, profile, iterationCount, 0, rateLimit.Reason)
+		} else {
+			r.publishRunFinished(ctx, loop, run, profile, iterationCount+1, len(plan.ConsumeItemIDs), runResult.errText)
+		}
+
+		// A rate-limited run does not count as an iteration: rest the profile and go
+		// straight back to selection, which picks the next available pool member.
+		if rateLimit != nil {
+			until := time.Now().UTC().Add(r.rateLimitCooldown(rateLimit))
+			if err := profileRepo.SetCooldown(ctx, profile.ID, &until); err != nil {
+				logWriter.WriteLine(fmt.Sprintf("profile cooldown failed: %v", err))
+			}
+			logWriter.WriteLine(fmt.Sprintf("run %s rate limited (profile=%s): %s; cooldown until %s", run.ID, profile.Name, rateLimit.Reason, until.Format(time.RFC3339)))
+
+			loop.LastRunAt = run.FinishedAt
+			loop.LastExitCode = run.ExitCode
+			loop.LastError = fmt.Sprintf("rate limited on profile %s until %s", profile.Name, until.Format(time.RFC3339))
+			_ = r.updateLoop(ctx, loopRepo, loop)
+			continue
+		}
 
 		if run.FinishedAt != nil {
 			loop.LastRunAt = run.FinishedAt
@@ -323,6 +480,9 @@ func (r *Runner) runLoop(ctx context.Context, loopID string, singleRun bool) err
 		loop.State = models.LoopStateSleeping
 		iterationCount++
 		setLoopIterationCount(loop, iterationCount)
+		if run.Status == models.LoopRunStatusSuccess {
+			resetRestartCount(loop)
+		}
 
 		stopState = loadStopState(loop)
 		switch runKind {
@@ -335,7 +495,7 @@ func (r *Runner) runLoop(ctx context.Context, loopID string, singleRun bool) err
 		}
 		saveStopState(loop, stopState)
 
-		_ = loopRepo.Update(ctx, loop)
+		_ = r.updateLoop(ctx, loopRepo, loop)
 
 		_ = markQueueCompleted(ctx, queueRepo, plan.ConsumeItemIDs)
 
@@ -346,8 +506,9 @@ func (r *Runner) runLoop(ctx context.Context, loopID string, singleRun bool) err
 		skipSleep := false
 		if interruptResult != nil && interruptResult.killOnly {
 			logWriter.WriteLine("run interrupted: kill")
+			r.stoppedByOperator = true
 			loop.State = models.LoopStateStopped
-			_ = loopRepo.Update(ctx, loop)
+			_ = r.updateLoop(ctx, loopRepo, loop)
 			return nil
 		}
 		if interruptResult != nil && interruptResult.steerMessage != "" {
@@ -362,36 +523,42 @@ func (r *Runner) runLoop(ctx context.Context, loopID string, singleRun bool) err
 
 		if killRequested, _ := hasPendingKill(ctx, queueRepo, loop.ID); killRequested {
 			logWriter.WriteLine("kill queued")
+			r.stoppedByOperator = true
 			_ = consumePendingKill(ctx, queueRepo, loop.ID)
 			loop.State = models.LoopStateStopped
-			_ = loopRepo.Update(ctx, loop)
+			_ = r.updateLoop(ctx, loopRepo, loop)
 			return nil
 		}
 
 		if stopRequested, _ := hasPendingStop(ctx, queueRepo, loop.ID); stopRequested {
 			logWriter.WriteLine("graceful stop queued")
+			r.stoppedByOperator = true
 			_ = consumePendingStop(ctx, queueRepo, loop.ID)
 			loop.State = models.LoopStateStopped
-			_ = loopRepo.Update(ctx, loop)
+			_ = r.updateLoop(ctx, loopRepo, loop)
 			return nil
 		}
 
 		if runKind == "qual_stop" && stopCfgOK && stopCfg.Qual != nil {
-			signal, ok := parseQualSignal(runResult.outputTail)
+			qualOutput := runResult.outputTail
+			if streamParser != nil {
+				if result, ok := streamParser.Result(); ok {
+					qualOutput = result
+				}
+			}
+			signal, ok := parseQualSignal(qualOutput)
 			if !ok {
 				onInvalid := normalizeOnInvalid(stopCfg.Qual.OnInvalid)
 				logWriter.WriteLine(fmt.Sprintf("qual stop invalid output (on_invalid=%s)", onInvalid))
 				if onInvalid == stopDecisionStop {
-					loop.State = models.LoopStateStopped
-					loop.LastError = "qual stop: invalid output (expected 0 or 1)"
-					_ = loopRepo.Update(ctx, loop)
+					r.publishStopMatched(ctx, loop, run, "qual", stopDecisionStop, "invalid output (expected 0 or 1)")
+					r.stopForRule(ctx, loopRepo, loop, "qual", "qual stop: invalid output (expected 0 or 1)")
 					return nil
 				}
 			} else if signal == 0 {
 				logWriter.WriteLine("qual stop signaled stop (0)")
-				loop.State = models.LoopStateStopped
-				loop.LastError = "qual stop: signaled stop"
-				_ = loopRepo.Update(ctx, loop)
+				r.publishStopMatched(ctx, loop, run, "qual", stopDecisionStop, "signaled stop")
+				r.stopForRule(ctx, loopRepo, loop, "qual", "qual stop: signaled stop")
 				return nil
 			} else {
 				logWriter.WriteLine("qual stop signaled continue (1)")
@@ -407,10 +574,9 @@ func (r *Runner) runLoop(ctx context.Context, loopID string, singleRun bool) err
 			if matched {
 				decision := normalizeDecision(stopCfg.Quant.Decision)
 				logWriter.WriteLine(fmt.Sprintf("quant stop matched (decision=%s exit_code=%d)", decision, res.exitCode))
+				r.publishStopMatched(ctx, loop, run, "quant", decision, matchReason)
 				if decision == stopDecisionStop {
-					loop.State = models.LoopStateStopped
-					loop.LastError = fmt.Sprintf("quant stop: %s", matchReason)
-					_ = loopRepo.Update(ctx, loop)
+					r.stopForRule(ctx, loopRepo, loop, "quant", fmt.Sprintf("quant stop: %s", matchReason))
 					return nil
 				}
 			} else if strings.TrimSpace(matchReason) != "" {
@@ -418,6 +584,33 @@ func (r *Runner) runLoop(ctx context.Context, loopID string, singleRun bool) err
 			}
 		}
 
+		if runKind == "main" && r.StopCheck != nil {
+			matched, reason, err := r.StopCheck(ctx, loop, run)
+			if err != nil {
+				logWriter.WriteLine(fmt.Sprintf("stop check error: %v", err))
+			} else if matched {
+				logWriter.WriteLine(fmt.Sprintf("stop condition matched: %s", reason))
+				r.publishStopMatched(ctx, loop, run, "condition", stopDecisionStop, reason)
+				r.stopForRule(ctx, loopRepo, loop, "condition", fmt.Sprintf("stop condition: %s", reason))
+				return nil
+			} else if strings.TrimSpace(reason) != "" {
+				logWriter.WriteLine(fmt.Sprintf("stop condition not matched: %s", reason))
+			}
+		}
+
+		if stopRules {
+			ruleRun.output = runResult.outputTail
+			stopped, pause := r.applyStopRules(ctx, loopRepo, loop, stopCfg, ruleRun, logWriter)
+			if stopped {
+				return nil
+			}
+			if pause > 0 && !singleRun {
+				logWriter.WriteLine(fmt.Sprintf("pause for %s", pause))
+				r.sleep(ctx, pause)
+				skipSleep = true
+			}
+		}
+
 		if runKind == "main" && !singleRun && stopCfgOK && stopCfg.Qual != nil {
 			stopState = loadStopState(loop)
 			if qualDue(stopCfg.Qual, stopState) {
@@ -426,18 +619,27 @@ func (r *Runner) runLoop(ctx context.Context, loopID string, singleRun bool) err
 			}
 		}
 
-		if reason, shouldStop := loopLimitReason(maxIterations, iterationCount, maxRuntime, startedAt); shouldStop {
+		if reason, kind, shouldStop := loopLimitReason(maxIterations, iterationCount, maxRuntime, startedAt); shouldStop {
+			logWriter.WriteLine(reason)
+			r.stopReason = kind
+			loop.State = models.LoopStateStopped
+			loop.LastError = reason
+			_ = r.updateLoop(ctx, loopRepo, loop)
+			return nil
+		}
+		if reason, shouldStop := r.budgetReason(ctx, runRepo, loop); shouldStop {
 			logWriter.WriteLine(reason)
+			r.stopReason = models.LoopStopReasonBudget
 			loop.State = models.LoopStateStopped
 			loop.LastError = reason
-			_ = loopRepo.Update(ctx, loop)
+			_ = r.updateLoop(ctx, loopRepo, loop)
 			return nil
 		}
 
 		if plan.PauseDuration > 0 && !plan.PauseBeforeRun {
 			logWriter.WriteLine(fmt.Sprintf("pause for %s", plan.PauseDuration))
 			loop.State = models.LoopStateSleeping
-			_ = loopRepo.Update(ctx, loop)
+			_ = r.updateLoop(ctx, loopRepo, loop)
 			r.sleep(ctx, plan.PauseDuration)
 			if ctx.Err() == nil {
 				_ = markQueueCompleted(ctx, queueRepo, plan.PauseItemIDs)
@@ -447,13 +649,12 @@ func (r *Runner) runLoop(ctx context.Context, loopID string, singleRun bool) err
 
 		if singleRun {
 			loop.State = models.LoopStateStopped
-			_ = loopRepo.Update(ctx, loop)
+			_ = r.updateLoop(ctx, loopRepo, loop)
 			return nil
 		}
 
 		if !skipSleep {
-			interval := time.Duration(loop.IntervalSeconds) * time.Second
-			r.sleep(ctx, interval)
+			r.waitForNextRun(ctx, loopRepo, queueRepo, loop, logWriter, true, runtimeDeadline(maxRuntime, startedAt))
 		}
 	}
 }
@@ -478,7 +679,7 @@ func (r *Runner) preparePrompt(loop *models.Loop, run *models.LoopRun, profile *
 	return promptPath, promptContent, nil
 }
 
-func (r *Runner) runWithInterrupt(ctx context.Context, loop *models.Loop, run *models.LoopRun, profile *models.Profile, promptPath, promptContent string, logWriter *loopLogger) (runResult, *interruptResult) {
+func (r *Runner) runWithInterrupt(ctx context.Context, loop *models.Loop, run *models.LoopRun, profile *models.Profile, promptPath, promptContent string, output io.Writer) (runResult, *interruptResult) {
 	resultCh := make(chan runResult, 1)
 	interruptCh := make(chan interruptResult, 1)
 
@@ -491,7 +692,7 @@ func (r *Runner) runWithInterrupt(ctx context.Context, loop *models.Loop, run *m
 
 	go func() {
 		outputWriter := newTailWriter(r.OutputTailLines)
-		writer := io.MultiWriter(logWriter, outputWriter)
+		writer := io.MultiWriter(output, outputWriter)
 		exitCode, outputTail, err := r.Exec(runCtx, *profile, promptPath, promptContent, loop.RepoPath, writer)
 		resultCh <- runResult{
 			status:     statusFromResult(err),
@@ -558,11 +759,16 @@ func (r *Runner) attachLoopPID(ctx context.Context, loop *models.Loop, repo *db.
 		loop.Metadata = make(map[string]any)
 	}
 	loop.Metadata["pid"] = os.Getpid()
-	loop.Metadata["started_at"] = time.Now().UTC().Format(time.RFC3339)
-	loop.Metadata["iteration_count"] = 0
+	// A restart continues the interrupted run, so max_iterations and
+	// max_runtime keep counting from where it left off.
+	if !takeRestartPending(loop) {
+		loop.Metadata["started_at"] = time.Now().UTC().Format(time.RFC3339)
+		loop.Metadata["iteration_count"] = 0
+	}
 	// Loop "smart stop" state is runtime-scoped; keep config but reset counters.
 	resetStopState(loop)
-	return repo.Update(ctx, loop)
+	delete(loop.Metadata, StoppedByKey)
+	return r.updateLoop(ctx, repo, loop)
 }
 
 func loopIterationCount(metadata map[string]any) int {
@@ -627,12 +833,36 @@ func setLoopStartedAt(loop *models.Loop, startedAt time.Time) {
 	loop.Metadata["started_at"] = startedAt.UTC().Format(time.RFC3339)
 }
 
-func loopLimitReason(maxIterations, iterationCount int, maxRuntime time.Duration, startedAt time.Time) (string, bool) {
+func loopLimitReason(maxIterations, iterationCount int, maxRuntime time.Duration, startedAt time.Time) (string, models.LoopStopReason, bool) {
 	if maxIterations > 0 && iterationCount >= maxIterations {
-		return fmt.Sprintf("max iterations reached (%d)", maxIterations), true
+		return fmt.Sprintf("max iterations reached (%d)", maxIterations), models.LoopStopReasonMaxIterations, true
 	}
 	if maxRuntime > 0 && time.Since(startedAt) >= maxRuntime {
-		return fmt.Sprintf("max runtime reached (%s)", maxRuntime), true
+		return fmt.Sprintf("max runtime reached (%s)", maxRuntime), models.LoopStopReasonMaxRuntime, true
+	}
+	return "", "", false
+}
+
+// budgetReason reports whether the loop's runs have used up its token or cost
+// budget. Budgets count every run of the loop, across restarts.
+func (r *Runner) budgetReason(ctx context.Context, runRepo *db.LoopRunRepository, loop *models.Loop) (string, bool) {
+	if loop.MaxTokens <= 0 && loop.MaxCostUSD <= 0 {
+		return "", false
+	}
+	spent, err := runRepo.UsageByLoop(ctx, loop.ID)
+	if err != nil {
+		r.Logger.Warn().Err(err).Str("loop_id", loop.ID).Msg("failed to load loop usage")
+		return "", false
+	}
+	return loopBudgetReason(loop, spent)
+}
+
+func loopBudgetReason(loop *models.Loop, spent models.LoopRunUsage) (string, bool) {
+	if loop.MaxTokens > 0 && spent.TotalTokens >= loop.MaxTokens {
+		return fmt.Sprintf("max tokens reached (%d of %d)", spent.TotalTokens, loop.MaxTokens), true
+	}
+	if loop.MaxCostUSD > 0 && spent.CostUSD >= loop.MaxCostUSD {
+		return fmt.Sprintf("max cost reached ($%.2f of $%.2f)", spent.CostUSD, loop.MaxCostUSD), true
 	}
 	return "", false
 }
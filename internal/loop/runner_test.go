@@ -3,6 +3,8 @@ package loop
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"io"
 	"os"
 	"path/filepath"
@@ -173,6 +175,92 @@ func TestRunnerClaudeHarnessRunsLoop(t *testing.T) {
 	}
 }
 
+func TestRunnerStreamJSONRecordsEvents(t *testing.T) {
+	database, cleanup := testutil.NewTestDB(t)
+	defer cleanup()
+
+	cfg := config.DefaultConfig()
+	cfg.Global.DataDir = t.TempDir()
+	cfg.Global.ConfigDir = t.TempDir()
+
+	profileRepo := db.NewProfileRepository(database)
+	loopRepo := db.NewLoopRepository(database)
+	runRepo := db.NewLoopRunRepository(database)
+
+	profile := &models.Profile{
+		Name:            "claude-stream",
+		Harness:         models.HarnessClaude,
+		PromptMode:      models.PromptModeEnv,
+		OutputFormat:    models.OutputFormatStreamJSON,
+		CommandTemplate: "claude -p \"$FORGE_PROMPT_CONTENT\" --output-format stream-json --verbose",
+		MaxConcurrency:  1,
+	}
+	if err := profileRepo.Create(context.Background(), profile); err != nil {
+		t.Fatalf("create profile: %v", err)
+	}
+
+	loopEntry := &models.Loop{
+		Name:          "stream-loop",
+		RepoPath:      t.TempDir(),
+		BasePromptMsg: "base",
+		ProfileID:     profile.ID,
+		State:         models.LoopStateStopped,
+	}
+	if err := loopRepo.Create(context.Background(), loopEntry); err != nil {
+		t.Fatalf("create loop: %v", err)
+	}
+
+	runner := NewRunner(database, cfg)
+	runner.Exec = func(ctx context.Context, p models.Profile, promptPath, promptContent, workDir string, output io.Writer) (int, string, error) {
+		fmt.Fprintln(output, `{"type":"assistant","message":{"content":[{"type":"tool_use","id":"t1","name":"Write","input":{"file_path":"notes.md","content":"hi"}}]}}`)
+		fmt.Fprintln(output, `{"type":"result","subtype":"success","result":"Wrote notes.","usage":{"input_tokens":3,"output_tokens":4}}`)
+		return 0, "", nil
+	}
+
+	if err := runner.RunOnce(context.Background(), loopEntry.ID); err != nil {
+		t.Fatalf("run once: %v", err)
+	}
+
+	runs, err := runRepo.ListByLoop(context.Background(), loopEntry.ID)
+	if err != nil || len(runs) != 1 {
+		t.Fatalf("expected 1 run, got %d (%v)", len(runs), err)
+	}
+	run := runs[0]
+	if run.EventsPath == "" {
+		t.Fatalf("expected events artifact to be linked")
+	}
+	events, err := ReadRunEvents(run.EventsPath)
+	if err != nil {
+		t.Fatalf("read events: %v", err)
+	}
+	if len(events) != 2 || events[0].Kind != models.LoopRunEventFileEdit || events[0].Path != "notes.md" || events[1].Text != "Wrote notes." {
+		t.Fatalf("unexpected events %+v", events)
+	}
+	if !strings.Contains(run.OutputTail, "[edit] Write notes.md") {
+		t.Fatalf("expected rendered output tail, got %q", run.OutputTail)
+	}
+	if run.Usage.TotalTokens != 7 {
+		t.Fatalf("expected usage from the raw stream, got %+v", run.Usage)
+	}
+
+	current, err := loopRepo.Get(context.Background(), loopEntry.ID)
+	if err != nil {
+		t.Fatalf("get loop: %v", err)
+	}
+	logData, err := os.ReadFile(current.LogPath)
+	if err != nil {
+		t.Fatalf("read log: %v", err)
+	}
+	if !strings.Contains(string(logData), "[result] success: Wrote notes.") || strings.Contains(string(logData), `"type":"result"`) {
+		t.Fatalf("expected rendered events in the loop log, got:\n%s", logData)
+	}
+
+	output, err := ReadRunArtifact(run.OutputPath)
+	if err != nil || !strings.Contains(output, `"type":"result"`) {
+		t.Fatalf("expected raw stream in the output artifact, got %q (%v)", output, err)
+	}
+}
+
 func TestRunnerInjectsLoopEnv(t *testing.T) {
 	database, cleanup := testutil.NewTestDB(t)
 	defer cleanup()
@@ -505,6 +593,77 @@ func TestRunnerMaxIterationsStopsLoop(t *testing.T) {
 	}
 }
 
+func TestRunnerStopCheckStopsLoop(t *testing.T) {
+	database, cleanup := testutil.NewTestDB(t)
+	defer cleanup()
+
+	repoDir := t.TempDir()
+	cfg := config.DefaultConfig()
+	cfg.Global.DataDir = t.TempDir()
+	cfg.Global.ConfigDir = t.TempDir()
+
+	profileRepo := db.NewProfileRepository(database)
+	loopRepo := db.NewLoopRepository(database)
+	runRepo := db.NewLoopRunRepository(database)
+
+	profile := &models.Profile{
+		Name:            "stop-check-profile",
+		Harness:         models.HarnessPi,
+		PromptMode:      models.PromptModeEnv,
+		CommandTemplate: "pi -p \"$FORGE_PROMPT_CONTENT\"",
+		MaxConcurrency:  1,
+	}
+	if err := profileRepo.Create(context.Background(), profile); err != nil {
+		t.Fatalf("create profile: %v", err)
+	}
+
+	loopEntry := &models.Loop{
+		Name:            "loop-stop-check",
+		RepoPath:        repoDir,
+		BasePromptMsg:   "base",
+		IntervalSeconds: 0,
+		MaxIterations:   10,
+		ProfileID:       profile.ID,
+		State:           models.LoopStateStopped,
+	}
+	if err := loopRepo.Create(context.Background(), loopEntry); err != nil {
+		t.Fatalf("create loop: %v", err)
+	}
+
+	runner := NewRunner(database, cfg)
+	runner.Exec = func(ctx context.Context, profile models.Profile, promptPath, promptContent, workDir string, output io.Writer) (int, string, error) {
+		return 0, "ok", nil
+	}
+	checks := 0
+	runner.StopCheck = func(ctx context.Context, loop *models.Loop, run *models.LoopRun) (bool, string, error) {
+		checks++
+		if run.OutputTail != "ok" {
+			t.Fatalf("expected finished run passed to stop check, got %+v", run)
+		}
+		return checks >= 2, fmt.Sprintf("check %d", checks), nil
+	}
+
+	if err := runner.RunLoop(context.Background(), loopEntry.ID); err != nil {
+		t.Fatalf("run loop: %v", err)
+	}
+
+	runs, err := runRepo.ListByLoop(context.Background(), loopEntry.ID)
+	if err != nil {
+		t.Fatalf("list runs: %v", err)
+	}
+	if len(runs) != 2 {
+		t.Fatalf("expected 2 runs, got %d", len(runs))
+	}
+
+	updated, err := loopRepo.Get(context.Background(), loopEntry.ID)
+	if err != nil {
+		t.Fatalf("get loop: %v", err)
+	}
+	if updated.State != models.LoopStateStopped || updated.LastError != "stop condition: check 2" {
+		t.Fatalf("expected loop stopped by stop condition, got %s (%s)", updated.State, updated.LastError)
+	}
+}
+
 func TestRunnerMaxRuntimeStopsLoop(t *testing.T) {
 	database, cleanup := testutil.NewTestDB(t)
 	defer cleanup()
@@ -883,6 +1042,166 @@ func TestRunnerQualStopRunsAsNextIterationAndStops(t *testing.T) {
 	}
 }
 
+func TestRunnerStopRuleSameOutputStopsLoop(t *testing.T) {
+	database, cleanup := testutil.NewTestDB(t)
+	defer cleanup()
+
+	repoDir := t.TempDir()
+	cfg := config.DefaultConfig()
+	cfg.Global.DataDir = t.TempDir()
+	cfg.Global.ConfigDir = t.TempDir()
+
+	profileRepo := db.NewProfileRepository(database)
+	loopRepo := db.NewLoopRepository(database)
+	runRepo := db.NewLoopRunRepository(database)
+
+	profile := &models.Profile{
+		Name:            "stop-rule-profile",
+		Harness:         models.HarnessPi,
+		PromptMode:      models.PromptModeEnv,
+		CommandTemplate: "pi -p \"$FORGE_PROMPT_CONTENT\"",
+		MaxConcurrency:  1,
+	}
+	if err := profileRepo.Create(context.Background(), profile); err != nil {
+		t.Fatalf("create profile: %v", err)
+	}
+
+	stopCfg := models.LoopStopConfig{
+		Rules: []models.LoopStopRule{
+			{Name: "stuck", Kind: models.LoopStopRuleSameOutput, Count: 3},
+			{Kind: models.LoopStopRuleFailures, Count: 2},
+		},
+	}
+
+	loopEntry := &models.Loop{
+		Name:            "loop-stop-rule-same-output",
+		RepoPath:        repoDir,
+		BasePromptMsg:   "base",
+		IntervalSeconds: 0,
+		MaxIterations:   10,
+		ProfileID:       profile.ID,
+		State:           models.LoopStateStopped,
+		Metadata:        map[string]any{"stop_config": stopCfg},
+	}
+	if err := loopRepo.Create(context.Background(), loopEntry); err != nil {
+		t.Fatalf("create loop: %v", err)
+	}
+
+	runner := NewRunner(database, cfg)
+	runner.Exec = func(ctx context.Context, profile models.Profile, promptPath, promptContent, workDir string, output io.Writer) (int, string, error) {
+		_, _ = io.WriteString(output, "nothing left to do\n")
+		return 0, "nothing left to do\n", nil
+	}
+
+	if err := runner.RunLoop(context.Background(), loopEntry.ID); err != nil {
+		t.Fatalf("run loop: %v", err)
+	}
+
+	runs, err := runRepo.ListByLoop(context.Background(), loopEntry.ID)
+	if err != nil {
+		t.Fatalf("list runs: %v", err)
+	}
+	if len(runs) != 3 {
+		t.Fatalf("expected 3 runs, got %d", len(runs))
+	}
+
+	updated, err := loopRepo.Get(context.Background(), loopEntry.ID)
+	if err != nil {
+		t.Fatalf("get loop: %v", err)
+	}
+	if updated.State != models.LoopStateStopped {
+		t.Fatalf("expected loop stopped, got %s", updated.State)
+	}
+	if StoppedBy(updated) != "stuck" {
+		t.Fatalf("expected loop stopped by rule stuck, got %q", StoppedBy(updated))
+	}
+	if !strings.Contains(updated.LastError, "same output 3 times in a row") {
+		t.Fatalf("expected same output last error, got %q", updated.LastError)
+	}
+}
+
+func TestRunnerStopRuleSwitchesPromptBeforeStopping(t *testing.T) {
+	database, cleanup := testutil.NewTestDB(t)
+	defer cleanup()
+
+	repoDir := t.TempDir()
+	cfg := config.DefaultConfig()
+	cfg.Global.DataDir = t.TempDir()
+	cfg.Global.ConfigDir = t.TempDir()
+
+	profileRepo := db.NewProfileRepository(database)
+	loopRepo := db.NewLoopRepository(database)
+
+	profile := &models.Profile{
+		Name:            "switch-prompt-profile",
+		Harness:         models.HarnessPi,
+		PromptMode:      models.PromptModeEnv,
+		CommandTemplate: "pi -p \"$FORGE_PROMPT_CONTENT\"",
+		MaxConcurrency:  1,
+	}
+	if err := profileRepo.Create(context.Background(), profile); err != nil {
+		t.Fatalf("create profile: %v", err)
+	}
+
+	stopCfg := models.LoopStopConfig{
+		Rules: []models.LoopStopRule{
+			{
+				Kind:   models.LoopStopRuleFileExists,
+				Path:   "HANDOFF.md",
+				Action: models.LoopStopActionSwitchPrompt,
+				Prompt: &models.NextPromptOverridePayload{Prompt: "review the handoff"},
+			},
+			{Kind: models.LoopStopRuleFailures, Count: 1},
+		},
+	}
+
+	loopEntry := &models.Loop{
+		Name:            "loop-stop-rule-switch-prompt",
+		RepoPath:        repoDir,
+		BasePromptMsg:   "implement",
+		IntervalSeconds: 0,
+		MaxIterations:   10,
+		ProfileID:       profile.ID,
+		State:           models.LoopStateStopped,
+		Metadata:        map[string]any{"stop_config": stopCfg},
+	}
+	if err := loopRepo.Create(context.Background(), loopEntry); err != nil {
+		t.Fatalf("create loop: %v", err)
+	}
+
+	prompts := make([]string, 0)
+	runner := NewRunner(database, cfg)
+	runner.Exec = func(ctx context.Context, profile models.Profile, promptPath, promptContent, workDir string, output io.Writer) (int, string, error) {
+		prompts = append(prompts, strings.TrimSpace(promptContent))
+		if strings.HasPrefix(strings.TrimSpace(promptContent), "review") {
+			return 1, "review failed\n", errors.New("exit status 1")
+		}
+		if err := os.WriteFile(filepath.Join(workDir, "HANDOFF.md"), []byte("done"), 0o644); err != nil {
+			t.Fatalf("write handoff: %v", err)
+		}
+		return 0, "implemented\n", nil
+	}
+
+	if err := runner.RunLoop(context.Background(), loopEntry.ID); err != nil {
+		t.Fatalf("run loop: %v", err)
+	}
+
+	if len(prompts) != 2 || !strings.HasPrefix(prompts[0], "implement") || !strings.HasPrefix(prompts[1], "review the handoff") {
+		t.Fatalf("expected implement then review prompts, got %q", prompts)
+	}
+
+	updated, err := loopRepo.Get(context.Background(), loopEntry.ID)
+	if err != nil {
+		t.Fatalf("get loop: %v", err)
+	}
+	if updated.BasePromptMsg != "review the handoff" {
+		t.Fatalf("expected switched base prompt, got %q", updated.BasePromptMsg)
+	}
+	if StoppedBy(updated) != "failures-2" {
+		t.Fatalf("expected loop stopped by failures rule, got %q", StoppedBy(updated))
+	}
+}
+
 func TestEnsureLoopPathsCreatesLogDirWhenLogPathSet(t *testing.T) {
 	database, cleanup := testutil.NewTestDB(t)
 	defer cleanup()
@@ -932,3 +1251,72 @@ func mustJSON(v any) []byte {
 	}
 	return data
 }
+
+func TestRunnerMaxCostStopsLoop(t *testing.T) {
+	database, cleanup := testutil.NewTestDB(t)
+	defer cleanup()
+
+	cfg := config.DefaultConfig()
+	cfg.Global.DataDir = t.TempDir()
+	cfg.Global.ConfigDir = t.TempDir()
+
+	profileRepo := db.NewProfileRepository(database)
+	loopRepo := db.NewLoopRepository(database)
+	runRepo := db.NewLoopRunRepository(database)
+
+	profile := &models.Profile{
+		Name:            "budget-profile",
+		Harness:         models.HarnessPi,
+		PromptMode:      models.PromptModeEnv,
+		CommandTemplate: "pi -p \"$FORGE_PROMPT_CONTENT\"",
+		MaxConcurrency:  1,
+	}
+	if err := profileRepo.Create(context.Background(), profile); err != nil {
+		t.Fatalf("create profile: %v", err)
+	}
+
+	loopEntry := &models.Loop{
+		Name:          "loop-max-cost",
+		RepoPath:      t.TempDir(),
+		BasePromptMsg: "base",
+		MaxIterations: 10,
+		MaxCostUSD:    1,
+		ProfileID:     profile.ID,
+		State:         models.LoopStateStopped,
+	}
+	if err := loopRepo.Create(context.Background(), loopEntry); err != nil {
+		t.Fatalf("create loop: %v", err)
+	}
+
+	runner := NewRunner(database, cfg)
+	runner.Exec = func(ctx context.Context, profile models.Profile, promptPath, promptContent, workDir string, output io.Writer) (int, string, error) {
+		fmt.Fprintln(output, `FORGE_USAGE: {"input_tokens": 1000, "output_tokens": 200, "cost_usd": 0.6}`)
+		return 0, "ok", nil
+	}
+
+	if err := runner.RunLoop(context.Background(), loopEntry.ID); err != nil {
+		t.Fatalf("run loop: %v", err)
+	}
+
+	runs, err := runRepo.ListByLoop(context.Background(), loopEntry.ID)
+	if err != nil {
+		t.Fatalf("list runs: %v", err)
+	}
+	if len(runs) != 2 {
+		t.Fatalf("expected budget to stop the loop after 2 runs, got %d", len(runs))
+	}
+	if runs[0].Usage.TotalTokens != 1200 || runs[0].Usage.CostUSD != 0.6 {
+		t.Fatalf("expected run usage to be recorded, got %+v", runs[0].Usage)
+	}
+
+	updated, err := loopRepo.Get(context.Background(), loopEntry.ID)
+	if err != nil {
+		t.Fatalf("get loop: %v", err)
+	}
+	if updated.State != models.LoopStateStopped {
+		t.Fatalf("expected loop stopped, got %s", updated.State)
+	}
+	if !strings.Contains(updated.LastError, "max cost reached") {
+		t.Fatalf("expected budget stop reason, got %q", updated.LastError)
+	}
+}
@@ -0,0 +1,104 @@
+package loop
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/tOgg1/forge/internal/db"
+	"github.com/tOgg1/forge/internal/models"
+)
+
+// nextRunAt returns when the loop should run next. afterRun is false before the
+// first iteration, when unscheduled loops run immediately. ok is false when the
+// loop only runs once triggered.
+func nextRunAt(loop *models.Loop, now time.Time, afterRun bool) (time.Time, bool) {
+	interval := time.Duration(loop.IntervalSeconds) * time.Second
+	schedule, err := models.ParseLoopSchedule(loop.Schedule)
+	if err != nil || schedule == nil {
+		if !afterRun {
+			return now, true
+		}
+		return now.Add(interval), true
+	}
+
+	if schedule.Kind == models.LoopScheduleWindow && afterRun {
+		// Inside a window the loop keeps its usual interval between runs.
+		now = now.Add(interval)
+	}
+	return schedule.Next(now)
+}
+
+// runtimeDeadline returns when a loop with maxRuntime reaches its limit, or the
+// zero time when it has none.
+func runtimeDeadline(maxRuntime time.Duration, startedAt time.Time) time.Time {
+	if maxRuntime <= 0 {
+		return time.Time{}
+	}
+	return startedAt.Add(maxRuntime)
+}
+
+// waitForNextRun sleeps until the loop's next run, recording the time as
+// NextRunAt so `forge ps` can show it. The wait never extends past deadline
+// (when set) and ends early when a stop, kill or trigger is queued.
+func (r *Runner) waitForNextRun(ctx context.Context, loopRepo *db.LoopRepository, queueRepo *db.LoopQueueRepository, loop *models.Loop, logWriter *loopLogger, afterRun bool, deadline time.Time) {
+	now := time.Now().UTC()
+	next, timed := nextRunAt(loop, now, afterRun)
+	if timed && !next.After(now) {
+		return
+	}
+	if timed && !deadline.IsZero() && next.After(deadline) {
+		next = deadline
+	}
+
+	if timed {
+		next = next.UTC()
+		loop.NextRunAt = &next
+		if loop.Schedule != "" {
+			logWriter.WriteLine(fmt.Sprintf("next run at %s", next.Format(time.RFC3339)))
+		}
+	} else {
+		loop.NextRunAt = nil
+		logWriter.WriteLine("waiting for trigger")
+		if !deadline.IsZero() {
+			// Still wake up in time to stop at max runtime.
+			next, timed = deadline, true
+		}
+	}
+	loop.State = models.LoopStateSleeping
+	_ = r.updateLoop(ctx, loopRepo, loop)
+
+	r.sleepUntilWake(ctx, queueRepo, loop.ID, next, timed)
+
+	if loop.NextRunAt != nil {
+		loop.NextRunAt = nil
+		_ = r.updateLoop(ctx, loopRepo, loop)
+	}
+}
+
+// sleepUntilWake waits until next (or indefinitely when timed is false) but
+// wakes early when a stop, kill or trigger is queued, so long waits do not
+// delay operator requests.
+func (r *Runner) sleepUntilWake(ctx context.Context, queueRepo *db.LoopQueueRepository, loopID string, next time.Time, timed bool) {
+	var fire <-chan time.Time
+	if timed {
+		timer := time.NewTimer(time.Until(next))
+		defer timer.Stop()
+		fire = timer.C
+	}
+	ticker := time.NewTicker(r.InterruptPollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-fire:
+			return
+		case <-ticker.C:
+			if wake, _ := hasPendingWake(ctx, queueRepo, loopID); wake {
+				return
+			}
+		}
+	}
+}
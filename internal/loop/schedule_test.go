@@ -0,0 +1,120 @@
+package loop
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/tOgg1/forge/internal/config"
+	"github.com/tOgg1/forge/internal/db"
+	"github.com/tOgg1/forge/internal/models"
+	"github.com/tOgg1/forge/internal/testutil"
+)
+
+func TestNextRunAt(t *testing.T) {
+	now := time.Date(2026, 10, 14, 10, 30, 0, 0, time.UTC)
+	loopEntry := &models.Loop{IntervalSeconds: 60}
+
+	if next, ok := nextRunAt(loopEntry, now, false); !ok || !next.Equal(now) {
+		t.Fatalf("expected unscheduled loop to start immediately, got %s", next)
+	}
+	if next, ok := nextRunAt(loopEntry, now, true); !ok || !next.Equal(now.Add(time.Minute)) {
+		t.Fatalf("expected interval after a run, got %s", next)
+	}
+
+	loopEntry.Schedule = "TZ=UTC window:09:00-10:31"
+	if next, ok := nextRunAt(loopEntry, now, false); !ok || !next.Equal(now) {
+		t.Fatalf("expected to start inside the window, got %s", next)
+	}
+	if next, ok := nextRunAt(loopEntry, now, true); !ok || !next.Equal(time.Date(2026, 10, 15, 9, 0, 0, 0, time.UTC)) {
+		t.Fatalf("expected the next window once the interval leaves this one, got %s", next)
+	}
+
+	loopEntry.Schedule = "trigger"
+	if _, ok := nextRunAt(loopEntry, now, true); ok {
+		t.Fatalf("expected trigger loop to wait for a trigger")
+	}
+}
+
+func TestRunnerWaitsForTrigger(t *testing.T) {
+	database, cleanup := testutil.NewTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	cfg := config.DefaultConfig()
+	cfg.Global.DataDir = t.TempDir()
+	cfg.Global.ConfigDir = t.TempDir()
+
+	profile := &models.Profile{Name: "pi-trigger", Harness: models.HarnessPi, PromptMode: models.PromptModeEnv, CommandTemplate: "pi"}
+	if err := db.NewProfileRepository(database).Create(ctx, profile); err != nil {
+		t.Fatalf("create profile: %v", err)
+	}
+	loopRepo := db.NewLoopRepository(database)
+	loopEntry := &models.Loop{
+		Name:          "loop-trigger",
+		RepoPath:      t.TempDir(),
+		BasePromptMsg: "nightly pass",
+		ProfileID:     profile.ID,
+		MaxIterations: 1,
+		Schedule:      "trigger",
+	}
+	if err := loopRepo.Create(ctx, loopEntry); err != nil {
+		t.Fatalf("create loop: %v", err)
+	}
+
+	runs := make(chan struct{}, 1)
+	runner := NewRunner(database, cfg)
+	runner.InterruptPollInterval = 10 * time.Millisecond
+	runner.Exec = func(ctx context.Context, profile models.Profile, promptPath, promptContent, workDir string, output io.Writer) (int, string, error) {
+		runs <- struct{}{}
+		return 0, "ok", nil
+	}
+
+	done := make(chan error, 1)
+	go func() { done <- runner.RunLoop(ctx, loopEntry.ID) }()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		current, err := loopRepo.Get(ctx, loopEntry.ID)
+		if err == nil && current.State == models.LoopStateSleeping {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("loop never started waiting for a trigger")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	select {
+	case <-runs:
+		t.Fatalf("expected trigger loop not to run before it is triggered")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	queueRepo := db.NewLoopQueueRepository(database)
+	payload, _ := json.Marshal(models.TriggerPayload{Reason: "test"})
+	if err := queueRepo.Enqueue(ctx, loopEntry.ID, &models.LoopQueueItem{Type: models.LoopQueueItemTrigger, Payload: payload}); err != nil {
+		t.Fatalf("enqueue trigger: %v", err)
+	}
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("run loop: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("loop did not run after trigger")
+	}
+	if len(runs) != 1 {
+		t.Fatalf("expected one triggered run")
+	}
+
+	items, err := queueRepo.List(ctx, loopEntry.ID)
+	if err != nil {
+		t.Fatalf("list queue: %v", err)
+	}
+	if len(items) != 1 || items[0].Status != models.LoopQueueStatusCompleted {
+		t.Fatalf("expected trigger to be consumed, got %+v", items)
+	}
+}
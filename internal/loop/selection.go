@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"sort"
 	"strconv"
 	"time"
 
@@ -24,11 +25,15 @@ func (r *Runner) selectProfile(ctx context.Context, loop *models.Loop, profileRe
 		if err != nil {
 			return nil, nil, err
 		}
-		available, _, err := profileAvailable(ctx, runRepo, profile, now)
+		available, next, err := profileAvailable(ctx, runRepo, profile, now)
 		if err != nil {
 			return nil, nil, err
 		}
 		if !available {
+			// A pinned profile on cooldown is waited out rather than treated as an error.
+			if next != nil {
+				return nil, next, nil
+			}
 			return nil, nil, fmt.Errorf("pinned profile %s unavailable", profile.Name)
 		}
 		return profile, nil, nil
@@ -39,47 +44,226 @@ func (r *Runner) selectProfile(ctx context.Context, loop *models.Loop, profileRe
 		return nil, nil, err
 	}
 
-	members, err := poolRepo.ListMembers(ctx, pool.ID)
+	selection, err := choosePoolMember(ctx, pool, profileRepo, poolRepo, runRepo, now)
 	if err != nil {
 		return nil, nil, err
 	}
-	if len(members) == 0 {
-		return nil, nil, ErrPoolUnavailable
+	if selection.Profile == nil {
+		return nil, selection.WaitUntil, nil
 	}
+	if selection.slot >= 0 {
+		setPoolLastIndex(pool, selection.slot)
+		_ = poolRepo.Update(ctx, pool)
+	}
+	return selection.Profile, nil, nil
+}
 
-	startIndex := poolLastIndex(pool)
-	var earliest *time.Time
+// PoolSelection describes the pool member a loop would be handed next.
+type PoolSelection struct {
+	Profile   *models.Profile `json:"profile,omitempty"`
+	Reason    string          `json:"reason"`
+	WaitUntil *time.Time      `json:"wait_until,omitempty"`
+
+	// slot is the weighted round-robin position to record, or -1.
+	slot int
+}
+
+// NextPoolMember reports which member the pool's strategy would select right now,
+// without recording the choice.
+func NextPoolMember(ctx context.Context, database *db.DB, pool *models.Pool) (*PoolSelection, error) {
+	return choosePoolMember(ctx, pool, db.NewProfileRepository(database), db.NewPoolRepository(database), db.NewLoopRunRepository(database), time.Now().UTC())
+}
+
+// poolCandidate is a pool member with the state the strategies order by.
+type poolCandidate struct {
+	member      *models.PoolMember
+	profile     *models.Profile
+	running     int
+	lastStarted *time.Time
+	slot        int
+	reason      string
+}
+
+func choosePoolMember(ctx context.Context, pool *models.Pool, profileRepo *db.ProfileRepository, poolRepo *db.PoolRepository, runRepo *db.LoopRunRepository, now time.Time) (*PoolSelection, error) {
+	members, err := poolRepo.ListMembers(ctx, pool.ID)
+	if err != nil {
+		return nil, err
+	}
+	if len(members) == 0 {
+		return nil, ErrPoolUnavailable
+	}
 
-	for i := 0; i < len(members); i++ {
-		idx := (startIndex + 1 + i) % len(members)
-		member := members[idx]
+	candidates := make([]*poolCandidate, 0, len(members))
+	for _, member := range members {
 		profile, err := profileRepo.Get(ctx, member.ProfileID)
 		if err != nil {
 			continue
 		}
-		available, next, err := profileAvailable(ctx, runRepo, profile, now)
+		candidate := &poolCandidate{member: member, profile: profile, slot: -1}
+		if pool.Strategy == models.PoolStrategyLeastLoaded {
+			if candidate.running, err = runRepo.CountRunningByProfile(ctx, profile.ID); err != nil {
+				return nil, err
+			}
+		}
+		if pool.Strategy == models.PoolStrategyLRU {
+			if candidate.lastStarted, err = runRepo.LastStartedByProfile(ctx, profile.ID); err != nil {
+				return nil, err
+			}
+		}
+		candidates = append(candidates, candidate)
+	}
+
+	switch pool.Strategy {
+	case models.PoolStrategyLRU:
+		candidates = orderLeastRecentlyUsed(candidates)
+	case models.PoolStrategyLeastLoaded:
+		candidates = orderLeastLoaded(candidates)
+	default:
+		candidates = orderWeightedRoundRobin(candidates, poolLastIndex(pool))
+	}
+
+	var earliest *time.Time
+	for _, candidate := range candidates {
+		available, next, err := profileAvailable(ctx, runRepo, candidate.profile, now)
 		if err != nil {
 			continue
 		}
 		if available {
-			setPoolLastIndex(pool, idx)
-			_ = poolRepo.Update(ctx, pool)
-			return profile, nil, nil
-		}
-		if next != nil {
-			if earliest == nil || next.Before(*earliest) {
-				copy := *next
-				earliest = &copy
-			}
+			return &PoolSelection{Profile: candidate.profile, Reason: candidate.reason, slot: candidate.slot}, nil
+		}
+		if next != nil && (earliest == nil || next.Before(*earliest)) {
+			copy := *next
+			earliest = &copy
 		}
 	}
 
 	if earliest == nil {
 		wait := now.Add(defaultWaitInterval)
-		earliest = &wait
+		return &PoolSelection{Reason: "all members at max concurrency", WaitUntil: &wait, slot: -1}, nil
 	}
+	return &PoolSelection{Reason: "all members on cooldown or busy", WaitUntil: earliest, slot: -1}, nil
+}
+
+// orderWeightedRoundRobin orders members by their next turn in the weighted
+// rotation after lastSlot. A member with weight N holds N slots per cycle.
+func orderWeightedRoundRobin(candidates []*poolCandidate, lastSlot int) []*poolCandidate {
+	members := make([]*models.PoolMember, len(candidates))
+	for i, candidate := range candidates {
+		members[i] = candidate.member
+	}
+	slots := weightedSlots(members)
+	if len(slots) == 0 {
+		return nil
+	}
+
+	ordered := make([]*poolCandidate, 0, len(candidates))
+	seen := make(map[int]bool, len(candidates))
+	for i := 0; i < len(slots); i++ {
+		slot := (lastSlot + 1 + i) % len(slots)
+		if slot < 0 {
+			slot += len(slots)
+		}
+		idx := slots[slot]
+		if seen[idx] {
+			continue
+		}
+		seen[idx] = true
+		candidate := candidates[idx]
+		candidate.slot = slot
+		candidate.reason = fmt.Sprintf("round robin slot %d of %d, weight %d", slot+1, len(slots), memberWeight(candidate.member))
+		ordered = append(ordered, candidate)
+	}
+	return ordered
+}
+
+// weightedSlots spreads members over one rotation cycle using smooth weighted
+// round-robin, so heavier members are interleaved rather than run back to back.
+// Each slot holds an index into members.
+func weightedSlots(members []*models.PoolMember) []int {
+	total := 0
+	for _, member := range members {
+		total += memberWeight(member)
+	}
+
+	current := make([]int, len(members))
+	slots := make([]int, 0, total)
+	for len(slots) < total {
+		best := 0
+		for i, member := range members {
+			current[i] += memberWeight(member)
+			if current[i] > current[best] {
+				best = i
+			}
+		}
+		current[best] -= total
+		slots = append(slots, best)
+	}
+	return slots
+}
+
+// orderLeastRecentlyUsed puts members that never ran first, then the ones whose
+// last run started longest ago. Ties go to the heavier member, then pool order.
+func orderLeastRecentlyUsed(candidates []*poolCandidate) []*poolCandidate {
+	sort.SliceStable(candidates, func(i, j int) bool {
+		a, b := candidates[i].lastStarted, candidates[j].lastStarted
+		switch {
+		case a == nil && b != nil:
+			return true
+		case a != nil && b == nil:
+			return false
+		case a != nil && b != nil && !a.Equal(*b):
+			return a.Before(*b)
+		}
+		return memberWeight(candidates[i].member) > memberWeight(candidates[j].member)
+	})
+	for _, candidate := range candidates {
+		if candidate.lastStarted == nil {
+			candidate.reason = "least recently used, never run"
+		} else {
+			candidate.reason = fmt.Sprintf("least recently used, last run started %s", candidate.lastStarted.UTC().Format(time.RFC3339))
+		}
+	}
+	return candidates
+}
+
+// orderLeastLoaded puts members with the lowest share of their max_concurrency in
+// use first. Members without a limit count as unloaded only while idle. Ties go
+// to fewer running loops, then the heavier member, then pool order.
+func orderLeastLoaded(candidates []*poolCandidate) []*poolCandidate {
+	sort.SliceStable(candidates, func(i, j int) bool {
+		a, b := candidateLoad(candidates[i]), candidateLoad(candidates[j])
+		if a != b {
+			return a < b
+		}
+		if candidates[i].running != candidates[j].running {
+			return candidates[i].running < candidates[j].running
+		}
+		return memberWeight(candidates[i].member) > memberWeight(candidates[j].member)
+	})
+	for _, candidate := range candidates {
+		if candidate.profile.MaxConcurrency > 0 {
+			candidate.reason = fmt.Sprintf("least loaded, %d/%d running", candidate.running, candidate.profile.MaxConcurrency)
+		} else {
+			candidate.reason = fmt.Sprintf("least loaded, %d running with no limit", candidate.running)
+		}
+	}
+	return candidates
+}
 
-	return nil, earliest, nil
+// candidateLoad is the fraction of a member's concurrency in use. Without a
+// limit, each running loop counts as a full slot.
+func candidateLoad(candidate *poolCandidate) float64 {
+	if candidate.profile.MaxConcurrency > 0 {
+		return float64(candidate.running) / float64(candidate.profile.MaxConcurrency)
+	}
+	return float64(candidate.running)
+}
+
+func memberWeight(member *models.PoolMember) int {
+	if member.Weight < 1 {
+		return 1
+	}
+	return member.Weight
 }
 
 func profileAvailable(ctx context.Context, runRepo *db.LoopRunRepository, profile *models.Profile, now time.Time) (bool, *time.Time, error) {
@@ -2,6 +2,7 @@ package loop
 
 import (
 	"context"
+	"strings"
 	"testing"
 	"time"
 
@@ -185,3 +186,181 @@ func TestSelectProfilePoolWaitsForCooldown(t *testing.T) {
 		t.Fatalf("expected waitUntil near %s, got %s", early, waitUntil)
 	}
 }
+
+func TestSelectProfilePinnedWaitsForCooldown(t *testing.T) {
+	database, cleanup := testutil.NewTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	profileRepo := db.NewProfileRepository(database)
+	poolRepo := db.NewPoolRepository(database)
+	loopRepo := db.NewLoopRepository(database)
+	runRepo := db.NewLoopRunRepository(database)
+
+	until := time.Now().UTC().Add(10 * time.Minute).Truncate(time.Second)
+	profile := &models.Profile{Name: "pinned-cooldown", CommandTemplate: "echo", CooldownUntil: &until}
+	if err := profileRepo.Create(ctx, profile); err != nil {
+		t.Fatalf("create profile: %v", err)
+	}
+	loop := &models.Loop{Name: "loop-pinned", RepoPath: "/tmp/repo", ProfileID: profile.ID}
+	if err := loopRepo.Create(ctx, loop); err != nil {
+		t.Fatalf("create loop: %v", err)
+	}
+
+	runner := NewRunner(database, config.DefaultConfig())
+	selected, waitUntil, err := runner.selectProfile(ctx, loop, profileRepo, poolRepo, runRepo)
+	if err != nil {
+		t.Fatalf("select profile: %v", err)
+	}
+	if selected != nil || waitUntil == nil || !waitUntil.Equal(until) {
+		t.Fatalf("expected wait until %s, got selected=%v wait=%v", until, selected, waitUntil)
+	}
+}
+
+func createPoolProfiles(t *testing.T, database *db.DB, strategy models.PoolStrategy, weights map[string]int, profiles ...*models.Profile) *models.Pool {
+	t.Helper()
+	ctx := context.Background()
+	profileRepo := db.NewProfileRepository(database)
+	poolRepo := db.NewPoolRepository(database)
+
+	pool := &models.Pool{Name: "pool-" + string(strategy), Strategy: strategy, IsDefault: true}
+	if err := poolRepo.Create(ctx, pool); err != nil {
+		t.Fatalf("create pool: %v", err)
+	}
+	for i, profile := range profiles {
+		if profile.CommandTemplate == "" {
+			profile.CommandTemplate = "echo"
+		}
+		if err := profileRepo.Create(ctx, profile); err != nil {
+			t.Fatalf("create profile: %v", err)
+		}
+		member := &models.PoolMember{PoolID: pool.ID, ProfileID: profile.ID, Position: i + 1, Weight: weights[profile.Name]}
+		if err := poolRepo.AddMember(ctx, member); err != nil {
+			t.Fatalf("add member: %v", err)
+		}
+	}
+	return pool
+}
+
+func TestSelectProfileWeightedRoundRobin(t *testing.T) {
+	database, cleanup := testutil.NewTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	heavy := &models.Profile{Name: "heavy"}
+	light := &models.Profile{Name: "light"}
+	pool := createPoolProfiles(t, database, models.PoolStrategyRoundRobin, map[string]int{"heavy": 2}, heavy, light)
+
+	loop := &models.Loop{Name: "loop-wrr", RepoPath: "/tmp/repo", PoolID: pool.ID}
+	if err := db.NewLoopRepository(database).Create(ctx, loop); err != nil {
+		t.Fatalf("create loop: %v", err)
+	}
+
+	runner := NewRunner(database, config.DefaultConfig())
+	got := make([]string, 0, 6)
+	for i := 0; i < 6; i++ {
+		selected, _, err := runner.selectProfile(ctx, loop, db.NewProfileRepository(database), db.NewPoolRepository(database), db.NewLoopRunRepository(database))
+		if err != nil {
+			t.Fatalf("select profile: %v", err)
+		}
+		got = append(got, selected.Name)
+	}
+	want := []string{"heavy", "light", "heavy", "heavy", "light", "heavy"}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("expected rotation %v, got %v", want, got)
+		}
+	}
+}
+
+func TestNextPoolMemberLRU(t *testing.T) {
+	database, cleanup := testutil.NewTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	recent := &models.Profile{Name: "recent"}
+	stale := &models.Profile{Name: "stale"}
+	pool := createPoolProfiles(t, database, models.PoolStrategyLRU, nil, recent, stale)
+
+	loop := &models.Loop{Name: "loop-lru", RepoPath: "/tmp/repo", PoolID: pool.ID}
+	if err := db.NewLoopRepository(database).Create(ctx, loop); err != nil {
+		t.Fatalf("create loop: %v", err)
+	}
+	runRepo := db.NewLoopRunRepository(database)
+	now := time.Now().UTC()
+	for profileID, startedAt := range map[string]time.Time{recent.ID: now.Add(-time.Minute), stale.ID: now.Add(-time.Hour)} {
+		run := &models.LoopRun{LoopID: loop.ID, ProfileID: profileID, Status: models.LoopRunStatusSuccess, StartedAt: startedAt}
+		if err := runRepo.Create(ctx, run); err != nil {
+			t.Fatalf("create run: %v", err)
+		}
+	}
+
+	next, err := NextPoolMember(ctx, database, pool)
+	if err != nil {
+		t.Fatalf("next pool member: %v", err)
+	}
+	if next.Profile == nil || next.Profile.ID != stale.ID {
+		t.Fatalf("expected stale profile, got %#v", next.Profile)
+	}
+	if !strings.Contains(next.Reason, "least recently used") {
+		t.Fatalf("unexpected reason %q", next.Reason)
+	}
+
+	fresh := &models.Profile{Name: "fresh", CommandTemplate: "echo"}
+	if err := db.NewProfileRepository(database).Create(ctx, fresh); err != nil {
+		t.Fatalf("create profile: %v", err)
+	}
+	if err := db.NewPoolRepository(database).AddMember(ctx, &models.PoolMember{PoolID: pool.ID, ProfileID: fresh.ID, Position: 3}); err != nil {
+		t.Fatalf("add member: %v", err)
+	}
+	next, err = NextPoolMember(ctx, database, pool)
+	if err != nil {
+		t.Fatalf("next pool member: %v", err)
+	}
+	if next.Profile == nil || next.Profile.ID != fresh.ID || next.Reason != "least recently used, never run" {
+		t.Fatalf("expected never-run profile first, got %#v (%s)", next.Profile, next.Reason)
+	}
+}
+
+func TestNextPoolMemberLeastLoaded(t *testing.T) {
+	database, cleanup := testutil.NewTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	busy := &models.Profile{Name: "busy", MaxConcurrency: 2}
+	roomy := &models.Profile{Name: "roomy", MaxConcurrency: 4}
+	pool := createPoolProfiles(t, database, models.PoolStrategyLeastLoaded, nil, busy, roomy)
+
+	loop := &models.Loop{Name: "loop-load", RepoPath: "/tmp/repo", PoolID: pool.ID}
+	if err := db.NewLoopRepository(database).Create(ctx, loop); err != nil {
+		t.Fatalf("create loop: %v", err)
+	}
+	runRepo := db.NewLoopRunRepository(database)
+	for _, profileID := range []string{busy.ID, roomy.ID, roomy.ID} {
+		run := &models.LoopRun{LoopID: loop.ID, ProfileID: profileID, Status: models.LoopRunStatusRunning}
+		if err := runRepo.Create(ctx, run); err != nil {
+			t.Fatalf("create run: %v", err)
+		}
+	}
+
+	// busy is at 1/2, roomy at 2/4: tie on load, fewer running wins.
+	next, err := NextPoolMember(ctx, database, pool)
+	if err != nil {
+		t.Fatalf("next pool member: %v", err)
+	}
+	if next.Profile == nil || next.Profile.ID != busy.ID || next.Reason != "least loaded, 1/2 running" {
+		t.Fatalf("expected busy at 1/2, got %#v (%s)", next.Profile, next.Reason)
+	}
+
+	run := &models.LoopRun{LoopID: loop.ID, ProfileID: busy.ID, Status: models.LoopRunStatusRunning}
+	if err := runRepo.Create(ctx, run); err != nil {
+		t.Fatalf("create run: %v", err)
+	}
+	next, err = NextPoolMember(ctx, database, pool)
+	if err != nil {
+		t.Fatalf("next pool member: %v", err)
+	}
+	if next.Profile == nil || next.Profile.ID != roomy.ID || next.Reason != "least loaded, 2/4 running" {
+		t.Fatalf("expected roomy at 2/4, got %#v (%s)", next.Profile, next.Reason)
+	}
+}
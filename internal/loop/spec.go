@@ -0,0 +1,252 @@
+package loop
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/tOgg1/forge/internal/models"
+	"gopkg.in/yaml.v3"
+)
+
+// LoopSpecKey is the loop metadata key naming the spec that manages a loop.
+const LoopSpecKey = "spec"
+
+// Spec declares a set of identical loops for `forge apply`.
+type Spec struct {
+	Name          string   `yaml:"name" json:"name"`
+	Count         int      `yaml:"count" json:"count"`
+	Pool          string   `yaml:"pool,omitempty" json:"pool,omitempty"`
+	Profile       string   `yaml:"profile,omitempty" json:"profile,omitempty"`
+	Prompt        string   `yaml:"prompt,omitempty" json:"prompt,omitempty"`
+	PromptMsg     string   `yaml:"prompt_msg,omitempty" json:"prompt_msg,omitempty"`
+	Interval      string   `yaml:"interval,omitempty" json:"interval,omitempty"`
+	MaxIterations int      `yaml:"max_iterations" json:"max_iterations"`
+	MaxRuntime    string   `yaml:"max_runtime" json:"max_runtime"`
+	MaxTokens     int64    `yaml:"max_tokens,omitempty" json:"max_tokens,omitempty"`
+	MaxCost       float64  `yaml:"max_cost,omitempty" json:"max_cost,omitempty"`
+	Tags          []string `yaml:"tags,omitempty" json:"tags,omitempty"`
+	Restart       string   `yaml:"restart,omitempty" json:"restart,omitempty"`
+	Schedule      string   `yaml:"schedule,omitempty" json:"schedule,omitempty"`
+	Isolate       string   `yaml:"isolate,omitempty" json:"isolate,omitempty"`
+	Stop          StopSpec `yaml:"stop,omitempty" json:"stop"`
+	ChainSpec     `yaml:",inline"`
+	Source        string `yaml:"-" json:"source,omitempty"`
+}
+
+// ChainSpec declares the loops to hand off to when a loop stops on its own.
+// The same fields back the --on-stop and --on-success flags.
+type ChainSpec struct {
+	OnStop    []ChainTargetSpec `yaml:"on_stop,omitempty" json:"on_stop,omitempty"`
+	OnSuccess []ChainTargetSpec `yaml:"on_success,omitempty" json:"on_success,omitempty"`
+
+	// OnStopFlags and OnSuccessFlags hold raw flag values, parsed by the CLI.
+	OnStopFlags    []string `yaml:"-" json:"-"`
+	OnSuccessFlags []string `yaml:"-" json:"-"`
+}
+
+// ChainTargetSpec declares one downstream loop. Reasons limits it to some stop
+// reasons (quant, qual, rule, condition, max_iterations, max_runtime, budget
+// or error).
+type ChainTargetSpec struct {
+	Loop    string   `yaml:"loop" json:"loop"`
+	Reasons []string `yaml:"reasons,omitempty" json:"reasons,omitempty"`
+	Message string   `yaml:"message,omitempty" json:"message,omitempty"`
+}
+
+// StopSpec declares smart stop rules. The same fields back the
+// --quantitative-stop-*, --qualitative-stop-* and --stop-rule* flags.
+type StopSpec struct {
+	Quantitative QuantStopSpec  `yaml:"quantitative,omitempty" json:"quantitative"`
+	Qualitative  QualStopSpec   `yaml:"qualitative,omitempty" json:"qualitative"`
+	Combine      string         `yaml:"combine,omitempty" json:"combine,omitempty"`
+	Rules        []StopRuleSpec `yaml:"rules,omitempty" json:"rules,omitempty"`
+
+	// RuleFlags holds raw --stop-rule values, parsed into Rules by the CLI.
+	RuleFlags []string `yaml:"-" json:"-"`
+}
+
+// StopRuleSpec declares one composite stop rule. Kind selects the condition
+// (command, no_changes, same_output, failures, queue_empty or file_exists) and
+// Action what happens when it holds (stop, pause, switch_prompt or notify).
+type StopRuleSpec struct {
+	Name        string `yaml:"name,omitempty" json:"name,omitempty"`
+	Kind        string `yaml:"kind" json:"kind"`
+	Count       int    `yaml:"count,omitempty" json:"count,omitempty"`
+	Path        string `yaml:"path,omitempty" json:"path,omitempty"`
+	Queue       string `yaml:"queue,omitempty" json:"queue,omitempty"`
+	Topic       string `yaml:"topic,omitempty" json:"topic,omitempty"`
+	Cmd         string `yaml:"cmd,omitempty" json:"cmd,omitempty"`
+	ExitCodes   []int  `yaml:"exit_codes,omitempty" json:"exit_codes,omitempty"`
+	ExitInvert  bool   `yaml:"exit_invert,omitempty" json:"exit_invert,omitempty"`
+	Stdout      string `yaml:"stdout,omitempty" json:"stdout,omitempty"`
+	Stderr      string `yaml:"stderr,omitempty" json:"stderr,omitempty"`
+	StdoutRegex string `yaml:"stdout_regex,omitempty" json:"stdout_regex,omitempty"`
+	StderrRegex string `yaml:"stderr_regex,omitempty" json:"stderr_regex,omitempty"`
+	Timeout     string `yaml:"timeout,omitempty" json:"timeout,omitempty"`
+	Action      string `yaml:"action,omitempty" json:"action,omitempty"`
+	Pause       string `yaml:"pause,omitempty" json:"pause,omitempty"`
+	Prompt      string `yaml:"prompt,omitempty" json:"prompt,omitempty"`
+	PromptMsg   string `yaml:"prompt_msg,omitempty" json:"prompt_msg,omitempty"`
+	Message     string `yaml:"message,omitempty" json:"message,omitempty"`
+}
+
+// QuantStopSpec declares a command-based stop rule.
+type QuantStopSpec struct {
+	Cmd         string `yaml:"cmd,omitempty" json:"cmd,omitempty"`
+	Every       int    `yaml:"every,omitempty" json:"every,omitempty"`
+	When        string `yaml:"when,omitempty" json:"when,omitempty"`
+	Decision    string `yaml:"decision,omitempty" json:"decision,omitempty"`
+	ExitCodes   []int  `yaml:"exit_codes,omitempty" json:"exit_codes,omitempty"`
+	ExitInvert  bool   `yaml:"exit_invert,omitempty" json:"exit_invert,omitempty"`
+	Stdout      string `yaml:"stdout,omitempty" json:"stdout,omitempty"`
+	Stderr      string `yaml:"stderr,omitempty" json:"stderr,omitempty"`
+	StdoutRegex string `yaml:"stdout_regex,omitempty" json:"stdout_regex,omitempty"`
+	StderrRegex string `yaml:"stderr_regex,omitempty" json:"stderr_regex,omitempty"`
+	Timeout     string `yaml:"timeout,omitempty" json:"timeout,omitempty"`
+}
+
+// QualStopSpec declares a judge-iteration stop rule.
+type QualStopSpec struct {
+	Every     int    `yaml:"every,omitempty" json:"every,omitempty"`
+	Prompt    string `yaml:"prompt,omitempty" json:"prompt,omitempty"`
+	PromptMsg string `yaml:"prompt_msg,omitempty" json:"prompt_msg,omitempty"`
+	OnInvalid string `yaml:"on_invalid,omitempty" json:"on_invalid,omitempty"`
+}
+
+// DefaultStopSpec returns the stop rule defaults shared by flags and spec files.
+func DefaultStopSpec() StopSpec {
+	return StopSpec{
+		Quantitative: QuantStopSpec{
+			Every:    1,
+			When:     "before",
+			Decision: "stop",
+			Stdout:   "any",
+			Stderr:   "any",
+		},
+		Qualitative: QualStopSpec{
+			OnInvalid: "continue",
+		},
+	}
+}
+
+// SpecDir returns the directory holding a repo's loop specs.
+func SpecDir(repoPath string) string {
+	return filepath.Join(repoPath, ".forge", "loops")
+}
+
+// LoopNames returns the names of the loops a spec manages. The first loop is
+// named after the spec and the rest get a numeric suffix, so changing the
+// count never renames existing loops.
+func (s *Spec) LoopNames() []string {
+	names := make([]string, 0, s.Count)
+	for i := 1; i <= s.Count; i++ {
+		if i == 1 {
+			names = append(names, s.Name)
+			continue
+		}
+		names = append(names, fmt.Sprintf("%s-%d", s.Name, i))
+	}
+	return names
+}
+
+// Validate checks the parts of a spec that do not need the database.
+func (s *Spec) Validate() error {
+	if s.Name == "" {
+		return errors.New("name is required")
+	}
+	if strings.ContainsAny(s.Name, " \t\n/") {
+		return fmt.Errorf("name %q must not contain whitespace or slashes", s.Name)
+	}
+	if s.Count < 0 {
+		return errors.New("count must be >= 0")
+	}
+	if s.Pool != "" && s.Profile != "" {
+		return errors.New("use either pool or profile, not both")
+	}
+	if s.Prompt != "" && s.PromptMsg != "" {
+		return errors.New("use either prompt or prompt_msg, not both")
+	}
+	if s.MaxIterations <= 0 || strings.TrimSpace(s.MaxRuntime) == "" {
+		return errors.New("max_iterations and max_runtime are required")
+	}
+	return nil
+}
+
+// LoadSpec reads a single loop spec file.
+func LoadSpec(path string) (*Spec, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read loop spec %s: %w", path, err)
+	}
+
+	spec := Spec{Count: 1, Stop: DefaultStopSpec()}
+	if err := yaml.Unmarshal(data, &spec); err != nil {
+		return nil, fmt.Errorf("parse loop spec %s: %w", path, err)
+	}
+	spec.Name = strings.TrimSpace(spec.Name)
+	if err := spec.Validate(); err != nil {
+		return nil, fmt.Errorf("loop spec %s: %w", path, err)
+	}
+	spec.Source = path
+	return &spec, nil
+}
+
+// LoadSpecs reads loop specs from files and directories of *.yaml/*.yml files.
+// Spec names must be unique.
+func LoadSpecs(paths ...string) ([]*Spec, error) {
+	files := make([]string, 0)
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return nil, err
+		}
+		if !info.IsDir() {
+			files = append(files, path)
+			continue
+		}
+		entries, err := os.ReadDir(path)
+		if err != nil {
+			return nil, fmt.Errorf("read loop specs dir %s: %w", path, err)
+		}
+		for _, entry := range entries {
+			ext := strings.ToLower(filepath.Ext(entry.Name()))
+			if entry.IsDir() || (ext != ".yaml" && ext != ".yml") {
+				continue
+			}
+			files = append(files, filepath.Join(path, entry.Name()))
+		}
+	}
+
+	specs := make([]*Spec, 0, len(files))
+	seen := make(map[string]string, len(files))
+	for _, file := range files {
+		spec, err := LoadSpec(file)
+		if err != nil {
+			return nil, err
+		}
+		if other, ok := seen[spec.Name]; ok {
+			return nil, fmt.Errorf("loop spec %q is defined in both %s and %s", spec.Name, other, file)
+		}
+		seen[spec.Name] = file
+		specs = append(specs, spec)
+	}
+
+	sort.Slice(specs, func(i, j int) bool { return specs[i].Name < specs[j].Name })
+	return specs, nil
+}
+
+// SpecName returns the spec that manages a loop, if any.
+func SpecName(loop *models.Loop) string {
+	if loop == nil || loop.Metadata == nil {
+		return ""
+	}
+	name, _ := loop.Metadata[LoopSpecKey].(string)
+	return name
+}
@@ -0,0 +1,134 @@
+package loop
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/tOgg1/forge/internal/config"
+	"github.com/tOgg1/forge/internal/db"
+	"github.com/tOgg1/forge/internal/models"
+	"github.com/tOgg1/forge/internal/testutil"
+)
+
+func TestLoadSpecsAppliesDefaults(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+	}
+	write("review.yaml", `name: review
+count: 3
+max_iterations: 10
+max_runtime: 2h
+stop:
+  quantitative:
+    cmd: make test
+    exit_codes: [0]
+`)
+	write("docs.yml", "name: docs\nmax_iterations: 1\nmax_runtime: 10m\n")
+	write("README.md", "not a spec")
+
+	specs, err := LoadSpecs(dir)
+	if err != nil {
+		t.Fatalf("load specs: %v", err)
+	}
+	if len(specs) != 2 || specs[0].Name != "docs" || specs[1].Name != "review" {
+		t.Fatalf("unexpected specs: %+v", specs)
+	}
+	if specs[0].Count != 1 {
+		t.Fatalf("expected count to default to 1, got %d", specs[0].Count)
+	}
+	quant := specs[1].Stop.Quantitative
+	if quant.Every != 1 || quant.When != "before" || quant.Decision != "stop" || len(quant.ExitCodes) != 1 {
+		t.Fatalf("expected stop defaults to be kept, got %+v", quant)
+	}
+	if got := strings.Join(specs[1].LoopNames(), ","); got != "review,review-2,review-3" {
+		t.Fatalf("unexpected loop names %q", got)
+	}
+
+	write("dup.yaml", "name: docs\nmax_iterations: 1\nmax_runtime: 10m\n")
+	if _, err := LoadSpecs(dir); err == nil || !strings.Contains(err.Error(), "defined in both") {
+		t.Fatalf("expected duplicate spec error, got %v", err)
+	}
+
+	write("dup.yaml", "name: other\ncount: 1\n")
+	if _, err := LoadSpecs(filepath.Join(dir, "dup.yaml")); err == nil {
+		t.Fatalf("expected spec without limits to be rejected")
+	}
+}
+
+func TestRunnerAppliesQueuedConfigUpdate(t *testing.T) {
+	database, cleanup := testutil.NewTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	cfg := config.DefaultConfig()
+	cfg.Global.DataDir = t.TempDir()
+	cfg.Global.ConfigDir = t.TempDir()
+
+	profile := &models.Profile{Name: "pi-config", Harness: models.HarnessPi, PromptMode: models.PromptModeEnv, CommandTemplate: "pi"}
+	if err := db.NewProfileRepository(database).Create(ctx, profile); err != nil {
+		t.Fatalf("create profile: %v", err)
+	}
+	loopRepo := db.NewLoopRepository(database)
+	loopEntry := &models.Loop{
+		Name:          "loop-config",
+		RepoPath:      t.TempDir(),
+		BasePromptMsg: "old prompt",
+		ProfileID:     profile.ID,
+		MaxIterations: 1,
+		Metadata:      map[string]any{"stop_config": models.LoopStopConfig{Quant: &models.LoopQuantStopConfig{Cmd: "true", EveryN: 1}}},
+	}
+	if err := loopRepo.Create(ctx, loopEntry); err != nil {
+		t.Fatalf("create loop: %v", err)
+	}
+
+	update := LoopConfig(loopEntry)
+	update.BasePromptMsg = "new prompt"
+	update.IntervalSeconds = 42
+	update.MaxIterations = 3
+	update.StopConfig = nil
+	payload, _ := json.Marshal(update)
+	queueRepo := db.NewLoopQueueRepository(database)
+	if err := queueRepo.Enqueue(ctx, loopEntry.ID, &models.LoopQueueItem{Type: models.LoopQueueItemConfigUpdate, Payload: payload}); err != nil {
+		t.Fatalf("enqueue config update: %v", err)
+	}
+
+	var prompts []string
+	runner := NewRunner(database, cfg)
+	runner.Exec = func(ctx context.Context, profile models.Profile, promptPath, promptContent, workDir string, output io.Writer) (int, string, error) {
+		prompts = append(prompts, promptContent)
+		return 0, "ok", nil
+	}
+	if err := runner.RunOnce(ctx, loopEntry.ID); err != nil {
+		t.Fatalf("run once: %v", err)
+	}
+
+	if len(prompts) != 1 || !strings.Contains(prompts[0], "new prompt") {
+		t.Fatalf("expected the updated prompt to be used, got %q", prompts)
+	}
+	updated, err := loopRepo.Get(ctx, loopEntry.ID)
+	if err != nil {
+		t.Fatalf("get loop: %v", err)
+	}
+	if updated.IntervalSeconds != 42 || updated.MaxIterations != 3 {
+		t.Fatalf("expected updated settings, got %+v", updated)
+	}
+	if LoopConfig(updated).StopConfig != nil {
+		t.Fatalf("expected stop config to be removed")
+	}
+	items, err := queueRepo.List(ctx, loopEntry.ID)
+	if err != nil {
+		t.Fatalf("list queue: %v", err)
+	}
+	if len(items) != 1 || items[0].Status != models.LoopQueueStatusCompleted {
+		t.Fatalf("expected config update to be completed, got %+v", items)
+	}
+}
@@ -0,0 +1,292 @@
+package loop
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/tOgg1/forge/internal/beads"
+	"github.com/tOgg1/forge/internal/db"
+	"github.com/tOgg1/forge/internal/fmail"
+	"github.com/tOgg1/forge/internal/models"
+)
+
+// StoppedByKey is the loop metadata key naming the stop rule that stopped a
+// loop. It is cleared when the loop starts again.
+const StoppedByKey = "stopped_by"
+
+// StoppedBy returns the stop rule that stopped a loop, if any.
+func StoppedBy(loop *models.Loop) string {
+	if loop == nil || loop.Metadata == nil {
+		return ""
+	}
+	rule, _ := loop.Metadata[StoppedByKey].(string)
+	return rule
+}
+
+// stopRuleRun is what a main iteration left behind for stop rules to inspect.
+type stopRuleRun struct {
+	run        *models.LoopRun
+	output     string
+	treeBefore string
+	treeAfter  string
+}
+
+// stopRuleMatch is a rule whose condition held long enough to act on.
+type stopRuleMatch struct {
+	name   string
+	rule   models.LoopStopRule
+	reason string
+}
+
+func (m stopRuleMatch) action() models.LoopStopAction {
+	if m.rule.Action == "" {
+		return models.LoopStopActionStop
+	}
+	return m.rule.Action
+}
+
+// needsWorktreeFingerprint reports whether a no_changes rule needs the working
+// tree hashed around each run.
+func needsWorktreeFingerprint(cfg models.LoopStopConfig) bool {
+	for _, rule := range cfg.Rules {
+		if rule.Kind == models.LoopStopRuleNoChanges {
+			return true
+		}
+	}
+	return false
+}
+
+// worktreeFingerprint returns the tree hash of dir's working tree, or "" when
+// it cannot be computed.
+func worktreeFingerprint(dir string) string {
+	if !isGitRepo(dir) {
+		return ""
+	}
+	_, tree, err := writeWorktreeTree(dir)
+	if err != nil {
+		return ""
+	}
+	return tree
+}
+
+func hashOutput(output string) string {
+	sum := sha256.Sum256([]byte(strings.TrimSpace(output)))
+	return hex.EncodeToString(sum[:])
+}
+
+// evaluateStopRules records a main iteration in the rule streaks of state and
+// returns the rules to act on.
+func (r *Runner) evaluateStopRules(ctx context.Context, cfg models.LoopStopConfig, state *models.LoopStopState, loop *models.Loop, obs stopRuleRun) []stopRuleMatch {
+	if state.RuleStreaks == nil {
+		state.RuleStreaks = make(map[string]int)
+	}
+	outputHash := hashOutput(obs.output)
+
+	matches := make([]stopRuleMatch, 0)
+	allMatched := true
+	for i, rule := range cfg.Rules {
+		name := cfg.RuleName(i)
+		held, reason := r.stopRuleHolds(ctx, rule, state, loop, obs, outputHash)
+
+		streak := state.RuleStreaks[name]
+		switch {
+		case held:
+			streak++
+		case rule.Kind == models.LoopStopRuleSameOutput:
+			// A new output starts a fresh run of identical outputs.
+			streak = 1
+		default:
+			streak = 0
+		}
+		state.RuleStreaks[name] = streak
+
+		if streak < max(rule.Count, 1) {
+			allMatched = false
+			continue
+		}
+		switch rule.Kind {
+		case models.LoopStopRuleNoChanges:
+			reason = fmt.Sprintf("working tree unchanged for %d iterations", streak)
+		case models.LoopStopRuleSameOutput:
+			reason = fmt.Sprintf("same output %d times in a row", streak)
+		case models.LoopStopRuleFailures:
+			reason = fmt.Sprintf("%d failed iterations in a row", streak)
+		}
+		matches = append(matches, stopRuleMatch{name: name, rule: rule, reason: reason})
+	}
+	state.LastOutputHash = outputHash
+
+	if strings.EqualFold(strings.TrimSpace(cfg.Combine), models.LoopStopCombineAll) && !allMatched {
+		return nil
+	}
+	return matches
+}
+
+// stopRuleHolds reports whether a rule's condition held for this iteration.
+func (r *Runner) stopRuleHolds(ctx context.Context, rule models.LoopStopRule, state *models.LoopStopState, loop *models.Loop, obs stopRuleRun, outputHash string) (bool, string) {
+	switch rule.Kind {
+	case models.LoopStopRuleCommand:
+		if rule.Command == nil {
+			return false, "empty cmd"
+		}
+		timeout := time.Duration(rule.Command.TimeoutSeconds) * time.Second
+		res := r.RunCommand(ctx, loop.RepoPath, rule.Command.Cmd, timeout)
+		matched, reason := quantRuleMatches(*rule.Command, res)
+		if matched {
+			reason = fmt.Sprintf("command matched (exit_code=%d)", res.exitCode)
+		}
+		return matched, reason
+	case models.LoopStopRuleNoChanges:
+		if obs.treeBefore == "" || obs.treeAfter == "" {
+			return false, "working tree unavailable"
+		}
+		return obs.treeBefore == obs.treeAfter, ""
+	case models.LoopStopRuleSameOutput:
+		return state.LastOutputHash == outputHash, ""
+	case models.LoopStopRuleFailures:
+		return obs.run != nil && obs.run.Status != models.LoopRunStatusSuccess, ""
+	case models.LoopStopRuleQueueEmpty:
+		return queueEmpty(rule, loop, obs.run)
+	case models.LoopStopRuleFileExists:
+		if strings.TrimSpace(rule.Path) == "" {
+			return false, "empty path"
+		}
+		if _, err := os.Stat(resolveRepoPath(loop.RepoPath, rule.Path)); err != nil {
+			return false, ""
+		}
+		return true, fmt.Sprintf("%s exists", rule.Path)
+	default:
+		return false, fmt.Sprintf("unknown rule kind %q", rule.Kind)
+	}
+}
+
+// queueEmpty checks a beads queue for open issues, or an fmail topic for
+// messages posted since the run started.
+func queueEmpty(rule models.LoopStopRule, loop *models.Loop, run *models.LoopRun) (bool, string) {
+	switch rule.Queue {
+	case models.LoopStopQueueBeads:
+		issues, err := beads.LoadIssues(beads.IssuesPath(loop.RepoPath))
+		if err != nil {
+			return false, fmt.Sprintf("beads queue unavailable: %v", err)
+		}
+		for _, issue := range issues {
+			if issue.Status != "closed" && issue.Status != "tombstone" {
+				return false, ""
+			}
+		}
+		return true, "beads queue empty"
+	case models.LoopStopQueueFmail:
+		root, err := fmail.DiscoverProjectRoot(loop.RepoPath)
+		if err != nil {
+			return false, fmt.Sprintf("fmail unavailable: %v", err)
+		}
+		store, err := fmail.NewStore(root)
+		if err != nil {
+			return false, fmt.Sprintf("fmail unavailable: %v", err)
+		}
+		var messages []fmail.Message
+		if agent, ok := strings.CutPrefix(rule.Topic, "@"); ok {
+			messages, err = store.ListDMMessages(agent)
+		} else {
+			messages, err = store.ListTopicMessages(rule.Topic)
+		}
+		if err != nil {
+			return false, fmt.Sprintf("fmail unavailable: %v", err)
+		}
+		var since time.Time
+		if run != nil {
+			since = run.StartedAt
+		}
+		for _, message := range messages {
+			if !message.Time.Before(since) {
+				return false, ""
+			}
+		}
+		return true, fmt.Sprintf("fmail %s empty", rule.Topic)
+	default:
+		return false, fmt.Sprintf("unknown queue %q", rule.Queue)
+	}
+}
+
+// applyStopRules evaluates a loop's stop rules after a main iteration and runs
+// the actions of matching rules. Stop wins over every other action; it reports
+// whether the loop stopped and how long to pause otherwise.
+func (r *Runner) applyStopRules(ctx context.Context, loopRepo *db.LoopRepository, loop *models.Loop, cfg models.LoopStopConfig, obs stopRuleRun, logWriter *loopLogger) (bool, time.Duration) {
+	state := loadStopState(loop)
+	matches := r.evaluateStopRules(ctx, cfg, &state, loop, obs)
+
+	var stop *stopRuleMatch
+	var pause time.Duration
+	for i := range matches {
+		match := matches[i]
+		action := match.action()
+		logWriter.WriteLine(fmt.Sprintf("stop rule %s matched (action=%s): %s", match.name, action, match.reason))
+
+		reason := match.reason
+		switch action {
+		case models.LoopStopActionStop:
+			if stop == nil {
+				stop = &match
+			}
+			continue
+		case models.LoopStopActionNotify:
+			if msg := strings.TrimSpace(match.rule.Message); msg != "" {
+				reason = fmt.Sprintf("%s (%s)", msg, match.reason)
+			}
+		case models.LoopStopActionSwitchPrompt:
+			if err := switchBasePrompt(loop, match.rule.Prompt); err != nil {
+				logWriter.WriteLine(fmt.Sprintf("stop rule %s: %v", match.name, err))
+			} else {
+				logWriter.WriteLine(fmt.Sprintf("stop rule %s switched the base prompt", match.name))
+			}
+		case models.LoopStopActionPause:
+			pause = max(pause, time.Duration(match.rule.PauseSeconds)*time.Second)
+		}
+		r.publishStopMatched(ctx, loop, obs.run, match.name, string(action), reason)
+		// Rules that keep the loop going need a fresh streak to fire again.
+		delete(state.RuleStreaks, match.name)
+	}
+	saveStopState(loop, state)
+
+	if stop != nil {
+		r.publishStopMatched(ctx, loop, obs.run, stop.name, stopDecisionStop, stop.reason)
+		r.stopForRule(ctx, loopRepo, loop, stop.name, fmt.Sprintf("stop rule %s: %s", stop.name, stop.reason))
+		return true, 0
+	}
+	_ = r.updateLoop(ctx, loopRepo, loop)
+	return false, pause
+}
+
+// switchBasePrompt makes prompt the loop's base prompt for later iterations.
+func switchBasePrompt(loop *models.Loop, prompt *models.NextPromptOverridePayload) error {
+	if prompt == nil || strings.TrimSpace(prompt.Prompt) == "" {
+		return errors.New("switch_prompt rule has no prompt")
+	}
+	if prompt.IsPath {
+		loop.BasePromptPath = prompt.Prompt
+		loop.BasePromptMsg = ""
+	} else {
+		loop.BasePromptMsg = prompt.Prompt
+		loop.BasePromptPath = ""
+	}
+	return nil
+}
+
+// stopForRule stops the loop on behalf of a stop rule and records the rule for
+// `forge ps`.
+func (r *Runner) stopForRule(ctx context.Context, loopRepo *db.LoopRepository, loop *models.Loop, rule, reason string) {
+	loop.State = models.LoopStateStopped
+	loop.LastError = reason
+	if loop.Metadata == nil {
+		loop.Metadata = make(map[string]any)
+	}
+	loop.Metadata[StoppedByKey] = rule
+	r.stopReason = stopReasonForRule(rule)
+	_ = r.updateLoop(ctx, loopRepo, loop)
+}
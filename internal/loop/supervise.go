@@ -0,0 +1,243 @@
+package loop
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/tOgg1/forge/internal/db"
+	"github.com/tOgg1/forge/internal/models"
+)
+
+const (
+	defaultHeartbeatInterval = 30 * time.Second
+	defaultHeartbeatTimeout  = 5 * time.Minute
+
+	restartBackoffBase = 5 * time.Second
+	restartBackoffMax  = 5 * time.Minute
+)
+
+// updateLoop persists the loop and stamps its heartbeat, so every state
+// transition doubles as a liveness signal.
+func (r *Runner) updateLoop(ctx context.Context, repo *db.LoopRepository, loop *models.Loop) error {
+	now := time.Now().UTC()
+	loop.HeartbeatAt = &now
+	return repo.Update(ctx, loop)
+}
+
+// startHeartbeat records a heartbeat on a fixed interval until the returned
+// stop function is called. It keeps long runs and sleeps visibly alive.
+func (r *Runner) startHeartbeat(ctx context.Context, repo *db.LoopRepository, loopID string) func() {
+	heartbeatCtx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(r.heartbeatInterval())
+		defer ticker.Stop()
+		for {
+			select {
+			case <-heartbeatCtx.Done():
+				return
+			case now := <-ticker.C:
+				if err := repo.Heartbeat(heartbeatCtx, loopID, now); err != nil && heartbeatCtx.Err() == nil {
+					r.Logger.Warn().Err(err).Str("loop_id", loopID).Msg("failed to record loop heartbeat")
+				}
+			}
+		}
+	}()
+
+	return func() {
+		cancel()
+		<-done
+	}
+}
+
+func (r *Runner) heartbeatInterval() time.Duration {
+	if r.HeartbeatInterval > 0 {
+		return r.HeartbeatInterval
+	}
+	if r.Config != nil && r.Config.LoopDefaults.HeartbeatInterval > 0 {
+		return r.Config.LoopDefaults.HeartbeatInterval
+	}
+	return defaultHeartbeatInterval
+}
+
+// Supervise runs the loop and relaunches it in-process according to its restart
+// policy. Operator stops and kills, and context cancellation, always end it.
+// A loop that stops on its own for good hands off to its chain targets.
+func (r *Runner) Supervise(ctx context.Context, loopID string) error {
+	if r.DB == nil || r.Config == nil {
+		return fmt.Errorf("runner requires database and config")
+	}
+	loopRepo := db.NewLoopRepository(r.DB)
+
+	for {
+		runErr := r.RunLoop(ctx, loopID)
+		if ctx.Err() != nil {
+			return runErr
+		}
+
+		loop, err := loopRepo.Get(ctx, loopID)
+		if err != nil {
+			return runErr
+		}
+		reason := r.loopStopReason(loop)
+		if !shouldRestart(loop.RestartPolicy, runErr, reason, r.stoppedByOperator) {
+			r.runChain(ctx, loop, reason)
+			return runErr
+		}
+
+		now := time.Now().UTC()
+		delay := restartDelay(loop, now)
+		recordRestart(loop, now.Add(delay))
+		if err := loopRepo.Update(ctx, loop); err != nil {
+			return runErr
+		}
+
+		message := fmt.Sprintf("restart policy %s: restarting in %s (attempt %d)", loop.RestartPolicy, delay, loopRestartCount(loop.Metadata))
+		if runErr != nil {
+			message = fmt.Sprintf("loop runner failed: %v; %s", runErr, message)
+		}
+		r.logLine(loop, message)
+
+		r.sleep(ctx, delay)
+		if ctx.Err() != nil {
+			return runErr
+		}
+	}
+}
+
+// logLine appends a line to the loop log outside of a running iteration.
+func (r *Runner) logLine(loop *models.Loop, message string) {
+	r.Logger.Info().Str("loop_id", loop.ID).Msg(message)
+	if loop.LogPath == "" {
+		return
+	}
+	logWriter, err := newLoopLogger(loop.LogPath)
+	if err != nil {
+		return
+	}
+	defer logWriter.Close()
+	logWriter.WriteLine(message)
+}
+
+// shouldRestart reports whether a runner exit warrants a relaunch. Only
+// unexpected exits do: a loop stopped by an operator, or by a configured limit,
+// budget or stop rule, is done.
+func shouldRestart(policy models.LoopRestartPolicy, runErr error, reason models.LoopStopReason, stoppedByOperator bool) bool {
+	if stoppedByOperator {
+		return false
+	}
+	if reason != "" && reason != models.LoopStopReasonError {
+		return false
+	}
+	switch policy {
+	case models.LoopRestartAlways:
+		return true
+	case models.LoopRestartOnFailure:
+		return runErr != nil || reason == models.LoopStopReasonError
+	default:
+		return false
+	}
+}
+
+// restartBackoff returns the delay before the given restart attempt, doubling
+// from restartBackoffBase up to restartBackoffMax.
+func restartBackoff(attempt int) time.Duration {
+	if attempt <= 1 {
+		return restartBackoffBase
+	}
+	delay := restartBackoffBase
+	for i := 1; i < attempt; i++ {
+		delay *= 2
+		if delay >= restartBackoffMax {
+			return restartBackoffMax
+		}
+	}
+	return delay
+}
+
+// restartDelay returns how long to wait before relaunching the loop. The first
+// restart after a successful run is immediate; consecutive restarts back off.
+func restartDelay(loop *models.Loop, now time.Time) time.Duration {
+	count := loopRestartCount(loop.Metadata)
+	if count == 0 {
+		return 0
+	}
+	last := loopRestartedAt(loop.Metadata)
+	if last.IsZero() {
+		return restartBackoff(count)
+	}
+	if due := last.Add(restartBackoff(count)); due.After(now) {
+		return due.Sub(now)
+	}
+	return 0
+}
+
+// restartPendingKey marks a loop whose next runner start is a restart, so the
+// runner keeps its iteration count and start time and limits still apply.
+const restartPendingKey = "restart_pending"
+
+func recordRestart(loop *models.Loop, at time.Time) {
+	if loop.Metadata == nil {
+		loop.Metadata = make(map[string]any)
+	}
+	loop.Metadata["restart_count"] = loopRestartCount(loop.Metadata) + 1
+	loop.Metadata["restarted_at"] = at.UTC().Format(time.RFC3339)
+	loop.Metadata[restartPendingKey] = true
+}
+
+// takeRestartPending reports whether the loop is being restarted and clears
+// the marker.
+func takeRestartPending(loop *models.Loop) bool {
+	if loop.Metadata == nil {
+		return false
+	}
+	pending, _ := loop.Metadata[restartPendingKey].(bool)
+	delete(loop.Metadata, restartPendingKey)
+	return pending
+}
+
+func resetRestartCount(loop *models.Loop) {
+	if loop.Metadata == nil {
+		return
+	}
+	delete(loop.Metadata, "restart_count")
+}
+
+func loopRestartCount(metadata map[string]any) int {
+	if metadata == nil {
+		return 0
+	}
+	switch v := metadata["restart_count"].(type) {
+	case int:
+		return v
+	case int64:
+		return int(v)
+	case float64:
+		return int(v)
+	case string:
+		parsed, err := strconv.Atoi(v)
+		if err == nil {
+			return parsed
+		}
+	}
+	return 0
+}
+
+func loopRestartedAt(metadata map[string]any) time.Time {
+	if metadata == nil {
+		return time.Time{}
+	}
+	value, ok := metadata["restarted_at"].(string)
+	if !ok {
+		return time.Time{}
+	}
+	parsed, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}
+	}
+	return parsed
+}
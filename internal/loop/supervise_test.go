@@ -0,0 +1,315 @@
+package loop
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/tOgg1/forge/internal/config"
+	"github.com/tOgg1/forge/internal/db"
+	"github.com/tOgg1/forge/internal/events"
+	"github.com/tOgg1/forge/internal/models"
+	"github.com/tOgg1/forge/internal/testutil"
+)
+
+func TestSuperviseAlwaysRestartsUntilOperatorStop(t *testing.T) {
+	database, cleanup := testutil.NewTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	cfg := config.DefaultConfig()
+	cfg.Global.DataDir = t.TempDir()
+	cfg.Global.ConfigDir = t.TempDir()
+
+	profile := &models.Profile{Name: "pi-restart", Harness: models.HarnessPi, PromptMode: models.PromptModeEnv, CommandTemplate: "pi"}
+	if err := db.NewProfileRepository(database).Create(ctx, profile); err != nil {
+		t.Fatalf("create profile: %v", err)
+	}
+	repoPath := t.TempDir()
+	promptPath := filepath.Join(repoPath, "PROMPT.md")
+	if err := os.WriteFile(promptPath, []byte("base"), 0o644); err != nil {
+		t.Fatalf("write prompt: %v", err)
+	}
+	loopRepo := db.NewLoopRepository(database)
+	loopEntry := &models.Loop{Name: "loop-restart", RepoPath: repoPath, BasePromptPath: promptPath, ProfileID: profile.ID, MaxIterations: 5, RestartPolicy: models.LoopRestartAlways}
+	if err := loopRepo.Create(ctx, loopEntry); err != nil {
+		t.Fatalf("create loop: %v", err)
+	}
+
+	// The first iteration removes the prompt so the next one crashes the
+	// runner; the prompt is restored when the error is published.
+	publisher := events.NewInMemoryPublisher()
+	if err := publisher.Subscribe("restore-prompt", events.Filter{EventTypes: []models.EventType{models.EventTypeLoopError}}, func(*models.Event) {
+		_ = os.WriteFile(promptPath, []byte("base"), 0o644)
+	}); err != nil {
+		t.Fatalf("subscribe: %v", err)
+	}
+
+	queueRepo := db.NewLoopQueueRepository(database)
+	calls := 0
+	runner := NewRunner(database, cfg)
+	runner.Publisher = publisher
+	runner.Exec = func(ctx context.Context, profile models.Profile, promptPath, promptContent, workDir string, output io.Writer) (int, string, error) {
+		calls++
+		switch calls {
+		case 1:
+			if err := os.Remove(filepath.Join(workDir, "PROMPT.md")); err != nil {
+				t.Fatalf("remove prompt: %v", err)
+			}
+		case 2:
+			payload, _ := json.Marshal(models.StopPayload{Reason: "operator"})
+			if err := queueRepo.Enqueue(ctx, loopEntry.ID, &models.LoopQueueItem{Type: models.LoopQueueItemStopGraceful, Payload: payload}); err != nil {
+				t.Fatalf("enqueue stop: %v", err)
+			}
+		}
+		return 0, "ok", nil
+	}
+
+	if err := runner.Supervise(ctx, loopEntry.ID); err != nil {
+		t.Fatalf("supervise: %v", err)
+	}
+	if calls != 2 {
+		t.Fatalf("expected the loop to run twice, got %d", calls)
+	}
+
+	updated, err := loopRepo.Get(ctx, loopEntry.ID)
+	if err != nil {
+		t.Fatalf("get loop: %v", err)
+	}
+	if updated.State != models.LoopStateStopped {
+		t.Fatalf("expected stopped loop, got %s", updated.State)
+	}
+	if updated.HeartbeatAt == nil {
+		t.Fatalf("expected heartbeat to be recorded")
+	}
+	if loopRestartedAt(updated.Metadata).IsZero() {
+		t.Fatalf("expected restart to be recorded, got %v", updated.Metadata)
+	}
+	if count := loopRestartCount(updated.Metadata); count != 0 {
+		t.Fatalf("expected restart count reset after a successful run, got %d", count)
+	}
+	if count := loopIterationCount(updated.Metadata); count != 2 {
+		t.Fatalf("expected the iteration count to carry across the restart, got %d", count)
+	}
+}
+
+func TestSuperviseAlwaysLeavesFinishedLoopStopped(t *testing.T) {
+	database, cleanup := testutil.NewTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	cfg := config.DefaultConfig()
+	cfg.Global.DataDir = t.TempDir()
+	cfg.Global.ConfigDir = t.TempDir()
+
+	profile := &models.Profile{Name: "pi-finished", Harness: models.HarnessPi, PromptMode: models.PromptModeEnv, CommandTemplate: "pi"}
+	if err := db.NewProfileRepository(database).Create(ctx, profile); err != nil {
+		t.Fatalf("create profile: %v", err)
+	}
+	loopRepo := db.NewLoopRepository(database)
+	loopEntry := &models.Loop{Name: "loop-finished", RepoPath: t.TempDir(), BasePromptMsg: "base", ProfileID: profile.ID, MaxIterations: 2, RestartPolicy: models.LoopRestartAlways}
+	if err := loopRepo.Create(ctx, loopEntry); err != nil {
+		t.Fatalf("create loop: %v", err)
+	}
+
+	calls := 0
+	runner := NewRunner(database, cfg)
+	runner.Exec = func(ctx context.Context, profile models.Profile, promptPath, promptContent, workDir string, output io.Writer) (int, string, error) {
+		calls++
+		return 0, "ok", nil
+	}
+
+	if err := runner.Supervise(ctx, loopEntry.ID); err != nil {
+		t.Fatalf("supervise: %v", err)
+	}
+	if calls != 2 {
+		t.Fatalf("expected max_iterations to end the loop after 2 runs, got %d", calls)
+	}
+	updated, err := loopRepo.Get(ctx, loopEntry.ID)
+	if err != nil {
+		t.Fatalf("get loop: %v", err)
+	}
+	if updated.State != models.LoopStateStopped || !loopRestartedAt(updated.Metadata).IsZero() {
+		t.Fatalf("expected a stopped loop without restarts, got %s %v", updated.State, updated.Metadata)
+	}
+}
+
+func TestShouldRestart(t *testing.T) {
+	crash := errors.New("runner crashed")
+	cases := []struct {
+		name     string
+		policy   models.LoopRestartPolicy
+		runErr   error
+		reason   models.LoopStopReason
+		operator bool
+		want     bool
+	}{
+		{name: "always after crash", policy: models.LoopRestartAlways, runErr: crash, reason: models.LoopStopReasonError, want: true},
+		{name: "on-failure after crash", policy: models.LoopRestartOnFailure, runErr: crash, reason: models.LoopStopReasonError, want: true},
+		{name: "never after crash", policy: models.LoopRestartNever, runErr: crash, reason: models.LoopStopReasonError, want: false},
+		{name: "always after max iterations", policy: models.LoopRestartAlways, reason: models.LoopStopReasonMaxIterations, want: false},
+		{name: "always after stop rule", policy: models.LoopRestartAlways, reason: models.LoopStopReasonQuant, want: false},
+		{name: "always after budget", policy: models.LoopRestartAlways, reason: models.LoopStopReasonBudget, want: false},
+		{name: "always after operator stop", policy: models.LoopRestartAlways, operator: true, want: false},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := shouldRestart(tc.policy, tc.runErr, tc.reason, tc.operator); got != tc.want {
+				t.Fatalf("shouldRestart = %t, want %t", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestSuperviseOnFailureIgnoresCleanStop(t *testing.T) {
+	database, cleanup := testutil.NewTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	cfg := config.DefaultConfig()
+	cfg.Global.DataDir = t.TempDir()
+	cfg.Global.ConfigDir = t.TempDir()
+
+	profile := &models.Profile{Name: "pi-on-failure", Harness: models.HarnessPi, PromptMode: models.PromptModeEnv, CommandTemplate: "pi"}
+	if err := db.NewProfileRepository(database).Create(ctx, profile); err != nil {
+		t.Fatalf("create profile: %v", err)
+	}
+	loopEntry := &models.Loop{Name: "loop-on-failure", RepoPath: t.TempDir(), BasePromptMsg: "base", ProfileID: profile.ID, RestartPolicy: models.LoopRestartOnFailure}
+	if err := db.NewLoopRepository(database).Create(ctx, loopEntry); err != nil {
+		t.Fatalf("create loop: %v", err)
+	}
+
+	calls := 0
+	runner := NewRunner(database, cfg)
+	runner.Exec = func(ctx context.Context, profile models.Profile, promptPath, promptContent, workDir string, output io.Writer) (int, string, error) {
+		calls++
+		return 0, "ok", nil
+	}
+	runner.StopCheck = func(ctx context.Context, loop *models.Loop, run *models.LoopRun) (bool, string, error) {
+		return true, "done", nil
+	}
+
+	if err := runner.Supervise(ctx, loopEntry.ID); err != nil {
+		t.Fatalf("supervise: %v", err)
+	}
+	if calls != 1 {
+		t.Fatalf("expected a single run, got %d", calls)
+	}
+}
+
+func TestSuperviseChainsToNextLoop(t *testing.T) {
+	database, cleanup := testutil.NewTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	cfg := config.DefaultConfig()
+	cfg.Global.DataDir = t.TempDir()
+	cfg.Global.ConfigDir = t.TempDir()
+
+	profile := &models.Profile{Name: "pi-chain", Harness: models.HarnessPi, PromptMode: models.PromptModeEnv, CommandTemplate: "pi"}
+	if err := db.NewProfileRepository(database).Create(ctx, profile); err != nil {
+		t.Fatalf("create profile: %v", err)
+	}
+	loopRepo := db.NewLoopRepository(database)
+	review := &models.Loop{Name: "loop-review", RepoPath: t.TempDir(), BasePromptMsg: "review", ProfileID: profile.ID, State: models.LoopStateStopped}
+	release := &models.Loop{Name: "loop-release", RepoPath: t.TempDir(), BasePromptMsg: "release", ProfileID: profile.ID, State: models.LoopStateStopped}
+	for _, loopEntry := range []*models.Loop{review, release} {
+		if err := loopRepo.Create(ctx, loopEntry); err != nil {
+			t.Fatalf("create loop: %v", err)
+		}
+	}
+	chain := models.LoopChainConfig{
+		OnStop:    []models.LoopChainTarget{{Loop: review.Name, Reasons: []models.LoopStopReason{models.LoopStopReasonMaxIterations}, Message: "Review the change."}},
+		OnSuccess: []models.LoopChainTarget{{Loop: release.ID}},
+	}
+	build := &models.Loop{Name: "loop-build", RepoPath: t.TempDir(), BasePromptMsg: "build", ProfileID: profile.ID, MaxIterations: 1, Metadata: ChainMetadata(chain)}
+	if err := loopRepo.Create(ctx, build); err != nil {
+		t.Fatalf("create loop: %v", err)
+	}
+	if err := db.NewLoopKVRepository(database).Set(ctx, build.ID, "branch", "feat/chain"); err != nil {
+		t.Fatalf("set kv: %v", err)
+	}
+	if err := db.NewLoopWorkStateRepository(database).SetCurrent(ctx, &models.LoopWorkState{LoopID: build.ID, AgentID: "agent-1", TaskID: "forge-42", Status: "done"}); err != nil {
+		t.Fatalf("set work: %v", err)
+	}
+
+	started := make([]string, 0)
+	runner := NewRunner(database, cfg)
+	runner.Exec = func(ctx context.Context, profile models.Profile, promptPath, promptContent, workDir string, output io.Writer) (int, string, error) {
+		return 0, "ok", nil
+	}
+	runner.StartLoop = func(loopID string) error {
+		started = append(started, loopID)
+		return nil
+	}
+
+	if err := runner.Supervise(ctx, build.ID); err != nil {
+		t.Fatalf("supervise: %v", err)
+	}
+	if len(started) != 1 || started[0] != review.ID {
+		t.Fatalf("expected only the on_stop target to start, got %v", started)
+	}
+
+	kv, err := db.NewLoopKVRepository(database).Get(ctx, review.ID, "branch")
+	if err != nil || kv.Value != "feat/chain" {
+		t.Fatalf("expected memory copied to the target, got %+v (%v)", kv, err)
+	}
+	items, err := db.NewLoopQueueRepository(database).List(ctx, review.ID)
+	if err != nil {
+		t.Fatalf("list queue: %v", err)
+	}
+	if len(items) != 2 || items[0].Type != models.LoopQueueItemMessageAppend || items[1].Type != models.LoopQueueItemTrigger {
+		t.Fatalf("expected a handoff message and trigger, got %+v", items)
+	}
+	var payload models.MessageAppendPayload
+	if err := json.Unmarshal(items[0].Payload, &payload); err != nil {
+		t.Fatalf("decode message: %v", err)
+	}
+	for _, want := range []string{"loop-build", "max_iterations", "forge-42 [done]", "branch", "Review the change."} {
+		if !strings.Contains(payload.Text, want) {
+			t.Fatalf("expected handoff message to mention %q, got %q", want, payload.Text)
+		}
+	}
+
+	releaseItems, err := db.NewLoopQueueRepository(database).List(ctx, release.ID)
+	if err != nil {
+		t.Fatalf("list queue: %v", err)
+	}
+	if len(releaseItems) != 0 {
+		t.Fatalf("expected no handoff to the on_success target, got %+v", releaseItems)
+	}
+}
+
+func TestRestartDelayBacksOff(t *testing.T) {
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	loopEntry := &models.Loop{}
+
+	if delay := restartDelay(loopEntry, now); delay != 0 {
+		t.Fatalf("expected first restart to be immediate, got %s", delay)
+	}
+
+	recordRestart(loopEntry, now)
+	if delay := restartDelay(loopEntry, now); delay != restartBackoffBase {
+		t.Fatalf("expected %s after one restart, got %s", restartBackoffBase, delay)
+	}
+
+	recordRestart(loopEntry, now)
+	recordRestart(loopEntry, now)
+	if delay := restartDelay(loopEntry, now.Add(5*time.Second)); delay != 15*time.Second {
+		t.Fatalf("expected 15s remaining on the third backoff, got %s", delay)
+	}
+	if got := restartBackoff(20); got != restartBackoffMax {
+		t.Fatalf("expected backoff capped at %s, got %s", restartBackoffMax, got)
+	}
+
+	resetRestartCount(loopEntry)
+	if delay := restartDelay(loopEntry, now); delay != 0 {
+		t.Fatalf("expected reset count to restart immediately, got %s", delay)
+	}
+}
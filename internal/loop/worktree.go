@@ -0,0 +1,275 @@
+package loop
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/tOgg1/forge/internal/models"
+)
+
+const loopWorktreeKey = "worktree"
+
+// WorktreePath returns the directory an isolated loop's worktree is checked out in.
+func WorktreePath(dataDir, name, id string) string {
+	slug := loopSlug(name)
+	if slug == "" {
+		slug = id
+	}
+	return filepath.Join(dataDir, "worktrees", slug)
+}
+
+// WorktreeBranch returns the branch an isolated loop works on.
+func WorktreeBranch(name, id string) string {
+	slug := loopSlug(name)
+	if slug == "" {
+		slug = id
+	}
+	return "forge/" + slug
+}
+
+// CreateWorktree adds a git worktree for the loop on a new branch started from
+// the current HEAD of repoPath.
+func CreateWorktree(repoPath, dataDir string, loop *models.Loop) (*models.LoopWorktree, error) {
+	root, err := gitOutput(repoPath, "rev-parse", "--show-toplevel")
+	if err != nil {
+		return nil, fmt.Errorf("worktree isolation requires a git repository: %w", err)
+	}
+	baseCommit, err := gitOutput(root, "rev-parse", "--verify", "HEAD")
+	if err != nil {
+		return nil, fmt.Errorf("worktree isolation requires at least one commit: %w", err)
+	}
+	// Empty on a detached HEAD; merges then target whatever is checked out.
+	baseBranch, _ := gitOutput(root, "symbolic-ref", "--quiet", "--short", "HEAD")
+
+	wt := &models.LoopWorktree{
+		Path:       WorktreePath(dataDir, loop.Name, loop.ID),
+		Branch:     WorktreeBranch(loop.Name, loop.ID),
+		RepoPath:   root,
+		BaseBranch: baseBranch,
+		BaseCommit: baseCommit,
+	}
+
+	if _, err := os.Stat(wt.Path); err == nil {
+		return nil, fmt.Errorf("worktree path %s already exists", wt.Path)
+	}
+	if _, err := gitOutput(root, "rev-parse", "--verify", "--quiet", "refs/heads/"+wt.Branch); err == nil {
+		return nil, fmt.Errorf("branch %s already exists; merge or delete it first", wt.Branch)
+	}
+	if err := os.MkdirAll(filepath.Dir(wt.Path), 0o755); err != nil {
+		return nil, err
+	}
+	if _, err := gitOutput(root, "worktree", "add", "-b", wt.Branch, wt.Path, baseCommit); err != nil {
+		return nil, fmt.Errorf("failed to create worktree: %w", err)
+	}
+
+	return wt, nil
+}
+
+// RemoveWorktree deletes the loop's worktree. Uncommitted work in it is first
+// committed onto the loop branch, and the branch is deleted only when it is
+// fully merged, so loop work is never lost. It returns the snapshot commit, or
+// "" when the worktree was clean.
+func RemoveWorktree(wt *models.LoopWorktree) (string, error) {
+	if wt == nil || wt.Path == "" {
+		return "", nil
+	}
+	snapshot, err := saveUncommittedWork(wt)
+	if err != nil {
+		return "", err
+	}
+	if _, err := gitOutput(wt.RepoPath, "worktree", "remove", "--force", wt.Path); err != nil {
+		if _, statErr := os.Stat(wt.Path); statErr == nil {
+			return snapshot, fmt.Errorf("failed to remove worktree %s: %w", wt.Path, err)
+		}
+		// Already gone from disk; drop git's bookkeeping for it.
+		_, _ = gitOutput(wt.RepoPath, "worktree", "prune")
+	}
+	_, _ = gitOutput(wt.RepoPath, "branch", "-d", wt.Branch)
+	return snapshot, nil
+}
+
+// saveUncommittedWork commits uncommitted changes in the worktree, untracked
+// files included, and returns the commit.
+func saveUncommittedWork(wt *models.LoopWorktree) (string, error) {
+	if _, err := os.Stat(wt.Path); err != nil {
+		return "", nil
+	}
+	status, err := gitOutput(wt.Path, "status", "--porcelain")
+	if err != nil {
+		return "", fmt.Errorf("failed to check worktree %s for uncommitted changes: %w", wt.Path, err)
+	}
+	if status == "" {
+		return "", nil
+	}
+
+	if _, err := gitOutput(wt.Path, "add", "-A"); err == nil {
+		_, err = gitCommand(wt.Path, checkpointIdentity, nil, "commit", "--no-verify", "-m", "forge: snapshot uncommitted loop work")
+		if err == nil {
+			return gitOutput(wt.Path, "rev-parse", "HEAD")
+		}
+	}
+	return "", fmt.Errorf("worktree %s has uncommitted changes that could not be saved to %s; commit or discard them first: %w", wt.Path, wt.Branch, err)
+}
+
+// LoadWorktree returns the worktree an isolated loop runs in.
+func LoadWorktree(loop *models.Loop) (*models.LoopWorktree, bool) {
+	if loop == nil || loop.Metadata == nil {
+		return nil, false
+	}
+	raw, ok := loop.Metadata[loopWorktreeKey]
+	if !ok || raw == nil {
+		return nil, false
+	}
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return nil, false
+	}
+	var wt models.LoopWorktree
+	if err := json.Unmarshal(data, &wt); err != nil || wt.Path == "" {
+		return nil, false
+	}
+	return &wt, true
+}
+
+// SetWorktree records the loop's worktree and points the loop at it.
+func SetWorktree(loop *models.Loop, wt *models.LoopWorktree) {
+	if loop.Metadata == nil {
+		loop.Metadata = make(map[string]any)
+	}
+	loop.Metadata[loopWorktreeKey] = wt
+	loop.RepoPath = wt.Path
+}
+
+// SourceRepoPath returns the main checkout a loop belongs to, which differs
+// from RepoPath for loops isolated in a worktree.
+func SourceRepoPath(loop *models.Loop) string {
+	if wt, ok := LoadWorktree(loop); ok && wt.RepoPath != "" {
+		return wt.RepoPath
+	}
+	if loop == nil {
+		return ""
+	}
+	return loop.RepoPath
+}
+
+// MergeResult reports the outcome of merging a loop branch back.
+type MergeResult struct {
+	Branch    string   `json:"branch"`
+	Into      string   `json:"into"`
+	Rebase    bool     `json:"rebase"`
+	Commits   int      `json:"commits"`
+	Merged    bool     `json:"merged"`
+	Conflicts []string `json:"conflicts,omitempty"`
+	// DirtyWorktree is set when the loop worktree has uncommitted changes, which
+	// are not part of the merge.
+	DirtyWorktree bool `json:"dirty_worktree,omitempty"`
+}
+
+// MergeWorktree brings the loop branch into the branch checked out in the main
+// repo. With rebase, the loop branch is first rebased onto it and then
+// fast-forwarded. A conflicting merge or rebase is aborted and its conflicting
+// files are reported in the result.
+func MergeWorktree(wt *models.LoopWorktree, rebase bool) (*MergeResult, error) {
+	if wt == nil {
+		return nil, errors.New("loop has no worktree")
+	}
+	into, err := gitOutput(wt.RepoPath, "symbolic-ref", "--quiet", "--short", "HEAD")
+	if err != nil {
+		return nil, fmt.Errorf("%s has a detached HEAD; check out the branch to merge into", wt.RepoPath)
+	}
+	if status, err := gitOutput(wt.RepoPath, "status", "--porcelain", "--untracked-files=no"); err != nil {
+		return nil, err
+	} else if status != "" {
+		return nil, fmt.Errorf("%s has uncommitted changes; commit or stash them first", wt.RepoPath)
+	}
+
+	result := &MergeResult{Branch: wt.Branch, Into: into, Rebase: rebase}
+	if status, err := gitOutput(wt.Path, "status", "--porcelain"); err == nil && status != "" {
+		result.DirtyWorktree = true
+	}
+
+	count, err := gitOutput(wt.RepoPath, "rev-list", "--count", into+".."+wt.Branch)
+	if err != nil {
+		return nil, err
+	}
+	result.Commits, _ = strconv.Atoi(count)
+	if result.Commits == 0 {
+		return result, nil
+	}
+
+	if rebase {
+		if _, err := gitOutput(wt.Path, "rebase", into); err != nil {
+			conflicts := conflictedFiles(wt.Path)
+			_, _ = gitOutput(wt.Path, "rebase", "--abort")
+			if len(conflicts) == 0 {
+				return nil, fmt.Errorf("rebase onto %s failed: %w", into, err)
+			}
+			result.Conflicts = conflicts
+			return result, nil
+		}
+		if _, err := gitOutput(wt.RepoPath, "merge", "--ff-only", wt.Branch); err != nil {
+			return nil, fmt.Errorf("fast-forward %s failed: %w", into, err)
+		}
+		result.Merged = true
+		return result, nil
+	}
+
+	if _, err := gitOutput(wt.RepoPath, "merge", "--no-ff", "--no-edit", wt.Branch); err != nil {
+		conflicts := conflictedFiles(wt.RepoPath)
+		_, _ = gitOutput(wt.RepoPath, "merge", "--abort")
+		if len(conflicts) == 0 {
+			return nil, fmt.Errorf("merge into %s failed: %w", into, err)
+		}
+		result.Conflicts = conflicts
+		return result, nil
+	}
+	result.Merged = true
+	return result, nil
+}
+
+func conflictedFiles(dir string) []string {
+	out, err := gitOutput(dir, "diff", "--name-only", "--diff-filter=U")
+	if err != nil || out == "" {
+		return nil
+	}
+	return strings.Split(out, "\n")
+}
+
+// gitOutput runs git in dir and returns trimmed stdout; errors carry stderr.
+func gitOutput(dir string, args ...string) (string, error) {
+	out, err := gitCommand(dir, nil, nil, args...)
+	return strings.TrimSpace(string(out)), err
+}
+
+// gitCommand runs git in dir with extra environment and optional stdin and
+// returns raw stdout; errors carry stderr.
+func gitCommand(dir string, env []string, stdin []byte, args ...string) ([]byte, error) {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	if len(env) > 0 {
+		cmd.Env = append(os.Environ(), env...)
+	}
+	if stdin != nil {
+		cmd.Stdin = bytes.NewReader(stdin)
+	}
+
+	var stdout bytes.Buffer
+	var stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("git %s: %s", args[0], msg)
+		}
+		return nil, fmt.Errorf("git %s: %w", args[0], err)
+	}
+	return stdout.Bytes(), nil
+}
@@ -0,0 +1,147 @@
+package loop
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/tOgg1/forge/internal/models"
+)
+
+func initTestRepo(t *testing.T) string {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	repo := t.TempDir()
+	for _, args := range [][]string{
+		{"init", "-q", "-b", "main"},
+		{"config", "user.email", "forge@example.com"},
+		{"config", "user.name", "Forge Test"},
+	} {
+		if _, err := gitOutput(repo, args...); err != nil {
+			t.Fatalf("git %v: %v", args, err)
+		}
+	}
+	commitFile(t, repo, "README.md", "hello\n", "initial")
+	return repo
+}
+
+func commitFile(t *testing.T, dir, name, content, message string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+		t.Fatalf("write %s: %v", name, err)
+	}
+	if _, err := gitOutput(dir, "add", name); err != nil {
+		t.Fatalf("git add: %v", err)
+	}
+	if _, err := gitOutput(dir, "commit", "-q", "-m", message); err != nil {
+		t.Fatalf("git commit: %v", err)
+	}
+}
+
+func TestWorktreeLifecycle(t *testing.T) {
+	repo := initTestRepo(t)
+	dataDir := t.TempDir()
+	loopEntry := &models.Loop{ID: "loop-1", Name: "Feature Loop", RepoPath: repo}
+
+	wt, err := CreateWorktree(repo, dataDir, loopEntry)
+	if err != nil {
+		t.Fatalf("create worktree: %v", err)
+	}
+	if wt.Branch != "forge/feature-loop" || wt.BaseBranch != "main" {
+		t.Fatalf("unexpected worktree: %+v", wt)
+	}
+	SetWorktree(loopEntry, wt)
+	if loopEntry.RepoPath != wt.Path || SourceRepoPath(loopEntry) != wt.RepoPath {
+		t.Fatalf("expected loop to run in worktree, got %q (source %q)", loopEntry.RepoPath, SourceRepoPath(loopEntry))
+	}
+
+	commitFile(t, wt.Path, "feature.txt", "feature\n", "add feature")
+	loaded, ok := LoadWorktree(loopEntry)
+	if !ok || loaded.Path != wt.Path {
+		t.Fatalf("expected worktree in metadata, got %+v", loaded)
+	}
+
+	result, err := MergeWorktree(loaded, false)
+	if err != nil {
+		t.Fatalf("merge: %v", err)
+	}
+	if !result.Merged || result.Commits != 1 || result.Into != "main" {
+		t.Fatalf("unexpected merge result: %+v", result)
+	}
+	if _, err := os.Stat(filepath.Join(repo, "feature.txt")); err != nil {
+		t.Fatalf("expected merged file in main repo: %v", err)
+	}
+
+	if snapshot, err := RemoveWorktree(loaded); err != nil || snapshot != "" {
+		t.Fatalf("remove worktree: snapshot %q, err %v", snapshot, err)
+	}
+	if _, err := os.Stat(wt.Path); !os.IsNotExist(err) {
+		t.Fatalf("expected worktree directory removed, got %v", err)
+	}
+	if _, err := gitOutput(repo, "rev-parse", "--verify", "--quiet", "refs/heads/"+wt.Branch); err == nil {
+		t.Fatalf("expected merged branch to be deleted")
+	}
+}
+
+func TestRemoveWorktreeSavesUncommittedWork(t *testing.T) {
+	repo := initTestRepo(t)
+	loopEntry := &models.Loop{ID: "loop-3", Name: "dirty", RepoPath: repo}
+	wt, err := CreateWorktree(repo, t.TempDir(), loopEntry)
+	if err != nil {
+		t.Fatalf("create worktree: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(wt.Path, "wip.txt"), []byte("half done\n"), 0o644); err != nil {
+		t.Fatalf("write wip: %v", err)
+	}
+
+	snapshot, err := RemoveWorktree(wt)
+	if err != nil {
+		t.Fatalf("remove worktree: %v", err)
+	}
+	if snapshot == "" {
+		t.Fatalf("expected uncommitted work to be committed")
+	}
+	if _, err := os.Stat(wt.Path); !os.IsNotExist(err) {
+		t.Fatalf("expected worktree directory removed, got %v", err)
+	}
+	head, err := gitOutput(repo, "rev-parse", "refs/heads/"+wt.Branch)
+	if err != nil || head != snapshot {
+		t.Fatalf("expected branch %s kept at the snapshot %s, got %q (%v)", wt.Branch, snapshot, head, err)
+	}
+	if content, err := gitOutput(repo, "show", wt.Branch+":wip.txt"); err != nil || content != "half done" {
+		t.Fatalf("expected wip.txt in the snapshot, got %q (%v)", content, err)
+	}
+}
+
+func TestMergeWorktreeReportsConflicts(t *testing.T) {
+	for _, rebase := range []bool{false, true} {
+		repo := initTestRepo(t)
+		loopEntry := &models.Loop{ID: "loop-2", Name: "conflict", RepoPath: repo}
+		wt, err := CreateWorktree(repo, t.TempDir(), loopEntry)
+		if err != nil {
+			t.Fatalf("create worktree: %v", err)
+		}
+
+		commitFile(t, wt.Path, "README.md", "from loop\n", "loop change")
+		commitFile(t, repo, "README.md", "from main\n", "main change")
+
+		result, err := MergeWorktree(wt, rebase)
+		if err != nil {
+			t.Fatalf("merge (rebase=%v): %v", rebase, err)
+		}
+		if result.Merged || len(result.Conflicts) != 1 || result.Conflicts[0] != "README.md" {
+			t.Fatalf("expected README.md conflict (rebase=%v), got %+v", rebase, result)
+		}
+
+		status, err := gitOutput(repo, "status", "--porcelain")
+		if err != nil {
+			t.Fatalf("git status: %v", err)
+		}
+		if status != "" {
+			t.Fatalf("expected aborted merge to leave main repo clean, got %q", status)
+		}
+	}
+}
@@ -18,6 +18,7 @@ import (
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/tOgg1/forge/internal/db"
+	"github.com/tOgg1/forge/internal/harness"
 	"github.com/tOgg1/forge/internal/loop"
 	"github.com/tOgg1/forge/internal/models"
 	"github.com/tOgg1/forge/internal/names"
@@ -30,6 +31,8 @@ const (
 
 	minWindowWidth  = 80
 	minWindowHeight = 22
+
+	maxApprovalRows = 3
 )
 
 const (
@@ -58,6 +61,7 @@ type Config struct {
 	DefaultPrompt    string
 	DefaultPromptMsg string
 	ConfigFile       string
+	HeartbeatTimeout time.Duration
 }
 
 // Run starts the loop TUI.
@@ -119,9 +123,21 @@ type loopView struct {
 	PoolName    string
 }
 
+// approvalView is a workflow human step waiting on a decision.
+type approvalView struct {
+	RunID    string
+	StepID   string
+	Workflow string
+	Prompt   string
+}
+
 type logTailView struct {
 	Lines   []string
 	Message string
+
+	// EventsRunID and Events describe the newest run with stream-json events.
+	EventsRunID string
+	Events      []models.LoopRunEvent
 }
 
 type confirmState struct {
@@ -160,6 +176,7 @@ type model struct {
 	defaultPrompt    string
 	defaultPromptMsg string
 	configFile       string
+	heartbeatTimeout time.Duration
 
 	width  int
 	height int
@@ -169,6 +186,7 @@ type model struct {
 	selectedID  string
 	selectedIdx int
 	selectedLog logTailView
+	approvals   []approvalView
 
 	mode        uiMode
 	filterText  string
@@ -187,6 +205,7 @@ type model struct {
 
 type refreshMsg struct {
 	loops      []loopView
+	approvals  []approvalView
 	selectedID string
 	selected   logTailView
 	err        error
@@ -221,6 +240,7 @@ func newModel(database *db.DB, cfg Config) model {
 		defaultPrompt:    cfg.DefaultPrompt,
 		defaultPromptMsg: cfg.DefaultPromptMsg,
 		configFile:       cfg.ConfigFile,
+		heartbeatTimeout: cfg.HeartbeatTimeout,
 		mode:             modeMain,
 		filterState:      "all",
 		filterFocus:      filterFocusText,
@@ -248,6 +268,7 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.err = msg.err
 		if msg.err == nil {
 			m.loops = msg.loops
+			m.approvals = msg.approvals
 			oldSelectedID := m.selectedID
 			oldSelectedIdx := m.selectedIdx
 			m.applyFilters(oldSelectedID, oldSelectedIdx)
@@ -315,14 +336,16 @@ func (m model) View() string {
 	height := m.effectiveHeight()
 
 	header := m.renderHeader()
+	approvals := m.renderApprovals(width)
 	leftWidth, rightWidth := paneWidths(width)
-	paneHeight := maxInt(10, height-8)
+	paneHeight := maxInt(10, height-8-len(approvals))
 
 	leftPane := m.renderLeftPane(leftWidth, paneHeight)
 	rightPane := m.renderRightPane(rightWidth, paneHeight)
 	body := lipgloss.JoinHorizontal(lipgloss.Top, leftPane, rightPane)
 
 	parts := []string{header, body}
+	parts = append(parts, approvals...)
 	if m.mode == modeFilter {
 		parts = append(parts, m.renderFilterBar(width))
 	}
@@ -768,6 +791,7 @@ func (m model) fetchCmd() tea.Cmd {
 	dataDir := m.dataDir
 	selectedID := m.selectedID
 	logLines := m.desiredLogLines()
+	heartbeatTimeout := m.heartbeatTimeout
 
 	if selectedID == "" && len(m.filtered) > 0 && m.selectedIdx >= 0 && m.selectedIdx < len(m.filtered) {
 		selectedID = m.filtered[m.selectedIdx].Loop.ID
@@ -777,14 +801,25 @@ func (m model) fetchCmd() tea.Cmd {
 		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
 		defer cancel()
 
+		reapOrphanedLoops(ctx, database, heartbeatTimeout)
+
 		views, err := loadLoopViews(ctx, database)
 		if err != nil {
 			return refreshMsg{err: err}
 		}
 
+		approvals, err := loadApprovalViews(ctx, database)
+		if err != nil {
+			return refreshMsg{err: err}
+		}
+
 		logLoopID, tail := loadSelectedLogTail(views, selectedID, dataDir, logLines)
+		if logLoopID != "" {
+			tail.EventsRunID, tail.Events = loadLastRunEvents(ctx, database, logLoopID)
+		}
 		return refreshMsg{
 			loops:      views,
+			approvals:  approvals,
 			selectedID: logLoopID,
 			selected:   tail,
 		}
@@ -971,6 +1006,10 @@ func (m model) renderRightPane(width, height int) string {
 	lines = append(lines, fmt.Sprintf("Pool: %s", displayName(view.PoolName, loopEntry.PoolID)))
 	lines = append(lines, fmt.Sprintf("Profile: %s", displayName(view.ProfileName, loopEntry.ProfileID)))
 	lines = append(lines, fmt.Sprintf("Last Run: %s", formatTime(loopEntry.LastRunAt)))
+	if strings.TrimSpace(loopEntry.Schedule) != "" {
+		lines = append(lines, fmt.Sprintf("Schedule: %s", loopEntry.Schedule))
+	}
+	lines = append(lines, fmt.Sprintf("Next Run: %s", formatNextRun(loopEntry)))
 	lines = append(lines, fmt.Sprintf("Queue Depth: %d", view.QueueDepth))
 	lines = append(lines, fmt.Sprintf("Interval: %s", formatDurationSeconds(loopEntry.IntervalSeconds)))
 	lines = append(lines, fmt.Sprintf("Max Runtime: %s", formatDurationSeconds(loopEntry.MaxRuntimeSeconds)))
@@ -988,6 +1027,15 @@ func (m model) renderRightPane(width, height int) string {
 	content = append(content, lines...)
 
 	availableForLogs := height - len(lines) - 6
+	if events := m.selectedLog.Events; len(events) > 0 && availableForLogs >= 8 {
+		// Split the space between the last run's events and the log.
+		shown := minInt(len(events), availableForLogs/2-2)
+		content = append(content, "", fmt.Sprintf("Last Run Events (%s):", shortRunID(m.selectedLog.EventsRunID)))
+		for _, event := range events[len(events)-shown:] {
+			content = append(content, truncateLine(formatRunEvent(event), contentWidth))
+		}
+		availableForLogs -= shown + 2
+	}
 	if availableForLogs >= 4 {
 		content = append(content, "", "Logs:")
 		logLines := m.selectedLog.Lines
@@ -1172,6 +1220,32 @@ func renderWizardField(label, value string, focused bool) string {
 	return fmt.Sprintf("%s: %s", label, display)
 }
 
+// renderApprovals lists workflow human steps waiting on a decision.
+func (m model) renderApprovals(width int) []string {
+	if len(m.approvals) == 0 {
+		return nil
+	}
+
+	contentWidth := maxInt(1, width-1)
+	headerStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(colorWaiting)).Bold(true)
+	lines := []string{headerStyle.Render(truncateLine(
+		fmt.Sprintf("Awaiting approval (%d): forge workflow approve|reject <run> <step> --note ...", len(m.approvals)),
+		contentWidth,
+	))}
+	for i, approval := range m.approvals {
+		if i == maxApprovalRows {
+			lines = append(lines, truncateLine(fmt.Sprintf("  ... %d more (forge workflow status)", len(m.approvals)-i), contentWidth))
+			break
+		}
+		line := fmt.Sprintf("  %s %s %s", approval.Workflow, approval.RunID, approval.StepID)
+		if approval.Prompt != "" {
+			line += ": " + approval.Prompt
+		}
+		lines = append(lines, truncateLine(line, contentWidth))
+	}
+	return lines
+}
+
 func (m model) renderStatusLine(width int) string {
 	style := lipgloss.NewStyle()
 	switch m.statusKind {
@@ -1200,6 +1274,17 @@ func statusStyle(state models.LoopState) lipgloss.Style {
 	}
 }
 
+// reapOrphanedLoops marks loops with a dead runner as orphaned before each
+// refresh. Restart policies are left to forged.
+func reapOrphanedLoops(ctx context.Context, database *db.DB, heartbeatTimeout time.Duration) {
+	if database == nil {
+		return
+	}
+	reaper := loop.NewReaper(database, nil)
+	reaper.HeartbeatTimeout = heartbeatTimeout
+	_, _ = reaper.Reap(ctx)
+}
+
 func loadLoopViews(ctx context.Context, database *db.DB) ([]loopView, error) {
 	if database == nil {
 		return nil, errors.New("database is nil")
@@ -1263,6 +1348,34 @@ func loadLoopViews(ctx context.Context, database *db.DB) ([]loopView, error) {
 	return views, nil
 }
 
+func loadApprovalViews(ctx context.Context, database *db.DB) ([]approvalView, error) {
+	approvals, err := db.NewApprovalRepository(database).ListPendingWorkflow(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	views := make([]approvalView, 0, len(approvals))
+	for _, approval := range approvals {
+		var details struct {
+			Workflow string `json:"workflow"`
+			Prompt   string `json:"prompt"`
+		}
+		_ = json.Unmarshal(approval.RequestDetails, &details)
+
+		runID := approval.WorkflowRunID
+		if len(runID) > 8 {
+			runID = runID[:8]
+		}
+		views = append(views, approvalView{
+			RunID:    runID,
+			StepID:   approval.WorkflowStepID,
+			Workflow: details.Workflow,
+			Prompt:   strings.Join(strings.Fields(details.Prompt), " "),
+		})
+	}
+	return views, nil
+}
+
 func loadSelectedLogTail(views []loopView, selectedID, dataDir string, maxLines int) (string, logTailView) {
 	if maxLines <= 0 {
 		maxLines = defaultLogLines
@@ -1302,6 +1415,20 @@ func loadSelectedLogTail(views []loopView, selectedID, dataDir string, maxLines
 	return selected.ID, logTailView{Lines: tail}
 }
 
+// loadLastRunEvents returns the structured events of a loop's newest run that
+// recorded any.
+func loadLastRunEvents(ctx context.Context, database *db.DB, loopID string) (string, []models.LoopRunEvent) {
+	run, err := db.NewLoopRunRepository(database).LastWithEvents(ctx, loopID)
+	if err != nil {
+		return "", nil
+	}
+	events, err := loop.ReadRunEvents(run.EventsPath)
+	if err != nil {
+		return "", nil
+	}
+	return run.ID, events
+}
+
 func tailFile(path string, maxLines int) ([]string, error) {
 	if maxLines <= 0 {
 		return nil, nil
@@ -1977,6 +2104,18 @@ func loopDisplayID(loopEntry *models.Loop) string {
 	return loopEntry.ID[:8]
 }
 
+func shortRunID(id string) string {
+	if len(id) <= 8 {
+		return id
+	}
+	return id[:8]
+}
+
+// formatRunEvent renders a run event on a single line.
+func formatRunEvent(event models.LoopRunEvent) string {
+	return strings.Join(strings.Fields(harness.FormatEvent(event)), " ")
+}
+
 func displayName(name, fallback string) string {
 	if strings.TrimSpace(name) != "" {
 		return name
@@ -2008,6 +2147,18 @@ func formatTime(value *time.Time) string {
 	return value.UTC().Format(time.RFC3339)
 }
 
+func formatNextRun(loopEntry *models.Loop) string {
+	if loopEntry.NextRunAt != nil {
+		return formatTime(loopEntry.NextRunAt)
+	}
+	if loopEntry.State == models.LoopStateSleeping {
+		if schedule, err := models.ParseLoopSchedule(loopEntry.Schedule); err == nil && schedule != nil && schedule.Kind == models.LoopScheduleTrigger {
+			return "on trigger"
+		}
+	}
+	return "-"
+}
+
 func truncateLine(text string, width int) string {
 	if width <= 0 {
 		return ""
@@ -231,6 +231,40 @@ func TestViewRendersErrorStateWithoutCrashing(t *testing.T) {
 	}
 }
 
+func TestViewShowsPendingWorkflowApprovals(t *testing.T) {
+	m := newModel(nil, Config{RefreshInterval: time.Second, LogLines: 8})
+	m.approvals = []approvalView{{RunID: "ab12cd34", StepID: "signoff", Workflow: "release", Prompt: "Ship it?"}}
+
+	out := m.View()
+	if !strings.Contains(out, "Awaiting approval (1)") || !strings.Contains(out, "release ab12cd34 signoff: Ship it?") {
+		t.Fatalf("expected pending approval rendering, got:\n%s", out)
+	}
+}
+
+func TestViewShowsLastRunEvents(t *testing.T) {
+	m := newModel(nil, Config{RefreshInterval: time.Second, LogLines: 8})
+	m.width = 160
+	m.height = 48
+	m.loops = []loopView{testLoopView("loop-1", "abc01", "alpha", models.LoopStateRunning, "/repo/alpha")}
+	m.applyFilters("", 0)
+	m.selectedLog = logTailView{
+		Lines:       []string{"[2026-10-16T10:00:00Z] loop started"},
+		EventsRunID: "3f2a9c1e-0000",
+		Events: []models.LoopRunEvent{
+			{Kind: models.LoopRunEventToolCall, Tool: "Bash", Text: "go test ./...", IsError: true},
+			{Kind: models.LoopRunEventFileEdit, Tool: "Edit", Path: "main.go"},
+			{Kind: models.LoopRunEventResult, Text: "Fixed\nthe build"},
+		},
+	}
+
+	out := m.View()
+	for _, want := range []string{"Last Run Events (3f2a9c1e):", "[tool] Bash: go test ./... (failed)", "[edit] Edit main.go", "[result] success: Fixed the build", "loop started"} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("expected %q in view, got:\n%s", want, out)
+		}
+	}
+}
+
 func testLoopView(id, shortID, name string, state models.LoopState, repo string) loopView {
 	return loopView{Loop: &models.Loop{ID: id, ShortID: shortID, Name: name, State: state, RepoPath: repo, CreatedAt: time.Now().UTC()}}
 }
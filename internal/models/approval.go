@@ -19,13 +19,22 @@ const (
 // ApprovalRequestType describes the kind of approval requested.
 type ApprovalRequestType string
 
-// Approval captures an approval request from an agent.
+// ApprovalRequestWorkflowStep is requested by a human step in a workflow run.
+const ApprovalRequestWorkflowStep ApprovalRequestType = "workflow_step"
+
+// Approval captures an approval request from an agent or a workflow step.
 type Approval struct {
 	// ID is the unique identifier for the approval.
 	ID string `json:"id"`
 
 	// AgentID references the agent that requested approval.
-	AgentID string `json:"agent_id"`
+	AgentID string `json:"agent_id,omitempty"`
+
+	// WorkflowRunID references the workflow run that requested approval.
+	WorkflowRunID string `json:"workflow_run_id,omitempty"`
+
+	// WorkflowStepID is the human step waiting on this approval.
+	WorkflowStepID string `json:"workflow_step_id,omitempty"`
 
 	// RequestType categorizes the approval request.
 	RequestType ApprovalRequestType `json:"request_type"`
@@ -44,13 +53,16 @@ type Approval struct {
 
 	// ResolvedBy indicates who or what resolved the approval (user/policy).
 	ResolvedBy string `json:"resolved_by,omitempty"`
+
+	// ResolutionNote is the optional note left by the resolver.
+	ResolutionNote string `json:"resolution_note,omitempty"`
 }
 
 // Validate checks if the approval request is valid.
 func (a *Approval) Validate() error {
 	validation := &ValidationErrors{}
-	if strings.TrimSpace(a.AgentID) == "" {
-		validation.AddMessage("agent_id", "agent_id is required")
+	if strings.TrimSpace(a.AgentID) == "" && strings.TrimSpace(a.WorkflowRunID) == "" {
+		validation.AddMessage("agent_id", "agent_id or workflow_run_id is required")
 	}
 	if strings.TrimSpace(string(a.RequestType)) == "" {
 		validation.AddMessage("request_type", "request_type is required")
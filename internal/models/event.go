@@ -47,6 +47,16 @@ const (
 	EventTypeCooldownEnded     EventType = "cooldown.ended"
 	EventTypeAccountRotated    EventType = "account.rotated"
 
+	// Loop events
+	EventTypeLoopStarted     EventType = "loop.started"
+	EventTypeLoopRunFinished EventType = "loop.run.finished"
+	EventTypeLoopStopMatched EventType = "loop.stop.matched"
+	EventTypeLoopWaiting     EventType = "loop.waiting"
+	EventTypeLoopError       EventType = "loop.error"
+
+	// Hook events
+	EventTypeHookTest EventType = "hook.test"
+
 	// System events
 	EventTypeError   EventType = "error"
 	EventTypeWarning EventType = "warning"
@@ -62,6 +72,7 @@ const (
 	EntityTypeQueue     EntityType = "queue"
 	EntityTypeAccount   EntityType = "account"
 	EntityTypeSystem    EntityType = "system"
+	EntityTypeLoop      EntityType = "loop"
 )
 
 // Event represents an append-only log entry.
@@ -159,6 +170,54 @@ type AccountRotatedPayload struct {
 	Reason       string `json:"reason"`
 }
 
+// LoopStartedPayload is the payload for loop.started events.
+type LoopStartedPayload struct {
+	LoopName  string `json:"loop_name"`
+	RepoPath  string `json:"repo_path"`
+	ProfileID string `json:"profile_id,omitempty"`
+	PoolID    string `json:"pool_id,omitempty"`
+	Iteration int    `json:"iteration"`
+}
+
+// LoopRunFinishedPayload is the payload for loop.run.finished events.
+type LoopRunFinishedPayload struct {
+	LoopName         string        `json:"loop_name"`
+	RunID            string        `json:"run_id"`
+	Status           LoopRunStatus `json:"status"`
+	ExitCode         *int          `json:"exit_code,omitempty"`
+	Duration         string        `json:"duration"`
+	DurationMs       int64         `json:"duration_ms"`
+	ProfileID        string        `json:"profile_id"`
+	ProfileName      string        `json:"profile_name"`
+	Kind             string        `json:"kind"`
+	Iteration        int           `json:"iteration"`
+	MessagesConsumed int           `json:"messages_consumed"`
+	Error            string        `json:"error,omitempty"`
+}
+
+// LoopStopMatchedPayload is the payload for loop.stop.matched events.
+type LoopStopMatchedPayload struct {
+	LoopName string `json:"loop_name"`
+	RunID    string `json:"run_id,omitempty"`
+	Rule     string `json:"rule"`
+	Decision string `json:"decision"`
+	Reason   string `json:"reason,omitempty"`
+}
+
+// LoopWaitingPayload is the payload for loop.waiting events.
+type LoopWaitingPayload struct {
+	LoopName  string    `json:"loop_name"`
+	WaitUntil time.Time `json:"wait_until"`
+	Reason    string    `json:"reason"`
+}
+
+// LoopErrorPayload is the payload for loop.error events.
+type LoopErrorPayload struct {
+	LoopName string `json:"loop_name"`
+	Stage    string `json:"stage"`
+	Error    string `json:"error"`
+}
+
 // ErrorPayload is the payload for error events.
 type ErrorPayload struct {
 	Error      string `json:"error"`
@@ -0,0 +1,58 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// HookDeliveryStatus represents the lifecycle state of a hook delivery.
+type HookDeliveryStatus string
+
+const (
+	// HookDeliveryPending is waiting for its first or next attempt.
+	HookDeliveryPending HookDeliveryStatus = "pending"
+	// HookDeliveryDelivered succeeded.
+	HookDeliveryDelivered HookDeliveryStatus = "delivered"
+	// HookDeliveryDead gave up after exhausting retries or a permanent error.
+	HookDeliveryDead HookDeliveryStatus = "dead"
+)
+
+// HookDelivery is one event queued for delivery to one hook.
+type HookDelivery struct {
+	// ID is the unique identifier for the delivery.
+	ID string `json:"id"`
+
+	// HookID references the hook the event is delivered to.
+	HookID string `json:"hook_id"`
+
+	// EventID and EventType describe the delivered event.
+	EventID   string    `json:"event_id,omitempty"`
+	EventType EventType `json:"event_type,omitempty"`
+
+	// Payload is the event as JSON, kept so the delivery can be retried or replayed.
+	Payload json.RawMessage `json:"payload"`
+
+	// Status is the current delivery status.
+	Status HookDeliveryStatus `json:"status"`
+
+	// Attempts is how many times delivery was tried.
+	Attempts int `json:"attempts"`
+
+	// NextAttemptAt is when a pending delivery is tried next.
+	NextAttemptAt *time.Time `json:"next_attempt_at,omitempty"`
+
+	// LastError is the error from the most recent failed attempt.
+	LastError string `json:"last_error,omitempty"`
+
+	// LastStatusCode is the HTTP status of the most recent webhook attempt.
+	LastStatusCode *int `json:"last_status_code,omitempty"`
+
+	// ReplayOf references the delivery this one replays.
+	ReplayOf string `json:"replay_of,omitempty"`
+
+	// DeliveredAt is when delivery succeeded.
+	DeliveredAt *time.Time `json:"delivered_at,omitempty"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
@@ -16,29 +16,48 @@ const (
 	LoopStateError    LoopState = "error"
 )
 
+// LoopRestartPolicy controls whether a loop runner is relaunched after it exits.
+type LoopRestartPolicy string
+
+const (
+	// LoopRestartNever leaves the loop stopped or errored (default).
+	LoopRestartNever LoopRestartPolicy = "never"
+	// LoopRestartOnFailure relaunches the loop after a runner error or when it is orphaned.
+	LoopRestartOnFailure LoopRestartPolicy = "on-failure"
+	// LoopRestartAlways also relaunches the loop after it stops on its own; operator
+	// stops and kills are still honoured.
+	LoopRestartAlways LoopRestartPolicy = "always"
+)
+
 // Loop represents a background agent loop tied to a repo.
 type Loop struct {
-	ID                string         `json:"id"`
-	ShortID           string         `json:"short_id"`
-	Name              string         `json:"name"`
-	RepoPath          string         `json:"repo_path"`
-	BasePromptPath    string         `json:"base_prompt_path,omitempty"`
-	BasePromptMsg     string         `json:"base_prompt_msg,omitempty"`
-	IntervalSeconds   int            `json:"interval_seconds"`
-	MaxIterations     int            `json:"max_iterations,omitempty"`
-	MaxRuntimeSeconds int            `json:"max_runtime_seconds,omitempty"`
-	PoolID            string         `json:"pool_id,omitempty"`
-	ProfileID         string         `json:"profile_id,omitempty"`
-	State             LoopState      `json:"state"`
-	LastRunAt         *time.Time     `json:"last_run_at,omitempty"`
-	LastExitCode      *int           `json:"last_exit_code,omitempty"`
-	LastError         string         `json:"last_error,omitempty"`
-	LogPath           string         `json:"log_path,omitempty"`
-	LedgerPath        string         `json:"ledger_path,omitempty"`
-	Tags              []string       `json:"tags,omitempty"`
-	Metadata          map[string]any `json:"metadata,omitempty"`
-	CreatedAt         time.Time      `json:"created_at"`
-	UpdatedAt         time.Time      `json:"updated_at"`
+	ID                string            `json:"id"`
+	ShortID           string            `json:"short_id"`
+	Name              string            `json:"name"`
+	RepoPath          string            `json:"repo_path"`
+	BasePromptPath    string            `json:"base_prompt_path,omitempty"`
+	BasePromptMsg     string            `json:"base_prompt_msg,omitempty"`
+	IntervalSeconds   int               `json:"interval_seconds"`
+	MaxIterations     int               `json:"max_iterations,omitempty"`
+	MaxRuntimeSeconds int               `json:"max_runtime_seconds,omitempty"`
+	MaxTokens         int64             `json:"max_tokens,omitempty"`
+	MaxCostUSD        float64           `json:"max_cost_usd,omitempty"`
+	PoolID            string            `json:"pool_id,omitempty"`
+	ProfileID         string            `json:"profile_id,omitempty"`
+	State             LoopState         `json:"state"`
+	LastRunAt         *time.Time        `json:"last_run_at,omitempty"`
+	LastExitCode      *int              `json:"last_exit_code,omitempty"`
+	LastError         string            `json:"last_error,omitempty"`
+	LogPath           string            `json:"log_path,omitempty"`
+	LedgerPath        string            `json:"ledger_path,omitempty"`
+	RestartPolicy     LoopRestartPolicy `json:"restart_policy,omitempty"`
+	HeartbeatAt       *time.Time        `json:"heartbeat_at,omitempty"`
+	Schedule          string            `json:"schedule,omitempty"`
+	NextRunAt         *time.Time        `json:"next_run_at,omitempty"`
+	Tags              []string          `json:"tags,omitempty"`
+	Metadata          map[string]any    `json:"metadata,omitempty"`
+	CreatedAt         time.Time         `json:"created_at"`
+	UpdatedAt         time.Time         `json:"updated_at"`
 }
 
 // Validate checks if the loop is valid.
@@ -62,6 +81,20 @@ func (l *Loop) Validate() error {
 	if l.MaxRuntimeSeconds < 0 {
 		validation.AddMessage("max_runtime_seconds", "max_runtime_seconds must be >= 0")
 	}
+	if l.MaxTokens < 0 {
+		validation.AddMessage("max_tokens", "max_tokens must be >= 0")
+	}
+	if l.MaxCostUSD < 0 {
+		validation.AddMessage("max_cost_usd", "max_cost_usd must be >= 0")
+	}
+	switch l.RestartPolicy {
+	case "", LoopRestartNever, LoopRestartOnFailure, LoopRestartAlways:
+	default:
+		validation.AddMessage("restart_policy", "restart_policy must be never, on-failure, or always")
+	}
+	if _, err := ParseLoopSchedule(l.Schedule); err != nil {
+		validation.AddMessage("schedule", err.Error())
+	}
 	if validation.Err() != nil {
 		return validation.Err()
 	}
@@ -0,0 +1,114 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// LoopStopReason classifies why a loop stopped on its own.
+type LoopStopReason string
+
+const (
+	LoopStopReasonQuant         LoopStopReason = "quant"
+	LoopStopReasonQual          LoopStopReason = "qual"
+	LoopStopReasonRule          LoopStopReason = "rule"
+	LoopStopReasonCondition     LoopStopReason = "condition"
+	LoopStopReasonMaxIterations LoopStopReason = "max_iterations"
+	LoopStopReasonMaxRuntime    LoopStopReason = "max_runtime"
+	LoopStopReasonBudget        LoopStopReason = "budget"
+	LoopStopReasonError         LoopStopReason = "error"
+)
+
+// Succeeded reports whether the loop decided it was done: a quant, qual,
+// composite or workflow stop rule ended it.
+func (r LoopStopReason) Succeeded() bool {
+	switch r {
+	case LoopStopReasonQuant, LoopStopReasonQual, LoopStopReasonRule, LoopStopReasonCondition:
+		return true
+	default:
+		return false
+	}
+}
+
+// ParseLoopStopReason validates a stop reason name.
+func ParseLoopStopReason(value string) (LoopStopReason, error) {
+	switch reason := LoopStopReason(strings.ToLower(strings.TrimSpace(value))); reason {
+	case LoopStopReasonQuant, LoopStopReasonQual, LoopStopReasonRule, LoopStopReasonCondition,
+		LoopStopReasonMaxIterations, LoopStopReasonMaxRuntime, LoopStopReasonBudget, LoopStopReasonError:
+		return reason, nil
+	default:
+		return "", fmt.Errorf("invalid stop reason %q (use quant, qual, rule, condition, max_iterations, max_runtime, budget, or error)", value)
+	}
+}
+
+// LoopChainConfig hands work to downstream loops once a loop stops on its own
+// (operator stops and kills never chain).
+//
+// Stored inside Loop.Metadata as JSON under the "chain" key.
+type LoopChainConfig struct {
+	// OnStop targets run whenever the loop stops on its own.
+	OnStop []LoopChainTarget `json:"on_stop,omitempty"`
+	// OnSuccess targets run only when a stop rule ended the loop.
+	OnSuccess []LoopChainTarget `json:"on_success,omitempty"`
+}
+
+// Empty reports whether the config has no targets.
+func (c LoopChainConfig) Empty() bool {
+	return len(c.OnStop) == 0 && len(c.OnSuccess) == 0
+}
+
+// Targets returns the targets to hand off to for a stop reason.
+func (c LoopChainConfig) Targets(reason LoopStopReason) []LoopChainTarget {
+	targets := make([]LoopChainTarget, 0)
+	for _, target := range c.OnStop {
+		if target.Matches(reason) {
+			targets = append(targets, target)
+		}
+	}
+	if reason.Succeeded() {
+		for _, target := range c.OnSuccess {
+			if target.Matches(reason) {
+				targets = append(targets, target)
+			}
+		}
+	}
+	return targets
+}
+
+// LoopChainTarget is a downstream loop. A stopped target is started; a running
+// one picks up the handoff message on its next iteration.
+type LoopChainTarget struct {
+	// Loop is the target loop name or ID.
+	Loop string `json:"loop"`
+	// Reasons limits the target to these stop reasons (empty = any).
+	Reasons []LoopStopReason `json:"reasons,omitempty"`
+	// Message is added to the handoff message.
+	Message string `json:"message,omitempty"`
+}
+
+// Matches reports whether the target applies to a stop reason.
+func (t LoopChainTarget) Matches(reason LoopStopReason) bool {
+	if len(t.Reasons) == 0 {
+		return true
+	}
+	for _, r := range t.Reasons {
+		if r == reason {
+			return true
+		}
+	}
+	return false
+}
+
+// Validate checks the target loop and reasons.
+func (t *LoopChainTarget) Validate() error {
+	if strings.TrimSpace(t.Loop) == "" {
+		return errors.New("chain target loop is required")
+	}
+	for _, reason := range t.Reasons {
+		if _, err := ParseLoopStopReason(string(reason)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
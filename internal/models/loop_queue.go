@@ -18,6 +18,8 @@ const (
 	LoopQueueItemStopGraceful       LoopQueueItemType = "stop_graceful"
 	LoopQueueItemKillNow            LoopQueueItemType = "kill_now"
 	LoopQueueItemSteerMessage       LoopQueueItemType = "steer_message"
+	LoopQueueItemConfigUpdate       LoopQueueItemType = "config_update"
+	LoopQueueItemTrigger            LoopQueueItemType = "trigger"
 )
 
 // LoopQueueItemStatus represents the status of a loop queue item.
@@ -78,6 +80,29 @@ type SteerPayload struct {
 	Message string `json:"message"`
 }
 
+// TriggerPayload wakes a sleeping loop to run immediately.
+type TriggerPayload struct {
+	Reason string `json:"reason,omitempty"`
+}
+
+// LoopConfigPayload replaces a loop's settings before its next iteration.
+type LoopConfigPayload struct {
+	BasePromptPath    string            `json:"base_prompt_path,omitempty"`
+	BasePromptMsg     string            `json:"base_prompt_msg,omitempty"`
+	IntervalSeconds   int               `json:"interval_seconds"`
+	MaxIterations     int               `json:"max_iterations"`
+	MaxRuntimeSeconds int               `json:"max_runtime_seconds"`
+	MaxTokens         int64             `json:"max_tokens,omitempty"`
+	MaxCostUSD        float64           `json:"max_cost_usd,omitempty"`
+	PoolID            string            `json:"pool_id,omitempty"`
+	ProfileID         string            `json:"profile_id,omitempty"`
+	Tags              []string          `json:"tags,omitempty"`
+	RestartPolicy     LoopRestartPolicy `json:"restart_policy,omitempty"`
+	Schedule          string            `json:"schedule,omitempty"`
+	StopConfig        *LoopStopConfig   `json:"stop_config,omitempty"`
+	ChainConfig       *LoopChainConfig  `json:"chain_config,omitempty"`
+}
+
 // Validate checks if the queue item is valid.
 func (q *LoopQueueItem) Validate() error {
 	validation := &ValidationErrors{}
@@ -137,6 +162,26 @@ func (q *LoopQueueItem) Validate() error {
 		if strings.TrimSpace(payload.Message) == "" {
 			return errors.New("steer_message payload message is required")
 		}
+	case LoopQueueItemConfigUpdate:
+		var payload LoopConfigPayload
+		if err := json.Unmarshal(q.Payload, &payload); err != nil {
+			return fmt.Errorf("invalid config_update payload: %w", err)
+		}
+		if payload.IntervalSeconds < 0 || payload.MaxIterations < 0 || payload.MaxRuntimeSeconds < 0 ||
+			payload.MaxTokens < 0 || payload.MaxCostUSD < 0 {
+			return errors.New("config_update payload interval and limits must be >= 0")
+		}
+		if payload.PoolID != "" && payload.ProfileID != "" {
+			return errors.New("config_update payload cannot set both pool_id and profile_id")
+		}
+		if _, err := ParseLoopSchedule(payload.Schedule); err != nil {
+			return fmt.Errorf("config_update payload schedule: %w", err)
+		}
+	case LoopQueueItemTrigger:
+		var payload TriggerPayload
+		if err := json.Unmarshal(q.Payload, &payload); err != nil {
+			return fmt.Errorf("invalid trigger payload: %w", err)
+		}
 	default:
 		return fmt.Errorf("unknown loop queue item type %q", q.Type)
 	}
@@ -6,10 +6,11 @@ import "time"
 type LoopRunStatus string
 
 const (
-	LoopRunStatusRunning LoopRunStatus = "running"
-	LoopRunStatusSuccess LoopRunStatus = "success"
-	LoopRunStatusError   LoopRunStatus = "error"
-	LoopRunStatusKilled  LoopRunStatus = "killed"
+	LoopRunStatusRunning     LoopRunStatus = "running"
+	LoopRunStatusSuccess     LoopRunStatus = "success"
+	LoopRunStatusError       LoopRunStatus = "error"
+	LoopRunStatusKilled      LoopRunStatus = "killed"
+	LoopRunStatusRateLimited LoopRunStatus = "rate_limited"
 )
 
 // LoopRun captures a single loop iteration.
@@ -25,5 +26,87 @@ type LoopRun struct {
 	FinishedAt     *time.Time     `json:"finished_at,omitempty"`
 	ExitCode       *int           `json:"exit_code,omitempty"`
 	OutputTail     string         `json:"output_tail,omitempty"`
+	OutputPath     string         `json:"output_path,omitempty"`
+	PromptSentPath string         `json:"prompt_sent_path,omitempty"`
+	EventsPath     string         `json:"events_path,omitempty"`
+	CheckpointRef  string         `json:"checkpoint_ref,omitempty"`
+	CommitBefore   string         `json:"commit_before,omitempty"`
+	CommitAfter    string         `json:"commit_after,omitempty"`
+	Usage          LoopRunUsage   `json:"usage"`
 	Metadata       map[string]any `json:"metadata,omitempty"`
 }
+
+// LoopRunEventKind identifies a structured event decoded from harness output.
+type LoopRunEventKind string
+
+const (
+	LoopRunEventMessage  LoopRunEventKind = "message"
+	LoopRunEventToolCall LoopRunEventKind = "tool_call"
+	LoopRunEventFileEdit LoopRunEventKind = "file_edit"
+	LoopRunEventResult   LoopRunEventKind = "result"
+)
+
+// LoopRunEvent is one step of what the agent did during a run, decoded from
+// a profile's stream-json output.
+type LoopRunEvent struct {
+	Kind LoopRunEventKind `json:"kind"`
+	// Tool is the tool name for tool calls and file edits.
+	Tool string `json:"tool,omitempty"`
+	// Path is the edited file for file edits.
+	Path string `json:"path,omitempty"`
+	// Text is the message text, a summary of the tool input, or the final result.
+	Text string `json:"text,omitempty"`
+	// IsError marks failed tool calls and failed results.
+	IsError bool `json:"is_error,omitempty"`
+}
+
+// LoopRunUsage is the token and cost usage a harness reported for a run.
+type LoopRunUsage struct {
+	InputTokens      int64   `json:"input_tokens"`
+	OutputTokens     int64   `json:"output_tokens"`
+	CacheReadTokens  int64   `json:"cache_read_tokens"`
+	CacheWriteTokens int64   `json:"cache_write_tokens"`
+	TotalTokens      int64   `json:"total_tokens"`
+	CostUSD          float64 `json:"cost_usd"`
+}
+
+// IsZero reports whether no usage was recorded.
+func (u LoopRunUsage) IsZero() bool {
+	return u == LoopRunUsage{}
+}
+
+// Add accumulates other into u.
+func (u *LoopRunUsage) Add(other LoopRunUsage) {
+	u.InputTokens += other.InputTokens
+	u.OutputTokens += other.OutputTokens
+	u.CacheReadTokens += other.CacheReadTokens
+	u.CacheWriteTokens += other.CacheWriteTokens
+	u.TotalTokens += other.TotalTokens
+	u.CostUSD += other.CostUSD
+}
+
+// LoopUsageGroup selects how loop run usage is aggregated.
+type LoopUsageGroup string
+
+const (
+	LoopUsageByLoop    LoopUsageGroup = "loop"
+	LoopUsageByProfile LoopUsageGroup = "profile"
+	LoopUsageByPool    LoopUsageGroup = "pool"
+	LoopUsageByDay     LoopUsageGroup = "day"
+)
+
+// LoopUsageQuery filters a loop run usage report.
+type LoopUsageQuery struct {
+	GroupBy LoopUsageGroup
+	LoopID  string
+	Since   *time.Time
+}
+
+// LoopUsageSummary aggregates the usage of a group of loop runs.
+type LoopUsageSummary struct {
+	// Key is the loop, profile or pool name, or the day (YYYY-MM-DD, UTC).
+	Key             string       `json:"key"`
+	Runs            int64        `json:"runs"`
+	DurationSeconds int64        `json:"duration_seconds"`
+	Usage           LoopRunUsage `json:"usage"`
+}
@@ -0,0 +1,376 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// LoopScheduleKind identifies how a scheduled loop decides when to run.
+type LoopScheduleKind string
+
+const (
+	// LoopScheduleCron runs the loop at the fire times of a cron expression.
+	LoopScheduleCron LoopScheduleKind = "cron"
+	// LoopScheduleWindow runs the loop back-to-back, but only inside time windows.
+	LoopScheduleWindow LoopScheduleKind = "window"
+	// LoopScheduleTrigger runs the loop only when it is triggered.
+	LoopScheduleTrigger LoopScheduleKind = "trigger"
+)
+
+// LoopSchedule is a parsed loop schedule.
+//
+// Supported forms:
+//
+//	0 2 * * *                              cron (minute hour day-of-month month day-of-week)
+//	@hourly, @daily, @weekly, @monthly     cron shorthands (also @midnight, @yearly, @annually)
+//	window:Mon-Fri 09:00-17:00;Sat 10:00-12:00
+//	window:22:00-06:00                     windows may cross midnight
+//	trigger                                run only on `forge loop trigger`
+//
+// Any form may be prefixed with "TZ=<zone> " (or "CRON_TZ=<zone> "); times are
+// otherwise interpreted in the local time zone.
+type LoopSchedule struct {
+	Kind     LoopScheduleKind
+	Location *time.Location
+
+	cron    *cronSchedule
+	windows []scheduleWindow
+}
+
+var cronShorthands = map[string]string{
+	"@hourly":   "0 * * * *",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@weekly":   "0 0 * * 0",
+	"@monthly":  "0 0 1 * *",
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+}
+
+// ParseLoopSchedule parses a loop schedule. An empty spec returns nil, meaning
+// the loop runs every IntervalSeconds.
+func ParseLoopSchedule(spec string) (*LoopSchedule, error) {
+	spec = strings.TrimSpace(spec)
+	if spec == "" {
+		return nil, nil
+	}
+
+	schedule := &LoopSchedule{Location: time.Local}
+	for _, prefix := range []string{"TZ=", "CRON_TZ="} {
+		if !strings.HasPrefix(spec, prefix) {
+			continue
+		}
+		zone, rest, _ := strings.Cut(strings.TrimPrefix(spec, prefix), " ")
+		loc, err := time.LoadLocation(zone)
+		if err != nil {
+			return nil, fmt.Errorf("invalid schedule time zone %q: %w", zone, err)
+		}
+		schedule.Location = loc
+		spec = strings.TrimSpace(rest)
+		break
+	}
+
+	switch {
+	case spec == "":
+		return nil, errors.New("schedule is empty after time zone")
+	case strings.EqualFold(spec, string(LoopScheduleTrigger)):
+		schedule.Kind = LoopScheduleTrigger
+	case strings.HasPrefix(strings.ToLower(spec), "window:"):
+		windows, err := parseScheduleWindows(spec[len("window:"):])
+		if err != nil {
+			return nil, err
+		}
+		schedule.Kind = LoopScheduleWindow
+		schedule.windows = windows
+	default:
+		if expanded, ok := cronShorthands[strings.ToLower(spec)]; ok {
+			spec = expanded
+		}
+		cron, err := parseCron(spec)
+		if err != nil {
+			return nil, err
+		}
+		schedule.Kind = LoopScheduleCron
+		schedule.cron = cron
+	}
+	return schedule, nil
+}
+
+// Next returns the earliest time at or after t when the loop may run. Cron
+// schedules fire on whole minutes strictly after t; window schedules return t
+// itself when t falls inside a window. ok is false for trigger schedules and
+// for cron expressions that never fire.
+func (s *LoopSchedule) Next(t time.Time) (time.Time, bool) {
+	if s == nil {
+		return t, true
+	}
+	loc := s.Location
+	if loc == nil {
+		loc = time.Local
+	}
+	switch s.Kind {
+	case LoopScheduleCron:
+		return s.cron.next(t.In(loc))
+	case LoopScheduleWindow:
+		return nextInWindows(s.windows, t.In(loc))
+	default:
+		return time.Time{}, false
+	}
+}
+
+// cronSchedule holds the allowed values of each cron field as bit sets.
+type cronSchedule struct {
+	minute, hour, dom, month, dow uint64
+	domAny, dowAny                bool
+}
+
+type cronField struct {
+	name     string
+	min, max int
+	names    map[string]int
+}
+
+var (
+	cronMonthNames = map[string]int{
+		"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6,
+		"jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12,
+	}
+	cronDayNames = map[string]int{
+		"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6,
+	}
+
+	cronMinuteField = cronField{name: "minute", min: 0, max: 59}
+	cronHourField   = cronField{name: "hour", min: 0, max: 23}
+	cronDomField    = cronField{name: "day-of-month", min: 1, max: 31}
+	cronMonthField  = cronField{name: "month", min: 1, max: 12, names: cronMonthNames}
+	// Day-of-week accepts 7 as an alias for Sunday.
+	cronDowField = cronField{name: "day-of-week", min: 0, max: 7, names: cronDayNames}
+)
+
+func parseCron(spec string) (*cronSchedule, error) {
+	fields := strings.Fields(spec)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("invalid cron expression %q: expected 5 fields (minute hour day-of-month month day-of-week)", spec)
+	}
+
+	cron := &cronSchedule{}
+	var err error
+	if cron.minute, err = cronMinuteField.parse(fields[0]); err != nil {
+		return nil, err
+	}
+	if cron.hour, err = cronHourField.parse(fields[1]); err != nil {
+		return nil, err
+	}
+	if cron.dom, err = cronDomField.parse(fields[2]); err != nil {
+		return nil, err
+	}
+	if cron.month, err = cronMonthField.parse(fields[3]); err != nil {
+		return nil, err
+	}
+	if cron.dow, err = cronDowField.parse(fields[4]); err != nil {
+		return nil, err
+	}
+	if cron.dow&(1<<7) != 0 {
+		cron.dow |= 1
+	}
+	cron.domAny = fields[2] == "*" || fields[2] == "?"
+	cron.dowAny = fields[4] == "*" || fields[4] == "?"
+	return cron, nil
+}
+
+// parse parses a comma-separated list of values, ranges and steps.
+func (f cronField) parse(value string) (uint64, error) {
+	var bits uint64
+	for _, part := range strings.Split(value, ",") {
+		rangePart, stepPart, hasStep := strings.Cut(part, "/")
+		step := 1
+		if hasStep {
+			n, err := strconv.Atoi(stepPart)
+			if err != nil || n <= 0 {
+				return 0, fmt.Errorf("invalid %s step %q", f.name, part)
+			}
+			step = n
+		}
+
+		var start, end int
+		switch {
+		case rangePart == "*" || rangePart == "?":
+			start, end = f.min, f.max
+		case strings.Contains(rangePart, "-"):
+			lo, hi, _ := strings.Cut(rangePart, "-")
+			var err error
+			if start, err = f.value(lo); err != nil {
+				return 0, err
+			}
+			if end, err = f.value(hi); err != nil {
+				return 0, err
+			}
+			if start > end {
+				return 0, fmt.Errorf("invalid %s range %q", f.name, part)
+			}
+		default:
+			var err error
+			if start, err = f.value(rangePart); err != nil {
+				return 0, err
+			}
+			end = start
+			if hasStep {
+				end = f.max
+			}
+		}
+
+		for v := start; v <= end; v += step {
+			bits |= 1 << uint(v)
+		}
+	}
+	return bits, nil
+}
+
+func (f cronField) value(raw string) (int, error) {
+	if n, ok := f.names[strings.ToLower(raw)]; ok {
+		return n, nil
+	}
+	n, err := strconv.Atoi(raw)
+	if err != nil || n < f.min || n > f.max {
+		return 0, fmt.Errorf("invalid %s value %q (expected %d-%d)", f.name, raw, f.min, f.max)
+	}
+	return n, nil
+}
+
+// cronSearchYears bounds the search for expressions that rarely or never fire
+// (e.g. 30 February).
+const cronSearchYears = 5
+
+func (c *cronSchedule) next(after time.Time) (time.Time, bool) {
+	loc := after.Location()
+	t := after.Truncate(time.Minute).Add(time.Minute)
+	limit := after.Year() + cronSearchYears
+
+	for t.Year() <= limit {
+		var advanced time.Time
+		switch {
+		case c.month&(1<<uint(t.Month())) == 0:
+			advanced = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, loc)
+		case !c.dayMatches(t):
+			advanced = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, loc)
+		case c.hour&(1<<uint(t.Hour())) == 0:
+			advanced = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, loc)
+		case c.minute&(1<<uint(t.Minute())) == 0:
+			advanced = t.Add(time.Minute)
+		default:
+			return t, true
+		}
+		// Daylight saving transitions can map a wall-clock time backwards;
+		// always make progress.
+		if !advanced.After(t) {
+			advanced = t.Add(time.Minute)
+		}
+		t = advanced
+	}
+	return time.Time{}, false
+}
+
+// dayMatches applies cron's day rule: when both day-of-month and day-of-week
+// are restricted, a day matching either field fires.
+func (c *cronSchedule) dayMatches(t time.Time) bool {
+	domMatch := c.dom&(1<<uint(t.Day())) != 0
+	dowMatch := c.dow&(1<<uint(t.Weekday())) != 0
+	if c.domAny || c.dowAny {
+		return domMatch && dowMatch
+	}
+	return domMatch || dowMatch
+}
+
+// scheduleWindow allows runs on the given weekdays from start for duration.
+type scheduleWindow struct {
+	days     uint64
+	start    time.Duration
+	duration time.Duration
+}
+
+func parseScheduleWindows(spec string) ([]scheduleWindow, error) {
+	windows := make([]scheduleWindow, 0)
+	for _, part := range strings.Split(spec, ";") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		window := scheduleWindow{days: 0x7f}
+		fields := strings.Fields(part)
+		switch len(fields) {
+		case 1:
+		case 2:
+			days, err := cronDowField.parse(fields[0])
+			if err != nil {
+				return nil, fmt.Errorf("invalid schedule window %q: %w", part, err)
+			}
+			if days&(1<<7) != 0 {
+				days |= 1
+			}
+			window.days = days & 0x7f
+		default:
+			return nil, fmt.Errorf("invalid schedule window %q (expected [days] HH:MM-HH:MM)", part)
+		}
+
+		from, to, ok := strings.Cut(fields[len(fields)-1], "-")
+		if !ok {
+			return nil, fmt.Errorf("invalid schedule window %q (expected [days] HH:MM-HH:MM)", part)
+		}
+		start, err := parseClock(from)
+		if err != nil {
+			return nil, fmt.Errorf("invalid schedule window %q: %w", part, err)
+		}
+		end, err := parseClock(to)
+		if err != nil {
+			return nil, fmt.Errorf("invalid schedule window %q: %w", part, err)
+		}
+		window.start = start
+		window.duration = end - start
+		if window.duration <= 0 {
+			window.duration += 24 * time.Hour
+		}
+		windows = append(windows, window)
+	}
+	if len(windows) == 0 {
+		return nil, errors.New("schedule window requires at least one HH:MM-HH:MM range")
+	}
+	return windows, nil
+}
+
+func parseClock(value string) (time.Duration, error) {
+	hour, minute, ok := strings.Cut(value, ":")
+	h, herr := strconv.Atoi(hour)
+	m, merr := strconv.Atoi(minute)
+	if !ok || herr != nil || merr != nil || h < 0 || h > 24 || m < 0 || m > 59 || (h == 24 && m != 0) {
+		return 0, fmt.Errorf("invalid time %q (expected HH:MM)", value)
+	}
+	return time.Duration(h)*time.Hour + time.Duration(m)*time.Minute, nil
+}
+
+// nextInWindows returns t when it falls inside a window, otherwise the start of
+// the next window.
+func nextInWindows(windows []scheduleWindow, t time.Time) (time.Time, bool) {
+	loc := t.Location()
+	var next time.Time
+	// Start a day back so windows that began yesterday and cross midnight count.
+	for offset := -1; offset <= 7; offset++ {
+		day := time.Date(t.Year(), t.Month(), t.Day()+offset, 0, 0, 0, 0, loc)
+		for _, window := range windows {
+			if window.days&(1<<uint(day.Weekday())) == 0 {
+				continue
+			}
+			start := time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, loc).Add(window.start)
+			end := start.Add(window.duration)
+			if !t.Before(start) && t.Before(end) {
+				return t, true
+			}
+			if start.After(t) && (next.IsZero() || start.Before(next)) {
+				next = start
+			}
+		}
+	}
+	return next, !next.IsZero()
+}
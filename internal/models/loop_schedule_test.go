@@ -0,0 +1,76 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLoopScheduleNext(t *testing.T) {
+	// Wednesday.
+	base := time.Date(2026, 10, 14, 10, 30, 15, 0, time.UTC)
+
+	tests := []struct {
+		spec string
+		from time.Time
+		want time.Time
+	}{
+		{"TZ=UTC 0 2 * * *", base, time.Date(2026, 10, 15, 2, 0, 0, 0, time.UTC)},
+		{"TZ=UTC */15 * * * *", base, time.Date(2026, 10, 14, 10, 45, 0, 0, time.UTC)},
+		{"TZ=UTC 30 10 * * *", base, time.Date(2026, 10, 15, 10, 30, 0, 0, time.UTC)},
+		{"TZ=UTC 0 9 * * mon-fri", time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC), time.Date(2026, 10, 19, 9, 0, 0, 0, time.UTC)},
+		{"TZ=UTC 0 0 1,15 * 0", base, time.Date(2026, 10, 15, 0, 0, 0, 0, time.UTC)},
+		{"TZ=UTC 0 0 29 2 *", base, time.Date(2028, 2, 29, 0, 0, 0, 0, time.UTC)},
+		{"TZ=UTC @monthly", base, time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC)},
+		{"TZ=UTC window:Mon-Fri 09:00-17:00", base, base},
+		{"TZ=UTC window:Mon-Fri 09:00-17:00", time.Date(2026, 10, 16, 18, 0, 0, 0, time.UTC), time.Date(2026, 10, 19, 9, 0, 0, 0, time.UTC)},
+		{"TZ=UTC window:22:00-06:00", time.Date(2026, 10, 15, 3, 0, 0, 0, time.UTC), time.Date(2026, 10, 15, 3, 0, 0, 0, time.UTC)},
+		{"TZ=UTC window:22:00-06:00", base, time.Date(2026, 10, 14, 22, 0, 0, 0, time.UTC)},
+		{"TZ=UTC window:Sat,Sun 10:00-12:00;Wed 11:00-12:00", base, time.Date(2026, 10, 14, 11, 0, 0, 0, time.UTC)},
+		{"TZ=Europe/Oslo 0 2 * * *", base, time.Date(2026, 10, 15, 0, 0, 0, 0, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		schedule, err := ParseLoopSchedule(tt.spec)
+		if err != nil {
+			t.Fatalf("parse %q: %v", tt.spec, err)
+		}
+		got, ok := schedule.Next(tt.from)
+		if !ok || !got.Equal(tt.want) {
+			t.Errorf("%q next after %s = %s (ok=%v), want %s", tt.spec, tt.from, got.UTC(), ok, tt.want)
+		}
+	}
+}
+
+func TestParseLoopScheduleKinds(t *testing.T) {
+	schedule, err := ParseLoopSchedule("")
+	if err != nil || schedule != nil {
+		t.Fatalf("expected empty schedule to be nil, got %+v, %v", schedule, err)
+	}
+
+	schedule, err = ParseLoopSchedule("trigger")
+	if err != nil || schedule.Kind != LoopScheduleTrigger {
+		t.Fatalf("expected trigger schedule, got %+v, %v", schedule, err)
+	}
+	if _, ok := schedule.Next(time.Now()); ok {
+		t.Fatalf("expected trigger schedule to have no next run")
+	}
+
+	if _, ok := mustParseSchedule(t, "0 0 31 2 *").Next(time.Now()); ok {
+		t.Fatalf("expected 31 February to never fire")
+	}
+
+	for _, spec := range []string{"* * * *", "61 * * * *", "5-1 * * * *", "*/0 * * * *", "window:9-17", "window:Mon-Fri 09:00", "TZ=Nowhere/Else @daily"} {
+		if _, err := ParseLoopSchedule(spec); err == nil {
+			t.Errorf("expected %q to be rejected", spec)
+		}
+	}
+}
+
+func mustParseSchedule(t *testing.T, spec string) *LoopSchedule {
+	t.Helper()
+	schedule, err := ParseLoopSchedule(spec)
+	if err != nil {
+		t.Fatalf("parse %q: %v", spec, err)
+	}
+	return schedule
+}
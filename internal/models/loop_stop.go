@@ -1,11 +1,152 @@
 package models
 
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
 // LoopStopConfig configures optional "smart stop" behavior for a loop.
 //
 // Stored inside Loop.Metadata as JSON under the "stop_config" key.
 type LoopStopConfig struct {
 	Quant *LoopQuantStopConfig `json:"quant,omitempty"`
 	Qual  *LoopQualStopConfig  `json:"qual,omitempty"`
+
+	// Rules are evaluated after each main iteration.
+	Rules []LoopStopRule `json:"rules,omitempty"`
+
+	// Combine controls how Rules trigger: "any" (default) applies each matching
+	// rule's action, "all" applies every action once all rules match together.
+	Combine string `json:"combine,omitempty"`
+}
+
+// Empty reports whether the config holds no stop rules.
+func (c LoopStopConfig) Empty() bool {
+	return c.Quant == nil && c.Qual == nil && len(c.Rules) == 0
+}
+
+// RuleName returns the name of rule i, falling back to "<kind>-<n>" for
+// unnamed rules.
+func (c LoopStopConfig) RuleName(i int) string {
+	if name := strings.TrimSpace(c.Rules[i].Name); name != "" {
+		return name
+	}
+	return fmt.Sprintf("%s-%d", c.Rules[i].Kind, i+1)
+}
+
+// Stop rule combinators.
+const (
+	LoopStopCombineAny = "any"
+	LoopStopCombineAll = "all"
+)
+
+// LoopStopRuleKind identifies a built-in stop rule.
+type LoopStopRuleKind string
+
+const (
+	// LoopStopRuleCommand runs a command and matches its exit code/stdout/stderr.
+	LoopStopRuleCommand LoopStopRuleKind = "command"
+	// LoopStopRuleNoChanges matches after Count iterations in a row left the git working tree unchanged.
+	LoopStopRuleNoChanges LoopStopRuleKind = "no_changes"
+	// LoopStopRuleSameOutput matches after Count iterations in a row produced identical output.
+	LoopStopRuleSameOutput LoopStopRuleKind = "same_output"
+	// LoopStopRuleFailures matches after Count failed iterations in a row.
+	LoopStopRuleFailures LoopStopRuleKind = "failures"
+	// LoopStopRuleQueueEmpty matches when the beads or fmail work queue is empty.
+	LoopStopRuleQueueEmpty LoopStopRuleKind = "queue_empty"
+	// LoopStopRuleFileExists matches when Path exists.
+	LoopStopRuleFileExists LoopStopRuleKind = "file_exists"
+)
+
+// LoopStopAction is what a stop rule does when it matches.
+type LoopStopAction string
+
+const (
+	LoopStopActionStop         LoopStopAction = "stop"
+	LoopStopActionPause        LoopStopAction = "pause"
+	LoopStopActionSwitchPrompt LoopStopAction = "switch_prompt"
+	LoopStopActionNotify       LoopStopAction = "notify"
+)
+
+// Work queues checked by queue_empty rules.
+const (
+	LoopStopQueueBeads = "beads"
+	LoopStopQueueFmail = "fmail"
+)
+
+// LoopStopRule is one entry of LoopStopConfig.Rules.
+type LoopStopRule struct {
+	Name string           `json:"name,omitempty"`
+	Kind LoopStopRuleKind `json:"kind"`
+
+	// Count is how many iterations in a row the condition must hold (default 1).
+	Count int `json:"count,omitempty"`
+
+	// Path is the file checked by file_exists rules, relative to the repo root.
+	Path string `json:"path,omitempty"`
+
+	// Queue ("beads" or "fmail") and Topic select the queue of queue_empty
+	// rules. A beads queue is empty when no issue is open; an fmail topic (or
+	// "@agent" inbox) is empty when nothing was posted to it during the run.
+	Queue string `json:"queue,omitempty"`
+	Topic string `json:"topic,omitempty"`
+
+	// Command configures command rules; EveryN, When and Decision are ignored.
+	Command *LoopQuantStopConfig `json:"command,omitempty"`
+
+	// Action defaults to "stop". Pause sleeps for PauseSeconds, switch_prompt
+	// replaces the loop's base prompt with Prompt and notify emits a
+	// loop.stop.matched event carrying Message.
+	Action       LoopStopAction             `json:"action,omitempty"`
+	PauseSeconds int                        `json:"pause_seconds,omitempty"`
+	Prompt       *NextPromptOverridePayload `json:"prompt,omitempty"`
+	Message      string                     `json:"message,omitempty"`
+}
+
+// Validate checks that the rule has the settings its kind and action need.
+func (r *LoopStopRule) Validate() error {
+	if r.Count < 0 {
+		return errors.New("count must be >= 0")
+	}
+	switch r.Kind {
+	case LoopStopRuleCommand:
+		if r.Command == nil || strings.TrimSpace(r.Command.Cmd) == "" {
+			return errors.New("command rule requires cmd")
+		}
+	case LoopStopRuleNoChanges, LoopStopRuleSameOutput, LoopStopRuleFailures:
+	case LoopStopRuleQueueEmpty:
+		switch r.Queue {
+		case LoopStopQueueBeads:
+		case LoopStopQueueFmail:
+			if strings.TrimSpace(r.Topic) == "" {
+				return errors.New("fmail queue_empty rule requires topic")
+			}
+		default:
+			return fmt.Errorf("invalid queue %q (use beads or fmail)", r.Queue)
+		}
+	case LoopStopRuleFileExists:
+		if strings.TrimSpace(r.Path) == "" {
+			return errors.New("file_exists rule requires path")
+		}
+	default:
+		return fmt.Errorf("invalid stop rule kind %q", r.Kind)
+	}
+
+	switch r.Action {
+	case "", LoopStopActionStop, LoopStopActionNotify:
+	case LoopStopActionPause:
+		if r.PauseSeconds <= 0 {
+			return errors.New("pause action requires a pause duration")
+		}
+	case LoopStopActionSwitchPrompt:
+		if r.Prompt == nil || strings.TrimSpace(r.Prompt.Prompt) == "" {
+			return errors.New("switch_prompt action requires a prompt")
+		}
+	default:
+		return fmt.Errorf("invalid stop rule action %q", r.Action)
+	}
+	return nil
 }
 
 // LoopQuantStopConfig runs a shell command and matches its exit code/stdout/stderr.
@@ -61,4 +202,11 @@ type LoopStopState struct {
 
 	// QualLastMainCount is the main iteration count we last ran a qualitative check for.
 	QualLastMainCount int `json:"qual_last_main_count,omitempty"`
+
+	// RuleStreaks counts, per rule name, the iterations in a row a rule's
+	// condition held. A rule matches once its streak reaches its Count.
+	RuleStreaks map[string]int `json:"rule_streaks,omitempty"`
+
+	// LastOutputHash is the hash of the previous main iteration's output.
+	LastOutputHash string `json:"last_output_hash,omitempty"`
 }
@@ -0,0 +1,23 @@
+package models
+
+// LoopIsolation selects how a loop's working tree is separated from other loops.
+type LoopIsolation string
+
+const (
+	// LoopIsolationNone runs the loop directly in the repo checkout (default).
+	LoopIsolationNone LoopIsolation = "none"
+	// LoopIsolationWorktree runs the loop in its own git worktree and branch.
+	LoopIsolationWorktree LoopIsolation = "worktree"
+)
+
+// LoopWorktree describes the git worktree an isolated loop runs in.
+//
+// Stored inside Loop.Metadata as JSON under the "worktree" key. Loop.RepoPath
+// points at Path; RepoPath here is the main checkout the worktree belongs to.
+type LoopWorktree struct {
+	Path       string `json:"path"`
+	Branch     string `json:"branch"`
+	RepoPath   string `json:"repo_path"`
+	BaseBranch string `json:"base_branch,omitempty"`
+	BaseCommit string `json:"base_commit,omitempty"`
+}
@@ -6,8 +6,9 @@ import "time"
 type PoolStrategy string
 
 const (
-	PoolStrategyRoundRobin PoolStrategy = "round_robin"
-	PoolStrategyLRU        PoolStrategy = "lru"
+	PoolStrategyRoundRobin  PoolStrategy = "round_robin"
+	PoolStrategyLRU         PoolStrategy = "lru"
+	PoolStrategyLeastLoaded PoolStrategy = "least_loaded"
 )
 
 // Pool represents a list of profiles with selection strategy.
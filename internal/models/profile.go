@@ -25,6 +25,27 @@ const (
 	PromptModePath  PromptMode = "path"
 )
 
+// OutputFormat selects how a profile's harness output is interpreted.
+type OutputFormat string
+
+const (
+	// OutputFormatText treats harness output as opaque text.
+	OutputFormatText OutputFormat = "text"
+	// OutputFormatStreamJSON decodes the harness's streaming JSON events
+	// (claude --output-format stream-json, codex exec --json, opencode run --format json).
+	OutputFormatStreamJSON OutputFormat = "stream-json"
+)
+
+// SupportsStreamJSON reports whether stream-json output can be decoded for a harness.
+func SupportsStreamJSON(harness Harness) bool {
+	switch harness {
+	case HarnessClaude, HarnessCodex, HarnessOpenCode:
+		return true
+	default:
+		return false
+	}
+}
+
 // Profile represents a harness+auth combination.
 type Profile struct {
 	ID              string            `json:"id"`
@@ -33,6 +54,7 @@ type Profile struct {
 	AuthKind        string            `json:"auth_kind,omitempty"`
 	AuthHome        string            `json:"auth_home,omitempty"`
 	PromptMode      PromptMode        `json:"prompt_mode"`
+	OutputFormat    OutputFormat      `json:"output_format,omitempty"`
 	CommandTemplate string            `json:"command_template"`
 	Model           string            `json:"model,omitempty"`
 	ExtraArgs       []string          `json:"extra_args,omitempty"`
@@ -66,6 +88,16 @@ func (p *Profile) Validate() error {
 		return ErrInvalidProfileHarness
 	}
 
+	switch p.OutputFormat {
+	case "", OutputFormatText:
+	case OutputFormatStreamJSON:
+		if !SupportsStreamJSON(p.Harness) {
+			return errors.New("output_format stream-json is only supported for claude, codex, and opencode")
+		}
+	default:
+		return errors.New("invalid output_format")
+	}
+
 	switch p.PromptMode {
 	case "", PromptModeEnv, PromptModeStdin, PromptModePath:
 		return nil
@@ -0,0 +1,134 @@
+package models
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+// WorkflowRunStatus represents the lifecycle state of a workflow run.
+type WorkflowRunStatus string
+
+const (
+	WorkflowRunStatusPending  WorkflowRunStatus = "pending"
+	WorkflowRunStatusRunning  WorkflowRunStatus = "running"
+	WorkflowRunStatusSuccess  WorkflowRunStatus = "success"
+	WorkflowRunStatusFailed   WorkflowRunStatus = "failed"
+	WorkflowRunStatusCanceled WorkflowRunStatus = "canceled"
+)
+
+// WorkflowStepStatus represents the lifecycle state of a single workflow step.
+type WorkflowStepStatus string
+
+const (
+	WorkflowStepStatusPending  WorkflowStepStatus = "pending"
+	WorkflowStepStatusRunning  WorkflowStepStatus = "running"
+	WorkflowStepStatusWaiting  WorkflowStepStatus = "waiting"
+	WorkflowStepStatusSuccess  WorkflowStepStatus = "success"
+	WorkflowStepStatusFailed   WorkflowStepStatus = "failed"
+	WorkflowStepStatusSkipped  WorkflowStepStatus = "skipped"
+	WorkflowStepStatusCanceled WorkflowStepStatus = "canceled"
+)
+
+// WorkflowRun captures a single execution of a workflow definition.
+type WorkflowRun struct {
+	ID             string            `json:"id"`
+	WorkflowName   string            `json:"workflow_name"`
+	WorkflowSource string            `json:"workflow_source,omitempty"`
+	RepoPath       string            `json:"repo_path,omitempty"`
+	Status         WorkflowRunStatus `json:"status"`
+	ParentRunID    string            `json:"parent_run_id,omitempty"`
+	ParentStepID   string            `json:"parent_step_id,omitempty"`
+	Inputs         map[string]any    `json:"inputs,omitempty"`
+	Outputs        map[string]any    `json:"outputs,omitempty"`
+	Error          string            `json:"error,omitempty"`
+	Metadata       map[string]any    `json:"metadata,omitempty"`
+	StartedAt      *time.Time        `json:"started_at,omitempty"`
+	FinishedAt     *time.Time        `json:"finished_at,omitempty"`
+	CreatedAt      time.Time         `json:"created_at"`
+	UpdatedAt      time.Time         `json:"updated_at"`
+}
+
+// WorkflowStepRun captures the state of a single step inside a workflow run.
+type WorkflowStepRun struct {
+	ID         string             `json:"id"`
+	RunID      string             `json:"run_id"`
+	StepID     string             `json:"step_id"`
+	StepType   string             `json:"step_type"`
+	Status     WorkflowStepStatus `json:"status"`
+	Attempt    int                `json:"attempt"`
+	LoopID     string             `json:"loop_id,omitempty"`
+	ChildRunID string             `json:"child_run_id,omitempty"`
+	LogPath    string             `json:"log_path,omitempty"`
+	ExitCode   *int               `json:"exit_code,omitempty"`
+	Outputs    map[string]any     `json:"outputs,omitempty"`
+	Error      string             `json:"error,omitempty"`
+	StartedAt  *time.Time         `json:"started_at,omitempty"`
+	FinishedAt *time.Time         `json:"finished_at,omitempty"`
+	CreatedAt  time.Time          `json:"created_at"`
+	UpdatedAt  time.Time          `json:"updated_at"`
+}
+
+// Validate checks if the workflow run is valid.
+func (r *WorkflowRun) Validate() error {
+	validation := &ValidationErrors{}
+	if strings.TrimSpace(r.WorkflowName) == "" {
+		validation.AddMessage("workflow_name", "workflow_name is required")
+	}
+	if validation.Err() != nil {
+		return validation.Err()
+	}
+
+	switch r.Status {
+	case "", WorkflowRunStatusPending, WorkflowRunStatusRunning, WorkflowRunStatusSuccess,
+		WorkflowRunStatusFailed, WorkflowRunStatusCanceled:
+		return nil
+	default:
+		return errors.New("invalid workflow run status")
+	}
+}
+
+// IsTerminal reports whether the run has finished.
+func (s WorkflowRunStatus) IsTerminal() bool {
+	switch s {
+	case WorkflowRunStatusSuccess, WorkflowRunStatusFailed, WorkflowRunStatusCanceled:
+		return true
+	default:
+		return false
+	}
+}
+
+// Validate checks if the workflow step run is valid.
+func (s *WorkflowStepRun) Validate() error {
+	validation := &ValidationErrors{}
+	if strings.TrimSpace(s.RunID) == "" {
+		validation.AddMessage("run_id", "run_id is required")
+	}
+	if strings.TrimSpace(s.StepID) == "" {
+		validation.AddMessage("step_id", "step_id is required")
+	}
+	if s.Attempt < 0 {
+		validation.AddMessage("attempt", "attempt must be >= 0")
+	}
+	if validation.Err() != nil {
+		return validation.Err()
+	}
+
+	switch s.Status {
+	case "", WorkflowStepStatusPending, WorkflowStepStatusRunning, WorkflowStepStatusWaiting,
+		WorkflowStepStatusSuccess, WorkflowStepStatusFailed, WorkflowStepStatusSkipped, WorkflowStepStatusCanceled:
+		return nil
+	default:
+		return errors.New("invalid workflow step status")
+	}
+}
+
+// IsTerminal reports whether the step has finished (successfully or not).
+func (s WorkflowStepStatus) IsTerminal() bool {
+	switch s {
+	case WorkflowStepStatusSuccess, WorkflowStepStatusFailed, WorkflowStepStatusSkipped, WorkflowStepStatusCanceled:
+		return true
+	default:
+		return false
+	}
+}
@@ -5,6 +5,8 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"os"
+	"path/filepath"
 	"sync"
 	"time"
 
@@ -14,6 +16,7 @@ import (
 	"github.com/tOgg1/forge/internal/models"
 	"github.com/tOgg1/forge/internal/ssh"
 	"github.com/tOgg1/forge/internal/tmux"
+	"github.com/tOgg1/forge/internal/vault"
 )
 
 // Common client errors
@@ -205,12 +208,12 @@ func (c *Client) connectDaemon(ctx context.Context, cfg *clientOpts) error {
 	if c.node.IsLocal {
 		// Direct connection for local node
 		target := fmt.Sprintf("127.0.0.1:%d", cfg.daemonPort)
-		daemonClient, err = forged.Dial(dialCtx, target, forged.WithLogger(c.logger))
+		daemonClient, err = forged.Dial(dialCtx, target, daemonClientOptions(c.node, c.logger)...)
 	} else {
 		// SSH tunnel for remote node
 		user, host, port := ParseSSHTarget(c.node.SSHTarget)
 		_ = user // SSH executor handles user
-		daemonClient, err = forged.DialSSH(dialCtx, host, port, cfg.daemonPort, forged.WithLogger(c.logger))
+		daemonClient, err = forged.DialSSH(dialCtx, host, port, cfg.daemonPort, daemonClientOptions(c.node, c.logger)...)
 	}
 
 	if err != nil {
@@ -232,6 +235,26 @@ func (c *Client) connectDaemon(ctx context.Context, cfg *clientOpts) error {
 	return nil
 }
 
+// daemonClientOptions returns the options for dialing a node's forged daemon,
+// including the token and TLS certificates stored by "forge node add".
+func daemonClientOptions(node *models.Node, logger zerolog.Logger) []forged.ClientOption {
+	opts := []forged.ClientOption{forged.WithLogger(logger)}
+	vaultPath := vault.DefaultVaultPath()
+	if token, err := vault.NodeToken(vaultPath, node.Name); err == nil {
+		opts = append(opts, forged.WithToken(token))
+	}
+	certDir := vault.NodePath(vaultPath, node.Name)
+	if _, err := os.Stat(filepath.Join(certDir, forged.CACertFile)); err == nil {
+		tlsConfig, err := forged.LoadClientTLS(certDir)
+		if err != nil {
+			logger.Warn().Err(err).Str("node", node.Name).Msg("ignoring node TLS certificates")
+		} else {
+			opts = append(opts, forged.WithTLS(tlsConfig))
+		}
+	}
+	return opts
+}
+
 func (c *Client) connectSSH(ctx context.Context, node *models.Node, cfg *clientOpts) error {
 	var executor ssh.Executor
 	var err error
@@ -174,14 +174,14 @@ func (e *NodeExecutor) connectForged(ctx context.Context) error {
 	if e.node.IsLocal {
 		// Direct connection for local nodes
 		target := fmt.Sprintf("127.0.0.1:%d", e.forgedPort)
-		client, err = forged.Dial(ctx, target, forged.WithLogger(e.logger))
+		client, err = forged.Dial(ctx, target, daemonClientOptions(e.node, e.logger)...)
 	} else if e.sshExecutor != nil {
 		// Use SSH tunnel for remote nodes
 		forwarder, ok := e.sshExecutor.(ssh.PortForwarder)
 		if !ok {
 			return fmt.Errorf("SSH executor does not support port forwarding")
 		}
-		client, err = forged.DialSSHWithExecutor(ctx, forwarder, e.forgedPort, forged.WithLogger(e.logger))
+		client, err = forged.DialSSHWithExecutor(ctx, forwarder, e.forgedPort, daemonClientOptions(e.node, e.logger)...)
 	} else {
 		return fmt.Errorf("no SSH executor available for remote node")
 	}
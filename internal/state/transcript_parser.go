@@ -29,7 +29,7 @@ func ParseTranscript(text string) *models.StateInfo {
 			Confidence: models.StateConfidenceMedium,
 			Reason:     "rate limit indicator detected in transcript",
 		}
-		if retryAfter, ok := extractRetryAfter(lower); ok {
+		if retryAfter, ok := ExtractRetryAfter(lower); ok {
 			info.Evidence = []string{fmt.Sprintf("retry_after=%s", retryAfter)}
 		}
 		return info
@@ -46,9 +46,10 @@ func ParseTranscript(text string) *models.StateInfo {
 	return nil
 }
 
-var retryAfterPattern = regexp.MustCompile(`(?i)(retry after|try again in)\s+(\d+)\s*([a-z]+)?`)
+var retryAfterPattern = regexp.MustCompile(`(?i)(retry[- ]after:?|try again in)\s+(\d+)\s*([a-z]+)?`)
 
-func extractRetryAfter(text string) (time.Duration, bool) {
+// ExtractRetryAfter parses a "retry after N" or "try again in N minutes" hint.
+func ExtractRetryAfter(text string) (time.Duration, bool) {
 	match := retryAfterPattern.FindStringSubmatch(text)
 	if len(match) < 3 {
 		return 0, false
@@ -0,0 +1,55 @@
+package vault
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// HookPath returns the directory a hook's signing secret is stored in.
+func HookPath(vaultPath, hookID string) string {
+	return filepath.Join(vaultPath, "hooks", hookID)
+}
+
+// StoreHookSecret saves the secret webhook payloads of a hook are signed with.
+func StoreHookSecret(vaultPath, hookID, secret string) error {
+	if !validTokenName(hookID) {
+		return ErrInvalidTokenName
+	}
+	dir := HookPath(vaultPath, hookID)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return fmt.Errorf("failed to create hook directory: %w", err)
+	}
+	path := filepath.Join(dir, "secret")
+	if err := os.WriteFile(path, []byte(strings.TrimSpace(secret)+"\n"), 0600); err != nil {
+		return fmt.Errorf("failed to write hook secret: %w", err)
+	}
+	return nil
+}
+
+// HookSecret returns the stored signing secret for a hook, or ErrSecretNotFound.
+func HookSecret(vaultPath, hookID string) (string, error) {
+	if !validTokenName(hookID) {
+		return "", ErrInvalidTokenName
+	}
+	data, err := os.ReadFile(filepath.Join(HookPath(vaultPath, hookID), "secret"))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", ErrSecretNotFound
+		}
+		return "", fmt.Errorf("failed to read hook secret: %w", err)
+	}
+	return strings.TrimSpace(string(data)), nil
+}
+
+// DeleteHookSecret removes a hook's signing secret.
+func DeleteHookSecret(vaultPath, hookID string) error {
+	if !validTokenName(hookID) {
+		return ErrInvalidTokenName
+	}
+	if err := os.RemoveAll(HookPath(vaultPath, hookID)); err != nil {
+		return fmt.Errorf("failed to delete hook secret: %w", err)
+	}
+	return nil
+}
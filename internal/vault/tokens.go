@@ -0,0 +1,247 @@
+package vault
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// TokenScope limits what a daemon token may do.
+type TokenScope string
+
+// Token scopes. Control implies read.
+const (
+	TokenScopeRead    TokenScope = "read"
+	TokenScopeControl TokenScope = "control"
+)
+
+// tokenPrefix marks forged API tokens so they are easy to spot in configs.
+const tokenPrefix = "fgd_"
+
+// Token errors.
+var (
+	ErrTokenNotFound    = errors.New("token not found")
+	ErrTokenExists      = errors.New("token already exists")
+	ErrInvalidTokenName = errors.New("invalid token name")
+	ErrInvalidScope     = errors.New("invalid token scope")
+)
+
+// ParseTokenScope converts a string to a TokenScope.
+func ParseTokenScope(s string) (TokenScope, error) {
+	switch TokenScope(strings.ToLower(strings.TrimSpace(s))) {
+	case TokenScopeRead:
+		return TokenScopeRead, nil
+	case TokenScopeControl:
+		return TokenScopeControl, nil
+	default:
+		return "", fmt.Errorf("%w: %q (use read or control)", ErrInvalidScope, s)
+	}
+}
+
+// Allows reports whether a token with this scope may perform an operation
+// that requires required.
+func (s TokenScope) Allows(required TokenScope) bool {
+	switch s {
+	case TokenScopeControl:
+		return true
+	case TokenScopeRead:
+		return required == TokenScopeRead
+	default:
+		return false
+	}
+}
+
+// Token is a daemon API token. Only the SHA-256 hash of the secret is stored.
+type Token struct {
+	// Name identifies the token (e.g., the node or operator it was minted for).
+	Name string `json:"name"`
+
+	// Scope limits what the token may do.
+	Scope TokenScope `json:"scope"`
+
+	// Hash is the hex SHA-256 hash of the token secret.
+	Hash string `json:"hash"`
+
+	// CreatedAt is when the token was minted.
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// TokensPath returns the file daemon tokens are stored in.
+func TokensPath(vaultPath string) string {
+	return filepath.Join(vaultPath, "forged", "tokens.json")
+}
+
+// NodePath returns the directory credentials for reaching a node's daemon
+// are stored in: its token and, for TLS, the CA and client certificate.
+func NodePath(vaultPath, nodeName string) string {
+	return filepath.Join(vaultPath, "nodes", nodeName)
+}
+
+// MintToken creates a new daemon token and returns its secret. The secret is
+// not stored and cannot be recovered later.
+func MintToken(vaultPath, name string, scope TokenScope) (string, *Token, error) {
+	name = strings.TrimSpace(name)
+	if !validTokenName(name) {
+		return "", nil, ErrInvalidTokenName
+	}
+	if _, err := ParseTokenScope(string(scope)); err != nil {
+		return "", nil, err
+	}
+
+	tokens, err := ListTokens(vaultPath)
+	if err != nil {
+		return "", nil, err
+	}
+	for _, token := range tokens {
+		if token.Name == name {
+			return "", nil, ErrTokenExists
+		}
+	}
+
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return "", nil, fmt.Errorf("failed to generate token: %w", err)
+	}
+	secret := tokenPrefix + hex.EncodeToString(raw)
+
+	token := &Token{
+		Name:      name,
+		Scope:     scope,
+		Hash:      hashToken(secret),
+		CreatedAt: time.Now().UTC(),
+	}
+	tokens = append(tokens, token)
+	if err := writeTokens(vaultPath, tokens); err != nil {
+		return "", nil, err
+	}
+	return secret, token, nil
+}
+
+// ListTokens returns all daemon tokens sorted by name.
+func ListTokens(vaultPath string) ([]*Token, error) {
+	data, err := os.ReadFile(TokensPath(vaultPath))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read tokens: %w", err)
+	}
+
+	var tokens []*Token
+	if err := json.Unmarshal(data, &tokens); err != nil {
+		return nil, fmt.Errorf("failed to parse tokens: %w", err)
+	}
+	sort.Slice(tokens, func(i, j int) bool { return tokens[i].Name < tokens[j].Name })
+	return tokens, nil
+}
+
+// RevokeToken deletes a daemon token by name.
+func RevokeToken(vaultPath, name string) error {
+	tokens, err := ListTokens(vaultPath)
+	if err != nil {
+		return err
+	}
+
+	kept := tokens[:0]
+	for _, token := range tokens {
+		if token.Name != name {
+			kept = append(kept, token)
+		}
+	}
+	if len(kept) == len(tokens) {
+		return ErrTokenNotFound
+	}
+	return writeTokens(vaultPath, kept)
+}
+
+// MatchToken returns the token whose hash matches secret.
+func MatchToken(tokens []*Token, secret string) (*Token, bool) {
+	if secret == "" {
+		return nil, false
+	}
+	hash := []byte(hashToken(secret))
+	for _, token := range tokens {
+		if subtle.ConstantTimeCompare(hash, []byte(token.Hash)) == 1 {
+			return token, true
+		}
+	}
+	return nil, false
+}
+
+// StoreNodeToken saves the token used to reach a remote node's daemon.
+func StoreNodeToken(vaultPath, nodeName, secret string) error {
+	if !validTokenName(nodeName) {
+		return ErrInvalidTokenName
+	}
+	dir := NodePath(vaultPath, nodeName)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return fmt.Errorf("failed to create node directory: %w", err)
+	}
+	path := filepath.Join(dir, "token")
+	if err := os.WriteFile(path, []byte(strings.TrimSpace(secret)+"\n"), 0600); err != nil {
+		return fmt.Errorf("failed to write node token: %w", err)
+	}
+	return nil
+}
+
+// NodeToken returns the stored token for a node, or ErrTokenNotFound.
+func NodeToken(vaultPath, nodeName string) (string, error) {
+	if !validTokenName(nodeName) {
+		return "", ErrInvalidTokenName
+	}
+	data, err := os.ReadFile(filepath.Join(NodePath(vaultPath, nodeName), "token"))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", ErrTokenNotFound
+		}
+		return "", fmt.Errorf("failed to read node token: %w", err)
+	}
+	return strings.TrimSpace(string(data)), nil
+}
+
+// DeleteNodeCredentials removes everything stored for a node.
+func DeleteNodeCredentials(vaultPath, nodeName string) error {
+	if !validTokenName(nodeName) {
+		return ErrInvalidTokenName
+	}
+	if err := os.RemoveAll(NodePath(vaultPath, nodeName)); err != nil {
+		return fmt.Errorf("failed to delete node credentials: %w", err)
+	}
+	return nil
+}
+
+func writeTokens(vaultPath string, tokens []*Token) error {
+	path := TokensPath(vaultPath)
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("failed to create token directory: %w", err)
+	}
+	if tokens == nil {
+		tokens = []*Token{}
+	}
+	data, err := json.MarshalIndent(tokens, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal tokens: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return fmt.Errorf("failed to write tokens: %w", err)
+	}
+	return nil
+}
+
+func hashToken(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+func validTokenName(name string) bool {
+	return name != "" && name != "." && name != ".." && !strings.ContainsAny(name, `/\`)
+}
@@ -0,0 +1,118 @@
+package vault
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestTokens_MintMatchRevoke(t *testing.T) {
+	dir := t.TempDir()
+
+	secret, token, err := MintToken(dir, "ci", TokenScopeRead)
+	if err != nil {
+		t.Fatalf("failed to mint token: %v", err)
+	}
+	if !strings.HasPrefix(secret, tokenPrefix) {
+		t.Errorf("expected %s prefix, got %q", tokenPrefix, secret)
+	}
+	if token.Hash == secret || strings.Contains(token.Hash, secret) {
+		t.Error("token secret must not be stored")
+	}
+
+	if _, _, err := MintToken(dir, "ci", TokenScopeControl); !errors.Is(err, ErrTokenExists) {
+		t.Errorf("expected ErrTokenExists, got %v", err)
+	}
+	if _, _, err := MintToken(dir, "ops", "admin"); !errors.Is(err, ErrInvalidScope) {
+		t.Errorf("expected ErrInvalidScope, got %v", err)
+	}
+
+	info, err := os.Stat(TokensPath(dir))
+	if err != nil {
+		t.Fatalf("failed to stat tokens file: %v", err)
+	}
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("expected tokens file mode 0600, got %v", info.Mode().Perm())
+	}
+
+	tokens, err := ListTokens(dir)
+	if err != nil {
+		t.Fatalf("failed to list tokens: %v", err)
+	}
+	matched, ok := MatchToken(tokens, secret)
+	if !ok || matched.Name != "ci" || matched.Scope != TokenScopeRead {
+		t.Fatalf("expected secret to match ci token, got %+v", matched)
+	}
+	if _, ok := MatchToken(tokens, secret+"x"); ok {
+		t.Error("expected altered secret not to match")
+	}
+
+	if err := RevokeToken(dir, "ci"); err != nil {
+		t.Fatalf("failed to revoke token: %v", err)
+	}
+	if err := RevokeToken(dir, "ci"); !errors.Is(err, ErrTokenNotFound) {
+		t.Errorf("expected ErrTokenNotFound, got %v", err)
+	}
+	tokens, _ = ListTokens(dir)
+	if _, ok := MatchToken(tokens, secret); ok {
+		t.Error("revoked token should not match")
+	}
+}
+
+func TestTokenScope_Allows(t *testing.T) {
+	if !TokenScopeControl.Allows(TokenScopeRead) || !TokenScopeControl.Allows(TokenScopeControl) {
+		t.Error("control scope should allow read and control")
+	}
+	if !TokenScopeRead.Allows(TokenScopeRead) {
+		t.Error("read scope should allow read")
+	}
+	if TokenScopeRead.Allows(TokenScopeControl) {
+		t.Error("read scope should not allow control")
+	}
+}
+
+func TestNodeTokens(t *testing.T) {
+	dir := t.TempDir()
+
+	if _, err := NodeToken(dir, "prod"); !errors.Is(err, ErrTokenNotFound) {
+		t.Fatalf("expected ErrTokenNotFound, got %v", err)
+	}
+	if err := StoreNodeToken(dir, "prod", "fgd_secret"); err != nil {
+		t.Fatalf("failed to store node token: %v", err)
+	}
+	got, err := NodeToken(dir, "prod")
+	if err != nil || got != "fgd_secret" {
+		t.Fatalf("NodeToken() = %q, %v", got, err)
+	}
+	if err := DeleteNodeCredentials(dir, "prod"); err != nil {
+		t.Fatalf("failed to delete node credentials: %v", err)
+	}
+	if _, err := NodeToken(dir, "prod"); !errors.Is(err, ErrTokenNotFound) {
+		t.Errorf("expected ErrTokenNotFound after delete, got %v", err)
+	}
+	if err := StoreNodeToken(dir, "../escape", "x"); !errors.Is(err, ErrInvalidTokenName) {
+		t.Errorf("expected ErrInvalidTokenName, got %v", err)
+	}
+}
+
+func TestHookSecrets(t *testing.T) {
+	dir := t.TempDir()
+
+	if _, err := HookSecret(dir, "hook-1"); !errors.Is(err, ErrSecretNotFound) {
+		t.Fatalf("expected ErrSecretNotFound, got %v", err)
+	}
+	if err := StoreHookSecret(dir, "hook-1", "s3cret"); err != nil {
+		t.Fatalf("failed to store hook secret: %v", err)
+	}
+	got, err := HookSecret(dir, "hook-1")
+	if err != nil || got != "s3cret" {
+		t.Fatalf("HookSecret() = %q, %v", got, err)
+	}
+	if err := DeleteHookSecret(dir, "hook-1"); err != nil {
+		t.Fatalf("failed to delete hook secret: %v", err)
+	}
+	if _, err := HookSecret(dir, "hook-1"); !errors.Is(err, ErrSecretNotFound) {
+		t.Errorf("expected ErrSecretNotFound after delete, got %v", err)
+	}
+}
@@ -0,0 +1,585 @@
+package workflows
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// EvalExpr evaluates a workflow expression against a scope.
+//
+// Expressions support literals (numbers, quoted strings, true/false/null),
+// dotted references such as inputs.repo or steps.plan.outputs.count,
+// index access (steps["run-tests"]), comparison operators, &&, ||, !,
+// parentheses, and the helper functions len, count, empty, contains, and
+// string. Missing references evaluate to null.
+func EvalExpr(expr string, scope map[string]any) (any, error) {
+	tokens, err := tokenizeExpr(expr)
+	if err != nil {
+		return nil, err
+	}
+	p := &exprParser{tokens: tokens, scope: scope}
+	if p.peek().kind == exprTokenEOF {
+		return nil, fmt.Errorf("empty expression")
+	}
+	value, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if tok := p.peek(); tok.kind != exprTokenEOF {
+		return nil, fmt.Errorf("unexpected %q in expression %q", tok.text, expr)
+	}
+	return value, nil
+}
+
+// EvalBool evaluates an expression and reports its truthiness.
+func EvalBool(expr string, scope map[string]any) (bool, error) {
+	value, err := EvalExpr(expr, scope)
+	if err != nil {
+		return false, err
+	}
+	return truthy(value), nil
+}
+
+// Interpolate replaces ${expr} placeholders in text with evaluated values.
+func Interpolate(text string, scope map[string]any) (string, error) {
+	if !strings.Contains(text, "${") {
+		return text, nil
+	}
+
+	var b strings.Builder
+	rest := text
+	for {
+		start := strings.Index(rest, "${")
+		if start < 0 {
+			b.WriteString(rest)
+			break
+		}
+		end := strings.Index(rest[start:], "}")
+		if end < 0 {
+			return "", fmt.Errorf("unterminated placeholder in %q", text)
+		}
+		end += start
+
+		b.WriteString(rest[:start])
+		value, err := EvalExpr(rest[start+2:end], scope)
+		if err != nil {
+			return "", err
+		}
+		b.WriteString(FormatValue(value))
+		rest = rest[end+1:]
+	}
+	return b.String(), nil
+}
+
+// FormatValue renders an expression value as text.
+func FormatValue(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case bool:
+		return strconv.FormatBool(v)
+	case float64:
+		if v == math.Trunc(v) && math.Abs(v) < 1e15 {
+			return strconv.FormatInt(int64(v), 10)
+		}
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case int, int64, int32:
+		return fmt.Sprint(v)
+	default:
+		data, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Sprint(v)
+		}
+		return string(data)
+	}
+}
+
+type exprTokenKind int
+
+const (
+	exprTokenEOF exprTokenKind = iota
+	exprTokenIdent
+	exprTokenNumber
+	exprTokenString
+	exprTokenOp
+)
+
+type exprToken struct {
+	kind exprTokenKind
+	text string
+}
+
+func tokenizeExpr(expr string) ([]exprToken, error) {
+	tokens := make([]exprToken, 0)
+	runes := []rune(expr)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '"' || r == '\'':
+			quote := r
+			var b strings.Builder
+			j := i + 1
+			for ; j < len(runes) && runes[j] != quote; j++ {
+				if runes[j] == '\\' && j+1 < len(runes) {
+					j++
+				}
+				b.WriteRune(runes[j])
+			}
+			if j >= len(runes) {
+				return nil, fmt.Errorf("unterminated string in expression %q", expr)
+			}
+			tokens = append(tokens, exprToken{kind: exprTokenString, text: b.String()})
+			i = j + 1
+		case unicode.IsDigit(r):
+			j := i
+			for j < len(runes) && (unicode.IsDigit(runes[j]) || runes[j] == '.') {
+				j++
+			}
+			tokens = append(tokens, exprToken{kind: exprTokenNumber, text: string(runes[i:j])})
+			i = j
+		case r == '_' || unicode.IsLetter(r):
+			j := i
+			for j < len(runes) && isExprIdentRune(runes[j]) {
+				j++
+			}
+			tokens = append(tokens, exprToken{kind: exprTokenIdent, text: string(runes[i:j])})
+			i = j
+		default:
+			if i+1 < len(runes) {
+				two := string(runes[i : i+2])
+				switch two {
+				case "==", "!=", "<=", ">=", "&&", "||":
+					tokens = append(tokens, exprToken{kind: exprTokenOp, text: two})
+					i += 2
+					continue
+				}
+			}
+			switch r {
+			case '<', '>', '!', '(', ')', '[', ']', '.', ',', '-':
+				tokens = append(tokens, exprToken{kind: exprTokenOp, text: string(r)})
+				i++
+			default:
+				return nil, fmt.Errorf("unexpected character %q in expression %q", r, expr)
+			}
+		}
+	}
+	tokens = append(tokens, exprToken{kind: exprTokenEOF})
+	return tokens, nil
+}
+
+// Step ids commonly contain dashes, so identifiers may include them.
+func isExprIdentRune(r rune) bool {
+	return r == '_' || r == '-' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+type exprParser struct {
+	tokens []exprToken
+	pos    int
+	scope  map[string]any
+}
+
+func (p *exprParser) peek() exprToken {
+	return p.tokens[p.pos]
+}
+
+func (p *exprParser) next() exprToken {
+	tok := p.tokens[p.pos]
+	if tok.kind != exprTokenEOF {
+		p.pos++
+	}
+	return tok
+}
+
+func (p *exprParser) acceptOp(ops ...string) (string, bool) {
+	tok := p.peek()
+	if tok.kind != exprTokenOp && tok.kind != exprTokenIdent {
+		return "", false
+	}
+	for _, op := range ops {
+		if tok.text == op {
+			p.pos++
+			return op, true
+		}
+	}
+	return "", false
+}
+
+func (p *exprParser) expectOp(op string) error {
+	tok := p.next()
+	if tok.kind != exprTokenOp || tok.text != op {
+		return fmt.Errorf("expected %q, got %q", op, tok.text)
+	}
+	return nil
+}
+
+func (p *exprParser) parseOr() (any, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		if _, ok := p.acceptOp("||", "or"); !ok {
+			return left, nil
+		}
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = truthy(left) || truthy(right)
+	}
+}
+
+func (p *exprParser) parseAnd() (any, error) {
+	left, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		if _, ok := p.acceptOp("&&", "and"); !ok {
+			return left, nil
+		}
+		right, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		left = truthy(left) && truthy(right)
+	}
+}
+
+func (p *exprParser) parseNot() (any, error) {
+	if _, ok := p.acceptOp("!", "not"); ok {
+		value, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		return !truthy(value), nil
+	}
+	return p.parseComparison()
+}
+
+func (p *exprParser) parseComparison() (any, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	op, ok := p.acceptOp("==", "!=", "<=", ">=", "<", ">")
+	if !ok {
+		return left, nil
+	}
+	right, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	return compareValues(op, left, right)
+}
+
+func (p *exprParser) parseUnary() (any, error) {
+	if _, ok := p.acceptOp("-"); ok {
+		value, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		num, ok := toNumber(value)
+		if !ok {
+			return nil, fmt.Errorf("cannot negate %s", FormatValue(value))
+		}
+		return -num, nil
+	}
+	return p.parsePostfix()
+}
+
+func (p *exprParser) parsePostfix() (any, error) {
+	value, err := p.parsePrimary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		if _, ok := p.acceptOp("."); ok {
+			tok := p.next()
+			if tok.kind != exprTokenIdent && tok.kind != exprTokenNumber {
+				return nil, fmt.Errorf("expected field name after '.', got %q", tok.text)
+			}
+			value = lookupField(value, tok.text)
+			continue
+		}
+		if _, ok := p.acceptOp("["); ok {
+			key, err := p.parseOr()
+			if err != nil {
+				return nil, err
+			}
+			if err := p.expectOp("]"); err != nil {
+				return nil, err
+			}
+			value = lookupIndex(value, key)
+			continue
+		}
+		return value, nil
+	}
+}
+
+func (p *exprParser) parsePrimary() (any, error) {
+	tok := p.next()
+	switch tok.kind {
+	case exprTokenNumber:
+		num, err := strconv.ParseFloat(tok.text, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number %q", tok.text)
+		}
+		return num, nil
+	case exprTokenString:
+		return tok.text, nil
+	case exprTokenIdent:
+		switch tok.text {
+		case "true":
+			return true, nil
+		case "false":
+			return false, nil
+		case "null", "nil":
+			return nil, nil
+		}
+		if next := p.peek(); next.kind == exprTokenOp && next.text == "(" {
+			p.pos++
+			args, err := p.parseArgs()
+			if err != nil {
+				return nil, err
+			}
+			return callExprFunc(tok.text, args)
+		}
+		return lookupField(p.scope, tok.text), nil
+	case exprTokenOp:
+		if tok.text == "(" {
+			value, err := p.parseOr()
+			if err != nil {
+				return nil, err
+			}
+			if err := p.expectOp(")"); err != nil {
+				return nil, err
+			}
+			return value, nil
+		}
+	case exprTokenEOF:
+		return nil, fmt.Errorf("unexpected end of expression")
+	}
+	return nil, fmt.Errorf("unexpected %q in expression", tok.text)
+}
+
+func (p *exprParser) parseArgs() ([]any, error) {
+	args := make([]any, 0)
+	if _, ok := p.acceptOp(")"); ok {
+		return args, nil
+	}
+	for {
+		value, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, value)
+		if _, ok := p.acceptOp(","); ok {
+			continue
+		}
+		if err := p.expectOp(")"); err != nil {
+			return nil, err
+		}
+		return args, nil
+	}
+}
+
+func callExprFunc(name string, args []any) (any, error) {
+	switch name {
+	case "len", "count":
+		if len(args) != 1 {
+			return nil, fmt.Errorf("%s expects 1 argument", name)
+		}
+		return float64(valueLen(args[0])), nil
+	case "empty":
+		if len(args) != 1 {
+			return nil, fmt.Errorf("empty expects 1 argument")
+		}
+		return valueLen(args[0]) == 0, nil
+	case "contains":
+		if len(args) != 2 {
+			return nil, fmt.Errorf("contains expects 2 arguments")
+		}
+		return valueContains(args[0], args[1]), nil
+	case "string":
+		if len(args) != 1 {
+			return nil, fmt.Errorf("string expects 1 argument")
+		}
+		return FormatValue(args[0]), nil
+	default:
+		return nil, fmt.Errorf("unknown function %q", name)
+	}
+}
+
+func lookupField(value any, key string) any {
+	switch v := value.(type) {
+	case map[string]any:
+		return v[key]
+	case map[string]string:
+		if s, ok := v[key]; ok {
+			return s
+		}
+		return nil
+	case []any:
+		if idx, err := strconv.Atoi(key); err == nil && idx >= 0 && idx < len(v) {
+			return v[idx]
+		}
+		return nil
+	default:
+		return nil
+	}
+}
+
+func lookupIndex(value any, key any) any {
+	if num, ok := key.(float64); ok {
+		return lookupField(value, strconv.Itoa(int(num)))
+	}
+	return lookupField(value, FormatValue(key))
+}
+
+func valueLen(value any) int {
+	switch v := value.(type) {
+	case nil:
+		return 0
+	case string:
+		return len(v)
+	case []any:
+		return len(v)
+	case map[string]any:
+		return len(v)
+	}
+	rv := reflect.ValueOf(value)
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Array, reflect.Map, reflect.String:
+		return rv.Len()
+	}
+	return 0
+}
+
+func valueContains(container any, item any) bool {
+	switch v := container.(type) {
+	case string:
+		return strings.Contains(v, FormatValue(item))
+	case []any:
+		for _, entry := range v {
+			if valuesEqual(entry, item) {
+				return true
+			}
+		}
+	case map[string]any:
+		_, ok := v[FormatValue(item)]
+		return ok
+	}
+	return false
+}
+
+func truthy(value any) bool {
+	switch v := value.(type) {
+	case nil:
+		return false
+	case bool:
+		return v
+	case float64:
+		return v != 0
+	case int:
+		return v != 0
+	case int64:
+		return v != 0
+	case string:
+		trimmed := strings.ToLower(strings.TrimSpace(v))
+		return trimmed != "" && trimmed != "false" && trimmed != "0"
+	default:
+		return valueLen(value) > 0 || reflect.ValueOf(value).Kind() == reflect.Struct
+	}
+}
+
+func toNumber(value any) (float64, bool) {
+	switch v := value.(type) {
+	case float64:
+		return v, true
+	case float32:
+		return float64(v), true
+	case int:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case int32:
+		return float64(v), true
+	case string:
+		num, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+		if err != nil {
+			return 0, false
+		}
+		return num, true
+	default:
+		return 0, false
+	}
+}
+
+func valuesEqual(left, right any) bool {
+	if left == nil || right == nil {
+		return left == nil && right == nil
+	}
+	if lb, ok := left.(bool); ok {
+		return lb == truthy(right)
+	}
+	if rb, ok := right.(bool); ok {
+		return rb == truthy(left)
+	}
+	if ln, ok := toNumber(left); ok {
+		if rn, ok := toNumber(right); ok {
+			return ln == rn
+		}
+	}
+	return FormatValue(left) == FormatValue(right)
+}
+
+func compareValues(op string, left, right any) (any, error) {
+	switch op {
+	case "==":
+		return valuesEqual(left, right), nil
+	case "!=":
+		return !valuesEqual(left, right), nil
+	}
+
+	ln, lok := toNumber(left)
+	rn, rok := toNumber(right)
+	if lok && rok {
+		switch op {
+		case "<":
+			return ln < rn, nil
+		case "<=":
+			return ln <= rn, nil
+		case ">":
+			return ln > rn, nil
+		case ">=":
+			return ln >= rn, nil
+		}
+	}
+
+	ls, lsok := left.(string)
+	rs, rsok := right.(string)
+	if lsok && rsok {
+		switch op {
+		case "<":
+			return ls < rs, nil
+		case "<=":
+			return ls <= rs, nil
+		case ">":
+			return ls > rs, nil
+		case ">=":
+			return ls >= rs, nil
+		}
+	}
+
+	return nil, fmt.Errorf("cannot compare %s %s %s", FormatValue(left), op, FormatValue(right))
+}
@@ -0,0 +1,65 @@
+package workflows
+
+import "testing"
+
+func TestEvalExpr(t *testing.T) {
+	scope := map[string]any{
+		"inputs": map[string]any{"repo": ".", "flag": "true", "limit": 20.0},
+		"steps": map[string]any{
+			"run-tests": map[string]any{
+				"status":  "success",
+				"outputs": map[string]any{"count": "42", "items": []any{"a", "b"}},
+			},
+		},
+	}
+
+	cases := []struct {
+		expr string
+		want bool
+	}{
+		{expr: `inputs.repo == "."`, want: true},
+		{expr: `inputs.flag`, want: true},
+		{expr: `steps.run-tests.outputs.count > inputs.limit`, want: true},
+		{expr: `steps["run-tests"].status != 'success'`, want: false},
+		{expr: `count(steps.run-tests.outputs.items) == 2 && !empty(inputs.repo)`, want: true},
+		{expr: `contains(steps.run-tests.outputs.items, "c") || inputs.missing`, want: false},
+		{expr: `not (inputs.limit >= 21) and true`, want: true},
+		{expr: `-1 < 0`, want: true},
+	}
+
+	for _, tc := range cases {
+		got, err := EvalBool(tc.expr, scope)
+		if err != nil {
+			t.Fatalf("eval %q: %v", tc.expr, err)
+		}
+		if got != tc.want {
+			t.Fatalf("eval %q: expected %t, got %t", tc.expr, tc.want, got)
+		}
+	}
+}
+
+func TestEvalExprErrors(t *testing.T) {
+	for _, expr := range []string{"", "inputs.a ==", "unknown(1)", `"open`, "(true", "1 < true"} {
+		if _, err := EvalExpr(expr, map[string]any{}); err == nil {
+			t.Fatalf("expected error for %q", expr)
+		}
+	}
+}
+
+func TestInterpolate(t *testing.T) {
+	scope := map[string]any{
+		"inputs": map[string]any{"name": "forge", "n": 3.0, "tags": []any{"x"}},
+	}
+
+	got, err := Interpolate("hello ${inputs.name} x${ inputs.n } ${inputs.tags}", scope)
+	if err != nil {
+		t.Fatalf("interpolate: %v", err)
+	}
+	if got != `hello forge x3 ["x"]` {
+		t.Fatalf("unexpected interpolation %q", got)
+	}
+
+	if _, err := Interpolate("broken ${inputs.name", scope); err == nil {
+		t.Fatalf("expected unterminated placeholder error")
+	}
+}
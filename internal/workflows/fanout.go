@@ -0,0 +1,430 @@
+package workflows
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/tOgg1/forge/internal/beads"
+	"github.com/tOgg1/forge/internal/db"
+	"github.com/tOgg1/forge/internal/models"
+)
+
+// defaultBeadsStatus is the issue status a beads query selects when none is given.
+const defaultBeadsStatus = "open"
+
+// runFanoutStep resolves the item list and runs one agent or loop child per item,
+// at most max_parallel (and the profile or pool capacity) at a time. Each child is
+// recorded as its own step run, "<step>.<n>". The step fails if any child fails;
+// its results are still available to a join step.
+func (r *Runner) runFanoutStep(ctx context.Context, sc *stepContext) stepOutcome {
+	items, err := r.fanoutItems(ctx, sc)
+	if err != nil {
+		return failedOutcome(fmt.Errorf("items: %w", err))
+	}
+
+	parallel, err := r.fanoutParallelism(ctx, sc.step, len(items))
+	if err != nil {
+		return failedOutcome(err)
+	}
+	sc.log.WriteLine(fmt.Sprintf("fanout over %d items (each=%s max_parallel=%d)", len(items), fanoutChildType(sc.step), parallel))
+
+	results := make([]map[string]any, len(items))
+	sem := make(chan struct{}, max(parallel, 1))
+	var wg sync.WaitGroup
+	for i, item := range items {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			canceled := &models.WorkflowStepRun{StepID: fanoutChildID(sc.step.ID, i), Status: models.WorkflowStepStatusCanceled, Error: "workflow canceled"}
+			results[i] = fanoutResult(i, item, canceled)
+			continue
+		}
+
+		wg.Add(1)
+		go func(index int, item any) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			results[index] = fanoutResult(index, item, r.runFanoutChild(ctx, sc, index, item))
+		}(i, item)
+	}
+	wg.Wait()
+
+	failed := 0
+	list := make([]any, 0, len(results))
+	for _, result := range results {
+		if result["status"] != string(models.WorkflowStepStatusSuccess) {
+			failed++
+		}
+		list = append(list, result)
+	}
+
+	outcome := stepOutcome{
+		status: models.WorkflowStepStatusSuccess,
+		outputs: map[string]any{
+			"count":     len(items),
+			"succeeded": len(items) - failed,
+			"failed":    failed,
+			"results":   list,
+		},
+	}
+	if failed > 0 {
+		outcome.status = models.WorkflowStepStatusFailed
+		outcome.err = fmt.Errorf("%d of %d items failed", failed, len(items))
+	}
+	return outcome
+}
+
+// runFanoutChild runs a single item as an agent or loop step and records it.
+func (r *Runner) runFanoutChild(ctx context.Context, parent *stepContext, index int, item any) *models.WorkflowStepRun {
+	e := parent.exec
+	step := parent.step
+	step.ID = fanoutChildID(parent.step.ID, index)
+	step.Type = fanoutChildType(parent.step)
+	step.AliveWith = nil
+
+	rec, err := r.fanoutChildRecord(ctx, e, step)
+	if err != nil {
+		return &models.WorkflowStepRun{StepID: step.ID, Status: models.WorkflowStepStatusFailed, Error: err.Error()}
+	}
+
+	logger, err := newStepLogger(rec.LogPath)
+	if err != nil {
+		return r.completeStep(ctx, e, rec, failedOutcome(fmt.Errorf("open step log: %w", err)))
+	}
+	defer logger.Close()
+	logger.WriteLine(fmt.Sprintf("step %s started (type=%s item=%s)", step.ID, step.Type, FormatValue(item)))
+	parent.log.WriteLine(fmt.Sprintf("item %d started (step %s)", index+1, step.ID))
+
+	scope := make(map[string]any, len(parent.scope)+2)
+	for key, value := range parent.scope {
+		scope[key] = value
+	}
+	scope["item"] = item
+	scope["index"] = index
+
+	env := append([]string{}, parent.env...)
+	env = append(env, "FORGE_FANOUT_INDEX="+fmt.Sprint(index), "FORGE_FANOUT_ITEM="+FormatValue(item))
+
+	sc := &stepContext{
+		exec:    e,
+		step:    step,
+		rec:     rec,
+		scope:   scope,
+		log:     logger,
+		workDir: parent.workDir,
+		env:     env,
+	}
+	outcome := r.runLoopStep(ctx, sc)
+	if ctx.Err() != nil {
+		outcome.status = models.WorkflowStepStatusCanceled
+		outcome.err = errors.New("workflow canceled")
+	}
+	if outcome.outputs != nil {
+		outcome.outputs["item"] = item
+		outcome.outputs["index"] = index
+	}
+
+	if outcome.err != nil {
+		logger.WriteLine(fmt.Sprintf("step %s %s: %v", step.ID, outcome.status, outcome.err))
+	} else {
+		logger.WriteLine(fmt.Sprintf("step %s %s", step.ID, outcome.status))
+	}
+	parent.log.WriteLine(fmt.Sprintf("item %d %s (step %s)", index+1, outcome.status, step.ID))
+	return r.completeStep(ctx, e, rec, outcome)
+}
+
+// fanoutChildRecord creates or restarts the step run record of a fanout child.
+func (r *Runner) fanoutChildRecord(ctx context.Context, e *execution, step WorkflowStep) (*models.WorkflowStepRun, error) {
+	rec, err := e.repo.GetStep(ctx, e.run.ID, step.ID)
+	created := false
+	if errors.Is(err, db.ErrWorkflowStepRunNotFound) {
+		rec = &models.WorkflowStepRun{
+			RunID:    e.run.ID,
+			StepID:   step.ID,
+			StepType: string(step.Type),
+			Status:   models.WorkflowStepStatusPending,
+		}
+		created = true
+	} else if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	rec.Status = models.WorkflowStepStatusRunning
+	rec.Attempt++
+	rec.StartedAt = &now
+	rec.FinishedAt = nil
+	rec.ExitCode = nil
+	rec.Outputs = nil
+	rec.Error = ""
+	rec.LoopID = ""
+	rec.LogPath = StepLogPath(r.Config.Global.DataDir, e.run.ID, step.ID)
+
+	if created {
+		if err := e.repo.CreateStep(ctx, rec); err != nil {
+			return nil, err
+		}
+		return rec, nil
+	}
+	if err := e.repo.UpdateStep(ctx, rec); err != nil {
+		return nil, err
+	}
+	return rec, nil
+}
+
+// fanoutItems resolves the fanout list from items, items_cmd, or items_beads.
+func (r *Runner) fanoutItems(ctx context.Context, sc *stepContext) ([]any, error) {
+	step := sc.step
+	switch {
+	case step.ItemsCmd != "":
+		return r.fanoutCmdItems(ctx, sc)
+	case step.ItemsBeads != nil:
+		return fanoutBeadsItems(sc.workDir, step.ItemsBeads)
+	}
+
+	switch items := step.Items.(type) {
+	case []any:
+		return items, nil
+	case string:
+		expr := strings.TrimSpace(items)
+		if strings.HasPrefix(expr, "${") && strings.HasSuffix(expr, "}") {
+			expr = expr[2 : len(expr)-1]
+		}
+		value, err := EvalExpr(expr, sc.scope)
+		if err != nil {
+			return nil, err
+		}
+		list, ok := value.([]any)
+		if !ok {
+			return nil, fmt.Errorf("%s is %T, not a list", items, value)
+		}
+		return list, nil
+	default:
+		return nil, errors.New("no items source")
+	}
+}
+
+// fanoutCmdItems runs items_cmd and parses its stdout as a JSON array, falling back
+// to one item per non-empty line.
+func (r *Runner) fanoutCmdItems(ctx context.Context, sc *stepContext) ([]any, error) {
+	cmdText, err := Interpolate(sc.step.ItemsCmd, sc.scope)
+	if err != nil {
+		return nil, err
+	}
+
+	sc.log.WriteLine("$ " + cmdText)
+	var stdout bytes.Buffer
+	cmd := exec.CommandContext(ctx, "bash", "-lc", cmdText)
+	cmd.Dir = sc.workDir
+	cmd.Env = append(os.Environ(), sc.env...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = sc.log
+	cmd.WaitDelay = time.Second
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("items_cmd: %w", err)
+	}
+
+	trimmed := strings.TrimSpace(stdout.String())
+	if trimmed == "" {
+		return []any{}, nil
+	}
+	var parsed any
+	if err := json.Unmarshal([]byte(trimmed), &parsed); err == nil {
+		list, ok := parsed.([]any)
+		if !ok {
+			return nil, fmt.Errorf("items_cmd printed JSON %T, expected an array", parsed)
+		}
+		return list, nil
+	}
+
+	items := make([]any, 0)
+	for _, line := range strings.Split(trimmed, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			items = append(items, line)
+		}
+	}
+	return items, nil
+}
+
+// fanoutBeadsItems selects issues from the repo's beads file, highest priority first.
+func fanoutBeadsItems(repoPath string, query *BeadsQuery) ([]any, error) {
+	issues, err := beads.LoadIssues(beads.IssuesPath(repoPath))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("no beads issues in %s", repoPath)
+		}
+		return nil, err
+	}
+
+	status := query.Status
+	if status == "" {
+		status = defaultBeadsStatus
+	}
+	selected := make([]beads.Issue, 0, len(issues))
+	for _, issue := range issues {
+		if status != "any" && !strings.EqualFold(issue.Status, status) {
+			continue
+		}
+		if query.Type != "" && !strings.EqualFold(issue.IssueType, query.Type) {
+			continue
+		}
+		selected = append(selected, issue)
+	}
+	sort.SliceStable(selected, func(i, j int) bool {
+		return selected[i].Priority < selected[j].Priority
+	})
+	if query.Limit > 0 && len(selected) > query.Limit {
+		selected = selected[:query.Limit]
+	}
+
+	items := make([]any, 0, len(selected))
+	for _, issue := range selected {
+		items = append(items, map[string]any{
+			"id":          issue.ID,
+			"title":       issue.Title,
+			"description": issue.Description,
+			"status":      issue.Status,
+			"priority":    issue.Priority,
+			"type":        issue.IssueType,
+		})
+	}
+	return items, nil
+}
+
+// fanoutParallelism bounds concurrent children by max_parallel and by the
+// concurrency of the step's profile or pool. Zero max_concurrency means unlimited.
+func (r *Runner) fanoutParallelism(ctx context.Context, step WorkflowStep, count int) (int, error) {
+	parallel := count
+	if step.MaxParallel > 0 && step.MaxParallel < parallel {
+		parallel = step.MaxParallel
+	}
+
+	capacity, err := r.profileCapacity(ctx, step)
+	if err != nil {
+		return 0, err
+	}
+	if capacity > 0 && capacity < parallel {
+		parallel = capacity
+	}
+	return parallel, nil
+}
+
+func (r *Runner) profileCapacity(ctx context.Context, step WorkflowStep) (int, error) {
+	profiles := db.NewProfileRepository(r.DB)
+	if step.Profile != "" {
+		profile, err := resolveProfileRef(ctx, profiles, step.Profile)
+		if err != nil {
+			return 0, err
+		}
+		return profile.MaxConcurrency, nil
+	}
+	if step.Pool == "" {
+		return 0, nil
+	}
+
+	pools := db.NewPoolRepository(r.DB)
+	pool, err := resolvePoolRef(ctx, pools, step.Pool)
+	if err != nil {
+		return 0, err
+	}
+	members, err := pools.ListMembers(ctx, pool.ID)
+	if err != nil {
+		return 0, err
+	}
+	capacity := 0
+	for _, member := range members {
+		profile, err := profiles.Get(ctx, member.ProfileID)
+		if err != nil {
+			continue
+		}
+		if profile.MaxConcurrency <= 0 {
+			return 0, nil
+		}
+		capacity += profile.MaxConcurrency
+	}
+	return capacity, nil
+}
+
+// runJoinStep collects the results of a fanout step. It fails when any child failed.
+func (r *Runner) runJoinStep(sc *stepContext) stepOutcome {
+	steps, _ := sc.scope["steps"].(map[string]any)
+	fanout, _ := steps[sc.step.Fanout].(map[string]any)
+	outputs, _ := fanout["outputs"].(map[string]any)
+	results, ok := outputs["results"].([]any)
+	if !ok {
+		return failedOutcome(fmt.Errorf("fanout %s has no results", sc.step.Fanout))
+	}
+
+	collected := make([]any, 0, len(results))
+	exitCodes := make([]any, 0, len(results))
+	failed := 0
+	for _, entry := range results {
+		result, _ := entry.(map[string]any)
+		collected = append(collected, result["outputs"])
+		exitCodes = append(exitCodes, result["exit_code"])
+		if result["status"] != string(models.WorkflowStepStatusSuccess) {
+			failed++
+		}
+	}
+	sc.log.WriteLine(fmt.Sprintf("joined %d results from %s (%d failed)", len(results), sc.step.Fanout, failed))
+
+	outcome := stepOutcome{
+		status: models.WorkflowStepStatusSuccess,
+		outputs: map[string]any{
+			"count":      len(results),
+			"succeeded":  len(results) - failed,
+			"failed":     failed,
+			"results":    results,
+			"outputs":    collected,
+			"exit_codes": exitCodes,
+		},
+	}
+	if failed > 0 {
+		outcome.status = models.WorkflowStepStatusFailed
+		outcome.err = fmt.Errorf("%d of %d fanout items failed", failed, len(results))
+	}
+	return outcome
+}
+
+// fanoutResult is the per-item entry of a fanout step's results output.
+func fanoutResult(index int, item any, rec *models.WorkflowStepRun) map[string]any {
+	result := map[string]any{
+		"index":   index,
+		"item":    item,
+		"step_id": rec.StepID,
+		"status":  string(rec.Status),
+		"outputs": rec.Outputs,
+	}
+	if rec.ExitCode != nil {
+		result["exit_code"] = *rec.ExitCode
+	}
+	if rec.LoopID != "" {
+		result["loop_id"] = rec.LoopID
+	}
+	if rec.Error != "" {
+		result["error"] = rec.Error
+	}
+	return result
+}
+
+func fanoutChildID(stepID string, index int) string {
+	return fmt.Sprintf("%s.%d", stepID, index+1)
+}
+
+func fanoutChildType(step WorkflowStep) StepType {
+	if step.Each == "" {
+		return StepTypeAgent
+	}
+	return step.Each
+}
@@ -0,0 +1,169 @@
+package workflows
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/tOgg1/forge/internal/db"
+	"github.com/tOgg1/forge/internal/models"
+)
+
+func TestFanoutRunsItemsAndJoinCollectsResults(t *testing.T) {
+	runner, database, repoDir := newTestRunner(t)
+	profile := &models.Profile{
+		Name:            "wide",
+		Harness:         models.HarnessPi,
+		PromptMode:      models.PromptModeEnv,
+		CommandTemplate: "pi -p \"$FORGE_PROMPT_CONTENT\"",
+		MaxConcurrency:  4,
+	}
+	if err := db.NewProfileRepository(database).Create(context.Background(), profile); err != nil {
+		t.Fatalf("create profile: %v", err)
+	}
+
+	var mu sync.Mutex
+	active, peak := 0, 0
+	prompts := make([]string, 0, 3)
+	runner.LoopExec = func(ctx context.Context, profile models.Profile, promptPath, promptContent, workDir string, output io.Writer) (int, string, error) {
+		mu.Lock()
+		active++
+		peak = max(peak, active)
+		prompts = append(prompts, firstLine(promptContent))
+		mu.Unlock()
+
+		time.Sleep(50 * time.Millisecond)
+
+		mu.Lock()
+		active--
+		mu.Unlock()
+		if strings.Contains(promptContent, "task-b") {
+			return 1, "broken", errors.New("exit status 1")
+		}
+		return 0, "done " + firstLine(promptContent), nil
+	}
+
+	wf := &Workflow{
+		Name:   "spread",
+		Source: filepath.Join(repoDir, ".forge", "workflows", "spread.toml"),
+		Steps: []WorkflowStep{
+			{
+				ID:          "workers",
+				Type:        StepTypeFanout,
+				Items:       []any{"task-a", "task-b", "task-c"},
+				Prompt:      "work on ${item} (#${index})",
+				Profile:     "wide",
+				MaxParallel: 2,
+			},
+			{ID: "collect", Type: StepTypeJoin, Fanout: "workers"},
+		},
+	}
+
+	run, err := runner.Run(context.Background(), wf, nil)
+	if err != nil {
+		t.Fatalf("run workflow: %v", err)
+	}
+	if run.Status != models.WorkflowRunStatusFailed {
+		t.Fatalf("expected failed run, got %s", run.Status)
+	}
+	if peak > 2 {
+		t.Fatalf("expected at most 2 concurrent items, got %d", peak)
+	}
+	if len(prompts) != 3 || !containsString(prompts, "work on task-c (#2)") {
+		t.Fatalf("expected interpolated prompts, got %q", prompts)
+	}
+
+	steps := stepsByID(t, database, run.ID)
+	if steps["workers"].Error != "1 of 3 items failed" {
+		t.Fatalf("unexpected fanout error %q", steps["workers"].Error)
+	}
+	if steps["workers.2"].Status != models.WorkflowStepStatusFailed || steps["workers.3"].Status != models.WorkflowStepStatusSuccess {
+		t.Fatalf("expected child step records, got %v", steps)
+	}
+
+	collect := steps["collect"]
+	if collect.Status != models.WorkflowStepStatusFailed {
+		t.Fatalf("expected join to fail on child failure, got %s", collect.Status)
+	}
+	if collect.Outputs["succeeded"] != float64(2) || collect.Outputs["failed"] != float64(1) {
+		t.Fatalf("unexpected join counts %v", collect.Outputs)
+	}
+	exitCodes, _ := collect.Outputs["exit_codes"].([]any)
+	if len(exitCodes) != 3 || exitCodes[1] != float64(1) {
+		t.Fatalf("expected per-item exit codes, got %v", collect.Outputs["exit_codes"])
+	}
+	outputs, _ := collect.Outputs["outputs"].([]any)
+	first, _ := outputs[0].(map[string]any)
+	if first["output"] != "done work on task-a (#0)" {
+		t.Fatalf("expected child outputs collected, got %v", outputs[0])
+	}
+}
+
+func TestFanoutItemsFromCommandAndBeads(t *testing.T) {
+	runner, database, repoDir := newTestRunner(t)
+	createTestProfile(t, database)
+
+	var mu sync.Mutex
+	var prompts []string
+	runner.LoopExec = func(ctx context.Context, profile models.Profile, promptPath, promptContent, workDir string, output io.Writer) (int, string, error) {
+		mu.Lock()
+		prompts = append(prompts, firstLine(promptContent))
+		mu.Unlock()
+		return 0, "ok", nil
+	}
+
+	beadsDir := filepath.Join(repoDir, ".beads")
+	if err := os.MkdirAll(beadsDir, 0o755); err != nil {
+		t.Fatalf("mkdir beads: %v", err)
+	}
+	issues := strings.Join([]string{
+		`{"id":"bd-1","title":"Low","status":"open","priority":3,"issue_type":"task"}`,
+		`{"id":"bd-2","title":"Done","status":"closed","priority":0,"issue_type":"task"}`,
+		`{"id":"bd-3","title":"Urgent","status":"open","priority":0,"issue_type":"task"}`,
+	}, "\n")
+	if err := os.WriteFile(filepath.Join(beadsDir, "issues.jsonl"), []byte(issues), 0o644); err != nil {
+		t.Fatalf("write issues: %v", err)
+	}
+
+	wf := &Workflow{
+		Name:   "sources",
+		Source: filepath.Join(repoDir, ".forge", "workflows", "sources.toml"),
+		Steps: []WorkflowStep{
+			{ID: "cmd", Type: StepTypeFanout, ItemsCmd: `echo '[{"name": "x"}, {"name": "y"}]'`, Prompt: "cmd ${item.name}", Profile: "pi-default"},
+			{ID: "tasks", Type: StepTypeFanout, ItemsBeads: &BeadsQuery{}, Prompt: "bead ${item.id} ${item.title}", Profile: "pi-default", DependsOn: []string{"cmd"}},
+		},
+	}
+
+	run, err := runner.Run(context.Background(), wf, nil)
+	if err != nil {
+		t.Fatalf("run workflow: %v", err)
+	}
+	if run.Status != models.WorkflowRunStatusSuccess {
+		t.Fatalf("expected success, got %s (%s)", run.Status, run.Error)
+	}
+
+	want := []string{"cmd x", "cmd y", "bead bd-3 Urgent", "bead bd-1 Low"}
+	if fmt.Sprint(prompts) != fmt.Sprint(want) {
+		t.Fatalf("expected prompts %q, got %q", want, prompts)
+	}
+}
+
+func TestFanoutParallelismCappedByProfile(t *testing.T) {
+	runner, database, _ := newTestRunner(t)
+	createTestProfile(t, database)
+
+	parallel, err := runner.fanoutParallelism(context.Background(), WorkflowStep{Profile: "pi-default", MaxParallel: 4}, 10)
+	if err != nil {
+		t.Fatalf("fanout parallelism: %v", err)
+	}
+	if parallel != 1 {
+		t.Fatalf("expected profile max_concurrency to cap parallelism, got %d", parallel)
+	}
+}
@@ -0,0 +1,243 @@
+package workflows
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/tOgg1/forge/internal/db"
+	"github.com/tOgg1/forge/internal/models"
+)
+
+// defaultApprovalPollInterval is how often a waiting human step checks its approval.
+const defaultApprovalPollInterval = 2 * time.Second
+
+// approvalResolver is recorded on approvals the runner resolves itself.
+const approvalResolver = "workflow"
+
+// ErrNoPendingApproval is returned when a step has no approval waiting on a decision.
+var ErrNoPendingApproval = errors.New("no pending approval")
+
+// humanRequest is stored as the approval request details for a human step.
+type humanRequest struct {
+	Workflow string `json:"workflow"`
+	RunID    string `json:"run_id"`
+	StepID   string `json:"step_id"`
+	Prompt   string `json:"prompt,omitempty"`
+}
+
+// runHumanStep pauses the step until its approval is approved, rejected, or times out.
+// A pending approval left by an earlier attempt is reused so resumed runs keep their request.
+func (r *Runner) runHumanStep(ctx context.Context, sc *stepContext) stepOutcome {
+	e := sc.exec
+	step := sc.step
+	repo := db.NewApprovalRepository(r.DB)
+
+	var timeout time.Duration
+	if step.Timeout != "" {
+		parsed, err := time.ParseDuration(step.Timeout)
+		if err != nil {
+			return failedOutcome(fmt.Errorf("invalid timeout %q: %w", step.Timeout, err))
+		}
+		timeout = parsed
+	}
+
+	approval, err := repo.GetPendingForWorkflowStep(ctx, e.run.ID, step.ID)
+	if errors.Is(err, db.ErrApprovalNotFound) {
+		approval, err = r.requestApproval(ctx, sc)
+	}
+	if err != nil {
+		return failedOutcome(fmt.Errorf("approval: %w", err))
+	}
+
+	sc.rec.Status = models.WorkflowStepStatusWaiting
+	if err := e.repo.UpdateStep(ctx, sc.rec); err != nil {
+		r.Logger.Warn().Err(err).Str("step", step.ID).Msg("failed to record waiting step")
+	}
+	sc.log.WriteLine(fmt.Sprintf("waiting for approval %s (forge workflow approve|reject %s %s)", approval.ID, shortRunID(e.run.ID), step.ID))
+
+	var deadline <-chan time.Time
+	if timeout > 0 {
+		timer := time.NewTimer(timeout)
+		defer timer.Stop()
+		deadline = timer.C
+	}
+
+	interval := r.ApprovalPollInterval
+	if interval <= 0 {
+		interval = defaultApprovalPollInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		current, err := repo.Get(context.WithoutCancel(ctx), approval.ID)
+		if err != nil {
+			return failedOutcome(fmt.Errorf("approval: %w", err))
+		}
+		if current.Status != models.ApprovalStatusPending {
+			return approvalOutcome(current)
+		}
+
+		select {
+		case <-ctx.Done():
+			// Expire the request so a resumed run asks again instead of showing a stale gate.
+			r.expireApproval(context.WithoutCancel(ctx), repo, approval.ID, "workflow canceled")
+			return failedOutcome(ctx.Err())
+		case <-deadline:
+			if !r.expireApproval(ctx, repo, approval.ID, "timed out") {
+				continue
+			}
+			return failedOutcome(fmt.Errorf("approval timed out after %s", timeout))
+		case <-ticker.C:
+		}
+	}
+}
+
+func (r *Runner) requestApproval(ctx context.Context, sc *stepContext) (*models.Approval, error) {
+	prompt := ResolveStepPrompt(sc.exec.wf, sc.step)
+	text := prompt.Inline
+	if text == "" && prompt.Path != "" {
+		data, err := os.ReadFile(prompt.Path)
+		if err != nil {
+			return nil, fmt.Errorf("prompt not found: %s", prompt.Path)
+		}
+		text = string(data)
+	}
+	text, err := Interpolate(strings.TrimSpace(text), sc.scope)
+	if err != nil {
+		return nil, fmt.Errorf("prompt: %w", err)
+	}
+
+	details, err := json.Marshal(humanRequest{
+		Workflow: sc.exec.wf.Name,
+		RunID:    sc.exec.run.ID,
+		StepID:   sc.step.ID,
+		Prompt:   text,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	approval := &models.Approval{
+		WorkflowRunID:  sc.exec.run.ID,
+		WorkflowStepID: sc.step.ID,
+		RequestType:    models.ApprovalRequestWorkflowStep,
+		RequestDetails: details,
+		Status:         models.ApprovalStatusPending,
+	}
+	if err := db.NewApprovalRepository(r.DB).Create(ctx, approval); err != nil {
+		return nil, err
+	}
+	sc.log.WriteLine("approval requested: " + text)
+	return approval, nil
+}
+
+// expireApproval marks a pending approval expired. It reports false when the
+// approval was resolved concurrently, in which case that decision wins.
+func (r *Runner) expireApproval(ctx context.Context, repo *db.ApprovalRepository, id, note string) bool {
+	err := repo.Resolve(ctx, id, models.ApprovalStatusExpired, approvalResolver, note)
+	if errors.Is(err, db.ErrApprovalNotPending) {
+		return false
+	}
+	if err != nil {
+		r.Logger.Warn().Err(err).Str("approval", id).Msg("failed to expire approval")
+	}
+	return true
+}
+
+// approvalOutcome converts a resolved approval into the human step result.
+// The approver's note is exposed as steps.<id>.outputs.note.
+func approvalOutcome(approval *models.Approval) stepOutcome {
+	outputs := map[string]any{
+		"approval_id": approval.ID,
+		"approved":    approval.Status == models.ApprovalStatusApproved,
+		"decision":    string(approval.Status),
+		"note":        approval.ResolutionNote,
+		"resolved_by": approval.ResolvedBy,
+	}
+
+	switch approval.Status {
+	case models.ApprovalStatusApproved:
+		return stepOutcome{status: models.WorkflowStepStatusSuccess, outputs: outputs}
+	case models.ApprovalStatusDenied:
+		reason := "rejected"
+		if approval.ResolvedBy != "" {
+			reason += " by " + approval.ResolvedBy
+		}
+		if approval.ResolutionNote != "" {
+			reason += ": " + approval.ResolutionNote
+		}
+		return stepOutcome{status: models.WorkflowStepStatusFailed, outputs: outputs, err: errors.New(reason)}
+	default:
+		return stepOutcome{status: models.WorkflowStepStatusFailed, outputs: outputs, err: fmt.Errorf("approval %s", approval.Status)}
+	}
+}
+
+// ResolveApproval approves or rejects the pending approval of a human step.
+// The process executing the run picks up the decision on its next poll. When that
+// process is gone, the step is settled here and the returned flag is true: the
+// caller must execute the run again so downstream steps continue.
+func (r *Runner) ResolveApproval(ctx context.Context, runID, stepID string, status models.ApprovalStatus, resolvedBy, note string) (*models.Approval, bool, error) {
+	if r.DB == nil {
+		return nil, false, errors.New("workflow runner requires database")
+	}
+	if status != models.ApprovalStatusApproved && status != models.ApprovalStatusDenied {
+		return nil, false, fmt.Errorf("invalid approval decision %q", status)
+	}
+
+	runRepo := db.NewWorkflowRunRepository(r.DB)
+	run, err := runRepo.Get(ctx, runID)
+	if err != nil {
+		return nil, false, err
+	}
+
+	approvals := db.NewApprovalRepository(r.DB)
+	approval, err := approvals.GetPendingForWorkflowStep(ctx, run.ID, stepID)
+	if err != nil {
+		if errors.Is(err, db.ErrApprovalNotFound) {
+			return nil, false, fmt.Errorf("%w for step %s in run %s", ErrNoPendingApproval, stepID, run.ID)
+		}
+		return nil, false, err
+	}
+	if err := approvals.Resolve(ctx, approval.ID, status, resolvedBy, note); err != nil {
+		return nil, false, err
+	}
+	approval, err = approvals.Get(ctx, approval.ID)
+	if err != nil {
+		return nil, false, err
+	}
+
+	if pid, ok := runPID(run); ok && processAlive(pid) {
+		return approval, false, nil
+	}
+
+	rec, err := runRepo.GetStep(ctx, run.ID, stepID)
+	if err != nil {
+		return nil, false, err
+	}
+	outcome := approvalOutcome(approval)
+	now := time.Now().UTC()
+	rec.Status = outcome.status
+	rec.Outputs = outcome.outputs
+	rec.Error = ""
+	if outcome.err != nil {
+		rec.Error = outcome.err.Error()
+	}
+	rec.FinishedAt = &now
+	if err := runRepo.UpdateStep(ctx, rec); err != nil {
+		return nil, false, err
+	}
+	return approval, true, nil
+}
+
+func shortRunID(id string) string {
+	if len(id) > 8 {
+		return id[:8]
+	}
+	return id
+}
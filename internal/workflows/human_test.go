@@ -0,0 +1,156 @@
+package workflows
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/tOgg1/forge/internal/db"
+	"github.com/tOgg1/forge/internal/models"
+)
+
+func humanWorkflow(repoDir string) *Workflow {
+	return &Workflow{
+		Name:   "gated",
+		Source: filepath.Join(repoDir, ".forge", "workflows", "gated.toml"),
+		Steps: []WorkflowStep{
+			{ID: "build", Type: StepTypeBash, Cmd: "echo built"},
+			{ID: "signoff", Type: StepTypeHuman, Prompt: "Ship ${steps.build.outputs.stdout}?", DependsOn: []string{"build"}},
+			{
+				ID:        "deploy",
+				Type:      StepTypeBash,
+				Cmd:       "echo deploy-$FORGE_INPUT_NOTE",
+				DependsOn: []string{"signoff"},
+				Inputs:    map[string]any{"note": "${steps.signoff.outputs.note}"},
+			},
+		},
+	}
+}
+
+// waitForApproval polls until the human step has requested approval.
+func waitForApproval(t *testing.T, database *db.DB, runID, stepID string) *models.Approval {
+	t.Helper()
+
+	repo := db.NewApprovalRepository(database)
+	deadline := time.Now().Add(10 * time.Second)
+	for time.Now().Before(deadline) {
+		approval, err := repo.GetPendingForWorkflowStep(context.Background(), runID, stepID)
+		if err == nil {
+			return approval
+		}
+		if !errors.Is(err, db.ErrApprovalNotFound) {
+			t.Fatalf("get approval: %v", err)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("timed out waiting for approval on step %s", stepID)
+	return nil
+}
+
+func runInBackground(t *testing.T, runner *Runner, wf *Workflow) (*models.WorkflowRun, <-chan *models.WorkflowRun) {
+	t.Helper()
+
+	run, err := runner.CreateRun(context.Background(), wf, nil)
+	if err != nil {
+		t.Fatalf("create run: %v", err)
+	}
+	done := make(chan *models.WorkflowRun, 1)
+	go func() {
+		finished, err := runner.Execute(context.Background(), run, wf)
+		if err != nil {
+			t.Errorf("execute run: %v", err)
+		}
+		done <- finished
+	}()
+	return run, done
+}
+
+func TestHumanStepApprovalPassesNoteDownstream(t *testing.T) {
+	runner, database, repoDir := newTestRunner(t)
+	runner.ApprovalPollInterval = 10 * time.Millisecond
+
+	run, done := runInBackground(t, runner, humanWorkflow(repoDir))
+	approval := waitForApproval(t, database, run.ID, "signoff")
+	if !strings.Contains(string(approval.RequestDetails), "Ship built?") {
+		t.Fatalf("expected interpolated prompt in request, got %s", approval.RequestDetails)
+	}
+	if status := stepsByID(t, database, run.ID)["signoff"].Status; status != models.WorkflowStepStatusWaiting {
+		t.Fatalf("expected signoff waiting, got %s", status)
+	}
+
+	if _, orphaned, err := runner.ResolveApproval(context.Background(), run.ID, "signoff", models.ApprovalStatusApproved, "alice", "lgtm"); err != nil || orphaned {
+		t.Fatalf("resolve approval: orphaned=%t err=%v", orphaned, err)
+	}
+
+	finished := <-done
+	if finished.Status != models.WorkflowRunStatusSuccess {
+		t.Fatalf("expected success, got %s (%s)", finished.Status, finished.Error)
+	}
+	steps := stepsByID(t, database, run.ID)
+	if steps["signoff"].Outputs["resolved_by"] != "alice" {
+		t.Fatalf("expected approver in outputs, got %v", steps["signoff"].Outputs)
+	}
+	if steps["deploy"].Outputs["stdout"] != "deploy-lgtm" {
+		t.Fatalf("expected note passed to deploy, got %v", steps["deploy"].Outputs["stdout"])
+	}
+}
+
+func TestHumanStepRejectionFailsRun(t *testing.T) {
+	runner, database, repoDir := newTestRunner(t)
+	runner.ApprovalPollInterval = 10 * time.Millisecond
+
+	run, done := runInBackground(t, runner, humanWorkflow(repoDir))
+	waitForApproval(t, database, run.ID, "signoff")
+
+	if _, _, err := runner.ResolveApproval(context.Background(), run.ID, "signoff", models.ApprovalStatusDenied, "bob", "not today"); err != nil {
+		t.Fatalf("resolve approval: %v", err)
+	}
+
+	finished := <-done
+	if finished.Status != models.WorkflowRunStatusFailed {
+		t.Fatalf("expected failed, got %s", finished.Status)
+	}
+	steps := stepsByID(t, database, run.ID)
+	if !strings.Contains(steps["signoff"].Error, "rejected by bob: not today") {
+		t.Fatalf("unexpected signoff error %q", steps["signoff"].Error)
+	}
+	if steps["deploy"].Status != models.WorkflowStepStatusSkipped {
+		t.Fatalf("expected deploy skipped, got %s", steps["deploy"].Status)
+	}
+
+	if _, _, err := runner.ResolveApproval(context.Background(), run.ID, "signoff", models.ApprovalStatusApproved, "bob", ""); !errors.Is(err, ErrNoPendingApproval) {
+		t.Fatalf("expected ErrNoPendingApproval, got %v", err)
+	}
+}
+
+func TestHumanStepTimeoutExpiresApproval(t *testing.T) {
+	runner, database, repoDir := newTestRunner(t)
+	runner.ApprovalPollInterval = 10 * time.Millisecond
+
+	wf := &Workflow{
+		Name:   "timeout",
+		Source: filepath.Join(repoDir, ".forge", "workflows", "timeout.toml"),
+		Steps: []WorkflowStep{
+			{ID: "signoff", Type: StepTypeHuman, Prompt: "Approve?", Timeout: "50ms"},
+		},
+	}
+
+	run, err := runner.Run(context.Background(), wf, nil)
+	if err != nil {
+		t.Fatalf("run workflow: %v", err)
+	}
+	if run.Status != models.WorkflowRunStatusFailed {
+		t.Fatalf("expected failed, got %s", run.Status)
+	}
+
+	approvals, err := db.NewApprovalRepository(database).ListByWorkflowRun(context.Background(), run.ID)
+	if err != nil {
+		t.Fatalf("list approvals: %v", err)
+	}
+	if len(approvals) != 1 || approvals[0].Status != models.ApprovalStatusExpired {
+		t.Fatalf("expected one expired approval, got %+v", approvals)
+	}
+}
@@ -0,0 +1,66 @@
+package workflows
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// RunLogDir returns the log directory for a workflow run.
+func RunLogDir(dataDir, runID string) string {
+	return filepath.Join(dataDir, "logs", "workflows", runID)
+}
+
+// RunLogPath returns the scheduler log path for a workflow run.
+func RunLogPath(dataDir, runID string) string {
+	return filepath.Join(RunLogDir(dataDir, runID), "run.log")
+}
+
+// StepLogPath returns the log path for a workflow step.
+func StepLogPath(dataDir, runID, stepID string) string {
+	return filepath.Join(RunLogDir(dataDir, runID), "steps", stepID+".log")
+}
+
+type stepLogger struct {
+	file *os.File
+	mu   sync.Mutex
+	w    *bufio.Writer
+}
+
+func newStepLogger(path string) (*stepLogger, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, err
+	}
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	return &stepLogger{file: file, w: bufio.NewWriter(file)}, nil
+}
+
+func (l *stepLogger) Write(p []byte) (int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	n, err := l.w.Write(p)
+	if err != nil {
+		return n, err
+	}
+	return n, l.w.Flush()
+}
+
+func (l *stepLogger) WriteLine(message string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	stamp := time.Now().UTC().Format(time.RFC3339)
+	_, _ = l.w.WriteString("[" + stamp + "] " + message + "\n")
+	_ = l.w.Flush()
+}
+
+func (l *stepLogger) Close() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	_ = l.w.Flush()
+	_ = l.file.Close()
+}
@@ -35,6 +35,14 @@ func NormalizeWorkflow(wf *Workflow) *Workflow {
 		step.JobName = strings.TrimSpace(step.JobName)
 		step.WorkflowName = strings.TrimSpace(step.WorkflowName)
 		step.Timeout = strings.TrimSpace(step.Timeout)
+		step.RetryBackoff = strings.TrimSpace(step.RetryBackoff)
+		step.Each = StepType(strings.ToLower(strings.TrimSpace(string(step.Each))))
+		step.ItemsCmd = strings.TrimSpace(step.ItemsCmd)
+		step.Fanout = strings.TrimSpace(step.Fanout)
+		if step.ItemsBeads != nil {
+			step.ItemsBeads.Status = strings.TrimSpace(step.ItemsBeads.Status)
+			step.ItemsBeads.Type = strings.TrimSpace(step.ItemsBeads.Type)
+		}
 
 		if step.Stop != nil {
 			step.Stop.Expr = strings.TrimSpace(step.Stop.Expr)
@@ -0,0 +1,124 @@
+package workflows
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"github.com/tOgg1/forge/internal/db"
+	"github.com/tOgg1/forge/internal/models"
+)
+
+// parasite tracks a running alive_with step so it can be stopped when a host finishes.
+// The loop ID is set from the step goroutine; the stop request from the scheduler.
+type parasite struct {
+	mu       sync.Mutex
+	loopID   string
+	stopHost string
+}
+
+func newParasites(wf *Workflow) map[string]*parasite {
+	parasites := make(map[string]*parasite)
+	for _, step := range wf.Steps {
+		if len(step.AliveWith) > 0 {
+			parasites[step.ID] = &parasite{}
+		}
+	}
+	return parasites
+}
+
+// hostReadiness decides whether a parasite can start: every host must be running.
+// A host that already finished means the parasite has nothing to live alongside.
+func (e *execution) hostReadiness(step WorkflowStep) (stepAction, string) {
+	for _, host := range step.AliveWith {
+		status := e.steps[host].Status
+		switch {
+		case status == models.WorkflowStepStatusRunning || status == models.WorkflowStepStatusWaiting:
+			continue
+		case status.IsTerminal():
+			return stepActionSkip, fmt.Sprintf("alive_with host %s %s before start", host, status)
+		default:
+			return stepActionWait, ""
+		}
+	}
+	return stepActionStart, ""
+}
+
+// attachParasiteLoop records the loop backing a parasite step. If a host already
+// finished, the stop is queued right away.
+func (e *execution) attachParasiteLoop(ctx context.Context, stepID, loopID string) {
+	p := e.parasites[stepID]
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.loopID = loopID
+	if p.stopHost != "" {
+		e.queueParasiteStop(ctx, stepID, loopID, p.stopHost)
+	}
+}
+
+// detachParasiteLoop forgets a parasite's loop once its step is done, so no
+// stop is queued for a loop that no longer exists.
+func (e *execution) detachParasiteLoop(stepID string) {
+	p := e.parasites[stepID]
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.loopID = ""
+}
+
+// stopParasites asks every running parasite of hostID to stop gracefully.
+func (e *execution) stopParasites(ctx context.Context, hostID string) {
+	for _, step := range e.wf.Steps {
+		p := e.parasites[step.ID]
+		if p == nil || e.steps[step.ID].Status != models.WorkflowStepStatusRunning || !containsString(step.AliveWith, hostID) {
+			continue
+		}
+
+		p.mu.Lock()
+		if p.stopHost == "" {
+			p.stopHost = hostID
+			e.log.WriteLine(fmt.Sprintf("step %s stopping: alive_with host %s finished", step.ID, hostID))
+			if p.loopID != "" {
+				e.queueParasiteStop(ctx, step.ID, p.loopID, hostID)
+			}
+		}
+		p.mu.Unlock()
+	}
+}
+
+func (e *execution) queueParasiteStop(ctx context.Context, stepID, loopID, hostID string) {
+	payload, err := json.Marshal(models.StopPayload{Reason: fmt.Sprintf("alive_with host %s finished", hostID)})
+	if err != nil {
+		return
+	}
+	item := &models.LoopQueueItem{Type: models.LoopQueueItemStopGraceful, Payload: payload}
+	if err := db.NewLoopQueueRepository(e.runner.DB).Enqueue(context.WithoutCancel(ctx), loopID, item); err != nil {
+		e.runner.Logger.Warn().Err(err).Str("step", stepID).Msg("failed to queue parasite stop")
+	}
+}
+
+// parasiteStoppedBy returns the host whose completion stopped the parasite, if any.
+func (e *execution) parasiteStoppedBy(stepID string) string {
+	p := e.parasites[stepID]
+	if p == nil {
+		return ""
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.stopHost
+}
+
+func containsString(values []string, target string) bool {
+	for _, value := range values {
+		if value == target {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,85 @@
+package workflows
+
+import (
+	"context"
+	"io"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/tOgg1/forge/internal/models"
+)
+
+func TestParasiteStopsWhenHostFinishes(t *testing.T) {
+	runner, database, repoDir := newTestRunner(t)
+	createTestProfile(t, database)
+
+	var iterations atomic.Int32
+	runner.LoopExec = func(ctx context.Context, profile models.Profile, promptPath, promptContent, workDir string, output io.Writer) (int, string, error) {
+		iterations.Add(1)
+		return 0, "watching", nil
+	}
+
+	wf := &Workflow{
+		Name:   "watch",
+		Source: filepath.Join(repoDir, ".forge", "workflows", "watch.toml"),
+		Steps: []WorkflowStep{
+			{ID: "build", Type: StepTypeBash, Cmd: "sleep 0.5"},
+			{ID: "monitor", Type: StepTypeLoop, Prompt: "watch the build", Profile: "pi-default", Interval: "1m", AliveWith: []string{"build"}},
+			{ID: "report", Type: StepTypeBash, Cmd: "echo ${steps.monitor.outputs.stopped_by}", DependsOn: []string{"monitor"}},
+		},
+	}
+
+	started := time.Now()
+	run, err := runner.Run(context.Background(), wf, nil)
+	if err != nil {
+		t.Fatalf("run workflow: %v", err)
+	}
+	if run.Status != models.WorkflowRunStatusSuccess {
+		t.Fatalf("expected success, got %s (%s)", run.Status, run.Error)
+	}
+	if elapsed := time.Since(started); elapsed > 30*time.Second {
+		t.Fatalf("parasite waited out its interval (%s)", elapsed)
+	}
+	if iterations.Load() != 1 {
+		t.Fatalf("expected a single iteration before the stop, got %d", iterations.Load())
+	}
+
+	steps := stepsByID(t, database, run.ID)
+	monitor := steps["monitor"]
+	if monitor.Status != models.WorkflowStepStatusSuccess || monitor.Outputs["stopped_by"] != "build" {
+		t.Fatalf("expected monitor stopped by build, got %s %v", monitor.Status, monitor.Outputs)
+	}
+	if monitor.StartedAt.Before(*steps["build"].StartedAt) || monitor.FinishedAt.Before(*steps["build"].FinishedAt) {
+		t.Fatalf("expected monitor to live inside build: build=%v-%v monitor=%v-%v",
+			steps["build"].StartedAt, steps["build"].FinishedAt, monitor.StartedAt, monitor.FinishedAt)
+	}
+	if steps["report"].Outputs["stdout"] != "build" {
+		t.Fatalf("expected stopped_by passed downstream, got %v", steps["report"].Outputs["stdout"])
+	}
+}
+
+func TestParasiteSkippedWhenHostAlreadyFinished(t *testing.T) {
+	runner, database, repoDir := newTestRunner(t)
+	createTestProfile(t, database)
+
+	wf := &Workflow{
+		Name:   "late",
+		Source: filepath.Join(repoDir, ".forge", "workflows", "late.toml"),
+		Steps: []WorkflowStep{
+			{ID: "build", Type: StepTypeBash, Cmd: "echo built"},
+			{ID: "monitor", Type: StepTypeLoop, Prompt: "watch", Profile: "pi-default", DependsOn: []string{"build"}, AliveWith: []string{"build"}},
+		},
+	}
+
+	run, err := runner.Run(context.Background(), wf, nil)
+	if err != nil {
+		t.Fatalf("run workflow: %v", err)
+	}
+
+	monitor := stepsByID(t, database, run.ID)["monitor"]
+	if monitor.Status != models.WorkflowStepStatusSkipped || monitor.Error != "alive_with host build success before start" {
+		t.Fatalf("expected monitor skipped, got %s (%s)", monitor.Status, monitor.Error)
+	}
+}
@@ -0,0 +1,227 @@
+package workflows
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"syscall"
+
+	"github.com/tOgg1/forge/internal/db"
+	"github.com/tOgg1/forge/internal/models"
+)
+
+// ErrRunActive is returned when a run is still being executed by a live process.
+var ErrRunActive = errors.New("workflow run is still active")
+
+// PrepareResume resets every step that did not succeed so the run can re-enter the DAG.
+// Steps that already succeeded keep their checkpointed outputs and are not re-run.
+func (r *Runner) PrepareResume(ctx context.Context, runID string) (*models.WorkflowRun, error) {
+	run, wf, err := r.loadRunForRestart(ctx, runID)
+	if err != nil {
+		return nil, err
+	}
+	if run.Status == models.WorkflowRunStatusSuccess {
+		return nil, fmt.Errorf("workflow run %s already succeeded; use retry to re-run a step", run.ID)
+	}
+
+	repo := db.NewWorkflowRunRepository(r.DB)
+	steps, err := repo.ListSteps(ctx, run.ID)
+	if err != nil {
+		return nil, err
+	}
+	known := make(map[string]struct{}, len(wf.Steps))
+	for _, step := range wf.Steps {
+		known[step.ID] = struct{}{}
+	}
+	for _, rec := range steps {
+		if _, ok := known[rec.StepID]; !ok || rec.Status == models.WorkflowStepStatusSuccess {
+			continue
+		}
+		if err := resetStep(ctx, repo, rec); err != nil {
+			return nil, err
+		}
+	}
+
+	return r.markRestarted(ctx, repo, run)
+}
+
+// PrepareRetry resets a step and everything downstream of it so they run again.
+func (r *Runner) PrepareRetry(ctx context.Context, runID, stepID string) (*models.WorkflowRun, error) {
+	run, wf, err := r.loadRunForRestart(ctx, runID)
+	if err != nil {
+		return nil, err
+	}
+
+	var target *WorkflowStep
+	for i := range wf.Steps {
+		if wf.Steps[i].ID == stepID {
+			target = &wf.Steps[i]
+			break
+		}
+	}
+	if target == nil {
+		return nil, fmt.Errorf("step %q not found in workflow %s", stepID, wf.Name)
+	}
+
+	repo := db.NewWorkflowRunRepository(r.DB)
+	steps, err := repo.ListSteps(ctx, run.ID)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[string]*models.WorkflowStepRun, len(steps))
+	for _, rec := range steps {
+		byID[rec.StepID] = rec
+	}
+
+	for _, dep := range target.DependsOn {
+		if rec, ok := byID[dep]; ok && rec.Status != models.WorkflowStepStatusSuccess {
+			return nil, fmt.Errorf("dependency %s of step %s is %s; resume the run instead", dep, stepID, rec.Status)
+		}
+	}
+
+	for _, id := range downstreamSteps(wf, stepID) {
+		rec, ok := byID[id]
+		if !ok {
+			continue
+		}
+		if err := resetStep(ctx, repo, rec); err != nil {
+			return nil, err
+		}
+	}
+
+	return r.markRestarted(ctx, repo, run)
+}
+
+// Resume prepares and executes an interrupted or failed run.
+func (r *Runner) Resume(ctx context.Context, runID string) (*models.WorkflowRun, error) {
+	run, err := r.PrepareResume(ctx, runID)
+	if err != nil {
+		return nil, err
+	}
+	return r.ExecuteRun(ctx, run.ID)
+}
+
+// Retry re-runs a step and its downstream steps.
+func (r *Runner) Retry(ctx context.Context, runID, stepID string) (*models.WorkflowRun, error) {
+	run, err := r.PrepareRetry(ctx, runID, stepID)
+	if err != nil {
+		return nil, err
+	}
+	return r.ExecuteRun(ctx, run.ID)
+}
+
+func (r *Runner) loadRunForRestart(ctx context.Context, runID string) (*models.WorkflowRun, *Workflow, error) {
+	if r.DB == nil || r.Config == nil {
+		return nil, nil, errors.New("workflow runner requires database and config")
+	}
+
+	run, err := db.NewWorkflowRunRepository(r.DB).Get(ctx, runID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if run.ParentRunID != "" {
+		return nil, nil, fmt.Errorf("workflow run %s is a child of %s; resume the parent run instead", run.ID, run.ParentRunID)
+	}
+	if run.Status == models.WorkflowRunStatusRunning {
+		if pid, ok := runPID(run); ok && pid != os.Getpid() && processAlive(pid) {
+			return nil, nil, fmt.Errorf("%w (pid %d)", ErrRunActive, pid)
+		}
+	}
+	if run.WorkflowSource == "" {
+		return nil, nil, fmt.Errorf("workflow run %s has no workflow source", run.ID)
+	}
+
+	wf, err := LoadWorkflow(run.WorkflowSource)
+	if err != nil {
+		return nil, nil, err
+	}
+	validated, err := ValidateWorkflow(wf)
+	if err != nil {
+		return nil, nil, err
+	}
+	return run, validated, nil
+}
+
+func (r *Runner) markRestarted(ctx context.Context, repo *db.WorkflowRunRepository, run *models.WorkflowRun) (*models.WorkflowRun, error) {
+	run.Status = models.WorkflowRunStatusPending
+	run.Error = ""
+	run.FinishedAt = nil
+	run.Outputs = nil
+	if run.Metadata != nil {
+		delete(run.Metadata, "pid")
+	}
+	if err := repo.Update(ctx, run); err != nil {
+		return nil, err
+	}
+	return run, nil
+}
+
+func resetStep(ctx context.Context, repo *db.WorkflowRunRepository, rec *models.WorkflowStepRun) error {
+	rec.Status = models.WorkflowStepStatusPending
+	rec.Outputs = nil
+	rec.ExitCode = nil
+	rec.Error = ""
+	rec.StartedAt = nil
+	rec.FinishedAt = nil
+	return repo.UpdateStep(ctx, rec)
+}
+
+// downstreamSteps returns stepID and every step that transitively depends on it,
+// including logic branch targets, joins, and alive_with parasites.
+func downstreamSteps(wf *Workflow, stepID string) []string {
+	dependents := make(map[string][]string)
+	for _, step := range wf.Steps {
+		for _, dep := range step.DependsOn {
+			dependents[dep] = append(dependents[dep], step.ID)
+		}
+		if step.Type == StepTypeJoin && step.Fanout != "" {
+			dependents[step.Fanout] = append(dependents[step.Fanout], step.ID)
+		}
+		for _, host := range step.AliveWith {
+			dependents[host] = append(dependents[host], step.ID)
+		}
+		if step.Type == StepTypeLogic {
+			dependents[step.ID] = append(dependents[step.ID], step.Then...)
+			dependents[step.ID] = append(dependents[step.ID], step.Else...)
+		}
+	}
+
+	seen := map[string]struct{}{stepID: {}}
+	order := []string{stepID}
+	for i := 0; i < len(order); i++ {
+		for _, next := range dependents[order[i]] {
+			if _, ok := seen[next]; ok {
+				continue
+			}
+			seen[next] = struct{}{}
+			order = append(order, next)
+		}
+	}
+	return order
+}
+
+func runPID(run *models.WorkflowRun) (int, bool) {
+	if run == nil || run.Metadata == nil {
+		return 0, false
+	}
+	switch v := run.Metadata["pid"].(type) {
+	case float64:
+		return int(v), true
+	case int:
+		return v, true
+	default:
+		return 0, false
+	}
+}
+
+func processAlive(pid int) bool {
+	if pid <= 0 {
+		return false
+	}
+	process, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+	return process.Signal(syscall.Signal(0)) == nil
+}
@@ -0,0 +1,157 @@
+package workflows
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/tOgg1/forge/internal/models"
+)
+
+func writeWorkflowFile(t *testing.T, repoDir, name, content string) *Workflow {
+	t.Helper()
+
+	path := filepath.Join(repoDir, ".forge", "workflows", name+".toml")
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("write workflow: %v", err)
+	}
+	wf, err := LoadWorkflow(path)
+	if err != nil {
+		t.Fatalf("load workflow: %v", err)
+	}
+	return wf
+}
+
+func countLines(t *testing.T, path string) int {
+	t.Helper()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read %s: %v", path, err)
+	}
+	return len(strings.Split(strings.TrimSpace(string(data)), "\n"))
+}
+
+const resumeWorkflow = `name = "resumable"
+
+[[steps]]
+id = "expensive"
+type = "bash"
+cmd = "echo ran >> expensive.count"
+
+[[steps]]
+id = "flaky"
+type = "bash"
+cmd = "test -f ready"
+depends_on = ["expensive"]
+
+[[steps]]
+id = "report"
+type = "bash"
+cmd = "echo done"
+depends_on = ["flaky"]
+`
+
+func TestRunnerResumeSkipsCompletedSteps(t *testing.T) {
+	runner, database, repoDir := newTestRunner(t)
+	wf := writeWorkflowFile(t, repoDir, "resumable", resumeWorkflow)
+
+	run, err := runner.Run(context.Background(), wf, nil)
+	if err != nil {
+		t.Fatalf("run workflow: %v", err)
+	}
+	if run.Status != models.WorkflowRunStatusFailed {
+		t.Fatalf("expected failed run, got %s", run.Status)
+	}
+	if status := stepsByID(t, database, run.ID)["report"].Status; status != models.WorkflowStepStatusSkipped {
+		t.Fatalf("expected report skipped, got %s", status)
+	}
+
+	if err := os.WriteFile(filepath.Join(repoDir, "ready"), []byte("ok"), 0o644); err != nil {
+		t.Fatalf("write ready: %v", err)
+	}
+
+	resumed, err := runner.Resume(context.Background(), run.ID)
+	if err != nil {
+		t.Fatalf("resume: %v", err)
+	}
+	if resumed.Status != models.WorkflowRunStatusSuccess {
+		t.Fatalf("expected success after resume, got %s (%s)", resumed.Status, resumed.Error)
+	}
+	if got := countLines(t, filepath.Join(repoDir, "expensive.count")); got != 1 {
+		t.Fatalf("expected expensive step to run once, ran %d times", got)
+	}
+
+	steps := stepsByID(t, database, run.ID)
+	if steps["flaky"].Attempt != 2 {
+		t.Fatalf("expected flaky attempt 2, got %d", steps["flaky"].Attempt)
+	}
+	if steps["expensive"].Attempt != 1 {
+		t.Fatalf("expected expensive attempt 1, got %d", steps["expensive"].Attempt)
+	}
+
+	if _, err := runner.PrepareResume(context.Background(), run.ID); err == nil {
+		t.Fatalf("expected resume of successful run to fail")
+	}
+}
+
+func TestRunnerRetryInvalidatesDownstream(t *testing.T) {
+	runner, database, repoDir := newTestRunner(t)
+	wf := writeWorkflowFile(t, repoDir, "resumable", resumeWorkflow)
+	if err := os.WriteFile(filepath.Join(repoDir, "ready"), []byte("ok"), 0o644); err != nil {
+		t.Fatalf("write ready: %v", err)
+	}
+
+	run, err := runner.Run(context.Background(), wf, nil)
+	if err != nil {
+		t.Fatalf("run workflow: %v", err)
+	}
+	if run.Status != models.WorkflowRunStatusSuccess {
+		t.Fatalf("expected success, got %s (%s)", run.Status, run.Error)
+	}
+
+	retried, err := runner.Retry(context.Background(), run.ID, "flaky")
+	if err != nil {
+		t.Fatalf("retry: %v", err)
+	}
+	if retried.Status != models.WorkflowRunStatusSuccess {
+		t.Fatalf("expected success after retry, got %s (%s)", retried.Status, retried.Error)
+	}
+
+	steps := stepsByID(t, database, run.ID)
+	if steps["expensive"].Attempt != 1 {
+		t.Fatalf("expected upstream step untouched, got attempt %d", steps["expensive"].Attempt)
+	}
+	if steps["flaky"].Attempt != 2 || steps["report"].Attempt != 2 {
+		t.Fatalf("expected flaky and report re-run, got %d and %d", steps["flaky"].Attempt, steps["report"].Attempt)
+	}
+
+	if _, err := runner.PrepareRetry(context.Background(), run.ID, "missing"); err == nil {
+		t.Fatalf("expected error for unknown step")
+	}
+}
+
+func TestRunnerStepRetries(t *testing.T) {
+	runner, database, repoDir := newTestRunner(t)
+
+	wf := &Workflow{
+		Name:   "retrying",
+		Source: filepath.Join(repoDir, ".forge", "workflows", "retrying.toml"),
+		Steps: []WorkflowStep{
+			{ID: "eventually", Type: StepTypeBash, Cmd: "echo x >> tries; test $(wc -l < tries) -ge 3", Retries: 2, RetryBackoff: "10ms"},
+		},
+	}
+
+	run, err := runner.Run(context.Background(), wf, nil)
+	if err != nil {
+		t.Fatalf("run workflow: %v", err)
+	}
+	if run.Status != models.WorkflowRunStatusSuccess {
+		t.Fatalf("expected success, got %s (%s)", run.Status, run.Error)
+	}
+	if attempt := stepsByID(t, database, run.ID)["eventually"].Attempt; attempt != 3 {
+		t.Fatalf("expected 3 attempts, got %d", attempt)
+	}
+}
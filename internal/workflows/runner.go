@@ -0,0 +1,549 @@
+package workflows
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/tOgg1/forge/internal/config"
+	"github.com/tOgg1/forge/internal/db"
+	"github.com/tOgg1/forge/internal/events"
+	"github.com/tOgg1/forge/internal/logging"
+	"github.com/tOgg1/forge/internal/loop"
+	"github.com/tOgg1/forge/internal/models"
+)
+
+// maxWorkflowDepth bounds nested workflow steps to catch accidental recursion.
+const maxWorkflowDepth = 5
+
+// Runner executes workflow runs and records run/step state.
+type Runner struct {
+	DB     *db.DB
+	Config *config.Config
+	Logger zerolog.Logger
+	// LoopExec overrides the harness execution used by agent and loop steps.
+	LoopExec loop.ExecuteFunc
+	// ApprovalPollInterval controls how often human steps check for a decision.
+	ApprovalPollInterval time.Duration
+	// Publisher receives lifecycle events from the loops agent and loop steps run.
+	Publisher events.Publisher
+}
+
+// NewRunner creates a Runner with default dependencies.
+func NewRunner(database *db.DB, cfg *config.Config) *Runner {
+	return &Runner{
+		DB:                   database,
+		Config:               cfg,
+		Logger:               logging.Component("workflow"),
+		ApprovalPollInterval: defaultApprovalPollInterval,
+	}
+}
+
+// CreateRun validates a workflow and records a pending run for it.
+func (r *Runner) CreateRun(ctx context.Context, wf *Workflow, inputs map[string]any) (*models.WorkflowRun, error) {
+	return r.createRun(ctx, wf, inputs, nil, "")
+}
+
+// Run creates a workflow run and executes it to completion.
+func (r *Runner) Run(ctx context.Context, wf *Workflow, inputs map[string]any) (*models.WorkflowRun, error) {
+	run, err := r.CreateRun(ctx, wf, inputs)
+	if err != nil {
+		return nil, err
+	}
+	return r.Execute(ctx, run, wf)
+}
+
+// ExecuteRun executes a previously created run, loading the workflow from its source file.
+func (r *Runner) ExecuteRun(ctx context.Context, runID string) (*models.WorkflowRun, error) {
+	if r.DB == nil || r.Config == nil {
+		return nil, errors.New("workflow runner requires database and config")
+	}
+
+	run, err := db.NewWorkflowRunRepository(r.DB).Get(ctx, runID)
+	if err != nil {
+		return nil, err
+	}
+	if run.WorkflowSource == "" {
+		return nil, fmt.Errorf("workflow run %s has no workflow source", run.ID)
+	}
+
+	wf, err := LoadWorkflow(run.WorkflowSource)
+	if err != nil {
+		return nil, err
+	}
+	return r.Execute(ctx, run, wf)
+}
+
+func (r *Runner) createRun(ctx context.Context, wf *Workflow, inputs map[string]any, parent *models.WorkflowRun, parentStepID string) (*models.WorkflowRun, error) {
+	if r.DB == nil || r.Config == nil {
+		return nil, errors.New("workflow runner requires database and config")
+	}
+
+	validated, err := ValidateWorkflow(wf)
+	if err != nil {
+		return nil, err
+	}
+
+	merged := make(map[string]any, len(validated.Inputs)+len(inputs))
+	for key, value := range validated.Inputs {
+		merged[key] = value
+	}
+	for key, value := range inputs {
+		merged[key] = value
+	}
+
+	repoPath := RepoRootFromWorkflow(validated)
+	if repoPath == "" && parent != nil {
+		repoPath = parent.RepoPath
+	}
+	if repoPath == "" {
+		if cwd, err := os.Getwd(); err == nil {
+			repoPath = cwd
+		}
+	}
+
+	depth := 0
+	run := &models.WorkflowRun{
+		WorkflowName:   validated.Name,
+		WorkflowSource: validated.Source,
+		RepoPath:       repoPath,
+		Status:         models.WorkflowRunStatusPending,
+		Inputs:         merged,
+	}
+	if parent != nil {
+		depth = runDepth(parent) + 1
+		run.ParentRunID = parent.ID
+		run.ParentStepID = parentStepID
+	}
+	run.Metadata = map[string]any{"depth": depth}
+
+	repo := db.NewWorkflowRunRepository(r.DB)
+	if err := repo.Create(ctx, run); err != nil {
+		return nil, err
+	}
+
+	for _, step := range validated.Steps {
+		if err := repo.CreateStep(ctx, &models.WorkflowStepRun{
+			RunID:    run.ID,
+			StepID:   step.ID,
+			StepType: string(step.Type),
+			Status:   models.WorkflowStepStatusPending,
+		}); err != nil {
+			return nil, err
+		}
+	}
+
+	return run, nil
+}
+
+// Execute runs the steps of a created run until the workflow settles.
+func (r *Runner) Execute(ctx context.Context, run *models.WorkflowRun, wf *Workflow) (*models.WorkflowRun, error) {
+	if r.DB == nil || r.Config == nil {
+		return nil, errors.New("workflow runner requires database and config")
+	}
+
+	validated, err := ValidateWorkflow(wf)
+	if err != nil {
+		return nil, err
+	}
+
+	e := &execution{
+		runner: r,
+		repo:   db.NewWorkflowRunRepository(r.DB),
+		run:    run,
+		wf:     validated,
+		steps:  make(map[string]*models.WorkflowStepRun),
+		gates:  logicGates(validated),
+
+		parasites: newParasites(validated),
+	}
+	if err := e.loadSteps(ctx); err != nil {
+		return nil, err
+	}
+
+	logger, err := newStepLogger(RunLogPath(r.Config.Global.DataDir, run.ID))
+	if err != nil {
+		return nil, err
+	}
+	defer logger.Close()
+	e.log = logger
+
+	now := time.Now().UTC()
+	run.Status = models.WorkflowRunStatusRunning
+	if run.StartedAt == nil {
+		run.StartedAt = &now
+	}
+	run.FinishedAt = nil
+	run.Error = ""
+	if run.Metadata == nil {
+		run.Metadata = make(map[string]any)
+	}
+	run.Metadata["pid"] = os.Getpid()
+	if err := e.repo.Update(ctx, run); err != nil {
+		return nil, err
+	}
+	logger.WriteLine(fmt.Sprintf("workflow %s started (run=%s)", validated.Name, run.ID))
+
+	env := e.env(nil)
+	var hookErr error
+	if validated.Hooks != nil {
+		hookErr = runHooks(ctx, validated.Hooks.Pre, run.RepoPath, env, logger)
+	}
+	if hookErr == nil {
+		e.schedule(ctx)
+		if validated.Hooks != nil {
+			hookErr = runHooks(ctx, validated.Hooks.Post, run.RepoPath, env, logger)
+		}
+	}
+
+	e.finish(ctx, hookErr)
+	return run, nil
+}
+
+type execution struct {
+	runner *Runner
+	repo   *db.WorkflowRunRepository
+	run    *models.WorkflowRun
+	wf     *Workflow
+	steps  map[string]*models.WorkflowStepRun
+	gates  map[string][]logicGate
+	log    *stepLogger
+
+	// parasites holds the alive_with steps, keyed by step ID.
+	parasites map[string]*parasite
+}
+
+// logicGate records that a step only runs when a logic step picks a branch.
+type logicGate struct {
+	stepID string
+	branch string
+}
+
+type stepAction int
+
+const (
+	stepActionWait stepAction = iota
+	stepActionStart
+	stepActionSkip
+	stepActionCancel
+	stepActionFail
+)
+
+func logicGates(wf *Workflow) map[string][]logicGate {
+	gates := make(map[string][]logicGate)
+	for _, step := range wf.Steps {
+		if step.Type != StepTypeLogic {
+			continue
+		}
+		for _, target := range step.Then {
+			gates[target] = append(gates[target], logicGate{stepID: step.ID, branch: "then"})
+		}
+		for _, target := range step.Else {
+			gates[target] = append(gates[target], logicGate{stepID: step.ID, branch: "else"})
+		}
+	}
+	return gates
+}
+
+func (e *execution) loadSteps(ctx context.Context) error {
+	existing, err := e.repo.ListSteps(ctx, e.run.ID)
+	if err != nil {
+		return err
+	}
+	for _, rec := range existing {
+		// A step left running or waiting belongs to a process that died; run it again.
+		if rec.Status == models.WorkflowStepStatusRunning || rec.Status == models.WorkflowStepStatusWaiting {
+			if err := resetStep(ctx, e.repo, rec); err != nil {
+				return err
+			}
+		}
+		e.steps[rec.StepID] = rec
+	}
+
+	for _, step := range e.wf.Steps {
+		if _, ok := e.steps[step.ID]; ok {
+			continue
+		}
+		rec := &models.WorkflowStepRun{
+			RunID:    e.run.ID,
+			StepID:   step.ID,
+			StepType: string(step.Type),
+			Status:   models.WorkflowStepStatusPending,
+		}
+		if err := e.repo.CreateStep(ctx, rec); err != nil {
+			return err
+		}
+		e.steps[step.ID] = rec
+	}
+	return nil
+}
+
+// schedule starts steps as their dependencies settle and waits until no step can make progress.
+func (e *execution) schedule(ctx context.Context) {
+	results := make(chan *models.WorkflowStepRun)
+	running := 0
+
+	for {
+		for e.advance(ctx, results, &running) {
+		}
+		if running == 0 {
+			return
+		}
+
+		rec := <-results
+		running--
+		e.steps[rec.StepID] = rec
+		e.log.WriteLine(fmt.Sprintf("step %s %s", rec.StepID, rec.Status))
+		e.stopParasites(ctx, rec.StepID)
+	}
+}
+
+func (e *execution) advance(ctx context.Context, results chan<- *models.WorkflowStepRun, running *int) bool {
+	progressed := false
+	for _, step := range e.wf.Steps {
+		rec := e.steps[step.ID]
+		if rec.Status != models.WorkflowStepStatusPending {
+			continue
+		}
+
+		action, reason := e.readiness(ctx, step)
+		switch action {
+		case stepActionWait:
+			continue
+		case stepActionStart:
+			e.start(ctx, step, rec, results)
+			*running++
+		case stepActionSkip:
+			e.settle(ctx, rec, models.WorkflowStepStatusSkipped, reason)
+		case stepActionCancel:
+			e.settle(ctx, rec, models.WorkflowStepStatusCanceled, reason)
+		case stepActionFail:
+			e.settle(ctx, rec, models.WorkflowStepStatusFailed, reason)
+		}
+		progressed = true
+	}
+	return progressed
+}
+
+func (e *execution) readiness(ctx context.Context, step WorkflowStep) (stepAction, string) {
+	if ctx.Err() != nil {
+		return stepActionCancel, "workflow canceled"
+	}
+
+	for _, dep := range step.DependsOn {
+		if !e.steps[dep].Status.IsTerminal() {
+			return stepActionWait, ""
+		}
+	}
+	for _, gate := range e.gates[step.ID] {
+		if !e.steps[gate.stepID].Status.IsTerminal() {
+			return stepActionWait, ""
+		}
+	}
+	if step.Type == StepTypeJoin && !e.steps[step.Fanout].Status.IsTerminal() {
+		return stepActionWait, ""
+	}
+
+	for _, dep := range step.DependsOn {
+		status := e.steps[dep].Status
+		if status != models.WorkflowStepStatusSuccess {
+			return stepActionSkip, fmt.Sprintf("dependency %s %s", dep, status)
+		}
+	}
+	for _, gate := range e.gates[step.ID] {
+		rec := e.steps[gate.stepID]
+		if rec.Status != models.WorkflowStepStatusSuccess {
+			return stepActionSkip, fmt.Sprintf("logic step %s %s", gate.stepID, rec.Status)
+		}
+		if branch, _ := rec.Outputs["branch"].(string); branch != gate.branch {
+			return stepActionSkip, fmt.Sprintf("branch %s of %s not taken", gate.branch, gate.stepID)
+		}
+	}
+
+	// A join collects failed children too, so it only needs the fanout to have run them.
+	if step.Type == StepTypeJoin {
+		fanout := e.steps[step.Fanout]
+		if _, ok := fanout.Outputs["results"]; !ok {
+			return stepActionSkip, fmt.Sprintf("fanout %s %s", step.Fanout, fanout.Status)
+		}
+	}
+
+	if len(step.AliveWith) > 0 {
+		if action, reason := e.hostReadiness(step); action != stepActionStart {
+			return action, reason
+		}
+	}
+
+	if step.When != "" {
+		ok, err := EvalBool(step.When, e.scope())
+		if err != nil {
+			return stepActionFail, fmt.Sprintf("when: %v", err)
+		}
+		if !ok {
+			return stepActionSkip, "when condition is false"
+		}
+	}
+
+	return stepActionStart, ""
+}
+
+func (e *execution) start(ctx context.Context, step WorkflowStep, rec *models.WorkflowStepRun, results chan<- *models.WorkflowStepRun) {
+	now := time.Now().UTC()
+	rec.Status = models.WorkflowStepStatusRunning
+	rec.Attempt++
+	rec.StartedAt = &now
+	rec.FinishedAt = nil
+	rec.ExitCode = nil
+	rec.Outputs = nil
+	rec.Error = ""
+	rec.LoopID = ""
+	rec.ChildRunID = ""
+	rec.LogPath = StepLogPath(e.runner.Config.Global.DataDir, e.run.ID, step.ID)
+	if err := e.repo.UpdateStep(ctx, rec); err != nil {
+		e.runner.Logger.Warn().Err(err).Str("step", step.ID).Msg("failed to record step start")
+	}
+	e.log.WriteLine(fmt.Sprintf("step %s started (type=%s attempt=%d)", step.ID, step.Type, rec.Attempt))
+
+	work := *rec
+	scope := e.scope()
+	go func() {
+		results <- e.runner.runStep(ctx, e, step, &work, scope)
+	}()
+}
+
+// settle finishes a step that never ran (skipped, canceled, or rejected before start).
+func (e *execution) settle(ctx context.Context, rec *models.WorkflowStepRun, status models.WorkflowStepStatus, reason string) {
+	now := time.Now().UTC()
+	rec.Status = status
+	rec.Error = reason
+	rec.FinishedAt = &now
+	if err := e.repo.UpdateStep(context.WithoutCancel(ctx), rec); err != nil {
+		e.runner.Logger.Warn().Err(err).Str("step", rec.StepID).Msg("failed to record step status")
+	}
+	e.log.WriteLine(fmt.Sprintf("step %s %s: %s", rec.StepID, status, reason))
+}
+
+func (e *execution) finish(ctx context.Context, hookErr error) {
+	status := models.WorkflowRunStatusSuccess
+	errText := ""
+	for _, step := range e.wf.Steps {
+		rec := e.steps[step.ID]
+		switch rec.Status {
+		case models.WorkflowStepStatusFailed:
+			if status != models.WorkflowRunStatusFailed {
+				status = models.WorkflowRunStatusFailed
+				errText = fmt.Sprintf("step %s failed: %s", step.ID, rec.Error)
+			}
+		case models.WorkflowStepStatusCanceled:
+			if status == models.WorkflowRunStatusSuccess {
+				status = models.WorkflowRunStatusCanceled
+				errText = "workflow canceled"
+			}
+		}
+	}
+	if hookErr != nil {
+		status = models.WorkflowRunStatusFailed
+		errText = hookErr.Error()
+	} else if status == models.WorkflowRunStatusSuccess && ctx.Err() != nil {
+		status = models.WorkflowRunStatusCanceled
+		errText = "workflow canceled"
+	}
+
+	if status == models.WorkflowRunStatusSuccess && len(e.wf.Outputs) > 0 {
+		outputs, err := resolveOutputs(e.wf.Outputs, e.scope())
+		if err != nil {
+			status = models.WorkflowRunStatusFailed
+			errText = fmt.Sprintf("outputs: %v", err)
+		} else {
+			e.run.Outputs = outputs
+		}
+	}
+
+	now := time.Now().UTC()
+	e.run.Status = status
+	e.run.Error = errText
+	e.run.FinishedAt = &now
+	if err := e.repo.Update(context.WithoutCancel(ctx), e.run); err != nil {
+		e.runner.Logger.Warn().Err(err).Str("run", e.run.ID).Msg("failed to record workflow run status")
+	}
+
+	if errText != "" {
+		e.log.WriteLine(fmt.Sprintf("workflow %s %s: %s", e.wf.Name, status, errText))
+	} else {
+		e.log.WriteLine(fmt.Sprintf("workflow %s %s", e.wf.Name, status))
+	}
+}
+
+// scope returns the expression scope for the current state of the run.
+func (e *execution) scope() map[string]any {
+	steps := make(map[string]any, len(e.steps))
+	for id, rec := range e.steps {
+		steps[id] = stepScope(rec)
+	}
+
+	inputs := make(map[string]any, len(e.run.Inputs))
+	for key, value := range e.run.Inputs {
+		inputs[key] = value
+	}
+
+	return map[string]any{
+		"inputs": inputs,
+		"steps":  steps,
+		"workflow": map[string]any{
+			"name":   e.wf.Name,
+			"run_id": e.run.ID,
+			"repo":   e.run.RepoPath,
+		},
+	}
+}
+
+func stepScope(rec *models.WorkflowStepRun) map[string]any {
+	outputs := rec.Outputs
+	if outputs == nil {
+		outputs = map[string]any{}
+	}
+	entry := map[string]any{
+		"status":  string(rec.Status),
+		"outputs": outputs,
+		"error":   rec.Error,
+	}
+	if rec.ExitCode != nil {
+		entry["exit_code"] = float64(*rec.ExitCode)
+	}
+	return entry
+}
+
+// resolveOutputs interpolates declared output values against a scope.
+func resolveOutputs(declared map[string]any, scope map[string]any) (map[string]any, error) {
+	outputs := make(map[string]any, len(declared))
+	for key, value := range declared {
+		text, ok := value.(string)
+		if !ok {
+			outputs[key] = value
+			continue
+		}
+		resolved, err := Interpolate(text, scope)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", key, err)
+		}
+		outputs[key] = resolved
+	}
+	return outputs, nil
+}
+
+func runDepth(run *models.WorkflowRun) int {
+	if run == nil || run.Metadata == nil {
+		return 0
+	}
+	switch v := run.Metadata["depth"].(type) {
+	case int:
+		return v
+	case float64:
+		return int(v)
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,274 @@
+package workflows
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/tOgg1/forge/internal/config"
+	"github.com/tOgg1/forge/internal/db"
+	"github.com/tOgg1/forge/internal/models"
+	"github.com/tOgg1/forge/internal/testutil"
+)
+
+func newTestRunner(t *testing.T) (*Runner, *db.DB, string) {
+	t.Helper()
+
+	database, cleanup := testutil.NewTestDB(t)
+	t.Cleanup(cleanup)
+
+	cfg := config.DefaultConfig()
+	cfg.Global.DataDir = t.TempDir()
+	cfg.Global.ConfigDir = t.TempDir()
+
+	repoDir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(repoDir, ".forge", "workflows"), 0o755); err != nil {
+		t.Fatalf("mkdir workflows: %v", err)
+	}
+
+	return NewRunner(database, cfg), database, repoDir
+}
+
+func stepsByID(t *testing.T, database *db.DB, runID string) map[string]*models.WorkflowStepRun {
+	t.Helper()
+
+	steps, err := db.NewWorkflowRunRepository(database).ListSteps(context.Background(), runID)
+	if err != nil {
+		t.Fatalf("list steps: %v", err)
+	}
+	out := make(map[string]*models.WorkflowStepRun, len(steps))
+	for _, step := range steps {
+		out[step.StepID] = step
+	}
+	return out
+}
+
+func TestRunnerRunsBashStepsInDependencyOrder(t *testing.T) {
+	runner, database, repoDir := newTestRunner(t)
+
+	wf := &Workflow{
+		Name:    "basic",
+		Source:  filepath.Join(repoDir, ".forge", "workflows", "basic.toml"),
+		Inputs:  map[string]any{"greeting": "hello"},
+		Outputs: map[string]any{"result": "${steps.build.outputs.stdout}"},
+		Steps: []WorkflowStep{
+			{ID: "plan", Type: StepTypeBash, Cmd: `echo '{"count": 3}'`},
+			{ID: "build", Type: StepTypeBash, Cmd: "echo ${inputs.greeting}-${steps.plan.outputs.json.count}-$FORGE_INPUT_GREETING", DependsOn: []string{"plan"}},
+			{ID: "skip-me", Type: StepTypeBash, Cmd: "echo never", DependsOn: []string{"plan"}, When: "steps.plan.outputs.json.count > 5"},
+		},
+	}
+
+	run, err := runner.Run(context.Background(), wf, nil)
+	if err != nil {
+		t.Fatalf("run workflow: %v", err)
+	}
+	if run.Status != models.WorkflowRunStatusSuccess {
+		t.Fatalf("expected success, got %s (%s)", run.Status, run.Error)
+	}
+	if run.RepoPath != repoDir {
+		t.Fatalf("expected repo path %s, got %s", repoDir, run.RepoPath)
+	}
+	if run.Outputs["result"] != "hello-3-hello" {
+		t.Fatalf("unexpected workflow output %v", run.Outputs["result"])
+	}
+
+	steps := stepsByID(t, database, run.ID)
+	if steps["build"].Status != models.WorkflowStepStatusSuccess {
+		t.Fatalf("expected build success, got %s", steps["build"].Status)
+	}
+	if steps["skip-me"].Status != models.WorkflowStepStatusSkipped {
+		t.Fatalf("expected skip-me skipped, got %s", steps["skip-me"].Status)
+	}
+
+	data, err := os.ReadFile(steps["build"].LogPath)
+	if err != nil {
+		t.Fatalf("read step log: %v", err)
+	}
+	if !strings.Contains(string(data), "hello-3-hello") {
+		t.Fatalf("expected command output in step log, got %q", string(data))
+	}
+}
+
+func TestRunnerFailureSkipsDependents(t *testing.T) {
+	runner, database, repoDir := newTestRunner(t)
+
+	wf := &Workflow{
+		Name:   "failing",
+		Source: filepath.Join(repoDir, ".forge", "workflows", "failing.toml"),
+		Steps: []WorkflowStep{
+			{ID: "broken", Type: StepTypeBash, Cmd: "exit 3"},
+			{ID: "after", Type: StepTypeBash, Cmd: "echo after", DependsOn: []string{"broken"}},
+			{ID: "independent", Type: StepTypeBash, Cmd: "echo ok"},
+		},
+	}
+
+	run, err := runner.Run(context.Background(), wf, nil)
+	if err != nil {
+		t.Fatalf("run workflow: %v", err)
+	}
+	if run.Status != models.WorkflowRunStatusFailed {
+		t.Fatalf("expected failed, got %s", run.Status)
+	}
+	if !strings.Contains(run.Error, "broken") {
+		t.Fatalf("expected failing step in error, got %q", run.Error)
+	}
+
+	steps := stepsByID(t, database, run.ID)
+	if steps["broken"].ExitCode == nil || *steps["broken"].ExitCode != 3 {
+		t.Fatalf("expected exit code 3, got %v", steps["broken"].ExitCode)
+	}
+	if steps["after"].Status != models.WorkflowStepStatusSkipped {
+		t.Fatalf("expected after skipped, got %s", steps["after"].Status)
+	}
+	if steps["independent"].Status != models.WorkflowStepStatusSuccess {
+		t.Fatalf("expected independent success, got %s", steps["independent"].Status)
+	}
+}
+
+func TestRunnerLogicBranches(t *testing.T) {
+	runner, database, repoDir := newTestRunner(t)
+
+	wf := &Workflow{
+		Name:   "branching",
+		Source: filepath.Join(repoDir, ".forge", "workflows", "branching.toml"),
+		Inputs: map[string]any{"mode": "fast"},
+		Steps: []WorkflowStep{
+			{ID: "decide", Type: StepTypeLogic, If: `inputs.mode == "fast"`, Then: []string{"fast"}, Else: []string{"slow"}},
+			{ID: "fast", Type: StepTypeBash, Cmd: "echo fast"},
+			{ID: "slow", Type: StepTypeBash, Cmd: "echo slow"},
+		},
+	}
+
+	run, err := runner.Run(context.Background(), wf, map[string]any{"mode": "slow"})
+	if err != nil {
+		t.Fatalf("run workflow: %v", err)
+	}
+	if run.Status != models.WorkflowRunStatusSuccess {
+		t.Fatalf("expected success, got %s (%s)", run.Status, run.Error)
+	}
+
+	steps := stepsByID(t, database, run.ID)
+	if steps["fast"].Status != models.WorkflowStepStatusSkipped {
+		t.Fatalf("expected fast skipped, got %s", steps["fast"].Status)
+	}
+	if steps["slow"].Status != models.WorkflowStepStatusSuccess {
+		t.Fatalf("expected slow success, got %s", steps["slow"].Status)
+	}
+}
+
+func TestRunnerAgentStepCreatesLoop(t *testing.T) {
+	runner, database, repoDir := newTestRunner(t)
+
+	profile := &models.Profile{
+		Name:            "pi-default",
+		Harness:         models.HarnessPi,
+		PromptMode:      models.PromptModeEnv,
+		CommandTemplate: "pi -p \"$FORGE_PROMPT_CONTENT\"",
+		MaxConcurrency:  1,
+	}
+	if err := db.NewProfileRepository(database).Create(context.Background(), profile); err != nil {
+		t.Fatalf("create profile: %v", err)
+	}
+
+	var capturedPrompt string
+	var runningLoops []*models.Loop
+	runner.LoopExec = func(ctx context.Context, profile models.Profile, promptPath, promptContent, workDir string, output io.Writer) (int, string, error) {
+		capturedPrompt = promptContent
+		runningLoops, _ = db.NewLoopRepository(database).List(ctx)
+		return 0, "plan ready", nil
+	}
+
+	wf := &Workflow{
+		Name:   "agentic",
+		Source: filepath.Join(repoDir, ".forge", "workflows", "agentic.toml"),
+		Inputs: map[string]any{"target": "parser"},
+		Steps: []WorkflowStep{
+			{ID: "plan", Type: StepTypeAgent, Prompt: "Plan work on ${inputs.target}", Profile: "pi-default"},
+		},
+	}
+
+	run, err := runner.Run(context.Background(), wf, nil)
+	if err != nil {
+		t.Fatalf("run workflow: %v", err)
+	}
+	if run.Status != models.WorkflowRunStatusSuccess {
+		t.Fatalf("expected success, got %s (%s)", run.Status, run.Error)
+	}
+	if !strings.Contains(capturedPrompt, "Plan work on parser") {
+		t.Fatalf("expected interpolated prompt, got %q", capturedPrompt)
+	}
+
+	step := stepsByID(t, database, run.ID)["plan"]
+	if step.LoopID == "" {
+		t.Fatalf("expected loop id on agent step")
+	}
+	if step.Outputs["output"] != "plan ready" {
+		t.Fatalf("expected output tail in step outputs, got %v", step.Outputs["output"])
+	}
+
+	if len(runningLoops) != 1 || runningLoops[0].Metadata["workflow_run_id"] != run.ID {
+		t.Fatalf("expected one loop with the workflow run id in its metadata while the step ran, got %v", runningLoops)
+	}
+	if step.Outputs["log_path"] != runningLoops[0].LogPath {
+		t.Fatalf("expected loop log path in step outputs, got %v", step.Outputs["log_path"])
+	}
+
+	// The step's loop is kept, with its run history, and marked done.
+	stepLoop, err := db.NewLoopRepository(database).Get(context.Background(), step.LoopID)
+	if err != nil {
+		t.Fatalf("expected step loop to be kept: %v", err)
+	}
+	if stepLoop.State != models.LoopStateStopped || !slices.Contains(stepLoop.Tags, DoneLoopTag) {
+		t.Fatalf("expected stopped loop tagged %s, got state=%s tags=%v", DoneLoopTag, stepLoop.State, stepLoop.Tags)
+	}
+	loopRuns, err := db.NewLoopRunRepository(database).ListByLoop(context.Background(), step.LoopID)
+	if err != nil {
+		t.Fatalf("list loop runs: %v", err)
+	}
+	if len(loopRuns) != 1 || loopRuns[0].Status != models.LoopRunStatusSuccess {
+		t.Fatalf("expected the step's loop run to be kept, got %v", loopRuns)
+	}
+}
+
+func TestRunnerNestedWorkflow(t *testing.T) {
+	runner, database, repoDir := newTestRunner(t)
+
+	child := "name = \"child\"\n\n[outputs]\nvalue = \"${steps.echo.outputs.stdout}\"\n\n[[steps]]\nid = \"echo\"\ntype = \"bash\"\ncmd = \"echo child-${inputs.label}\"\n"
+	if err := os.WriteFile(filepath.Join(repoDir, ".forge", "workflows", "child.toml"), []byte(child), 0o644); err != nil {
+		t.Fatalf("write child workflow: %v", err)
+	}
+
+	wf := &Workflow{
+		Name:   "parent",
+		Source: filepath.Join(repoDir, ".forge", "workflows", "parent.toml"),
+		Steps: []WorkflowStep{
+			{ID: "nested", Type: StepTypeWorkflow, WorkflowName: "child", Params: map[string]any{"label": "x"}},
+			{ID: "after", Type: StepTypeBash, Cmd: "echo ${steps.nested.outputs.value}", DependsOn: []string{"nested"}},
+		},
+	}
+
+	run, err := runner.Run(context.Background(), wf, nil)
+	if err != nil {
+		t.Fatalf("run workflow: %v", err)
+	}
+	if run.Status != models.WorkflowRunStatusSuccess {
+		t.Fatalf("expected success, got %s (%s)", run.Status, run.Error)
+	}
+
+	steps := stepsByID(t, database, run.ID)
+	if steps["after"].Outputs["stdout"] != "child-x" {
+		t.Fatalf("expected child output to flow downstream, got %v", steps["after"].Outputs["stdout"])
+	}
+
+	childRun, err := db.NewWorkflowRunRepository(database).Get(context.Background(), steps["nested"].ChildRunID)
+	if err != nil {
+		t.Fatalf("get child run: %v", err)
+	}
+	if childRun.ParentRunID != run.ID || childRun.ParentStepID != "nested" {
+		t.Fatalf("expected child run linked to parent, got %+v", childRun)
+	}
+}
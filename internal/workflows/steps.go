@@ -0,0 +1,726 @@
+package workflows
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/tOgg1/forge/internal/db"
+	"github.com/tOgg1/forge/internal/loop"
+	"github.com/tOgg1/forge/internal/models"
+)
+
+// DoneLoopTag marks the loop of a finished agent or loop step. forge ps hides
+// these loops unless asked for them.
+const DoneLoopTag = "workflow-done"
+
+// maxRetryBackoff caps the exponential delay between step retries.
+const maxRetryBackoff = 10 * time.Minute
+
+// stepContext carries the per-execution data a step executor needs.
+type stepContext struct {
+	exec    *execution
+	step    WorkflowStep
+	rec     *models.WorkflowStepRun
+	scope   map[string]any
+	log     *stepLogger
+	workDir string
+	env     []string
+}
+
+// stepOutcome is the result of running a step executor.
+type stepOutcome struct {
+	status     models.WorkflowStepStatus
+	outputs    map[string]any
+	exitCode   *int
+	loopID     string
+	childRunID string
+	err        error
+}
+
+func failedOutcome(err error) stepOutcome {
+	return stepOutcome{status: models.WorkflowStepStatusFailed, err: err}
+}
+
+func (r *Runner) runStep(ctx context.Context, e *execution, step WorkflowStep, rec *models.WorkflowStepRun, scope map[string]any) *models.WorkflowStepRun {
+	logger, err := newStepLogger(rec.LogPath)
+	if err != nil {
+		return r.completeStep(ctx, e, rec, failedOutcome(fmt.Errorf("open step log: %w", err)))
+	}
+	defer logger.Close()
+	logger.WriteLine(fmt.Sprintf("step %s started (type=%s attempt=%d)", step.ID, step.Type, rec.Attempt))
+
+	sc := &stepContext{
+		exec:  e,
+		step:  step,
+		rec:   rec,
+		scope: scope,
+		log:   logger,
+	}
+	sc.workDir, err = e.workDir(step, scope)
+	if err != nil {
+		return r.completeStep(ctx, e, rec, failedOutcome(err))
+	}
+	stepInputs, err := resolveOutputs(step.Inputs, scope)
+	if err != nil {
+		return r.completeStep(ctx, e, rec, failedOutcome(fmt.Errorf("inputs: %w", err)))
+	}
+	sc.env = e.env(stepInputs)
+
+	var timeout time.Duration
+	if step.Timeout != "" && step.Type != StepTypeHuman {
+		timeout, err = time.ParseDuration(step.Timeout)
+		if err != nil {
+			return r.completeStep(ctx, e, rec, failedOutcome(fmt.Errorf("invalid timeout %q: %w", step.Timeout, err)))
+		}
+	}
+	var backoff time.Duration
+	if step.RetryBackoff != "" {
+		backoff, err = time.ParseDuration(step.RetryBackoff)
+		if err != nil {
+			return r.completeStep(ctx, e, rec, failedOutcome(fmt.Errorf("invalid retry_backoff %q: %w", step.RetryBackoff, err)))
+		}
+	}
+
+	outcome := r.attemptStep(ctx, sc, timeout)
+	for retry := 1; retry <= step.Retries && outcome.status == models.WorkflowStepStatusFailed && ctx.Err() == nil; retry++ {
+		delay := retryDelay(backoff, retry)
+		logger.WriteLine(fmt.Sprintf("step %s failed: %v; retry %d/%d in %s", step.ID, outcome.err, retry, step.Retries, delay))
+		if !sleepContext(ctx, delay) {
+			break
+		}
+
+		now := time.Now().UTC()
+		rec.Attempt++
+		rec.StartedAt = &now
+		rec.Error = ""
+		if err := e.repo.UpdateStep(ctx, rec); err != nil {
+			r.Logger.Warn().Err(err).Str("step", step.ID).Msg("failed to record step retry")
+		}
+		logger.WriteLine(fmt.Sprintf("step %s attempt %d", step.ID, rec.Attempt))
+		outcome = r.attemptStep(ctx, sc, timeout)
+	}
+	if ctx.Err() != nil {
+		outcome.status = models.WorkflowStepStatusCanceled
+		outcome.err = errors.New("workflow canceled")
+	}
+
+	if outcome.status == models.WorkflowStepStatusSuccess && len(step.Outputs) > 0 {
+		declared, err := resolveOutputs(step.Outputs, withStepScope(scope, step.ID, outcome))
+		if err != nil {
+			outcome.status = models.WorkflowStepStatusFailed
+			outcome.err = fmt.Errorf("outputs: %w", err)
+		} else {
+			if outcome.outputs == nil {
+				outcome.outputs = make(map[string]any, len(declared))
+			}
+			for key, value := range declared {
+				outcome.outputs[key] = value
+			}
+		}
+	}
+
+	if outcome.err != nil {
+		logger.WriteLine(fmt.Sprintf("step %s %s: %v", step.ID, outcome.status, outcome.err))
+	} else {
+		logger.WriteLine(fmt.Sprintf("step %s %s", step.ID, outcome.status))
+	}
+	return r.completeStep(ctx, e, rec, outcome)
+}
+
+// attemptStep runs the step hooks and executor once, bounded by the step timeout.
+func (r *Runner) attemptStep(ctx context.Context, sc *stepContext, timeout time.Duration) stepOutcome {
+	stepCtx := ctx
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		stepCtx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	var pre, post []string
+	if sc.step.Hooks != nil {
+		pre, post = sc.step.Hooks.Pre, sc.step.Hooks.Post
+	}
+
+	var outcome stepOutcome
+	if err := runHooks(stepCtx, pre, sc.workDir, sc.env, sc.log); err != nil {
+		outcome = failedOutcome(err)
+	} else {
+		outcome = r.executeStep(stepCtx, sc)
+		if err := runHooks(stepCtx, post, sc.workDir, sc.env, sc.log); err != nil && outcome.status == models.WorkflowStepStatusSuccess {
+			outcome.status = models.WorkflowStepStatusFailed
+			outcome.err = err
+		}
+	}
+
+	if ctx.Err() == nil && stepCtx.Err() != nil && outcome.status != models.WorkflowStepStatusSuccess {
+		outcome.status = models.WorkflowStepStatusFailed
+		outcome.err = fmt.Errorf("step timed out after %s", timeout)
+	}
+	return outcome
+}
+
+// retryDelay doubles the backoff for each retry, capped at maxRetryBackoff.
+func retryDelay(backoff time.Duration, retry int) time.Duration {
+	if backoff <= 0 {
+		return 0
+	}
+	delay := backoff
+	for i := 1; i < retry && delay < maxRetryBackoff; i++ {
+		delay *= 2
+	}
+	if delay > maxRetryBackoff {
+		delay = maxRetryBackoff
+	}
+	return delay
+}
+
+func sleepContext(ctx context.Context, d time.Duration) bool {
+	if d <= 0 {
+		return ctx.Err() == nil
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
+
+func (r *Runner) completeStep(ctx context.Context, e *execution, rec *models.WorkflowStepRun, outcome stepOutcome) *models.WorkflowStepRun {
+	now := time.Now().UTC()
+	rec.Status = outcome.status
+	rec.Outputs = outcome.outputs
+	rec.ExitCode = outcome.exitCode
+	if outcome.loopID != "" {
+		rec.LoopID = outcome.loopID
+	}
+	if outcome.childRunID != "" {
+		rec.ChildRunID = outcome.childRunID
+	}
+	rec.Error = ""
+	if outcome.err != nil {
+		rec.Error = outcome.err.Error()
+	}
+	rec.FinishedAt = &now
+
+	if err := e.repo.UpdateStep(context.WithoutCancel(ctx), rec); err != nil {
+		r.Logger.Warn().Err(err).Str("step", rec.StepID).Msg("failed to record step result")
+	}
+	return rec
+}
+
+func (r *Runner) executeStep(ctx context.Context, sc *stepContext) stepOutcome {
+	switch sc.step.Type {
+	case StepTypeBash:
+		return r.runBashStep(ctx, sc)
+	case StepTypeLogic:
+		return r.runLogicStep(sc)
+	case StepTypeAgent, StepTypeLoop:
+		return r.runLoopStep(ctx, sc)
+	case StepTypeWorkflow:
+		return r.runWorkflowStep(ctx, sc)
+	case StepTypeHuman:
+		return r.runHumanStep(ctx, sc)
+	case StepTypeFanout:
+		return r.runFanoutStep(ctx, sc)
+	case StepTypeJoin:
+		return r.runJoinStep(sc)
+	default:
+		return failedOutcome(fmt.Errorf("step type %q is not supported by the workflow runner yet", sc.step.Type))
+	}
+}
+
+func (r *Runner) runBashStep(ctx context.Context, sc *stepContext) stepOutcome {
+	cmdText, err := Interpolate(sc.step.Cmd, sc.scope)
+	if err != nil {
+		return failedOutcome(fmt.Errorf("cmd: %w", err))
+	}
+
+	sc.log.WriteLine("$ " + cmdText)
+	var stdout bytes.Buffer
+	cmd := exec.CommandContext(ctx, "bash", "-lc", cmdText)
+	cmd.Dir = sc.workDir
+	cmd.Env = append(os.Environ(), sc.env...)
+	cmd.Stdout = io.MultiWriter(&stdout, sc.log)
+	cmd.Stderr = sc.log
+	cmd.WaitDelay = time.Second
+
+	runErr := cmd.Run()
+	exitCode := exitCodeFromError(runErr)
+	trimmed := strings.TrimSpace(stdout.String())
+	outputs := map[string]any{
+		"stdout":    trimmed,
+		"exit_code": exitCode,
+	}
+	var parsed any
+	if trimmed != "" && json.Unmarshal([]byte(trimmed), &parsed) == nil {
+		outputs["json"] = parsed
+	}
+
+	outcome := stepOutcome{status: models.WorkflowStepStatusSuccess, outputs: outputs, exitCode: &exitCode}
+	if runErr != nil {
+		outcome.status = models.WorkflowStepStatusFailed
+		if exitCode >= 0 {
+			outcome.err = fmt.Errorf("command exited with code %d", exitCode)
+		} else {
+			outcome.err = runErr
+		}
+	}
+	return outcome
+}
+
+func (r *Runner) runLogicStep(sc *stepContext) stepOutcome {
+	result, err := EvalBool(sc.step.If, sc.scope)
+	if err != nil {
+		return failedOutcome(fmt.Errorf("if: %w", err))
+	}
+	branch := "else"
+	if result {
+		branch = "then"
+	}
+	sc.log.WriteLine(fmt.Sprintf("if %s => %t (branch=%s)", sc.step.If, result, branch))
+	return stepOutcome{
+		status:  models.WorkflowStepStatusSuccess,
+		outputs: map[string]any{"result": result, "branch": branch},
+	}
+}
+
+func (r *Runner) runLoopStep(ctx context.Context, sc *stepContext) stepOutcome {
+	e := sc.exec
+	step := sc.step
+	loopRepo := db.NewLoopRepository(r.DB)
+
+	entry := &models.Loop{
+		Name:     stepLoopName(e.run, step, sc.rec.Attempt),
+		RepoPath: sc.workDir,
+		Tags:     []string{"workflow"},
+		State:    models.LoopStateStopped,
+		Metadata: map[string]any{
+			"workflow_name":    e.wf.Name,
+			"workflow_run_id":  e.run.ID,
+			"workflow_step_id": step.ID,
+		},
+	}
+
+	prompt := ResolveStepPrompt(e.wf, step)
+	if prompt.Inline != "" {
+		text, err := Interpolate(prompt.Inline, sc.scope)
+		if err != nil {
+			return failedOutcome(fmt.Errorf("prompt: %w", err))
+		}
+		entry.BasePromptMsg = text
+	} else {
+		if _, err := os.Stat(prompt.Path); err != nil {
+			return failedOutcome(fmt.Errorf("prompt not found: %s", prompt.Path))
+		}
+		entry.BasePromptPath = prompt.Path
+	}
+
+	if step.Profile != "" {
+		profile, err := resolveProfileRef(ctx, db.NewProfileRepository(r.DB), step.Profile)
+		if err != nil {
+			return failedOutcome(err)
+		}
+		entry.ProfileID = profile.ID
+	}
+	if step.Pool != "" {
+		pool, err := resolvePoolRef(ctx, db.NewPoolRepository(r.DB), step.Pool)
+		if err != nil {
+			return failedOutcome(err)
+		}
+		entry.PoolID = pool.ID
+	}
+
+	if step.MaxRuntime != "" {
+		maxRuntime, err := time.ParseDuration(step.MaxRuntime)
+		if err != nil {
+			return failedOutcome(fmt.Errorf("invalid max_runtime %q: %w", step.MaxRuntime, err))
+		}
+		entry.MaxRuntimeSeconds = int(maxRuntime.Round(time.Second).Seconds())
+	}
+	if step.Type == StepTypeAgent {
+		entry.MaxIterations = 1
+	} else {
+		entry.MaxIterations = step.MaxIterations
+		if step.Interval != "" {
+			interval, err := time.ParseDuration(step.Interval)
+			if err != nil {
+				return failedOutcome(fmt.Errorf("invalid interval %q: %w", step.Interval, err))
+			}
+			entry.IntervalSeconds = int(interval.Round(time.Second).Seconds())
+		}
+	}
+
+	if err := loopRepo.Create(ctx, entry); err != nil {
+		return failedOutcome(fmt.Errorf("create loop: %w", err))
+	}
+	defer r.finishStepLoop(ctx, sc, entry.ID)
+	entry.LogPath = loop.LogPath(r.Config.Global.DataDir, entry.Name, entry.ID)
+	entry.LedgerPath = loop.LedgerPath(entry.RepoPath, entry.Name, entry.ID)
+	if err := loopRepo.Update(ctx, entry); err != nil {
+		return failedOutcome(fmt.Errorf("update loop: %w", err))
+	}
+	sc.log.WriteLine(fmt.Sprintf("loop %s created (id=%s log=%s)", entry.Name, entry.ID, entry.LogPath))
+	e.attachParasiteLoop(ctx, step.ID, entry.ID)
+
+	runner := loop.NewRunner(r.DB, r.Config)
+	runner.Publisher = r.Publisher
+	if r.LoopExec != nil {
+		runner.Exec = r.LoopExec
+	}
+
+	stop := newStopEvaluator(r, sc, runner.Exec)
+	stopMatched := false
+	if stop != nil && step.Type == StepTypeLoop {
+		runner.StopCheck = func(ctx context.Context, loopEntry *models.Loop, run *models.LoopRun) (bool, string, error) {
+			matched, reason, err := stop.check(ctx, loopEntry, run)
+			stopMatched = matched
+			return matched, reason, err
+		}
+	}
+
+	var runErr error
+	if step.Type == StepTypeAgent {
+		runCtx := ctx
+		if entry.MaxRuntimeSeconds > 0 {
+			var cancel context.CancelFunc
+			runCtx, cancel = context.WithTimeout(ctx, time.Duration(entry.MaxRuntimeSeconds)*time.Second)
+			defer cancel()
+		}
+		runErr = runner.RunOnce(runCtx, entry.ID)
+	} else {
+		runErr = runner.RunLoop(ctx, entry.ID)
+	}
+
+	outcome := stepOutcome{loopID: entry.ID}
+	current, err := loopRepo.Get(context.WithoutCancel(ctx), entry.ID)
+	if err != nil {
+		outcome.status = models.WorkflowStepStatusFailed
+		outcome.err = err
+		return outcome
+	}
+	runs, err := db.NewLoopRunRepository(r.DB).ListByLoop(context.WithoutCancel(ctx), entry.ID)
+	if err != nil {
+		outcome.status = models.WorkflowStepStatusFailed
+		outcome.err = err
+		return outcome
+	}
+
+	outcome.outputs = map[string]any{
+		"loop_id":   current.ID,
+		"loop_name": current.Name,
+		"log_path":  current.LogPath,
+		"runs":      len(runs),
+		"state":     string(current.State),
+	}
+	if current.LastError != "" {
+		outcome.outputs["stop_reason"] = current.LastError
+	}
+	var latest *models.LoopRun
+	if len(runs) > 0 {
+		latest = runs[0]
+		outcome.outputs["output"] = latest.OutputTail
+		outcome.outputs["run_status"] = string(latest.Status)
+		if latest.ExitCode != nil {
+			exitCode := *latest.ExitCode
+			outcome.exitCode = &exitCode
+			outcome.outputs["exit_code"] = exitCode
+		}
+	}
+
+	// Agent steps run once, so the stop condition acts as an acceptance check.
+	stopReason := ""
+	if stop != nil && step.Type == StepTypeAgent && runErr == nil && latest != nil && latest.Status == models.LoopRunStatusSuccess {
+		stopMatched, stopReason, err = stop.check(ctx, current, latest)
+		if err != nil {
+			outcome.status = models.WorkflowStepStatusFailed
+			outcome.err = err
+			return outcome
+		}
+	}
+	if stop != nil {
+		outcome.outputs["stop_matched"] = stopMatched
+	}
+	stoppedBy := e.parasiteStoppedBy(step.ID)
+	if len(step.AliveWith) > 0 {
+		outcome.outputs["alive_with"] = step.AliveWith
+		outcome.outputs["stopped_by"] = stoppedBy
+	}
+
+	switch {
+	case runErr != nil:
+		outcome.status = models.WorkflowStepStatusFailed
+		outcome.err = runErr
+	case latest == nil && stoppedBy != "":
+		// The host finished before the parasite got a full iteration in.
+		outcome.status = models.WorkflowStepStatusSuccess
+	case latest == nil:
+		outcome.status = models.WorkflowStepStatusFailed
+		outcome.err = errors.New("loop finished without running")
+	case step.Type == StepTypeAgent && latest.Status != models.LoopRunStatusSuccess:
+		outcome.status = models.WorkflowStepStatusFailed
+		outcome.err = fmt.Errorf("agent run %s", latest.Status)
+	case step.Type == StepTypeAgent && stop != nil && !stopMatched:
+		outcome.status = models.WorkflowStepStatusFailed
+		outcome.err = fmt.Errorf("stop condition not met: %s", stopReason)
+	case current.State == models.LoopStateError:
+		outcome.status = models.WorkflowStepStatusFailed
+		outcome.err = fmt.Errorf("loop error: %s", current.LastError)
+	default:
+		outcome.status = models.WorkflowStepStatusSuccess
+	}
+	return outcome
+}
+
+// finishStepLoop marks the loop that backed a step as done once the step
+// finishes. The loop is kept, with its run history, and tagged DoneLoopTag so
+// forge ps hides it.
+func (r *Runner) finishStepLoop(ctx context.Context, sc *stepContext, loopID string) {
+	sc.exec.detachParasiteLoop(sc.step.ID)
+
+	ctx = context.WithoutCancel(ctx)
+	loopRepo := db.NewLoopRepository(r.DB)
+	entry, err := loopRepo.Get(ctx, loopID)
+	if err != nil {
+		if !errors.Is(err, db.ErrLoopNotFound) {
+			r.Logger.Warn().Err(err).Str("step", sc.step.ID).Msg("failed to load step loop")
+		}
+		return
+	}
+	if entry.State != models.LoopStateError {
+		entry.State = models.LoopStateStopped
+	}
+	if !slices.Contains(entry.Tags, DoneLoopTag) {
+		entry.Tags = append(entry.Tags, DoneLoopTag)
+	}
+	if err := loopRepo.Update(ctx, entry); err != nil {
+		r.Logger.Warn().Err(err).Str("step", sc.step.ID).Msg("failed to mark step loop done")
+	}
+}
+
+func (r *Runner) runWorkflowStep(ctx context.Context, sc *stepContext) stepOutcome {
+	e := sc.exec
+	step := sc.step
+
+	if runDepth(e.run)+1 > maxWorkflowDepth {
+		return failedOutcome(fmt.Errorf("nested workflows exceed max depth %d", maxWorkflowDepth))
+	}
+
+	child, err := findWorkflow(e.run.RepoPath, step.WorkflowName)
+	if err != nil {
+		return failedOutcome(err)
+	}
+	params, err := resolveOutputs(step.Params, sc.scope)
+	if err != nil {
+		return failedOutcome(fmt.Errorf("params: %w", err))
+	}
+
+	childRun, err := r.createRun(ctx, child, params, e.run, step.ID)
+	if err != nil {
+		return failedOutcome(fmt.Errorf("create workflow run: %w", err))
+	}
+	sc.rec.ChildRunID = childRun.ID
+	if err := e.repo.UpdateStep(ctx, sc.rec); err != nil {
+		r.Logger.Warn().Err(err).Str("step", step.ID).Msg("failed to record child workflow run")
+	}
+	sc.log.WriteLine(fmt.Sprintf("workflow %s started (run=%s)", child.Name, childRun.ID))
+
+	finished, err := r.Execute(ctx, childRun, child)
+	if err != nil {
+		outcome := failedOutcome(err)
+		outcome.childRunID = childRun.ID
+		return outcome
+	}
+
+	outputs := map[string]any{"run_id": finished.ID}
+	for key, value := range finished.Outputs {
+		outputs[key] = value
+	}
+	outcome := stepOutcome{outputs: outputs, childRunID: finished.ID}
+	switch finished.Status {
+	case models.WorkflowRunStatusSuccess:
+		outcome.status = models.WorkflowStepStatusSuccess
+	case models.WorkflowRunStatusCanceled:
+		outcome.status = models.WorkflowStepStatusCanceled
+		outcome.err = errors.New("child workflow canceled")
+	default:
+		outcome.status = models.WorkflowStepStatusFailed
+		outcome.err = fmt.Errorf("child workflow %s: %s", finished.Status, finished.Error)
+	}
+	return outcome
+}
+
+// workDir resolves the working directory for a step relative to the run repo.
+func (e *execution) workDir(step WorkflowStep, scope map[string]any) (string, error) {
+	if step.Workdir == "" {
+		return e.run.RepoPath, nil
+	}
+	dir, err := Interpolate(step.Workdir, scope)
+	if err != nil {
+		return "", fmt.Errorf("workdir: %w", err)
+	}
+	if !filepath.IsAbs(dir) && e.run.RepoPath != "" {
+		dir = filepath.Join(e.run.RepoPath, dir)
+	}
+	return dir, nil
+}
+
+// env returns the FORGE_* environment passed to step commands and hooks.
+func (e *execution) env(stepInputs map[string]any) []string {
+	env := []string{
+		"FORGE_WORKFLOW_NAME=" + e.wf.Name,
+		"FORGE_WORKFLOW_RUN_ID=" + e.run.ID,
+	}
+	for key, value := range e.run.Inputs {
+		env = append(env, "FORGE_INPUT_"+envKey(key)+"="+FormatValue(value))
+	}
+	for key, value := range stepInputs {
+		env = append(env, "FORGE_INPUT_"+envKey(key)+"="+FormatValue(value))
+	}
+	return env
+}
+
+func envKey(key string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return unicode.ToUpper(r)
+		}
+		return '_'
+	}, key)
+}
+
+func withStepScope(scope map[string]any, stepID string, outcome stepOutcome) map[string]any {
+	steps := make(map[string]any)
+	if existing, ok := scope["steps"].(map[string]any); ok {
+		for key, value := range existing {
+			steps[key] = value
+		}
+	}
+	rec := &models.WorkflowStepRun{Status: outcome.status, Outputs: outcome.outputs, ExitCode: outcome.exitCode}
+	steps[stepID] = stepScope(rec)
+
+	next := make(map[string]any, len(scope))
+	for key, value := range scope {
+		next[key] = value
+	}
+	next["steps"] = steps
+	return next
+}
+
+func runHooks(ctx context.Context, hooks []string, workDir string, env []string, logger *stepLogger) error {
+	for _, hook := range hooks {
+		cmdText, err := hookCommand(hook)
+		if err != nil {
+			return err
+		}
+		logger.WriteLine("hook: " + hook)
+
+		cmd := exec.CommandContext(ctx, "bash", "-lc", cmdText)
+		cmd.Dir = workDir
+		cmd.Env = append(os.Environ(), env...)
+		cmd.Stdout = logger
+		cmd.Stderr = logger
+		cmd.WaitDelay = time.Second
+		if err := cmd.Run(); err != nil {
+			return fmt.Errorf("hook %q failed: %w", hook, err)
+		}
+	}
+	return nil
+}
+
+// hookCommand strips the "bash:" prefix from a hook spec.
+func hookCommand(hook string) (string, error) {
+	kind, body, found := strings.Cut(hook, ":")
+	if !found || strings.ContainsAny(kind, " \t/.") {
+		return hook, nil
+	}
+	switch strings.ToLower(kind) {
+	case "bash", "sh":
+		return strings.TrimSpace(body), nil
+	default:
+		return "", fmt.Errorf("unsupported hook type %q", kind)
+	}
+}
+
+func stepLoopName(run *models.WorkflowRun, step WorkflowStep, attempt int) string {
+	name := fmt.Sprintf("wf-%s-%s", shortRunID(run.ID), step.ID)
+	if attempt > 1 {
+		name = fmt.Sprintf("%s-%d", name, attempt)
+	}
+	return name
+}
+
+func resolveProfileRef(ctx context.Context, repo *db.ProfileRepository, ref string) (*models.Profile, error) {
+	profile, err := repo.GetByName(ctx, ref)
+	if err == nil {
+		return profile, nil
+	}
+	if !errors.Is(err, db.ErrProfileNotFound) {
+		return nil, err
+	}
+	profile, err = repo.Get(ctx, ref)
+	if err != nil {
+		if errors.Is(err, db.ErrProfileNotFound) {
+			return nil, fmt.Errorf("profile %q not found", ref)
+		}
+		return nil, err
+	}
+	return profile, nil
+}
+
+func resolvePoolRef(ctx context.Context, repo *db.PoolRepository, ref string) (*models.Pool, error) {
+	pool, err := repo.GetByName(ctx, ref)
+	if err == nil {
+		return pool, nil
+	}
+	if !errors.Is(err, db.ErrPoolNotFound) {
+		return nil, err
+	}
+	pool, err = repo.Get(ctx, ref)
+	if err != nil {
+		if errors.Is(err, db.ErrPoolNotFound) {
+			return nil, fmt.Errorf("pool %q not found", ref)
+		}
+		return nil, err
+	}
+	return pool, nil
+}
+
+// findWorkflow loads a workflow by name from the repo search paths.
+func findWorkflow(repoPath, name string) (*Workflow, error) {
+	clean := strings.TrimSuffix(strings.TrimSpace(name), ".toml")
+	items, err := LoadWorkflowsFromSearchPaths(repoPath)
+	if err != nil {
+		return nil, err
+	}
+	for _, wf := range items {
+		if wf.Name == clean {
+			return wf, nil
+		}
+	}
+	return nil, fmt.Errorf("workflow %q not found", clean)
+}
+
+func exitCodeFromError(err error) int {
+	if err == nil {
+		return 0
+	}
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return exitErr.ExitCode()
+	}
+	return -1
+}
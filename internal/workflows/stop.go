@@ -0,0 +1,249 @@
+package workflows
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/tOgg1/forge/internal/db"
+	"github.com/tOgg1/forge/internal/loop"
+	"github.com/tOgg1/forge/internal/models"
+)
+
+// stopToolTimeout bounds a single stop.tool invocation.
+const stopToolTimeout = 2 * time.Minute
+
+// stopJudgeInstructions is appended to the llm rubric when pass_if is empty,
+// matching the 0/1 protocol used by loop qualitative stops.
+const stopJudgeInstructions = "Respond with 0 if the rubric is satisfied and the work should stop, or 1 to continue. Put the digit first."
+
+// stopEvaluator evaluates a workflow step's stop condition against the latest loop run.
+type stopEvaluator struct {
+	runner *Runner
+	sc     *stepContext
+	cond   *StopCondition
+	exec   loop.ExecuteFunc
+}
+
+func newStopEvaluator(r *Runner, sc *stepContext, exec loop.ExecuteFunc) *stopEvaluator {
+	if sc.step.Stop == nil {
+		return nil
+	}
+	return &stopEvaluator{runner: r, sc: sc, cond: sc.step.Stop, exec: exec}
+}
+
+// check runs the configured checks in order (tool, expr, llm); all of them must pass.
+// The returned reason describes the deciding check.
+func (s *stopEvaluator) check(ctx context.Context, loopEntry *models.Loop, run *models.LoopRun) (bool, string, error) {
+	outputs := stopRunOutputs(loopEntry, run)
+	scope := make(map[string]any, len(s.sc.scope)+2)
+	for key, value := range s.sc.scope {
+		scope[key] = value
+	}
+	scope["outputs"] = outputs
+	if data, ok := outputs["json"].(map[string]any); ok {
+		mergeScope(scope, data)
+	}
+
+	var reasons []string
+	if s.cond.Tool != nil {
+		result, err := s.runTool(ctx)
+		if err != nil {
+			return false, "", err
+		}
+		scope["tool"] = result
+		if data, ok := result["json"].(map[string]any); ok {
+			mergeScope(scope, data)
+		}
+		if s.cond.Expr == "" {
+			code, _ := result["exit_code"].(int)
+			if code != 0 {
+				return false, fmt.Sprintf("tool %s exited %d", s.cond.Tool.Name, code), nil
+			}
+			reasons = append(reasons, fmt.Sprintf("tool %s succeeded", s.cond.Tool.Name))
+		}
+	}
+
+	if s.cond.Expr != "" {
+		ok, err := EvalBool(s.cond.Expr, scope)
+		if err != nil {
+			return false, "", fmt.Errorf("stop.expr: %w", err)
+		}
+		if !ok {
+			return false, fmt.Sprintf("expr %s is false", s.cond.Expr), nil
+		}
+		reasons = append(reasons, "expr "+s.cond.Expr)
+	}
+
+	if s.cond.LLM != nil {
+		passed, verdict, err := s.judge(ctx, run, scope)
+		if err != nil {
+			return false, "", err
+		}
+		if !passed {
+			return false, "llm judge: " + verdict, nil
+		}
+		reasons = append(reasons, "llm judge: "+verdict)
+	}
+
+	return true, strings.Join(reasons, "; "), nil
+}
+
+// runTool executes stop.tool in the step working directory.
+func (s *stopEvaluator) runTool(ctx context.Context) (map[string]any, error) {
+	tool := s.cond.Tool
+	name, err := Interpolate(tool.Name, s.sc.scope)
+	if err != nil {
+		return nil, fmt.Errorf("stop.tool.name: %w", err)
+	}
+	args := make([]string, 0, len(tool.Args))
+	for _, arg := range tool.Args {
+		value, err := Interpolate(arg, s.sc.scope)
+		if err != nil {
+			return nil, fmt.Errorf("stop.tool.args: %w", err)
+		}
+		args = append(args, value)
+	}
+
+	runCtx, cancel := context.WithTimeout(ctx, stopToolTimeout)
+	defer cancel()
+
+	var stdout bytes.Buffer
+	cmd := exec.CommandContext(runCtx, name, args...)
+	cmd.Dir = s.sc.workDir
+	cmd.Env = append(os.Environ(), s.sc.env...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = s.sc.log
+	cmd.WaitDelay = time.Second
+
+	s.sc.log.WriteLine(fmt.Sprintf("stop tool: %s %s", name, strings.Join(args, " ")))
+	runErr := cmd.Run()
+	exitCode := exitCodeFromError(runErr)
+	if exitCode < 0 {
+		return nil, fmt.Errorf("stop tool %s: %w", name, runErr)
+	}
+
+	trimmed := strings.TrimSpace(stdout.String())
+	result := map[string]any{
+		"stdout":    trimmed,
+		"exit_code": exitCode,
+	}
+	var parsed any
+	if trimmed != "" && json.Unmarshal([]byte(trimmed), &parsed) == nil {
+		result["json"] = parsed
+	}
+	return result, nil
+}
+
+// judge runs one harness iteration with the rubric through the profile of the latest run
+// and matches its output against pass_if.
+func (s *stopEvaluator) judge(ctx context.Context, run *models.LoopRun, scope map[string]any) (bool, string, error) {
+	if s.exec == nil {
+		return false, "", errors.New("stop.llm: no harness available")
+	}
+	if run == nil || run.ProfileID == "" {
+		return false, "", errors.New("stop.llm: no profile to run the judge")
+	}
+	profile, err := db.NewProfileRepository(s.runner.DB).Get(ctx, run.ProfileID)
+	if err != nil {
+		return false, "", fmt.Errorf("stop.llm: %w", err)
+	}
+
+	rubric, err := Interpolate(s.cond.LLM.Rubric, scope)
+	if err != nil {
+		return false, "", fmt.Errorf("stop.llm.rubric: %w", err)
+	}
+	prompt := buildJudgePrompt(rubric, run.OutputTail, s.cond.LLM.PassIf)
+
+	s.sc.log.WriteLine(fmt.Sprintf("stop judge start (profile=%s)", profile.Name))
+	var output bytes.Buffer
+	_, tail, err := s.exec(ctx, *profile, "", prompt, s.sc.workDir, io.MultiWriter(&output, s.sc.log))
+	if err != nil {
+		return false, "", fmt.Errorf("stop.llm: judge failed: %w", err)
+	}
+	if strings.TrimSpace(tail) == "" {
+		tail = output.String()
+	}
+	verdict := firstLine(tail)
+
+	if s.cond.LLM.PassIf == "" {
+		fields := strings.Fields(tail)
+		return len(fields) > 0 && fields[0] == "0", verdict, nil
+	}
+	re, err := regexp.Compile("(?i)" + s.cond.LLM.PassIf)
+	if err != nil {
+		return false, "", fmt.Errorf("stop.llm.pass_if: %w", err)
+	}
+	return re.MatchString(tail), verdict, nil
+}
+
+func buildJudgePrompt(rubric, output, passIf string) string {
+	var b strings.Builder
+	b.WriteString("You are judging whether a workflow step is done.\n\n")
+	if strings.TrimSpace(rubric) != "" {
+		b.WriteString("Rubric:\n")
+		b.WriteString(strings.TrimSpace(rubric))
+		b.WriteString("\n\n")
+	}
+	if strings.TrimSpace(output) != "" {
+		b.WriteString("Latest step output:\n")
+		b.WriteString(strings.TrimSpace(output))
+		b.WriteString("\n\n")
+	}
+	if passIf == "" {
+		b.WriteString(stopJudgeInstructions)
+	} else {
+		b.WriteString("Reply with a one-line verdict.")
+	}
+	b.WriteString("\n")
+	return b.String()
+}
+
+// stopRunOutputs exposes the latest loop run to stop expressions as `outputs`.
+func stopRunOutputs(loopEntry *models.Loop, run *models.LoopRun) map[string]any {
+	outputs := map[string]any{}
+	if loopEntry != nil {
+		outputs["loop_id"] = loopEntry.ID
+		outputs["loop_name"] = loopEntry.Name
+	}
+	if run == nil {
+		return outputs
+	}
+	outputs["output"] = run.OutputTail
+	outputs["run_status"] = string(run.Status)
+	if run.ExitCode != nil {
+		outputs["exit_code"] = *run.ExitCode
+	}
+	var parsed any
+	if trimmed := strings.TrimSpace(run.OutputTail); trimmed != "" && json.Unmarshal([]byte(trimmed), &parsed) == nil {
+		outputs["json"] = parsed
+	}
+	return outputs
+}
+
+// mergeScope adds JSON fields as top-level names without shadowing the workflow scope.
+func mergeScope(scope map[string]any, data map[string]any) {
+	for key, value := range data {
+		switch key {
+		case "inputs", "steps", "workflow", "outputs", "tool":
+			continue
+		}
+		scope[key] = value
+	}
+}
+
+func firstLine(text string) string {
+	text = strings.TrimSpace(text)
+	if idx := strings.IndexByte(text, '\n'); idx >= 0 {
+		text = text[:idx]
+	}
+	return strings.TrimSpace(text)
+}
@@ -0,0 +1,142 @@
+package workflows
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/tOgg1/forge/internal/db"
+	"github.com/tOgg1/forge/internal/models"
+)
+
+func createTestProfile(t *testing.T, database *db.DB) {
+	t.Helper()
+
+	profile := &models.Profile{
+		Name:            "pi-default",
+		Harness:         models.HarnessPi,
+		PromptMode:      models.PromptModeEnv,
+		CommandTemplate: "pi -p \"$FORGE_PROMPT_CONTENT\"",
+		MaxConcurrency:  1,
+	}
+	if err := db.NewProfileRepository(database).Create(context.Background(), profile); err != nil {
+		t.Fatalf("create profile: %v", err)
+	}
+}
+
+func TestLoopStepStopsOnExpr(t *testing.T) {
+	runner, database, repoDir := newTestRunner(t)
+	createTestProfile(t, database)
+
+	iterations := 0
+	runner.LoopExec = func(ctx context.Context, profile models.Profile, promptPath, promptContent, workDir string, output io.Writer) (int, string, error) {
+		iterations++
+		open := make([]string, 0, 3)
+		for i := iterations; i < 3; i++ {
+			open = append(open, fmt.Sprintf("%q", fmt.Sprintf("t%d", i)))
+		}
+		return 0, fmt.Sprintf(`{"tasks": {"open": [%s]}}`, strings.Join(open, ",")), nil
+	}
+
+	wf := &Workflow{
+		Name:   "drain",
+		Source: filepath.Join(repoDir, ".forge", "workflows", "drain.toml"),
+		Steps: []WorkflowStep{
+			{ID: "work", Type: StepTypeLoop, Prompt: "close a task", Profile: "pi-default", MaxIterations: 10, Stop: &StopCondition{Expr: "count(tasks.open) == 0"}},
+		},
+	}
+
+	run, err := runner.Run(context.Background(), wf, nil)
+	if err != nil {
+		t.Fatalf("run workflow: %v", err)
+	}
+	if run.Status != models.WorkflowRunStatusSuccess {
+		t.Fatalf("expected success, got %s (%s)", run.Status, run.Error)
+	}
+	if iterations != 3 {
+		t.Fatalf("expected loop to stop after 3 iterations, got %d", iterations)
+	}
+
+	step := stepsByID(t, database, run.ID)["work"]
+	if step.Outputs["stop_matched"] != true {
+		t.Fatalf("expected stop_matched output, got %v", step.Outputs)
+	}
+	if reason, _ := step.Outputs["stop_reason"].(string); !strings.Contains(reason, "count(tasks.open) == 0") {
+		t.Fatalf("expected stop reason, got %q", reason)
+	}
+}
+
+func TestAgentStepStopToolActsAsAcceptanceCheck(t *testing.T) {
+	runner, database, repoDir := newTestRunner(t)
+	createTestProfile(t, database)
+
+	runner.LoopExec = func(ctx context.Context, profile models.Profile, promptPath, promptContent, workDir string, output io.Writer) (int, string, error) {
+		return 0, "done", nil
+	}
+
+	wf := &Workflow{
+		Name:   "accept",
+		Source: filepath.Join(repoDir, ".forge", "workflows", "accept.toml"),
+		Steps: []WorkflowStep{
+			{ID: "pass", Type: StepTypeAgent, Prompt: "work", Profile: "pi-default", Stop: &StopCondition{Tool: &StopTool{Name: "true"}}},
+			{ID: "fail", Type: StepTypeAgent, Prompt: "work", Profile: "pi-default", DependsOn: []string{"pass"}, Stop: &StopCondition{Tool: &StopTool{Name: "sh", Args: []string{"-c", "exit 4"}}}},
+		},
+	}
+
+	run, err := runner.Run(context.Background(), wf, nil)
+	if err != nil {
+		t.Fatalf("run workflow: %v", err)
+	}
+
+	steps := stepsByID(t, database, run.ID)
+	if steps["pass"].Status != models.WorkflowStepStatusSuccess {
+		t.Fatalf("expected pass success, got %s (%s)", steps["pass"].Status, steps["pass"].Error)
+	}
+	if steps["fail"].Status != models.WorkflowStepStatusFailed || !strings.Contains(steps["fail"].Error, "exited 4") {
+		t.Fatalf("expected fail to fail on tool exit code, got %s (%s)", steps["fail"].Status, steps["fail"].Error)
+	}
+}
+
+func TestLoopStepStopsOnLLMJudge(t *testing.T) {
+	runner, database, repoDir := newTestRunner(t)
+	createTestProfile(t, database)
+
+	iterations := 0
+	var judgePrompt string
+	runner.LoopExec = func(ctx context.Context, profile models.Profile, promptPath, promptContent, workDir string, output io.Writer) (int, string, error) {
+		if strings.HasPrefix(promptContent, "You are judging") {
+			judgePrompt = promptContent
+			if iterations >= 2 {
+				return 0, "Verdict: GOOD coverage", nil
+			}
+			return 0, "Verdict: poor", nil
+		}
+		iterations++
+		return 0, fmt.Sprintf("iteration %d", iterations), nil
+	}
+
+	wf := &Workflow{
+		Name:   "judge",
+		Source: filepath.Join(repoDir, ".forge", "workflows", "judge.toml"),
+		Steps: []WorkflowStep{
+			{ID: "work", Type: StepTypeLoop, Prompt: "add tests", Profile: "pi-default", MaxIterations: 5, Stop: &StopCondition{LLM: &StopLLM{Rubric: "Is coverage sufficient?", PassIf: "good"}}},
+		},
+	}
+
+	run, err := runner.Run(context.Background(), wf, nil)
+	if err != nil {
+		t.Fatalf("run workflow: %v", err)
+	}
+	if run.Status != models.WorkflowRunStatusSuccess {
+		t.Fatalf("expected success, got %s (%s)", run.Status, run.Error)
+	}
+	if iterations != 2 {
+		t.Fatalf("expected judge to stop after 2 iterations, got %d", iterations)
+	}
+	if !strings.Contains(judgePrompt, "Is coverage sufficient?") || !strings.Contains(judgePrompt, "iteration 2") {
+		t.Fatalf("expected rubric and output in judge prompt, got %q", judgePrompt)
+	}
+}
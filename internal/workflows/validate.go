@@ -2,8 +2,10 @@ package workflows
 
 import (
 	"fmt"
+	"regexp"
 	"sort"
 	"strings"
+	"time"
 )
 
 var validStepTypes = map[StepType]struct{}{
@@ -14,6 +16,8 @@ var validStepTypes = map[StepType]struct{}{
 	StepTypeJob:      {},
 	StepTypeWorkflow: {},
 	StepTypeHuman:    {},
+	StepTypeFanout:   {},
+	StepTypeJoin:     {},
 }
 
 // ValidateWorkflow validates and normalizes a workflow.
@@ -93,12 +97,15 @@ func ValidateWorkflow(wf *Workflow) (*Workflow, error) {
 		}
 
 		validateStepSpecificFields(step, index, path, list)
+		validateRetryFields(step, index, path, list)
 		validateStopCondition(step, index, path, list)
 		validateDependencies(step, index, path, list)
 	}
 
 	validateDependencyTargets(wf, stepIndex, list)
 	validateLogicTargets(wf, stepIndex, list)
+	validateAliveWith(wf, stepIndex, list)
+	validateJoinTargets(wf, stepIndex, list)
 	validateCycles(wf, stepIndex, list)
 
 	if list.Empty() {
@@ -141,6 +148,90 @@ func validateStepSpecificFields(step *WorkflowStep, index int, path string, list
 		}
 	case StepTypeHuman:
 		validatePromptFields(step, index, path, list)
+	case StepTypeFanout:
+		validatePromptFields(step, index, path, list)
+		validateFanoutFields(step, index, path, list)
+	case StepTypeJoin:
+		if step.Fanout == "" {
+			list.Add(missingFieldError(path, step.ID, index, "fanout"))
+		}
+	}
+}
+
+func validateFanoutFields(step *WorkflowStep, index int, path string, list *ErrorList) {
+	if step.Each != "" && step.Each != StepTypeAgent && step.Each != StepTypeLoop {
+		list.Add(WorkflowError{
+			Code:    ErrCodeInvalidField,
+			Message: fmt.Sprintf("invalid each %q (expected agent or loop)", step.Each),
+			Path:    path,
+			StepID:  step.ID,
+			Field:   "each",
+			Index:   index,
+		})
+	}
+
+	sources := 0
+	if step.Items != nil {
+		sources++
+		switch step.Items.(type) {
+		case []any, string:
+		default:
+			list.Add(WorkflowError{
+				Code:    ErrCodeInvalidField,
+				Message: "items must be an array or an expression string",
+				Path:    path,
+				StepID:  step.ID,
+				Field:   "items",
+				Index:   index,
+			})
+		}
+	}
+	if step.ItemsCmd != "" {
+		sources++
+	}
+	if step.ItemsBeads != nil {
+		sources++
+		if step.ItemsBeads.Limit < 0 {
+			list.Add(WorkflowError{
+				Code:    ErrCodeInvalidField,
+				Message: "items_beads.limit must be >= 0",
+				Path:    path,
+				StepID:  step.ID,
+				Field:   "items_beads.limit",
+				Index:   index,
+			})
+		}
+	}
+	switch {
+	case sources == 0:
+		list.Add(WorkflowError{
+			Code:    ErrCodeMissingField,
+			Message: "items, items_cmd, or items_beads is required",
+			Path:    path,
+			StepID:  step.ID,
+			Field:   "items",
+			Index:   index,
+		})
+	case sources > 1:
+		list.Add(WorkflowError{
+			Code:    ErrCodeInvalidField,
+			Message: "only one of items, items_cmd, or items_beads may be set",
+			Path:    path,
+			StepID:  step.ID,
+			Field:   "items",
+			Index:   index,
+		})
+	}
+
+	if step.MaxParallel < 0 {
+		list.Add(WorkflowError{
+			Code:    ErrCodeInvalidField,
+			Message: "max_parallel must be >= 0",
+			Path:    path,
+			StepID:  step.ID,
+			Field:   "max_parallel",
+			Index:   index,
+		})
 	}
 }
 
@@ -157,6 +248,34 @@ func validatePromptFields(step *WorkflowStep, index int, path string, list *Erro
 	}
 }
 
+func validateRetryFields(step *WorkflowStep, index int, path string, list *ErrorList) {
+	if step.Retries < 0 {
+		list.Add(WorkflowError{
+			Code:    ErrCodeInvalidField,
+			Message: "retries must be >= 0",
+			Path:    path,
+			StepID:  step.ID,
+			Field:   "retries",
+			Index:   index,
+		})
+	}
+
+	if step.RetryBackoff == "" {
+		return
+	}
+	backoff, err := time.ParseDuration(step.RetryBackoff)
+	if err != nil || backoff < 0 {
+		list.Add(WorkflowError{
+			Code:    ErrCodeInvalidField,
+			Message: fmt.Sprintf("invalid retry_backoff %q (expected duration like 30s)", step.RetryBackoff),
+			Path:    path,
+			StepID:  step.ID,
+			Field:   "retry_backoff",
+			Index:   index,
+		})
+	}
+}
+
 func validateStopCondition(step *WorkflowStep, index int, path string, list *ErrorList) {
 	if step.Stop == nil {
 		return
@@ -187,6 +306,30 @@ func validateStopCondition(step *WorkflowStep, index int, path string, list *Err
 			Index:   index,
 		})
 	}
+
+	if step.Stop.LLM != nil && step.Stop.LLM.PassIf != "" {
+		if _, err := regexp.Compile(step.Stop.LLM.PassIf); err != nil {
+			list.Add(WorkflowError{
+				Code:    ErrCodeInvalidField,
+				Message: fmt.Sprintf("invalid stop.llm.pass_if pattern: %v", err),
+				Path:    path,
+				StepID:  step.ID,
+				Field:   "stop.llm.pass_if",
+				Index:   index,
+			})
+		}
+	}
+
+	if step.Type != "" && step.Type != StepTypeAgent && step.Type != StepTypeLoop && step.Type != StepTypeFanout {
+		list.Add(WorkflowError{
+			Code:    ErrCodeInvalidField,
+			Message: "stop is only supported on agent, loop, and fanout steps",
+			Path:    path,
+			StepID:  step.ID,
+			Field:   "stop",
+			Index:   index,
+		})
+	}
 }
 
 func validateDependencies(step *WorkflowStep, index int, path string, list *ErrorList) {
@@ -275,6 +418,87 @@ func validateLogicTargets(wf *Workflow, stepIndex map[string]int, list *ErrorLis
 	}
 }
 
+// validateAliveWith checks parasitic steps: only loop steps can be stopped when
+// their hosts finish, and every host must be another step in the workflow.
+func validateAliveWith(wf *Workflow, stepIndex map[string]int, list *ErrorList) {
+	for i := range wf.Steps {
+		step := &wf.Steps[i]
+		if len(step.AliveWith) == 0 {
+			continue
+		}
+		index := i + 1
+		if step.Type != "" && step.Type != StepTypeLoop {
+			list.Add(WorkflowError{
+				Code:    ErrCodeInvalidField,
+				Message: "alive_with is only supported on loop steps",
+				Path:    wf.Source,
+				StepID:  step.ID,
+				Field:   "alive_with",
+				Index:   index,
+			})
+		}
+		for _, host := range step.AliveWith {
+			if host == "" {
+				continue
+			}
+			if host == step.ID {
+				list.Add(WorkflowError{
+					Code:    ErrCodeInvalidField,
+					Message: "step cannot be alive_with itself",
+					Path:    wf.Source,
+					StepID:  step.ID,
+					Field:   "alive_with",
+					Index:   index,
+				})
+				continue
+			}
+			if _, exists := stepIndex[host]; !exists {
+				list.Add(WorkflowError{
+					Code:    ErrCodeMissingStep,
+					Message: fmt.Sprintf("unknown alive_with step %q", host),
+					Path:    wf.Source,
+					StepID:  step.ID,
+					Field:   "alive_with",
+					Index:   index,
+				})
+			}
+		}
+	}
+}
+
+// validateJoinTargets checks that every join step names a fanout step.
+func validateJoinTargets(wf *Workflow, stepIndex map[string]int, list *ErrorList) {
+	for i := range wf.Steps {
+		step := &wf.Steps[i]
+		if step.Type != StepTypeJoin || step.Fanout == "" {
+			continue
+		}
+		index := i + 1
+		target, exists := stepIndex[step.Fanout]
+		if !exists {
+			list.Add(WorkflowError{
+				Code:    ErrCodeMissingStep,
+				Message: fmt.Sprintf("unknown fanout step %q", step.Fanout),
+				Path:    wf.Source,
+				StepID:  step.ID,
+				Field:   "fanout",
+				Index:   index,
+			})
+			continue
+		}
+		if wf.Steps[target-1].Type != StepTypeFanout {
+			list.Add(WorkflowError{
+				Code:    ErrCodeInvalidField,
+				Message: fmt.Sprintf("step %q is not a fanout step", step.Fanout),
+				Path:    wf.Source,
+				StepID:  step.ID,
+				Field:   "fanout",
+				Index:   index,
+			})
+		}
+	}
+}
+
 func validateCycles(wf *Workflow, stepIndex map[string]int, list *ErrorList) {
 	if len(stepIndex) == 0 {
 		return
@@ -297,6 +521,20 @@ func validateCycles(wf *Workflow, stepIndex map[string]int, list *ErrorList) {
 			adj[dep] = append(adj[dep], step.ID)
 			inDegree[step.ID]++
 		}
+		if step.Type == StepTypeJoin && step.Fanout != "" && step.Fanout != step.ID {
+			if _, ok := inDegree[step.Fanout]; ok {
+				adj[step.Fanout] = append(adj[step.Fanout], step.ID)
+				inDegree[step.ID]++
+			}
+		}
+		// A parasite starts after its hosts, so a host waiting on it can never run.
+		for _, host := range step.AliveWith {
+			if _, ok := inDegree[host]; !ok || host == step.ID {
+				continue
+			}
+			adj[host] = append(adj[host], step.ID)
+			inDegree[step.ID]++
+		}
 	}
 
 	queue := make([]string, 0)
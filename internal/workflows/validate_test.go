@@ -116,3 +116,155 @@ func TestValidateWorkflowUnknownDependency(t *testing.T) {
 		t.Fatalf("expected missing dependency error")
 	}
 }
+
+func TestValidateWorkflowRetryFields(t *testing.T) {
+	wf := &Workflow{
+		Name:   "retry",
+		Source: "testdata/retry.toml",
+		Steps: []WorkflowStep{
+			{ID: "a", Type: StepTypeBash, Cmd: "echo a", Retries: -1},
+			{ID: "b", Type: StepTypeBash, Cmd: "echo b", Retries: 2, RetryBackoff: "soon"},
+			{ID: "c", Type: StepTypeBash, Cmd: "echo c", Retries: 2, RetryBackoff: "30s"},
+		},
+	}
+
+	_, err := ValidateWorkflow(wf)
+	var list *ErrorList
+	if !errors.As(err, &list) {
+		t.Fatalf("expected ErrorList, got %T", err)
+	}
+
+	fields := make(map[string]string)
+	for _, item := range list.Errors {
+		fields[item.StepID] = item.Field
+	}
+	if fields["a"] != "retries" {
+		t.Fatalf("expected retries error on step a, got %v", list.Errors)
+	}
+	if fields["b"] != "retry_backoff" {
+		t.Fatalf("expected retry_backoff error on step b, got %v", list.Errors)
+	}
+	if _, ok := fields["c"]; ok {
+		t.Fatalf("expected step c to be valid, got %v", list.Errors)
+	}
+}
+
+func TestValidateWorkflowStopCondition(t *testing.T) {
+	wf := &Workflow{
+		Name:   "stop",
+		Source: "testdata/stop.toml",
+		Steps: []WorkflowStep{
+			{ID: "a", Type: StepTypeBash, Cmd: "echo a", Stop: &StopCondition{Expr: "true"}},
+			{ID: "b", Type: StepTypeLoop, Prompt: "work", Stop: &StopCondition{LLM: &StopLLM{PassIf: "(done"}}},
+			{ID: "c", Type: StepTypeLoop, Prompt: "work", Stop: &StopCondition{Expr: "count(tasks.open) == 0"}},
+		},
+	}
+
+	_, err := ValidateWorkflow(wf)
+	var list *ErrorList
+	if !errors.As(err, &list) {
+		t.Fatalf("expected ErrorList, got %T", err)
+	}
+
+	fields := make(map[string]string)
+	for _, item := range list.Errors {
+		fields[item.StepID] = item.Field
+	}
+	if fields["a"] != "stop" {
+		t.Fatalf("expected stop error on bash step, got %v", list.Errors)
+	}
+	if fields["b"] != "stop.llm.pass_if" {
+		t.Fatalf("expected pass_if error on step b, got %v", list.Errors)
+	}
+	if _, ok := fields["c"]; ok {
+		t.Fatalf("expected step c to be valid, got %v", list.Errors)
+	}
+}
+
+func TestValidateWorkflowAliveWith(t *testing.T) {
+	wf := &Workflow{
+		Name:   "parasites",
+		Source: "testdata/parasites.toml",
+		Steps: []WorkflowStep{
+			{ID: "build", Type: StepTypeBash, Cmd: "make"},
+			{ID: "watch", Type: StepTypeLoop, Prompt: "watch", AliveWith: []string{"build"}},
+			{ID: "notify", Type: StepTypeBash, Cmd: "echo hi", AliveWith: []string{"build"}},
+			{ID: "ghost", Type: StepTypeLoop, Prompt: "watch", AliveWith: []string{"missing"}},
+		},
+	}
+
+	_, err := ValidateWorkflow(wf)
+	var list *ErrorList
+	if !errors.As(err, &list) {
+		t.Fatalf("expected ErrorList, got %T", err)
+	}
+
+	codes := make(map[string]string)
+	for _, item := range list.Errors {
+		codes[item.StepID] = item.Code
+	}
+	if codes["notify"] != ErrCodeInvalidField {
+		t.Fatalf("expected invalid field on bash parasite, got %v", list.Errors)
+	}
+	if codes["ghost"] != ErrCodeMissingStep {
+		t.Fatalf("expected missing step on unknown host, got %v", list.Errors)
+	}
+	if _, ok := codes["watch"]; ok {
+		t.Fatalf("expected watch to be valid, got %v", list.Errors)
+	}
+}
+
+func TestValidateWorkflowFanoutFixture(t *testing.T) {
+	wf, err := LoadWorkflow(filepath.Join("testdata", "valid-fanout.toml"))
+	if err != nil {
+		t.Fatalf("load workflow: %v", err)
+	}
+	validated, err := ValidateWorkflow(wf)
+	if err != nil {
+		t.Fatalf("validate workflow: %v", err)
+	}
+	if validated.Steps[2].ItemsBeads == nil || validated.Steps[2].ItemsBeads.Limit != 10 {
+		t.Fatalf("expected items_beads query, got %+v", validated.Steps[2].ItemsBeads)
+	}
+}
+
+func TestValidateWorkflowFanoutAndJoin(t *testing.T) {
+	wf := &Workflow{
+		Name:   "fanout",
+		Source: "testdata/fanout.toml",
+		Steps: []WorkflowStep{
+			{ID: "none", Type: StepTypeFanout, Prompt: "work"},
+			{ID: "both", Type: StepTypeFanout, Prompt: "work", Items: []any{"a"}, ItemsCmd: "ls"},
+			{ID: "each", Type: StepTypeFanout, Prompt: "work", Items: []any{"a"}, Each: StepTypeBash},
+			{ID: "join-missing", Type: StepTypeJoin, Fanout: "none-such"},
+			{ID: "join-wrong", Type: StepTypeJoin, Fanout: "each"},
+			{ID: "join-bad", Type: StepTypeJoin, Fanout: "join-wrong"},
+		},
+	}
+
+	_, err := ValidateWorkflow(wf)
+	var list *ErrorList
+	if !errors.As(err, &list) {
+		t.Fatalf("expected ErrorList, got %T", err)
+	}
+
+	fields := make(map[string]string)
+	for _, item := range list.Errors {
+		fields[item.StepID] = item.Field
+	}
+	want := map[string]string{
+		"none":         "items",
+		"both":         "items",
+		"each":         "each",
+		"join-missing": "fanout",
+		"join-bad":     "fanout",
+	}
+	for stepID, field := range want {
+		if fields[stepID] != field {
+			t.Fatalf("expected %s error on %s, got %v", field, stepID, list.Errors)
+		}
+	}
+	if _, ok := fields["join-wrong"]; ok {
+		t.Fatalf("expected join-wrong to be valid, got %v", list.Errors)
+	}
+}
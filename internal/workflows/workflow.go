@@ -12,6 +12,8 @@ const (
 	StepTypeJob      StepType = "job"
 	StepTypeWorkflow StepType = "workflow"
 	StepTypeHuman    StepType = "human"
+	StepTypeFanout   StepType = "fanout"
+	StepTypeJoin     StepType = "join"
 )
 
 // Workflow defines a workflow file model.
@@ -56,6 +58,15 @@ type WorkflowStep struct {
 	WorkflowName  string         `toml:"workflow_name"`
 	Params        map[string]any `toml:"params"`
 	Timeout       string         `toml:"timeout"`
+	Retries       int            `toml:"retries"`
+	RetryBackoff  string         `toml:"retry_backoff"`
+
+	Each        StepType    `toml:"each"`
+	Items       any         `toml:"items"`
+	ItemsCmd    string      `toml:"items_cmd"`
+	ItemsBeads  *BeadsQuery `toml:"items_beads"`
+	MaxParallel int         `toml:"max_parallel"`
+	Fanout      string      `toml:"fanout"`
 }
 
 // WorkflowHooks defines pre/post hooks.
@@ -64,6 +75,13 @@ type WorkflowHooks struct {
 	Post []string `toml:"post"`
 }
 
+// BeadsQuery selects fanout items from the repo's beads issues.
+type BeadsQuery struct {
+	Status string `toml:"status"`
+	Type   string `toml:"type"`
+	Limit  int    `toml:"limit"`
+}
+
 // StopCondition defines stop conditions for loop steps.
 type StopCondition struct {
 	Expr string    `toml:"expr"`